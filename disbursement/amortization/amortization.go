@@ -0,0 +1,131 @@
+// Package amortization builds repayment schedules for a disbursed principal.
+// It supports reducing-balance EMIs, where interest accrues on the
+// outstanding balance, and flat-rate schedules, where interest is charged on
+// the original principal for the whole tenure.
+package amortization
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+type Method string
+
+const (
+	ReducingBalance Method = "reducing_balance"
+	Flat            Method = "flat"
+)
+
+var ErrInvalidTerms = errors.New("invalid amortization terms")
+
+// Terms describe a schedule. The tenure is expressed in months and must be a
+// whole number of repayment periods.
+type Terms struct {
+	Principal    float64
+	AnnualRate   float64 // percent per annum
+	TenureMonths int
+	PeriodMonths int // 1 for monthly, 3 for quarterly
+	Method       Method
+}
+
+type Installment struct {
+	Number         int
+	DueDate        time.Time
+	OpeningBalance float64
+	Principal      float64
+	Interest       float64
+	Amount         float64
+	ClosingBalance float64
+}
+
+func (t Terms) Validate() error {
+	switch {
+	case t.Principal <= 0,
+		t.AnnualRate < 0,
+		t.TenureMonths <= 0,
+		t.PeriodMonths <= 0,
+		t.TenureMonths%t.PeriodMonths != 0,
+		t.Method != ReducingBalance && t.Method != Flat:
+		return ErrInvalidTerms
+	}
+	return nil
+}
+
+// Generate returns the installments for the terms. The first installment
+// falls one period after disbursedAt and each later one a period after the
+// previous, clamped to the last day of shorter months. Amounts are rounded to
+// paise and the final installment absorbs any rounding difference so the
+// principal repaid always equals the principal disbursed.
+func Generate(terms Terms, disbursedAt time.Time) ([]Installment, error) {
+	if err := terms.Validate(); err != nil {
+		return nil, err
+	}
+
+	count := terms.TenureMonths / terms.PeriodMonths
+	rate := terms.AnnualRate / 100 * float64(terms.PeriodMonths) / 12
+
+	var amount, flatInterest float64
+	switch terms.Method {
+	case ReducingBalance:
+		amount = round(emi(terms.Principal, rate, count))
+	case Flat:
+		flatInterest = round(terms.Principal * rate)
+		amount = round(terms.Principal/float64(count)) + flatInterest
+	}
+
+	installments := make([]Installment, 0, count)
+	balance := terms.Principal
+	for number := 1; number <= count; number++ {
+		interest := flatInterest
+		if terms.Method == ReducingBalance {
+			interest = round(balance * rate)
+		}
+		principal := round(amount - interest)
+		if number == count || principal > balance {
+			principal = round(balance)
+		}
+
+		installments = append(installments, Installment{
+			Number:         number,
+			DueDate:        addMonths(disbursedAt, number*terms.PeriodMonths),
+			OpeningBalance: round(balance),
+			Principal:      principal,
+			Interest:       interest,
+			Amount:         round(principal + interest),
+			ClosingBalance: round(balance - principal),
+		})
+		balance = round(balance - principal)
+	}
+	return installments, nil
+}
+
+func emi(principal, rate float64, count int) float64 {
+	if rate == 0 {
+		return principal / float64(count)
+	}
+	growth := math.Pow(1+rate, float64(count))
+	return principal * rate * growth / (growth - 1)
+}
+
+// addMonths moves date forward by months, keeping the day of month where
+// possible: 31 January plus one month is 29 February in a leap year.
+func addMonths(date time.Time, months int) time.Time {
+	year, month, day := date.Date()
+	firstOfTarget := time.Date(year, month+time.Month(months), 1, 0, 0, 0, 0, date.Location())
+	lastDay := firstOfTarget.AddDate(0, 1, -1).Day()
+	return time.Date(
+		firstOfTarget.Year(),
+		firstOfTarget.Month(),
+		min(day, lastDay),
+		date.Hour(),
+		date.Minute(),
+		date.Second(),
+		date.Nanosecond(),
+		date.Location(),
+	)
+}
+
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
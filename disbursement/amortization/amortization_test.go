@@ -0,0 +1,122 @@
+package amortization
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	disbursedAt := time.Date(2024, time.January, 15, 10, 30, 0, 0, time.UTC)
+
+	t.Run("reducing balance monthly EMI", func(t *testing.T) {
+		installments, err := Generate(Terms{
+			Principal:    100000,
+			AnnualRate:   12,
+			TenureMonths: 12,
+			PeriodMonths: 1,
+			Method:       ReducingBalance,
+		}, disbursedAt)
+
+		assert.NoError(t, err)
+		assert.Len(t, installments, 12)
+		assert.Equal(t, 8884.88, installments[0].Amount)
+		assert.Equal(t, 1000.0, installments[0].Interest)
+		assert.Equal(t, 7884.88, installments[0].Principal)
+		assert.Equal(t, time.Date(2024, time.February, 15, 10, 30, 0, 0, time.UTC), installments[0].DueDate)
+		assert.Equal(t, time.Date(2025, time.January, 15, 10, 30, 0, 0, time.UTC), installments[11].DueDate)
+		assert.Equal(t, 0.0, installments[11].ClosingBalance)
+		assert.InDelta(t, 100000, sumPrincipal(installments), 0.001)
+		assert.Less(t, installments[11].Interest, installments[0].Interest)
+	})
+
+	t.Run("flat rate charges interest on original principal", func(t *testing.T) {
+		installments, err := Generate(Terms{
+			Principal:    100000,
+			AnnualRate:   10,
+			TenureMonths: 12,
+			PeriodMonths: 1,
+			Method:       Flat,
+		}, disbursedAt)
+
+		assert.NoError(t, err)
+		assert.Len(t, installments, 12)
+		for _, installment := range installments {
+			assert.Equal(t, 833.33, installment.Interest)
+		}
+		assert.Equal(t, 9166.66, installments[0].Amount)
+		assert.InDelta(t, 100000, sumPrincipal(installments), 0.001)
+		assert.Equal(t, 0.0, installments[11].ClosingBalance)
+	})
+
+	t.Run("quarterly schedule", func(t *testing.T) {
+		installments, err := Generate(Terms{
+			Principal:    40000,
+			AnnualRate:   8,
+			TenureMonths: 12,
+			PeriodMonths: 3,
+			Method:       ReducingBalance,
+		}, disbursedAt)
+
+		assert.NoError(t, err)
+		assert.Len(t, installments, 4)
+		assert.Equal(t, 800.0, installments[0].Interest)
+		assert.Equal(t, time.Date(2024, time.April, 15, 10, 30, 0, 0, time.UTC), installments[0].DueDate)
+		assert.Equal(t, time.Date(2025, time.January, 15, 10, 30, 0, 0, time.UTC), installments[3].DueDate)
+	})
+
+	t.Run("zero interest splits principal evenly", func(t *testing.T) {
+		installments, err := Generate(Terms{
+			Principal:    1000,
+			AnnualRate:   0,
+			TenureMonths: 3,
+			PeriodMonths: 1,
+			Method:       ReducingBalance,
+		}, disbursedAt)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []float64{333.33, 333.33, 333.34}, []float64{
+			installments[0].Amount,
+			installments[1].Amount,
+			installments[2].Amount,
+		})
+	})
+
+	t.Run("clamps due date to end of shorter month", func(t *testing.T) {
+		installments, err := Generate(Terms{
+			Principal:    1000,
+			AnnualRate:   12,
+			TenureMonths: 2,
+			PeriodMonths: 1,
+			Method:       Flat,
+		}, time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC))
+
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC), installments[0].DueDate)
+		assert.Equal(t, time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC), installments[1].DueDate)
+	})
+
+	t.Run("rejects invalid terms", func(t *testing.T) {
+		testCases := []Terms{
+			{Principal: 0, AnnualRate: 12, TenureMonths: 12, PeriodMonths: 1, Method: Flat},
+			{Principal: 1000, AnnualRate: -1, TenureMonths: 12, PeriodMonths: 1, Method: Flat},
+			{Principal: 1000, AnnualRate: 12, TenureMonths: 0, PeriodMonths: 1, Method: Flat},
+			{Principal: 1000, AnnualRate: 12, TenureMonths: 10, PeriodMonths: 3, Method: Flat},
+			{Principal: 1000, AnnualRate: 12, TenureMonths: 12, PeriodMonths: 1, Method: "balloon"},
+		}
+		for _, terms := range testCases {
+			installments, err := Generate(terms, disbursedAt)
+			assert.ErrorIs(t, err, ErrInvalidTerms)
+			assert.Nil(t, installments)
+		}
+	})
+}
+
+func sumPrincipal(installments []Installment) float64 {
+	total := 0.0
+	for _, installment := range installments {
+		total += installment.Principal
+	}
+	return total
+}
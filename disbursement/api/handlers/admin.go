@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"loan-disbursement-service/api/middlewares"
+	"loan-disbursement-service/api/services"
+	"loan-disbursement-service/models"
+	"shared/apperrors"
+	"shared/pii"
+
+	"github.com/gorilla/mux"
+)
+
+type AdminHandler struct {
+	BaseHandler
+	service services.AdminService
+}
+
+func NewAdminHandler(service services.AdminService) *AdminHandler {
+	return &AdminHandler{service: service}
+}
+
+func (a AdminHandler) MarkSuccess(w http.ResponseWriter, r *http.Request) {
+	var req models.MarkSuccessRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+	operator, _ := middlewares.OperatorFromContext(r.Context())
+	result, err := a.service.MarkSuccess(r.Context(), operator, mux.Vars(r)["id"], req)
+	a.respond(w, r, result, err)
+}
+
+func (a AdminHandler) MarkFailed(w http.ResponseWriter, r *http.Request) {
+	var req models.AdminActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+	operator, _ := middlewares.OperatorFromContext(r.Context())
+	result, err := a.service.MarkFailed(r.Context(), operator, mux.Vars(r)["id"], req)
+	a.respond(w, r, result, err)
+}
+
+func (a AdminHandler) Requeue(w http.ResponseWriter, r *http.Request) {
+	var req models.AdminActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+	operator, _ := middlewares.OperatorFromContext(r.Context())
+	result, err := a.service.Requeue(r.Context(), operator, mux.Vars(r)["id"], req)
+	a.respond(w, r, result, err)
+}
+
+func (a AdminHandler) ForceChannel(w http.ResponseWriter, r *http.Request) {
+	var req models.ForceChannelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+	operator, _ := middlewares.OperatorFromContext(r.Context())
+	result, err := a.service.ForceChannel(r.Context(), operator, mux.Vars(r)["id"], req)
+	a.respond(w, r, result, err)
+}
+
+func (a AdminHandler) ResetRetries(w http.ResponseWriter, r *http.Request) {
+	var req models.AdminActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+	operator, _ := middlewares.OperatorFromContext(r.Context())
+	result, err := a.service.ResetRetries(r.Context(), operator, mux.Vars(r)["id"], req)
+	a.respond(w, r, result, err)
+}
+
+func (a AdminHandler) CorrectBeneficiary(w http.ResponseWriter, r *http.Request) {
+	var req models.BeneficiaryCorrectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+	operator, _ := middlewares.OperatorFromContext(r.Context())
+	result, err := a.service.CorrectBeneficiary(r.Context(), operator, mux.Vars(r)["id"], req)
+	a.respond(w, r, result, err)
+}
+
+func (a AdminHandler) AuditTrail(w http.ResponseWriter, r *http.Request) {
+	result, err := a.service.AuditTrail(r.Context(), mux.Vars(r)["id"])
+	a.respond(w, r, result, err)
+}
+
+func (a AdminHandler) BeneficiaryHistory(w http.ResponseWriter, r *http.Request) {
+	result, err := a.service.BeneficiaryHistory(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		a.Error(w, r, notFound(err, "loan not found"))
+		return
+	}
+	a.JSONResponse(w, pii.Visible(r.Context(), result))
+}
+
+func (a AdminHandler) respond(w http.ResponseWriter, r *http.Request, result any, err error) {
+	if err != nil {
+		a.Error(w, r, notFound(err, "disbursement not found"))
+		return
+	}
+
+	a.JSONResponse(w, pii.Visible(r.Context(), result))
+}
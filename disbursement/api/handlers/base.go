@@ -2,7 +2,14 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+
+	"loan-disbursement-service/auth"
+	"shared/apperrors"
+	"shared/authn"
+
+	"gorm.io/gorm"
 )
 
 type BaseHandler struct{}
@@ -13,8 +20,34 @@ func (b *BaseHandler) JSONResponse(w http.ResponseWriter, v any) {
 	json.NewEncoder(w).Encode(v)
 }
 
-func (b *BaseHandler) ErrorResponse(w http.ResponseWriter, status int, error string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(map[string]string{"error": error})
+// Error sends err in the error envelope, with the status of its code.
+// Errors without a code are sent as internal.
+func (b *BaseHandler) Error(w http.ResponseWriter, r *http.Request, err error) {
+	apperrors.Write(w, r, err)
+}
+
+// notFound reports a missing record as message. Services return the
+// repositories' gorm.ErrRecordNotFound, which only the handler can name.
+func notFound(err error, message string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperrors.Wrap(err, apperrors.CodeNotFound, message)
+	}
+	return err
+}
+
+// clientId is the authenticated caller. The disbursements and webhook
+// subscriptions it creates belong to it.
+func clientId(r *http.Request) string {
+	principal, _ := authn.PrincipalFromContext(r.Context())
+	return principal.Subject
+}
+
+// clientScope is the client whose records the caller may read, or empty for
+// admins, who may read every client's.
+func clientScope(r *http.Request) string {
+	principal, _ := authn.PrincipalFromContext(r.Context())
+	if auth.Can(principal, auth.PermissionAdmin) {
+		return ""
+	}
+	return principal.Subject
 }
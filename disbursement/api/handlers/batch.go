@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"loan-disbursement-service/api/services"
+	"loan-disbursement-service/models"
+	"shared/apperrors"
+	"shared/pii"
+
+	"github.com/gorilla/mux"
+)
+
+const maxBatchUploadBytes = 10 << 20
+
+type BatchHandler struct {
+	BaseHandler
+	service services.BatchService
+}
+
+func NewBatchHandler(service services.BatchService) *BatchHandler {
+	return &BatchHandler{service: service}
+}
+
+// Create accepts a multipart upload with the file in the "file" field, a
+// text/csv body, or a JSON array of disburse requests.
+func (b BatchHandler) Create(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBatchUploadBytes)
+
+	var (
+		result *models.BatchResponse
+		err    error
+	)
+	contentType := r.Header.Get("Content-Type")
+	switch {
+	case strings.HasPrefix(contentType, "multipart/form-data"):
+		file, header, formErr := r.FormFile("file")
+		if formErr != nil {
+			b.Error(w, r, apperrors.Invalid(formErr))
+			return
+		}
+		defer file.Close()
+
+		if strings.EqualFold(filepath.Ext(header.Filename), ".json") {
+			var requests []models.DisburseRequest
+			if err := json.NewDecoder(file).Decode(&requests); err != nil {
+				b.Error(w, r, apperrors.Invalid(err))
+				return
+			}
+			result, err = b.service.Create(r.Context(), clientId(r), header.Filename, requests)
+		} else {
+			result, err = b.service.CreateFromCSV(r.Context(), clientId(r), header.Filename, file)
+		}
+	case strings.HasPrefix(contentType, "text/csv"):
+		result, err = b.service.CreateFromCSV(r.Context(), clientId(r), "csv", r.Body)
+	default:
+		var requests []models.DisburseRequest
+		if err := json.NewDecoder(r.Body).Decode(&requests); err != nil {
+			b.Error(w, r, apperrors.Invalid(err))
+			return
+		}
+		result, err = b.service.Create(r.Context(), clientId(r), "json", requests)
+	}
+	if err != nil {
+		b.Error(w, r, err)
+		return
+	}
+
+	b.JSONResponse(w, result)
+}
+
+func (b BatchHandler) Get(w http.ResponseWriter, r *http.Request) {
+	batchId := mux.Vars(r)["id"]
+	result, err := b.service.Get(r.Context(), clientScope(r), batchId)
+	if err != nil {
+		b.Error(w, r, notFound(err, "batch not found"))
+		return
+	}
+
+	b.JSONResponse(w, pii.Visible(r.Context(), result))
+}
+
+// Results downloads the per-row outcome of a batch as CSV.
+func (b BatchHandler) Results(w http.ResponseWriter, r *http.Request) {
+	batchId := mux.Vars(r)["id"]
+
+	// Buffer the file so a failure part way through still gets a proper
+	// error response instead of a truncated download.
+	var buf bytes.Buffer
+	if err := b.service.WriteResults(r.Context(), clientScope(r), batchId, &buf); err != nil {
+		b.Error(w, r, notFound(err, "batch not found"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set(
+		"Content-Disposition",
+		fmt.Sprintf("attachment; filename=%q", batchId+"-results.csv"),
+	)
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
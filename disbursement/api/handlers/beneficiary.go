@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"loan-disbursement-service/api/middlewares"
+	"loan-disbursement-service/api/services"
+	"loan-disbursement-service/models"
+	"shared/apperrors"
+	"shared/pii"
+	"shared/validation"
+
+	"github.com/gorilla/mux"
+)
+
+type BeneficiaryHandler struct {
+	BaseHandler
+	service services.BeneficiaryService
+}
+
+func NewBeneficiaryHandler(service services.BeneficiaryService) *BeneficiaryHandler {
+	return &BeneficiaryHandler{service: service}
+}
+
+func (b BeneficiaryHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.BeneficiaryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		b.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+	if err := validation.Struct(req); err != nil {
+		b.Error(w, r, err)
+		return
+	}
+
+	beneficiary, err := b.service.Create(r.Context(), req)
+	if err != nil {
+		b.Error(w, r, err)
+		return
+	}
+
+	b.JSONResponse(w, pii.Visible(r.Context(), beneficiary))
+}
+
+func (b BeneficiaryHandler) List(w http.ResponseWriter, r *http.Request) {
+	beneficiaries, err := b.service.List(r.Context())
+	if err != nil {
+		b.Error(w, r, err)
+		return
+	}
+
+	b.JSONResponse(w, pii.Visible(r.Context(), beneficiaries))
+}
+
+func (b BeneficiaryHandler) Get(w http.ResponseWriter, r *http.Request) {
+	beneficiaryId := mux.Vars(r)["id"]
+	beneficiary, err := b.service.Get(r.Context(), beneficiaryId)
+	if err != nil {
+		b.Error(w, r, notFound(err, "beneficiary not found"))
+		return
+	}
+
+	b.JSONResponse(w, pii.Visible(r.Context(), beneficiary))
+}
+
+func (b BeneficiaryHandler) Update(w http.ResponseWriter, r *http.Request) {
+	beneficiaryId := mux.Vars(r)["id"]
+	var req models.BeneficiaryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		b.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+	if err := validation.Present(req); err != nil {
+		b.Error(w, r, err)
+		return
+	}
+
+	beneficiary, err := b.service.Update(r.Context(), beneficiaryId, req)
+	if err != nil {
+		b.Error(w, r, notFound(err, "beneficiary not found"))
+		return
+	}
+
+	b.JSONResponse(w, pii.Visible(r.Context(), beneficiary))
+}
+
+func (b BeneficiaryHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	beneficiaryId := mux.Vars(r)["id"]
+	if err := b.service.Delete(r.Context(), beneficiaryId); err != nil {
+		b.Error(w, r, notFound(err, "beneficiary not found"))
+		return
+	}
+
+	b.JSONResponse(w, map[string]string{"id": beneficiaryId})
+}
+
+func (b BeneficiaryHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	beneficiaryId := mux.Vars(r)["id"]
+	var req models.BeneficiaryVerificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		b.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+
+	beneficiary, err := b.service.Verify(r.Context(), beneficiaryId, req)
+	if err != nil {
+		b.Error(w, r, notFound(err, "beneficiary not found"))
+		return
+	}
+
+	b.JSONResponse(w, pii.Visible(r.Context(), beneficiary))
+}
+
+func (b BeneficiaryHandler) Override(w http.ResponseWriter, r *http.Request) {
+	beneficiaryId := mux.Vars(r)["id"]
+	var req models.BeneficiaryOverrideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		b.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+
+	operator, _ := middlewares.OperatorFromContext(r.Context())
+	beneficiary, err := b.service.Override(r.Context(), operator, beneficiaryId, req)
+	if err != nil {
+		b.Error(w, r, notFound(err, "beneficiary not found"))
+		return
+	}
+
+	b.JSONResponse(w, pii.Visible(r.Context(), beneficiary))
+}
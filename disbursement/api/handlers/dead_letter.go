@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"loan-disbursement-service/api/middlewares"
+	"loan-disbursement-service/api/services"
+	"loan-disbursement-service/models"
+	"shared/apperrors"
+	"shared/pii"
+)
+
+type DeadLetterHandler struct {
+	BaseHandler
+	service services.DeadLetterService
+}
+
+func NewDeadLetterHandler(service services.DeadLetterService) *DeadLetterHandler {
+	return &DeadLetterHandler{service: service}
+}
+
+// List takes status, category, offset and limit query parameters.
+func (d DeadLetterHandler) List(w http.ResponseWriter, r *http.Request) {
+	filter, err := deadLetterFilter(r)
+	if err != nil {
+		d.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+	result, err := d.service.List(r.Context(), filter)
+	d.respond(w, r, result, err)
+}
+
+// Export downloads every entry matching the status and category filters as
+// CSV.
+func (d DeadLetterHandler) Export(w http.ResponseWriter, r *http.Request) {
+	filter, err := deadLetterFilter(r)
+	if err != nil {
+		d.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+
+	// Buffered for the same reason as batch results: an error part way
+	// through should not reach the client as a truncated file.
+	var buf bytes.Buffer
+	if err := d.service.Export(r.Context(), filter, &buf); err != nil {
+		d.respond(w, r, nil, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set(
+		"Content-Disposition",
+		fmt.Sprintf("attachment; filename=%q", "dead-letters.csv"),
+	)
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+func (d DeadLetterHandler) Requeue(w http.ResponseWriter, r *http.Request) {
+	var req models.DeadLetterBulkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		d.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+	operator, _ := middlewares.OperatorFromContext(r.Context())
+	result, err := d.service.Requeue(r.Context(), operator, req)
+	d.respond(w, r, result, err)
+}
+
+func (d DeadLetterHandler) Close(w http.ResponseWriter, r *http.Request) {
+	var req models.DeadLetterBulkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		d.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+	operator, _ := middlewares.OperatorFromContext(r.Context())
+	result, err := d.service.Close(r.Context(), operator, req)
+	d.respond(w, r, result, err)
+}
+
+func (d DeadLetterHandler) respond(w http.ResponseWriter, r *http.Request, result any, err error) {
+	if err != nil {
+		d.Error(w, r, err)
+		return
+	}
+
+	d.JSONResponse(w, pii.Visible(r.Context(), result))
+}
+
+func deadLetterFilter(r *http.Request) (models.DeadLetterFilter, error) {
+	query := r.URL.Query()
+	offset, err := queryInt(query.Get("offset"), "offset")
+	if err != nil {
+		return models.DeadLetterFilter{}, err
+	}
+	limit, err := queryInt(query.Get("limit"), "limit")
+	if err != nil {
+		return models.DeadLetterFilter{}, err
+	}
+	return models.DeadLetterFilter{
+		Status:   models.DeadLetterStatus(query.Get("status")),
+		Category: models.FailureCategory(query.Get("category")),
+		Offset:   offset,
+		Limit:    limit,
+	}, nil
+}
+
+func queryInt(value string, name string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer", name)
+	}
+	return parsed, nil
+}
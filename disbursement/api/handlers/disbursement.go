@@ -2,14 +2,24 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
+	"loan-disbursement-service/api/middlewares"
 	"loan-disbursement-service/api/services"
+	"loan-disbursement-service/metrics"
 	"loan-disbursement-service/models"
+	"shared/apperrors"
+	"shared/pii"
+	"shared/validation"
 
 	"github.com/gorilla/mux"
 )
 
+// queueFullRetryAfter is the Retry-After, in seconds, sent while the payment
+// queue is full. The payment worker drains it in a few seconds.
+const queueFullRetryAfter = "5"
+
 type DisbursementHandler struct {
 	BaseHandler
 	service services.DisbursementService
@@ -22,13 +32,22 @@ func NewDisbursementHandler(service services.DisbursementService) *DisbursementH
 func (d DisbursementHandler) Disburse(w http.ResponseWriter, r *http.Request) {
 	var req models.DisburseRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		d.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		d.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+	if err := validation.Struct(req); err != nil {
+		d.Error(w, r, err)
 		return
 	}
 
+	req.ClientId = clientId(r)
 	result, err := d.service.Disburse(r.Context(), &req)
 	if err != nil {
-		d.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		if errors.Is(err, models.PAYMENT_QUEUE_FULL) {
+			d.queueFull(w, r, err)
+			return
+		}
+		d.Error(w, r, err)
 		return
 	}
 
@@ -37,22 +56,45 @@ func (d DisbursementHandler) Disburse(w http.ResponseWriter, r *http.Request) {
 
 func (d DisbursementHandler) Fetch(w http.ResponseWriter, r *http.Request) {
 	id := mux.Vars(r)["id"]
-	result, err := d.service.Fetch(r.Context(), id)
+	result, err := d.service.Fetch(r.Context(), clientScope(r), id)
 	if err != nil {
-		d.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		d.Error(w, r, notFound(err, "disbursement not found"))
 		return
 	}
 
-	d.JSONResponse(w, result)
+	d.JSONResponse(w, pii.Visible(r.Context(), result))
 }
 
 func (d DisbursementHandler) Retry(w http.ResponseWriter, r *http.Request) {
 	id := mux.Vars(r)["id"]
 	result, err := d.service.Retry(r.Context(), id)
 	if err != nil {
-		d.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		if errors.Is(err, models.PAYMENT_QUEUE_FULL) {
+			d.queueFull(w, r, err)
+			return
+		}
+		d.Error(w, r, notFound(err, "disbursement not found"))
+		return
+	}
+
+	d.JSONResponse(w, result)
+}
+
+func (d DisbursementHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	result, err := d.service.Cancel(r.Context(), id)
+	if err != nil {
+		d.Error(w, r, notFound(err, "disbursement not found"))
 		return
 	}
 
 	d.JSONResponse(w, result)
 }
+
+// queueFull turns the caller away with 503 while the payment queue is full,
+// rather than holding the request until the worker makes room.
+func (d DisbursementHandler) queueFull(w http.ResponseWriter, r *http.Request, err error) {
+	metrics.RecordRejected(middlewares.RouteName(r), "queue_full")
+	w.Header().Set("Retry-After", queueFullRetryAfter)
+	d.Error(w, r, err)
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"loan-disbursement-service/models"
+	"shared/apperrors"
+	"shared/ifsc"
+
+	"github.com/gorilla/mux"
+)
+
+// IFSCHandler serves the bank directory, so callers can check a payee's
+// branch before registering it.
+type IFSCHandler struct {
+	BaseHandler
+	directory *ifsc.Directory
+}
+
+func NewIFSCHandler(directory *ifsc.Directory) *IFSCHandler {
+	return &IFSCHandler{directory: directory}
+}
+
+func (h IFSCHandler) Lookup(w http.ResponseWriter, r *http.Request) {
+	branch, ok := h.directory.Lookup(mux.Vars(r)["code"])
+	if !ok {
+		h.Error(w, r, models.IFSC_NOT_FOUND)
+		return
+	}
+	h.JSONResponse(w, branch)
+}
+
+// Search takes q, bank, city, state and limit query parameters.
+func (h IFSCHandler) Search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, err := queryInt(query.Get("limit"), "limit")
+	if err != nil {
+		h.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+	h.JSONResponse(w, h.directory.Search(ifsc.Query{
+		Text:  query.Get("q"),
+		Bank:  query.Get("bank"),
+		City:  query.Get("city"),
+		State: query.Get("state"),
+		Limit: limit,
+	}))
+}
@@ -6,9 +6,10 @@ import (
 
 	"loan-disbursement-service/api/services"
 	"loan-disbursement-service/models"
+	"shared/apperrors"
+	"shared/validation"
 
 	"github.com/gorilla/mux"
-	"gorm.io/gorm"
 )
 
 type LoanHandler struct {
@@ -23,13 +24,17 @@ func NewLoanHandler(service services.LoanService) *LoanHandler {
 func (l LoanHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var req models.LoanRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		l.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+	if err := validation.Struct(req); err != nil {
+		l.Error(w, r, err)
 		return
 	}
 
-	loan, err := l.service.Create(r.Context(), req.Amount)
+	loan, err := l.service.Create(r.Context(), req)
 	if err != nil {
-		l.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		l.Error(w, r, err)
 		return
 	}
 
@@ -40,17 +45,46 @@ func (l LoanHandler) Update(w http.ResponseWriter, r *http.Request) {
 	loanId := mux.Vars(r)["id"]
 	var req models.LoanRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		l.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+	if err := validation.Present(req); err != nil {
+		l.Error(w, r, err)
 		return
 	}
 
-	loan, err := l.service.Update(r.Context(), loanId, map[string]any{"amount": req.Amount})
+	fields := map[string]any{}
+	if req.Amount > 0 {
+		fields["amount"] = req.Amount
+	}
+	if req.BorrowerId != "" {
+		fields["borrower_id"] = req.BorrowerId
+	}
+	if req.BorrowerName != "" {
+		fields["borrower_name"] = req.BorrowerName
+	}
+	if req.ProductType != "" {
+		fields["product_type"] = req.ProductType
+	}
+	if req.SanctionDate != nil {
+		fields["sanction_date"] = *req.SanctionDate
+	}
+	if req.InterestRate != nil {
+		fields["interest_rate"] = *req.InterestRate
+	}
+	if req.TenureMonths > 0 {
+		fields["tenure_months"] = req.TenureMonths
+	}
+	if req.RepaymentFrequency != "" {
+		fields["repayment_frequency"] = req.RepaymentFrequency
+	}
+	if req.InterestMethod != "" {
+		fields["interest_method"] = req.InterestMethod
+	}
+
+	loan, err := l.service.Update(r.Context(), loanId, fields)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			l.ErrorResponse(w, http.StatusNotFound, "loan not found")
-			return
-		}
-		l.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		l.Error(w, r, notFound(err, "loan not found"))
 		return
 	}
 
@@ -61,11 +95,41 @@ func (l LoanHandler) Get(w http.ResponseWriter, r *http.Request) {
 	loanId := mux.Vars(r)["id"]
 	loan, err := l.service.Get(r.Context(), loanId)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			l.ErrorResponse(w, http.StatusNotFound, "loan not found")
-			return
-		}
-		l.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		l.Error(w, r, notFound(err, "loan not found"))
+		return
+	}
+
+	l.JSONResponse(w, loan)
+}
+
+func (l LoanHandler) LinkBeneficiary(w http.ResponseWriter, r *http.Request) {
+	loanId := mux.Vars(r)["id"]
+	var req models.LinkBeneficiaryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		l.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+
+	loan, err := l.service.LinkBeneficiary(r.Context(), loanId, req.BeneficiaryId)
+	if err != nil {
+		l.Error(w, r, notFound(err, "loan or beneficiary not found"))
+		return
+	}
+
+	l.JSONResponse(w, loan)
+}
+
+func (l LoanHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	loanId := mux.Vars(r)["id"]
+	var req models.LoanStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		l.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+
+	loan, err := l.service.UpdateStatus(r.Context(), loanId, req.Status)
+	if err != nil {
+		l.Error(w, r, notFound(err, "loan not found"))
 		return
 	}
 
@@ -75,7 +139,7 @@ func (l LoanHandler) Get(w http.ResponseWriter, r *http.Request) {
 func (l LoanHandler) List(w http.ResponseWriter, r *http.Request) {
 	loans, err := l.service.List(r.Context())
 	if err != nil {
-		l.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		l.Error(w, r, err)
 		return
 	}
 
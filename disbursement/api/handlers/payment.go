@@ -5,6 +5,7 @@ import (
 	"loan-disbursement-service/api/services"
 	"loan-disbursement-service/models"
 	"net/http"
+	"shared/apperrors"
 )
 
 type PaymentHandler struct {
@@ -19,13 +20,13 @@ func NewPaymentHandler(service services.PaymentService) *PaymentHandler {
 func (h PaymentHandler) ProcessPayment(w http.ResponseWriter, r *http.Request) {
 	var req models.PaymentNotificationRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		h.Error(w, r, apperrors.Invalid(err))
 		return
 	}
 
 	err := h.service.HandleNotification(r.Context(), req)
 	if err != nil {
-		h.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		h.Error(w, r, notFound(err, "transaction not found"))
 		return
 	}
 
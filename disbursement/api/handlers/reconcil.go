@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"loan-disbursement-service/api/services"
+	"loan-disbursement-service/models"
+	"shared/apperrors"
+)
+
+type ReconciliationHandler struct {
+	BaseHandler
+	service services.ReconciliationService
+}
+
+func NewReconciliationHandler(service services.ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{service: service}
+}
+
+// Reconcile matches a bank statement against the transactions that
+// succeeded on its date.
+func (h ReconciliationHandler) Reconcile(w http.ResponseWriter, r *http.Request) {
+	var req models.ReconciliationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+
+	result, err := h.service.Reconcile(r.Context(), req)
+	if err != nil {
+		h.Error(w, r, err)
+		return
+	}
+
+	h.JSONResponse(w, result)
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+
+	"loan-disbursement-service/api/services"
+
+	"github.com/gorilla/mux"
+)
+
+type ScheduleHandler struct {
+	BaseHandler
+	service services.ScheduleService
+}
+
+func NewScheduleHandler(service services.ScheduleService) *ScheduleHandler {
+	return &ScheduleHandler{service: service}
+}
+
+func (s ScheduleHandler) Get(w http.ResponseWriter, r *http.Request) {
+	loanId := mux.Vars(r)["id"]
+	schedule, err := s.service.Get(r.Context(), loanId)
+	if err != nil {
+		s.Error(w, r, notFound(err, "loan not found"))
+		return
+	}
+
+	s.JSONResponse(w, schedule)
+}
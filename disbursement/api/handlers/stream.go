@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"loan-disbursement-service/auth"
+	"loan-disbursement-service/events"
+	"loan-disbursement-service/models"
+	"shared/apperrors"
+	"shared/authn"
+	"shared/pii"
+
+	"github.com/gorilla/mux"
+)
+
+// streamHeartbeat keeps idle streams from being closed by proxies.
+const streamHeartbeat = 15 * time.Second
+
+// StreamHandler serves disbursement events as Server-Sent Events.
+type StreamHandler struct {
+	BaseHandler
+	bus *events.Bus
+}
+
+func NewStreamHandler(bus *events.Bus) *StreamHandler {
+	return &StreamHandler{bus: bus}
+}
+
+// Stream takes loan_id, status, type and channel query parameters. status,
+// type and channel accept comma separated lists.
+func (s StreamHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	s.serve(w, r, "")
+}
+
+// StreamDisbursement streams one disbursement's events and takes the same
+// filters as Stream.
+func (s StreamHandler) StreamDisbursement(w http.ResponseWriter, r *http.Request) {
+	s.serve(w, r, mux.Vars(r)["id"])
+}
+
+// serve resumes after the Last-Event-ID header browsers send on reconnect,
+// or the last_event_id query parameter. When events after it are no longer
+// held a resync event is sent first, and the client should reload the
+// disbursements it shows.
+func (s StreamHandler) serve(w http.ResponseWriter, r *http.Request, disbursementId string) {
+	filter, err := streamFilter(r)
+	if err != nil {
+		s.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+	filter.DisbursementId = disbursementId
+	// Partners only see the disbursements they created, as with webhooks;
+	// operations see every client's.
+	principal, _ := authn.PrincipalFromContext(r.Context())
+	if !auth.Can(principal, auth.PermissionAdmin) {
+		filter.ClientId = principal.Subject
+	}
+
+	lastEventId := r.Header.Get("Last-Event-ID")
+	if lastEventId == "" {
+		lastEventId = r.URL.Query().Get("last_event_id")
+	}
+	var lastId uint64
+	if lastEventId != "" {
+		lastId, err = strconv.ParseUint(lastEventId, 10, 64)
+		if err != nil {
+			s.Error(w, r, apperrors.New(apperrors.CodeInvalidRequest, "last event id must be a non-negative integer"))
+			return
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.Error(w, r, errors.New("streaming is not supported"))
+		return
+	}
+	subscription, replay, complete, err := s.bus.Subscribe(filter, lastId)
+	if err != nil {
+		s.Error(w, r, apperrors.Wrap(err, apperrors.CodeUnavailable, err.Error()))
+		return
+	}
+	defer subscription.Cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprint(w, "retry: 3000\n\n")
+	if !complete {
+		fmt.Fprint(w, "event: resync\ndata: {}\n\n")
+	}
+	for _, event := range replay {
+		writeEvent(w, r, event)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, open := <-subscription.Events():
+			// Closed when the client fell behind or the server is shutting
+			// down. Either way it reconnects and resumes from the last id.
+			if !open {
+				return
+			}
+			writeEvent(w, r, event)
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, r *http.Request, event events.Event) {
+	data, _ := json.Marshal(pii.Visible(r.Context(), event))
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Id, event.Type, data)
+}
+
+func streamFilter(r *http.Request) (events.Filter, error) {
+	query := r.URL.Query()
+	filter := events.Filter{LoanId: query.Get("loan_id")}
+	for _, value := range queryList(query.Get("type")) {
+		eventType := events.Type(value)
+		if !eventType.IsValid() {
+			return events.Filter{}, fmt.Errorf("invalid type: %s", value)
+		}
+		filter.Types = append(filter.Types, eventType)
+	}
+	for _, value := range queryList(query.Get("status")) {
+		status := models.DisbursementStatus(value)
+		if !status.IsValid() {
+			return events.Filter{}, fmt.Errorf("invalid status: %s", value)
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+	for _, value := range queryList(query.Get("channel")) {
+		channel := models.PaymentChannel(strings.ToUpper(value))
+		if !channel.IsValid() {
+			return events.Filter{}, fmt.Errorf("invalid channel: %s", value)
+		}
+		filter.Channels = append(filter.Channels, channel)
+	}
+	return filter, nil
+}
+
+func queryList(value string) []string {
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"loan-disbursement-service/api/services"
+	"loan-disbursement-service/models"
+	"shared/apperrors"
+	"shared/pii"
+
+	"github.com/gorilla/mux"
+)
+
+type WebhookHandler struct {
+	BaseHandler
+	service services.WebhookService
+}
+
+func NewWebhookHandler(service services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{service: service}
+}
+
+func (h WebhookHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	var req models.WebhookSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+	result, err := h.service.Subscribe(r.Context(), clientId(r), req)
+	h.respond(w, r, result, err)
+}
+
+func (h WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	result, err := h.service.ListSubscriptions(r.Context(), clientId(r))
+	h.respond(w, r, result, err)
+}
+
+func (h WebhookHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	subscriptionId := mux.Vars(r)["id"]
+	err := h.service.Unsubscribe(r.Context(), clientId(r), subscriptionId)
+	h.respond(w, r, map[string]string{"id": subscriptionId}, err)
+}
+
+// Deliveries takes status, offset and limit query parameters.
+func (h WebhookHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	offset, err := queryInt(query.Get("offset"), "offset")
+	if err != nil {
+		h.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+	limit, err := queryInt(query.Get("limit"), "limit")
+	if err != nil {
+		h.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+	result, err := h.service.ListDeliveries(r.Context(), clientId(r), mux.Vars(r)["id"], models.WebhookDeliveryFilter{
+		Status: models.WebhookDeliveryStatus(query.Get("status")),
+		Offset: offset,
+		Limit:  limit,
+	})
+	h.respond(w, r, result, err)
+}
+
+func (h WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	result, err := h.service.Redeliver(r.Context(), clientId(r), mux.Vars(r)["id"])
+	h.respond(w, r, result, err)
+}
+
+func (h WebhookHandler) respond(w http.ResponseWriter, r *http.Request, result any, err error) {
+	if err != nil {
+		h.Error(w, r, err)
+		return
+	}
+
+	h.JSONResponse(w, pii.Visible(r.Context(), result))
+}
@@ -0,0 +1,61 @@
+package middlewares
+
+import (
+	"errors"
+	"loan-disbursement-service/auth"
+	"net/http"
+	"shared/apperrors"
+	"shared/authn"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Authenticate admits requests whose credentials one of authenticators
+// accepts and puts the caller's principal in the request context. Requests
+// without credentials, or with credentials that do not check out, get 401.
+func Authenticate(authenticators ...authn.Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, err := authn.Authenticate(r, authenticators...)
+			if err != nil {
+				if !errors.Is(err, authn.ErrNoCredentials) {
+					log.Ctx(r.Context()).Warn().Err(err).Msg("rejected credentials")
+				}
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				apperrors.Write(w, r, unauthenticated(err))
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(authn.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// RequirePermission admits callers whose roles grant permission, and gives
+// everyone else 403. It must run after Authenticate.
+func RequirePermission(permission auth.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, _ := authn.PrincipalFromContext(r.Context())
+			if !auth.Can(principal, permission) {
+				apperrors.Write(w, r, apperrors.Wrap(
+					auth.ErrPermissionDenied,
+					apperrors.CodePermissionDenied,
+					auth.ErrPermissionDenied.Error(),
+				))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// unauthenticated keeps the reason a token or key was refused out of the
+// response; it is logged instead.
+func unauthenticated(err error) error {
+	if errors.Is(err, authn.ErrInvalidCredentials) {
+		err = authn.ErrInvalidCredentials
+	} else {
+		err = authn.ErrNoCredentials
+	}
+	return apperrors.Wrap(err, apperrors.CodeUnauthenticated, err.Error())
+}
@@ -0,0 +1,45 @@
+package middlewares
+
+import (
+	"context"
+	"loan-disbursement-service/models"
+	"net/http"
+	"shared/apperrors"
+	"shared/authn"
+)
+
+type operatorKey struct{}
+
+// RequireRole admits operators holding one of roles. The operator is the
+// principal Authenticate admitted, acting in the first of roles it holds, so
+// it must run after Authenticate; callers without one of roles get 403.
+func RequireRole(roles ...models.OperatorRole) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := authn.PrincipalFromContext(r.Context())
+			if !ok {
+				apperrors.Write(w, r, models.OPERATOR_REQUIRED)
+				return
+			}
+			operator := models.Operator{Id: principal.Subject}
+			for _, role := range roles {
+				if principal.HasRole(string(role)) {
+					operator.Role = role
+					break
+				}
+			}
+			if operator.Role == "" {
+				apperrors.Write(w, r, models.OPERATOR_NOT_ALLOWED)
+				return
+			}
+			ctx := context.WithValue(r.Context(), operatorKey{}, operator)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// OperatorFromContext returns the operator RequireRole admitted.
+func OperatorFromContext(ctx context.Context) (models.Operator, bool) {
+	operator, ok := ctx.Value(operatorKey{}).(models.Operator)
+	return operator, ok
+}
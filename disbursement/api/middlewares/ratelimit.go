@@ -0,0 +1,56 @@
+package middlewares
+
+import (
+	"loan-disbursement-service/metrics"
+	"loan-disbursement-service/ratelimit"
+	"net/http"
+	"shared/apperrors"
+	"shared/authn"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// RateLimit admits each client's requests to a route at the rate limiter
+// allows, answering the rest with 429 and a Retry-After of when a request
+// would be admitted. Clients are told apart by their principal, so it must
+// run after Authenticate.
+func RateLimit(limiter *ratelimit.Limiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, _ := authn.PrincipalFromContext(r.Context())
+			route := RouteName(r)
+			ok, delay := limiter.Allow(principal.Subject, route, time.Now())
+			if !ok {
+				log.Ctx(r.Context()).Warn().
+					Str("client", principal.Subject).
+					Str("route", route).
+					Msg("rate limit exceeded")
+				metrics.RecordRejected(route, "rate_limited")
+				w.Header().Set("Retry-After", strconv.Itoa(ratelimit.RetryAfter(delay)))
+				apperrors.Write(w, r, apperrors.Wrap(
+					ratelimit.ErrRateLimited,
+					apperrors.CodeRateLimited,
+					ratelimit.ErrRateLimited.Error(),
+				))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RouteName names the route r matched by its method and path template, as
+// in "POST /api/v1/disburse/{id}/retry", so that requests for different ids
+// count against the same limit.
+func RouteName(r *http.Request) string {
+	path := r.URL.Path
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			path = template
+		}
+	}
+	return r.Method + " " + path
+}
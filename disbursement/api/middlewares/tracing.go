@@ -1,21 +1,95 @@
 package middlewares
 
 import (
-	"context"
 	"fmt"
 	"net/http"
+	"shared/logging"
+	"shared/tracing"
 
 	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// CorrelationIDMiddleware attaches the request's context logger, which
+// services enrich with the ids of the loan and disbursement they work on.
 func CorrelationIDMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		id := r.Header.Get("X-Request-ID")
 		if id == "" {
 			id = fmt.Sprintf("req-%s", uuid.New().String())
 		}
-		ctx := context.WithValue(r.Context(), "request_id", id)
+		ctx := tracing.WithRequestId(r.Context(), id)
+		ctx = logging.With(ctx, logging.Fields{RequestId: id})
 		w.Header().Set("X-Request-ID", id)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// TracingMiddleware continues the caller's trace from the traceparent header,
+// or starts one, with a server span named after the matched route. It runs
+// after CorrelationIDMiddleware so the span carries the request id, and adds
+// the trace id to the context logger.
+func TracingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := r.URL.Path
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+		ctx := tracing.Extract(r.Context(), r.Header)
+		ctx, span := tracing.Tracer().Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.HTTPRoute(route),
+			),
+		)
+		defer span.End()
+		ctx = logging.With(ctx, logging.Fields{})
+		if requestId, ok := tracing.RequestId(ctx); ok {
+			span.SetAttributes(tracing.RequestIdKey.String(requestId))
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(recorder.status))
+		if recorder.status >= http.StatusInternalServerError {
+			tracing.Record(span, fmt.Errorf("%s", http.StatusText(recorder.status)))
+		}
+	})
+}
+
+// statusRecorder keeps the response status for the span. It passes Flush
+// through so event streams still reach the client as they are written.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(body []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(body)
+}
+
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -3,6 +3,9 @@ package api
 import (
 	"loan-disbursement-service/api/handlers"
 	"loan-disbursement-service/api/middlewares"
+	"loan-disbursement-service/auth"
+	"loan-disbursement-service/metrics"
+	"loan-disbursement-service/models"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -13,32 +16,154 @@ func (d *DisbursementServer) routes() http.Handler {
 
 	router.Use(middlewares.CorrelationIDMiddleware)
 
+	router.Handle("/metrics", metrics.Handler()).Methods(http.MethodGet)
+
 	loanService := d.serviceFactory.GetLoanService()
 	loanHandler := handlers.NewLoanHandler(loanService)
+	scheduleHandler := handlers.NewScheduleHandler(d.serviceFactory.GetScheduleService())
 
 	subRoute := router.PathPrefix("/api/v1").Subrouter()
+	subRoute.Use(
+		middlewares.TracingMiddleware,
+		middlewares.Authenticate(d.authenticators...),
+		middlewares.RateLimit(d.limiter),
+		middlewares.PIIAccessMiddleware,
+	)
+
+	// Reads are open to any authenticated caller; writes, and reads of a
+	// partner's uploads, need the permission of their area.
+	loanWrite := middlewares.RequirePermission(auth.PermissionLoanWrite)
+	disburse := middlewares.RequirePermission(auth.PermissionDisburse)
+	retry := middlewares.RequirePermission(auth.PermissionRetry)
+	admin := middlewares.RequirePermission(auth.PermissionAdmin)
+	adminOnly := middlewares.RequireRole(models.OperatorRoleAdmin)
+	anyOperator := middlewares.RequireRole(models.OperatorRoleAdmin, models.OperatorRoleSupport)
 
 	loanSubRoute := subRoute.PathPrefix("/loan").Subrouter()
 
-	loanSubRoute.HandleFunc("", loanHandler.Create).Methods(http.MethodPost)
+	loanSubRoute.Handle("", loanWrite(http.HandlerFunc(loanHandler.Create))).Methods(http.MethodPost)
 	loanSubRoute.HandleFunc("", loanHandler.List).Methods(http.MethodGet)
-	loanSubRoute.HandleFunc("/{id}", loanHandler.Update).Methods(http.MethodPut)
+	loanSubRoute.Handle("/{id}", loanWrite(http.HandlerFunc(loanHandler.Update))).Methods(http.MethodPut)
 	loanSubRoute.HandleFunc("/{id}", loanHandler.Get).Methods(http.MethodGet)
+	loanSubRoute.Handle("/{id}/beneficiary", loanWrite(http.HandlerFunc(loanHandler.LinkBeneficiary))).
+		Methods(http.MethodPut)
+	loanSubRoute.Handle("/{id}/status", loanWrite(http.HandlerFunc(loanHandler.UpdateStatus))).
+		Methods(http.MethodPut)
+	loanSubRoute.HandleFunc("/{id}/schedule", scheduleHandler.Get).Methods(http.MethodGet)
+
+	beneficiaryService := d.serviceFactory.GetBeneficiaryService()
+	beneficiaryHandler := handlers.NewBeneficiaryHandler(beneficiaryService)
+
+	beneficiarySubRoute := subRoute.PathPrefix("/beneficiary").Subrouter()
+	beneficiarySubRoute.Handle("", loanWrite(http.HandlerFunc(beneficiaryHandler.Create))).
+		Methods(http.MethodPost)
+	beneficiarySubRoute.HandleFunc("", beneficiaryHandler.List).Methods(http.MethodGet)
+	beneficiarySubRoute.HandleFunc("/{id}", beneficiaryHandler.Get).Methods(http.MethodGet)
+	beneficiarySubRoute.Handle("/{id}", loanWrite(http.HandlerFunc(beneficiaryHandler.Update))).
+		Methods(http.MethodPut)
+	beneficiarySubRoute.Handle("/{id}", loanWrite(http.HandlerFunc(beneficiaryHandler.Delete))).
+		Methods(http.MethodDelete)
+	// Verifying a payee is kept from the callers that register them, and
+	// overriding a failed name match is an operations decision.
+	beneficiarySubRoute.Handle("/{id}/verify", admin(anyOperator(http.HandlerFunc(beneficiaryHandler.Verify)))).
+		Methods(http.MethodPost)
+	beneficiarySubRoute.Handle("/{id}/override", admin(adminOnly(http.HandlerFunc(beneficiaryHandler.Override)))).
+		Methods(http.MethodPost)
+
+	ifscHandler := handlers.NewIFSCHandler(d.serviceFactory.GetIFSCDirectory())
+
+	ifscSubRoute := subRoute.PathPrefix("/ifsc").Subrouter()
+	ifscSubRoute.HandleFunc("", ifscHandler.Search).Methods(http.MethodGet)
+	ifscSubRoute.HandleFunc("/{code}", ifscHandler.Lookup).Methods(http.MethodGet)
 
 	disbursementService := d.serviceFactory.GetDisbursementService()
 	disbursementHandler := handlers.NewDisbursementHandler(disbursementService)
 
+	streamHandler := handlers.NewStreamHandler(d.serviceFactory.GetEventBus())
+
 	disbursementSubRoute := subRoute.PathPrefix("/disburse").Subrouter()
-	disbursementSubRoute.HandleFunc("", disbursementHandler.Disburse).Methods(http.MethodPost)
+	disbursementSubRoute.Handle("", disburse(http.HandlerFunc(disbursementHandler.Disburse))).
+		Methods(http.MethodPost)
+	// Registered ahead of /{id} so "stream" is not taken for an id.
+	disbursementSubRoute.Handle("/stream", disburse(http.HandlerFunc(streamHandler.Stream))).
+		Methods(http.MethodGet)
+	disbursementSubRoute.Handle("/{id}/stream", disburse(http.HandlerFunc(streamHandler.StreamDisbursement))).
+		Methods(http.MethodGet)
 	disbursementSubRoute.HandleFunc("/{id}", disbursementHandler.Fetch).Methods(http.MethodGet)
-	disbursementSubRoute.HandleFunc("/{id}/retry", disbursementHandler.Retry).
+	disbursementSubRoute.Handle("/{id}/retry", retry(http.HandlerFunc(disbursementHandler.Retry))).
+		Methods(http.MethodPost)
+	disbursementSubRoute.Handle("/{id}/cancel", disburse(http.HandlerFunc(disbursementHandler.Cancel))).
+		Methods(http.MethodPost)
+
+	batchService := d.serviceFactory.GetBatchService()
+	batchHandler := handlers.NewBatchHandler(batchService)
+
+	batchSubRoute := subRoute.PathPrefix("/batch").Subrouter()
+	batchSubRoute.Handle("", disburse(http.HandlerFunc(batchHandler.Create))).Methods(http.MethodPost)
+	batchSubRoute.Handle("/{id}", disburse(http.HandlerFunc(batchHandler.Get))).Methods(http.MethodGet)
+	batchSubRoute.Handle("/{id}/results", disburse(http.HandlerFunc(batchHandler.Results))).Methods(http.MethodGet)
+
+	adminHandler := handlers.NewAdminHandler(d.serviceFactory.GetAdminService())
+
+	adminSubRoute := subRoute.PathPrefix("/admin/disburse").Subrouter()
+	adminSubRoute.Use(admin)
+	adminSubRoute.Handle("/{id}/success", adminOnly(http.HandlerFunc(adminHandler.MarkSuccess))).
+		Methods(http.MethodPost)
+	adminSubRoute.Handle("/{id}/fail", adminOnly(http.HandlerFunc(adminHandler.MarkFailed))).
+		Methods(http.MethodPost)
+	adminSubRoute.Handle("/{id}/channel", adminOnly(http.HandlerFunc(adminHandler.ForceChannel))).
+		Methods(http.MethodPut)
+	adminSubRoute.Handle("/{id}/requeue", anyOperator(http.HandlerFunc(adminHandler.Requeue))).
+		Methods(http.MethodPost)
+	adminSubRoute.Handle("/{id}/reset-retries", anyOperator(http.HandlerFunc(adminHandler.ResetRetries))).
+		Methods(http.MethodPost)
+	adminSubRoute.Handle("/{id}/audit", anyOperator(http.HandlerFunc(adminHandler.AuditTrail))).
+		Methods(http.MethodGet)
+	adminSubRoute.Handle("/{id}/beneficiary", adminOnly(http.HandlerFunc(adminHandler.CorrectBeneficiary))).
+		Methods(http.MethodPut)
+	subRoute.Handle("/admin/loan/{id}/beneficiary-history",
+		admin(anyOperator(http.HandlerFunc(adminHandler.BeneficiaryHistory))),
+	).Methods(http.MethodGet)
+
+	deadLetterHandler := handlers.NewDeadLetterHandler(d.serviceFactory.GetDeadLetterService())
+
+	deadLetterSubRoute := subRoute.PathPrefix("/admin/dead-letters").Subrouter()
+	deadLetterSubRoute.Use(admin)
+	deadLetterSubRoute.Handle("", anyOperator(http.HandlerFunc(deadLetterHandler.List))).
+		Methods(http.MethodGet)
+	deadLetterSubRoute.Handle("/export", anyOperator(http.HandlerFunc(deadLetterHandler.Export))).
+		Methods(http.MethodGet)
+	deadLetterSubRoute.Handle("/requeue", anyOperator(http.HandlerFunc(deadLetterHandler.Requeue))).
+		Methods(http.MethodPost)
+	deadLetterSubRoute.Handle("/close", adminOnly(http.HandlerFunc(deadLetterHandler.Close))).
+		Methods(http.MethodPost)
+
+	webhookHandler := handlers.NewWebhookHandler(d.serviceFactory.GetWebhookService())
+
+	webhookSubRoute := subRoute.PathPrefix("/webhooks").Subrouter()
+	webhookSubRoute.Handle("", disburse(http.HandlerFunc(webhookHandler.Subscribe))).
+		Methods(http.MethodPost)
+	webhookSubRoute.Handle("", disburse(http.HandlerFunc(webhookHandler.List))).Methods(http.MethodGet)
+	webhookSubRoute.Handle("/{id}", disburse(http.HandlerFunc(webhookHandler.Unsubscribe))).
+		Methods(http.MethodDelete)
+	webhookSubRoute.Handle("/{id}/deliveries", disburse(http.HandlerFunc(webhookHandler.Deliveries))).
+		Methods(http.MethodGet)
+	webhookSubRoute.Handle("/deliveries/{id}/redeliver", disburse(http.HandlerFunc(webhookHandler.Redeliver))).
 		Methods(http.MethodPost)
 
 	paymentService := d.serviceFactory.GetPaymentService()
 	paymentHandler := handlers.NewPaymentHandler(paymentService)
 
 	paymentSubRoute := subRoute.PathPrefix("/payment").Subrouter()
-	paymentSubRoute.HandleFunc("/notify", paymentHandler.ProcessPayment).Methods(http.MethodPost)
+	paymentSubRoute.Handle("/notify",
+		middlewares.RequirePermission(auth.PermissionNotify)(http.HandlerFunc(paymentHandler.ProcessPayment)),
+	).Methods(http.MethodPost)
+
+	reconciliationHandler := handlers.NewReconciliationHandler(d.serviceFactory.GetReconciliationService())
+
+	subRoute.Handle("/reconciliation",
+		middlewares.RequirePermission(auth.PermissionReconciliation)(http.HandlerFunc(reconciliationHandler.Reconcile)),
+	).Methods(http.MethodPost)
 
 	return router
 }
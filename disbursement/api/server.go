@@ -3,7 +3,9 @@ package api
 import (
 	"context"
 	"loan-disbursement-service/api/services"
+	"loan-disbursement-service/ratelimit"
 	"net/http"
+	"shared/authn"
 
 	"github.com/rs/zerolog/log"
 )
@@ -12,13 +14,24 @@ type DisbursementServer struct {
 	port           string
 	server         *http.Server
 	serviceFactory *services.ServiceFactory
+	limiter        *ratelimit.Limiter
+	authenticators []authn.Authenticator
 }
 
-func New(port string, serviceFactory *services.ServiceFactory) *DisbursementServer {
+// New serves the API to callers one of authenticators admits, at the rates
+// limiter allows them.
+func New(
+	port string,
+	serviceFactory *services.ServiceFactory,
+	limiter *ratelimit.Limiter,
+	authenticators ...authn.Authenticator,
+) *DisbursementServer {
 	return &DisbursementServer{
 		port:           port,
 		server:         nil,
 		serviceFactory: serviceFactory,
+		limiter:        limiter,
+		authenticators: authenticators,
 	}
 }
 
@@ -27,6 +40,9 @@ func (d *DisbursementServer) Serve() error {
 		Addr:    ":" + d.port,
 		Handler: d.routes(),
 	}
+	// Event streams stay open until the client leaves, so they are ended
+	// here or shutdown would wait on them.
+	server.RegisterOnShutdown(d.serviceFactory.GetEventBus().Close)
 	d.server = server
 
 	log.Info().Msgf("Disbursement server started at %s", d.port)
@@ -0,0 +1,707 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"loan-disbursement-service/config"
+	"loan-disbursement-service/db"
+	"loan-disbursement-service/db/daos"
+	"loan-disbursement-service/db/schema"
+	"loan-disbursement-service/events"
+	"loan-disbursement-service/models"
+	"loan-disbursement-service/utils"
+	"regexp"
+	"shared/ifsc"
+	"shared/logging"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// UTRs are 16 characters for NEFT and 12 digit RRNs for IMPS and UPI; some
+// banks pad them, so anything alphanumeric in between is accepted.
+var utrPattern = regexp.MustCompile(`^[A-Za-z0-9]{12,22}$`)
+
+// AdminService holds the manual levers operations use to unstick
+// disbursements. Every action is recorded in the audit trail with the
+// operator and their reason.
+type AdminService interface {
+	MarkSuccess(
+		ctx context.Context,
+		operator models.Operator,
+		disbursementId string,
+		req models.MarkSuccessRequest,
+	) (*models.DisbursementResponse, error)
+	MarkFailed(
+		ctx context.Context,
+		operator models.Operator,
+		disbursementId string,
+		req models.AdminActionRequest,
+	) (*models.DisbursementResponse, error)
+	Requeue(
+		ctx context.Context,
+		operator models.Operator,
+		disbursementId string,
+		req models.AdminActionRequest,
+	) (*models.DisbursementResponse, error)
+	ForceChannel(
+		ctx context.Context,
+		operator models.Operator,
+		disbursementId string,
+		req models.ForceChannelRequest,
+	) (*models.DisbursementResponse, error)
+	ResetRetries(
+		ctx context.Context,
+		operator models.Operator,
+		disbursementId string,
+		req models.AdminActionRequest,
+	) (*models.DisbursementResponse, error)
+	CorrectBeneficiary(
+		ctx context.Context,
+		operator models.Operator,
+		disbursementId string,
+		req models.BeneficiaryCorrectionRequest,
+	) (*models.DisbursementResponse, error)
+	AuditTrail(ctx context.Context, disbursementId string) ([]models.AuditEntry, error)
+	BeneficiaryHistory(ctx context.Context, loanId string) ([]models.BeneficiaryChange, error)
+}
+
+type AdminServiceImpl struct {
+	db                 *db.Database
+	idGenerator        utils.IdGenerator
+	disbursement       daos.DisbursementRepository
+	transaction        daos.TransactionRepository
+	loan               daos.LoanRepository
+	beneficiary        daos.BeneficiaryRepository
+	audit              daos.AuditRepository
+	deadLetter         daos.DeadLetterRepository
+	beneficiaryChange  daos.BeneficiaryChangeRepository
+	paymentService     PaymentService
+	beneficiaryService BeneficiaryService
+	nameMatch          NameMatchPolicy
+	webhook            WebhookService
+	directory          *ifsc.Directory
+	bus                *events.Bus
+	paymentChan        chan string
+	settings           *config.Store
+}
+
+func NewAdminService(
+	database *db.Database,
+	idGenerator utils.IdGenerator,
+	disbursement daos.DisbursementRepository,
+	transaction daos.TransactionRepository,
+	loan daos.LoanRepository,
+	beneficiary daos.BeneficiaryRepository,
+	audit daos.AuditRepository,
+	deadLetter daos.DeadLetterRepository,
+	beneficiaryChange daos.BeneficiaryChangeRepository,
+	paymentService PaymentService,
+	beneficiaryService BeneficiaryService,
+	nameMatch NameMatchPolicy,
+	webhook WebhookService,
+	directory *ifsc.Directory,
+	bus *events.Bus,
+	paymentChan chan string,
+	settings *config.Store,
+) AdminService {
+	return &AdminServiceImpl{
+		db:                 database,
+		idGenerator:        idGenerator,
+		disbursement:       disbursement,
+		transaction:        transaction,
+		loan:               loan,
+		beneficiary:        beneficiary,
+		audit:              audit,
+		deadLetter:         deadLetter,
+		beneficiaryChange:  beneficiaryChange,
+		paymentService:     paymentService,
+		beneficiaryService: beneficiaryService,
+		nameMatch:          nameMatch,
+		webhook:            webhook,
+		directory:          directory,
+		bus:                bus,
+		paymentChan:        paymentChan,
+		settings:           settings,
+	}
+}
+
+// MarkSuccess settles a disbursement the bank confirmed outside the gateway.
+// It records a transaction carrying the bank's UTR and then settles through
+// the payment service, so the loan is credited and the repayment schedule
+// generated exactly as for a gateway success. The loan a failed
+// disbursement released is reopened in the same database transaction.
+func (a *AdminServiceImpl) MarkSuccess(
+	ctx context.Context,
+	operator models.Operator,
+	disbursementId string,
+	req models.MarkSuccessRequest,
+) (*models.DisbursementResponse, error) {
+	if err := requireReason(req.Reason); err != nil {
+		return nil, err
+	}
+	utr := strings.TrimSpace(req.UTR)
+	if !utrPattern.MatchString(utr) {
+		return nil, models.INVALID_UTR
+	}
+	settledAt := time.Now()
+	if req.SettledAt != nil {
+		if req.SettledAt.After(settledAt) {
+			return nil, models.INVALID_SETTLED_AT
+		}
+		settledAt = *req.SettledAt
+	}
+
+	disbursement, err := a.load(ctx, disbursementId,
+		models.DisbursementStatusProcessing,
+		models.DisbursementStatusSuspended,
+		models.DisbursementStatusFailed,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("marked successful by %s: %s", operator.Id, req.Reason)
+	transaction, err := a.transaction.Create(ctx, schema.Transaction{
+		Id:             a.idGenerator.GenerateTransactionId(),
+		DisbursementId: disbursement.Id,
+		ReferenceId:    a.idGenerator.GenerateReferenceId(),
+		Channel:        disbursement.Channel,
+		Amount:         disbursement.Amount,
+		Status:         models.TransactionStatusInitiated,
+		Message:        &message,
+		UTR:            &utr,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	}
+	settleCtx := logging.With(ctx, logging.Fields{
+		LoanId:         disbursement.LoanId,
+		DisbursementId: disbursement.Id,
+		TransactionId:  transaction.Id,
+		ReferenceId:    transaction.ReferenceId,
+		Channel:        string(disbursement.Channel),
+	})
+	err = a.paymentService.HandleSuccess(settleCtx, disbursement, transaction.Id, disbursement.Channel, settledAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to settle disbursement: %w", err)
+	}
+
+	return a.record(ctx, operator, models.AdminActionMarkSuccess, disbursement,
+		models.DisbursementStatusSuccess, req.Reason,
+		map[string]any{"utr": utr, "transaction_id": transaction.Id},
+	)
+}
+
+// MarkFailed gives up on a disbursement and releases its loan for another
+// disbursement. The disbursement lands in the dead-letter queue like any
+// other permanent failure; the status change, the loan release, the dead
+// letter and the webhook event are written in one database transaction. A transfer still in
+// flight at the gateway is not recalled.
+func (a *AdminServiceImpl) MarkFailed(
+	ctx context.Context,
+	operator models.Operator,
+	disbursementId string,
+	req models.AdminActionRequest,
+) (*models.DisbursementResponse, error) {
+	if err := requireReason(req.Reason); err != nil {
+		return nil, err
+	}
+
+	disbursement, err := a.load(ctx, disbursementId,
+		models.DisbursementStatusInitiated,
+		models.DisbursementStatusProcessing,
+		models.DisbursementStatusSuspended,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = failDisbursement(ctx, a.db, a.loan, a.disbursement, a.deadLetter, a.webhook, disbursement,
+		models.FailureCategoryManual, req.Reason,
+	)
+	if err != nil {
+		return nil, err
+	}
+	publishStatus(a.bus, disbursement, models.DisbursementStatusFailed, disbursement.Channel, disbursement.RetryCount, req.Reason)
+
+	return a.record(ctx, operator, models.AdminActionMarkFailed, disbursement,
+		models.DisbursementStatusFailed, req.Reason, nil,
+	)
+}
+
+// Requeue sends a disbursement to the workers now rather than after its retry
+// backoff.
+func (a *AdminServiceImpl) Requeue(
+	ctx context.Context,
+	operator models.Operator,
+	disbursementId string,
+	req models.AdminActionRequest,
+) (*models.DisbursementResponse, error) {
+	if err := requireReason(req.Reason); err != nil {
+		return nil, err
+	}
+
+	disbursement, err := a.load(ctx, disbursementId,
+		models.DisbursementStatusInitiated,
+		models.DisbursementStatusSuspended,
+		models.DisbursementStatusFailed,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = requeue(ctx, a.loan, a.disbursement, a.deadLetter, a.bus, a.paymentChan, disbursement,
+		operator.Id, req.Reason,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.record(ctx, operator, models.AdminActionRequeue, disbursement,
+		models.DisbursementStatusInitiated, req.Reason, nil,
+	)
+}
+
+// ForceChannel pins the channel every later attempt uses, overriding the
+// amount and retry based choice. The channel must still take the amount and
+// the beneficiary's branch must be on it. UPI still falls back to IMPS while
+// the gateway reports UPI down.
+func (a *AdminServiceImpl) ForceChannel(
+	ctx context.Context,
+	operator models.Operator,
+	disbursementId string,
+	req models.ForceChannelRequest,
+) (*models.DisbursementResponse, error) {
+	if err := requireReason(req.Reason); err != nil {
+		return nil, err
+	}
+	switch req.Channel {
+	case models.PaymentChannelUPI, models.PaymentChannelIMPS, models.PaymentChannelNEFT:
+	default:
+		return nil, models.INVALID_PAYMENT_CHANNEL
+	}
+
+	disbursement, err := a.load(ctx, disbursementId,
+		models.DisbursementStatusInitiated,
+		models.DisbursementStatusSuspended,
+		models.DisbursementStatusFailed,
+		models.DisbursementStatusScheduled,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.checkChannel(ctx, disbursement, req.Channel); err != nil {
+		return nil, err
+	}
+
+	// The channel column decides which worker picks the disbursement up, so
+	// it moves along with the forced channel. Only from the status it was
+	// read in, so a worker that picked it up meanwhile keeps its channel.
+	updated, err := a.disbursement.UpdateIfStatus(ctx, disbursement.Id, disbursement.Status,
+		map[string]any{
+			"forced_channel": req.Channel,
+			"channel":        req.Channel,
+			"updated_at":     time.Now(),
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update disbursement: %w", err)
+	}
+	if !updated {
+		return nil, fmt.Errorf("%w: disbursement is no longer %s", models.DISBURSEMENT_STATUS_CHANGED, disbursement.Status)
+	}
+	// Not a status change, but streams show the channel the disbursement
+	// now goes out on.
+	a.bus.Publish(events.Event{
+		Type:           events.TypeStatus,
+		DisbursementId: disbursement.Id,
+		LoanId:         disbursement.LoanId,
+		ClientId:       disbursement.ClientId,
+		Status:         disbursement.Status,
+		Channel:        req.Channel,
+		Amount:         disbursement.Amount,
+		RetryCount:     disbursement.RetryCount,
+	})
+	// An initiated disbursement moved off NEFT would otherwise wait for a
+	// payment worker message it never gets.
+	if disbursement.Status == models.DisbursementStatusInitiated &&
+		req.Channel != models.PaymentChannelNEFT {
+		queuePayment(ctx, a.paymentChan, disbursement.Id)
+	}
+
+	return a.record(ctx, operator, models.AdminActionForceChannel, disbursement,
+		disbursement.Status, req.Reason,
+		map[string]any{"from_channel": disbursement.Channel, "to_channel": req.Channel},
+	)
+}
+
+// checkChannel refuses a channel whose limit is below the disbursement's
+// amount, or that the beneficiary's branch is not on. A branch missing from
+// the IFSC directory is left to the gateway, as routeChannel leaves it.
+func (a *AdminServiceImpl) checkChannel(
+	ctx context.Context,
+	disbursement *schema.Disbursement,
+	channel models.PaymentChannel,
+) error {
+	lowest := channelForAmount(a.settings.Get().Channels, disbursement.Amount)
+	if slices.Index(channelOrder, channel) < slices.Index(channelOrder, lowest) {
+		return fmt.Errorf("%w: %.2f needs %s or above", models.CHANNEL_OVER_LIMIT, disbursement.Amount, lowest)
+	}
+
+	loan, err := a.loan.Get(ctx, disbursement.LoanId)
+	if err != nil {
+		return fmt.Errorf("failed to get loan: %w", err)
+	}
+	if loan.BeneficiaryId == nil {
+		return nil
+	}
+	beneficiary, err := a.beneficiary.GetById(ctx, *loan.BeneficiaryId)
+	if err != nil {
+		return fmt.Errorf("failed to get beneficiary: %w", err)
+	}
+	branch, ok := a.directory.Resolve(beneficiary.IFSC)
+	if ok && !branch.Supports(string(channel)) {
+		return fmt.Errorf("%w: %s is not on %s", models.CHANNEL_NOT_AT_BRANCH, beneficiary.IFSC, channel)
+	}
+	return nil
+}
+
+// ResetRetries clears the retry count, which gives the disbursement its full
+// retry budget back and returns channel selection to the first attempt's.
+func (a *AdminServiceImpl) ResetRetries(
+	ctx context.Context,
+	operator models.Operator,
+	disbursementId string,
+	req models.AdminActionRequest,
+) (*models.DisbursementResponse, error) {
+	if err := requireReason(req.Reason); err != nil {
+		return nil, err
+	}
+
+	disbursement, err := a.load(ctx, disbursementId,
+		models.DisbursementStatusInitiated,
+		models.DisbursementStatusSuspended,
+		models.DisbursementStatusFailed,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := a.disbursement.UpdateIfStatus(ctx, disbursement.Id, disbursement.Status,
+		map[string]any{
+			"retry_count": 0,
+			"updated_at":  time.Now(),
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update disbursement: %w", err)
+	}
+	if !updated {
+		return nil, fmt.Errorf("%w: disbursement is no longer %s", models.DISBURSEMENT_STATUS_CHANGED, disbursement.Status)
+	}
+
+	return a.record(ctx, operator, models.AdminActionResetRetries, disbursement,
+		disbursement.Status, req.Reason,
+		map[string]any{"from_retry_count": disbursement.RetryCount},
+	)
+}
+
+// CorrectBeneficiary fixes a disbursement that failed on the payee's details,
+// such as an invalid IFSC or a closed account. The corrected account is
+// penny dropped and name matched like a new registration, then the loan is
+// relinked to it and the disbursement reopened with its full retry budget in
+// one database transaction, and queued once that commits. A corrected payee
+// not yet verified is only paid when the operator overrides it explicitly.
+func (a *AdminServiceImpl) CorrectBeneficiary(
+	ctx context.Context,
+	operator models.Operator,
+	disbursementId string,
+	req models.BeneficiaryCorrectionRequest,
+) (*models.DisbursementResponse, error) {
+	if err := requireReason(req.Reason); err != nil {
+		return nil, err
+	}
+	payee := models.BeneficiaryRequest{
+		Name:          strings.TrimSpace(req.Name),
+		AccountNumber: strings.TrimSpace(req.AccountNumber),
+		IFSCCode:      strings.ToUpper(strings.TrimSpace(req.IFSCCode)),
+		Bank:          strings.TrimSpace(req.Bank),
+	}
+	if payee.Name == "" || payee.AccountNumber == "" ||
+		payee.IFSCCode == "" || payee.Bank == "" {
+		return nil, models.BENEFICIARY_DETAILS_REQUIRED
+	}
+	overrideReason := strings.TrimSpace(req.OverrideReason)
+	if req.Override && overrideReason == "" {
+		return nil, models.OVERRIDE_APPROVAL_REQUIRED
+	}
+
+	disbursement, err := a.load(ctx, disbursementId, models.DisbursementStatusFailed)
+	if err != nil {
+		return nil, err
+	}
+	loan, err := a.loan.Get(ctx, disbursement.LoanId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loan: %w", err)
+	}
+	var previous *schema.Beneficiary
+	if loan.BeneficiaryId != nil {
+		previous, err = a.beneficiary.GetById(ctx, *loan.BeneficiaryId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get beneficiary: %w", err)
+		}
+	}
+
+	// The penny drop calls the gateway, so it stays outside the transaction.
+	corrected, err := a.beneficiaryService.Create(ctx, payee)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register corrected beneficiary: %w", err)
+	}
+	if previous != nil && corrected.Id == previous.Id {
+		return nil, models.BENEFICIARY_UNCHANGED
+	}
+	if corrected.Status == models.BeneficiaryStatusRejected {
+		reason := ""
+		if corrected.StatusReason != nil {
+			reason = *corrected.StatusReason
+		}
+		return nil, fmt.Errorf("beneficiary %s: %w: %s", corrected.Id, models.BENEFICIARY_REJECTED, reason)
+	}
+	if corrected.PennyDroppedAt == nil {
+		return nil, fmt.Errorf("beneficiary %s: %w", corrected.Id, models.BENEFICIARY_UNVERIFIABLE)
+	}
+	nameMatch := a.nameMatch.Evaluate(corrected.Name, loan.BorrowerName, corrected.RegisteredName)
+	if nameMatch.Decision == models.NameMatchDecisionBlock {
+		return nil, fmt.Errorf("beneficiary %s: %w", corrected.Id, models.NAME_MISMATCH)
+	}
+	override := corrected.Status != models.BeneficiaryStatusVerified && corrected.OverriddenAt == nil
+	if override && !req.Override {
+		return nil, fmt.Errorf("beneficiary %s: %w", corrected.Id, models.CORRECTION_OVERRIDE_REQUIRED)
+	}
+
+	change := schema.BeneficiaryChange{
+		Id:             a.idGenerator.GenerateBeneficiaryChangeId(),
+		LoanId:         loan.Id,
+		DisbursementId: disbursement.Id,
+		BeneficiaryId:  corrected.Id,
+		OperatorId:     operator.Id,
+		Reason:         req.Reason,
+	}
+	if previous != nil {
+		change.PreviousBeneficiaryId = &previous.Id
+		change.PreviousName = previous.Name
+		change.PreviousAccount = previous.Account
+		change.PreviousIFSC = previous.IFSC
+		change.PreviousBank = previous.Bank
+	}
+
+	err = a.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		beneficiaries := a.beneficiary.WithTx(tx)
+		loans := a.loan.WithTx(tx)
+		disbursements := a.disbursement.WithTx(tx)
+
+		if override {
+			err := beneficiaries.Update(ctx, corrected.Id, map[string]any{
+				"override_reason": overrideReason,
+				"overridden_by":   operator.Id,
+				"overridden_at":   time.Now(),
+				"updated_at":      time.Now(),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to update beneficiary: %w", err)
+			}
+		}
+		if _, err := loans.Update(ctx, loan.Id, map[string]any{"beneficiary_id": corrected.Id}); err != nil {
+			return fmt.Errorf("failed to update loan: %w", err)
+		}
+		if err := a.beneficiaryChange.WithTx(tx).Create(ctx, change); err != nil {
+			return fmt.Errorf("failed to record beneficiary change: %w", err)
+		}
+
+		err := disbursements.Update(ctx, disbursement.Id, map[string]any{
+			"retry_count":           0,
+			"name_match_decision":   nameMatch.Decision,
+			"borrower_name_score":   nameMatch.BorrowerScore,
+			"registered_name_score": nameMatch.RegisteredScore,
+			"updated_at":            time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update disbursement: %w", err)
+		}
+		// The correction is the fix the dead letter was waiting for.
+		return reopen(ctx, loans, disbursements, a.deadLetter.WithTx(tx), disbursement,
+			operator.Id, req.Reason,
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	enqueue(ctx, a.bus, a.paymentChan, disbursement)
+
+	details := map[string]any{
+		"to_beneficiary_id":   corrected.Id,
+		"change_id":           change.Id,
+		"name_match_decision": nameMatch.Decision,
+	}
+	if previous != nil {
+		details["from_beneficiary_id"] = previous.Id
+	}
+	if override {
+		details["override_reason"] = overrideReason
+	}
+	return a.record(ctx, operator, models.AdminActionCorrectBeneficiary, disbursement,
+		models.DisbursementStatusInitiated, req.Reason, details,
+	)
+}
+
+func (a *AdminServiceImpl) AuditTrail(
+	ctx context.Context,
+	disbursementId string,
+) ([]models.AuditEntry, error) {
+	if _, err := a.disbursement.Get(ctx, disbursementId); err != nil {
+		return nil, err
+	}
+
+	entries, err := a.audit.ListByEntity(ctx, models.AuditEntityDisbursement, disbursementId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit trail: %w", err)
+	}
+
+	trail := make([]models.AuditEntry, len(entries))
+	for i, entry := range entries {
+		trail[i] = models.AuditEntry{
+			AuditId:      entry.Id,
+			Action:       entry.Action,
+			OperatorId:   entry.OperatorId,
+			OperatorRole: entry.OperatorRole,
+			Reason:       entry.Reason,
+			FromStatus:   entry.FromStatus,
+			ToStatus:     entry.ToStatus,
+			CreatedAt:    entry.CreatedAt,
+		}
+		if entry.Details != nil {
+			if err := json.Unmarshal([]byte(*entry.Details), &trail[i].Details); err != nil {
+				return nil, fmt.Errorf("failed to decode audit details: %w", err)
+			}
+		}
+	}
+	return trail, nil
+}
+
+// BeneficiaryHistory lists the corrections made to a loan's beneficiary,
+// oldest first.
+func (a *AdminServiceImpl) BeneficiaryHistory(
+	ctx context.Context,
+	loanId string,
+) ([]models.BeneficiaryChange, error) {
+	if _, err := a.loan.Get(ctx, loanId); err != nil {
+		return nil, err
+	}
+
+	changes, err := a.beneficiaryChange.ListByLoan(ctx, loanId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list beneficiary history: %w", err)
+	}
+
+	history := make([]models.BeneficiaryChange, len(changes))
+	for i, change := range changes {
+		history[i] = models.BeneficiaryChange{
+			ChangeId:              change.Id,
+			LoanId:                change.LoanId,
+			DisbursementId:        change.DisbursementId,
+			PreviousBeneficiaryId: change.PreviousBeneficiaryId,
+			Previous: models.Beneficiary{
+				Name:    change.PreviousName,
+				Account: change.PreviousAccount,
+				IFSC:    change.PreviousIFSC,
+				Bank:    change.PreviousBank,
+			},
+			BeneficiaryId: change.BeneficiaryId,
+			OperatorId:    change.OperatorId,
+			Reason:        change.Reason,
+			CreatedAt:     change.CreatedAt,
+		}
+	}
+	return history, nil
+}
+
+// load fetches the disbursement and checks the action is allowed from its
+// current status.
+func (a *AdminServiceImpl) load(
+	ctx context.Context,
+	disbursementId string,
+	allowed ...models.DisbursementStatus,
+) (*schema.Disbursement, error) {
+	disbursement, err := a.disbursement.Get(ctx, disbursementId)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(allowed, disbursement.Status) {
+		return nil, fmt.Errorf("%w: disbursement is %s", models.ACTION_NOT_ALLOWED, disbursement.Status)
+	}
+	return disbursement, nil
+}
+
+// record writes the audit entry once the action has been applied. A failure
+// here is returned so the operator knows the trail is missing an entry, even
+// though the action itself went through.
+func (a *AdminServiceImpl) record(
+	ctx context.Context,
+	operator models.Operator,
+	action models.AdminAction,
+	disbursement *schema.Disbursement,
+	to models.DisbursementStatus,
+	reason string,
+	details map[string]any,
+) (*models.DisbursementResponse, error) {
+	entry := schema.AuditLog{
+		Id:           a.idGenerator.GenerateAuditId(),
+		EntityType:   models.AuditEntityDisbursement,
+		EntityId:     disbursement.Id,
+		Action:       action,
+		OperatorId:   operator.Id,
+		OperatorRole: operator.Role,
+		Reason:       reason,
+		FromStatus:   disbursement.Status,
+		ToStatus:     to,
+	}
+	if details != nil {
+		encoded, err := json.Marshal(details)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode audit details: %w", err)
+		}
+		value := string(encoded)
+		entry.Details = &value
+	}
+	if err := a.audit.Create(ctx, entry); err != nil {
+		return nil, fmt.Errorf("%s applied but audit entry not recorded: %w", action, err)
+	}
+
+	ctx = logging.With(ctx, logging.Fields{
+		LoanId:         disbursement.LoanId,
+		DisbursementId: disbursement.Id,
+	})
+	log.Ctx(ctx).Info().
+		Str("action", string(action)).
+		Str("operator_id", operator.Id).
+		Str("from", string(disbursement.Status)).
+		Str("to", string(to)).
+		Msg("admin action applied")
+	return &models.DisbursementResponse{
+		DisbursementId: disbursement.Id,
+		Status:         to,
+		Message:        fmt.Sprintf("%s recorded as %s", action, entry.Id),
+	}, nil
+}
+
+func requireReason(reason string) error {
+	if strings.TrimSpace(reason) == "" {
+		return models.REASON_REQUIRED
+	}
+	return nil
+}
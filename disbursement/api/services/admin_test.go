@@ -0,0 +1,1079 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"loan-disbursement-service/config"
+	"loan-disbursement-service/db/schema"
+	"loan-disbursement-service/events"
+	"loan-disbursement-service/models"
+	db_test "loan-disbursement-service/test/db"
+	utils_test "loan-disbursement-service/test/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockPaymentService struct {
+	mock.Mock
+}
+
+func (m *MockPaymentService) Process(ctx context.Context, disbursement *schema.Disbursement) error {
+	args := m.Called(ctx, disbursement)
+	return args.Error(0)
+}
+
+func (m *MockPaymentService) HandleNotification(
+	ctx context.Context,
+	notification models.PaymentNotificationRequest,
+) error {
+	args := m.Called(ctx, notification)
+	return args.Error(0)
+}
+
+func (m *MockPaymentService) HandleFailure(
+	ctx context.Context,
+	disbursement *schema.Disbursement,
+	transaction *schema.Transaction,
+	channel models.PaymentChannel,
+	err error,
+) error {
+	args := m.Called(ctx, disbursement, transaction, channel, err)
+	return args.Error(0)
+}
+
+func (m *MockPaymentService) HandleSuccess(
+	ctx context.Context,
+	disbursement *schema.Disbursement,
+	transactionId string,
+	channel models.PaymentChannel,
+	settledAt time.Time,
+) error {
+	args := m.Called(ctx, disbursement, transactionId, channel, settledAt)
+	return args.Error(0)
+}
+
+func (m *MockPaymentService) GeneratePendingSchedules(ctx context.Context, limit int) (int, error) {
+	args := m.Called(ctx, limit)
+	return args.Int(0), args.Error(1)
+}
+
+type MockBeneficiaryService struct {
+	mock.Mock
+}
+
+func (m *MockBeneficiaryService) Create(
+	ctx context.Context,
+	req models.BeneficiaryRequest,
+) (*models.BeneficiaryResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BeneficiaryResponse), args.Error(1)
+}
+
+func (m *MockBeneficiaryService) Register(
+	ctx context.Context,
+	req models.BeneficiaryRequest,
+) (*models.BeneficiaryResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BeneficiaryResponse), args.Error(1)
+}
+
+func (m *MockBeneficiaryService) PennyDropPending(ctx context.Context, limit int) (int, error) {
+	args := m.Called(ctx, limit)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockBeneficiaryService) Update(
+	ctx context.Context,
+	beneficiaryId string,
+	req models.BeneficiaryRequest,
+) (*models.BeneficiaryResponse, error) {
+	args := m.Called(ctx, beneficiaryId, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BeneficiaryResponse), args.Error(1)
+}
+
+func (m *MockBeneficiaryService) List(ctx context.Context) ([]models.BeneficiaryResponse, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.BeneficiaryResponse), args.Error(1)
+}
+
+func (m *MockBeneficiaryService) Get(
+	ctx context.Context,
+	beneficiaryId string,
+) (*models.BeneficiaryResponse, error) {
+	args := m.Called(ctx, beneficiaryId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BeneficiaryResponse), args.Error(1)
+}
+
+func (m *MockBeneficiaryService) Delete(ctx context.Context, beneficiaryId string) error {
+	args := m.Called(ctx, beneficiaryId)
+	return args.Error(0)
+}
+
+func (m *MockBeneficiaryService) Verify(
+	ctx context.Context,
+	beneficiaryId string,
+	req models.BeneficiaryVerificationRequest,
+) (*models.BeneficiaryResponse, error) {
+	args := m.Called(ctx, beneficiaryId, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BeneficiaryResponse), args.Error(1)
+}
+
+func (m *MockBeneficiaryService) Override(
+	ctx context.Context,
+	operator models.Operator,
+	beneficiaryId string,
+	req models.BeneficiaryOverrideRequest,
+) (*models.BeneficiaryResponse, error) {
+	args := m.Called(ctx, operator, beneficiaryId, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BeneficiaryResponse), args.Error(1)
+}
+
+type adminMocks struct {
+	idGenerator        *utils_test.MockIdGenerator
+	disbursement       *db_test.MockDisbursementRepository
+	transaction        *db_test.MockTransactionRepository
+	loan               *db_test.MockLoanRepository
+	beneficiary        *db_test.MockBeneficiaryRepository
+	audit              *db_test.MockAuditRepository
+	deadLetter         *db_test.MockDeadLetterRepository
+	beneficiaryChange  *db_test.MockBeneficiaryChangeRepository
+	paymentService     *MockPaymentService
+	beneficiaryService *MockBeneficiaryService
+	webhook            *MockWebhookService
+	bus                *events.Bus
+	paymentChan        chan string
+}
+
+func newAdminService(t *testing.T) (AdminService, adminMocks) {
+	settings := config.Default()
+	settings.Channels = config.Channels{UPILimit: 50000, IMPSLimit: 200000}
+	mocks := adminMocks{
+		idGenerator:        new(utils_test.MockIdGenerator),
+		disbursement:       new(db_test.MockDisbursementRepository),
+		transaction:        new(db_test.MockTransactionRepository),
+		loan:               new(db_test.MockLoanRepository),
+		beneficiary:        new(db_test.MockBeneficiaryRepository),
+		audit:              new(db_test.MockAuditRepository),
+		deadLetter:         new(db_test.MockDeadLetterRepository),
+		beneficiaryChange:  new(db_test.MockBeneficiaryChangeRepository),
+		paymentService:     new(MockPaymentService),
+		beneficiaryService: new(MockBeneficiaryService),
+		webhook:            newMockWebhookService(),
+		bus:                events.NewBus(events.DefaultHistorySize),
+		paymentChan:        make(chan string, 1),
+	}
+	service := NewAdminService(
+		setupMockDB(t),
+		mocks.idGenerator,
+		mocks.disbursement,
+		mocks.transaction,
+		mocks.loan,
+		mocks.beneficiary,
+		mocks.audit,
+		mocks.deadLetter,
+		mocks.beneficiaryChange,
+		mocks.paymentService,
+		mocks.beneficiaryService,
+		NewNameMatchPolicy(nil),
+		mocks.webhook,
+		newTestDirectory(t),
+		mocks.bus,
+		mocks.paymentChan,
+		config.NewStore("", settings),
+	)
+	return service, mocks
+}
+
+func auditEntry(action models.AdminAction, from, to models.DisbursementStatus) any {
+	return mock.MatchedBy(func(entry schema.AuditLog) bool {
+		return entry.Id == "AUD-123" &&
+			entry.EntityType == models.AuditEntityDisbursement &&
+			entry.EntityId == "DISB-123" &&
+			entry.Action == action &&
+			entry.OperatorId == "ops@example.com" &&
+			entry.OperatorRole == models.OperatorRoleAdmin &&
+			entry.FromStatus == from &&
+			entry.ToStatus == to
+	})
+}
+
+func TestAdminService_MarkSuccess(t *testing.T) {
+	ctx := context.Background()
+	operator := models.Operator{Id: "ops@example.com", Role: models.OperatorRoleAdmin}
+	req := models.MarkSuccessRequest{UTR: "HDFCN52025081400123", Reason: "confirmed with bank"}
+
+	t.Run("records the UTR and settles through the payment service", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+		disbursement := &schema.Disbursement{
+			Id:      "DISB-123",
+			LoanId:  "LOAN-123",
+			Amount:  50000,
+			Channel: models.PaymentChannelNEFT,
+			Status:  models.DisbursementStatusProcessing,
+		}
+
+		mocks.disbursement.On("Get", ctx, "DISB-123").Return(disbursement, nil).Once()
+		mocks.idGenerator.On("GenerateTransactionId").Return("TXN-123").Once()
+		mocks.idGenerator.On("GenerateReferenceId").Return("REF-123").Once()
+		mocks.idGenerator.On("GenerateAuditId").Return("AUD-123").Once()
+		mocks.transaction.On("Create", ctx, mock.MatchedBy(func(txn schema.Transaction) bool {
+			return txn.Id == "TXN-123" &&
+				txn.DisbursementId == "DISB-123" &&
+				txn.Channel == models.PaymentChannelNEFT &&
+				txn.Amount == 50000 &&
+				*txn.UTR == "HDFCN52025081400123"
+		})).Return(&schema.Transaction{Id: "TXN-123"}, nil).Once()
+		mocks.paymentService.On("HandleSuccess", mock.Anything, disbursement, "TXN-123", models.PaymentChannelNEFT, mock.AnythingOfType("time.Time")).
+			Return(nil).Once()
+		mocks.audit.On("Create", ctx, mock.MatchedBy(func(entry schema.AuditLog) bool {
+			return entry.Action == models.AdminActionMarkSuccess &&
+				entry.FromStatus == models.DisbursementStatusProcessing &&
+				entry.ToStatus == models.DisbursementStatusSuccess &&
+				entry.Reason == "confirmed with bank" &&
+				*entry.Details == `{"transaction_id":"TXN-123","utr":"HDFCN52025081400123"}`
+		})).Return(nil).Once()
+
+		response, err := service.MarkSuccess(ctx, operator, "DISB-123", req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.DisbursementStatusSuccess, response.Status)
+		mocks.transaction.AssertExpectations(t)
+		mocks.paymentService.AssertExpectations(t)
+		mocks.audit.AssertExpectations(t)
+	})
+
+	t.Run("settles a failed disbursement through the payment service", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+		disbursement := &schema.Disbursement{
+			Id:      "DISB-123",
+			LoanId:  "LOAN-123",
+			Channel: models.PaymentChannelIMPS,
+			Status:  models.DisbursementStatusFailed,
+		}
+
+		mocks.disbursement.On("Get", ctx, "DISB-123").Return(disbursement, nil).Once()
+		mocks.idGenerator.On("GenerateTransactionId").Return("TXN-123").Once()
+		mocks.idGenerator.On("GenerateReferenceId").Return("REF-123").Once()
+		mocks.idGenerator.On("GenerateAuditId").Return("AUD-123").Once()
+		mocks.transaction.On("Create", ctx, mock.Anything).Return(&schema.Transaction{Id: "TXN-123"}, nil).Once()
+		mocks.paymentService.On("HandleSuccess", mock.Anything, disbursement, "TXN-123", models.PaymentChannelIMPS, mock.AnythingOfType("time.Time")).
+			Return(nil).Once()
+		mocks.audit.On("Create", ctx, auditEntry(
+			models.AdminActionMarkSuccess,
+			models.DisbursementStatusFailed,
+			models.DisbursementStatusSuccess,
+		)).Return(nil).Once()
+
+		_, err := service.MarkSuccess(ctx, operator, "DISB-123", req)
+
+		assert.NoError(t, err)
+		// The loan is reopened inside the settlement's transaction.
+		mocks.loan.AssertNotCalled(t, "UpdateIfStatus")
+		mocks.paymentService.AssertExpectations(t)
+		mocks.audit.AssertExpectations(t)
+	})
+
+	t.Run("rejects malformed UTR", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+
+		_, err := service.MarkSuccess(ctx, operator, "DISB-123", models.MarkSuccessRequest{
+			UTR:    "UTR-1",
+			Reason: "confirmed with bank",
+		})
+
+		assert.ErrorIs(t, err, models.INVALID_UTR)
+		mocks.disbursement.AssertNotCalled(t, "Get")
+	})
+
+	t.Run("rejects settlement time in the future", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+		settledAt := time.Now().Add(time.Hour)
+
+		_, err := service.MarkSuccess(ctx, operator, "DISB-123", models.MarkSuccessRequest{
+			UTR:       "HDFCN52025081400123",
+			Reason:    "confirmed with bank",
+			SettledAt: &settledAt,
+		})
+
+		assert.ErrorIs(t, err, models.INVALID_SETTLED_AT)
+		mocks.disbursement.AssertNotCalled(t, "Get")
+	})
+
+	t.Run("rejects missing reason", func(t *testing.T) {
+		service, _ := newAdminService(t)
+
+		_, err := service.MarkSuccess(ctx, operator, "DISB-123", models.MarkSuccessRequest{
+			UTR: "HDFCN52025081400123",
+		})
+
+		assert.ErrorIs(t, err, models.REASON_REQUIRED)
+	})
+
+	t.Run("rejects disbursement that already succeeded", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+
+		mocks.disbursement.On("Get", ctx, "DISB-123").Return(&schema.Disbursement{
+			Id:     "DISB-123",
+			Status: models.DisbursementStatusSuccess,
+		}, nil).Once()
+
+		_, err := service.MarkSuccess(ctx, operator, "DISB-123", req)
+
+		assert.ErrorIs(t, err, models.ACTION_NOT_ALLOWED)
+		mocks.transaction.AssertNotCalled(t, "Create")
+	})
+
+	t.Run("returns not found for unknown disbursement", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+
+		mocks.disbursement.On("Get", ctx, "DISB-404").Return(nil, gorm.ErrRecordNotFound).Once()
+
+		_, err := service.MarkSuccess(ctx, operator, "DISB-404", req)
+
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
+
+func TestAdminService_MarkFailed(t *testing.T) {
+	ctx := context.Background()
+	operator := models.Operator{Id: "ops@example.com", Role: models.OperatorRoleAdmin}
+
+	t.Run("fails the disbursement and releases the loan", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+		subscription, _, _, _ := mocks.bus.Subscribe(events.Filter{}, 0)
+
+		mocks.disbursement.On("Get", ctx, "DISB-123").Return(&schema.Disbursement{
+			Id:     "DISB-123",
+			LoanId: "LOAN-123",
+			Status: models.DisbursementStatusSuspended,
+		}, nil).Once()
+		mocks.disbursement.On("UpdateIfStatus", ctx, "DISB-123", models.DisbursementStatusSuspended,
+			mock.MatchedBy(func(fields map[string]any) bool {
+				return fields["status"] == models.DisbursementStatusFailed &&
+					fields["last_error"] == "account closed"
+			}),
+		).Return(true, nil).Once()
+		mocks.loan.On("Get", ctx, "LOAN-123").
+			Return(&schema.Loan{Id: "LOAN-123", Status: models.LoanStatusDisbursementPending}, nil).Once()
+		mocks.loan.On("UpdateIfStatus", ctx, "LOAN-123", models.LoanStatusDisbursementPending, map[string]any{"status": models.LoanStatusSanctioned}).
+			Return(true, nil).Once()
+		mocks.deadLetter.On("Record", ctx, mock.MatchedBy(func(entry schema.DeadLetter) bool {
+			return entry.DisbursementId == "DISB-123" &&
+				entry.Category == models.FailureCategoryManual &&
+				entry.LastError == "account closed" &&
+				entry.Status == models.DeadLetterStatusOpen
+		})).Return(nil).Once()
+		mocks.idGenerator.On("GenerateAuditId").Return("AUD-123").Once()
+		mocks.audit.On("Create", ctx, auditEntry(
+			models.AdminActionMarkFailed,
+			models.DisbursementStatusSuspended,
+			models.DisbursementStatusFailed,
+		)).Return(nil).Once()
+
+		response, err := service.MarkFailed(ctx, operator, "DISB-123", models.AdminActionRequest{
+			Reason: "account closed",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.DisbursementStatusFailed, response.Status)
+		event := <-subscription.Events()
+		assert.Equal(t, models.DisbursementStatusFailed, event.Status)
+		assert.Equal(t, "account closed", event.Error)
+		mocks.disbursement.AssertExpectations(t)
+		mocks.loan.AssertExpectations(t)
+		mocks.deadLetter.AssertExpectations(t)
+		mocks.audit.AssertExpectations(t)
+	})
+
+	t.Run("reports action applied when audit write fails", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+
+		mocks.disbursement.On("Get", ctx, "DISB-123").Return(&schema.Disbursement{
+			Id:     "DISB-123",
+			LoanId: "LOAN-123",
+			Status: models.DisbursementStatusInitiated,
+		}, nil).Once()
+		mocks.disbursement.On("UpdateIfStatus", ctx, "DISB-123", models.DisbursementStatusInitiated, mock.Anything).
+			Return(true, nil).Once()
+		mocks.loan.On("Get", ctx, "LOAN-123").
+			Return(&schema.Loan{Id: "LOAN-123", Status: models.LoanStatusDisbursementPending}, nil).Once()
+		mocks.loan.On("UpdateIfStatus", ctx, "LOAN-123", models.LoanStatusDisbursementPending, mock.Anything).Return(true, nil).Once()
+		mocks.deadLetter.On("Record", ctx, mock.Anything).Return(nil).Once()
+		mocks.idGenerator.On("GenerateAuditId").Return("AUD-123").Once()
+		mocks.audit.On("Create", ctx, mock.Anything).Return(errors.New("database error")).Once()
+
+		_, err := service.MarkFailed(ctx, operator, "DISB-123", models.AdminActionRequest{
+			Reason: "account closed",
+		})
+
+		assert.ErrorContains(t, err, "mark_failed applied but audit entry not recorded")
+	})
+
+	t.Run("returns conflict when the status moved since it was read", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+
+		mocks.disbursement.On("Get", ctx, "DISB-123").Return(&schema.Disbursement{
+			Id:     "DISB-123",
+			LoanId: "LOAN-123",
+			Status: models.DisbursementStatusProcessing,
+		}, nil).Once()
+		mocks.disbursement.On("UpdateIfStatus", ctx, "DISB-123", models.DisbursementStatusProcessing, mock.Anything).
+			Return(false, nil).Once()
+
+		_, err := service.MarkFailed(ctx, operator, "DISB-123", models.AdminActionRequest{
+			Reason: "account closed",
+		})
+
+		assert.ErrorIs(t, err, models.DISBURSEMENT_STATUS_CHANGED)
+		mocks.loan.AssertNotCalled(t, "Get")
+		mocks.deadLetter.AssertNotCalled(t, "Record")
+		mocks.audit.AssertNotCalled(t, "Create")
+	})
+
+	t.Run("rejects failed disbursement", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+
+		mocks.disbursement.On("Get", ctx, "DISB-123").Return(&schema.Disbursement{
+			Id:     "DISB-123",
+			Status: models.DisbursementStatusFailed,
+		}, nil).Once()
+
+		_, err := service.MarkFailed(ctx, operator, "DISB-123", models.AdminActionRequest{
+			Reason: "account closed",
+		})
+
+		assert.ErrorIs(t, err, models.ACTION_NOT_ALLOWED)
+		mocks.disbursement.AssertNotCalled(t, "Update")
+	})
+}
+
+func TestAdminService_Requeue(t *testing.T) {
+	ctx := context.Background()
+	operator := models.Operator{Id: "ops@example.com", Role: models.OperatorRoleAdmin}
+
+	t.Run("initiates suspended disbursement and queues it without backoff", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+
+		mocks.disbursement.On("Get", ctx, "DISB-123").Return(&schema.Disbursement{
+			Id:      "DISB-123",
+			Channel: models.PaymentChannelUPI,
+			Status:  models.DisbursementStatusSuspended,
+		}, nil).Once()
+		mocks.disbursement.On("UpdateIfStatus", ctx, "DISB-123", models.DisbursementStatusSuspended,
+			mock.MatchedBy(func(fields map[string]any) bool {
+				return fields["status"] == string(models.DisbursementStatusInitiated)
+			}),
+		).Return(true, nil).Once()
+		mocks.idGenerator.On("GenerateAuditId").Return("AUD-123").Once()
+		mocks.audit.On("Create", ctx, auditEntry(
+			models.AdminActionRequeue,
+			models.DisbursementStatusSuspended,
+			models.DisbursementStatusInitiated,
+		)).Return(nil).Once()
+
+		response, err := service.Requeue(ctx, operator, "DISB-123", models.AdminActionRequest{
+			Reason: "gateway recovered",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.DisbursementStatusInitiated, response.Status)
+		assert.Equal(t, "DISB-123", <-mocks.paymentChan)
+		mocks.audit.AssertExpectations(t)
+	})
+
+	t.Run("reopens failed disbursement and resolves its dead letter", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+
+		mocks.disbursement.On("Get", ctx, "DISB-123").Return(&schema.Disbursement{
+			Id:      "DISB-123",
+			LoanId:  "LOAN-123",
+			Channel: models.PaymentChannelUPI,
+			Status:  models.DisbursementStatusFailed,
+		}, nil).Once()
+		mocks.loan.On("Get", ctx, "LOAN-123").
+			Return(&schema.Loan{Id: "LOAN-123", Status: models.LoanStatusSanctioned}, nil).Once()
+		mocks.loan.On("UpdateIfStatus", ctx, "LOAN-123", models.LoanStatusSanctioned, map[string]any{"status": models.LoanStatusDisbursementPending}).
+			Return(true, nil).Once()
+		mocks.disbursement.On("UpdateIfStatus", ctx, "DISB-123", models.DisbursementStatusFailed, mock.Anything).
+			Return(true, nil).Once()
+		mocks.deadLetter.On("UpdateIfStatus", ctx, "DISB-123", models.DeadLetterStatusOpen,
+			mock.MatchedBy(func(fields map[string]any) bool {
+				return fields["status"] == models.DeadLetterStatusRequeued &&
+					fields["resolved_by"] == "ops@example.com" &&
+					fields["resolution_note"] == "IFSC corrected at the bank"
+			}),
+		).Return(true, nil).Once()
+		mocks.idGenerator.On("GenerateAuditId").Return("AUD-123").Once()
+		mocks.audit.On("Create", ctx, mock.Anything).Return(nil).Once()
+
+		_, err := service.Requeue(ctx, operator, "DISB-123", models.AdminActionRequest{
+			Reason: "IFSC corrected at the bank",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "DISB-123", <-mocks.paymentChan)
+		mocks.loan.AssertExpectations(t)
+		mocks.deadLetter.AssertExpectations(t)
+	})
+
+	t.Run("returns conflict when the status moved since it was read", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+
+		mocks.disbursement.On("Get", ctx, "DISB-123").Return(&schema.Disbursement{
+			Id:      "DISB-123",
+			Channel: models.PaymentChannelUPI,
+			Status:  models.DisbursementStatusSuspended,
+		}, nil).Once()
+		mocks.disbursement.On("UpdateIfStatus", ctx, "DISB-123", models.DisbursementStatusSuspended, mock.Anything).
+			Return(false, nil).Once()
+
+		_, err := service.Requeue(ctx, operator, "DISB-123", models.AdminActionRequest{
+			Reason: "gateway recovered",
+		})
+
+		assert.ErrorIs(t, err, models.DISBURSEMENT_STATUS_CHANGED)
+		assert.Empty(t, mocks.paymentChan)
+		mocks.audit.AssertNotCalled(t, "Create")
+	})
+
+	t.Run("rejects processing disbursement", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+
+		mocks.disbursement.On("Get", ctx, "DISB-123").Return(&schema.Disbursement{
+			Id:     "DISB-123",
+			Status: models.DisbursementStatusProcessing,
+		}, nil).Once()
+
+		_, err := service.Requeue(ctx, operator, "DISB-123", models.AdminActionRequest{
+			Reason: "gateway recovered",
+		})
+
+		assert.ErrorIs(t, err, models.ACTION_NOT_ALLOWED)
+		assert.Empty(t, mocks.paymentChan)
+	})
+}
+
+func TestAdminService_ForceChannel(t *testing.T) {
+	ctx := context.Background()
+	operator := models.Operator{Id: "ops@example.com", Role: models.OperatorRoleAdmin}
+	beneficiaryId := "BEN-123"
+	expectPayee := func(mocks adminMocks, ifscCode string) {
+		mocks.loan.On("Get", ctx, "LOAN-123").Return(&schema.Loan{
+			Id:            "LOAN-123",
+			BeneficiaryId: &beneficiaryId,
+		}, nil).Once()
+		mocks.beneficiary.On("GetById", ctx, beneficiaryId).Return(&schema.Beneficiary{
+			Id:   beneficiaryId,
+			IFSC: ifscCode,
+		}, nil).Once()
+	}
+
+	t.Run("pins the channel and queues initiated disbursement", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+
+		mocks.disbursement.On("Get", ctx, "DISB-123").Return(&schema.Disbursement{
+			Id:      "DISB-123",
+			LoanId:  "LOAN-123",
+			Amount:  100000,
+			Channel: models.PaymentChannelNEFT,
+			Status:  models.DisbursementStatusInitiated,
+		}, nil).Once()
+		expectPayee(mocks, "HDFC0001234")
+		mocks.disbursement.On("UpdateIfStatus", ctx, "DISB-123", models.DisbursementStatusInitiated,
+			mock.MatchedBy(func(fields map[string]any) bool {
+				return fields["forced_channel"] == models.PaymentChannelIMPS &&
+					fields["channel"] == models.PaymentChannelIMPS
+			}),
+		).Return(true, nil).Once()
+		mocks.idGenerator.On("GenerateAuditId").Return("AUD-123").Once()
+		mocks.audit.On("Create", ctx, mock.MatchedBy(func(entry schema.AuditLog) bool {
+			return entry.Action == models.AdminActionForceChannel &&
+				*entry.Details == `{"from_channel":"NEFT","to_channel":"IMPS"}`
+		})).Return(nil).Once()
+
+		_, err := service.ForceChannel(ctx, operator, "DISB-123", models.ForceChannelRequest{
+			Channel: models.PaymentChannelIMPS,
+			Reason:  "NEFT returns from beneficiary bank",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "DISB-123", <-mocks.paymentChan)
+		mocks.disbursement.AssertExpectations(t)
+		mocks.audit.AssertExpectations(t)
+	})
+
+	t.Run("leaves suspended disbursement for the retry worker", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+
+		mocks.disbursement.On("Get", ctx, "DISB-123").Return(&schema.Disbursement{
+			Id:     "DISB-123",
+			LoanId: "LOAN-123",
+			Amount: 20000,
+			Status: models.DisbursementStatusSuspended,
+		}, nil).Once()
+		expectPayee(mocks, "HDFC0001234")
+		mocks.disbursement.On("UpdateIfStatus", ctx, "DISB-123", models.DisbursementStatusSuspended, mock.Anything).
+			Return(true, nil).Once()
+		mocks.idGenerator.On("GenerateAuditId").Return("AUD-123").Once()
+		mocks.audit.On("Create", ctx, mock.Anything).Return(nil).Once()
+
+		_, err := service.ForceChannel(ctx, operator, "DISB-123", models.ForceChannelRequest{
+			Channel: models.PaymentChannelUPI,
+			Reason:  "IMPS limits at beneficiary bank",
+		})
+
+		assert.NoError(t, err)
+		assert.Empty(t, mocks.paymentChan)
+	})
+
+	t.Run("refuses when a worker picked the disbursement up meanwhile", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+
+		mocks.disbursement.On("Get", ctx, "DISB-123").Return(&schema.Disbursement{
+			Id:     "DISB-123",
+			LoanId: "LOAN-123",
+			Amount: 100000,
+			Status: models.DisbursementStatusInitiated,
+		}, nil).Once()
+		expectPayee(mocks, "HDFC0001234")
+		mocks.disbursement.On("UpdateIfStatus", ctx, "DISB-123", models.DisbursementStatusInitiated, mock.Anything).
+			Return(false, nil).Once()
+
+		_, err := service.ForceChannel(ctx, operator, "DISB-123", models.ForceChannelRequest{
+			Channel: models.PaymentChannelIMPS,
+			Reason:  "NEFT returns from beneficiary bank",
+		})
+
+		assert.ErrorIs(t, err, models.DISBURSEMENT_STATUS_CHANGED)
+		assert.Empty(t, mocks.paymentChan)
+		mocks.audit.AssertNotCalled(t, "Create")
+	})
+
+	t.Run("refuses a channel whose limit is below the amount", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+
+		mocks.disbursement.On("Get", ctx, "DISB-123").Return(&schema.Disbursement{
+			Id:     "DISB-123",
+			LoanId: "LOAN-123",
+			Amount: 500000,
+			Status: models.DisbursementStatusFailed,
+		}, nil).Once()
+
+		_, err := service.ForceChannel(ctx, operator, "DISB-123", models.ForceChannelRequest{
+			Channel: models.PaymentChannelIMPS,
+			Reason:  "NEFT returns from beneficiary bank",
+		})
+
+		assert.ErrorIs(t, err, models.CHANNEL_OVER_LIMIT)
+		mocks.disbursement.AssertNotCalled(t, "UpdateIfStatus")
+	})
+
+	t.Run("refuses a channel the beneficiary's branch is not on", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+
+		mocks.disbursement.On("Get", ctx, "DISB-123").Return(&schema.Disbursement{
+			Id:     "DISB-123",
+			LoanId: "LOAN-123",
+			Amount: 20000,
+			Status: models.DisbursementStatusFailed,
+		}, nil).Once()
+		expectPayee(mocks, "SBIN0001234")
+
+		_, err := service.ForceChannel(ctx, operator, "DISB-123", models.ForceChannelRequest{
+			Channel: models.PaymentChannelUPI,
+			Reason:  "IMPS limits at beneficiary bank",
+		})
+
+		assert.ErrorIs(t, err, models.CHANNEL_NOT_AT_BRANCH)
+		mocks.disbursement.AssertNotCalled(t, "UpdateIfStatus")
+	})
+
+	t.Run("rejects unknown channel", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+
+		_, err := service.ForceChannel(ctx, operator, "DISB-123", models.ForceChannelRequest{
+			Channel: "RTGS",
+			Reason:  "large amount",
+		})
+
+		assert.ErrorIs(t, err, models.INVALID_PAYMENT_CHANNEL)
+		mocks.disbursement.AssertNotCalled(t, "Get")
+	})
+}
+
+func TestAdminService_ResetRetries(t *testing.T) {
+	ctx := context.Background()
+	operator := models.Operator{Id: "ops@example.com", Role: models.OperatorRoleAdmin}
+
+	t.Run("clears the retry count", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+
+		mocks.disbursement.On("Get", ctx, "DISB-123").Return(&schema.Disbursement{
+			Id:         "DISB-123",
+			Status:     models.DisbursementStatusSuspended,
+			RetryCount: 3,
+		}, nil).Once()
+		mocks.disbursement.On("UpdateIfStatus", ctx, "DISB-123", models.DisbursementStatusSuspended,
+			mock.MatchedBy(func(fields map[string]any) bool {
+				return fields["retry_count"] == 0
+			}),
+		).Return(true, nil).Once()
+		mocks.idGenerator.On("GenerateAuditId").Return("AUD-123").Once()
+		mocks.audit.On("Create", ctx, mock.MatchedBy(func(entry schema.AuditLog) bool {
+			return entry.Action == models.AdminActionResetRetries &&
+				entry.ToStatus == models.DisbursementStatusSuspended &&
+				*entry.Details == `{"from_retry_count":3}`
+		})).Return(nil).Once()
+
+		_, err := service.ResetRetries(ctx, operator, "DISB-123", models.AdminActionRequest{
+			Reason: "bank outage, not the beneficiary",
+		})
+
+		assert.NoError(t, err)
+		mocks.disbursement.AssertExpectations(t)
+		mocks.audit.AssertExpectations(t)
+	})
+}
+
+func TestAdminService_AuditTrail(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns entries with decoded details", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+		details := `{"from_retry_count":3}`
+
+		mocks.disbursement.On("Get", ctx, "DISB-123").Return(&schema.Disbursement{Id: "DISB-123"}, nil).Once()
+		mocks.audit.On("ListByEntity", ctx, models.AuditEntityDisbursement, "DISB-123").Return([]schema.AuditLog{
+			{
+				Id:         "AUD-1",
+				Action:     models.AdminActionResetRetries,
+				OperatorId: "ops@example.com",
+				Details:    &details,
+			},
+			{Id: "AUD-2", Action: models.AdminActionRequeue},
+		}, nil).Once()
+
+		trail, err := service.AuditTrail(ctx, "DISB-123")
+
+		assert.NoError(t, err)
+		assert.Len(t, trail, 2)
+		assert.Equal(t, map[string]any{"from_retry_count": float64(3)}, trail[0].Details)
+		assert.Nil(t, trail[1].Details)
+	})
+
+	t.Run("returns not found for unknown disbursement", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+
+		mocks.disbursement.On("Get", ctx, "DISB-404").Return(nil, gorm.ErrRecordNotFound).Once()
+
+		_, err := service.AuditTrail(ctx, "DISB-404")
+
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		mocks.audit.AssertNotCalled(t, "ListByEntity")
+	})
+}
+
+func TestAdminService_CorrectBeneficiary(t *testing.T) {
+	ctx := context.Background()
+	operator := models.Operator{Id: "ops@example.com", Role: models.OperatorRoleAdmin}
+	registeredName := "RAVI KUMAR"
+	pennyDroppedAt := time.Now()
+	request := models.BeneficiaryCorrectionRequest{
+		Name:          "Ravi Kumar",
+		AccountNumber: "50100012345678",
+		IFSCCode:      " hdfc0001234 ",
+		Bank:          "HDFC Bank",
+		Reason:        "IFSC corrected by borrower",
+	}
+	payee := models.BeneficiaryRequest{
+		Name:          "Ravi Kumar",
+		AccountNumber: "50100012345678",
+		IFSCCode:      "HDFC0001234",
+		Bank:          "HDFC Bank",
+	}
+	failedDisbursement := func() *schema.Disbursement {
+		return &schema.Disbursement{
+			Id:         "DISB-123",
+			LoanId:     "LOAN-123",
+			Channel:    models.PaymentChannelIMPS,
+			RetryCount: 3,
+			Status:     models.DisbursementStatusFailed,
+		}
+	}
+	releasedLoan := func() *schema.Loan {
+		return &schema.Loan{
+			Id:            "LOAN-123",
+			BorrowerName:  "Ravi Kumar",
+			BeneficiaryId: stringPtr("BEN-OLD"),
+			Status:        models.LoanStatusSanctioned,
+		}
+	}
+	previous := &schema.Beneficiary{
+		Id:      "BEN-OLD",
+		Name:    "Ravi Kumar",
+		Account: "50100012345678",
+		IFSC:    "HDFC0000000",
+		Bank:    "HDFC Bank",
+	}
+
+	t.Run("relinks the loan, records history and requeues the disbursement", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+		overridden := request
+		overridden.Override = true
+		overridden.OverrideReason = "borrower KYC confirmed by branch"
+
+		mocks.disbursement.On("Get", ctx, "DISB-123").Return(failedDisbursement(), nil).Once()
+		mocks.loan.On("Get", ctx, "LOAN-123").Return(releasedLoan(), nil).Twice()
+		mocks.beneficiary.On("GetById", ctx, "BEN-OLD").Return(previous, nil).Once()
+		mocks.beneficiaryService.On("Create", ctx, payee).Return(&models.BeneficiaryResponse{
+			Id:             "BEN-NEW",
+			Name:           "Ravi Kumar",
+			Status:         models.BeneficiaryStatusUnverified,
+			RegisteredName: &registeredName,
+			PennyDroppedAt: &pennyDroppedAt,
+		}, nil).Once()
+		mocks.beneficiary.On("Update", ctx, "BEN-NEW", mock.MatchedBy(func(fields map[string]any) bool {
+			return fields["overridden_by"] == "ops@example.com" &&
+				fields["override_reason"] == "borrower KYC confirmed by branch"
+		})).Return(nil).Once()
+		mocks.loan.On("Update", ctx, "LOAN-123", map[string]any{"beneficiary_id": "BEN-NEW"}).
+			Return(&schema.Loan{Id: "LOAN-123"}, nil).Once()
+		mocks.idGenerator.On("GenerateBeneficiaryChangeId").Return("BCH-123").Once()
+		mocks.beneficiaryChange.On("Create", ctx, mock.MatchedBy(func(change schema.BeneficiaryChange) bool {
+			return change.Id == "BCH-123" &&
+				change.LoanId == "LOAN-123" &&
+				change.DisbursementId == "DISB-123" &&
+				*change.PreviousBeneficiaryId == "BEN-OLD" &&
+				change.PreviousIFSC == "HDFC0000000" &&
+				change.BeneficiaryId == "BEN-NEW" &&
+				change.OperatorId == "ops@example.com"
+		})).Return(nil).Once()
+		mocks.disbursement.On("Update", ctx, "DISB-123", mock.MatchedBy(func(fields map[string]any) bool {
+			return fields["retry_count"] == 0 &&
+				fields["name_match_decision"] == models.NameMatchDecisionAllow
+		})).Return(nil).Once()
+		mocks.disbursement.On("UpdateIfStatus", ctx, "DISB-123", models.DisbursementStatusFailed,
+			mock.MatchedBy(func(fields map[string]any) bool {
+				return fields["status"] == string(models.DisbursementStatusInitiated)
+			}),
+		).Return(true, nil).Once()
+		mocks.loan.On("UpdateIfStatus", ctx, "LOAN-123", models.LoanStatusSanctioned, map[string]any{"status": models.LoanStatusDisbursementPending}).
+			Return(true, nil).Once()
+		mocks.deadLetter.On("UpdateIfStatus", ctx, "DISB-123", models.DeadLetterStatusOpen,
+			mock.MatchedBy(func(fields map[string]any) bool {
+				return fields["status"] == models.DeadLetterStatusRequeued
+			}),
+		).Return(true, nil).Once()
+		mocks.idGenerator.On("GenerateAuditId").Return("AUD-123").Once()
+		mocks.audit.On("Create", ctx, auditEntry(
+			models.AdminActionCorrectBeneficiary,
+			models.DisbursementStatusFailed,
+			models.DisbursementStatusInitiated,
+		)).Return(nil).Once()
+
+		response, err := service.CorrectBeneficiary(ctx, operator, "DISB-123", overridden)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.DisbursementStatusInitiated, response.Status)
+		assert.Equal(t, "DISB-123", <-mocks.paymentChan)
+		mocks.beneficiary.AssertExpectations(t)
+		mocks.loan.AssertExpectations(t)
+		mocks.beneficiaryChange.AssertExpectations(t)
+		mocks.disbursement.AssertExpectations(t)
+		mocks.deadLetter.AssertExpectations(t)
+		mocks.audit.AssertExpectations(t)
+	})
+
+	t.Run("refuses unverified payee without an explicit override", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+
+		mocks.disbursement.On("Get", ctx, "DISB-123").Return(failedDisbursement(), nil).Once()
+		mocks.loan.On("Get", ctx, "LOAN-123").Return(releasedLoan(), nil).Once()
+		mocks.beneficiary.On("GetById", ctx, "BEN-OLD").Return(previous, nil).Once()
+		mocks.beneficiaryService.On("Create", ctx, payee).Return(&models.BeneficiaryResponse{
+			Id:             "BEN-NEW",
+			Name:           "Ravi Kumar",
+			Status:         models.BeneficiaryStatusUnverified,
+			RegisteredName: &registeredName,
+			PennyDroppedAt: &pennyDroppedAt,
+		}, nil).Once()
+
+		_, err := service.CorrectBeneficiary(ctx, operator, "DISB-123", request)
+
+		assert.ErrorIs(t, err, models.CORRECTION_OVERRIDE_REQUIRED)
+		mocks.beneficiary.AssertNotCalled(t, "Update")
+		mocks.loan.AssertNotCalled(t, "Update")
+		assert.Empty(t, mocks.paymentChan)
+	})
+
+	t.Run("requires a reason with the override", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+		overridden := request
+		overridden.Override = true
+
+		_, err := service.CorrectBeneficiary(ctx, operator, "DISB-123", overridden)
+
+		assert.ErrorIs(t, err, models.OVERRIDE_APPROVAL_REQUIRED)
+		mocks.disbursement.AssertNotCalled(t, "Get")
+	})
+
+	t.Run("refuses account the penny drop rejects", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+		reason := "Inactive Beneficiary Account"
+
+		mocks.disbursement.On("Get", ctx, "DISB-123").Return(failedDisbursement(), nil).Once()
+		mocks.loan.On("Get", ctx, "LOAN-123").Return(releasedLoan(), nil).Once()
+		mocks.beneficiary.On("GetById", ctx, "BEN-OLD").Return(previous, nil).Once()
+		mocks.beneficiaryService.On("Create", ctx, payee).Return(&models.BeneficiaryResponse{
+			Id:             "BEN-NEW",
+			Status:         models.BeneficiaryStatusRejected,
+			StatusReason:   &reason,
+			PennyDroppedAt: &pennyDroppedAt,
+		}, nil).Once()
+
+		_, err := service.CorrectBeneficiary(ctx, operator, "DISB-123", request)
+
+		assert.ErrorIs(t, err, models.BENEFICIARY_REJECTED)
+		assert.ErrorContains(t, err, reason)
+		mocks.loan.AssertNotCalled(t, "Update")
+		assert.Empty(t, mocks.paymentChan)
+	})
+
+	t.Run("refuses when the penny drop could not run", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+
+		mocks.disbursement.On("Get", ctx, "DISB-123").Return(failedDisbursement(), nil).Once()
+		mocks.loan.On("Get", ctx, "LOAN-123").Return(releasedLoan(), nil).Once()
+		mocks.beneficiary.On("GetById", ctx, "BEN-OLD").Return(previous, nil).Once()
+		mocks.beneficiaryService.On("Create", ctx, payee).Return(&models.BeneficiaryResponse{
+			Id:     "BEN-NEW",
+			Status: models.BeneficiaryStatusUnverified,
+		}, nil).Once()
+
+		_, err := service.CorrectBeneficiary(ctx, operator, "DISB-123", request)
+
+		assert.ErrorIs(t, err, models.BENEFICIARY_UNVERIFIABLE)
+		mocks.loan.AssertNotCalled(t, "Update")
+	})
+
+	t.Run("refuses account held by someone else", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+		holder := "SUNITA SHARMA"
+
+		mocks.disbursement.On("Get", ctx, "DISB-123").Return(failedDisbursement(), nil).Once()
+		mocks.loan.On("Get", ctx, "LOAN-123").Return(releasedLoan(), nil).Once()
+		mocks.beneficiary.On("GetById", ctx, "BEN-OLD").Return(previous, nil).Once()
+		mocks.beneficiaryService.On("Create", ctx, payee).Return(&models.BeneficiaryResponse{
+			Id:             "BEN-NEW",
+			Name:           "Ravi Kumar",
+			Status:         models.BeneficiaryStatusUnverified,
+			RegisteredName: &holder,
+			PennyDroppedAt: &pennyDroppedAt,
+		}, nil).Once()
+
+		_, err := service.CorrectBeneficiary(ctx, operator, "DISB-123", request)
+
+		assert.ErrorIs(t, err, models.NAME_MISMATCH)
+		mocks.loan.AssertNotCalled(t, "Update")
+	})
+
+	t.Run("refuses details that match the current beneficiary", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+
+		mocks.disbursement.On("Get", ctx, "DISB-123").Return(failedDisbursement(), nil).Once()
+		mocks.loan.On("Get", ctx, "LOAN-123").Return(releasedLoan(), nil).Once()
+		mocks.beneficiary.On("GetById", ctx, "BEN-OLD").Return(previous, nil).Once()
+		mocks.beneficiaryService.On("Create", ctx, payee).
+			Return(&models.BeneficiaryResponse{Id: "BEN-OLD"}, nil).Once()
+
+		_, err := service.CorrectBeneficiary(ctx, operator, "DISB-123", request)
+
+		assert.ErrorIs(t, err, models.BENEFICIARY_UNCHANGED)
+	})
+
+	t.Run("rejects disbursement that has not failed", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+
+		mocks.disbursement.On("Get", ctx, "DISB-123").Return(&schema.Disbursement{
+			Id:     "DISB-123",
+			Status: models.DisbursementStatusSuspended,
+		}, nil).Once()
+
+		_, err := service.CorrectBeneficiary(ctx, operator, "DISB-123", request)
+
+		assert.ErrorIs(t, err, models.ACTION_NOT_ALLOWED)
+		mocks.beneficiaryService.AssertNotCalled(t, "Create")
+	})
+
+	t.Run("requires every payee detail", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+
+		_, err := service.CorrectBeneficiary(ctx, operator, "DISB-123", models.BeneficiaryCorrectionRequest{
+			IFSCCode: "HDFC0001234",
+			Reason:   "IFSC corrected by borrower",
+		})
+
+		assert.ErrorIs(t, err, models.BENEFICIARY_DETAILS_REQUIRED)
+		mocks.disbursement.AssertNotCalled(t, "Get")
+	})
+}
+
+func TestAdminService_BeneficiaryHistory(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns corrections with the previous details", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+
+		mocks.loan.On("Get", ctx, "LOAN-123").Return(&schema.Loan{Id: "LOAN-123"}, nil).Once()
+		mocks.beneficiaryChange.On("ListByLoan", ctx, "LOAN-123").Return([]schema.BeneficiaryChange{
+			{
+				Id:                    "BCH-1",
+				LoanId:                "LOAN-123",
+				PreviousBeneficiaryId: stringPtr("BEN-OLD"),
+				PreviousIFSC:          "HDFC0000000",
+				BeneficiaryId:         "BEN-NEW",
+			},
+		}, nil).Once()
+
+		history, err := service.BeneficiaryHistory(ctx, "LOAN-123")
+
+		assert.NoError(t, err)
+		assert.Len(t, history, 1)
+		assert.Equal(t, "BCH-1", history[0].ChangeId)
+		assert.Equal(t, "HDFC0000000", history[0].Previous.IFSC)
+		assert.Equal(t, "BEN-NEW", history[0].BeneficiaryId)
+	})
+
+	t.Run("returns not found for unknown loan", func(t *testing.T) {
+		service, mocks := newAdminService(t)
+
+		mocks.loan.On("Get", ctx, "LOAN-404").Return(nil, gorm.ErrRecordNotFound).Once()
+
+		_, err := service.BeneficiaryHistory(ctx, "LOAN-404")
+
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		mocks.beneficiaryChange.AssertNotCalled(t, "ListByLoan")
+	})
+}
@@ -0,0 +1,559 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"loan-disbursement-service/db/daos"
+	"loan-disbursement-service/db/schema"
+	"loan-disbursement-service/models"
+	"loan-disbursement-service/utils"
+	"shared/logging"
+	"shared/pii"
+	"shared/tracing"
+	"shared/validation"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// MaxBatchRows caps a single upload. Larger files should be split by the
+// partner so one bad file does not hold up a day's disbursements.
+const MaxBatchRows = 5000
+
+var (
+	batchColumns = []string{
+		"loan_id",
+		"amount",
+		"account_number",
+		"ifsc_code",
+		"beneficiary_name",
+		"beneficiary_bank",
+		"scheduled_at",
+	}
+	batchResultColumns = append(append([]string{"row_number"}, batchColumns...),
+		"status",
+		"error",
+		"disbursement_id",
+		"disbursement_status",
+	)
+)
+
+type BatchService interface {
+	Create(
+		ctx context.Context,
+		clientId, source string,
+		requests []models.DisburseRequest,
+	) (*models.BatchResponse, error)
+	CreateFromCSV(ctx context.Context, clientId, source string, file io.Reader) (*models.BatchResponse, error)
+	Process(ctx context.Context, batchId string) error
+	ListUnfinished(ctx context.Context) ([]string, error)
+	// Get and WriteResults only find batches clientId uploaded, or any batch
+	// when clientId is empty.
+	Get(ctx context.Context, clientId, batchId string) (*models.BatchResponse, error)
+	WriteResults(ctx context.Context, clientId, batchId string, w io.Writer) error
+}
+
+type BatchServiceImpl struct {
+	idGenerator  utils.IdGenerator
+	batch        daos.BatchRepository
+	loan         daos.LoanRepository
+	disbursement daos.DisbursementRepository
+	disburser    DisbursementService
+	batchChan    chan string
+}
+
+func NewBatchService(
+	idGenerator utils.IdGenerator,
+	batch daos.BatchRepository,
+	loan daos.LoanRepository,
+	disbursement daos.DisbursementRepository,
+	disburser DisbursementService,
+	batchChan chan string,
+) BatchService {
+	return &BatchServiceImpl{
+		idGenerator:  idGenerator,
+		batch:        batch,
+		loan:         loan,
+		disbursement: disbursement,
+		disburser:    disburser,
+		batchChan:    batchChan,
+	}
+}
+
+// batchInput is one uploaded row. err is set when the row could not even be
+// parsed, so it is rejected without further checks.
+type batchInput struct {
+	request models.DisburseRequest
+	err     error
+}
+
+func (s *BatchServiceImpl) Create(
+	ctx context.Context,
+	clientId, source string,
+	requests []models.DisburseRequest,
+) (*models.BatchResponse, error) {
+	inputs := make([]batchInput, len(requests))
+	for i, request := range requests {
+		inputs[i] = batchInput{request: request}
+	}
+	return s.create(ctx, clientId, source, inputs)
+}
+
+// CreateFromCSV reads a file with a header row naming the columns. loan_id
+// and amount are required; the beneficiary columns may be left out for loans
+// that already have a beneficiary linked, and scheduled_at for immediate
+// disbursement.
+func (s *BatchServiceImpl) CreateFromCSV(
+	ctx context.Context,
+	clientId, source string,
+	file io.Reader,
+) (*models.BatchResponse, error) {
+	inputs, err := parseBatchCSV(file)
+	if err != nil {
+		return nil, err
+	}
+	return s.create(ctx, clientId, source, inputs)
+}
+
+func (s *BatchServiceImpl) create(
+	ctx context.Context,
+	clientId, source string,
+	inputs []batchInput,
+) (*models.BatchResponse, error) {
+	if len(inputs) == 0 {
+		return nil, models.EMPTY_BATCH
+	}
+	if len(inputs) > MaxBatchRows {
+		return nil, fmt.Errorf("%d rows, limit is %d: %w", len(inputs), MaxBatchRows, models.BATCH_TOO_LARGE)
+	}
+
+	batchId := s.idGenerator.GenerateBatchId()
+	rows := make([]schema.BatchRow, len(inputs))
+	seen := make(map[string]int, len(inputs))
+	accepted := 0
+	for i, input := range inputs {
+		request := input.request
+		rows[i] = schema.BatchRow{
+			BatchId:         batchId,
+			RowNumber:       i + 1,
+			LoanId:          request.LoanId,
+			Amount:          request.Amount,
+			AccountNumber:   request.AccountNumber,
+			IFSCCode:        request.IFSCCode,
+			BeneficiaryName: request.BeneficiaryName,
+			BeneficiaryBank: request.BeneficiaryBank,
+			ScheduledAt:     request.ScheduledAt,
+			Status:          models.BatchRowStatusPending,
+		}
+
+		err := input.err
+		if err == nil {
+			err = validation.Struct(request)
+		}
+		if err == nil {
+			if first, ok := seen[request.LoanId]; ok {
+				err = fmt.Errorf("duplicate loan_id, first seen in row %d", first)
+			}
+		}
+		if err == nil {
+			reason, lookupErr := s.checkLoan(ctx, request)
+			if lookupErr != nil {
+				return nil, lookupErr
+			}
+			if reason != "" {
+				err = errors.New(reason)
+			}
+		}
+		if request.LoanId != "" {
+			if _, ok := seen[request.LoanId]; !ok {
+				seen[request.LoanId] = i + 1
+			}
+		}
+
+		if err != nil {
+			message := err.Error()
+			rows[i].Status = models.BatchRowStatusRejected
+			rows[i].Error = &message
+			continue
+		}
+		accepted++
+	}
+
+	batch, err := s.batch.Create(ctx, schema.Batch{
+		Id:           batchId,
+		Source:       source,
+		ClientId:     clientId,
+		Status:       models.BatchStatusAccepted,
+		TotalRows:    len(rows),
+		AcceptedRows: accepted,
+		RejectedRows: len(rows) - accepted,
+		TraceParent:  tracing.TraceParent(ctx),
+	}, rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create batch: %w", err)
+	}
+	ctx = logging.With(ctx, logging.Fields{BatchId: batchId})
+
+	log.Ctx(ctx).Info().
+		Str("source", source).
+		Int("total_rows", batch.TotalRows).
+		Int("accepted_rows", batch.AcceptedRows).
+		Msg("disbursement batch accepted")
+
+	// A full channel leaves the batch accepted for the batch worker's resume
+	// poll, so the upload never waits on rows already queued.
+	select {
+	case s.batchChan <- batchId:
+	default:
+		log.Ctx(ctx).Warn().Msg("batch queue is full, leaving batch for the resume poll")
+	}
+
+	return batchResponse(batch, rows, nil), nil
+}
+
+// checkLoan returns why Disburse would refuse the row because of the loan
+// itself, so the partner hears about it on upload rather than in the results
+// file. An empty reason means the row can go ahead.
+func (s *BatchServiceImpl) checkLoan(
+	ctx context.Context,
+	request models.DisburseRequest,
+) (string, error) {
+	loan, err := s.loan.Get(ctx, request.LoanId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "loan not found", nil
+		}
+		return "", fmt.Errorf("failed to get loan %s: %w", request.LoanId, err)
+	}
+	switch {
+	case !loan.Status.IsDisbursable():
+		return fmt.Sprintf("loan is %s: %s", loan.Status, models.LOAN_NOT_DISBURSABLE), nil
+	case request.Amount > loan.Amount-loan.DisbursedAmount:
+		return "amount exceeds undisbursed loan amount", nil
+	case loan.BeneficiaryId == nil && request.AccountNumber == "":
+		return "loan has no beneficiary, account_number, ifsc_code and beneficiary_name are required", nil
+	}
+	return "", nil
+}
+
+// Process submits every pending row through Disburse, which queues the
+// payment for the payment worker. Rows already submitted are skipped, so a
+// batch interrupted by a restart can be processed again.
+func (s *BatchServiceImpl) Process(ctx context.Context, batchId string) error {
+	batch, err := s.batch.Get(ctx, batchId)
+	if err != nil {
+		return fmt.Errorf("failed to get batch: %w", err)
+	}
+	if batch.Status == models.BatchStatusCompleted {
+		return nil
+	}
+
+	ctx, span := tracing.Resume(ctx, batch.TraceParent, "batch.process",
+		tracing.BatchIdKey.String(batchId),
+	)
+	defer span.End()
+	ctx = logging.With(ctx, logging.Fields{BatchId: batchId})
+	return tracing.Record(span, s.process(ctx, batch))
+}
+
+func (s *BatchServiceImpl) process(ctx context.Context, batch *schema.Batch) error {
+	batchId := batch.Id
+	if err := s.batch.Update(ctx, batchId, map[string]any{
+		"status": models.BatchStatusProcessing,
+	}); err != nil {
+		return fmt.Errorf("failed to update batch: %w", err)
+	}
+
+	rows, err := s.batch.ListRows(ctx, batchId)
+	if err != nil {
+		return fmt.Errorf("failed to list batch rows: %w", err)
+	}
+
+	for _, row := range rows {
+		if row.Status != models.BatchRowStatusPending {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		fields := map[string]any{}
+		rowCtx := logging.With(ctx, logging.Fields{LoanId: row.LoanId})
+		result, err := s.disburser.Disburse(rowCtx, &models.DisburseRequest{
+			LoanId:          row.LoanId,
+			Amount:          row.Amount,
+			AccountNumber:   row.AccountNumber,
+			IFSCCode:        row.IFSCCode,
+			BeneficiaryName: row.BeneficiaryName,
+			BeneficiaryBank: row.BeneficiaryBank,
+			ScheduledAt:     row.ScheduledAt,
+			ClientId:        batch.ClientId,
+		})
+		if errors.Is(err, models.PAYMENT_QUEUE_FULL) {
+			// The row stays pending and the batch processing, so the
+			// batch poller carries on from here once the queue drains.
+			log.Ctx(rowCtx).Warn().
+				Int("row_number", row.RowNumber).
+				Msg("payment queue is full, pausing batch")
+			return nil
+		}
+		if err != nil {
+			log.Ctx(rowCtx).Warn().
+				Err(err).
+				Int("row_number", row.RowNumber).
+				Msg("batch row disbursement failed")
+			fields["status"] = models.BatchRowStatusFailed
+			fields["error"] = err.Error()
+		} else {
+			fields["status"] = models.BatchRowStatusSubmitted
+			fields["disbursement_id"] = result.DisbursementId
+		}
+
+		if err := s.batch.UpdateRow(ctx, batchId, row.RowNumber, fields); err != nil {
+			return fmt.Errorf("failed to update batch row %d: %w", row.RowNumber, err)
+		}
+	}
+
+	if err := s.batch.Update(ctx, batchId, map[string]any{
+		"status":       models.BatchStatusCompleted,
+		"completed_at": time.Now(),
+	}); err != nil {
+		return fmt.Errorf("failed to update batch: %w", err)
+	}
+
+	log.Ctx(ctx).Info().Msg("disbursement batch processed")
+	return nil
+}
+
+// ListUnfinished returns batches that were accepted but not fully processed,
+// oldest first, so the worker can pick them up again after a restart.
+func (s *BatchServiceImpl) ListUnfinished(ctx context.Context) ([]string, error) {
+	batches, err := s.batch.ListByStatus(ctx, []models.BatchStatus{
+		models.BatchStatusAccepted,
+		models.BatchStatusProcessing,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list batches: %w", err)
+	}
+
+	ids := make([]string, len(batches))
+	for i, batch := range batches {
+		ids[i] = batch.Id
+	}
+	return ids, nil
+}
+
+func (s *BatchServiceImpl) Get(ctx context.Context, clientId, batchId string) (*models.BatchResponse, error) {
+	batch, rows, disbursements, err := s.load(ctx, clientId, batchId)
+	if err != nil {
+		return nil, err
+	}
+	return batchResponse(batch, rows, disbursements), nil
+}
+
+// WriteResults writes the uploaded rows back as CSV with the outcome of each
+// row and the current status of its disbursement.
+func (s *BatchServiceImpl) WriteResults(ctx context.Context, clientId, batchId string, w io.Writer) error {
+	_, rows, disbursements, err := s.load(ctx, clientId, batchId)
+	if err != nil {
+		return err
+	}
+
+	// Account numbers and names are masked like in JSON responses for
+	// callers without pii:view.
+	account, name, text := pii.Account, pii.Name, pii.Text
+	if pii.CanView(ctx) {
+		account, name, text = unmasked, unmasked, unmasked
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(batchResultColumns); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		var scheduledAt, rowError, disbursementId, disbursementStatus string
+		if row.ScheduledAt != nil {
+			scheduledAt = row.ScheduledAt.Format(time.RFC3339)
+		}
+		if row.Error != nil {
+			rowError = text(*row.Error)
+		}
+		if row.DisbursementId != nil {
+			disbursementId = *row.DisbursementId
+			disbursementStatus = string(disbursements[disbursementId])
+		}
+		if err := writer.Write([]string{
+			strconv.Itoa(row.RowNumber),
+			row.LoanId,
+			strconv.FormatFloat(row.Amount, 'f', 2, 64),
+			account(row.AccountNumber),
+			row.IFSCCode,
+			name(row.BeneficiaryName),
+			row.BeneficiaryBank,
+			scheduledAt,
+			string(row.Status),
+			rowError,
+			disbursementId,
+			disbursementStatus,
+		}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func unmasked(value string) string {
+	return value
+}
+
+func (s *BatchServiceImpl) load(
+	ctx context.Context,
+	clientId, batchId string,
+) (*schema.Batch, []schema.BatchRow, map[string]models.DisbursementStatus, error) {
+	batch, err := s.batch.Get(ctx, batchId)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	// Another client's batch is reported as missing, so ids cannot be probed.
+	if clientId != "" && batch.ClientId != clientId {
+		return nil, nil, nil, models.BATCH_NOT_FOUND
+	}
+
+	rows, err := s.batch.ListRows(ctx, batchId)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to list batch rows: %w", err)
+	}
+
+	var ids []string
+	for _, row := range rows {
+		if row.DisbursementId != nil {
+			ids = append(ids, *row.DisbursementId)
+		}
+	}
+	statuses := make(map[string]models.DisbursementStatus, len(ids))
+	if len(ids) > 0 {
+		disbursements, err := s.disbursement.ListByIds(ctx, ids)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to list disbursements: %w", err)
+		}
+		for _, disbursement := range disbursements {
+			statuses[disbursement.Id] = disbursement.Status
+		}
+	}
+	return batch, rows, statuses, nil
+}
+
+func batchResponse(
+	batch *schema.Batch,
+	rows []schema.BatchRow,
+	disbursements map[string]models.DisbursementStatus,
+) *models.BatchResponse {
+	response := &models.BatchResponse{
+		BatchId:            batch.Id,
+		Status:             batch.Status,
+		Source:             batch.Source,
+		TotalRows:          batch.TotalRows,
+		AcceptedRows:       batch.AcceptedRows,
+		RejectedRows:       batch.RejectedRows,
+		RowStatus:          map[models.BatchRowStatus]int{},
+		DisbursementStatus: map[models.DisbursementStatus]int{},
+		Rows:               make([]models.BatchRow, len(rows)),
+		CreatedAt:          batch.CreatedAt,
+		UpdatedAt:          batch.UpdatedAt,
+	}
+	for i, row := range rows {
+		response.RowStatus[row.Status]++
+		if row.Status != models.BatchRowStatusRejected {
+			response.TotalAmount += row.Amount
+		}
+		response.Rows[i] = models.BatchRow{
+			RowNumber:      row.RowNumber,
+			LoanId:         row.LoanId,
+			Amount:         row.Amount,
+			Status:         row.Status,
+			Error:          row.Error,
+			DisbursementId: row.DisbursementId,
+		}
+		if row.DisbursementId != nil {
+			if status, ok := disbursements[*row.DisbursementId]; ok {
+				response.Rows[i].DisbursementStatus = status
+				response.DisbursementStatus[status]++
+			}
+		}
+	}
+	return response
+}
+
+func parseBatchCSV(file io.Reader) ([]batchInput, error) {
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, models.EMPTY_BATCH
+		}
+		return nil, fmt.Errorf("%w: %v", models.INVALID_BATCH_FILE, err)
+	}
+	index := make(map[string]int, len(header))
+	for i, column := range header {
+		index[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, required := range []string{"loan_id", "amount"} {
+		if _, ok := index[required]; !ok {
+			return nil, fmt.Errorf("%w: missing %s column", models.INVALID_BATCH_FILE, required)
+		}
+	}
+
+	var inputs []batchInput
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", models.INVALID_BATCH_FILE, err)
+		}
+		if len(inputs) == MaxBatchRows {
+			return nil, fmt.Errorf("more than %d rows: %w", MaxBatchRows, models.BATCH_TOO_LARGE)
+		}
+
+		value := func(column string) string {
+			i, ok := index[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		input := batchInput{request: models.DisburseRequest{
+			LoanId:          value("loan_id"),
+			AccountNumber:   value("account_number"),
+			IFSCCode:        strings.ToUpper(value("ifsc_code")),
+			BeneficiaryName: value("beneficiary_name"),
+			BeneficiaryBank: value("beneficiary_bank"),
+		}}
+		input.request.Amount, err = strconv.ParseFloat(value("amount"), 64)
+		if err != nil {
+			input.err = fmt.Errorf("amount %q is not a number", value("amount"))
+		}
+		if scheduledAt := value("scheduled_at"); scheduledAt != "" && input.err == nil {
+			parsed, err := time.Parse(time.RFC3339, scheduledAt)
+			if err != nil {
+				input.err = fmt.Errorf("scheduled_at %q is not an RFC 3339 time", scheduledAt)
+			} else {
+				input.request.ScheduledAt = &parsed
+			}
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs, nil
+}
@@ -0,0 +1,490 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"loan-disbursement-service/db/schema"
+	"loan-disbursement-service/models"
+	db_test "loan-disbursement-service/test/db"
+	utils_test "loan-disbursement-service/test/utils"
+	"shared/pii"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+var _ DisbursementService = (*MockDisbursementService)(nil)
+
+type MockDisbursementService struct {
+	mock.Mock
+}
+
+func (m *MockDisbursementService) Disburse(
+	ctx context.Context,
+	req *models.DisburseRequest,
+) (*models.DisbursementResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DisbursementResponse), args.Error(1)
+}
+
+func (m *MockDisbursementService) Fetch(ctx context.Context, clientId, disbursementId string) (any, error) {
+	args := m.Called(ctx, clientId, disbursementId)
+	return args.Get(0), args.Error(1)
+}
+
+func (m *MockDisbursementService) Retry(ctx context.Context, disbursementId string) (any, error) {
+	args := m.Called(ctx, disbursementId)
+	return args.Get(0), args.Error(1)
+}
+
+func (m *MockDisbursementService) Cancel(
+	ctx context.Context,
+	disbursementId string,
+) (*models.DisbursementResponse, error) {
+	args := m.Called(ctx, disbursementId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DisbursementResponse), args.Error(1)
+}
+
+type batchMocks struct {
+	idGenerator  *utils_test.MockIdGenerator
+	batch        *db_test.MockBatchRepository
+	loan         *db_test.MockLoanRepository
+	disbursement *db_test.MockDisbursementRepository
+	disburser    *MockDisbursementService
+	batchChan    chan string
+}
+
+func newBatchService() (BatchService, batchMocks) {
+	mocks := batchMocks{
+		idGenerator:  new(utils_test.MockIdGenerator),
+		batch:        new(db_test.MockBatchRepository),
+		loan:         new(db_test.MockLoanRepository),
+		disbursement: new(db_test.MockDisbursementRepository),
+		disburser:    new(MockDisbursementService),
+		batchChan:    make(chan string, 1),
+	}
+	service := NewBatchService(
+		mocks.idGenerator,
+		mocks.batch,
+		mocks.loan,
+		mocks.disbursement,
+		mocks.disburser,
+		mocks.batchChan,
+	)
+	return service, mocks
+}
+
+func TestBatchService_Create(t *testing.T) {
+	ctx := context.Background()
+	beneficiaryId := "BEN-123"
+
+	t.Run("validates every row and queues the batch", func(t *testing.T) {
+		service, mocks := newBatchService()
+
+		mocks.idGenerator.On("GenerateBatchId").Return("BATCH-123").Once()
+		mocks.loan.On("Get", ctx, "LOAN-1").Return(&schema.Loan{
+			Id:            "LOAN-1",
+			Amount:        50000,
+			Status:        models.LoanStatusSanctioned,
+			BeneficiaryId: &beneficiaryId,
+		}, nil).Once()
+		mocks.loan.On("Get", ctx, "LOAN-2").Return(nil, gorm.ErrRecordNotFound).Once()
+		mocks.loan.On("Get", ctx, "LOAN-3").Return(&schema.Loan{
+			Id:     "LOAN-3",
+			Amount: 50000,
+			Status: models.LoanStatusDisbursed,
+		}, nil).Once()
+		mocks.loan.On("Get", ctx, "LOAN-4").Return(&schema.Loan{
+			Id:     "LOAN-4",
+			Amount: 50000,
+			Status: models.LoanStatusSanctioned,
+		}, nil).Once()
+		mocks.batch.On("Create", ctx, mock.MatchedBy(func(batch schema.Batch) bool {
+			return batch.Id == "BATCH-123" &&
+				batch.Source == "json" &&
+				batch.ClientId == "los" &&
+				batch.Status == models.BatchStatusAccepted &&
+				batch.TotalRows == 7 &&
+				batch.AcceptedRows == 1 &&
+				batch.RejectedRows == 6
+		}), mock.MatchedBy(func(rows []schema.BatchRow) bool {
+			return len(rows) == 7 &&
+				rows[0].Status == models.BatchRowStatusPending &&
+				rows[1].Status == models.BatchRowStatusRejected &&
+				*rows[1].Error == "amount must be greater than zero" &&
+				*rows[2].Error == "ifsc_code is not a valid IFSC" &&
+				*rows[3].Error == "duplicate loan_id, first seen in row 1" &&
+				*rows[4].Error == "loan not found" &&
+				strings.Contains(*rows[5].Error, "loan is disbursed") &&
+				strings.HasPrefix(*rows[6].Error, "loan has no beneficiary")
+		})).Return(&schema.Batch{
+			Id:           "BATCH-123",
+			Source:       "json",
+			Status:       models.BatchStatusAccepted,
+			TotalRows:    7,
+			AcceptedRows: 1,
+			RejectedRows: 6,
+		}, nil).Once()
+
+		result, err := service.Create(ctx, "los", "json", []models.DisburseRequest{
+			{LoanId: "LOAN-1", Amount: 10000},
+			{LoanId: "LOAN-5", Amount: 0},
+			{
+				LoanId:          "LOAN-6",
+				Amount:          10000,
+				AccountNumber:   "1234567890",
+				IFSCCode:        "BANK1234",
+				BeneficiaryName: "John Doe",
+			},
+			{LoanId: "LOAN-1", Amount: 5000},
+			{LoanId: "LOAN-2", Amount: 10000},
+			{LoanId: "LOAN-3", Amount: 10000},
+			{LoanId: "LOAN-4", Amount: 10000},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "BATCH-123", result.BatchId)
+		assert.Equal(t, 1, result.RowStatus[models.BatchRowStatusPending])
+		assert.Equal(t, 6, result.RowStatus[models.BatchRowStatusRejected])
+		assert.Equal(t, 10000.0, result.TotalAmount)
+		assert.Equal(t, "BATCH-123", <-mocks.batchChan)
+		mocks.batch.AssertExpectations(t)
+		mocks.loan.AssertExpectations(t)
+	})
+
+	t.Run("accepts the batch without waiting when the queue is full", func(t *testing.T) {
+		service, mocks := newBatchService()
+		mocks.batchChan <- "BATCH-000"
+
+		mocks.idGenerator.On("GenerateBatchId").Return("BATCH-123").Once()
+		mocks.loan.On("Get", ctx, "LOAN-1").Return(&schema.Loan{
+			Id:            "LOAN-1",
+			Amount:        50000,
+			Status:        models.LoanStatusSanctioned,
+			BeneficiaryId: &beneficiaryId,
+		}, nil).Once()
+		mocks.batch.On("Create", ctx, mock.Anything, mock.Anything).Return(&schema.Batch{
+			Id:           "BATCH-123",
+			Status:       models.BatchStatusAccepted,
+			TotalRows:    1,
+			AcceptedRows: 1,
+		}, nil).Once()
+
+		result, err := service.Create(ctx, "los", "json", []models.DisburseRequest{
+			{LoanId: "LOAN-1", Amount: 10000},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.BatchStatusAccepted, result.Status)
+		assert.Equal(t, "BATCH-000", <-mocks.batchChan)
+		assert.Empty(t, mocks.batchChan)
+	})
+
+	t.Run("returns error for empty batch", func(t *testing.T) {
+		service, mocks := newBatchService()
+
+		result, err := service.Create(ctx, "los", "json", nil)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, models.EMPTY_BATCH)
+		mocks.batch.AssertNotCalled(t, "Create")
+	})
+
+	t.Run("returns error when loan lookup fails", func(t *testing.T) {
+		service, mocks := newBatchService()
+
+		mocks.idGenerator.On("GenerateBatchId").Return("BATCH-123").Once()
+		mocks.loan.On("Get", ctx, "LOAN-1").Return(nil, errors.New("database error")).Once()
+
+		result, err := service.Create(ctx, "los", "json", []models.DisburseRequest{
+			{LoanId: "LOAN-1", Amount: 10000},
+		})
+
+		assert.Nil(t, result)
+		assert.ErrorContains(t, err, "database error")
+		mocks.batch.AssertNotCalled(t, "Create")
+		assert.Empty(t, mocks.batchChan)
+	})
+}
+
+func TestBatchService_CreateFromCSV(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("parses rows by header name", func(t *testing.T) {
+		service, mocks := newBatchService()
+
+		file := strings.NewReader(
+			"amount,loan_id,account_number,ifsc_code,beneficiary_name,beneficiary_bank\n" +
+				"10000,LOAN-1,1234567890,sbin0001234,John Doe,State Bank\n" +
+				"ten,LOAN-2,,,,\n",
+		)
+
+		mocks.idGenerator.On("GenerateBatchId").Return("BATCH-123").Once()
+		mocks.loan.On("Get", ctx, "LOAN-1").Return(&schema.Loan{
+			Id:     "LOAN-1",
+			Amount: 50000,
+			Status: models.LoanStatusSanctioned,
+		}, nil).Once()
+		mocks.batch.On("Create", ctx, mock.Anything, mock.MatchedBy(func(rows []schema.BatchRow) bool {
+			return len(rows) == 2 &&
+				rows[0].LoanId == "LOAN-1" &&
+				rows[0].Amount == 10000 &&
+				rows[0].IFSCCode == "SBIN0001234" &&
+				rows[0].BeneficiaryBank == "State Bank" &&
+				rows[0].Status == models.BatchRowStatusPending &&
+				rows[1].Status == models.BatchRowStatusRejected &&
+				*rows[1].Error == `amount "ten" is not a number`
+		})).Return(&schema.Batch{Id: "BATCH-123", Source: "partner.csv"}, nil).Once()
+
+		result, err := service.CreateFromCSV(ctx, "los", "partner.csv", file)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "partner.csv", result.Source)
+		mocks.batch.AssertExpectations(t)
+	})
+
+	t.Run("returns error when a required column is missing", func(t *testing.T) {
+		service, mocks := newBatchService()
+
+		result, err := service.CreateFromCSV(ctx, "los", "partner.csv", strings.NewReader("loan_id\nLOAN-1\n"))
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, models.INVALID_BATCH_FILE)
+		mocks.batch.AssertNotCalled(t, "Create")
+	})
+}
+
+func TestBatchService_Process(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("submits pending rows and completes the batch", func(t *testing.T) {
+		service, mocks := newBatchService()
+		rejected := "loan not found"
+
+		mocks.batch.On("Get", mock.Anything, "BATCH-123").
+			Return(&schema.Batch{Id: "BATCH-123", ClientId: "los", Status: models.BatchStatusAccepted}, nil).Once()
+		mocks.batch.On("Update", mock.Anything, "BATCH-123", map[string]any{
+			"status": models.BatchStatusProcessing,
+		}).Return(nil).Once()
+		mocks.batch.On("ListRows", mock.Anything, "BATCH-123").Return([]schema.BatchRow{
+			{BatchId: "BATCH-123", RowNumber: 1, LoanId: "LOAN-1", Amount: 10000, Status: models.BatchRowStatusPending},
+			{BatchId: "BATCH-123", RowNumber: 2, LoanId: "LOAN-2", Amount: 10000, Status: models.BatchRowStatusRejected, Error: &rejected},
+			{BatchId: "BATCH-123", RowNumber: 3, LoanId: "LOAN-3", Amount: 5000, Status: models.BatchRowStatusPending},
+		}, nil).Once()
+		mocks.disburser.On("Disburse", mock.Anything, &models.DisburseRequest{LoanId: "LOAN-1", Amount: 10000, ClientId: "los"}).
+			Return(&models.DisbursementResponse{DisbursementId: "DISB-1"}, nil).Once()
+		mocks.disburser.On("Disburse", mock.Anything, &models.DisburseRequest{LoanId: "LOAN-3", Amount: 5000, ClientId: "los"}).
+			Return(nil, models.BENEFICIARY_NOT_VERIFIED).Once()
+		mocks.batch.On("UpdateRow", mock.Anything, "BATCH-123", 1, map[string]any{
+			"status":          models.BatchRowStatusSubmitted,
+			"disbursement_id": "DISB-1",
+		}).Return(nil).Once()
+		mocks.batch.On("UpdateRow", mock.Anything, "BATCH-123", 3, map[string]any{
+			"status": models.BatchRowStatusFailed,
+			"error":  models.BENEFICIARY_NOT_VERIFIED.Error(),
+		}).Return(nil).Once()
+		mocks.batch.On("Update", mock.Anything, "BATCH-123", mock.MatchedBy(func(fields map[string]any) bool {
+			return fields["status"] == models.BatchStatusCompleted && fields["completed_at"] != nil
+		})).Return(nil).Once()
+
+		err := service.Process(ctx, "BATCH-123")
+
+		assert.NoError(t, err)
+		mocks.batch.AssertExpectations(t)
+		mocks.disburser.AssertExpectations(t)
+	})
+
+	t.Run("pauses with the row pending when the payment queue is full", func(t *testing.T) {
+		service, mocks := newBatchService()
+
+		mocks.batch.On("Get", mock.Anything, "BATCH-123").
+			Return(&schema.Batch{Id: "BATCH-123", Status: models.BatchStatusAccepted}, nil).Once()
+		mocks.batch.On("Update", mock.Anything, "BATCH-123", map[string]any{
+			"status": models.BatchStatusProcessing,
+		}).Return(nil).Once()
+		mocks.batch.On("ListRows", mock.Anything, "BATCH-123").Return([]schema.BatchRow{
+			{BatchId: "BATCH-123", RowNumber: 1, LoanId: "LOAN-1", Amount: 10000, Status: models.BatchRowStatusPending},
+			{BatchId: "BATCH-123", RowNumber: 2, LoanId: "LOAN-2", Amount: 10000, Status: models.BatchRowStatusPending},
+		}, nil).Once()
+		mocks.disburser.On("Disburse", mock.Anything, mock.Anything).
+			Return(nil, models.PAYMENT_QUEUE_FULL).Once()
+
+		err := service.Process(ctx, "BATCH-123")
+
+		assert.NoError(t, err)
+		mocks.disburser.AssertNumberOfCalls(t, "Disburse", 1)
+		mocks.batch.AssertNotCalled(t, "UpdateRow", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mocks.batch.AssertExpectations(t)
+	})
+
+	t.Run("skips completed batch", func(t *testing.T) {
+		service, mocks := newBatchService()
+
+		mocks.batch.On("Get", mock.Anything, "BATCH-123").
+			Return(&schema.Batch{Id: "BATCH-123", Status: models.BatchStatusCompleted}, nil).Once()
+
+		err := service.Process(ctx, "BATCH-123")
+
+		assert.NoError(t, err)
+		mocks.batch.AssertNotCalled(t, "ListRows")
+		mocks.disburser.AssertNotCalled(t, "Disburse")
+	})
+
+	t.Run("stops when a row cannot be saved", func(t *testing.T) {
+		service, mocks := newBatchService()
+
+		mocks.batch.On("Get", mock.Anything, "BATCH-123").
+			Return(&schema.Batch{Id: "BATCH-123", Status: models.BatchStatusProcessing}, nil).Once()
+		mocks.batch.On("Update", mock.Anything, "BATCH-123", mock.Anything).Return(nil).Once()
+		mocks.batch.On("ListRows", mock.Anything, "BATCH-123").Return([]schema.BatchRow{
+			{BatchId: "BATCH-123", RowNumber: 1, LoanId: "LOAN-1", Amount: 10000, Status: models.BatchRowStatusPending},
+		}, nil).Once()
+		mocks.disburser.On("Disburse", mock.Anything, mock.Anything).
+			Return(&models.DisbursementResponse{DisbursementId: "DISB-1"}, nil).Once()
+		mocks.batch.On("UpdateRow", mock.Anything, "BATCH-123", 1, mock.Anything).
+			Return(errors.New("database error")).Once()
+
+		err := service.Process(ctx, "BATCH-123")
+
+		assert.ErrorContains(t, err, "failed to update batch row 1")
+		mocks.batch.AssertNumberOfCalls(t, "Update", 1)
+	})
+}
+
+func TestBatchService_Get(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("summarises rows and disbursement statuses", func(t *testing.T) {
+		service, mocks := newBatchService()
+		disb1, disb2 := "DISB-1", "DISB-2"
+		rejected := "loan not found"
+
+		mocks.batch.On("Get", ctx, "BATCH-123").Return(&schema.Batch{
+			Id:           "BATCH-123",
+			Status:       models.BatchStatusCompleted,
+			TotalRows:    3,
+			AcceptedRows: 2,
+			RejectedRows: 1,
+		}, nil).Once()
+		mocks.batch.On("ListRows", ctx, "BATCH-123").Return([]schema.BatchRow{
+			{RowNumber: 1, LoanId: "LOAN-1", Amount: 10000, Status: models.BatchRowStatusSubmitted, DisbursementId: &disb1},
+			{RowNumber: 2, LoanId: "LOAN-2", Amount: 20000, Status: models.BatchRowStatusSubmitted, DisbursementId: &disb2},
+			{RowNumber: 3, LoanId: "LOAN-3", Amount: 30000, Status: models.BatchRowStatusRejected, Error: &rejected},
+		}, nil).Once()
+		mocks.disbursement.On("ListByIds", ctx, []string{disb1, disb2}).Return([]schema.Disbursement{
+			{Id: disb1, Status: models.DisbursementStatusSuccess},
+			{Id: disb2, Status: models.DisbursementStatusFailed},
+		}, nil).Once()
+
+		result, err := service.Get(ctx, "", "BATCH-123")
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.RowStatus[models.BatchRowStatusSubmitted])
+		assert.Equal(t, 1, result.DisbursementStatus[models.DisbursementStatusSuccess])
+		assert.Equal(t, 1, result.DisbursementStatus[models.DisbursementStatusFailed])
+		assert.Equal(t, 30000.0, result.TotalAmount)
+		assert.Equal(t, models.DisbursementStatusSuccess, result.Rows[0].DisbursementStatus)
+	})
+
+	t.Run("returns error when batch not found", func(t *testing.T) {
+		service, mocks := newBatchService()
+
+		mocks.batch.On("Get", ctx, "BATCH-404").Return(nil, gorm.ErrRecordNotFound).Once()
+
+		result, err := service.Get(ctx, "", "BATCH-404")
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("hides another client's batch", func(t *testing.T) {
+		service, mocks := newBatchService()
+
+		mocks.batch.On("Get", ctx, "BATCH-123").
+			Return(&schema.Batch{Id: "BATCH-123", ClientId: "partner-a"}, nil).Once()
+
+		result, err := service.Get(ctx, "partner-b", "BATCH-123")
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, models.BATCH_NOT_FOUND)
+		mocks.batch.AssertNotCalled(t, "ListRows", mock.Anything, mock.Anything)
+	})
+}
+
+func TestBatchService_WriteResults(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("writes one line per uploaded row", func(t *testing.T) {
+		ctx := pii.WithAccess(ctx)
+		service, mocks := newBatchService()
+		disb1 := "DISB-1"
+		rejected := "loan not found"
+
+		mocks.batch.On("Get", ctx, "BATCH-123").Return(&schema.Batch{Id: "BATCH-123"}, nil).Once()
+		mocks.batch.On("ListRows", ctx, "BATCH-123").Return([]schema.BatchRow{
+			{
+				RowNumber:       1,
+				LoanId:          "LOAN-1",
+				Amount:          10000,
+				AccountNumber:   "1234567890",
+				IFSCCode:        "SBIN0001234",
+				BeneficiaryName: "John Doe",
+				Status:          models.BatchRowStatusSubmitted,
+				DisbursementId:  &disb1,
+			},
+			{RowNumber: 2, LoanId: "LOAN-2", Amount: 500.5, Status: models.BatchRowStatusRejected, Error: &rejected},
+		}, nil).Once()
+		mocks.disbursement.On("ListByIds", ctx, []string{disb1}).Return([]schema.Disbursement{
+			{Id: disb1, Status: models.DisbursementStatusProcessing},
+		}, nil).Once()
+
+		var buf bytes.Buffer
+		err := service.WriteResults(ctx, "", "BATCH-123", &buf)
+
+		assert.NoError(t, err)
+		assert.Equal(t,
+			"row_number,loan_id,amount,account_number,ifsc_code,beneficiary_name,beneficiary_bank,scheduled_at,status,error,disbursement_id,disbursement_status\n"+
+				"1,LOAN-1,10000.00,1234567890,SBIN0001234,John Doe,,,submitted,,DISB-1,processing\n"+
+				"2,LOAN-2,500.50,,,,,,rejected,loan not found,,\n",
+			buf.String(),
+		)
+	})
+	t.Run("masks account numbers and names for callers without pii access", func(t *testing.T) {
+		service, mocks := newBatchService()
+		frozen := "account 123456789012 is frozen"
+
+		mocks.batch.On("Get", ctx, "BATCH-123").Return(&schema.Batch{Id: "BATCH-123"}, nil).Once()
+		mocks.batch.On("ListRows", ctx, "BATCH-123").Return([]schema.BatchRow{
+			{
+				RowNumber:       1,
+				LoanId:          "LOAN-1",
+				Amount:          10000,
+				AccountNumber:   "123456789012",
+				IFSCCode:        "SBIN0001234",
+				BeneficiaryName: "John Doe",
+				Status:          models.BatchRowStatusRejected,
+				Error:           &frozen,
+			},
+		}, nil).Once()
+
+		var buf bytes.Buffer
+		err := service.WriteResults(ctx, "", "BATCH-123", &buf)
+
+		assert.NoError(t, err)
+		assert.Equal(t,
+			"row_number,loan_id,amount,account_number,ifsc_code,beneficiary_name,beneficiary_bank,scheduled_at,status,error,disbursement_id,disbursement_status\n"+
+				"1,LOAN-1,10000.00,XXXXXXXX9012,SBIN0001234,J*** D**,,,rejected,account XXXXXXXX9012 is frozen,,\n",
+			buf.String(),
+		)
+	})
+}
@@ -0,0 +1,450 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"loan-disbursement-service/db/daos"
+	"loan-disbursement-service/db/schema"
+	"loan-disbursement-service/models"
+	"loan-disbursement-service/namematch"
+	"loan-disbursement-service/providers"
+	"loan-disbursement-service/utils"
+	"shared/ifsc"
+	"shared/logging"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type BeneficiaryService interface {
+	Create(ctx context.Context, req models.BeneficiaryRequest) (*models.BeneficiaryResponse, error)
+	// Register stores the beneficiary like Create but leaves the penny drop
+	// to PennyDropPending, for callers that cannot wait for the gateway.
+	Register(ctx context.Context, req models.BeneficiaryRequest) (*models.BeneficiaryResponse, error)
+	// PennyDropPending penny drops up to limit unverified beneficiaries whose
+	// account has not been penny dropped yet, and returns how many it did.
+	PennyDropPending(ctx context.Context, limit int) (int, error)
+	Update(
+		ctx context.Context,
+		beneficiaryId string,
+		req models.BeneficiaryRequest,
+	) (*models.BeneficiaryResponse, error)
+	List(ctx context.Context) ([]models.BeneficiaryResponse, error)
+	Get(ctx context.Context, beneficiaryId string) (*models.BeneficiaryResponse, error)
+	Delete(ctx context.Context, beneficiaryId string) error
+	Verify(
+		ctx context.Context,
+		beneficiaryId string,
+		req models.BeneficiaryVerificationRequest,
+	) (*models.BeneficiaryResponse, error)
+	// Override lets an unverified beneficiary be paid, recording operator as
+	// its approver.
+	Override(
+		ctx context.Context,
+		operator models.Operator,
+		beneficiaryId string,
+		req models.BeneficiaryOverrideRequest,
+	) (*models.BeneficiaryResponse, error)
+}
+
+type BeneficiaryServiceImpl struct {
+	beneficiary     daos.BeneficiaryRepository
+	loan            daos.LoanRepository
+	paymentProvider providers.PaymentProvider
+	idGenerator     utils.IdGenerator
+	directory       *ifsc.Directory
+}
+
+func NewBeneficiaryService(
+	beneficiary daos.BeneficiaryRepository,
+	loan daos.LoanRepository,
+	paymentProvider providers.PaymentProvider,
+	idGenerator utils.IdGenerator,
+	directory *ifsc.Directory,
+) BeneficiaryService {
+	return &BeneficiaryServiceImpl{
+		beneficiary:     beneficiary,
+		loan:            loan,
+		paymentProvider: paymentProvider,
+		idGenerator:     idGenerator,
+		directory:       directory,
+	}
+}
+
+func (s *BeneficiaryServiceImpl) Create(
+	ctx context.Context,
+	req models.BeneficiaryRequest,
+) (*models.BeneficiaryResponse, error) {
+	beneficiary, err := s.register(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if beneficiary.PennyDroppedAt != nil {
+		return s.toModel(beneficiary), nil
+	}
+
+	fields := s.pennyDrop(ctx, beneficiary.Id, models.Beneficiary{
+		Name:    beneficiary.Name,
+		Account: beneficiary.Account,
+		IFSC:    beneficiary.IFSC,
+		Bank:    beneficiary.Bank,
+	})
+	if len(fields) == 0 {
+		return s.toModel(beneficiary), nil
+	}
+	if err := s.beneficiary.Update(ctx, beneficiary.Id, fields); err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, beneficiary.Id)
+}
+
+func (s *BeneficiaryServiceImpl) Register(
+	ctx context.Context,
+	req models.BeneficiaryRequest,
+) (*models.BeneficiaryResponse, error) {
+	beneficiary, err := s.register(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return s.toModel(beneficiary), nil
+}
+
+func (s *BeneficiaryServiceImpl) register(
+	ctx context.Context,
+	req models.BeneficiaryRequest,
+) (*schema.Beneficiary, error) {
+	bank, err := resolveBank(s.directory, req.IFSCCode, req.Bank)
+	if err != nil {
+		return nil, err
+	}
+	return s.beneficiary.CreateOrGet(
+		ctx,
+		s.idGenerator.GenerateBeneficiaryId(),
+		req.Name,
+		req.AccountNumber,
+		req.IFSCCode,
+		bank,
+	)
+}
+
+func (s *BeneficiaryServiceImpl) PennyDropPending(ctx context.Context, limit int) (int, error) {
+	beneficiaries, err := s.beneficiary.ListPendingPennyDrop(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+	dropped := 0
+	for _, beneficiary := range beneficiaries {
+		fields := s.pennyDrop(ctx, beneficiary.Id, models.Beneficiary{
+			Name:    beneficiary.Name,
+			Account: beneficiary.Account,
+			IFSC:    beneficiary.IFSC,
+			Bank:    beneficiary.Bank,
+		})
+		recorded := len(fields) != 0
+		if !recorded {
+			// Moved to the back, so an account the gateway keeps failing on
+			// does not hold up the rest.
+			fields = map[string]any{"updated_at": time.Now()}
+		}
+		if err := s.beneficiary.Update(ctx, beneficiary.Id, fields); err != nil {
+			log.Ctx(ctx).Error().Err(err).
+				Str("beneficiary_id", beneficiary.Id).
+				Msg("failed to record penny drop")
+			continue
+		}
+		if recorded {
+			dropped++
+		}
+	}
+	return dropped, nil
+}
+
+func (s *BeneficiaryServiceImpl) Update(
+	ctx context.Context,
+	beneficiaryId string,
+	req models.BeneficiaryRequest,
+) (*models.BeneficiaryResponse, error) {
+	existing, err := s.beneficiary.GetById(ctx, beneficiaryId)
+	if err != nil {
+		return nil, err
+	}
+	if req.IFSCCode != "" {
+		if req.Bank, err = resolveBank(s.directory, req.IFSCCode, req.Bank); err != nil {
+			return nil, err
+		}
+	}
+
+	fields := map[string]any{
+		"name":       stringOr(req.Name, existing.Name),
+		"account":    stringOr(req.AccountNumber, existing.Account),
+		"ifsc":       stringOr(req.IFSCCode, existing.IFSC),
+		"bank":       stringOr(req.Bank, existing.Bank),
+		"updated_at": time.Now(),
+	}
+
+	// Any change to the payee details invalidates the previous KYC outcome.
+	if fields["name"] != existing.Name ||
+		fields["account"] != existing.Account ||
+		fields["ifsc"] != existing.IFSC ||
+		fields["bank"] != existing.Bank {
+		if err := s.checkUnlocked(ctx, beneficiaryId); err != nil {
+			return nil, err
+		}
+		fields["status"] = models.BeneficiaryStatusUnverified
+		fields["status_reason"] = nil
+		fields["verified_at"] = nil
+		fields["override_reason"] = nil
+		fields["overridden_by"] = nil
+		fields["overridden_at"] = nil
+		fields["registered_name"] = nil
+		fields["name_match_score"] = nil
+		fields["penny_drop_ref"] = nil
+		fields["penny_dropped_at"] = nil
+
+		pennyDrop := s.pennyDrop(ctx, beneficiaryId, models.Beneficiary{
+			Name:    fields["name"].(string),
+			Account: fields["account"].(string),
+			IFSC:    fields["ifsc"].(string),
+			Bank:    fields["bank"].(string),
+		})
+		for key, value := range pennyDrop {
+			fields[key] = value
+		}
+	}
+
+	if err := s.beneficiary.Update(ctx, beneficiaryId, fields); err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, beneficiaryId)
+}
+
+// checkUnlocked refuses changes to the payee details while a loan paying
+// the beneficiary is reserved for a disbursement still to be sent or
+// settled. Details of a payout under way go through the admin correction.
+func (s *BeneficiaryServiceImpl) checkUnlocked(ctx context.Context, beneficiaryId string) error {
+	loans, err := s.loan.ListByBeneficiary(ctx, beneficiaryId)
+	if err != nil {
+		return err
+	}
+	for _, loan := range loans {
+		if loan.Status == models.LoanStatusDisbursementPending {
+			return fmt.Errorf("loan %s is %s: %w", loan.Id, loan.Status, models.BENEFICIARY_LOCKED)
+		}
+	}
+	return nil
+}
+
+func (s *BeneficiaryServiceImpl) List(ctx context.Context) ([]models.BeneficiaryResponse, error) {
+	beneficiaries, err := s.beneficiary.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.BeneficiaryResponse, 0, len(beneficiaries))
+	for i := range beneficiaries {
+		if mapped := s.toModel(&beneficiaries[i]); mapped != nil {
+			result = append(result, *mapped)
+		}
+	}
+	return result, nil
+}
+
+func (s *BeneficiaryServiceImpl) Get(
+	ctx context.Context,
+	beneficiaryId string,
+) (*models.BeneficiaryResponse, error) {
+	beneficiary, err := s.beneficiary.GetById(ctx, beneficiaryId)
+	if err != nil {
+		return nil, err
+	}
+	return s.toModel(beneficiary), nil
+}
+
+func (s *BeneficiaryServiceImpl) Delete(ctx context.Context, beneficiaryId string) error {
+	loans, err := s.loan.ListByBeneficiary(ctx, beneficiaryId)
+	if err != nil {
+		return err
+	}
+	if len(loans) > 0 {
+		return models.BENEFICIARY_IN_USE
+	}
+	return s.beneficiary.Delete(ctx, beneficiaryId)
+}
+
+func (s *BeneficiaryServiceImpl) Verify(
+	ctx context.Context,
+	beneficiaryId string,
+	req models.BeneficiaryVerificationRequest,
+) (*models.BeneficiaryResponse, error) {
+	if _, err := s.beneficiary.GetById(ctx, beneficiaryId); err != nil {
+		return nil, err
+	}
+
+	fields := map[string]any{
+		"status":        req.Status,
+		"status_reason": nilIfEmpty(req.Reason),
+		"updated_at":    time.Now(),
+	}
+	switch req.Status {
+	case models.BeneficiaryStatusVerified:
+		fields["verified_at"] = time.Now()
+	case models.BeneficiaryStatusRejected:
+		if req.Reason == "" {
+			return nil, models.REJECTION_REASON_REQUIRED
+		}
+		fields["verified_at"] = nil
+	case models.BeneficiaryStatusUnverified:
+		fields["verified_at"] = nil
+	default:
+		return nil, models.INVALID_BENEFICIARY_STATUS
+	}
+
+	if err := s.beneficiary.Update(ctx, beneficiaryId, fields); err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, beneficiaryId)
+}
+
+func (s *BeneficiaryServiceImpl) Override(
+	ctx context.Context,
+	operator models.Operator,
+	beneficiaryId string,
+	req models.BeneficiaryOverrideRequest,
+) (*models.BeneficiaryResponse, error) {
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, models.OVERRIDE_APPROVAL_REQUIRED
+	}
+
+	beneficiary, err := s.beneficiary.GetById(ctx, beneficiaryId)
+	if err != nil {
+		return nil, err
+	}
+	if beneficiary.Status == models.BeneficiaryStatusRejected {
+		return nil, models.BENEFICIARY_REJECTED
+	}
+
+	err = s.beneficiary.Update(ctx, beneficiaryId, map[string]any{
+		"override_reason": req.Reason,
+		"overridden_by":   operator.Id,
+		"overridden_at":   time.Now(),
+		"updated_at":      time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, beneficiaryId)
+}
+
+func (s *BeneficiaryServiceImpl) toModel(beneficiary *schema.Beneficiary) *models.BeneficiaryResponse {
+	if beneficiary == nil {
+		return nil
+	}
+	return &models.BeneficiaryResponse{
+		Id:             beneficiary.Id,
+		Name:           beneficiary.Name,
+		AccountNumber:  beneficiary.Account,
+		IFSCCode:       beneficiary.IFSC,
+		Bank:           beneficiary.Bank,
+		Status:         beneficiary.Status,
+		StatusReason:   beneficiary.StatusReason,
+		VerifiedAt:     beneficiary.VerifiedAt,
+		OverrideReason: beneficiary.OverrideReason,
+		OverriddenBy:   beneficiary.OverriddenBy,
+		OverriddenAt:   beneficiary.OverriddenAt,
+		RegisteredName: beneficiary.RegisteredName,
+		NameMatchScore: beneficiary.NameMatchScore,
+		PennyDroppedAt: beneficiary.PennyDroppedAt,
+		CreatedAt:      beneficiary.CreatedAt,
+		UpdatedAt:      beneficiary.UpdatedAt,
+	}
+}
+
+// pennyDrop validates the account with a ₹1 transfer through the payment
+// gateway and returns the beneficiary fields to record. Accounts the bank
+// rejects outright mark the beneficiary as rejected; any other gateway error
+// returns no fields so the check is attempted again on the next registration.
+// When the bank has no holder name on record, no name or score is recorded.
+func (s *BeneficiaryServiceImpl) pennyDrop(
+	ctx context.Context,
+	beneficiaryId string,
+	details models.Beneficiary,
+) map[string]any {
+	referenceId := s.idGenerator.GenerateReferenceId()
+	ctx = logging.With(ctx, logging.Fields{ReferenceId: referenceId})
+	result, err := s.paymentProvider.VerifyAccount(ctx, models.AccountVerificationRequest{
+		ReferenceID: referenceId,
+		Beneficiary: details,
+	})
+	if err != nil {
+		for _, permanentErr := range models.PERMANENT_FAILURES {
+			if strings.Contains(err.Error(), permanentErr.Error()) {
+				log.Ctx(ctx).Warn().Err(err).
+					Str("beneficiary_id", beneficiaryId).
+					Msg("penny drop rejected beneficiary")
+				return map[string]any{
+					"status":           models.BeneficiaryStatusRejected,
+					"status_reason":    err.Error(),
+					"verified_at":      nil,
+					"penny_drop_ref":   referenceId,
+					"penny_dropped_at": time.Now(),
+				}
+			}
+		}
+		log.Ctx(ctx).Error().Err(err).
+			Str("beneficiary_id", beneficiaryId).
+			Msg("penny drop failed")
+		return nil
+	}
+
+	fields := map[string]any{
+		"registered_name":  nil,
+		"name_match_score": nil,
+		"penny_drop_ref":   referenceId,
+		"penny_dropped_at": result.VerifiedAt,
+	}
+	if result.RegisteredName != "" {
+		fields["registered_name"] = result.RegisteredName
+		fields["name_match_score"] = namematch.Score(details.Name, result.RegisteredName)
+	}
+	return fields
+}
+
+// checkPayable reports whether funds may be sent to the beneficiary: it must
+// be verified, or still unverified with a recorded override.
+func checkPayable(beneficiary *schema.Beneficiary) error {
+	switch beneficiary.Status {
+	case models.BeneficiaryStatusVerified:
+		return nil
+	case models.BeneficiaryStatusRejected:
+		return models.BENEFICIARY_REJECTED
+	}
+	if beneficiary.OverrideReason != nil && beneficiary.OverriddenAt != nil {
+		return nil
+	}
+	return models.BENEFICIARY_NOT_VERIFIED
+}
+
+// resolveBank checks the IFSC is in the bank directory and returns the bank
+// its branch belongs to, which replaces whatever bank the caller gave.
+func resolveBank(directory *ifsc.Directory, code, bank string) (string, error) {
+	branch, ok := directory.Resolve(code)
+	if !ok {
+		return "", fmt.Errorf("%w: %q", models.UNKNOWN_IFSC, code)
+	}
+	return stringOr(branch.Bank, bank), nil
+}
+
+func stringOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+func nilIfEmpty(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
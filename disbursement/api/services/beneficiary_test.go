@@ -0,0 +1,683 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"loan-disbursement-service/config"
+	"loan-disbursement-service/db/schema"
+	"loan-disbursement-service/models"
+	db_test "loan-disbursement-service/test/db"
+	provider_test "loan-disbursement-service/test/providers"
+	utils_test "loan-disbursement-service/test/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestBeneficiaryService_Create(t *testing.T) {
+	ctx := context.Background()
+
+	req := models.BeneficiaryRequest{
+		Name:          "John Doe",
+		AccountNumber: "1234567890",
+		IFSCCode:      "HDFC0001234",
+		Bank:          "HDFC Bank",
+	}
+	verificationRequest := models.AccountVerificationRequest{
+		ReferenceID: "REF-123",
+		Beneficiary: models.Beneficiary{
+			Name:    req.Name,
+			Account: req.AccountNumber,
+			IFSC:    req.IFSCCode,
+			Bank:    req.Bank,
+		},
+	}
+	newBeneficiary := func() *schema.Beneficiary {
+		return &schema.Beneficiary{
+			Id:      "BEN-123",
+			Name:    req.Name,
+			Account: req.AccountNumber,
+			IFSC:    req.IFSCCode,
+			Bank:    req.Bank,
+			Status:  models.BeneficiaryStatusUnverified,
+		}
+	}
+
+	t.Run("records penny drop result for new beneficiary", func(t *testing.T) {
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockProvider := new(provider_test.MockGatewayProvider)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewBeneficiaryService(mockBeneficiary, mockLoan, mockProvider, mockIdGenerator, nil)
+
+		registeredName := "JOHN DOE"
+		score := 100.0
+		verified := newBeneficiary()
+		verified.RegisteredName = &registeredName
+		verified.NameMatchScore = &score
+
+		mockIdGenerator.On("GenerateBeneficiaryId").Return("BEN-123").Once()
+		mockIdGenerator.On("GenerateReferenceId").Return("REF-123").Once()
+		mockBeneficiary.On("CreateOrGet", ctx, "BEN-123", req.Name, req.AccountNumber, req.IFSCCode, req.Bank).
+			Return(newBeneficiary(), nil).
+			Once()
+		mockProvider.On("VerifyAccount", mock.Anything, verificationRequest).
+			Return(models.AccountVerificationResponse{
+				TransactionID:  "IMPS-TXN-123",
+				ReferenceID:    "REF-123",
+				RegisteredName: registeredName,
+				Status:         models.TransactionStatusSuccess,
+				VerifiedAt:     time.Now(),
+			}, nil).
+			Once()
+		mockBeneficiary.On("Update", ctx, "BEN-123", mock.MatchedBy(func(fields map[string]any) bool {
+			return fields["registered_name"] == registeredName &&
+				fields["name_match_score"] == score &&
+				fields["penny_drop_ref"] == "REF-123"
+		})).Return(nil).Once()
+		mockBeneficiary.On("GetById", ctx, "BEN-123").Return(verified, nil).Once()
+
+		result, err := service.Create(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "BEN-123", result.Id)
+		assert.Equal(t, models.BeneficiaryStatusUnverified, result.Status)
+		assert.Equal(t, registeredName, *result.RegisteredName)
+		assert.Equal(t, score, *result.NameMatchScore)
+
+		mockBeneficiary.AssertExpectations(t)
+		mockProvider.AssertExpectations(t)
+		mockIdGenerator.AssertExpectations(t)
+	})
+
+	t.Run("records a score below the block threshold when the bank holds another name", func(t *testing.T) {
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockProvider := new(provider_test.MockGatewayProvider)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewBeneficiaryService(mockBeneficiary, mockLoan, mockProvider, mockIdGenerator, nil)
+
+		mockIdGenerator.On("GenerateBeneficiaryId").Return("BEN-123").Once()
+		mockIdGenerator.On("GenerateReferenceId").Return("REF-123").Once()
+		mockBeneficiary.On("CreateOrGet", ctx, "BEN-123", req.Name, req.AccountNumber, req.IFSCCode, req.Bank).
+			Return(newBeneficiary(), nil).
+			Once()
+		mockProvider.On("VerifyAccount", mock.Anything, verificationRequest).
+			Return(models.AccountVerificationResponse{
+				RegisteredName: "ACME TRADERS PRIVATE LIMITED",
+				Status:         models.TransactionStatusSuccess,
+				VerifiedAt:     time.Now(),
+			}, nil).
+			Once()
+		mockBeneficiary.On("Update", ctx, "BEN-123", mock.MatchedBy(func(fields map[string]any) bool {
+			score, ok := fields["name_match_score"].(float64)
+			return fields["registered_name"] == "ACME TRADERS PRIVATE LIMITED" &&
+				ok && score < config.Default().NameMatch.BlockThreshold
+		})).Return(nil).Once()
+		mockBeneficiary.On("GetById", ctx, "BEN-123").Return(newBeneficiary(), nil).Once()
+
+		_, err := service.Create(ctx, req)
+
+		assert.NoError(t, err)
+		mockBeneficiary.AssertExpectations(t)
+	})
+
+	t.Run("records no name or score when the bank has none on record", func(t *testing.T) {
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockProvider := new(provider_test.MockGatewayProvider)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewBeneficiaryService(mockBeneficiary, mockLoan, mockProvider, mockIdGenerator, nil)
+
+		mockIdGenerator.On("GenerateBeneficiaryId").Return("BEN-123").Once()
+		mockIdGenerator.On("GenerateReferenceId").Return("REF-123").Once()
+		mockBeneficiary.On("CreateOrGet", ctx, "BEN-123", req.Name, req.AccountNumber, req.IFSCCode, req.Bank).
+			Return(newBeneficiary(), nil).
+			Once()
+		mockProvider.On("VerifyAccount", mock.Anything, verificationRequest).
+			Return(models.AccountVerificationResponse{
+				Status:     models.TransactionStatusSuccess,
+				VerifiedAt: time.Now(),
+			}, nil).
+			Once()
+		mockBeneficiary.On("Update", ctx, "BEN-123", mock.MatchedBy(func(fields map[string]any) bool {
+			return fields["registered_name"] == nil &&
+				fields["name_match_score"] == nil &&
+				fields["penny_drop_ref"] == "REF-123"
+		})).Return(nil).Once()
+		mockBeneficiary.On("GetById", ctx, "BEN-123").Return(newBeneficiary(), nil).Once()
+
+		result, err := service.Create(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Nil(t, result.RegisteredName)
+		assert.Nil(t, result.NameMatchScore)
+		mockBeneficiary.AssertExpectations(t)
+	})
+
+	t.Run("names the bank after the branch", func(t *testing.T) {
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockProvider := new(provider_test.MockGatewayProvider)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewBeneficiaryService(mockBeneficiary, mockLoan, mockProvider, mockIdGenerator, newTestDirectory(t))
+
+		verified := newBeneficiary()
+		verified.Bank = "HDFC Bank"
+		verified.PennyDroppedAt = &time.Time{}
+
+		mockIdGenerator.On("GenerateBeneficiaryId").Return("BEN-123").Once()
+		mockBeneficiary.On("CreateOrGet", ctx, "BEN-123", req.Name, req.AccountNumber, req.IFSCCode, "HDFC Bank").
+			Return(verified, nil).
+			Once()
+
+		result, err := service.Create(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "HDFC Bank", result.Bank)
+		mockBeneficiary.AssertExpectations(t)
+	})
+
+	t.Run("rejects an IFSC missing from the directory", func(t *testing.T) {
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockProvider := new(provider_test.MockGatewayProvider)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewBeneficiaryService(mockBeneficiary, mockLoan, mockProvider, mockIdGenerator, newTestDirectory(t))
+
+		unknown := req
+		unknown.IFSCCode = "HDFC0009999"
+
+		result, err := service.Create(ctx, unknown)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, models.UNKNOWN_IFSC)
+		mockBeneficiary.AssertNotCalled(t, "CreateOrGet")
+		mockProvider.AssertNotCalled(t, "VerifyAccount")
+	})
+
+	t.Run("rejects beneficiary when bank reports inactive account", func(t *testing.T) {
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockProvider := new(provider_test.MockGatewayProvider)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewBeneficiaryService(mockBeneficiary, mockLoan, mockProvider, mockIdGenerator, nil)
+
+		rejected := newBeneficiary()
+		rejected.Status = models.BeneficiaryStatusRejected
+
+		mockIdGenerator.On("GenerateBeneficiaryId").Return("BEN-123").Once()
+		mockIdGenerator.On("GenerateReferenceId").Return("REF-123").Once()
+		mockBeneficiary.On("CreateOrGet", ctx, "BEN-123", req.Name, req.AccountNumber, req.IFSCCode, req.Bank).
+			Return(newBeneficiary(), nil).
+			Once()
+		mockProvider.On("VerifyAccount", mock.Anything, verificationRequest).
+			Return(models.AccountVerificationResponse{}, errors.New("Inactive Beneficiary Account")).
+			Once()
+		mockBeneficiary.On("Update", ctx, "BEN-123", mock.MatchedBy(func(fields map[string]any) bool {
+			return fields["status"] == models.BeneficiaryStatusRejected &&
+				fields["status_reason"] == "Inactive Beneficiary Account"
+		})).Return(nil).Once()
+		mockBeneficiary.On("GetById", ctx, "BEN-123").Return(rejected, nil).Once()
+
+		result, err := service.Create(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.BeneficiaryStatusRejected, result.Status)
+		mockBeneficiary.AssertExpectations(t)
+	})
+
+	t.Run("leaves beneficiary unverified when gateway is unreachable", func(t *testing.T) {
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockProvider := new(provider_test.MockGatewayProvider)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewBeneficiaryService(mockBeneficiary, mockLoan, mockProvider, mockIdGenerator, nil)
+
+		mockIdGenerator.On("GenerateBeneficiaryId").Return("BEN-123").Once()
+		mockIdGenerator.On("GenerateReferenceId").Return("REF-123").Once()
+		mockBeneficiary.On("CreateOrGet", ctx, "BEN-123", req.Name, req.AccountNumber, req.IFSCCode, req.Bank).
+			Return(newBeneficiary(), nil).
+			Once()
+		mockProvider.On("VerifyAccount", mock.Anything, verificationRequest).
+			Return(models.AccountVerificationResponse{}, models.NETWORK_ERROR).
+			Once()
+
+		result, err := service.Create(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.BeneficiaryStatusUnverified, result.Status)
+		assert.Nil(t, result.RegisteredName)
+		mockBeneficiary.AssertNotCalled(t, "Update")
+	})
+
+	t.Run("skips penny drop for already verified account", func(t *testing.T) {
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockProvider := new(provider_test.MockGatewayProvider)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewBeneficiaryService(mockBeneficiary, mockLoan, mockProvider, mockIdGenerator, nil)
+
+		existing := verifiedBeneficiary("BEN-EXISTING")
+		droppedAt := time.Now()
+		existing.PennyDroppedAt = &droppedAt
+
+		mockIdGenerator.On("GenerateBeneficiaryId").Return("BEN-123").Once()
+		mockBeneficiary.On("CreateOrGet", ctx, "BEN-123", req.Name, req.AccountNumber, req.IFSCCode, req.Bank).
+			Return(existing, nil).
+			Once()
+
+		result, err := service.Create(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "BEN-EXISTING", result.Id)
+		mockProvider.AssertNotCalled(t, "VerifyAccount")
+	})
+
+	t.Run("returns error when repository fails", func(t *testing.T) {
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockProvider := new(provider_test.MockGatewayProvider)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewBeneficiaryService(mockBeneficiary, mockLoan, mockProvider, mockIdGenerator, nil)
+
+		repoError := errors.New("database error")
+		mockIdGenerator.On("GenerateBeneficiaryId").Return("BEN-123").Once()
+		mockBeneficiary.On("CreateOrGet", ctx, "BEN-123", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(nil, repoError).
+			Once()
+
+		result, err := service.Create(ctx, models.BeneficiaryRequest{Name: "John Doe"})
+
+		assert.Nil(t, result)
+		assert.Equal(t, repoError, err)
+		mockProvider.AssertNotCalled(t, "VerifyAccount")
+	})
+}
+
+func TestBeneficiaryService_Register(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stores the beneficiary without a penny drop", func(t *testing.T) {
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockProvider := new(provider_test.MockGatewayProvider)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewBeneficiaryService(mockBeneficiary, nil, mockProvider, mockIdGenerator, nil)
+
+		mockIdGenerator.On("GenerateBeneficiaryId").Return("BEN-123").Once()
+		mockBeneficiary.On("CreateOrGet", ctx, "BEN-123", "John Doe", "1234567890", "HDFC0001234", "HDFC Bank").
+			Return(&schema.Beneficiary{Id: "BEN-123", Status: models.BeneficiaryStatusUnverified}, nil).
+			Once()
+
+		result, err := service.Register(ctx, models.BeneficiaryRequest{
+			Name:          "John Doe",
+			AccountNumber: "1234567890",
+			IFSCCode:      "HDFC0001234",
+			Bank:          "HDFC Bank",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.BeneficiaryStatusUnverified, result.Status)
+		mockBeneficiary.AssertExpectations(t)
+		mockProvider.AssertNotCalled(t, "VerifyAccount", mock.Anything, mock.Anything)
+	})
+}
+
+func TestBeneficiaryService_PennyDropPending(t *testing.T) {
+	ctx := context.Background()
+	pending := []schema.Beneficiary{
+		{Id: "BEN-1", Name: "John Doe", Account: "1234567890", IFSC: "HDFC0001234", Bank: "HDFC Bank"},
+		{Id: "BEN-2", Name: "Jane Doe", Account: "9876543210", IFSC: "HDFC0001234", Bank: "HDFC Bank"},
+	}
+
+	t.Run("records the penny drop and moves gateway failures to the back", func(t *testing.T) {
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockProvider := new(provider_test.MockGatewayProvider)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewBeneficiaryService(mockBeneficiary, nil, mockProvider, mockIdGenerator, nil)
+
+		mockBeneficiary.On("ListPendingPennyDrop", ctx, 10).Return(pending, nil).Once()
+		mockIdGenerator.On("GenerateReferenceId").Return("REF-1").Once()
+		mockIdGenerator.On("GenerateReferenceId").Return("REF-2").Once()
+		mockProvider.On("VerifyAccount", mock.Anything, mock.MatchedBy(func(req models.AccountVerificationRequest) bool {
+			return req.Beneficiary.Account == "1234567890"
+		})).Return(models.AccountVerificationResponse{RegisteredName: "JOHN DOE", VerifiedAt: time.Now()}, nil).Once()
+		mockProvider.On("VerifyAccount", mock.Anything, mock.MatchedBy(func(req models.AccountVerificationRequest) bool {
+			return req.Beneficiary.Account == "9876543210"
+		})).Return(models.AccountVerificationResponse{}, errors.New("gateway unavailable")).Once()
+		mockBeneficiary.On("Update", ctx, "BEN-1", mock.MatchedBy(func(fields map[string]any) bool {
+			return fields["registered_name"] == "JOHN DOE" && fields["penny_drop_ref"] == "REF-1"
+		})).Return(nil).Once()
+		mockBeneficiary.On("Update", ctx, "BEN-2", mock.MatchedBy(func(fields map[string]any) bool {
+			_, touched := fields["updated_at"]
+			return touched && len(fields) == 1
+		})).Return(nil).Once()
+
+		dropped, err := service.PennyDropPending(ctx, 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, dropped)
+		mockBeneficiary.AssertExpectations(t)
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("returns the error when listing fails", func(t *testing.T) {
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+
+		service := NewBeneficiaryService(mockBeneficiary, nil, nil, nil, nil)
+
+		mockBeneficiary.On("ListPendingPennyDrop", ctx, 10).Return(nil, errors.New("db down")).Once()
+
+		dropped, err := service.PennyDropPending(ctx, 10)
+
+		assert.Error(t, err)
+		assert.Zero(t, dropped)
+	})
+}
+
+func TestBeneficiaryService_Update(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("resets verification when account details change", func(t *testing.T) {
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockProvider := new(provider_test.MockGatewayProvider)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewBeneficiaryService(mockBeneficiary, mockLoan, mockProvider, mockIdGenerator, nil)
+
+		existing := verifiedBeneficiary("BEN-123")
+		updated := *existing
+		updated.Account = "9999999999"
+		updated.Status = models.BeneficiaryStatusUnverified
+
+		mockBeneficiary.On("GetById", ctx, "BEN-123").Return(existing, nil).Once()
+		mockLoan.On("ListByBeneficiary", ctx, "BEN-123").
+			Return([]schema.Loan{{Id: "LOAN-123", Status: models.LoanStatusPartiallyDisbursed}}, nil).
+			Once()
+		mockIdGenerator.On("GenerateReferenceId").Return("REF-456").Once()
+		mockProvider.On("VerifyAccount", mock.Anything, models.AccountVerificationRequest{
+			ReferenceID: "REF-456",
+			Beneficiary: models.Beneficiary{
+				Name:    existing.Name,
+				Account: "9999999999",
+				IFSC:    existing.IFSC,
+				Bank:    existing.Bank,
+			},
+		}).Return(models.AccountVerificationResponse{RegisteredName: "JOHN DOE"}, nil).Once()
+		mockBeneficiary.On("Update", ctx, "BEN-123", mock.MatchedBy(func(fields map[string]any) bool {
+			return fields["account"] == "9999999999" &&
+				fields["name"] == existing.Name &&
+				fields["status"] == models.BeneficiaryStatusUnverified &&
+				fields["verified_at"] == nil &&
+				fields["registered_name"] == "JOHN DOE" &&
+				fields["penny_drop_ref"] == "REF-456"
+		})).Return(nil).Once()
+		mockBeneficiary.On("GetById", ctx, "BEN-123").Return(&updated, nil).Once()
+
+		result, err := service.Update(ctx, "BEN-123", models.BeneficiaryRequest{
+			AccountNumber: "9999999999",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.BeneficiaryStatusUnverified, result.Status)
+		mockBeneficiary.AssertExpectations(t)
+	})
+
+	t.Run("refuses payee changes while a loan is being disbursed", func(t *testing.T) {
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockProvider := new(provider_test.MockGatewayProvider)
+
+		service := NewBeneficiaryService(mockBeneficiary, mockLoan, mockProvider, nil, nil)
+
+		mockBeneficiary.On("GetById", ctx, "BEN-123").Return(verifiedBeneficiary("BEN-123"), nil).Once()
+		mockLoan.On("ListByBeneficiary", ctx, "BEN-123").
+			Return([]schema.Loan{{Id: "LOAN-123", Status: models.LoanStatusDisbursementPending}}, nil).
+			Once()
+
+		result, err := service.Update(ctx, "BEN-123", models.BeneficiaryRequest{
+			AccountNumber: "9999999999",
+		})
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, models.BENEFICIARY_LOCKED)
+		mockProvider.AssertNotCalled(t, "VerifyAccount", mock.Anything, mock.Anything)
+		mockBeneficiary.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("keeps verification when details are unchanged", func(t *testing.T) {
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockProvider := new(provider_test.MockGatewayProvider)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewBeneficiaryService(mockBeneficiary, mockLoan, mockProvider, mockIdGenerator, nil)
+
+		existing := verifiedBeneficiary("BEN-123")
+
+		mockBeneficiary.On("GetById", ctx, "BEN-123").Return(existing, nil).Twice()
+		mockBeneficiary.On("Update", ctx, "BEN-123", mock.MatchedBy(func(fields map[string]any) bool {
+			_, reset := fields["status"]
+			return !reset
+		})).Return(nil).Once()
+
+		result, err := service.Update(ctx, "BEN-123", models.BeneficiaryRequest{Name: existing.Name})
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.BeneficiaryStatusVerified, result.Status)
+		mockBeneficiary.AssertExpectations(t)
+	})
+
+	t.Run("returns not found for unknown beneficiary", func(t *testing.T) {
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockProvider := new(provider_test.MockGatewayProvider)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewBeneficiaryService(mockBeneficiary, mockLoan, mockProvider, mockIdGenerator, nil)
+
+		mockBeneficiary.On("GetById", ctx, "BEN-404").Return(nil, gorm.ErrRecordNotFound).Once()
+
+		result, err := service.Update(ctx, "BEN-404", models.BeneficiaryRequest{})
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		mockBeneficiary.AssertNotCalled(t, "Update")
+	})
+}
+
+func TestBeneficiaryService_Verify(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("marks beneficiary as verified", func(t *testing.T) {
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockProvider := new(provider_test.MockGatewayProvider)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewBeneficiaryService(mockBeneficiary, mockLoan, mockProvider, mockIdGenerator, nil)
+
+		existing := verifiedBeneficiary("BEN-123")
+		existing.Status = models.BeneficiaryStatusUnverified
+
+		mockBeneficiary.On("GetById", ctx, "BEN-123").Return(existing, nil).Once()
+		mockBeneficiary.On("Update", ctx, "BEN-123", mock.MatchedBy(func(fields map[string]any) bool {
+			_, ok := fields["verified_at"].(time.Time)
+			return fields["status"] == models.BeneficiaryStatusVerified && ok
+		})).Return(nil).Once()
+		mockBeneficiary.On("GetById", ctx, "BEN-123").
+			Return(verifiedBeneficiary("BEN-123"), nil).
+			Once()
+
+		result, err := service.Verify(ctx, "BEN-123", models.BeneficiaryVerificationRequest{
+			Status: models.BeneficiaryStatusVerified,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.BeneficiaryStatusVerified, result.Status)
+		mockBeneficiary.AssertExpectations(t)
+	})
+
+	t.Run("requires a reason to reject", func(t *testing.T) {
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockProvider := new(provider_test.MockGatewayProvider)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewBeneficiaryService(mockBeneficiary, mockLoan, mockProvider, mockIdGenerator, nil)
+
+		mockBeneficiary.On("GetById", ctx, "BEN-123").
+			Return(verifiedBeneficiary("BEN-123"), nil).
+			Once()
+
+		result, err := service.Verify(ctx, "BEN-123", models.BeneficiaryVerificationRequest{
+			Status: models.BeneficiaryStatusRejected,
+		})
+
+		assert.Nil(t, result)
+		assert.Error(t, err)
+		mockBeneficiary.AssertNotCalled(t, "Update")
+	})
+
+	t.Run("rejects unknown status", func(t *testing.T) {
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockProvider := new(provider_test.MockGatewayProvider)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewBeneficiaryService(mockBeneficiary, mockLoan, mockProvider, mockIdGenerator, nil)
+
+		mockBeneficiary.On("GetById", ctx, "BEN-123").
+			Return(verifiedBeneficiary("BEN-123"), nil).
+			Once()
+
+		result, err := service.Verify(ctx, "BEN-123", models.BeneficiaryVerificationRequest{
+			Status: models.BeneficiaryStatus("pending"),
+		})
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, models.INVALID_BENEFICIARY_STATUS)
+	})
+}
+
+func TestBeneficiaryService_Override(t *testing.T) {
+	ctx := context.Background()
+	operator := models.Operator{Id: "ops-42", Role: models.OperatorRoleAdmin}
+
+	t.Run("records override for unverified beneficiary", func(t *testing.T) {
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockProvider := new(provider_test.MockGatewayProvider)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewBeneficiaryService(mockBeneficiary, mockLoan, mockProvider, mockIdGenerator, nil)
+
+		existing := verifiedBeneficiary("BEN-123")
+		existing.Status = models.BeneficiaryStatusUnverified
+
+		mockBeneficiary.On("GetById", ctx, "BEN-123").Return(existing, nil).Twice()
+		mockBeneficiary.On("Update", ctx, "BEN-123", mock.MatchedBy(func(fields map[string]any) bool {
+			return fields["override_reason"] == "documents checked at branch" &&
+				fields["overridden_by"] == "ops-42"
+		})).Return(nil).Once()
+
+		_, err := service.Override(ctx, operator, "BEN-123", models.BeneficiaryOverrideRequest{
+			Reason: "documents checked at branch",
+		})
+
+		assert.NoError(t, err)
+		mockBeneficiary.AssertExpectations(t)
+	})
+
+	t.Run("refuses override for rejected beneficiary", func(t *testing.T) {
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockProvider := new(provider_test.MockGatewayProvider)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewBeneficiaryService(mockBeneficiary, mockLoan, mockProvider, mockIdGenerator, nil)
+
+		existing := verifiedBeneficiary("BEN-123")
+		existing.Status = models.BeneficiaryStatusRejected
+
+		mockBeneficiary.On("GetById", ctx, "BEN-123").Return(existing, nil).Once()
+
+		result, err := service.Override(ctx, operator, "BEN-123", models.BeneficiaryOverrideRequest{
+			Reason: "documents checked at branch",
+		})
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, models.BENEFICIARY_REJECTED)
+		mockBeneficiary.AssertNotCalled(t, "Update")
+	})
+
+	t.Run("requires reason", func(t *testing.T) {
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockProvider := new(provider_test.MockGatewayProvider)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewBeneficiaryService(mockBeneficiary, mockLoan, mockProvider, mockIdGenerator, nil)
+
+		result, err := service.Override(ctx, operator, "BEN-123", models.BeneficiaryOverrideRequest{Reason: "  "})
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, models.OVERRIDE_APPROVAL_REQUIRED)
+		mockBeneficiary.AssertNotCalled(t, "GetById")
+	})
+}
+
+func TestBeneficiaryService_Delete(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("deletes unlinked beneficiary", func(t *testing.T) {
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockProvider := new(provider_test.MockGatewayProvider)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewBeneficiaryService(mockBeneficiary, mockLoan, mockProvider, mockIdGenerator, nil)
+
+		mockLoan.On("ListByBeneficiary", ctx, "BEN-123").Return([]schema.Loan{}, nil).Once()
+		mockBeneficiary.On("Delete", ctx, "BEN-123").Return(nil).Once()
+
+		err := service.Delete(ctx, "BEN-123")
+
+		assert.NoError(t, err)
+		mockBeneficiary.AssertExpectations(t)
+		mockLoan.AssertExpectations(t)
+	})
+
+	t.Run("refuses to delete beneficiary linked to a loan", func(t *testing.T) {
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockProvider := new(provider_test.MockGatewayProvider)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewBeneficiaryService(mockBeneficiary, mockLoan, mockProvider, mockIdGenerator, nil)
+
+		mockLoan.On("ListByBeneficiary", ctx, "BEN-123").
+			Return([]schema.Loan{{Id: "LOAN-123"}}, nil).
+			Once()
+
+		err := service.Delete(ctx, "BEN-123")
+
+		assert.ErrorIs(t, err, models.BENEFICIARY_IN_USE)
+		mockBeneficiary.AssertNotCalled(t, "Delete")
+	})
+}
@@ -0,0 +1,502 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"loan-disbursement-service/db"
+	"loan-disbursement-service/db/daos"
+	"loan-disbursement-service/db/schema"
+	"loan-disbursement-service/models"
+	"loan-disbursement-service/providers"
+	"loan-disbursement-service/utils"
+	"shared/logging"
+	"shared/pii"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+const DefaultDeadLetterPageSize = 50
+
+var deadLetterColumns = []string{
+	"disbursement_id",
+	"loan_id",
+	"amount",
+	"channel",
+	"category",
+	"last_error",
+	"status",
+	"failure_count",
+	"notified_at",
+	"resolved_by",
+	"resolution_note",
+	"created_at",
+	"updated_at",
+}
+
+// DeadLetterService is the operations view of disbursements that failed
+// permanently. Entries stay open until they are requeued or closed, and the
+// loan origination system is told about each one so it gets worked.
+type DeadLetterService interface {
+	List(ctx context.Context, filter models.DeadLetterFilter) ([]models.DeadLetter, error)
+	Export(ctx context.Context, filter models.DeadLetterFilter, w io.Writer) error
+	Requeue(
+		ctx context.Context,
+		operator models.Operator,
+		req models.DeadLetterBulkRequest,
+	) (*models.DeadLetterBulkResponse, error)
+	Close(
+		ctx context.Context,
+		operator models.Operator,
+		req models.DeadLetterBulkRequest,
+	) (*models.DeadLetterBulkResponse, error)
+	NotifyPending(ctx context.Context, limit int) (int, error)
+}
+
+type DeadLetterServiceImpl struct {
+	idGenerator  utils.IdGenerator
+	deadLetter   daos.DeadLetterRepository
+	disbursement daos.DisbursementRepository
+	audit        daos.AuditRepository
+	admin        AdminService
+	origination  providers.OriginationProvider
+}
+
+func NewDeadLetterService(
+	idGenerator utils.IdGenerator,
+	deadLetter daos.DeadLetterRepository,
+	disbursement daos.DisbursementRepository,
+	audit daos.AuditRepository,
+	admin AdminService,
+	origination providers.OriginationProvider,
+) DeadLetterService {
+	return &DeadLetterServiceImpl{
+		idGenerator:  idGenerator,
+		deadLetter:   deadLetter,
+		disbursement: disbursement,
+		audit:        audit,
+		admin:        admin,
+		origination:  origination,
+	}
+}
+
+// List returns one page of entries, open ones unless the filter asks for
+// another status.
+func (s *DeadLetterServiceImpl) List(
+	ctx context.Context,
+	filter models.DeadLetterFilter,
+) ([]models.DeadLetter, error) {
+	filter, err := normalizeDeadLetterFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultDeadLetterPageSize
+	}
+
+	entries, err := s.deadLetter.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	result := make([]models.DeadLetter, len(entries))
+	for i, entry := range entries {
+		result[i] = deadLetterResponse(entry)
+	}
+	return result, nil
+}
+
+// Export writes every entry matching the filter as CSV, ignoring paging.
+func (s *DeadLetterServiceImpl) Export(
+	ctx context.Context,
+	filter models.DeadLetterFilter,
+	w io.Writer,
+) error {
+	filter, err := normalizeDeadLetterFilter(filter)
+	if err != nil {
+		return err
+	}
+	filter.Offset, filter.Limit = 0, 0
+
+	entries, err := s.deadLetter.List(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to list dead letters: %w", err)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(deadLetterColumns); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		var notifiedAt, resolvedBy, resolutionNote string
+		if entry.NotifiedAt != nil {
+			notifiedAt = entry.NotifiedAt.Format(time.RFC3339)
+		}
+		if entry.ResolvedBy != nil {
+			resolvedBy = *entry.ResolvedBy
+		}
+		if entry.ResolutionNote != nil {
+			resolutionNote = *entry.ResolutionNote
+		}
+		lastError := entry.LastError
+		if !pii.CanView(ctx) {
+			lastError = pii.Text(lastError)
+		}
+		if err := writer.Write([]string{
+			entry.DisbursementId,
+			entry.LoanId,
+			strconv.FormatFloat(entry.Amount, 'f', 2, 64),
+			string(entry.Channel),
+			string(entry.Category),
+			lastError,
+			string(entry.Status),
+			strconv.Itoa(entry.FailureCount),
+			notifiedAt,
+			resolvedBy,
+			resolutionNote,
+			entry.CreatedAt.Format(time.RFC3339),
+			entry.UpdatedAt.Format(time.RFC3339),
+		}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Requeue sends each disbursement back to the workers, typically after the
+// cause was fixed (a corrected IFSC, a topped up pool account). Each
+// disbursement is requeued through the admin service so it lands in the
+// audit trail like a single requeue.
+func (s *DeadLetterServiceImpl) Requeue(
+	ctx context.Context,
+	operator models.Operator,
+	req models.DeadLetterBulkRequest,
+) (*models.DeadLetterBulkResponse, error) {
+	if err := validateDeadLetterBulk(req); err != nil {
+		return nil, err
+	}
+	return s.bulk(req.DisbursementIds, func(disbursementId string) error {
+		if _, err := s.open(ctx, disbursementId); err != nil {
+			return err
+		}
+		disbursement, err := s.disbursement.Get(ctx, disbursementId)
+		if err != nil {
+			return fmt.Errorf("failed to get disbursement: %w", err)
+		}
+		// The disbursement may have been retried outside the queue already;
+		// requeueing it again would send it twice.
+		if disbursement.Status != models.DisbursementStatusFailed {
+			return fmt.Errorf("%w: disbursement is %s", models.ACTION_NOT_ALLOWED, disbursement.Status)
+		}
+
+		// The requeue resolves the entry along with it.
+		_, err = s.admin.Requeue(ctx, operator, disbursementId, models.AdminActionRequest{
+			Reason: req.Reason,
+		})
+		return err
+	}), nil
+}
+
+// Close gives up on each disbursement. It stays failed with its loan already
+// released, and drops out of the open view.
+func (s *DeadLetterServiceImpl) Close(
+	ctx context.Context,
+	operator models.Operator,
+	req models.DeadLetterBulkRequest,
+) (*models.DeadLetterBulkResponse, error) {
+	if err := validateDeadLetterBulk(req); err != nil {
+		return nil, err
+	}
+	return s.bulk(req.DisbursementIds, func(disbursementId string) error {
+		entry, err := s.open(ctx, disbursementId)
+		if err != nil {
+			return err
+		}
+		closed, err := s.resolve(ctx, operator, disbursementId, models.DeadLetterStatusClosed, req.Reason)
+		if err != nil {
+			return err
+		}
+		if !closed {
+			return models.DEAD_LETTER_NOT_OPEN
+		}
+
+		err = s.audit.Create(ctx, schema.AuditLog{
+			Id:           s.idGenerator.GenerateAuditId(),
+			EntityType:   models.AuditEntityDisbursement,
+			EntityId:     disbursementId,
+			Action:       models.AdminActionCloseDeadLetter,
+			OperatorId:   operator.Id,
+			OperatorRole: operator.Role,
+			Reason:       req.Reason,
+			FromStatus:   models.DisbursementStatusFailed,
+			ToStatus:     models.DisbursementStatusFailed,
+		})
+		if err != nil {
+			return fmt.Errorf("%s applied but audit entry not recorded: %w",
+				models.AdminActionCloseDeadLetter, err)
+		}
+		log.Ctx(logging.With(ctx, logging.Fields{
+			LoanId:         entry.LoanId,
+			DisbursementId: disbursementId,
+		})).Info().
+			Str("operator_id", operator.Id).
+			Msg("dead letter closed")
+		return nil
+	}), nil
+}
+
+// NotifyPending posts up to limit unacknowledged entries to the loan
+// origination system and reports how many were delivered. Entries that
+// could not be delivered are left for the next run.
+func (s *DeadLetterServiceImpl) NotifyPending(ctx context.Context, limit int) (int, error) {
+	entries, err := s.deadLetter.ListUnnotified(ctx, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+
+	notified := 0
+	for _, entry := range entries {
+		ctx := logging.With(ctx, logging.Fields{
+			LoanId:         entry.LoanId,
+			DisbursementId: entry.DisbursementId,
+			Channel:        string(entry.Channel),
+		})
+		err := s.origination.NotifyDeadLetter(ctx, models.DeadLetterNotification{
+			Event:          models.DeadLetterEvent,
+			DisbursementId: entry.DisbursementId,
+			LoanId:         entry.LoanId,
+			Amount:         entry.Amount,
+			Channel:        entry.Channel,
+			Category:       entry.Category,
+			LastError:      entry.LastError,
+			FailureCount:   entry.FailureCount,
+			FailedAt:       entry.UpdatedAt,
+		})
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).
+				Msg("failed to notify origination system of dead letter")
+			continue
+		}
+		err = s.deadLetter.Update(ctx, entry.DisbursementId, map[string]any{
+			"notified_at": time.Now(),
+		})
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).
+				Msg("failed to mark dead letter notified")
+			continue
+		}
+		notified++
+	}
+	return notified, nil
+}
+
+// open fetches the entry and checks it is still waiting on operations.
+func (s *DeadLetterServiceImpl) open(
+	ctx context.Context,
+	disbursementId string,
+) (*schema.DeadLetter, error) {
+	entry, err := s.deadLetter.Get(ctx, disbursementId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.DEAD_LETTER_NOT_FOUND
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead letter: %w", err)
+	}
+	if entry.Status != models.DeadLetterStatusOpen {
+		return nil, fmt.Errorf("%w: dead letter is %s", models.DEAD_LETTER_NOT_OPEN, entry.Status)
+	}
+	return entry, nil
+}
+
+func (s *DeadLetterServiceImpl) resolve(
+	ctx context.Context,
+	operator models.Operator,
+	disbursementId string,
+	status models.DeadLetterStatus,
+	reason string,
+) (bool, error) {
+	resolved, err := s.deadLetter.UpdateIfStatus(ctx, disbursementId, models.DeadLetterStatusOpen,
+		map[string]any{
+			"status":          status,
+			"resolved_by":     operator.Id,
+			"resolution_note": reason,
+			"resolved_at":     time.Now(),
+		},
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to update dead letter: %w", err)
+	}
+	return resolved, nil
+}
+
+// bulk applies action to each distinct disbursement and collects the
+// outcomes. One failure does not stop the rest.
+func (s *DeadLetterServiceImpl) bulk(
+	disbursementIds []string,
+	action func(disbursementId string) error,
+) *models.DeadLetterBulkResponse {
+	response := &models.DeadLetterBulkResponse{Results: []models.DeadLetterBulkResult{}}
+	seen := make(map[string]bool, len(disbursementIds))
+	for _, disbursementId := range disbursementIds {
+		if seen[disbursementId] {
+			continue
+		}
+		seen[disbursementId] = true
+
+		result := models.DeadLetterBulkResult{DisbursementId: disbursementId, Success: true}
+		if err := action(disbursementId); err != nil {
+			result.Success = false
+			result.Error = err.Error()
+			response.Failed++
+		} else {
+			response.Succeeded++
+		}
+		response.Results = append(response.Results, result)
+	}
+	return response
+}
+
+func validateDeadLetterBulk(req models.DeadLetterBulkRequest) error {
+	if err := requireReason(req.Reason); err != nil {
+		return err
+	}
+	if len(req.DisbursementIds) == 0 {
+		return models.DISBURSEMENT_IDS_REQUIRED
+	}
+	if len(req.DisbursementIds) > models.MaxDeadLetterBulkSize {
+		return fmt.Errorf("%w: at most %d", models.TOO_MANY_DISBURSEMENT_IDS, models.MaxDeadLetterBulkSize)
+	}
+	return nil
+}
+
+func normalizeDeadLetterFilter(filter models.DeadLetterFilter) (models.DeadLetterFilter, error) {
+	if filter.Status == "" {
+		filter.Status = models.DeadLetterStatusOpen
+	}
+	if !filter.Status.IsValid() {
+		return filter, models.INVALID_DEAD_LETTER_STATUS
+	}
+	if filter.Category != "" && !filter.Category.IsValid() {
+		return filter, models.INVALID_FAILURE_CATEGORY
+	}
+	return filter, nil
+}
+
+// recordDeadLetter opens the dead letter for a disbursement that has just
+// failed permanently. channel is the one the last attempt used.
+func recordDeadLetter(
+	ctx context.Context,
+	deadLetters daos.DeadLetterRepository,
+	disbursement *schema.Disbursement,
+	channel models.PaymentChannel,
+	category models.FailureCategory,
+	lastError string,
+) error {
+	now := time.Now()
+	err := deadLetters.Record(ctx, schema.DeadLetter{
+		DisbursementId: disbursement.Id,
+		LoanId:         disbursement.LoanId,
+		Amount:         disbursement.Amount,
+		Channel:        channel,
+		Category:       category,
+		LastError:      lastError,
+		Status:         models.DeadLetterStatusOpen,
+		FailureCount:   1,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record dead letter: %w", err)
+	}
+	return nil
+}
+
+// failDisbursement moves a disbursement from the status it was read in to
+// failed, releases its loan, dead-letters it and queues the failed webhook
+// event in one database transaction, so a write failing partway leaves the
+// disbursement as it was.
+func failDisbursement(
+	ctx context.Context,
+	database *db.Database,
+	loans daos.LoanRepository,
+	disbursements daos.DisbursementRepository,
+	deadLetters daos.DeadLetterRepository,
+	webhook WebhookService,
+	disbursement *schema.Disbursement,
+	category models.FailureCategory,
+	reason string,
+) error {
+	return database.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		failed, err := disbursements.WithTx(tx).UpdateIfStatus(ctx, disbursement.Id, disbursement.Status,
+			map[string]any{
+				"status":     models.DisbursementStatusFailed,
+				"last_error": reason,
+				"updated_at": time.Now(),
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update disbursement: %w", err)
+		}
+		if !failed {
+			return fmt.Errorf("%w: disbursement is no longer %s", models.DISBURSEMENT_STATUS_CHANGED, disbursement.Status)
+		}
+		if err := releaseLoan(ctx, loans.WithTx(tx), disbursement.LoanId); err != nil {
+			return err
+		}
+		err = recordDeadLetter(ctx, deadLetters.WithTx(tx), disbursement, disbursement.Channel, category, reason)
+		if err != nil {
+			return err
+		}
+		return queueEvent(ctx, tx, webhook, models.WebhookEventFailed, disbursement.Id)
+	})
+}
+
+// resolveDeadLetter takes the entry of a disbursement that left failed out
+// of the open view, so the queue does not list a paid or requeued
+// disbursement as still failed. resolvedBy is empty when no operator acted.
+func resolveDeadLetter(
+	ctx context.Context,
+	deadLetters daos.DeadLetterRepository,
+	disbursementId string,
+	status models.DeadLetterStatus,
+	resolvedBy string,
+	note string,
+) error {
+	fields := map[string]any{
+		"status":          status,
+		"resolution_note": note,
+		"resolved_at":     time.Now(),
+	}
+	if resolvedBy != "" {
+		fields["resolved_by"] = resolvedBy
+	}
+	_, err := deadLetters.UpdateIfStatus(ctx, disbursementId, models.DeadLetterStatusOpen, fields)
+	if err != nil {
+		return fmt.Errorf("failed to update dead letter: %w", err)
+	}
+	return nil
+}
+
+func deadLetterResponse(entry schema.DeadLetter) models.DeadLetter {
+	return models.DeadLetter{
+		DisbursementId: entry.DisbursementId,
+		LoanId:         entry.LoanId,
+		Amount:         entry.Amount,
+		Channel:        entry.Channel,
+		Category:       entry.Category,
+		LastError:      entry.LastError,
+		Status:         entry.Status,
+		FailureCount:   entry.FailureCount,
+		NotifiedAt:     entry.NotifiedAt,
+		ResolvedBy:     entry.ResolvedBy,
+		ResolutionNote: entry.ResolutionNote,
+		ResolvedAt:     entry.ResolvedAt,
+		CreatedAt:      entry.CreatedAt,
+		UpdatedAt:      entry.UpdatedAt,
+	}
+}
@@ -0,0 +1,417 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"loan-disbursement-service/db/schema"
+	"loan-disbursement-service/models"
+	db_test "loan-disbursement-service/test/db"
+	provider_test "loan-disbursement-service/test/providers"
+	utils_test "loan-disbursement-service/test/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockAdminService struct {
+	mock.Mock
+}
+
+func (m *MockAdminService) MarkSuccess(
+	ctx context.Context,
+	operator models.Operator,
+	disbursementId string,
+	req models.MarkSuccessRequest,
+) (*models.DisbursementResponse, error) {
+	args := m.Called(ctx, operator, disbursementId, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DisbursementResponse), args.Error(1)
+}
+
+func (m *MockAdminService) MarkFailed(
+	ctx context.Context,
+	operator models.Operator,
+	disbursementId string,
+	req models.AdminActionRequest,
+) (*models.DisbursementResponse, error) {
+	args := m.Called(ctx, operator, disbursementId, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DisbursementResponse), args.Error(1)
+}
+
+func (m *MockAdminService) Requeue(
+	ctx context.Context,
+	operator models.Operator,
+	disbursementId string,
+	req models.AdminActionRequest,
+) (*models.DisbursementResponse, error) {
+	args := m.Called(ctx, operator, disbursementId, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DisbursementResponse), args.Error(1)
+}
+
+func (m *MockAdminService) ForceChannel(
+	ctx context.Context,
+	operator models.Operator,
+	disbursementId string,
+	req models.ForceChannelRequest,
+) (*models.DisbursementResponse, error) {
+	args := m.Called(ctx, operator, disbursementId, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DisbursementResponse), args.Error(1)
+}
+
+func (m *MockAdminService) ResetRetries(
+	ctx context.Context,
+	operator models.Operator,
+	disbursementId string,
+	req models.AdminActionRequest,
+) (*models.DisbursementResponse, error) {
+	args := m.Called(ctx, operator, disbursementId, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DisbursementResponse), args.Error(1)
+}
+
+func (m *MockAdminService) AuditTrail(
+	ctx context.Context,
+	disbursementId string,
+) ([]models.AuditEntry, error) {
+	args := m.Called(ctx, disbursementId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AuditEntry), args.Error(1)
+}
+
+func (m *MockAdminService) CorrectBeneficiary(
+	ctx context.Context,
+	operator models.Operator,
+	disbursementId string,
+	req models.BeneficiaryCorrectionRequest,
+) (*models.DisbursementResponse, error) {
+	args := m.Called(ctx, operator, disbursementId, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DisbursementResponse), args.Error(1)
+}
+
+func (m *MockAdminService) BeneficiaryHistory(
+	ctx context.Context,
+	loanId string,
+) ([]models.BeneficiaryChange, error) {
+	args := m.Called(ctx, loanId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.BeneficiaryChange), args.Error(1)
+}
+
+type deadLetterMocks struct {
+	idGenerator  *utils_test.MockIdGenerator
+	deadLetter   *db_test.MockDeadLetterRepository
+	disbursement *db_test.MockDisbursementRepository
+	audit        *db_test.MockAuditRepository
+	admin        *MockAdminService
+	origination  *provider_test.MockOriginationProvider
+}
+
+func newDeadLetterService() (DeadLetterService, deadLetterMocks) {
+	mocks := deadLetterMocks{
+		idGenerator:  new(utils_test.MockIdGenerator),
+		deadLetter:   new(db_test.MockDeadLetterRepository),
+		disbursement: new(db_test.MockDisbursementRepository),
+		audit:        new(db_test.MockAuditRepository),
+		admin:        new(MockAdminService),
+		origination:  new(provider_test.MockOriginationProvider),
+	}
+	service := NewDeadLetterService(
+		mocks.idGenerator,
+		mocks.deadLetter,
+		mocks.disbursement,
+		mocks.audit,
+		mocks.admin,
+		mocks.origination,
+	)
+	return service, mocks
+}
+
+func openDeadLetter(disbursementId string) *schema.DeadLetter {
+	return &schema.DeadLetter{
+		DisbursementId: disbursementId,
+		LoanId:         "LOAN-123",
+		Amount:         25000,
+		Channel:        models.PaymentChannelIMPS,
+		Category:       models.FailureCategoryInvalidIFSC,
+		LastError:      "Invalid IFSC code",
+		Status:         models.DeadLetterStatusOpen,
+		FailureCount:   1,
+	}
+}
+
+func TestDeadLetterService_List(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("lists open entries one page at a time by default", func(t *testing.T) {
+		service, mocks := newDeadLetterService()
+
+		mocks.deadLetter.On("List", ctx, models.DeadLetterFilter{
+			Status:   models.DeadLetterStatusOpen,
+			Category: models.FailureCategoryInvalidIFSC,
+			Limit:    DefaultDeadLetterPageSize,
+		}).Return([]schema.DeadLetter{*openDeadLetter("DISB-123")}, nil).Once()
+
+		entries, err := service.List(ctx, models.DeadLetterFilter{
+			Category: models.FailureCategoryInvalidIFSC,
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1)
+		assert.Equal(t, "DISB-123", entries[0].DisbursementId)
+		assert.Equal(t, models.FailureCategoryInvalidIFSC, entries[0].Category)
+		mocks.deadLetter.AssertExpectations(t)
+	})
+
+	t.Run("rejects unknown category", func(t *testing.T) {
+		service, mocks := newDeadLetterService()
+
+		_, err := service.List(ctx, models.DeadLetterFilter{Category: "typo"})
+
+		assert.ErrorIs(t, err, models.INVALID_FAILURE_CATEGORY)
+		mocks.deadLetter.AssertNotCalled(t, "List")
+	})
+
+	t.Run("rejects unknown status", func(t *testing.T) {
+		service, _ := newDeadLetterService()
+
+		_, err := service.List(ctx, models.DeadLetterFilter{Status: "pending"})
+
+		assert.ErrorIs(t, err, models.INVALID_DEAD_LETTER_STATUS)
+	})
+}
+
+func TestDeadLetterService_Export(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("writes every matching entry as CSV", func(t *testing.T) {
+		service, mocks := newDeadLetterService()
+		createdAt := time.Date(2025, time.March, 3, 10, 0, 0, 0, time.UTC)
+		entry := openDeadLetter("DISB-123")
+		entry.CreatedAt = createdAt
+		entry.UpdatedAt = createdAt
+
+		mocks.deadLetter.On("List", ctx, models.DeadLetterFilter{
+			Status: models.DeadLetterStatusOpen,
+		}).Return([]schema.DeadLetter{*entry}, nil).Once()
+
+		var buf bytes.Buffer
+		err := service.Export(ctx, models.DeadLetterFilter{Offset: 50, Limit: 50}, &buf)
+
+		assert.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		assert.Len(t, lines, 2)
+		assert.Equal(t, strings.Join(deadLetterColumns, ","), lines[0])
+		assert.Equal(t,
+			"DISB-123,LOAN-123,25000.00,IMPS,invalid_ifsc,Invalid IFSC code,open,1,,,,"+
+				"2025-03-03T10:00:00Z,2025-03-03T10:00:00Z",
+			lines[1],
+		)
+		mocks.deadLetter.AssertExpectations(t)
+	})
+}
+
+func TestDeadLetterService_Requeue(t *testing.T) {
+	ctx := context.Background()
+	operator := models.Operator{Id: "ops@example.com", Role: models.OperatorRoleSupport}
+
+	t.Run("requeues each failed disbursement once", func(t *testing.T) {
+		service, mocks := newDeadLetterService()
+
+		mocks.deadLetter.On("Get", ctx, "DISB-123").Return(openDeadLetter("DISB-123"), nil).Once()
+		mocks.disbursement.On("Get", ctx, "DISB-123").Return(&schema.Disbursement{
+			Id:     "DISB-123",
+			Status: models.DisbursementStatusFailed,
+		}, nil).Once()
+		mocks.admin.On("Requeue", ctx, operator, "DISB-123", models.AdminActionRequest{
+			Reason: "IFSC corrected",
+		}).Return(&models.DisbursementResponse{DisbursementId: "DISB-123"}, nil).Once()
+
+		response, err := service.Requeue(ctx, operator, models.DeadLetterBulkRequest{
+			DisbursementIds: []string{"DISB-123", "DISB-123"},
+			Reason:          "IFSC corrected",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, response.Succeeded)
+		assert.Equal(t, 0, response.Failed)
+		assert.Len(t, response.Results, 1)
+		mocks.deadLetter.AssertExpectations(t)
+		mocks.admin.AssertExpectations(t)
+	})
+
+	t.Run("reports entries that cannot be requeued and carries on", func(t *testing.T) {
+		service, mocks := newDeadLetterService()
+
+		closed := openDeadLetter("DISB-1")
+		closed.Status = models.DeadLetterStatusClosed
+		mocks.deadLetter.On("Get", ctx, "DISB-1").Return(closed, nil).Once()
+		mocks.deadLetter.On("Get", ctx, "DISB-2").Return(nil, gorm.ErrRecordNotFound).Once()
+		mocks.deadLetter.On("Get", ctx, "DISB-3").Return(openDeadLetter("DISB-3"), nil).Once()
+		mocks.disbursement.On("Get", ctx, "DISB-3").Return(&schema.Disbursement{
+			Id:     "DISB-3",
+			Status: models.DisbursementStatusInitiated,
+		}, nil).Once()
+
+		response, err := service.Requeue(ctx, operator, models.DeadLetterBulkRequest{
+			DisbursementIds: []string{"DISB-1", "DISB-2", "DISB-3"},
+			Reason:          "IFSC corrected",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, response.Succeeded)
+		assert.Equal(t, 3, response.Failed)
+		assert.Contains(t, response.Results[0].Error, models.DEAD_LETTER_NOT_OPEN.Error())
+		assert.Equal(t, models.DEAD_LETTER_NOT_FOUND.Error(), response.Results[1].Error)
+		assert.Contains(t, response.Results[2].Error, models.ACTION_NOT_ALLOWED.Error())
+		mocks.admin.AssertNotCalled(t, "Requeue")
+	})
+
+	t.Run("requires a reason", func(t *testing.T) {
+		service, _ := newDeadLetterService()
+
+		_, err := service.Requeue(ctx, operator, models.DeadLetterBulkRequest{
+			DisbursementIds: []string{"DISB-123"},
+		})
+
+		assert.ErrorIs(t, err, models.REASON_REQUIRED)
+	})
+
+	t.Run("rejects oversized requests", func(t *testing.T) {
+		service, _ := newDeadLetterService()
+
+		_, err := service.Requeue(ctx, operator, models.DeadLetterBulkRequest{
+			DisbursementIds: make([]string, models.MaxDeadLetterBulkSize+1),
+			Reason:          "IFSC corrected",
+		})
+
+		assert.ErrorIs(t, err, models.TOO_MANY_DISBURSEMENT_IDS)
+	})
+}
+
+func TestDeadLetterService_Close(t *testing.T) {
+	ctx := context.Background()
+	operator := models.Operator{Id: "ops@example.com", Role: models.OperatorRoleAdmin}
+
+	t.Run("closes the entry and records it in the audit trail", func(t *testing.T) {
+		service, mocks := newDeadLetterService()
+
+		mocks.deadLetter.On("Get", ctx, "DISB-123").Return(openDeadLetter("DISB-123"), nil).Once()
+		mocks.deadLetter.On("UpdateIfStatus", ctx, "DISB-123", models.DeadLetterStatusOpen,
+			mock.MatchedBy(func(fields map[string]any) bool {
+				return fields["status"] == models.DeadLetterStatusClosed
+			}),
+		).Return(true, nil).Once()
+		mocks.idGenerator.On("GenerateAuditId").Return("AUD-123").Once()
+		mocks.audit.On("Create", ctx, mock.MatchedBy(func(entry schema.AuditLog) bool {
+			return entry.Id == "AUD-123" &&
+				entry.EntityId == "DISB-123" &&
+				entry.Action == models.AdminActionCloseDeadLetter &&
+				entry.Reason == "loan cancelled by borrower"
+		})).Return(nil).Once()
+
+		response, err := service.Close(ctx, operator, models.DeadLetterBulkRequest{
+			DisbursementIds: []string{"DISB-123"},
+			Reason:          "loan cancelled by borrower",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, response.Succeeded)
+		mocks.deadLetter.AssertExpectations(t)
+		mocks.audit.AssertExpectations(t)
+	})
+
+	t.Run("fails the entry when another operator resolved it first", func(t *testing.T) {
+		service, mocks := newDeadLetterService()
+
+		mocks.deadLetter.On("Get", ctx, "DISB-123").Return(openDeadLetter("DISB-123"), nil).Once()
+		mocks.deadLetter.On("UpdateIfStatus", ctx, "DISB-123", models.DeadLetterStatusOpen, mock.Anything).
+			Return(false, nil).Once()
+
+		response, err := service.Close(ctx, operator, models.DeadLetterBulkRequest{
+			DisbursementIds: []string{"DISB-123"},
+			Reason:          "loan cancelled by borrower",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, response.Failed)
+		assert.Equal(t, models.DEAD_LETTER_NOT_OPEN.Error(), response.Results[0].Error)
+		mocks.audit.AssertNotCalled(t, "Create")
+	})
+
+	t.Run("requires disbursement ids", func(t *testing.T) {
+		service, _ := newDeadLetterService()
+
+		_, err := service.Close(ctx, operator, models.DeadLetterBulkRequest{Reason: "duplicate"})
+
+		assert.ErrorIs(t, err, models.DISBURSEMENT_IDS_REQUIRED)
+	})
+}
+
+func TestDeadLetterService_NotifyPending(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("marks delivered entries and leaves the rest for the next run", func(t *testing.T) {
+		service, mocks := newDeadLetterService()
+
+		mocks.deadLetter.On("ListUnnotified", ctx, 10).Return([]schema.DeadLetter{
+			*openDeadLetter("DISB-1"),
+			*openDeadLetter("DISB-2"),
+		}, nil).Once()
+		mocks.origination.On("NotifyDeadLetter", mock.Anything, mock.MatchedBy(func(n models.DeadLetterNotification) bool {
+			return n.DisbursementId == "DISB-1" &&
+				n.Event == models.DeadLetterEvent &&
+				n.Category == models.FailureCategoryInvalidIFSC
+		})).Return(nil).Once()
+		mocks.origination.On("NotifyDeadLetter", mock.Anything, mock.MatchedBy(func(n models.DeadLetterNotification) bool {
+			return n.DisbursementId == "DISB-2"
+		})).Return(errors.New("status=503")).Once()
+		mocks.deadLetter.On("Update", mock.Anything, "DISB-1", mock.MatchedBy(func(fields map[string]any) bool {
+			_, ok := fields["notified_at"]
+			return ok
+		})).Return(nil).Once()
+
+		notified, err := service.NotifyPending(ctx, 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, notified)
+		mocks.origination.AssertExpectations(t)
+		mocks.deadLetter.AssertExpectations(t)
+		mocks.deadLetter.AssertNotCalled(t, "Update", ctx, "DISB-2", mock.Anything)
+	})
+
+	t.Run("returns error when listing fails", func(t *testing.T) {
+		service, mocks := newDeadLetterService()
+
+		mocks.deadLetter.On("ListUnnotified", ctx, 10).Return(nil, errors.New("database error")).Once()
+
+		_, err := service.NotifyPending(ctx, 10)
+
+		assert.ErrorContains(t, err, "failed to list dead letters")
+		mocks.origination.AssertNotCalled(t, "NotifyDeadLetter")
+	})
+}
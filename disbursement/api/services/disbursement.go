@@ -4,9 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"loan-disbursement-service/config"
+	"loan-disbursement-service/db"
 	"loan-disbursement-service/db/daos"
+	"loan-disbursement-service/db/schema"
+	"loan-disbursement-service/events"
 	"loan-disbursement-service/models"
 	"loan-disbursement-service/utils"
+	"shared/ifsc"
+	"shared/logging"
+	"shared/tracing"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -15,34 +22,61 @@ import (
 
 type DisbursementService interface {
 	Disburse(ctx context.Context, req *models.DisburseRequest) (*models.DisbursementResponse, error)
-	Fetch(ctx context.Context, disbursementId string) (any, error)
+	// Fetch only finds disbursements clientId created, or any disbursement
+	// when clientId is empty.
+	Fetch(ctx context.Context, clientId, disbursementId string) (any, error)
 	Retry(ctx context.Context, disbursementId string) (any, error)
+	Cancel(ctx context.Context, disbursementId string) (*models.DisbursementResponse, error)
 }
 
 type DisbursementServiceImpl struct {
-	idGenerator  utils.IdGenerator
-	loan         daos.LoanRepository
-	disbursement daos.DisbursementRepository
-	transaction  daos.TransactionRepository
-	beneficiary  daos.BeneficiaryRepository
-	paymentChan  chan string
+	db                 *db.Database
+	idGenerator        utils.IdGenerator
+	loan               daos.LoanRepository
+	disbursement       daos.DisbursementRepository
+	transaction        daos.TransactionRepository
+	beneficiary        daos.BeneficiaryRepository
+	deadLetter         daos.DeadLetterRepository
+	nameMatch          NameMatchPolicy
+	webhook            WebhookService
+	paymentChan        chan string
+	beneficiaryService BeneficiaryService
+	directory          *ifsc.Directory
+	bus                *events.Bus
+	settings           *config.Store
 }
 
 func NewDisbursementService(
+	database *db.Database,
 	idGenerator utils.IdGenerator,
 	loan daos.LoanRepository,
 	disbursement daos.DisbursementRepository,
 	transaction daos.TransactionRepository,
 	beneficiary daos.BeneficiaryRepository,
+	deadLetter daos.DeadLetterRepository,
+	nameMatch NameMatchPolicy,
+	webhook WebhookService,
 	paymentChan chan string,
+	beneficiaryService BeneficiaryService,
+	directory *ifsc.Directory,
+	bus *events.Bus,
+	settings *config.Store,
 ) DisbursementService {
 	return &DisbursementServiceImpl{
-		idGenerator:  idGenerator,
-		loan:         loan,
-		disbursement: disbursement,
-		transaction:  transaction,
-		beneficiary:  beneficiary,
-		paymentChan:  paymentChan,
+		db:                 database,
+		idGenerator:        idGenerator,
+		loan:               loan,
+		disbursement:       disbursement,
+		transaction:        transaction,
+		beneficiary:        beneficiary,
+		deadLetter:         deadLetter,
+		nameMatch:          nameMatch,
+		webhook:            webhook,
+		paymentChan:        paymentChan,
+		beneficiaryService: beneficiaryService,
+		directory:          directory,
+		bus:                bus,
+		settings:           settings,
 	}
 }
 
@@ -50,43 +84,109 @@ func (d *DisbursementServiceImpl) Disburse(
 	ctx context.Context,
 	req *models.DisburseRequest,
 ) (*models.DisbursementResponse, error) {
+	ctx = logging.With(ctx, logging.Fields{LoanId: req.LoanId})
+	scheduled := req.ScheduledAt != nil && req.ScheduledAt.After(time.Now())
+	// Turned away early, before anything is written. The send below never
+	// waits, so a request that loses the race for the last slot is still
+	// created and left for the retry worker.
+	if !scheduled && queueFull(d.paymentChan) {
+		log.Ctx(ctx).Warn().Msg("payment queue is full, refusing disbursement")
+		return nil, models.PAYMENT_QUEUE_FULL
+	}
 	existing, err := d.disbursement.GetByLoanId(ctx, req.LoanId)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		log.Error().Err(err).Str("loan_id", req.LoanId).Msg("failed to check existing disbursement")
+		log.Ctx(ctx).Error().Err(err).Msg("failed to check existing disbursement")
 		return nil, fmt.Errorf("failed to check existing disbursement: %w", err)
 	}
-	log.Info().Msgf("existing disbursement: %+v", existing)
 	if existing != nil {
-		return &models.DisbursementResponse{
-			DisbursementId: existing.Id,
-			Status:         existing.Status,
-			Message:        "Disbursement already exists",
-		}, nil
+		log.Ctx(ctx).Info().
+			Str("existing_disbursement_id", existing.Id).
+			Str("existing_status", string(existing.Status)).
+			Msg("loan has an existing disbursement")
+	}
+	// A cancelled disbursement no longer holds the loan, so it is treated as
+	// if there were none.
+	if existing != nil && existing.Status == models.DisbursementStatusCancelled {
+		existing = nil
+	}
+	if existing != nil && existing.Status != models.DisbursementStatusSuccess {
+		return existingDisbursementResponse(existing), nil
 	}
 
 	loan, err := d.loan.Get(ctx, req.LoanId)
 	if err != nil {
-		log.Error().Err(err).Str("loan_id", req.LoanId).Msg("failed to get loan")
-		return nil, errors.New("invalid loan id")
+		log.Ctx(ctx).Error().Err(err).Msg("failed to get loan")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, models.INVALID_LOAN_ID
+		}
+		return nil, fmt.Errorf("failed to get loan: %w", err)
 	}
 
-	if loan.Amount != req.Amount {
-		return nil, fmt.Errorf("loan amount does not match disbursement amount")
+	// A loan whose last disbursement succeeded only takes another tranche
+	// while part of the sanctioned amount is still undisbursed.
+	if existing != nil && loan.Status != models.LoanStatusPartiallyDisbursed {
+		return existingDisbursementResponse(existing), nil
 	}
 
-	if loan.BeneficiaryId == nil {
-		beneficiaryId := d.idGenerator.GenerateBeneficiaryId()
-		beneficiary, err := d.beneficiary.CreateOrGet(
-			ctx,
-			beneficiaryId,
-			req.BeneficiaryName,
-			req.AccountNumber,
-			req.IFSCCode,
-			req.BeneficiaryBank,
-		)
+	if !loan.Status.IsDisbursable() {
+		return nil, fmt.Errorf("loan %s is %s: %w", loan.Id, loan.Status, models.LOAN_NOT_DISBURSABLE)
+	}
+
+	if req.Amount <= 0 || req.Amount > loan.Amount-loan.DisbursedAmount {
+		return nil, models.AMOUNT_EXCEEDS_UNDISBURSED
+	}
+
+	// A beneficiary given with the request is only linked to the loan once
+	// it passed the checks below, so a refused one does not stick to the
+	// loan and the next request can give other details.
+	var beneficiary *schema.Beneficiary
+	link := loan.BeneficiaryId == nil
+	if link {
+		// Registered without waiting for the penny drop, which the retry
+		// worker runs. A new payee is refused below as unverified; one
+		// already registered with these details is paid as it stands.
+		registered, err := d.beneficiaryService.Register(ctx, models.BeneficiaryRequest{
+			Name:          req.BeneficiaryName,
+			AccountNumber: req.AccountNumber,
+			IFSCCode:      req.IFSCCode,
+			Bank:          req.BeneficiaryBank,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to register beneficiary: %w", err)
+		}
+		beneficiary, err = d.beneficiary.GetById(ctx, registered.Id)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create or get beneficiary: %w", err)
+			return nil, fmt.Errorf("failed to get beneficiary: %w", err)
 		}
+	} else {
+		beneficiary, err = d.beneficiary.GetById(ctx, *loan.BeneficiaryId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get beneficiary: %w", err)
+		}
+	}
+
+	if err := checkPayable(beneficiary); err != nil {
+		log.Ctx(ctx).Warn().
+			Str("beneficiary_id", beneficiary.Id).
+			Str("status", string(beneficiary.Status)).
+			Msg("refusing disbursement to unverified beneficiary")
+		return nil, fmt.Errorf("beneficiary %s: %w", beneficiary.Id, err)
+	}
+
+	nameMatch := d.nameMatch.Evaluate(
+		beneficiary.Name,
+		loan.BorrowerName,
+		beneficiary.RegisteredName,
+	)
+	if nameMatch.Decision == models.NameMatchDecisionBlock {
+		log.Ctx(ctx).Warn().
+			Str("beneficiary_id", beneficiary.Id).
+			Any("name_match", nameMatch).
+			Msg("refusing disbursement on beneficiary name mismatch")
+		return nil, fmt.Errorf("beneficiary %s: %w", beneficiary.Id, models.NAME_MISMATCH)
+	}
+
+	if link {
 		loan.BeneficiaryId = &beneficiary.Id
 		_, err = d.loan.Update(ctx, loan.Id, map[string]any{
 			"beneficiary_id": beneficiary.Id,
@@ -96,33 +196,83 @@ func (d *DisbursementServiceImpl) Disburse(
 		}
 	}
 
+	status := models.DisbursementStatusInitiated
+	var scheduledAt *time.Time
+	if scheduled {
+		status = models.DisbursementStatusScheduled
+		scheduledAt = req.ScheduledAt
+	}
+
 	disbursementId := d.idGenerator.GenerateDisbursementId()
-	channel := d.selectChannel(req.Amount)
-	_, err = d.disbursement.Create(
-		ctx,
-		disbursementId,
-		loan.Id,
-		channel,
-		models.DisbursementStatusInitiated,
-		req.Amount,
-	)
+	channel := d.selectChannel(beneficiary, req.Amount)
+	ctx = logging.With(ctx, logging.Fields{DisbursementId: disbursementId, Channel: string(channel)})
+	disbursement := schema.Disbursement{
+		Id:                  disbursementId,
+		LoanId:              loan.Id,
+		ClientId:            req.ClientId,
+		Channel:             channel,
+		Amount:              req.Amount,
+		Status:              status,
+		NameMatchDecision:   nameMatch.Decision,
+		BorrowerNameScore:   nameMatch.BorrowerScore,
+		RegisteredNameScore: nameMatch.RegisteredScore,
+		ScheduledAt:         scheduledAt,
+		TraceParent:         tracing.TraceParent(ctx),
+	}
+	// The loan is reserved in the transaction that creates the disbursement,
+	// so of two requests racing on it only one creates a disbursement. A
+	// scheduled disbursement reserves it too, so no other disbursement can be
+	// created for it until this one is released and settled or cancelled.
+	err = d.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := transitionLoan(ctx, d.loan.WithTx(tx), loan, models.LoanStatusDisbursementPending, nil); err != nil {
+			return err
+		}
+		if _, err := d.disbursement.WithTx(tx).Create(ctx, disbursement); err != nil {
+			return fmt.Errorf("failed to create disbursement: %w", err)
+		}
+		return queueEvent(ctx, tx, d.webhook, models.WebhookEventCreated, disbursementId)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create disbursement: %w", err)
+		return nil, err
+	}
+	log.Ctx(ctx).Info().
+		Str("status", string(status)).
+		Float64("amount", req.Amount).
+		Msg("disbursement created")
+	// Published before the payment worker can pick the disbursement up, so
+	// subscribers see created ahead of processing.
+	publishStatus(d.bus, &disbursement, status, channel, 0, "")
+
+	message := "Disbursement created"
+	if status == models.DisbursementStatusScheduled {
+		message = fmt.Sprintf("Disbursement scheduled for %s", scheduledAt.Format(time.RFC3339))
+	} else if channel != models.PaymentChannelNEFT && !queuePayment(ctx, d.paymentChan, disbursementId) {
+		message += "; payment queue is full, it is sent on the next retry run"
+	}
+	if nameMatch.Decision == models.NameMatchDecisionFlag {
+		log.Ctx(ctx).Warn().
+			Any("name_match", nameMatch).
+			Msg("disbursement flagged for beneficiary name review")
+		message += "; beneficiary name flagged for review"
 	}
-	d.paymentChan <- disbursementId
 
 	return &models.DisbursementResponse{
 		DisbursementId: disbursementId,
-		Status:         models.DisbursementStatusInitiated,
-		Message:        "Disbursement created",
+		Status:         status,
+		Message:        message,
 	}, nil
 }
 
-func (d *DisbursementServiceImpl) Fetch(ctx context.Context, disbursementId string) (any, error) {
+func (d *DisbursementServiceImpl) Fetch(ctx context.Context, clientId, disbursementId string) (any, error) {
 	disbursement, err := d.disbursement.Get(ctx, disbursementId)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch disbursement: %w", err)
 	}
+	// Another client's disbursement is reported as missing, so ids cannot be
+	// probed.
+	if clientId != "" && disbursement.ClientId != clientId {
+		return nil, models.DISBURSEMENT_NOT_FOUND
+	}
 
 	transactions, err := d.transaction.ListByDisbursement(ctx, disbursementId)
 	if err != nil {
@@ -134,6 +284,11 @@ func (d *DisbursementServiceImpl) Fetch(ctx context.Context, disbursementId stri
 		Amount:         disbursement.Amount,
 		LoanId:         disbursement.LoanId,
 		Status:         disbursement.Status,
+		NameMatch: models.NameMatchResult{
+			BorrowerScore:   disbursement.BorrowerNameScore,
+			RegisteredScore: disbursement.RegisteredNameScore,
+			Decision:        disbursement.NameMatchDecision,
+		},
 		Transaction: func() []models.TransactionResponse {
 			txs := make([]models.TransactionResponse, len(transactions))
 			for i, transaction := range transactions {
@@ -142,14 +297,17 @@ func (d *DisbursementServiceImpl) Fetch(ctx context.Context, disbursementId stri
 					Status:        transaction.Status,
 					Channel:       transaction.Channel,
 					Message:       transaction.Message,
+					UTR:           transaction.UTR,
 					CreatedAt:     transaction.CreatedAt,
 					UpdatedAt:     transaction.UpdatedAt,
 				}
 			}
 			return txs
 		}(),
-		CreatedAt: disbursement.CreatedAt,
-		UpdatedAt: disbursement.UpdatedAt,
+		ScheduledAt:          disbursement.ScheduledAt,
+		ExpectedSettlementAt: disbursement.ExpectedSettlementAt,
+		CreatedAt:            disbursement.CreatedAt,
+		UpdatedAt:            disbursement.UpdatedAt,
 	}, nil
 }
 
@@ -158,23 +316,42 @@ func (d *DisbursementServiceImpl) Retry(ctx context.Context, disbursementId stri
 	if err != nil {
 		return nil, fmt.Errorf("failed to get disbursement: %w", err)
 	}
+	ctx = logging.With(ctx, logging.Fields{
+		LoanId:         disbursement.LoanId,
+		DisbursementId: disbursement.Id,
+	})
 
 	if disbursement.Status == models.DisbursementStatusProcessing {
-		return nil, fmt.Errorf("disbursement is in-progress")
+		return nil, fmt.Errorf("disbursement is in-progress: %w", models.DISBURSEMENT_NOT_RETRYABLE)
 	}
 
 	if disbursement.Status == models.DisbursementStatusSuccess {
-		return nil, fmt.Errorf("disbursement is completed")
+		return nil, fmt.Errorf("disbursement is completed: %w", models.DISBURSEMENT_NOT_RETRYABLE)
 	}
 
-	err = d.disbursement.Update(ctx, disbursementId, map[string]any{
-		"status":     string(models.DisbursementStatusInitiated),
-		"last_error": nil,
-		"updated_at": time.Now(),
-	})
+	if disbursement.Status == models.DisbursementStatusScheduled {
+		return nil, fmt.Errorf("disbursement is scheduled: %w", models.DISBURSEMENT_NOT_RETRYABLE)
+	}
+
+	if disbursement.Status == models.DisbursementStatusCancelled {
+		return nil, fmt.Errorf("disbursement is cancelled: %w", models.DISBURSEMENT_NOT_RETRYABLE)
+	}
+
+	// Turned away before anything is written, as in Disburse.
+	if disbursement.Channel != models.PaymentChannelNEFT && queueFull(d.paymentChan) {
+		log.Ctx(ctx).Warn().Msg("payment queue is full, refusing retry")
+		return nil, models.PAYMENT_QUEUE_FULL
+	}
+
+	err = requeue(ctx, d.loan, d.disbursement, d.deadLetter, d.bus, d.paymentChan, disbursement,
+		"", "retried through the disbursement API",
+	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to update disbursement: %w", err)
+		return nil, err
 	}
+	log.Ctx(ctx).Info().
+		Str("from", string(disbursement.Status)).
+		Msg("disbursement requeued for retry")
 
 	return &models.DisbursementResponse{
 		DisbursementId: disbursementId,
@@ -183,14 +360,192 @@ func (d *DisbursementServiceImpl) Retry(ctx context.Context, disbursementId stri
 	}, nil
 }
 
+// Cancel withdraws a scheduled disbursement before the scheduler releases it
+// and reopens the loan. Once released the payment may already be with the
+// bank, so it can no longer be cancelled.
+func (d *DisbursementServiceImpl) Cancel(
+	ctx context.Context,
+	disbursementId string,
+) (*models.DisbursementResponse, error) {
+	disbursement, err := d.disbursement.Get(ctx, disbursementId)
+	if err != nil {
+		return nil, err
+	}
+	if disbursement.Status != models.DisbursementStatusScheduled {
+		return nil, fmt.Errorf("disbursement is %s: %w", disbursement.Status, models.DISBURSEMENT_NOT_CANCELLABLE)
+	}
+	ctx = logging.With(ctx, logging.Fields{
+		LoanId:         disbursement.LoanId,
+		DisbursementId: disbursement.Id,
+	})
+
+	err = d.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		cancelled, err := d.disbursement.WithTx(tx).UpdateIfStatus(
+			ctx,
+			disbursementId,
+			models.DisbursementStatusScheduled,
+			map[string]any{
+				"status":     models.DisbursementStatusCancelled,
+				"updated_at": time.Now(),
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update disbursement: %w", err)
+		}
+		if !cancelled {
+			return fmt.Errorf("disbursement was released: %w", models.DISBURSEMENT_NOT_CANCELLABLE)
+		}
+		if err := releaseLoan(ctx, d.loan.WithTx(tx), disbursement.LoanId); err != nil {
+			return err
+		}
+		return queueEvent(ctx, tx, d.webhook, models.WebhookEventCancelled, disbursementId)
+	})
+	if err != nil {
+		return nil, err
+	}
+	publishStatus(d.bus, disbursement, models.DisbursementStatusCancelled, disbursement.Channel, disbursement.RetryCount, "")
+
+	log.Ctx(ctx).Info().Msg("scheduled disbursement cancelled")
+	return &models.DisbursementResponse{
+		DisbursementId: disbursementId,
+		Status:         models.DisbursementStatusCancelled,
+		Message:        "Disbursement cancelled",
+	}, nil
+}
+
+func existingDisbursementResponse(existing *schema.Disbursement) *models.DisbursementResponse {
+	return &models.DisbursementResponse{
+		DisbursementId: existing.Id,
+		Status:         existing.Status,
+		Message:        "Disbursement already exists",
+	}
+}
+
+// selectChannel picks the channel for amount and routes it to one the
+// beneficiary's branch is on, as the first payment attempt would. The
+// channel column decides which worker sends the disbursement, so a branch
+// only on NEFT goes to the NEFT worker from the start.
 func (d *DisbursementServiceImpl) selectChannel(
+	beneficiary *schema.Beneficiary,
 	amount float64,
 ) models.PaymentChannel {
-	if amount <= 100000 {
+	return routeChannel(d.directory, beneficiary, channelForAmount(d.settings.Get().Channels, amount))
+}
+
+// channelForAmount picks the first of UPI, IMPS and NEFT whose configured
+// limit covers amount.
+func channelForAmount(limits config.Channels, amount float64) models.PaymentChannel {
+	if amount <= limits.UPILimit {
 		return models.PaymentChannelUPI
 	}
-	if amount <= 500000 {
+	if amount <= limits.IMPSLimit {
 		return models.PaymentChannelIMPS
 	}
 	return models.PaymentChannelNEFT
 }
+
+// queueFull reports whether queue has no room left. Requests that would add
+// to it are turned away while it is full, rather than left waiting on the
+// payment worker. It is only a hint: sends go through queuePayment, which
+// never waits whatever the answer was.
+func queueFull(queue chan string) bool {
+	return len(queue) >= cap(queue)
+}
+
+// queuePayment hands an initiated disbursement to the payment worker without
+// waiting for room. When the queue is full the disbursement stays initiated
+// and the retry worker sends it instead, so callers need not act on a false
+// return.
+func queuePayment(ctx context.Context, paymentChan chan string, disbursementId string) bool {
+	select {
+	case paymentChan <- disbursementId:
+		return true
+	default:
+		log.Ctx(ctx).Warn().
+			Str("disbursement_id", disbursementId).
+			Msg("payment queue is full, leaving disbursement for the retry worker")
+		return false
+	}
+}
+
+// requeue puts a disbursement back to initiated so the workers pick it up
+// straight away, without waiting out the retry backoff. It only moves the
+// disbursement from the status it was read in, so a concurrent change wins.
+// A failed disbursement then reopens its loan and leaves the dead-letter
+// queue, resolved by resolvedBy with note. NEFT disbursements are left for
+// the NEFT worker, the rest are queued for the payment worker.
+func requeue(
+	ctx context.Context,
+	loans daos.LoanRepository,
+	disbursements daos.DisbursementRepository,
+	deadLetters daos.DeadLetterRepository,
+	bus *events.Bus,
+	paymentChan chan string,
+	disbursement *schema.Disbursement,
+	resolvedBy string,
+	note string,
+) error {
+	if err := reopen(ctx, loans, disbursements, deadLetters, disbursement, resolvedBy, note); err != nil {
+		return err
+	}
+	enqueue(ctx, bus, paymentChan, disbursement)
+	return nil
+}
+
+// reopen is the database half of requeue, so callers running it inside a
+// transaction can queue the disbursement once the transaction commits. The
+// loan of a failed disbursement is reserved first, so a disbursement whose
+// loan another disbursement now holds never becomes initiated for the
+// workers to send.
+func reopen(
+	ctx context.Context,
+	loans daos.LoanRepository,
+	disbursements daos.DisbursementRepository,
+	deadLetters daos.DeadLetterRepository,
+	disbursement *schema.Disbursement,
+	resolvedBy string,
+	note string,
+) error {
+	var loan *schema.Loan
+	if disbursement.Status == models.DisbursementStatusFailed {
+		var err error
+		loan, err = reserveLoan(ctx, loans, disbursement.LoanId)
+		if err != nil {
+			return err
+		}
+	}
+
+	updated, err := disbursements.UpdateIfStatus(ctx, disbursement.Id, disbursement.Status,
+		map[string]any{
+			"status":     string(models.DisbursementStatusInitiated),
+			"last_error": nil,
+			"updated_at": time.Now(),
+		},
+	)
+	if err == nil && !updated {
+		err = fmt.Errorf("%w: disbursement is no longer %s", models.DISBURSEMENT_STATUS_CHANGED, disbursement.Status)
+	} else if err != nil {
+		err = fmt.Errorf("failed to update disbursement: %w", err)
+	}
+	if err != nil {
+		if loan != nil {
+			unreserveLoan(ctx, loans, loan)
+		}
+		return err
+	}
+	if disbursement.Status == models.DisbursementStatusFailed {
+		return resolveDeadLetter(ctx, deadLetters, disbursement.Id,
+			models.DeadLetterStatusRequeued, resolvedBy, note,
+		)
+	}
+	return nil
+}
+
+// enqueue announces a reopened disbursement and hands it to the payment
+// worker. NEFT disbursements are left for the NEFT worker.
+func enqueue(ctx context.Context, bus *events.Bus, paymentChan chan string, disbursement *schema.Disbursement) {
+	publishStatus(bus, disbursement, models.DisbursementStatusInitiated, disbursement.Channel, disbursement.RetryCount, "")
+	if disbursement.Channel != models.PaymentChannelNEFT {
+		queuePayment(ctx, paymentChan, disbursement.Id)
+	}
+}
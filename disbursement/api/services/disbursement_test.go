@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"loan-disbursement-service/db/schema"
+	"loan-disbursement-service/events"
 	"loan-disbursement-service/models"
 	db_test "loan-disbursement-service/test/db"
 	utils_test "loan-disbursement-service/test/utils"
+	"shared/ifsc"
 	"testing"
 	"time"
 
@@ -27,12 +29,20 @@ func TestDisbursementService_Disburse(t *testing.T) {
 		paymentChan := make(chan string, 1)
 
 		service := NewDisbursementService(
+			setupMockDB(t),
 			mockIdGenerator,
 			mockLoan,
 			mockDisbursement,
 			mockTransaction,
 			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
 			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
 		)
 
 		loanId := "LOAN-123456789012"
@@ -51,6 +61,7 @@ func TestDisbursementService_Disburse(t *testing.T) {
 		loan := &schema.Loan{
 			Id:            loanId,
 			Amount:        request.Amount,
+			Status:        models.LoanStatusSanctioned,
 			BeneficiaryId: &beneficiaryId,
 			CreatedAt:     time.Now().Add(-24 * time.Hour),
 			UpdatedAt:     time.Now().Add(-24 * time.Hour),
@@ -66,14 +77,18 @@ func TestDisbursementService_Disburse(t *testing.T) {
 			UpdatedAt:  time.Now(),
 		}
 
-		mockDisbursement.On("Get", ctx, loanId).
+		mockDisbursement.On("GetByLoanId", mock.Anything, loanId).
 			Return(nil, gorm.ErrRecordNotFound).Once()
-		mockLoan.On("Get", ctx, loanId).
+		mockLoan.On("Get", mock.Anything, loanId).
 			Return(loan, nil).Once()
+		mockBeneficiary.On("GetById", mock.Anything, beneficiaryId).
+			Return(verifiedBeneficiary(beneficiaryId), nil).Once()
 		mockIdGenerator.On("GenerateDisbursementId").Return(disbursementId).Once()
-		mockDisbursement.On("Create", ctx, disbursementId, loanId, models.PaymentChannelUPI, models.DisbursementStatusInitiated, request.Amount).
+		mockDisbursement.On("Create", mock.Anything, matchDisbursement(disbursementId, loanId, models.PaymentChannelUPI, request.Amount)).
 			Return(&disbursement, nil).
 			Once()
+		mockLoan.On("UpdateIfStatus", mock.Anything, loanId, loan.Status, map[string]any{"status": models.LoanStatusDisbursementPending}).
+			Return(true, nil).Once()
 
 		result, err := service.Disburse(ctx, request)
 
@@ -102,15 +117,24 @@ func TestDisbursementService_Disburse(t *testing.T) {
 			mockDisbursement := new(db_test.MockDisbursementRepository)
 			mockTransaction := new(db_test.MockTransactionRepository)
 			mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+			mockBeneficiaryService := new(MockBeneficiaryService)
 			paymentChan := make(chan string, 1)
 
 			service := NewDisbursementService(
+				setupMockDB(t),
 				mockIdGenerator,
 				mockLoan,
 				mockDisbursement,
 				mockTransaction,
 				mockBeneficiary,
+				new(db_test.MockDeadLetterRepository),
+				NewNameMatchPolicy(nil),
+				newMockWebhookService(),
 				paymentChan,
+				mockBeneficiaryService,
+				nil,
+				events.NewBus(events.DefaultHistorySize),
+				nil,
 			)
 
 			loanId := "LOAN-123456789012"
@@ -129,6 +153,7 @@ func TestDisbursementService_Disburse(t *testing.T) {
 			loan := &schema.Loan{
 				Id:            loanId,
 				Amount:        request.Amount,
+				Status:        models.LoanStatusSanctioned,
 				BeneficiaryId: nil,
 				CreatedAt:     time.Now().Add(-24 * time.Hour),
 				UpdatedAt:     time.Now().Add(-24 * time.Hour),
@@ -137,6 +162,7 @@ func TestDisbursementService_Disburse(t *testing.T) {
 			updatedLoan := &schema.Loan{
 				Id:            loanId,
 				Amount:        request.Amount,
+				Status:        models.LoanStatusSanctioned,
 				BeneficiaryId: &beneficiaryId,
 				CreatedAt:     time.Now().Add(-24 * time.Hour),
 				UpdatedAt:     time.Now(),
@@ -148,6 +174,7 @@ func TestDisbursementService_Disburse(t *testing.T) {
 				Account:   request.AccountNumber,
 				IFSC:      request.IFSCCode,
 				Bank:      request.BeneficiaryBank,
+				Status:    models.BeneficiaryStatusVerified,
 				CreatedAt: time.Now(),
 				UpdatedAt: time.Now(),
 			}
@@ -162,20 +189,22 @@ func TestDisbursementService_Disburse(t *testing.T) {
 				UpdatedAt:  time.Now(),
 			}
 
-			mockDisbursement.On("Get", ctx, loanId).
+			mockDisbursement.On("GetByLoanId", mock.Anything, loanId).
 				Return(nil, gorm.ErrRecordNotFound).Once()
-			mockLoan.On("Get", ctx, loanId).
+			mockLoan.On("Get", mock.Anything, loanId).
 				Return(loan, nil).Once()
-			mockIdGenerator.On("GenerateBeneficiaryId").Return(beneficiaryId).Once()
-			mockBeneficiary.On("CreateOrGet", ctx, beneficiaryId, request.BeneficiaryName, request.AccountNumber, request.IFSCCode, request.BeneficiaryBank).
-				Return(&beneficiary, nil).
-				Once()
-			mockLoan.On("Update", ctx, loanId, map[string]any{"beneficiary_id": beneficiaryId}).
+			mockBeneficiaryService.On("Register", mock.Anything, beneficiaryRequest(request)).
+				Return(&models.BeneficiaryResponse{Id: beneficiaryId}, nil).Once()
+			mockBeneficiary.On("GetById", mock.Anything, beneficiaryId).
+				Return(&beneficiary, nil).Once()
+			mockLoan.On("Update", mock.Anything, loanId, map[string]any{"beneficiary_id": beneficiaryId}).
 				Return(updatedLoan, nil).Once()
 			mockIdGenerator.On("GenerateDisbursementId").Return(disbursementId).Once()
-			mockDisbursement.On("Create", ctx, disbursementId, loanId, models.PaymentChannelUPI, models.DisbursementStatusInitiated, request.Amount).
+			mockDisbursement.On("Create", mock.Anything, matchDisbursement(disbursementId, loanId, models.PaymentChannelUPI, request.Amount)).
 				Return(&disbursement, nil).
 				Once()
+			mockLoan.On("UpdateIfStatus", mock.Anything, loanId, loan.Status, map[string]any{"status": models.LoanStatusDisbursementPending}).
+				Return(true, nil).Once()
 
 			result, err := service.Disburse(ctx, request)
 
@@ -206,12 +235,20 @@ func TestDisbursementService_Disburse(t *testing.T) {
 
 		paymentChan := make(chan string, 1)
 		service := NewDisbursementService(
+			setupMockDB(t),
 			mockIdGenerator,
 			mockLoan,
 			mockDisbursement,
 			mockTransaction,
 			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
 			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
 		)
 
 		loanId := "LOAN-123456789012"
@@ -232,7 +269,7 @@ func TestDisbursementService_Disburse(t *testing.T) {
 			UpdatedAt:  time.Now(),
 		}
 
-		mockDisbursement.On("Get", ctx, loanId).
+		mockDisbursement.On("GetByLoanId", mock.Anything, loanId).
 			Return(&existingDisbursement, nil).Once()
 
 		result, err := service.Disburse(ctx, request)
@@ -256,12 +293,20 @@ func TestDisbursementService_Disburse(t *testing.T) {
 
 		paymentChan := make(chan string, 1)
 		service := NewDisbursementService(
+			setupMockDB(t),
 			mockIdGenerator,
 			mockLoan,
 			mockDisbursement,
 			mockTransaction,
 			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
 			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
 		)
 
 		loanId := "LOAN-123456789012"
@@ -273,7 +318,7 @@ func TestDisbursementService_Disburse(t *testing.T) {
 
 		repoError := errors.New("database connection error")
 
-		mockDisbursement.On("Get", ctx, loanId).
+		mockDisbursement.On("GetByLoanId", mock.Anything, loanId).
 			Return(nil, repoError).Once()
 
 		result, err := service.Disburse(ctx, request)
@@ -295,12 +340,20 @@ func TestDisbursementService_Disburse(t *testing.T) {
 
 		paymentChan := make(chan string, 1)
 		service := NewDisbursementService(
+			setupMockDB(t),
 			mockIdGenerator,
 			mockLoan,
 			mockDisbursement,
 			mockTransaction,
 			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
 			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
 		)
 
 		loanId := "LOAN-NONEXISTENT"
@@ -310,9 +363,9 @@ func TestDisbursementService_Disburse(t *testing.T) {
 			Amount: 10000.0,
 		}
 
-		mockDisbursement.On("Get", ctx, loanId).
+		mockDisbursement.On("GetByLoanId", mock.Anything, loanId).
 			Return(nil, gorm.ErrRecordNotFound).Once()
-		mockLoan.On("Get", ctx, loanId).
+		mockLoan.On("Get", mock.Anything, loanId).
 			Return(nil, gorm.ErrRecordNotFound).Once()
 
 		result, err := service.Disburse(ctx, request)
@@ -320,12 +373,13 @@ func TestDisbursementService_Disburse(t *testing.T) {
 		assert.Error(t, err)
 		assert.Nil(t, result)
 		assert.Equal(t, "invalid loan id", err.Error())
+		assert.ErrorIs(t, err, models.INVALID_LOAN_ID)
 
 		mockDisbursement.AssertExpectations(t)
 		mockLoan.AssertExpectations(t)
 	})
 
-	t.Run("returns error when loan amount does not match", func(t *testing.T) {
+	t.Run("returns error when amount exceeds undisbursed loan amount", func(t *testing.T) {
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 		mockLoan := new(db_test.MockLoanRepository)
 		mockDisbursement := new(db_test.MockDisbursementRepository)
@@ -334,12 +388,20 @@ func TestDisbursementService_Disburse(t *testing.T) {
 
 		paymentChan := make(chan string, 1)
 		service := NewDisbursementService(
+			setupMockDB(t),
 			mockIdGenerator,
 			mockLoan,
 			mockDisbursement,
 			mockTransaction,
 			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
 			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
 		)
 
 		loanId := "LOAN-123456789012"
@@ -351,23 +413,26 @@ func TestDisbursementService_Disburse(t *testing.T) {
 		}
 
 		loan := &schema.Loan{
-			Id:            loanId,
-			Amount:        15000.0, // Different amount
-			BeneficiaryId: &beneficiaryId,
-			CreatedAt:     time.Now().Add(-24 * time.Hour),
-			UpdatedAt:     time.Now().Add(-24 * time.Hour),
+			Id:              loanId,
+			Amount:          15000.0,
+			DisbursedAmount: 6000.0, // Only 9000 left to disburse
+			Status:          models.LoanStatusPartiallyDisbursed,
+			BeneficiaryId:   &beneficiaryId,
+			CreatedAt:       time.Now().Add(-24 * time.Hour),
+			UpdatedAt:       time.Now().Add(-24 * time.Hour),
 		}
 
-		mockDisbursement.On("Get", ctx, loanId).
+		mockDisbursement.On("GetByLoanId", mock.Anything, loanId).
 			Return(nil, gorm.ErrRecordNotFound).Once()
-		mockLoan.On("Get", ctx, loanId).
+		mockLoan.On("Get", mock.Anything, loanId).
 			Return(loan, nil).Once()
 
 		result, err := service.Disburse(ctx, request)
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
-		assert.Contains(t, err.Error(), "loan amount does not match disbursement amount")
+		assert.Contains(t, err.Error(), "disbursement amount exceeds undisbursed loan amount")
+		assert.ErrorIs(t, err, models.AMOUNT_EXCEEDS_UNDISBURSED)
 
 		mockDisbursement.AssertExpectations(t)
 		mockLoan.AssertExpectations(t)
@@ -379,19 +444,27 @@ func TestDisbursementService_Disburse(t *testing.T) {
 		mockDisbursement := new(db_test.MockDisbursementRepository)
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockBeneficiaryService := new(MockBeneficiaryService)
 
 		paymentChan := make(chan string, 1)
 		service := NewDisbursementService(
+			setupMockDB(t),
 			mockIdGenerator,
 			mockLoan,
 			mockDisbursement,
 			mockTransaction,
 			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
 			paymentChan,
+			mockBeneficiaryService,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
 		)
 
 		loanId := "LOAN-123456789012"
-		beneficiaryId := "BEN-987654321098"
 
 		request := &models.DisburseRequest{
 			LoanId:          loanId,
@@ -405,6 +478,7 @@ func TestDisbursementService_Disburse(t *testing.T) {
 		loan := &schema.Loan{
 			Id:            loanId,
 			Amount:        request.Amount,
+			Status:        models.LoanStatusSanctioned,
 			BeneficiaryId: nil,
 			CreatedAt:     time.Now().Add(-24 * time.Hour),
 			UpdatedAt:     time.Now().Add(-24 * time.Hour),
@@ -412,20 +486,18 @@ func TestDisbursementService_Disburse(t *testing.T) {
 
 		repoError := errors.New("database error")
 
-		mockDisbursement.On("Get", ctx, loanId).
+		mockDisbursement.On("GetByLoanId", mock.Anything, loanId).
 			Return(nil, gorm.ErrRecordNotFound).Once()
-		mockLoan.On("Get", ctx, loanId).
+		mockLoan.On("Get", mock.Anything, loanId).
 			Return(loan, nil).Once()
-		mockIdGenerator.On("GenerateBeneficiaryId").Return(beneficiaryId).Once()
-		mockBeneficiary.On("CreateOrGet", ctx, beneficiaryId, request.BeneficiaryName, request.AccountNumber, request.IFSCCode, request.BeneficiaryBank).
-			Return(nil, repoError).
-			Once()
+		mockBeneficiaryService.On("Register", mock.Anything, beneficiaryRequest(request)).
+			Return(nil, repoError).Once()
 
 		result, err := service.Disburse(ctx, request)
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
-		assert.Contains(t, err.Error(), "failed to create or get beneficiary")
+		assert.Contains(t, err.Error(), "failed to register beneficiary")
 
 		mockDisbursement.AssertExpectations(t)
 		mockLoan.AssertExpectations(t)
@@ -438,15 +510,24 @@ func TestDisbursementService_Disburse(t *testing.T) {
 		mockDisbursement := new(db_test.MockDisbursementRepository)
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockBeneficiaryService := new(MockBeneficiaryService)
 
 		paymentChan := make(chan string, 1)
 		service := NewDisbursementService(
+			setupMockDB(t),
 			mockIdGenerator,
 			mockLoan,
 			mockDisbursement,
 			mockTransaction,
 			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
 			paymentChan,
+			mockBeneficiaryService,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
 		)
 
 		loanId := "LOAN-123456789012"
@@ -464,6 +545,7 @@ func TestDisbursementService_Disburse(t *testing.T) {
 		loan := &schema.Loan{
 			Id:            loanId,
 			Amount:        request.Amount,
+			Status:        models.LoanStatusSanctioned,
 			BeneficiaryId: nil,
 			CreatedAt:     time.Now().Add(-24 * time.Hour),
 			UpdatedAt:     time.Now().Add(-24 * time.Hour),
@@ -475,21 +557,22 @@ func TestDisbursementService_Disburse(t *testing.T) {
 			Account:   request.AccountNumber,
 			IFSC:      request.IFSCCode,
 			Bank:      request.BeneficiaryBank,
+			Status:    models.BeneficiaryStatusVerified,
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
 		}
 
 		repoError := errors.New("database error")
 
-		mockDisbursement.On("Get", ctx, loanId).
+		mockDisbursement.On("GetByLoanId", mock.Anything, loanId).
 			Return(nil, gorm.ErrRecordNotFound).Once()
-		mockLoan.On("Get", ctx, loanId).
+		mockLoan.On("Get", mock.Anything, loanId).
 			Return(loan, nil).Once()
-		mockIdGenerator.On("GenerateBeneficiaryId").Return(beneficiaryId).Once()
-		mockBeneficiary.On("CreateOrGet", ctx, beneficiaryId, request.BeneficiaryName, request.AccountNumber, request.IFSCCode, request.BeneficiaryBank).
-			Return(&beneficiary, nil).
-			Once()
-		mockLoan.On("Update", ctx, loanId, map[string]any{"beneficiary_id": beneficiaryId}).
+		mockBeneficiaryService.On("Register", mock.Anything, beneficiaryRequest(request)).
+			Return(&models.BeneficiaryResponse{Id: beneficiaryId}, nil).Once()
+		mockBeneficiary.On("GetById", mock.Anything, beneficiaryId).
+			Return(&beneficiary, nil).Once()
+		mockLoan.On("Update", mock.Anything, loanId, map[string]any{"beneficiary_id": beneficiaryId}).
 			Return(nil, repoError).Once()
 
 		result, err := service.Disburse(ctx, request)
@@ -512,12 +595,20 @@ func TestDisbursementService_Disburse(t *testing.T) {
 
 		paymentChan := make(chan string, 1)
 		service := NewDisbursementService(
+			setupMockDB(t),
 			mockIdGenerator,
 			mockLoan,
 			mockDisbursement,
 			mockTransaction,
 			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
 			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
 		)
 
 		loanId := "LOAN-123456789012"
@@ -532,6 +623,7 @@ func TestDisbursementService_Disburse(t *testing.T) {
 		loan := &schema.Loan{
 			Id:            loanId,
 			Amount:        request.Amount,
+			Status:        models.LoanStatusSanctioned,
 			BeneficiaryId: &beneficiaryId,
 			CreatedAt:     time.Now().Add(-24 * time.Hour),
 			UpdatedAt:     time.Now().Add(-24 * time.Hour),
@@ -539,12 +631,17 @@ func TestDisbursementService_Disburse(t *testing.T) {
 
 		repoError := errors.New("database error")
 
-		mockDisbursement.On("Get", ctx, loanId).
+		mockDisbursement.On("GetByLoanId", mock.Anything, loanId).
 			Return(nil, gorm.ErrRecordNotFound).Once()
-		mockLoan.On("Get", ctx, loanId).
+		mockLoan.On("Get", mock.Anything, loanId).
 			Return(loan, nil).Once()
+		mockBeneficiary.On("GetById", mock.Anything, beneficiaryId).
+			Return(verifiedBeneficiary(beneficiaryId), nil).Once()
 		mockIdGenerator.On("GenerateDisbursementId").Return(disbursementId).Once()
-		mockDisbursement.On("Create", ctx, disbursementId, loanId, models.PaymentChannelUPI, models.DisbursementStatusInitiated, request.Amount).
+		mockLoan.On("UpdateIfStatus", mock.Anything, loanId, models.LoanStatusSanctioned, map[string]any{"status": models.LoanStatusDisbursementPending}).
+			Return(true, nil).
+			Once()
+		mockDisbursement.On("Create", mock.Anything, matchDisbursement(disbursementId, loanId, models.PaymentChannelUPI, request.Amount)).
 			Return(nil, repoError).
 			Once()
 
@@ -568,12 +665,20 @@ func TestDisbursementService_Disburse(t *testing.T) {
 		paymentChan := make(chan string, 1)
 
 		service := NewDisbursementService(
+			setupMockDB(t),
 			mockIdGenerator,
 			mockLoan,
 			mockDisbursement,
 			mockTransaction,
 			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
 			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
 		)
 
 		loanId := "LOAN-123456789012"
@@ -588,6 +693,7 @@ func TestDisbursementService_Disburse(t *testing.T) {
 		loan := &schema.Loan{
 			Id:            loanId,
 			Amount:        request.Amount,
+			Status:        models.LoanStatusSanctioned,
 			BeneficiaryId: &beneficiaryId,
 			CreatedAt:     time.Now().Add(-24 * time.Hour),
 			UpdatedAt:     time.Now().Add(-24 * time.Hour),
@@ -603,14 +709,18 @@ func TestDisbursementService_Disburse(t *testing.T) {
 			UpdatedAt:  time.Now(),
 		}
 
-		mockDisbursement.On("Get", ctx, loanId).
+		mockDisbursement.On("GetByLoanId", mock.Anything, loanId).
 			Return(nil, gorm.ErrRecordNotFound).Once()
-		mockLoan.On("Get", ctx, loanId).
+		mockLoan.On("Get", mock.Anything, loanId).
 			Return(loan, nil).Once()
+		mockBeneficiary.On("GetById", mock.Anything, beneficiaryId).
+			Return(verifiedBeneficiary(beneficiaryId), nil).Once()
 		mockIdGenerator.On("GenerateDisbursementId").Return(disbursementId).Once()
-		mockDisbursement.On("Create", ctx, disbursementId, loanId, models.PaymentChannelUPI, models.DisbursementStatusInitiated, request.Amount).
+		mockDisbursement.On("Create", mock.Anything, matchDisbursement(disbursementId, loanId, models.PaymentChannelUPI, request.Amount)).
 			Return(&disbursement, nil).
 			Once()
+		mockLoan.On("UpdateIfStatus", mock.Anything, loanId, loan.Status, map[string]any{"status": models.LoanStatusDisbursementPending}).
+			Return(true, nil).Once()
 
 		result, err := service.Disburse(ctx, request)
 
@@ -635,12 +745,20 @@ func TestDisbursementService_Disburse(t *testing.T) {
 		paymentChan := make(chan string, 1)
 
 		service := NewDisbursementService(
+			setupMockDB(t),
 			mockIdGenerator,
 			mockLoan,
 			mockDisbursement,
 			mockTransaction,
 			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
 			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
 		)
 
 		loanId := "LOAN-123456789012"
@@ -655,6 +773,7 @@ func TestDisbursementService_Disburse(t *testing.T) {
 		loan := &schema.Loan{
 			Id:            loanId,
 			Amount:        request.Amount,
+			Status:        models.LoanStatusSanctioned,
 			BeneficiaryId: &beneficiaryId,
 			CreatedAt:     time.Now().Add(-24 * time.Hour),
 			UpdatedAt:     time.Now().Add(-24 * time.Hour),
@@ -670,14 +789,18 @@ func TestDisbursementService_Disburse(t *testing.T) {
 			UpdatedAt:  time.Now(),
 		}
 
-		mockDisbursement.On("Get", ctx, loanId).
+		mockDisbursement.On("GetByLoanId", mock.Anything, loanId).
 			Return(nil, gorm.ErrRecordNotFound).Once()
-		mockLoan.On("Get", ctx, loanId).
+		mockLoan.On("Get", mock.Anything, loanId).
 			Return(loan, nil).Once()
+		mockBeneficiary.On("GetById", mock.Anything, beneficiaryId).
+			Return(verifiedBeneficiary(beneficiaryId), nil).Once()
 		mockIdGenerator.On("GenerateDisbursementId").Return(disbursementId).Once()
-		mockDisbursement.On("Create", ctx, disbursementId, loanId, models.PaymentChannelIMPS, models.DisbursementStatusInitiated, request.Amount).
+		mockDisbursement.On("Create", mock.Anything, matchDisbursement(disbursementId, loanId, models.PaymentChannelIMPS, request.Amount)).
 			Return(&disbursement, nil).
 			Once()
+		mockLoan.On("UpdateIfStatus", mock.Anything, loanId, loan.Status, map[string]any{"status": models.LoanStatusDisbursementPending}).
+			Return(true, nil).Once()
 
 		result, err := service.Disburse(ctx, request)
 
@@ -702,12 +825,20 @@ func TestDisbursementService_Disburse(t *testing.T) {
 		paymentChan := make(chan string, 1)
 
 		service := NewDisbursementService(
+			setupMockDB(t),
 			mockIdGenerator,
 			mockLoan,
 			mockDisbursement,
 			mockTransaction,
 			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
 			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
 		)
 
 		loanId := "LOAN-123456789012"
@@ -722,6 +853,7 @@ func TestDisbursementService_Disburse(t *testing.T) {
 		loan := &schema.Loan{
 			Id:            loanId,
 			Amount:        request.Amount,
+			Status:        models.LoanStatusSanctioned,
 			BeneficiaryId: &beneficiaryId,
 			CreatedAt:     time.Now().Add(-24 * time.Hour),
 			UpdatedAt:     time.Now().Add(-24 * time.Hour),
@@ -737,14 +869,18 @@ func TestDisbursementService_Disburse(t *testing.T) {
 			UpdatedAt:  time.Now(),
 		}
 
-		mockDisbursement.On("Get", ctx, loanId).
+		mockDisbursement.On("GetByLoanId", mock.Anything, loanId).
 			Return(nil, gorm.ErrRecordNotFound).Once()
-		mockLoan.On("Get", ctx, loanId).
+		mockLoan.On("Get", mock.Anything, loanId).
 			Return(loan, nil).Once()
+		mockBeneficiary.On("GetById", mock.Anything, beneficiaryId).
+			Return(verifiedBeneficiary(beneficiaryId), nil).Once()
 		mockIdGenerator.On("GenerateDisbursementId").Return(disbursementId).Once()
-		mockDisbursement.On("Create", ctx, disbursementId, loanId, models.PaymentChannelNEFT, models.DisbursementStatusInitiated, request.Amount).
+		mockDisbursement.On("Create", mock.Anything, matchDisbursement(disbursementId, loanId, models.PaymentChannelNEFT, request.Amount)).
 			Return(&disbursement, nil).
 			Once()
+		mockLoan.On("UpdateIfStatus", mock.Anything, loanId, loan.Status, map[string]any{"status": models.LoanStatusDisbursementPending}).
+			Return(true, nil).Once()
 
 		result, err := service.Disburse(ctx, request)
 
@@ -759,305 +895,1194 @@ func TestDisbursementService_Disburse(t *testing.T) {
 		mockLoan.AssertExpectations(t)
 		mockIdGenerator.AssertExpectations(t)
 	})
-}
-
-func TestDisbursementService_Fetch(t *testing.T) {
-	ctx := context.Background()
-
-	t.Run("successfully fetches disbursement with transactions", func(t *testing.T) {
+	t.Run("routes to NEFT when the beneficiary's branch is only on NEFT", func(t *testing.T) {
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 		mockLoan := new(db_test.MockLoanRepository)
 		mockDisbursement := new(db_test.MockDisbursementRepository)
-		mockTransaction := new(db_test.MockTransactionRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
-
 		paymentChan := make(chan string, 1)
+		directory, err := ifsc.New([]ifsc.Branch{
+			{IFSC: "IFSC0001234", Bank: "Test Bank", Branch: "Fort", NEFT: true},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
 		service := NewDisbursementService(
+			setupMockDB(t),
 			mockIdGenerator,
 			mockLoan,
 			mockDisbursement,
-			mockTransaction,
+			new(db_test.MockTransactionRepository),
 			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
 			paymentChan,
+			nil,
+			directory,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
 		)
 
-		disbursementId := "DISB-123456789012"
 		loanId := "LOAN-123456789012"
-
-		disbursement := schema.Disbursement{
-			Id:         disbursementId,
-			LoanId:     loanId,
-			Amount:     10000.0,
-			Status:     models.DisbursementStatusProcessing,
-			RetryCount: 1,
-			CreatedAt:  time.Now().Add(-2 * time.Hour),
-			UpdatedAt:  time.Now().Add(-1 * time.Hour),
-		}
-
-		transactions := []schema.Transaction{
-			{
-				Id:             "TXN-001",
-				DisbursementId: disbursementId,
-				ReferenceId:    "REF-001",
-				Amount:         5000.0,
-				Channel:        models.PaymentChannelUPI,
-				Status:         models.TransactionStatusSuccess,
-				Message:        nil,
-				CreatedAt:      time.Now().Add(-1 * time.Hour),
-				UpdatedAt:      time.Now().Add(-30 * time.Minute),
-			},
-			{
-				Id:             "TXN-002",
-				DisbursementId: disbursementId,
-				ReferenceId:    "REF-002",
-				Amount:         5000.0,
-				Channel:        models.PaymentChannelNEFT,
-				Status:         models.TransactionStatusFailed,
-				Message:        stringPtr("Insufficient balance"),
-				CreatedAt:      time.Now().Add(-45 * time.Minute),
-				UpdatedAt:      time.Now().Add(-20 * time.Minute),
-			},
+		beneficiaryId := "BEN-987654321098"
+		disbursementId := "DISB-123456789012"
+		request := &models.DisburseRequest{LoanId: loanId, Amount: 20000.0}
+		loan := &schema.Loan{
+			Id:            loanId,
+			Amount:        request.Amount,
+			Status:        models.LoanStatusSanctioned,
+			BeneficiaryId: &beneficiaryId,
 		}
 
-		mockDisbursement.On("Get", ctx, disbursementId).
-			Return(&disbursement, nil).Once()
-		mockTransaction.On("ListByDisbursement", ctx, disbursementId).
-			Return(transactions, nil).Once()
+		mockDisbursement.On("GetByLoanId", mock.Anything, loanId).
+			Return(nil, gorm.ErrRecordNotFound).Once()
+		mockLoan.On("Get", mock.Anything, loanId).Return(loan, nil).Once()
+		mockBeneficiary.On("GetById", mock.Anything, beneficiaryId).
+			Return(verifiedBeneficiary(beneficiaryId), nil).Once()
+		mockIdGenerator.On("GenerateDisbursementId").Return(disbursementId).Once()
+		mockDisbursement.On("Create", mock.Anything, matchDisbursement(disbursementId, loanId, models.PaymentChannelNEFT, request.Amount)).
+			Return(&schema.Disbursement{Id: disbursementId}, nil).Once()
+		mockLoan.On("UpdateIfStatus", mock.Anything, loanId, loan.Status, map[string]any{"status": models.LoanStatusDisbursementPending}).
+			Return(true, nil).Once()
 
-		result, err := service.Fetch(ctx, disbursementId)
+		_, err = service.Disburse(ctx, request)
 
 		assert.NoError(t, err)
-		assert.NotNil(t, result)
-
-		disbursementResult, ok := result.(models.Disbursement)
-		assert.True(t, ok)
-		assert.Equal(t, disbursementId, disbursementResult.DisbursementId)
-		assert.Equal(t, loanId, disbursementResult.LoanId)
-		assert.Equal(t, 10000.0, disbursementResult.Amount)
-		assert.Equal(t, models.DisbursementStatusProcessing, disbursementResult.Status)
-		assert.Len(t, disbursementResult.Transaction, 2)
-		assert.Equal(t, "TXN-001", disbursementResult.Transaction[0].TransactionId)
-		assert.Equal(t, models.TransactionStatusSuccess, disbursementResult.Transaction[0].Status)
-		assert.Equal(t, "TXN-002", disbursementResult.Transaction[1].TransactionId)
-		assert.Equal(t, models.TransactionStatusFailed, disbursementResult.Transaction[1].Status)
-
+		// Left for the NEFT worker; the payment worker skips NEFT.
+		assert.Empty(t, paymentChan)
 		mockDisbursement.AssertExpectations(t)
-		mockTransaction.AssertExpectations(t)
 	})
-
-	t.Run("successfully fetches disbursement with no transactions", func(t *testing.T) {
+	t.Run("refuses disbursement when beneficiary is not verified", func(t *testing.T) {
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 		mockLoan := new(db_test.MockLoanRepository)
 		mockDisbursement := new(db_test.MockDisbursementRepository)
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
-
 		paymentChan := make(chan string, 1)
+
 		service := NewDisbursementService(
+			setupMockDB(t),
 			mockIdGenerator,
 			mockLoan,
 			mockDisbursement,
 			mockTransaction,
 			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
 			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
 		)
 
-		disbursementId := "DISB-123456789012"
 		loanId := "LOAN-123456789012"
+		beneficiaryId := "BEN-987654321098"
 
-		disbursement := schema.Disbursement{
-			Id:         disbursementId,
-			LoanId:     loanId,
-			Amount:     10000.0,
-			Status:     models.DisbursementStatusInitiated,
-			RetryCount: 0,
-			CreatedAt:  time.Now().Add(-1 * time.Hour),
-			UpdatedAt:  time.Now().Add(-1 * time.Hour),
+		request := &models.DisburseRequest{
+			LoanId: loanId,
+			Amount: 10000.0,
 		}
 
-		transactions := []schema.Transaction{}
+		loan := &schema.Loan{
+			Id:            loanId,
+			Amount:        request.Amount,
+			Status:        models.LoanStatusSanctioned,
+			BeneficiaryId: &beneficiaryId,
+		}
 
-		mockDisbursement.On("Get", ctx, disbursementId).
-			Return(&disbursement, nil).Once()
-		mockTransaction.On("ListByDisbursement", ctx, disbursementId).
-			Return(transactions, nil).Once()
+		beneficiary := verifiedBeneficiary(beneficiaryId)
+		beneficiary.Status = models.BeneficiaryStatusUnverified
 
-		result, err := service.Fetch(ctx, disbursementId)
+		mockDisbursement.On("GetByLoanId", mock.Anything, loanId).
+			Return(nil, gorm.ErrRecordNotFound).Once()
+		mockLoan.On("Get", mock.Anything, loanId).
+			Return(loan, nil).Once()
+		mockBeneficiary.On("GetById", mock.Anything, beneficiaryId).
+			Return(beneficiary, nil).Once()
 
-		assert.NoError(t, err)
-		assert.NotNil(t, result)
+		result, err := service.Disburse(ctx, request)
 
-		disbursementResult, ok := result.(models.Disbursement)
-		assert.True(t, ok)
-		assert.Equal(t, disbursementId, disbursementResult.DisbursementId)
-		assert.Len(t, disbursementResult.Transaction, 0)
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, models.BENEFICIARY_NOT_VERIFIED)
+		assert.Empty(t, paymentChan)
 
-		mockDisbursement.AssertExpectations(t)
-		mockTransaction.AssertExpectations(t)
+		mockDisbursement.AssertNotCalled(t, "Create")
+		mockBeneficiary.AssertExpectations(t)
 	})
 
-	t.Run("returns error when disbursement not found", func(t *testing.T) {
+	t.Run("does not link a beneficiary given with the request when it is refused", func(t *testing.T) {
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 		mockLoan := new(db_test.MockLoanRepository)
 		mockDisbursement := new(db_test.MockDisbursementRepository)
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
-
+		mockBeneficiaryService := new(MockBeneficiaryService)
 		paymentChan := make(chan string, 1)
+
 		service := NewDisbursementService(
+			setupMockDB(t),
 			mockIdGenerator,
 			mockLoan,
 			mockDisbursement,
 			mockTransaction,
 			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
 			paymentChan,
+			mockBeneficiaryService,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
 		)
 
-		disbursementId := "DISB-NONEXISTENT"
+		loanId := "LOAN-123456789012"
+		beneficiaryId := "BEN-987654321098"
 
-		mockDisbursement.On("Get", ctx, disbursementId).
+		request := &models.DisburseRequest{
+			LoanId:          loanId,
+			Amount:          10000.0,
+			AccountNumber:   "1234567890",
+			IFSCCode:        "IFSC0001234",
+			BeneficiaryName: "John Doe",
+			BeneficiaryBank: "Test Bank",
+		}
+
+		loan := &schema.Loan{
+			Id:     loanId,
+			Amount: request.Amount,
+			Status: models.LoanStatusSanctioned,
+		}
+
+		beneficiary := verifiedBeneficiary(beneficiaryId)
+		beneficiary.Status = models.BeneficiaryStatusUnverified
+
+		mockDisbursement.On("GetByLoanId", mock.Anything, loanId).
 			Return(nil, gorm.ErrRecordNotFound).Once()
+		mockLoan.On("Get", mock.Anything, loanId).
+			Return(loan, nil).Once()
+		mockBeneficiaryService.On("Register", mock.Anything, beneficiaryRequest(request)).
+			Return(&models.BeneficiaryResponse{Id: beneficiaryId}, nil).Once()
+		mockBeneficiary.On("GetById", mock.Anything, beneficiaryId).
+			Return(beneficiary, nil).Once()
 
-		result, err := service.Fetch(ctx, disbursementId)
+		result, err := service.Disburse(ctx, request)
 
-		assert.Error(t, err)
 		assert.Nil(t, result)
-		assert.Contains(t, err.Error(), "failed to fetch disbursement")
+		assert.ErrorIs(t, err, models.BENEFICIARY_NOT_VERIFIED)
+		assert.Nil(t, loan.BeneficiaryId)
 
-		mockDisbursement.AssertExpectations(t)
-		mockTransaction.AssertNotCalled(t, "ListByDisbursement")
+		mockLoan.AssertNotCalled(t, "Update")
+		mockDisbursement.AssertNotCalled(t, "Create")
+		mockBeneficiary.AssertExpectations(t)
 	})
 
-	t.Run("returns error when transaction list fails", func(t *testing.T) {
+	t.Run("refuses disbursement when beneficiary is rejected", func(t *testing.T) {
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 		mockLoan := new(db_test.MockLoanRepository)
 		mockDisbursement := new(db_test.MockDisbursementRepository)
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
-
 		paymentChan := make(chan string, 1)
+
 		service := NewDisbursementService(
+			setupMockDB(t),
 			mockIdGenerator,
 			mockLoan,
 			mockDisbursement,
 			mockTransaction,
 			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
 			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
 		)
 
-		disbursementId := "DISB-123456789012"
+		loanId := "LOAN-123456789012"
+		beneficiaryId := "BEN-987654321098"
 
-		disbursement := schema.Disbursement{
-			Id:         disbursementId,
-			LoanId:     "LOAN-123456789012",
-			Amount:     10000.0,
-			Status:     models.DisbursementStatusInitiated,
-			RetryCount: 0,
-			CreatedAt:  time.Now(),
-			UpdatedAt:  time.Now(),
+		request := &models.DisburseRequest{
+			LoanId: loanId,
+			Amount: 10000.0,
 		}
 
-		repoError := errors.New("database error")
+		loan := &schema.Loan{
+			Id:            loanId,
+			Amount:        request.Amount,
+			Status:        models.LoanStatusSanctioned,
+			BeneficiaryId: &beneficiaryId,
+		}
 
-		mockDisbursement.On("Get", ctx, disbursementId).
-			Return(&disbursement, nil).Once()
-		mockTransaction.On("ListByDisbursement", ctx, disbursementId).
-			Return([]schema.Transaction{}, repoError).Once()
+		reason := "manual override"
+		now := time.Now()
+		beneficiary := verifiedBeneficiary(beneficiaryId)
+		beneficiary.Status = models.BeneficiaryStatusRejected
+		beneficiary.OverrideReason = &reason
+		beneficiary.OverriddenAt = &now
 
-		result, err := service.Fetch(ctx, disbursementId)
+		mockDisbursement.On("GetByLoanId", mock.Anything, loanId).
+			Return(nil, gorm.ErrRecordNotFound).Once()
+		mockLoan.On("Get", mock.Anything, loanId).
+			Return(loan, nil).Once()
+		mockBeneficiary.On("GetById", mock.Anything, beneficiaryId).
+			Return(beneficiary, nil).Once()
 
-		assert.Error(t, err)
-		assert.Nil(t, result)
-		assert.Contains(t, err.Error(), "failed to fetch transactions")
+		result, err := service.Disburse(ctx, request)
 
-		mockDisbursement.AssertExpectations(t)
-		mockTransaction.AssertExpectations(t)
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, models.BENEFICIARY_REJECTED)
+		mockDisbursement.AssertNotCalled(t, "Create")
 	})
-}
-
-func TestDisbursementService_Retry(t *testing.T) {
-	ctx := context.Background()
 
-	t.Run("successfully retries disbursement", func(t *testing.T) {
+	t.Run("disburses to unverified beneficiary when override is recorded", func(t *testing.T) {
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 		mockLoan := new(db_test.MockLoanRepository)
 		mockDisbursement := new(db_test.MockDisbursementRepository)
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
-
 		paymentChan := make(chan string, 1)
+
 		service := NewDisbursementService(
+			setupMockDB(t),
 			mockIdGenerator,
 			mockLoan,
 			mockDisbursement,
 			mockTransaction,
 			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
 			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
 		)
 
+		loanId := "LOAN-123456789012"
+		beneficiaryId := "BEN-987654321098"
 		disbursementId := "DISB-123456789012"
 
-		disbursement := schema.Disbursement{
-			Id:         disbursementId,
-			LoanId:     "LOAN-123456789012",
-			Amount:     10000.0,
-			Status:     models.DisbursementStatusFailed,
-			RetryCount: 2,
-			LastError:  stringPtr("Network error"),
-			CreatedAt:  time.Now().Add(-2 * time.Hour),
-			UpdatedAt:  time.Now().Add(-1 * time.Hour),
+		request := &models.DisburseRequest{
+			LoanId: loanId,
+			Amount: 10000.0,
 		}
 
-		mockDisbursement.On("Get", ctx, disbursementId).
-			Return(&disbursement, nil).Once()
-		mockDisbursement.On("Update", ctx, disbursementId, mock.MatchedBy(func(fields map[string]any) bool {
-			return fields["status"] == string(models.DisbursementStatusInitiated) &&
-				fields["last_error"] == nil &&
-				fields["updated_at"] != nil
-		})).
-			Return(nil).
+		loan := &schema.Loan{
+			Id:            loanId,
+			Amount:        request.Amount,
+			Status:        models.LoanStatusSanctioned,
+			BeneficiaryId: &beneficiaryId,
+		}
+
+		reason := "KYC documents verified offline"
+		now := time.Now()
+		beneficiary := verifiedBeneficiary(beneficiaryId)
+		beneficiary.Status = models.BeneficiaryStatusUnverified
+		beneficiary.OverrideReason = &reason
+		beneficiary.OverriddenAt = &now
+
+		mockDisbursement.On("GetByLoanId", mock.Anything, loanId).
+			Return(nil, gorm.ErrRecordNotFound).Once()
+		mockLoan.On("Get", mock.Anything, loanId).
+			Return(loan, nil).Once()
+		mockBeneficiary.On("GetById", mock.Anything, beneficiaryId).
+			Return(beneficiary, nil).Once()
+		mockIdGenerator.On("GenerateDisbursementId").Return(disbursementId).Once()
+		mockDisbursement.On("Create", mock.Anything, matchDisbursement(disbursementId, loanId, models.PaymentChannelUPI, request.Amount)).
+			Return(&schema.Disbursement{Id: disbursementId}, nil).
 			Once()
+		mockLoan.On("UpdateIfStatus", mock.Anything, loanId, loan.Status, map[string]any{"status": models.LoanStatusDisbursementPending}).
+			Return(true, nil).Once()
 
-		result, err := service.Retry(ctx, disbursementId)
+		result, err := service.Disburse(ctx, request)
 
 		assert.NoError(t, err)
-		assert.NotNil(t, result)
-
-		response, ok := result.(*models.DisbursementResponse)
-		assert.True(t, ok)
-		assert.Equal(t, disbursementId, response.DisbursementId)
-		assert.Equal(t, models.DisbursementStatusInitiated, response.Status)
-		assert.Equal(t, "Disbursement retried", response.Message)
+		assert.Equal(t, disbursementId, result.DisbursementId)
+		assert.Equal(t, disbursementId, <-paymentChan)
 
 		mockDisbursement.AssertExpectations(t)
+		mockBeneficiary.AssertExpectations(t)
 	})
 
-	t.Run("returns error when disbursement not found", func(t *testing.T) {
+	t.Run("refuses disbursement when beneficiary name does not match borrower", func(t *testing.T) {
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 		mockLoan := new(db_test.MockLoanRepository)
 		mockDisbursement := new(db_test.MockDisbursementRepository)
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
-
 		paymentChan := make(chan string, 1)
+
 		service := NewDisbursementService(
+			setupMockDB(t),
 			mockIdGenerator,
 			mockLoan,
 			mockDisbursement,
 			mockTransaction,
 			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
 			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
 		)
 
-		disbursementId := "DISB-NONEXISTENT"
+		loanId := "LOAN-123456789012"
+		beneficiaryId := "BEN-987654321098"
 
-		mockDisbursement.On("Get", ctx, disbursementId).
-			Return(nil, gorm.ErrRecordNotFound).Once()
+		request := &models.DisburseRequest{
+			LoanId: loanId,
+			Amount: 10000.0,
+		}
 
-		result, err := service.Retry(ctx, disbursementId)
+		loan := &schema.Loan{
+			Id:            loanId,
+			Amount:        request.Amount,
+			Status:        models.LoanStatusSanctioned,
+			BorrowerName:  "Anita Desai",
+			BeneficiaryId: &beneficiaryId,
+		}
 
-		assert.Error(t, err)
-		assert.Nil(t, result)
+		mockDisbursement.On("GetByLoanId", mock.Anything, loanId).
+			Return(nil, gorm.ErrRecordNotFound).Once()
+		mockLoan.On("Get", mock.Anything, loanId).
+			Return(loan, nil).Once()
+		mockBeneficiary.On("GetById", mock.Anything, beneficiaryId).
+			Return(verifiedBeneficiary(beneficiaryId), nil).Once()
+
+		result, err := service.Disburse(ctx, request)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, models.NAME_MISMATCH)
+		mockDisbursement.AssertNotCalled(t, "Create")
+		assert.Empty(t, paymentChan)
+	})
+
+	t.Run("flags disbursement when beneficiary name partially matches", func(t *testing.T) {
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		paymentChan := make(chan string, 1)
+
+		service := NewDisbursementService(
+			setupMockDB(t),
+			mockIdGenerator,
+			mockLoan,
+			mockDisbursement,
+			mockTransaction,
+			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
+			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
+		)
+
+		loanId := "LOAN-123456789012"
+		beneficiaryId := "BEN-987654321098"
+		disbursementId := "DISB-123456789012"
+
+		request := &models.DisburseRequest{
+			LoanId: loanId,
+			Amount: 10000.0,
+		}
+
+		loan := &schema.Loan{
+			Id:            loanId,
+			Amount:        request.Amount,
+			Status:        models.LoanStatusSanctioned,
+			BorrowerName:  "Jon Doe",
+			BeneficiaryId: &beneficiaryId,
+		}
+
+		mockDisbursement.On("GetByLoanId", mock.Anything, loanId).
+			Return(nil, gorm.ErrRecordNotFound).Once()
+		mockLoan.On("Get", mock.Anything, loanId).
+			Return(loan, nil).Once()
+		mockBeneficiary.On("GetById", mock.Anything, beneficiaryId).
+			Return(verifiedBeneficiary(beneficiaryId), nil).Once()
+		mockIdGenerator.On("GenerateDisbursementId").Return(disbursementId).Once()
+		mockDisbursement.On("Create", mock.Anything, mock.MatchedBy(func(disbursement schema.Disbursement) bool {
+			return disbursement.NameMatchDecision == models.NameMatchDecisionFlag &&
+				disbursement.BorrowerNameScore != nil &&
+				*disbursement.RegisteredNameScore == 100
+		})).
+			Return(&schema.Disbursement{Id: disbursementId}, nil).
+			Once()
+		mockLoan.On("UpdateIfStatus", mock.Anything, loanId, loan.Status, map[string]any{"status": models.LoanStatusDisbursementPending}).
+			Return(true, nil).Once()
+
+		result, err := service.Disburse(ctx, request)
+
+		assert.NoError(t, err)
+		assert.Equal(t, disbursementId, result.DisbursementId)
+		assert.Contains(t, result.Message, "flagged for review")
+		assert.Equal(t, disbursementId, <-paymentChan)
+
+		mockDisbursement.AssertExpectations(t)
+	})
+	t.Run("schedules disbursement when scheduled_at is in the future", func(t *testing.T) {
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		paymentChan := make(chan string, 1)
+
+		service := NewDisbursementService(
+			setupMockDB(t),
+			mockIdGenerator,
+			mockLoan,
+			mockDisbursement,
+			mockTransaction,
+			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
+			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
+		)
+
+		loanId := "LOAN-123456789012"
+		beneficiaryId := "BEN-987654321098"
+		disbursementId := "DISB-123456789012"
+		scheduledAt := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+
+		request := &models.DisburseRequest{
+			LoanId:      loanId,
+			Amount:      10000.0,
+			ScheduledAt: &scheduledAt,
+		}
+
+		loan := &schema.Loan{
+			Id:            loanId,
+			Amount:        request.Amount,
+			Status:        models.LoanStatusSanctioned,
+			BeneficiaryId: &beneficiaryId,
+		}
+
+		mockDisbursement.On("GetByLoanId", mock.Anything, loanId).
+			Return(nil, gorm.ErrRecordNotFound).Once()
+		mockLoan.On("Get", mock.Anything, loanId).
+			Return(loan, nil).Once()
+		mockBeneficiary.On("GetById", mock.Anything, beneficiaryId).
+			Return(verifiedBeneficiary(beneficiaryId), nil).Once()
+		mockIdGenerator.On("GenerateDisbursementId").Return(disbursementId).Once()
+		mockDisbursement.On("Create", mock.Anything, mock.MatchedBy(func(disbursement schema.Disbursement) bool {
+			return disbursement.Status == models.DisbursementStatusScheduled &&
+				disbursement.ScheduledAt.Equal(scheduledAt)
+		})).
+			Return(&schema.Disbursement{Id: disbursementId}, nil).
+			Once()
+		mockLoan.On("UpdateIfStatus", mock.Anything, loanId, loan.Status, map[string]any{"status": models.LoanStatusDisbursementPending}).
+			Return(true, nil).Once()
+
+		result, err := service.Disburse(ctx, request)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.DisbursementStatusScheduled, result.Status)
+		assert.Equal(t, "Disbursement scheduled for "+scheduledAt.Format(time.RFC3339), result.Message)
+		assert.Empty(t, paymentChan)
+
+		mockDisbursement.AssertExpectations(t)
+		mockLoan.AssertExpectations(t)
+	})
+
+	t.Run("refuses disbursement without touching the loan when the payment queue is full", func(t *testing.T) {
+		mockLoan := new(db_test.MockLoanRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		paymentChan := make(chan string, 1)
+		paymentChan <- "DISB-QUEUED"
+
+		service := NewDisbursementService(
+			setupMockDB(t),
+			new(utils_test.MockIdGenerator),
+			mockLoan,
+			mockDisbursement,
+			new(db_test.MockTransactionRepository),
+			new(db_test.MockBeneficiaryRepository),
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
+			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
+		)
+
+		result, err := service.Disburse(ctx, &models.DisburseRequest{
+			LoanId: "LOAN-123456789012",
+			Amount: 10000.0,
+		})
+
+		assert.ErrorIs(t, err, models.PAYMENT_QUEUE_FULL)
+		assert.Nil(t, result)
+		assert.Len(t, paymentChan, 1)
+		mockDisbursement.AssertNotCalled(t, "GetByLoanId")
+		mockLoan.AssertNotCalled(t, "Get")
+	})
+
+	t.Run("disburses immediately when scheduled_at has passed", func(t *testing.T) {
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		paymentChan := make(chan string, 1)
+
+		service := NewDisbursementService(
+			setupMockDB(t),
+			mockIdGenerator,
+			mockLoan,
+			mockDisbursement,
+			mockTransaction,
+			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
+			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
+		)
+
+		loanId := "LOAN-123456789012"
+		beneficiaryId := "BEN-987654321098"
+		disbursementId := "DISB-123456789012"
+		scheduledAt := time.Now().Add(-time.Hour)
+
+		loan := &schema.Loan{
+			Id:            loanId,
+			Amount:        10000.0,
+			Status:        models.LoanStatusSanctioned,
+			BeneficiaryId: &beneficiaryId,
+		}
+
+		mockDisbursement.On("GetByLoanId", mock.Anything, loanId).
+			Return(nil, gorm.ErrRecordNotFound).Once()
+		mockLoan.On("Get", mock.Anything, loanId).
+			Return(loan, nil).Once()
+		mockBeneficiary.On("GetById", mock.Anything, beneficiaryId).
+			Return(verifiedBeneficiary(beneficiaryId), nil).Once()
+		mockIdGenerator.On("GenerateDisbursementId").Return(disbursementId).Once()
+		mockDisbursement.On("Create", mock.Anything, mock.MatchedBy(func(disbursement schema.Disbursement) bool {
+			return disbursement.Status == models.DisbursementStatusInitiated &&
+				disbursement.ScheduledAt == nil
+		})).
+			Return(&schema.Disbursement{Id: disbursementId}, nil).
+			Once()
+		mockLoan.On("UpdateIfStatus", mock.Anything, loanId, loan.Status, map[string]any{"status": models.LoanStatusDisbursementPending}).
+			Return(true, nil).Once()
+
+		result, err := service.Disburse(ctx, &models.DisburseRequest{
+			LoanId:      loanId,
+			Amount:      10000.0,
+			ScheduledAt: &scheduledAt,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.DisbursementStatusInitiated, result.Status)
+		assert.Equal(t, disbursementId, <-paymentChan)
+	})
+
+	t.Run("creates new disbursement when previous one was cancelled", func(t *testing.T) {
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		paymentChan := make(chan string, 1)
+
+		service := NewDisbursementService(
+			setupMockDB(t),
+			mockIdGenerator,
+			mockLoan,
+			mockDisbursement,
+			mockTransaction,
+			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
+			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
+		)
+
+		loanId := "LOAN-123456789012"
+		beneficiaryId := "BEN-987654321098"
+		disbursementId := "DISB-123456789012"
+
+		loan := &schema.Loan{
+			Id:            loanId,
+			Amount:        10000.0,
+			Status:        models.LoanStatusSanctioned,
+			BeneficiaryId: &beneficiaryId,
+		}
+
+		mockDisbursement.On("GetByLoanId", mock.Anything, loanId).Return(&schema.Disbursement{
+			Id:     "DISB-CANCELLED",
+			LoanId: loanId,
+			Status: models.DisbursementStatusCancelled,
+		}, nil).Once()
+		mockLoan.On("Get", mock.Anything, loanId).
+			Return(loan, nil).Once()
+		mockBeneficiary.On("GetById", mock.Anything, beneficiaryId).
+			Return(verifiedBeneficiary(beneficiaryId), nil).Once()
+		mockIdGenerator.On("GenerateDisbursementId").Return(disbursementId).Once()
+		mockDisbursement.On("Create", mock.Anything, matchDisbursement(disbursementId, loanId, models.PaymentChannelUPI, 10000.0)).
+			Return(&schema.Disbursement{Id: disbursementId}, nil).
+			Once()
+		mockLoan.On("UpdateIfStatus", mock.Anything, loanId, loan.Status, map[string]any{"status": models.LoanStatusDisbursementPending}).
+			Return(true, nil).Once()
+
+		result, err := service.Disburse(ctx, &models.DisburseRequest{LoanId: loanId, Amount: 10000.0})
+
+		assert.NoError(t, err)
+		assert.Equal(t, disbursementId, result.DisbursementId)
+		assert.Equal(t, disbursementId, <-paymentChan)
+	})
+}
+
+// beneficiaryRequest is the registration Disburse makes for the beneficiary
+// given with request.
+func beneficiaryRequest(request *models.DisburseRequest) models.BeneficiaryRequest {
+	return models.BeneficiaryRequest{
+		Name:          request.BeneficiaryName,
+		AccountNumber: request.AccountNumber,
+		IFSCCode:      request.IFSCCode,
+		Bank:          request.BeneficiaryBank,
+	}
+}
+
+func verifiedBeneficiary(id string) *schema.Beneficiary {
+	verifiedAt := time.Now().Add(-1 * time.Hour)
+	registeredName := "JOHN DOE"
+	return &schema.Beneficiary{
+		Id:             id,
+		Name:           "John Doe",
+		Account:        "1234567890",
+		IFSC:           "IFSC0001234",
+		Bank:           "Test Bank",
+		Status:         models.BeneficiaryStatusVerified,
+		VerifiedAt:     &verifiedAt,
+		RegisteredName: &registeredName,
+	}
+}
+
+func matchDisbursement(
+	id, loanId string,
+	channel models.PaymentChannel,
+	amount float64,
+) any {
+	return mock.MatchedBy(func(disbursement schema.Disbursement) bool {
+		return disbursement.Id == id &&
+			disbursement.LoanId == loanId &&
+			disbursement.Channel == channel &&
+			disbursement.Status == models.DisbursementStatusInitiated &&
+			disbursement.Amount == amount
+	})
+}
+
+func TestDisbursementService_Fetch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("successfully fetches disbursement with transactions", func(t *testing.T) {
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+
+		paymentChan := make(chan string, 1)
+		service := NewDisbursementService(
+			setupMockDB(t),
+			mockIdGenerator,
+			mockLoan,
+			mockDisbursement,
+			mockTransaction,
+			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
+			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
+		)
+
+		disbursementId := "DISB-123456789012"
+		loanId := "LOAN-123456789012"
+
+		disbursement := schema.Disbursement{
+			Id:         disbursementId,
+			LoanId:     loanId,
+			Amount:     10000.0,
+			Status:     models.DisbursementStatusProcessing,
+			RetryCount: 1,
+			CreatedAt:  time.Now().Add(-2 * time.Hour),
+			UpdatedAt:  time.Now().Add(-1 * time.Hour),
+		}
+
+		transactions := []schema.Transaction{
+			{
+				Id:             "TXN-001",
+				DisbursementId: disbursementId,
+				ReferenceId:    "REF-001",
+				Amount:         5000.0,
+				Channel:        models.PaymentChannelUPI,
+				Status:         models.TransactionStatusSuccess,
+				Message:        nil,
+				CreatedAt:      time.Now().Add(-1 * time.Hour),
+				UpdatedAt:      time.Now().Add(-30 * time.Minute),
+			},
+			{
+				Id:             "TXN-002",
+				DisbursementId: disbursementId,
+				ReferenceId:    "REF-002",
+				Amount:         5000.0,
+				Channel:        models.PaymentChannelNEFT,
+				Status:         models.TransactionStatusFailed,
+				Message:        stringPtr("Insufficient balance"),
+				CreatedAt:      time.Now().Add(-45 * time.Minute),
+				UpdatedAt:      time.Now().Add(-20 * time.Minute),
+			},
+		}
+
+		mockDisbursement.On("Get", ctx, disbursementId).
+			Return(&disbursement, nil).Once()
+		mockTransaction.On("ListByDisbursement", ctx, disbursementId).
+			Return(transactions, nil).Once()
+
+		result, err := service.Fetch(ctx, "", disbursementId)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, result)
+
+		disbursementResult, ok := result.(models.Disbursement)
+		assert.True(t, ok)
+		assert.Equal(t, disbursementId, disbursementResult.DisbursementId)
+		assert.Equal(t, loanId, disbursementResult.LoanId)
+		assert.Equal(t, 10000.0, disbursementResult.Amount)
+		assert.Equal(t, models.DisbursementStatusProcessing, disbursementResult.Status)
+		assert.Len(t, disbursementResult.Transaction, 2)
+		assert.Equal(t, "TXN-001", disbursementResult.Transaction[0].TransactionId)
+		assert.Equal(t, models.TransactionStatusSuccess, disbursementResult.Transaction[0].Status)
+		assert.Equal(t, "TXN-002", disbursementResult.Transaction[1].TransactionId)
+		assert.Equal(t, models.TransactionStatusFailed, disbursementResult.Transaction[1].Status)
+
+		mockDisbursement.AssertExpectations(t)
+		mockTransaction.AssertExpectations(t)
+	})
+
+	t.Run("successfully fetches disbursement with no transactions", func(t *testing.T) {
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+
+		paymentChan := make(chan string, 1)
+		service := NewDisbursementService(
+			setupMockDB(t),
+			mockIdGenerator,
+			mockLoan,
+			mockDisbursement,
+			mockTransaction,
+			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
+			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
+		)
+
+		disbursementId := "DISB-123456789012"
+		loanId := "LOAN-123456789012"
+
+		disbursement := schema.Disbursement{
+			Id:         disbursementId,
+			LoanId:     loanId,
+			Amount:     10000.0,
+			Status:     models.DisbursementStatusInitiated,
+			RetryCount: 0,
+			CreatedAt:  time.Now().Add(-1 * time.Hour),
+			UpdatedAt:  time.Now().Add(-1 * time.Hour),
+		}
+
+		transactions := []schema.Transaction{}
+
+		mockDisbursement.On("Get", ctx, disbursementId).
+			Return(&disbursement, nil).Once()
+		mockTransaction.On("ListByDisbursement", ctx, disbursementId).
+			Return(transactions, nil).Once()
+
+		result, err := service.Fetch(ctx, "", disbursementId)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, result)
+
+		disbursementResult, ok := result.(models.Disbursement)
+		assert.True(t, ok)
+		assert.Equal(t, disbursementId, disbursementResult.DisbursementId)
+		assert.Len(t, disbursementResult.Transaction, 0)
+
+		mockDisbursement.AssertExpectations(t)
+		mockTransaction.AssertExpectations(t)
+	})
+
+	t.Run("returns error when disbursement not found", func(t *testing.T) {
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+
+		paymentChan := make(chan string, 1)
+		service := NewDisbursementService(
+			setupMockDB(t),
+			mockIdGenerator,
+			mockLoan,
+			mockDisbursement,
+			mockTransaction,
+			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
+			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
+		)
+
+		disbursementId := "DISB-NONEXISTENT"
+
+		mockDisbursement.On("Get", ctx, disbursementId).
+			Return(nil, gorm.ErrRecordNotFound).Once()
+
+		result, err := service.Fetch(ctx, "", disbursementId)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "failed to fetch disbursement")
+
+		mockDisbursement.AssertExpectations(t)
+		mockTransaction.AssertNotCalled(t, "ListByDisbursement")
+	})
+
+	t.Run("hides another client's disbursement", func(t *testing.T) {
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+
+		paymentChan := make(chan string, 1)
+		service := NewDisbursementService(
+			setupMockDB(t),
+			mockIdGenerator,
+			mockLoan,
+			mockDisbursement,
+			mockTransaction,
+			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
+			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
+		)
+
+		disbursementId := "DISB-123456789012"
+
+		mockDisbursement.On("Get", ctx, disbursementId).Return(&schema.Disbursement{
+			Id:       disbursementId,
+			ClientId: "partner-a",
+			Status:   models.DisbursementStatusProcessing,
+		}, nil).Once()
+
+		result, err := service.Fetch(ctx, "partner-b", disbursementId)
+
+		assert.ErrorIs(t, err, models.DISBURSEMENT_NOT_FOUND)
+		assert.Nil(t, result)
+		mockTransaction.AssertNotCalled(t, "ListByDisbursement")
+	})
+
+	t.Run("returns error when transaction list fails", func(t *testing.T) {
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+
+		paymentChan := make(chan string, 1)
+		service := NewDisbursementService(
+			setupMockDB(t),
+			mockIdGenerator,
+			mockLoan,
+			mockDisbursement,
+			mockTransaction,
+			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
+			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
+		)
+
+		disbursementId := "DISB-123456789012"
+
+		disbursement := schema.Disbursement{
+			Id:         disbursementId,
+			LoanId:     "LOAN-123456789012",
+			Amount:     10000.0,
+			Status:     models.DisbursementStatusInitiated,
+			RetryCount: 0,
+			CreatedAt:  time.Now(),
+			UpdatedAt:  time.Now(),
+		}
+
+		repoError := errors.New("database error")
+
+		mockDisbursement.On("Get", ctx, disbursementId).
+			Return(&disbursement, nil).Once()
+		mockTransaction.On("ListByDisbursement", ctx, disbursementId).
+			Return([]schema.Transaction{}, repoError).Once()
+
+		result, err := service.Fetch(ctx, "", disbursementId)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "failed to fetch transactions")
+
+		mockDisbursement.AssertExpectations(t)
+		mockTransaction.AssertExpectations(t)
+	})
+}
+
+func TestDisbursementService_Retry(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("successfully retries disbursement", func(t *testing.T) {
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
+
+		paymentChan := make(chan string, 1)
+		service := NewDisbursementService(
+			setupMockDB(t),
+			mockIdGenerator,
+			mockLoan,
+			mockDisbursement,
+			mockTransaction,
+			mockBeneficiary,
+			mockDeadLetter,
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
+			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
+		)
+
+		disbursementId := "DISB-123456789012"
+
+		disbursement := schema.Disbursement{
+			Id:         disbursementId,
+			LoanId:     "LOAN-123456789012",
+			Amount:     10000.0,
+			Status:     models.DisbursementStatusFailed,
+			RetryCount: 2,
+			LastError:  stringPtr("Network error"),
+			CreatedAt:  time.Now().Add(-2 * time.Hour),
+			UpdatedAt:  time.Now().Add(-1 * time.Hour),
+		}
+
+		mockDisbursement.On("Get", mock.Anything, disbursementId).
+			Return(&disbursement, nil).Once()
+		mockLoan.On("Get", mock.Anything, disbursement.LoanId).
+			Return(&schema.Loan{Id: disbursement.LoanId, Status: models.LoanStatusSanctioned}, nil).Once()
+		mockLoan.On("UpdateIfStatus", mock.Anything, disbursement.LoanId, models.LoanStatusSanctioned, map[string]any{"status": models.LoanStatusDisbursementPending}).
+			Return(true, nil).Once()
+		mockDisbursement.On("UpdateIfStatus", mock.Anything, disbursementId, disbursement.Status, mock.MatchedBy(func(fields map[string]any) bool {
+			return fields["status"] == string(models.DisbursementStatusInitiated) &&
+				fields["last_error"] == nil &&
+				fields["updated_at"] != nil
+		})).
+			Return(true, nil).
+			Once()
+		mockDeadLetter.On("UpdateIfStatus", mock.Anything, disbursementId, models.DeadLetterStatusOpen,
+			mock.MatchedBy(func(fields map[string]any) bool {
+				_, operator := fields["resolved_by"]
+				return fields["status"] == models.DeadLetterStatusRequeued && !operator
+			}),
+		).Return(true, nil).Once()
+
+		result, err := service.Retry(ctx, disbursementId)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, result)
+
+		response, ok := result.(*models.DisbursementResponse)
+		assert.True(t, ok)
+		assert.Equal(t, disbursementId, response.DisbursementId)
+		assert.Equal(t, models.DisbursementStatusInitiated, response.Status)
+		assert.Equal(t, "Disbursement retried", response.Message)
+		assert.Equal(t, disbursementId, <-paymentChan)
+
+		mockDisbursement.AssertExpectations(t)
+		mockLoan.AssertExpectations(t)
+		mockDeadLetter.AssertExpectations(t)
+	})
+
+	t.Run("leaves suspended NEFT disbursement for the NEFT worker", func(t *testing.T) {
+		mockLoan := new(db_test.MockLoanRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+
+		paymentChan := make(chan string, 1)
+		service := NewDisbursementService(
+			setupMockDB(t),
+			new(utils_test.MockIdGenerator),
+			mockLoan,
+			mockDisbursement,
+			new(db_test.MockTransactionRepository),
+			new(db_test.MockBeneficiaryRepository),
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
+			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
+		)
+
+		disbursement := schema.Disbursement{
+			Id:      "DISB-123456789012",
+			LoanId:  "LOAN-123456789012",
+			Channel: models.PaymentChannelNEFT,
+			Status:  models.DisbursementStatusSuspended,
+		}
+
+		mockDisbursement.On("Get", mock.Anything, disbursement.Id).Return(&disbursement, nil).Once()
+		mockDisbursement.On("UpdateIfStatus", mock.Anything, disbursement.Id, disbursement.Status, mock.Anything).Return(true, nil).Once()
+
+		_, err := service.Retry(ctx, disbursement.Id)
+
+		assert.NoError(t, err)
+		assert.Empty(t, paymentChan)
+		mockLoan.AssertNotCalled(t, "Get")
+		mockDisbursement.AssertExpectations(t)
+	})
+
+	t.Run("refuses retry when the payment queue is full", func(t *testing.T) {
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		paymentChan := make(chan string, 1)
+		paymentChan <- "DISB-QUEUED"
+
+		service := NewDisbursementService(
+			setupMockDB(t),
+			new(utils_test.MockIdGenerator),
+			new(db_test.MockLoanRepository),
+			mockDisbursement,
+			new(db_test.MockTransactionRepository),
+			new(db_test.MockBeneficiaryRepository),
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
+			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
+		)
+
+		disbursement := schema.Disbursement{
+			Id:      "DISB-123456789012",
+			LoanId:  "LOAN-123456789012",
+			Channel: models.PaymentChannelIMPS,
+			Status:  models.DisbursementStatusSuspended,
+		}
+		mockDisbursement.On("Get", mock.Anything, disbursement.Id).Return(&disbursement, nil).Once()
+
+		_, err := service.Retry(ctx, disbursement.Id)
+
+		assert.ErrorIs(t, err, models.PAYMENT_QUEUE_FULL)
+		mockDisbursement.AssertNotCalled(t, "UpdateIfStatus")
+		mockDisbursement.AssertExpectations(t)
+	})
+
+	t.Run("returns error when disbursement not found", func(t *testing.T) {
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+
+		paymentChan := make(chan string, 1)
+		service := NewDisbursementService(
+			setupMockDB(t),
+			mockIdGenerator,
+			mockLoan,
+			mockDisbursement,
+			mockTransaction,
+			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
+			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
+		)
+
+		disbursementId := "DISB-NONEXISTENT"
+
+		mockDisbursement.On("Get", mock.Anything, disbursementId).
+			Return(nil, gorm.ErrRecordNotFound).Once()
+
+		result, err := service.Retry(ctx, disbursementId)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
 		assert.Contains(t, err.Error(), "failed to get disbursement")
 
 		mockDisbursement.AssertExpectations(t)
@@ -1072,12 +2097,20 @@ func TestDisbursementService_Retry(t *testing.T) {
 
 		paymentChan := make(chan string, 1)
 		service := NewDisbursementService(
+			setupMockDB(t),
 			mockIdGenerator,
 			mockLoan,
 			mockDisbursement,
 			mockTransaction,
 			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
 			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
 		)
 
 		disbursementId := "DISB-123456789012"
@@ -1092,7 +2125,7 @@ func TestDisbursementService_Retry(t *testing.T) {
 			UpdatedAt:  time.Now(),
 		}
 
-		mockDisbursement.On("Get", ctx, disbursementId).
+		mockDisbursement.On("Get", mock.Anything, disbursementId).
 			Return(&disbursement, nil).Once()
 
 		result, err := service.Retry(ctx, disbursementId)
@@ -1100,9 +2133,10 @@ func TestDisbursementService_Retry(t *testing.T) {
 		assert.Error(t, err)
 		assert.Nil(t, result)
 		assert.Contains(t, err.Error(), "disbursement is in-progress")
+		assert.ErrorIs(t, err, models.DISBURSEMENT_NOT_RETRYABLE)
 
 		mockDisbursement.AssertExpectations(t)
-		mockDisbursement.AssertNotCalled(t, "Update")
+		mockDisbursement.AssertNotCalled(t, "UpdateIfStatus")
 	})
 
 	t.Run("returns error when disbursement is completed", func(t *testing.T) {
@@ -1114,12 +2148,20 @@ func TestDisbursementService_Retry(t *testing.T) {
 
 		paymentChan := make(chan string, 1)
 		service := NewDisbursementService(
+			setupMockDB(t),
 			mockIdGenerator,
 			mockLoan,
 			mockDisbursement,
 			mockTransaction,
 			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
 			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
 		)
 
 		disbursementId := "DISB-123456789012"
@@ -1134,7 +2176,7 @@ func TestDisbursementService_Retry(t *testing.T) {
 			UpdatedAt:  time.Now().Add(-1 * time.Hour),
 		}
 
-		mockDisbursement.On("Get", ctx, disbursementId).
+		mockDisbursement.On("Get", mock.Anything, disbursementId).
 			Return(&disbursement, nil).Once()
 
 		result, err := service.Retry(ctx, disbursementId)
@@ -1142,9 +2184,50 @@ func TestDisbursementService_Retry(t *testing.T) {
 		assert.Error(t, err)
 		assert.Nil(t, result)
 		assert.Contains(t, err.Error(), "disbursement is completed")
+		assert.ErrorIs(t, err, models.DISBURSEMENT_NOT_RETRYABLE)
 
 		mockDisbursement.AssertExpectations(t)
-		mockDisbursement.AssertNotCalled(t, "Update")
+		mockDisbursement.AssertNotCalled(t, "UpdateIfStatus")
+	})
+
+	t.Run("returns error when disbursement is scheduled", func(t *testing.T) {
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		paymentChan := make(chan string, 1)
+
+		service := NewDisbursementService(
+			setupMockDB(t),
+			mockIdGenerator,
+			mockLoan,
+			mockDisbursement,
+			mockTransaction,
+			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
+			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
+		)
+
+		disbursementId := "DISB-123456789012"
+
+		mockDisbursement.On("Get", mock.Anything, disbursementId).Return(&schema.Disbursement{
+			Id:     disbursementId,
+			LoanId: "LOAN-123456789012",
+			Status: models.DisbursementStatusScheduled,
+		}, nil).Once()
+
+		result, err := service.Retry(ctx, disbursementId)
+
+		assert.Nil(t, result)
+		assert.ErrorContains(t, err, "disbursement is scheduled")
+		mockDisbursement.AssertNotCalled(t, "UpdateIfStatus")
 	})
 
 	t.Run("returns error when disbursement update fails", func(t *testing.T) {
@@ -1156,12 +2239,20 @@ func TestDisbursementService_Retry(t *testing.T) {
 
 		paymentChan := make(chan string, 1)
 		service := NewDisbursementService(
+			setupMockDB(t),
 			mockIdGenerator,
 			mockLoan,
 			mockDisbursement,
 			mockTransaction,
 			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
 			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
 		)
 
 		disbursementId := "DISB-123456789012"
@@ -1179,10 +2270,16 @@ func TestDisbursementService_Retry(t *testing.T) {
 
 		repoError := errors.New("database error")
 
-		mockDisbursement.On("Get", ctx, disbursementId).
+		mockDisbursement.On("Get", mock.Anything, disbursementId).
 			Return(&disbursement, nil).Once()
-		mockDisbursement.On("Update", ctx, disbursementId, mock.Anything).
-			Return(repoError).Once()
+		mockLoan.On("Get", mock.Anything, disbursement.LoanId).
+			Return(&schema.Loan{Id: disbursement.LoanId, Status: models.LoanStatusSanctioned}, nil).Once()
+		mockLoan.On("UpdateIfStatus", mock.Anything, disbursement.LoanId, models.LoanStatusSanctioned, map[string]any{"status": models.LoanStatusDisbursementPending}).
+			Return(true, nil).Once()
+		mockDisbursement.On("UpdateIfStatus", mock.Anything, disbursementId, disbursement.Status, mock.Anything).
+			Return(false, repoError).Once()
+		mockLoan.On("UpdateIfStatus", mock.Anything, disbursement.LoanId, models.LoanStatusDisbursementPending, map[string]any{"status": models.LoanStatusSanctioned}).
+			Return(true, nil).Once()
 
 		result, err := service.Retry(ctx, disbursementId)
 
@@ -1191,6 +2288,53 @@ func TestDisbursementService_Retry(t *testing.T) {
 		assert.Contains(t, err.Error(), "failed to update disbursement")
 
 		mockDisbursement.AssertExpectations(t)
+		mockLoan.AssertExpectations(t)
+	})
+
+	t.Run("leaves failed disbursement when its loan is held by another", func(t *testing.T) {
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+
+		paymentChan := make(chan string, 1)
+		service := NewDisbursementService(
+			setupMockDB(t),
+			mockIdGenerator,
+			mockLoan,
+			mockDisbursement,
+			mockTransaction,
+			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
+			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
+		)
+
+		disbursementId := "DISB-123456789012"
+		disbursement := schema.Disbursement{
+			Id:     disbursementId,
+			LoanId: "LOAN-123456789012",
+			Amount: 10000.0,
+			Status: models.DisbursementStatusFailed,
+		}
+
+		mockDisbursement.On("Get", mock.Anything, disbursementId).
+			Return(&disbursement, nil).Once()
+		mockLoan.On("Get", mock.Anything, disbursement.LoanId).
+			Return(&schema.Loan{Id: disbursement.LoanId, Status: models.LoanStatusDisbursementPending}, nil).Once()
+
+		result, err := service.Retry(ctx, disbursementId)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, models.INVALID_LOAN_STATUS_TRANSITION)
+		assert.Empty(t, paymentChan)
+		mockDisbursement.AssertNotCalled(t, "UpdateIfStatus")
 	})
 
 	t.Run("allows retry for initiated status", func(t *testing.T) {
@@ -1202,12 +2346,20 @@ func TestDisbursementService_Retry(t *testing.T) {
 
 		paymentChan := make(chan string, 1)
 		service := NewDisbursementService(
+			setupMockDB(t),
 			mockIdGenerator,
 			mockLoan,
 			mockDisbursement,
 			mockTransaction,
 			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
 			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
 		)
 
 		disbursementId := "DISB-123456789012"
@@ -1223,14 +2375,14 @@ func TestDisbursementService_Retry(t *testing.T) {
 			UpdatedAt:  time.Now().Add(-1 * time.Hour),
 		}
 
-		mockDisbursement.On("Get", ctx, disbursementId).
+		mockDisbursement.On("Get", mock.Anything, disbursementId).
 			Return(&disbursement, nil).Once()
-		mockDisbursement.On("Update", ctx, disbursementId, mock.MatchedBy(func(fields map[string]any) bool {
+		mockDisbursement.On("UpdateIfStatus", mock.Anything, disbursementId, disbursement.Status, mock.MatchedBy(func(fields map[string]any) bool {
 			return fields["status"] == string(models.DisbursementStatusInitiated) &&
 				fields["last_error"] == nil &&
 				fields["updated_at"] != nil
 		})).
-			Return(nil).
+			Return(true, nil).
 			Once()
 
 		result, err := service.Retry(ctx, disbursementId)
@@ -1247,15 +2399,24 @@ func TestDisbursementService_Retry(t *testing.T) {
 		mockDisbursement := new(db_test.MockDisbursementRepository)
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
 
 		paymentChan := make(chan string, 1)
 		service := NewDisbursementService(
+			setupMockDB(t),
 			mockIdGenerator,
 			mockLoan,
 			mockDisbursement,
 			mockTransaction,
 			mockBeneficiary,
+			mockDeadLetter,
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
 			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
 		)
 
 		disbursementId := "DISB-123456789012"
@@ -1271,16 +2432,23 @@ func TestDisbursementService_Retry(t *testing.T) {
 			UpdatedAt:  time.Now().Add(-30 * time.Minute),
 		}
 
-		mockDisbursement.On("Get", ctx, disbursementId).
+		mockDisbursement.On("Get", mock.Anything, disbursementId).
 			Return(&disbursement, nil).Once()
-		mockDisbursement.On("Update", ctx, disbursementId, mock.MatchedBy(func(fields map[string]any) bool {
+		mockLoan.On("Get", mock.Anything, disbursement.LoanId).
+			Return(&schema.Loan{Id: disbursement.LoanId, Status: models.LoanStatusSanctioned}, nil).Once()
+		mockLoan.On("UpdateIfStatus", mock.Anything, disbursement.LoanId, models.LoanStatusSanctioned, map[string]any{"status": models.LoanStatusDisbursementPending}).
+			Return(true, nil).Once()
+		mockDisbursement.On("UpdateIfStatus", mock.Anything, disbursementId, disbursement.Status, mock.MatchedBy(func(fields map[string]any) bool {
 			return fields["status"] == string(models.DisbursementStatusInitiated) &&
 				fields["last_error"] == nil &&
 				fields["updated_at"] != nil
 		})).
-			Return(nil).
+			Return(true, nil).
 			Once()
 
+		mockDeadLetter.On("UpdateIfStatus", mock.Anything, disbursementId, models.DeadLetterStatusOpen, mock.Anything).
+			Return(true, nil).Once()
+
 		result, err := service.Retry(ctx, disbursementId)
 
 		assert.NoError(t, err)
@@ -1291,6 +2459,226 @@ func TestDisbursementService_Retry(t *testing.T) {
 }
 
 // Helper function to create string pointer
+func TestDisbursementService_Cancel(t *testing.T) {
+	ctx := context.Background()
+	disbursementId := "DISB-123456789012"
+	loanId := "LOAN-123456789012"
+	scheduled := &schema.Disbursement{
+		Id:     disbursementId,
+		LoanId: loanId,
+		Status: models.DisbursementStatusScheduled,
+	}
+	matchCancel := mock.MatchedBy(func(fields map[string]any) bool {
+		return fields["status"] == models.DisbursementStatusCancelled
+	})
+
+	t.Run("cancels scheduled disbursement and reopens the loan", func(t *testing.T) {
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockWebhook := new(MockWebhookService)
+		paymentChan := make(chan string, 1)
+		bus := events.NewBus(events.DefaultHistorySize)
+		subscription, _, _, _ := bus.Subscribe(events.Filter{}, 0)
+
+		service := NewDisbursementService(
+			setupMockDB(t),
+			mockIdGenerator,
+			mockLoan,
+			mockDisbursement,
+			mockTransaction,
+			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			mockWebhook,
+			paymentChan,
+			nil,
+			nil,
+			bus,
+			nil,
+		)
+
+		mockDisbursement.On("Get", mock.Anything, disbursementId).Return(scheduled, nil).Once()
+		mockDisbursement.On("UpdateIfStatus", mock.Anything, disbursementId, models.DisbursementStatusScheduled, matchCancel).
+			Return(true, nil).Once()
+		mockLoan.On("Get", mock.Anything, loanId).Return(&schema.Loan{
+			Id:     loanId,
+			Status: models.LoanStatusDisbursementPending,
+		}, nil).Once()
+		mockLoan.On("UpdateIfStatus", mock.Anything, loanId, models.LoanStatusDisbursementPending, map[string]any{"status": models.LoanStatusSanctioned}).
+			Return(true, nil).Once()
+		mockWebhook.On("Publish", mock.Anything, models.WebhookEventCancelled, disbursementId).Return(nil).Once()
+
+		result, err := service.Cancel(ctx, disbursementId)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.DisbursementStatusCancelled, result.Status)
+		assert.Equal(t, models.DisbursementStatusCancelled, (<-subscription.Events()).Status)
+		mockDisbursement.AssertExpectations(t)
+		mockLoan.AssertExpectations(t)
+		mockWebhook.AssertExpectations(t)
+	})
+
+	t.Run("returns loan to partially disbursed for a later tranche", func(t *testing.T) {
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		paymentChan := make(chan string, 1)
+
+		service := NewDisbursementService(
+			setupMockDB(t),
+			mockIdGenerator,
+			mockLoan,
+			mockDisbursement,
+			mockTransaction,
+			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
+			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
+		)
+
+		mockDisbursement.On("Get", mock.Anything, disbursementId).Return(scheduled, nil).Once()
+		mockDisbursement.On("UpdateIfStatus", mock.Anything, disbursementId, models.DisbursementStatusScheduled, matchCancel).
+			Return(true, nil).Once()
+		mockLoan.On("Get", mock.Anything, loanId).Return(&schema.Loan{
+			Id:              loanId,
+			DisbursedAmount: 5000,
+			Status:          models.LoanStatusDisbursementPending,
+		}, nil).Once()
+		mockLoan.On("UpdateIfStatus", mock.Anything, loanId, models.LoanStatusDisbursementPending, map[string]any{"status": models.LoanStatusPartiallyDisbursed}).
+			Return(true, nil).Once()
+
+		_, err := service.Cancel(ctx, disbursementId)
+
+		assert.NoError(t, err)
+		mockLoan.AssertExpectations(t)
+	})
+
+	t.Run("returns error when disbursement is not scheduled", func(t *testing.T) {
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		paymentChan := make(chan string, 1)
+
+		service := NewDisbursementService(
+			setupMockDB(t),
+			mockIdGenerator,
+			mockLoan,
+			mockDisbursement,
+			mockTransaction,
+			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
+			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
+		)
+
+		mockDisbursement.On("Get", mock.Anything, disbursementId).Return(&schema.Disbursement{
+			Id:     disbursementId,
+			Status: models.DisbursementStatusProcessing,
+		}, nil).Once()
+
+		result, err := service.Cancel(ctx, disbursementId)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, models.DISBURSEMENT_NOT_CANCELLABLE)
+		mockDisbursement.AssertNotCalled(t, "UpdateIfStatus")
+	})
+
+	t.Run("returns error when released before the cancel lands", func(t *testing.T) {
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		paymentChan := make(chan string, 1)
+
+		service := NewDisbursementService(
+			setupMockDB(t),
+			mockIdGenerator,
+			mockLoan,
+			mockDisbursement,
+			mockTransaction,
+			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
+			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
+		)
+
+		mockDisbursement.On("Get", mock.Anything, disbursementId).Return(scheduled, nil).Once()
+		mockDisbursement.On("UpdateIfStatus", mock.Anything, disbursementId, models.DisbursementStatusScheduled, matchCancel).
+			Return(false, nil).Once()
+
+		result, err := service.Cancel(ctx, disbursementId)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, models.DISBURSEMENT_NOT_CANCELLABLE)
+		mockLoan.AssertNotCalled(t, "Update")
+	})
+
+	t.Run("returns error when disbursement not found", func(t *testing.T) {
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		paymentChan := make(chan string, 1)
+
+		service := NewDisbursementService(
+			setupMockDB(t),
+			mockIdGenerator,
+			mockLoan,
+			mockDisbursement,
+			mockTransaction,
+			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			NewNameMatchPolicy(nil),
+			newMockWebhookService(),
+			paymentChan,
+			nil,
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			nil,
+		)
+
+		mockDisbursement.On("Get", mock.Anything, disbursementId).Return(nil, gorm.ErrRecordNotFound).Once()
+
+		result, err := service.Cancel(ctx, disbursementId)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
+
 func stringPtr(s string) *string {
 	return &s
 }
+
+func TestQueuePayment(t *testing.T) {
+	ctx := context.Background()
+	paymentChan := make(chan string, 1)
+
+	assert.True(t, queuePayment(ctx, paymentChan, "DISB-1"))
+	assert.False(t, queuePayment(ctx, paymentChan, "DISB-2"), "a full queue must not block")
+	assert.Equal(t, "DISB-1", <-paymentChan)
+}
@@ -1,9 +1,13 @@
 package services
 
 import (
+	"loan-disbursement-service/config"
 	"loan-disbursement-service/db"
+	"loan-disbursement-service/events"
 	"loan-disbursement-service/providers"
 	"loan-disbursement-service/utils"
+	"shared/calendar"
+	"shared/ifsc"
 )
 
 type ServiceFactory struct {
@@ -11,40 +15,144 @@ type ServiceFactory struct {
 	paymentService PaymentService
 	disbursement   DisbursementService
 	loanService    LoanService
+	beneficiary    BeneficiaryService
+	schedule       ScheduleService
+	batch          BatchService
+	scheduler      SchedulerService
 	retryPolicy    RetryPolicy
+	directory      *ifsc.Directory
 	reconciliation ReconciliationService
+	admin          AdminService
+	deadLetter     DeadLetterService
+	webhook        WebhookService
+	bus            *events.Bus
 }
 
 func New(
 	database *db.Database,
 	idGenerator utils.IdGenerator,
 	paymentProvider providers.PaymentProvider,
+	originationProvider providers.OriginationProvider,
+	webhookProvider providers.WebhookProvider,
 	notificationURL string,
 	paymentChan chan string,
+	batchChan chan string,
+	calendar *calendar.Calendar,
+	directory *ifsc.Directory,
+	bus *events.Bus,
+	settings *config.Store,
 ) *ServiceFactory {
-	retryPolicy := NewRetryPolicy()
+	retryPolicy := NewRetryPolicy(settings)
+	schedule := NewScheduleService(
+		database.GetLoanRepository(),
+		database.GetInstallmentRepository(),
+	)
+	nameMatch := NewNameMatchPolicy(settings)
+	webhook := NewWebhookService(
+		idGenerator,
+		database.GetWebhookSubscriptionRepository(),
+		database.GetWebhookDeliveryRepository(),
+		database.GetDisbursementRepository(),
+		webhookProvider,
+	)
+	beneficiary := NewBeneficiaryService(
+		database.GetBeneficiaryRepository(),
+		database.GetLoanRepository(),
+		paymentProvider,
+		idGenerator,
+		directory,
+	)
+	disbursement := NewDisbursementService(
+		database,
+		idGenerator,
+		database.GetLoanRepository(),
+		database.GetDisbursementRepository(),
+		database.GetTransactionRepository(),
+		database.GetBeneficiaryRepository(),
+		database.GetDeadLetterRepository(),
+		nameMatch,
+		webhook,
+		paymentChan,
+		beneficiary,
+		directory,
+		bus,
+		settings,
+	)
+	paymentService := NewPaymentService(
+		database,
+		database.GetDisbursementRepository(),
+		database.GetTransactionRepository(),
+		database.GetLoanRepository(),
+		database.GetBeneficiaryRepository(),
+		database.GetDeadLetterRepository(),
+		webhook,
+		retryPolicy,
+		schedule,
+		paymentProvider,
+		idGenerator,
+		calendar,
+		directory,
+		bus,
+		notificationURL,
+		settings,
+	)
+	admin := NewAdminService(
+		database,
+		idGenerator,
+		database.GetDisbursementRepository(),
+		database.GetTransactionRepository(),
+		database.GetLoanRepository(),
+		database.GetBeneficiaryRepository(),
+		database.GetAuditRepository(),
+		database.GetDeadLetterRepository(),
+		database.GetBeneficiaryChangeRepository(),
+		paymentService,
+		beneficiary,
+		nameMatch,
+		webhook,
+		directory,
+		bus,
+		paymentChan,
+		settings,
+	)
 	return &ServiceFactory{
-		database:    database,
-		retryPolicy: retryPolicy,
-		disbursement: NewDisbursementService(
-			idGenerator,
-			database.GetLoanRepository(),
+		database:     database,
+		retryPolicy:  retryPolicy,
+		directory:    directory,
+		schedule:     schedule,
+		disbursement: disbursement,
+		scheduler: NewSchedulerService(
 			database.GetDisbursementRepository(),
-			database.GetTransactionRepository(),
-			database.GetBeneficiaryRepository(),
+			calendar,
+			bus,
 			paymentChan,
 		),
-		loanService: NewLoanService(database.GetLoanRepository(), idGenerator),
-		paymentService: NewPaymentService(
-			database,
+		batch: NewBatchService(
+			idGenerator,
+			database.GetBatchRepository(),
+			database.GetLoanRepository(),
 			database.GetDisbursementRepository(),
-			database.GetTransactionRepository(),
+			disbursement,
+			batchChan,
+		),
+		loanService: NewLoanService(
 			database.GetLoanRepository(),
 			database.GetBeneficiaryRepository(),
-			retryPolicy,
-			paymentProvider,
+			database.GetDisbursementRepository(),
+			idGenerator,
+		),
+		beneficiary:    beneficiary,
+		paymentService: paymentService,
+		admin:          admin,
+		webhook:        webhook,
+		bus:            bus,
+		deadLetter: NewDeadLetterService(
 			idGenerator,
-			notificationURL,
+			database.GetDeadLetterRepository(),
+			database.GetDisbursementRepository(),
+			database.GetAuditRepository(),
+			admin,
+			originationProvider,
 		),
 		reconciliation: NewReconciliationService(
 			idGenerator,
@@ -57,10 +165,26 @@ func (f *ServiceFactory) GetDisbursementService() DisbursementService {
 	return f.disbursement
 }
 
+func (f *ServiceFactory) GetBatchService() BatchService {
+	return f.batch
+}
+
+func (f *ServiceFactory) GetSchedulerService() SchedulerService {
+	return f.scheduler
+}
+
 func (f *ServiceFactory) GetLoanService() LoanService {
 	return f.loanService
 }
 
+func (f *ServiceFactory) GetBeneficiaryService() BeneficiaryService {
+	return f.beneficiary
+}
+
+func (f *ServiceFactory) GetScheduleService() ScheduleService {
+	return f.schedule
+}
+
 func (f *ServiceFactory) GetPaymentService() PaymentService {
 	return f.paymentService
 }
@@ -72,3 +196,23 @@ func (f *ServiceFactory) GetRetryPolicy() RetryPolicy {
 func (f *ServiceFactory) GetReconciliationService() ReconciliationService {
 	return f.reconciliation
 }
+
+func (f *ServiceFactory) GetAdminService() AdminService {
+	return f.admin
+}
+
+func (f *ServiceFactory) GetDeadLetterService() DeadLetterService {
+	return f.deadLetter
+}
+
+func (f *ServiceFactory) GetWebhookService() WebhookService {
+	return f.webhook
+}
+
+func (f *ServiceFactory) GetEventBus() *events.Bus {
+	return f.bus
+}
+
+func (f *ServiceFactory) GetIFSCDirectory() *ifsc.Directory {
+	return f.directory
+}
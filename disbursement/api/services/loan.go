@@ -2,42 +2,140 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"loan-disbursement-service/db/daos"
 	"loan-disbursement-service/db/schema"
 	"loan-disbursement-service/models"
 	"loan-disbursement-service/utils"
+	"shared/logging"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
 )
 
 type LoanService interface {
-	Create(ctx context.Context, amount float64) (*models.Loan, error)
+	Create(ctx context.Context, req models.LoanRequest) (*models.Loan, error)
 	Update(ctx context.Context, loanId string, fields map[string]any) (*models.Loan, error)
 	List(ctx context.Context) ([]models.Loan, error)
 	Get(ctx context.Context, loanId string) (*models.Loan, error)
+	LinkBeneficiary(ctx context.Context, loanId, beneficiaryId string) (*models.Loan, error)
+	UpdateStatus(ctx context.Context, loanId string, status models.LoanStatus) (*models.Loan, error)
 }
 
 type LoanServiceImpl struct {
-	loan        daos.LoanRepository
-	idGenerator utils.IdGenerator
+	loan         daos.LoanRepository
+	beneficiary  daos.BeneficiaryRepository
+	disbursement daos.DisbursementRepository
+	idGenerator  utils.IdGenerator
 }
 
-func NewLoanService(loan daos.LoanRepository, idGenerator utils.IdGenerator) LoanService {
-	return &LoanServiceImpl{loan: loan, idGenerator: idGenerator}
+func NewLoanService(
+	loan daos.LoanRepository,
+	beneficiary daos.BeneficiaryRepository,
+	disbursement daos.DisbursementRepository,
+	idGenerator utils.IdGenerator,
+) LoanService {
+	return &LoanServiceImpl{
+		loan:         loan,
+		beneficiary:  beneficiary,
+		disbursement: disbursement,
+		idGenerator:  idGenerator,
+	}
 }
 
-func (s LoanServiceImpl) Create(ctx context.Context, amount float64) (*models.Loan, error) {
-	loanId := s.idGenerator.GenerateLoanId()
-	loan, err := s.loan.Create(ctx, loanId, amount)
+func (s LoanServiceImpl) Create(ctx context.Context, req models.LoanRequest) (*models.Loan, error) {
+	if req.ProductType != "" && !req.ProductType.IsValid() {
+		return nil, fmt.Errorf("%w: %s", models.INVALID_PRODUCT_TYPE, req.ProductType)
+	}
+	sanctionDate := time.Now()
+	if req.SanctionDate != nil {
+		sanctionDate = *req.SanctionDate
+	}
+
+	interestRate := 0.0
+	if req.InterestRate != nil {
+		interestRate = *req.InterestRate
+	}
+	frequency, method := req.RepaymentFrequency, req.InterestMethod
+	if req.TenureMonths > 0 || req.InterestRate != nil || frequency != "" || method != "" {
+		if frequency == "" {
+			frequency = models.RepaymentFrequencyMonthly
+		}
+		if method == "" {
+			method = models.InterestMethodReducingBalance
+		}
+		if err := validateTerms(interestRate, req.TenureMonths, frequency, method); err != nil {
+			return nil, err
+		}
+	}
+
+	loan, err := s.loan.Create(ctx, schema.Loan{
+		Id:                 s.idGenerator.GenerateLoanId(),
+		Amount:             req.Amount,
+		BorrowerId:         req.BorrowerId,
+		BorrowerName:       req.BorrowerName,
+		ProductType:        req.ProductType,
+		SanctionDate:       sanctionDate,
+		InterestRate:       interestRate,
+		TenureMonths:       req.TenureMonths,
+		RepaymentFrequency: frequency,
+		InterestMethod:     method,
+		Status:             models.LoanStatusSanctioned,
+	})
 	if err != nil {
 		return nil, err
 	}
 	return s.toModel(loan), nil
 }
 
+// Update applies the given fields to the loan. The amount and repayment terms
+// are locked once any disbursement has been created against the loan,
+// whatever its outcome, and are only changed while the loan is still in the
+// status the check read.
 func (s *LoanServiceImpl) Update(
 	ctx context.Context,
 	loanId string,
 	fields map[string]any,
 ) (*models.Loan, error) {
+	if productType, ok := fields["product_type"].(models.ProductType); ok && !productType.IsValid() {
+		return nil, fmt.Errorf("%w: %s", models.INVALID_PRODUCT_TYPE, productType)
+	}
+	_, changesAmount := fields["amount"]
+	changesTerms := hasAny(fields, loanTermFields...)
+	if changesAmount || changesTerms {
+		existing, err := s.loan.Get(ctx, loanId)
+		if err != nil {
+			return nil, err
+		}
+		if changesTerms {
+			if err := validateTerms(mergeTerms(existing, fields)); err != nil {
+				return nil, err
+			}
+		}
+		if lockedFieldsChanged(existing, fields) {
+			disbursements, err := s.disbursement.ListByLoan(ctx, loanId)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list disbursements: %w", err)
+			}
+			if len(disbursements) > 0 {
+				return nil, models.LOAN_AMOUNT_LOCKED
+			}
+			// Only while the loan is still in the status it was read in; a
+			// disbursement created meanwhile reserves the loan, which moves
+			// it on, so the lock holds against it.
+			updated, err := s.loan.UpdateIfStatus(ctx, loanId, existing.Status, fields)
+			if err != nil {
+				return nil, fmt.Errorf("failed to update loan: %w", err)
+			}
+			if !updated {
+				return nil, fmt.Errorf("%w: loan is no longer %s", models.LOAN_STATUS_CHANGED, existing.Status)
+			}
+			return s.Get(ctx, loanId)
+		}
+	}
+
 	loan, err := s.loan.Update(ctx, loanId, fields)
 	if err != nil {
 		return nil, err
@@ -68,14 +166,253 @@ func (s *LoanServiceImpl) Get(ctx context.Context, loanId string) (*models.Loan,
 	return s.toModel(loan), nil
 }
 
+// LinkBeneficiary sets the payee of a loan open for disbursement. The
+// payment path reads the loan's beneficiary when it sends, so the payee is
+// locked while a disbursement is in flight or failed and awaiting a retry,
+// and once the loan is fully disbursed or closed. A failed disbursement's
+// payee is changed through the audited admin correction instead.
+func (s *LoanServiceImpl) LinkBeneficiary(
+	ctx context.Context,
+	loanId, beneficiaryId string,
+) (*models.Loan, error) {
+	loan, err := s.loan.Get(ctx, loanId)
+	if err != nil {
+		return nil, err
+	}
+	if !loan.Status.IsDisbursable() {
+		return nil, fmt.Errorf("loan %s is %s: %w", loan.Id, loan.Status, models.LOAN_BENEFICIARY_LOCKED)
+	}
+	latest, err := s.disbursement.GetByLoanId(ctx, loanId)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to check existing disbursement: %w", err)
+	}
+	if latest != nil &&
+		latest.Status != models.DisbursementStatusSuccess &&
+		latest.Status != models.DisbursementStatusCancelled {
+		return nil, fmt.Errorf("disbursement %s is %s: %w", latest.Id, latest.Status, models.LOAN_BENEFICIARY_LOCKED)
+	}
+
+	beneficiary, err := s.beneficiary.GetById(ctx, beneficiaryId)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPayable(beneficiary); err != nil {
+		return nil, err
+	}
+
+	// Only while the loan is still in the status it was read in, so a
+	// disbursement reserving it meanwhile keeps the payee it was checked for.
+	linked, err := s.loan.UpdateIfStatus(ctx, loanId, loan.Status, map[string]any{"beneficiary_id": beneficiary.Id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update loan: %w", err)
+	}
+	if !linked {
+		return nil, fmt.Errorf("%w: loan is no longer %s", models.LOAN_STATUS_CHANGED, loan.Status)
+	}
+	updated, err := s.loan.Get(ctx, loanId)
+	if err != nil {
+		return nil, err
+	}
+	return s.toModel(updated), nil
+}
+
+// UpdateStatus handles the manual lifecycle changes: cancelling a sanctioned
+// loan and closing a disbursed one. The other statuses follow disbursement
+// outcomes and cannot be set directly.
+func (s *LoanServiceImpl) UpdateStatus(
+	ctx context.Context,
+	loanId string,
+	status models.LoanStatus,
+) (*models.Loan, error) {
+	if status != models.LoanStatusCancelled && status != models.LoanStatusClosed {
+		return nil, fmt.Errorf("%w: %s cannot be set manually", models.INVALID_LOAN_STATUS_TRANSITION, status)
+	}
+
+	loan, err := s.loan.Get(ctx, loanId)
+	if err != nil {
+		return nil, err
+	}
+	if err := transitionLoan(ctx, s.loan, loan, status, nil); err != nil {
+		return nil, err
+	}
+	updated, err := s.loan.Get(ctx, loanId)
+	if err != nil {
+		return nil, err
+	}
+	return s.toModel(updated), nil
+}
+
 func (s *LoanServiceImpl) toModel(loan *schema.Loan) *models.Loan {
 	if loan == nil {
 		return nil
 	}
 	return &models.Loan{
-		Id:        loan.Id,
-		Amount:    loan.Amount,
-		CreatedAt: loan.CreatedAt,
-		UpdatedAt: loan.UpdatedAt,
+		Id:                 loan.Id,
+		Amount:             loan.Amount,
+		DisbursedAmount:    loan.DisbursedAmount,
+		BorrowerId:         loan.BorrowerId,
+		BorrowerName:       loan.BorrowerName,
+		ProductType:        loan.ProductType,
+		SanctionDate:       loan.SanctionDate,
+		InterestRate:       loan.InterestRate,
+		TenureMonths:       loan.TenureMonths,
+		RepaymentFrequency: loan.RepaymentFrequency,
+		InterestMethod:     loan.InterestMethod,
+		Status:             loan.Status,
+		BeneficiaryId:      loan.BeneficiaryId,
+		CreatedAt:          loan.CreatedAt,
+		UpdatedAt:          loan.UpdatedAt,
+	}
+}
+
+var loanTermFields = []string{
+	"interest_rate",
+	"tenure_months",
+	"repayment_frequency",
+	"interest_method",
+}
+
+func hasAny(fields map[string]any, keys ...string) bool {
+	for _, key := range keys {
+		if _, ok := fields[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func lockedFieldsChanged(loan *schema.Loan, fields map[string]any) bool {
+	current := map[string]any{
+		"amount":              loan.Amount,
+		"interest_rate":       loan.InterestRate,
+		"tenure_months":       loan.TenureMonths,
+		"repayment_frequency": loan.RepaymentFrequency,
+		"interest_method":     loan.InterestMethod,
+	}
+	for key, value := range current {
+		if updated, ok := fields[key]; ok && updated != value {
+			return true
+		}
+	}
+	return false
+}
+
+func mergeTerms(
+	loan *schema.Loan,
+	fields map[string]any,
+) (float64, int, models.RepaymentFrequency, models.InterestMethod) {
+	rate, tenure := loan.InterestRate, loan.TenureMonths
+	frequency, method := loan.RepaymentFrequency, loan.InterestMethod
+	if value, ok := fields["interest_rate"].(float64); ok {
+		rate = value
+	}
+	if value, ok := fields["tenure_months"].(int); ok {
+		tenure = value
+	}
+	if value, ok := fields["repayment_frequency"].(models.RepaymentFrequency); ok {
+		frequency = value
+	}
+	if value, ok := fields["interest_method"].(models.InterestMethod); ok {
+		method = value
+	}
+	return rate, tenure, frequency, method
+}
+
+func validateTerms(
+	rate float64,
+	tenureMonths int,
+	frequency models.RepaymentFrequency,
+	method models.InterestMethod,
+) error {
+	period := frequency.PeriodMonths()
+	if rate < 0 || tenureMonths <= 0 || period == 0 || tenureMonths%period != 0 || !method.IsValid() {
+		return fmt.Errorf(
+			"%w: interest_rate=%.2f tenure_months=%d repayment_frequency=%q interest_method=%q",
+			models.INVALID_LOAN_TERMS,
+			rate,
+			tenureMonths,
+			frequency,
+			method,
+		)
+	}
+	return nil
+}
+
+// transitionLoan moves the loan to the next status, together with any extra
+// fields, after checking the move is allowed from its current status. The
+// loan only moves from the status it was read in, so of two callers racing
+// on it only one wins; the other gets LOAN_STATUS_CHANGED.
+func transitionLoan(
+	ctx context.Context,
+	loans daos.LoanRepository,
+	loan *schema.Loan,
+	next models.LoanStatus,
+	fields map[string]any,
+) error {
+	if !loan.Status.CanTransitionTo(next) {
+		return fmt.Errorf(
+			"%w: %s to %s",
+			models.INVALID_LOAN_STATUS_TRANSITION,
+			loan.Status,
+			next,
+		)
+	}
+	if fields == nil {
+		fields = map[string]any{}
+	}
+	fields["status"] = next
+
+	moved, err := loans.UpdateIfStatus(ctx, loan.Id, loan.Status, fields)
+	if err != nil {
+		return fmt.Errorf("failed to update loan status: %w", err)
+	}
+	if !moved {
+		return fmt.Errorf("%w: loan is no longer %s", models.LOAN_STATUS_CHANGED, loan.Status)
+	}
+	ctx = logging.With(ctx, logging.Fields{LoanId: loan.Id})
+	log.Ctx(ctx).Info().
+		Str("from", string(loan.Status)).
+		Str("to", string(next)).
+		Msg("loan status changed")
+	return nil
+}
+
+// releaseLoan reopens the loan for disbursement after a disbursement fails
+// for good or is cancelled.
+func releaseLoan(ctx context.Context, loans daos.LoanRepository, loanId string) error {
+	loan, err := loans.Get(ctx, loanId)
+	if err != nil {
+		return fmt.Errorf("failed to get loan: %w", err)
+	}
+
+	next := models.LoanStatusSanctioned
+	if loan.DisbursedAmount > 0 {
+		next = models.LoanStatusPartiallyDisbursed
+	}
+	return transitionLoan(ctx, loans, loan, next, nil)
+}
+
+// reserveLoan reopens the loan a failed disbursement released, so the
+// disbursement can be sent again or settled. It returns the loan as it was
+// before, for unreserveLoan.
+func reserveLoan(ctx context.Context, loans daos.LoanRepository, loanId string) (*schema.Loan, error) {
+	loan, err := loans.Get(ctx, loanId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loan: %w", err)
+	}
+	if err := transitionLoan(ctx, loans, loan, models.LoanStatusDisbursementPending, nil); err != nil {
+		return nil, err
+	}
+	return loan, nil
+}
+
+// unreserveLoan puts a loan reserved for a disbursement back in the status
+// it was read in, when the disbursement could not be written after all. A
+// failure is only logged, since the caller is already returning an error.
+func unreserveLoan(ctx context.Context, loans daos.LoanRepository, loan *schema.Loan) {
+	reserved := *loan
+	reserved.Status = models.LoanStatusDisbursementPending
+	if err := transitionLoan(ctx, loans, &reserved, loan.Status, nil); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to release loan")
 	}
 }
@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"loan-disbursement-service/db/schema"
+	"loan-disbursement-service/models"
 	db_test "loan-disbursement-service/test/db"
 	utils_test "loan-disbursement-service/test/utils"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"gorm.io/gorm"
 )
 
@@ -18,30 +20,37 @@ func TestLoanService_Create(t *testing.T) {
 
 	t.Run("successfully creates loan", func(t *testing.T) {
 		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
-		service := NewLoanService(mockLoan, mockIdGenerator)
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
 
 		amount := 10000.0
 		loanId := "LOAN-123456789012"
 
 		expectedLoan := schema.Loan{
-			Id:        loanId,
-			Amount:    amount,
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
+			Id:           loanId,
+			Amount:       amount,
+			BorrowerName: "Ravi Kumar",
+			CreatedAt:    time.Now(),
+			UpdatedAt:    time.Now(),
 		}
 
 		mockIdGenerator.On("GenerateLoanId").Return(loanId).Once()
-		mockLoan.On("Create", ctx, loanId, amount).
+		mockLoan.On("Create", ctx, matchLoan(loanId, amount, "Ravi Kumar")).
 			Return(&expectedLoan, nil).Once()
 
-		result, err := service.Create(ctx, amount)
+		result, err := service.Create(ctx, models.LoanRequest{
+			Amount:       amount,
+			BorrowerName: "Ravi Kumar",
+		})
 
 		assert.NoError(t, err)
 		assert.NotNil(t, result)
 		assert.Equal(t, loanId, result.Id)
 		assert.Equal(t, amount, result.Amount)
+		assert.Equal(t, "Ravi Kumar", result.BorrowerName)
 		assert.False(t, result.CreatedAt.IsZero())
 		assert.False(t, result.UpdatedAt.IsZero())
 
@@ -51,19 +60,21 @@ func TestLoanService_Create(t *testing.T) {
 
 	t.Run("returns error when repository create fails", func(t *testing.T) {
 		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
-		service := NewLoanService(mockLoan, mockIdGenerator)
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
 
 		amount := 10000.0
 		loanId := "LOAN-123456789012"
 		repoError := errors.New("database error")
 
 		mockIdGenerator.On("GenerateLoanId").Return(loanId).Once()
-		mockLoan.On("Create", ctx, loanId, amount).
+		mockLoan.On("Create", ctx, matchLoan(loanId, amount, "")).
 			Return(nil, repoError).Once()
 
-		result, err := service.Create(ctx, amount)
+		result, err := service.Create(ctx, models.LoanRequest{Amount: amount})
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
@@ -75,9 +86,11 @@ func TestLoanService_Create(t *testing.T) {
 
 	t.Run("creates loan with zero amount", func(t *testing.T) {
 		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
-		service := NewLoanService(mockLoan, mockIdGenerator)
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
 
 		amount := 0.0
 		loanId := "LOAN-000000000000"
@@ -90,10 +103,10 @@ func TestLoanService_Create(t *testing.T) {
 		}
 
 		mockIdGenerator.On("GenerateLoanId").Return(loanId).Once()
-		mockLoan.On("Create", ctx, loanId, amount).
+		mockLoan.On("Create", ctx, matchLoan(loanId, amount, "")).
 			Return(&expectedLoan, nil).Once()
 
-		result, err := service.Create(ctx, amount)
+		result, err := service.Create(ctx, models.LoanRequest{Amount: amount})
 
 		assert.NoError(t, err)
 		assert.NotNil(t, result)
@@ -105,9 +118,11 @@ func TestLoanService_Create(t *testing.T) {
 
 	t.Run("creates loan with large amount", func(t *testing.T) {
 		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
-		service := NewLoanService(mockLoan, mockIdGenerator)
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
 
 		amount := 1000000.0
 		loanId := "LOAN-999999999999"
@@ -120,10 +135,10 @@ func TestLoanService_Create(t *testing.T) {
 		}
 
 		mockIdGenerator.On("GenerateLoanId").Return(loanId).Once()
-		mockLoan.On("Create", ctx, loanId, amount).
+		mockLoan.On("Create", ctx, matchLoan(loanId, amount, "")).
 			Return(&expectedLoan, nil).Once()
 
-		result, err := service.Create(ctx, amount)
+		result, err := service.Create(ctx, models.LoanRequest{Amount: amount})
 
 		assert.NoError(t, err)
 		assert.NotNil(t, result)
@@ -132,6 +147,118 @@ func TestLoanService_Create(t *testing.T) {
 		mockLoan.AssertExpectations(t)
 		mockIdGenerator.AssertExpectations(t)
 	})
+
+	t.Run("creates sanctioned loan with borrower details", func(t *testing.T) {
+		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
+
+		loanId := "LOAN-123456789012"
+		sanctionDate := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+		mockIdGenerator.On("GenerateLoanId").Return(loanId).Once()
+		mockLoan.On("Create", ctx, mock.MatchedBy(func(loan schema.Loan) bool {
+			return loan.BorrowerId == "BRW-001" &&
+				loan.ProductType == models.ProductTypeBusiness &&
+				loan.SanctionDate.Equal(sanctionDate) &&
+				loan.Status == models.LoanStatusSanctioned
+		})).Return(&schema.Loan{
+			Id:           loanId,
+			Amount:       50000.0,
+			BorrowerId:   "BRW-001",
+			ProductType:  models.ProductTypeBusiness,
+			SanctionDate: sanctionDate,
+			Status:       models.LoanStatusSanctioned,
+		}, nil).Once()
+
+		result, err := service.Create(ctx, models.LoanRequest{
+			Amount:       50000.0,
+			BorrowerId:   "BRW-001",
+			ProductType:  models.ProductTypeBusiness,
+			SanctionDate: &sanctionDate,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.LoanStatusSanctioned, result.Status)
+		assert.Equal(t, "BRW-001", result.BorrowerId)
+		mockLoan.AssertExpectations(t)
+	})
+
+	t.Run("rejects unknown product type", func(t *testing.T) {
+		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
+
+		result, err := service.Create(ctx, models.LoanRequest{
+			Amount:      50000.0,
+			ProductType: "crypto",
+		})
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, models.INVALID_PRODUCT_TYPE)
+		mockLoan.AssertNotCalled(t, "Create")
+	})
+
+	t.Run("defaults repayment terms to monthly reducing balance", func(t *testing.T) {
+		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
+
+		rate := 11.5
+		mockIdGenerator.On("GenerateLoanId").Return("LOAN-123456789012").Once()
+		mockLoan.On("Create", ctx, mock.MatchedBy(func(loan schema.Loan) bool {
+			return loan.InterestRate == rate &&
+				loan.TenureMonths == 24 &&
+				loan.RepaymentFrequency == models.RepaymentFrequencyMonthly &&
+				loan.InterestMethod == models.InterestMethodReducingBalance
+		})).Return(&schema.Loan{Id: "LOAN-123456789012"}, nil).Once()
+
+		_, err := service.Create(ctx, models.LoanRequest{
+			Amount:       50000.0,
+			InterestRate: &rate,
+			TenureMonths: 24,
+		})
+
+		assert.NoError(t, err)
+		mockLoan.AssertExpectations(t)
+	})
+
+	t.Run("rejects tenure that is not a whole number of periods", func(t *testing.T) {
+		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
+
+		result, err := service.Create(ctx, models.LoanRequest{
+			Amount:             50000.0,
+			TenureMonths:       10,
+			RepaymentFrequency: models.RepaymentFrequencyQuarterly,
+		})
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, models.INVALID_LOAN_TERMS)
+		mockLoan.AssertNotCalled(t, "Create")
+	})
+}
+
+func matchLoan(id string, amount float64, borrowerName string) any {
+	return mock.MatchedBy(func(loan schema.Loan) bool {
+		return loan.Id == id &&
+			loan.Amount == amount &&
+			loan.BorrowerName == borrowerName &&
+			loan.Status == models.LoanStatusSanctioned
+	})
 }
 
 func TestLoanService_Update(t *testing.T) {
@@ -139,9 +266,11 @@ func TestLoanService_Update(t *testing.T) {
 
 	t.Run("successfully updates loan", func(t *testing.T) {
 		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
-		service := NewLoanService(mockLoan, mockIdGenerator)
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
 
 		loanId := "LOAN-123456789012"
 		fields := map[string]any{
@@ -155,7 +284,13 @@ func TestLoanService_Update(t *testing.T) {
 			UpdatedAt: time.Now(),
 		}
 
-		mockLoan.On("Update", ctx, loanId, fields).
+		mockLoan.On("Get", ctx, loanId).
+			Return(&schema.Loan{Id: loanId, Amount: 10000.0, Status: models.LoanStatusSanctioned}, nil).Once()
+		mockDisbursement.On("ListByLoan", ctx, loanId).
+			Return([]schema.Disbursement{}, nil).Once()
+		mockLoan.On("UpdateIfStatus", ctx, loanId, models.LoanStatusSanctioned, fields).
+			Return(true, nil).Once()
+		mockLoan.On("Get", ctx, loanId).
 			Return(&updatedLoan, nil).Once()
 
 		result, err := service.Update(ctx, loanId, fields)
@@ -170,9 +305,11 @@ func TestLoanService_Update(t *testing.T) {
 
 	t.Run("returns error when repository update fails", func(t *testing.T) {
 		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
-		service := NewLoanService(mockLoan, mockIdGenerator)
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
 
 		loanId := "LOAN-123456789012"
 		fields := map[string]any{
@@ -180,23 +317,28 @@ func TestLoanService_Update(t *testing.T) {
 		}
 		repoError := errors.New("database error")
 
-		mockLoan.On("Update", ctx, loanId, fields).
-			Return(nil, repoError).Once()
+		mockLoan.On("Get", ctx, loanId).
+			Return(&schema.Loan{Id: loanId, Amount: 10000.0, Status: models.LoanStatusSanctioned}, nil).Once()
+		mockDisbursement.On("ListByLoan", ctx, loanId).
+			Return([]schema.Disbursement{}, nil).Once()
+		mockLoan.On("UpdateIfStatus", ctx, loanId, models.LoanStatusSanctioned, fields).
+			Return(false, repoError).Once()
 
 		result, err := service.Update(ctx, loanId, fields)
 
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, repoError)
 		assert.Nil(t, result)
-		assert.Equal(t, repoError, err)
 
 		mockLoan.AssertExpectations(t)
 	})
 
 	t.Run("returns error when repository get fails after update", func(t *testing.T) {
 		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
-		service := NewLoanService(mockLoan, mockIdGenerator)
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
 
 		loanId := "LOAN-123456789012"
 		fields := map[string]any{
@@ -204,7 +346,13 @@ func TestLoanService_Update(t *testing.T) {
 		}
 		repoError := errors.New("database error")
 
-		mockLoan.On("Update", ctx, loanId, fields).
+		mockLoan.On("Get", ctx, loanId).
+			Return(&schema.Loan{Id: loanId, Amount: 10000.0, Status: models.LoanStatusSanctioned}, nil).Once()
+		mockDisbursement.On("ListByLoan", ctx, loanId).
+			Return([]schema.Disbursement{}, nil).Once()
+		mockLoan.On("UpdateIfStatus", ctx, loanId, models.LoanStatusSanctioned, fields).
+			Return(true, nil).Once()
+		mockLoan.On("Get", ctx, loanId).
 			Return(nil, repoError).Once()
 
 		result, err := service.Update(ctx, loanId, fields)
@@ -218,9 +366,11 @@ func TestLoanService_Update(t *testing.T) {
 
 	t.Run("updates loan with multiple fields", func(t *testing.T) {
 		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
-		service := NewLoanService(mockLoan, mockIdGenerator)
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
 
 		loanId := "LOAN-123456789012"
 		beneficiaryId := "BEN-987654321098"
@@ -237,7 +387,13 @@ func TestLoanService_Update(t *testing.T) {
 			UpdatedAt:     time.Now(),
 		}
 
-		mockLoan.On("Update", ctx, loanId, fields).
+		mockLoan.On("Get", ctx, loanId).
+			Return(&schema.Loan{Id: loanId, Amount: 10000.0, Status: models.LoanStatusSanctioned}, nil).Once()
+		mockDisbursement.On("ListByLoan", ctx, loanId).
+			Return([]schema.Disbursement{}, nil).Once()
+		mockLoan.On("UpdateIfStatus", ctx, loanId, models.LoanStatusSanctioned, fields).
+			Return(true, nil).Once()
+		mockLoan.On("Get", ctx, loanId).
 			Return(&updatedLoan, nil).Once()
 
 		result, err := service.Update(ctx, loanId, fields)
@@ -252,9 +408,11 @@ func TestLoanService_Update(t *testing.T) {
 
 	t.Run("updates loan with empty fields map", func(t *testing.T) {
 		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
-		service := NewLoanService(mockLoan, mockIdGenerator)
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
 
 		loanId := "LOAN-123456789012"
 		fields := map[string]any{}
@@ -276,6 +434,106 @@ func TestLoanService_Update(t *testing.T) {
 
 		mockLoan.AssertExpectations(t)
 	})
+
+	t.Run("refuses amount change once a disbursement exists", func(t *testing.T) {
+		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
+
+		loanId := "LOAN-123456789012"
+		fields := map[string]any{"amount": 15000.0}
+
+		mockLoan.On("Get", ctx, loanId).
+			Return(&schema.Loan{Id: loanId, Amount: 10000.0}, nil).Once()
+		mockDisbursement.On("ListByLoan", ctx, loanId).
+			Return([]schema.Disbursement{{Id: "DISB-001", LoanId: loanId}}, nil).Once()
+
+		result, err := service.Update(ctx, loanId, fields)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, models.LOAN_AMOUNT_LOCKED)
+		mockLoan.AssertNotCalled(t, "Update")
+	})
+
+	t.Run("allows unchanged amount once a disbursement exists", func(t *testing.T) {
+		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
+
+		loanId := "LOAN-123456789012"
+		fields := map[string]any{"amount": 10000.0, "borrower_name": "Ravi Kumar"}
+
+		mockLoan.On("Get", ctx, loanId).
+			Return(&schema.Loan{Id: loanId, Amount: 10000.0}, nil).Once()
+		mockLoan.On("Update", ctx, loanId, fields).
+			Return(&schema.Loan{Id: loanId, Amount: 10000.0, BorrowerName: "Ravi Kumar"}, nil).Once()
+
+		result, err := service.Update(ctx, loanId, fields)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Ravi Kumar", result.BorrowerName)
+		mockDisbursement.AssertNotCalled(t, "ListByLoan")
+	})
+
+	t.Run("refuses amount change when a disbursement reserved the loan meanwhile", func(t *testing.T) {
+		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
+
+		loanId := "LOAN-123456789012"
+		fields := map[string]any{"amount": 15000.0}
+
+		mockLoan.On("Get", ctx, loanId).
+			Return(&schema.Loan{Id: loanId, Amount: 10000.0, Status: models.LoanStatusSanctioned}, nil).Once()
+		mockDisbursement.On("ListByLoan", ctx, loanId).
+			Return([]schema.Disbursement{}, nil).Once()
+		mockLoan.On("UpdateIfStatus", ctx, loanId, models.LoanStatusSanctioned, fields).
+			Return(false, nil).Once()
+
+		result, err := service.Update(ctx, loanId, fields)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, models.LOAN_STATUS_CHANGED)
+		mockLoan.AssertNotCalled(t, "Update")
+	})
+
+	t.Run("refuses term change once a disbursement exists", func(t *testing.T) {
+		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
+
+		loanId := "LOAN-123456789012"
+		fields := map[string]any{"tenure_months": 36}
+
+		mockLoan.On("Get", ctx, loanId).Return(&schema.Loan{
+			Id:                 loanId,
+			Amount:             10000.0,
+			InterestRate:       12,
+			TenureMonths:       24,
+			RepaymentFrequency: models.RepaymentFrequencyMonthly,
+			InterestMethod:     models.InterestMethodFlat,
+		}, nil).Once()
+		mockDisbursement.On("ListByLoan", ctx, loanId).
+			Return([]schema.Disbursement{{Id: "DISB-001", LoanId: loanId}}, nil).Once()
+
+		result, err := service.Update(ctx, loanId, fields)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, models.LOAN_AMOUNT_LOCKED)
+		mockLoan.AssertNotCalled(t, "Update")
+	})
 }
 
 func TestLoanService_List(t *testing.T) {
@@ -283,9 +541,11 @@ func TestLoanService_List(t *testing.T) {
 
 	t.Run("successfully lists loans", func(t *testing.T) {
 		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
-		service := NewLoanService(mockLoan, mockIdGenerator)
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
 
 		loans := []schema.Loan{
 			{
@@ -328,9 +588,11 @@ func TestLoanService_List(t *testing.T) {
 
 	t.Run("returns empty list when no loans exist", func(t *testing.T) {
 		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
-		service := NewLoanService(mockLoan, mockIdGenerator)
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
 
 		loans := []schema.Loan{}
 
@@ -348,9 +610,11 @@ func TestLoanService_List(t *testing.T) {
 
 	t.Run("returns error when repository list fails", func(t *testing.T) {
 		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
-		service := NewLoanService(mockLoan, mockIdGenerator)
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
 
 		repoError := errors.New("database connection error")
 
@@ -368,9 +632,11 @@ func TestLoanService_List(t *testing.T) {
 
 	t.Run("filters out nil loans in list", func(t *testing.T) {
 		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
-		service := NewLoanService(mockLoan, mockIdGenerator)
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
 
 		// Note: The toModel method returns nil if loan is nil,
 		// so we test that nil loans are filtered out
@@ -401,9 +667,11 @@ func TestLoanService_Get(t *testing.T) {
 
 	t.Run("successfully retrieves loan", func(t *testing.T) {
 		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
-		service := NewLoanService(mockLoan, mockIdGenerator)
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
 
 		loanId := "LOAN-123456789012"
 		loan := schema.Loan{
@@ -430,9 +698,11 @@ func TestLoanService_Get(t *testing.T) {
 
 	t.Run("returns error when loan not found", func(t *testing.T) {
 		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
-		service := NewLoanService(mockLoan, mockIdGenerator)
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
 
 		loanId := "LOAN-NONEXISTENT"
 
@@ -450,9 +720,11 @@ func TestLoanService_Get(t *testing.T) {
 
 	t.Run("returns error when repository get fails", func(t *testing.T) {
 		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
-		service := NewLoanService(mockLoan, mockIdGenerator)
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
 
 		loanId := "LOAN-123456789012"
 		repoError := errors.New("database connection error")
@@ -471,9 +743,11 @@ func TestLoanService_Get(t *testing.T) {
 
 	t.Run("retrieves loan with beneficiary", func(t *testing.T) {
 		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
-		service := NewLoanService(mockLoan, mockIdGenerator)
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
 
 		loanId := "LOAN-123456789012"
 		beneficiaryId := "BEN-987654321098"
@@ -498,3 +772,251 @@ func TestLoanService_Get(t *testing.T) {
 		mockLoan.AssertExpectations(t)
 	})
 }
+
+func TestLoanService_LinkBeneficiary(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("links verified beneficiary to loan", func(t *testing.T) {
+		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
+
+		loanId := "LOAN-123456789012"
+		beneficiaryId := "BEN-987654321098"
+
+		mockLoan.On("Get", ctx, loanId).
+			Return(&schema.Loan{Id: loanId, Status: models.LoanStatusSanctioned}, nil).Once()
+		mockDisbursement.On("GetByLoanId", ctx, loanId).Return(nil, gorm.ErrRecordNotFound).Once()
+		mockBeneficiary.On("GetById", ctx, beneficiaryId).
+			Return(verifiedBeneficiary(beneficiaryId), nil).
+			Once()
+		mockLoan.On("UpdateIfStatus", ctx, loanId, models.LoanStatusSanctioned, map[string]any{"beneficiary_id": beneficiaryId}).
+			Return(true, nil).
+			Once()
+		mockLoan.On("Get", ctx, loanId).
+			Return(&schema.Loan{Id: loanId, Status: models.LoanStatusSanctioned, BeneficiaryId: &beneficiaryId}, nil).
+			Once()
+
+		result, err := service.LinkBeneficiary(ctx, loanId, beneficiaryId)
+
+		assert.NoError(t, err)
+		assert.Equal(t, beneficiaryId, *result.BeneficiaryId)
+		mockLoan.AssertExpectations(t)
+		mockBeneficiary.AssertExpectations(t)
+	})
+
+	t.Run("refuses to link unverified beneficiary", func(t *testing.T) {
+		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
+
+		loanId := "LOAN-123456789012"
+		beneficiaryId := "BEN-987654321098"
+		beneficiary := verifiedBeneficiary(beneficiaryId)
+		beneficiary.Status = models.BeneficiaryStatusUnverified
+
+		mockLoan.On("Get", ctx, loanId).
+			Return(&schema.Loan{Id: loanId, Status: models.LoanStatusSanctioned}, nil).Once()
+		mockDisbursement.On("GetByLoanId", ctx, loanId).Return(nil, gorm.ErrRecordNotFound).Once()
+		mockBeneficiary.On("GetById", ctx, beneficiaryId).Return(beneficiary, nil).Once()
+
+		result, err := service.LinkBeneficiary(ctx, loanId, beneficiaryId)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, models.BENEFICIARY_NOT_VERIFIED)
+		mockLoan.AssertNotCalled(t, "UpdateIfStatus")
+	})
+
+	t.Run("refuses while a disbursement holds the loan", func(t *testing.T) {
+		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
+
+		loanId := "LOAN-123456789012"
+		mockLoan.On("Get", ctx, loanId).
+			Return(&schema.Loan{Id: loanId, Status: models.LoanStatusDisbursementPending}, nil).Once()
+
+		result, err := service.LinkBeneficiary(ctx, loanId, "BEN-987654321098")
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, models.LOAN_BENEFICIARY_LOCKED)
+		mockBeneficiary.AssertNotCalled(t, "GetById")
+		mockLoan.AssertNotCalled(t, "UpdateIfStatus")
+	})
+
+	t.Run("refuses while the latest disbursement failed", func(t *testing.T) {
+		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
+
+		loanId := "LOAN-123456789012"
+		mockLoan.On("Get", ctx, loanId).
+			Return(&schema.Loan{Id: loanId, Status: models.LoanStatusSanctioned}, nil).Once()
+		mockDisbursement.On("GetByLoanId", ctx, loanId).
+			Return(&schema.Disbursement{Id: "DISB-123", Status: models.DisbursementStatusFailed}, nil).Once()
+
+		result, err := service.LinkBeneficiary(ctx, loanId, "BEN-987654321098")
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, models.LOAN_BENEFICIARY_LOCKED)
+		mockLoan.AssertNotCalled(t, "UpdateIfStatus")
+	})
+
+	t.Run("refuses when a disbursement reserved the loan meanwhile", func(t *testing.T) {
+		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
+
+		loanId := "LOAN-123456789012"
+		beneficiaryId := "BEN-987654321098"
+		mockLoan.On("Get", ctx, loanId).
+			Return(&schema.Loan{Id: loanId, Status: models.LoanStatusPartiallyDisbursed}, nil).Once()
+		mockDisbursement.On("GetByLoanId", ctx, loanId).
+			Return(&schema.Disbursement{Id: "DISB-123", Status: models.DisbursementStatusSuccess}, nil).Once()
+		mockBeneficiary.On("GetById", ctx, beneficiaryId).
+			Return(verifiedBeneficiary(beneficiaryId), nil).Once()
+		mockLoan.On("UpdateIfStatus", ctx, loanId, models.LoanStatusPartiallyDisbursed, map[string]any{"beneficiary_id": beneficiaryId}).
+			Return(false, nil).Once()
+
+		result, err := service.LinkBeneficiary(ctx, loanId, beneficiaryId)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, models.LOAN_STATUS_CHANGED)
+	})
+
+	t.Run("returns error when loan not found", func(t *testing.T) {
+		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
+
+		mockLoan.On("Get", ctx, "LOAN-404").Return(nil, gorm.ErrRecordNotFound).Once()
+
+		result, err := service.LinkBeneficiary(ctx, "LOAN-404", "BEN-123")
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		mockBeneficiary.AssertNotCalled(t, "GetById")
+	})
+}
+
+func TestLoanService_UpdateStatus(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("cancels sanctioned loan", func(t *testing.T) {
+		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
+
+		loanId := "LOAN-123456789012"
+		mockLoan.On("Get", ctx, loanId).
+			Return(&schema.Loan{Id: loanId, Status: models.LoanStatusSanctioned}, nil).Once()
+		mockLoan.On("UpdateIfStatus", ctx, loanId, models.LoanStatusSanctioned, map[string]any{"status": models.LoanStatusCancelled}).
+			Return(true, nil).Once()
+		mockLoan.On("Get", ctx, loanId).
+			Return(&schema.Loan{Id: loanId, Status: models.LoanStatusCancelled}, nil).Once()
+
+		result, err := service.UpdateStatus(ctx, loanId, models.LoanStatusCancelled)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.LoanStatusCancelled, result.Status)
+		mockLoan.AssertExpectations(t)
+	})
+
+	t.Run("closes disbursed loan", func(t *testing.T) {
+		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
+
+		loanId := "LOAN-123456789012"
+		mockLoan.On("Get", ctx, loanId).
+			Return(&schema.Loan{Id: loanId, Status: models.LoanStatusDisbursed}, nil).Once()
+		mockLoan.On("UpdateIfStatus", ctx, loanId, models.LoanStatusDisbursed, map[string]any{"status": models.LoanStatusClosed}).
+			Return(true, nil).Once()
+		mockLoan.On("Get", ctx, loanId).
+			Return(&schema.Loan{Id: loanId, Status: models.LoanStatusClosed}, nil).Once()
+
+		result, err := service.UpdateStatus(ctx, loanId, models.LoanStatusClosed)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.LoanStatusClosed, result.Status)
+	})
+
+	t.Run("refuses when the loan moved after it was read", func(t *testing.T) {
+		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
+
+		loanId := "LOAN-123456789012"
+		mockLoan.On("Get", ctx, loanId).
+			Return(&schema.Loan{Id: loanId, Status: models.LoanStatusSanctioned}, nil).Once()
+		mockLoan.On("UpdateIfStatus", ctx, loanId, models.LoanStatusSanctioned, map[string]any{"status": models.LoanStatusCancelled}).
+			Return(false, nil).Once()
+
+		result, err := service.UpdateStatus(ctx, loanId, models.LoanStatusCancelled)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, models.LOAN_STATUS_CHANGED)
+		mockLoan.AssertExpectations(t)
+	})
+
+	t.Run("refuses to cancel loan with disbursement in flight", func(t *testing.T) {
+		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
+
+		loanId := "LOAN-123456789012"
+		mockLoan.On("Get", ctx, loanId).
+			Return(&schema.Loan{Id: loanId, Status: models.LoanStatusDisbursementPending}, nil).Once()
+
+		result, err := service.UpdateStatus(ctx, loanId, models.LoanStatusCancelled)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, models.INVALID_LOAN_STATUS_TRANSITION)
+		mockLoan.AssertNotCalled(t, "Update")
+	})
+
+	t.Run("refuses statuses driven by disbursements", func(t *testing.T) {
+		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewLoanService(mockLoan, mockBeneficiary, mockDisbursement, mockIdGenerator)
+
+		result, err := service.UpdateStatus(ctx, "LOAN-123456789012", models.LoanStatusDisbursed)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, models.INVALID_LOAN_STATUS_TRANSITION)
+		mockLoan.AssertNotCalled(t, "Get")
+	})
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"loan-disbursement-service/config"
+	"loan-disbursement-service/models"
+	"loan-disbursement-service/namematch"
+)
+
+type NameMatchPolicy interface {
+	Evaluate(beneficiaryName, borrowerName string, registeredName *string) models.NameMatchResult
+}
+
+// NameMatchPolicyImpl reads its thresholds from settings on every call, so a
+// reloaded configuration applies to the next disbursement.
+type NameMatchPolicyImpl struct {
+	settings *config.Store
+}
+
+func NewNameMatchPolicy(settings *config.Store) NameMatchPolicy {
+	return &NameMatchPolicyImpl{settings: settings}
+}
+
+// Evaluate scores the beneficiary name against the borrower on the loan and
+// against the holder name returned by the penny drop. Names that are not
+// known are skipped; when neither is known the disbursement is flagged.
+// Otherwise the lowest score decides: below the block threshold the
+// disbursement is refused, below the flag threshold it proceeds but is
+// flagged for review, and at or above both it is allowed.
+func (p *NameMatchPolicyImpl) Evaluate(
+	beneficiaryName, borrowerName string,
+	registeredName *string,
+) models.NameMatchResult {
+	result := models.NameMatchResult{}
+	if borrowerName != "" {
+		score := namematch.Score(beneficiaryName, borrowerName)
+		result.BorrowerScore = &score
+	}
+	if registeredName != nil && *registeredName != "" {
+		score := namematch.Score(beneficiaryName, *registeredName)
+		result.RegisteredScore = &score
+	}
+
+	thresholds := p.settings.Get().NameMatch
+	lowest, ok := lowestScore(result.BorrowerScore, result.RegisteredScore)
+	switch {
+	case !ok:
+		result.Decision = models.NameMatchDecisionFlag
+	case lowest < thresholds.BlockThreshold:
+		result.Decision = models.NameMatchDecisionBlock
+	case lowest < thresholds.FlagThreshold:
+		result.Decision = models.NameMatchDecisionFlag
+	default:
+		result.Decision = models.NameMatchDecisionAllow
+	}
+	return result
+}
+
+func lowestScore(scores ...*float64) (float64, bool) {
+	lowest, found := 0.0, false
+	for _, score := range scores {
+		if score == nil {
+			continue
+		}
+		if !found || *score < lowest {
+			lowest = *score
+		}
+		found = true
+	}
+	return lowest, found
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"loan-disbursement-service/config"
+	"loan-disbursement-service/models"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNameMatchPolicy_Evaluate(t *testing.T) {
+	policy := NewNameMatchPolicy(nil)
+	registeredName := "RAVI KUMAR"
+
+	t.Run("allows when beneficiary matches borrower and bank records", func(t *testing.T) {
+		result := policy.Evaluate("Mr. Ravi Kumar", "Kumar Ravi", &registeredName)
+
+		assert.Equal(t, models.NameMatchDecisionAllow, result.Decision)
+		assert.Equal(t, 100.0, *result.BorrowerScore)
+		assert.Equal(t, 100.0, *result.RegisteredScore)
+	})
+
+	t.Run("blocks when beneficiary does not match borrower", func(t *testing.T) {
+		result := policy.Evaluate("Ravi Kumar", "Anita Desai", &registeredName)
+
+		assert.Equal(t, models.NameMatchDecisionBlock, result.Decision)
+	})
+
+	t.Run("blocks when bank records belong to someone else", func(t *testing.T) {
+		other := "ACME TRADERS PRIVATE LIMITED"
+
+		result := policy.Evaluate("Ravi Kumar", "Ravi Kumar", &other)
+
+		assert.Equal(t, models.NameMatchDecisionBlock, result.Decision)
+		assert.Equal(t, 100.0, *result.BorrowerScore)
+	})
+
+	t.Run("flags partial matches", func(t *testing.T) {
+		result := policy.Evaluate("Jon Doe", "John Doe", nil)
+
+		assert.Equal(t, models.NameMatchDecisionFlag, result.Decision)
+		assert.Nil(t, result.RegisteredScore)
+	})
+
+	t.Run("flags when there is no name to compare against", func(t *testing.T) {
+		result := policy.Evaluate("Ravi Kumar", "", nil)
+
+		assert.Equal(t, models.NameMatchDecisionFlag, result.Decision)
+		assert.Nil(t, result.BorrowerScore)
+		assert.Nil(t, result.RegisteredScore)
+	})
+
+	t.Run("uses configured thresholds", func(t *testing.T) {
+		settings := config.Default()
+		settings.NameMatch = config.NameMatch{BlockThreshold: 10, FlagThreshold: 20}
+		lenient := NewNameMatchPolicy(config.NewStore("", settings))
+
+		result := lenient.Evaluate("Suresh Kumar", "Ravi Kumar", nil)
+
+		assert.Equal(t, models.NameMatchDecisionAllow, result.Decision)
+	})
+
+	t.Run("applies reloaded thresholds to the next evaluation", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte(""), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		store := config.NewStore(path, config.Default())
+		reloadable := NewNameMatchPolicy(store)
+		assert.Equal(t, models.NameMatchDecisionBlock, reloadable.Evaluate("Suresh Kumar", "Ravi Kumar", nil).Decision)
+
+		if err := os.WriteFile(path, []byte("name_match:\n  block_threshold: 10\n  flag_threshold: 20\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		restart, err := store.Reload()
+
+		assert.NoError(t, err)
+		assert.Empty(t, restart)
+		assert.Equal(t, models.NameMatchDecisionAllow, reloadable.Evaluate("Suresh Kumar", "Ravi Kumar", nil).Decision)
+	})
+}
@@ -4,12 +4,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"loan-disbursement-service/config"
 	"loan-disbursement-service/db"
 	"loan-disbursement-service/db/daos"
 	"loan-disbursement-service/db/schema"
+	"loan-disbursement-service/events"
+	"loan-disbursement-service/metrics"
 	"loan-disbursement-service/models"
 	"loan-disbursement-service/providers"
 	"loan-disbursement-service/utils"
+	"shared/calendar"
+	"shared/ifsc"
+	"shared/logging"
+	"shared/tracing"
+	"slices"
 	"strings"
 	"time"
 
@@ -29,9 +37,15 @@ type PaymentService interface {
 	) error
 	HandleSuccess(
 		ctx context.Context,
-		disbursementId, transactionId string,
+		disbursement *schema.Disbursement,
+		transactionId string,
 		channel models.PaymentChannel,
+		settledAt time.Time,
 	) error
+	// GeneratePendingSchedules stores the repayment schedules of up to limit
+	// successful disbursements whose schedule could not be stored when they
+	// settled, and returns how many it stored.
+	GeneratePendingSchedules(ctx context.Context, limit int) (int, error)
 }
 
 type PaymentServiceImpl struct {
@@ -40,10 +54,17 @@ type PaymentServiceImpl struct {
 	transaction     daos.TransactionRepository
 	loan            daos.LoanRepository
 	beneficiary     daos.BeneficiaryRepository
+	deadLetter      daos.DeadLetterRepository
+	webhook         WebhookService
 	retryPolicy     RetryPolicy
+	schedule        ScheduleService
 	gatewayProvider providers.PaymentProvider
 	idGenerator     utils.IdGenerator
+	calendar        *calendar.Calendar
+	directory       *ifsc.Directory
+	bus             *events.Bus
 	notificationURL string
+	settings        *config.Store
 }
 
 func NewPaymentService(
@@ -52,10 +73,17 @@ func NewPaymentService(
 	transaction daos.TransactionRepository,
 	loan daos.LoanRepository,
 	beneficiary daos.BeneficiaryRepository,
+	deadLetter daos.DeadLetterRepository,
+	webhook WebhookService,
 	retryPolicy RetryPolicy,
+	schedule ScheduleService,
 	gatewayProvider providers.PaymentProvider,
 	idGenerator utils.IdGenerator,
+	calendar *calendar.Calendar,
+	directory *ifsc.Directory,
+	bus *events.Bus,
 	notificationURL string,
+	settings *config.Store,
 ) PaymentService {
 	return &PaymentServiceImpl{
 		db:              database,
@@ -63,19 +91,46 @@ func NewPaymentService(
 		transaction:     transaction,
 		loan:            loan,
 		beneficiary:     beneficiary,
+		deadLetter:      deadLetter,
+		webhook:         webhook,
 		retryPolicy:     retryPolicy,
+		schedule:        schedule,
 		gatewayProvider: gatewayProvider,
 		idGenerator:     idGenerator,
+		calendar:        calendar,
+		directory:       directory,
+		bus:             bus,
 		notificationURL: notificationURL,
+		settings:        settings,
 	}
 }
 
+// Process continues the trace of the request that created the disbursement,
+// whether it arrives from the payment channel or one of the pollers.
 func (p PaymentServiceImpl) Process(
 	ctx context.Context,
 	disbursement *schema.Disbursement,
+) error {
+	ctx, span := tracing.Resume(ctx, disbursement.TraceParent, "payment.process",
+		tracing.DisbursementIdKey.String(disbursement.Id),
+		tracing.LoanIdKey.String(disbursement.LoanId),
+	)
+	defer span.End()
+	ctx = logging.With(ctx, logging.Fields{
+		LoanId:         disbursement.LoanId,
+		DisbursementId: disbursement.Id,
+	})
+	return tracing.Record(span, p.process(ctx, disbursement))
+}
+
+func (p PaymentServiceImpl) process(
+	ctx context.Context,
+	disbursement *schema.Disbursement,
 ) error {
 	if !p.shouldProcess(disbursement) {
-		log.Info().Msgf("disbursement %v is not eligible for processing", disbursement.Id)
+		log.Ctx(ctx).Info().
+			Str("status", string(disbursement.Status)).
+			Msg("disbursement is not eligible for processing")
 		return nil
 	}
 
@@ -88,11 +143,22 @@ func (p PaymentServiceImpl) Process(
 	if err != nil {
 		return fmt.Errorf("failed to get beneficiary: %w", err)
 	}
+	// The payee may have changed since the disbursement was created, so it
+	// is checked again against what is about to be paid.
+	if err := checkPayable(beneficiary); err != nil {
+		return p.failUnpayable(ctx, disbursement, err)
+	}
 
-	channel := p.selectChannel(loan, disbursement)
-	if err := p.transitionToProcessing(ctx, disbursement, channel); err != nil {
+	channel := p.selectChannel(disbursement, beneficiary)
+	ctx = logging.With(ctx, logging.Fields{Channel: string(channel)})
+	claimed, err := p.transitionToProcessing(ctx, disbursement, channel)
+	if err != nil {
 		return fmt.Errorf("failed to transition to processing: %w", err)
 	}
+	if !claimed {
+		log.Ctx(ctx).Info().Msg("disbursement was taken by another worker")
+		return nil
+	}
 
 	return p.execute(ctx, disbursement, loan, beneficiary, channel)
 }
@@ -110,28 +176,51 @@ func (p PaymentServiceImpl) execute(
 	isChannelActive := p.isChannelActive(ctx, channel)
 	if !isChannelActive {
 		activeChannel, err = p.channelFallback(channel, isChannelActive)
-		log.Info().
-			Msgf("Channel %s is not active, falling back to %s", channel, activeChannel)
+		activeChannel = routeChannel(p.directory, beneficiary, activeChannel)
+		log.Ctx(ctx).Info().
+			Str("fallback_channel", string(activeChannel)).
+			Msg("channel is not active, falling back")
 		if err != nil {
 			return fmt.Errorf("failed to fallback channel: %w", err)
 		}
 	}
 	transactionId := p.idGenerator.GenerateTransactionId()
 	referenceId := p.idGenerator.GenerateReferenceId()
+	ctx = logging.With(ctx, logging.Fields{
+		TransactionId: transactionId,
+		ReferenceId:   referenceId,
+		Channel:       string(activeChannel),
+	})
 	transaction, err := p.transaction.Create(ctx,
 		schema.Transaction{
 			Id:             transactionId,
 			DisbursementId: disbursement.Id,
 			ReferenceId:    referenceId,
 			Channel:        activeChannel,
-			Amount:         loan.Amount,
+			Amount:         disbursement.Amount,
 			Status:         models.TransactionStatusInitiated,
 		},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create transaction: %w", err)
 	}
+	p.bus.Publish(events.Event{
+		Type:           events.TypeTransaction,
+		DisbursementId: disbursement.Id,
+		LoanId:         disbursement.LoanId,
+		ClientId:       disbursement.ClientId,
+		Status:         models.DisbursementStatusProcessing,
+		Channel:        activeChannel,
+		Amount:         disbursement.Amount,
+		RetryCount:     disbursement.RetryCount,
+		TransactionId:  transactionId,
+		ReferenceId:    referenceId,
+	})
 
+	log.Ctx(ctx).Info().
+		Float64("amount", disbursement.Amount).
+		Int("retry_count", disbursement.RetryCount).
+		Msg("sending transfer to gateway")
 	response, err := p.transfer(ctx, referenceId, disbursement, loan, beneficiary, activeChannel)
 	if err != nil {
 		return p.HandleFailure(ctx, disbursement, transaction, activeChannel, err)
@@ -140,6 +229,30 @@ func (p PaymentServiceImpl) execute(
 	return p.handleResponse(ctx, transaction.Id, response)
 }
 
+// failUnpayable fails a disbursement whose beneficiary can no longer be
+// paid without sending it, leaving it in the dead-letter queue for the
+// admin beneficiary correction.
+func (p PaymentServiceImpl) failUnpayable(
+	ctx context.Context,
+	disbursement *schema.Disbursement,
+	reason error,
+) error {
+	log.Ctx(ctx).Warn().Err(reason).Msg("beneficiary is not payable, failing disbursement")
+	err := failDisbursement(ctx, p.db, p.loan, p.disbursement, p.deadLetter, p.webhook, disbursement,
+		models.FailureCategoryBeneficiary, reason.Error(),
+	)
+	if err != nil {
+		return err
+	}
+	metrics.RecordFailure(models.DisbursementStatusFailed, disbursement.Channel, models.FailureCategoryBeneficiary,
+		disbursement.RetryCount,
+	)
+	publishStatus(p.bus, disbursement, models.DisbursementStatusFailed, disbursement.Channel,
+		disbursement.RetryCount, reason.Error(),
+	)
+	return nil
+}
+
 func (p PaymentServiceImpl) HandleNotification(
 	ctx context.Context,
 	notification models.PaymentNotificationRequest,
@@ -148,22 +261,45 @@ func (p PaymentServiceImpl) HandleNotification(
 	if err != nil {
 		return fmt.Errorf("failed to get transaction: %w", err)
 	}
+	ctx = logging.With(ctx, logging.Fields{
+		DisbursementId: transaction.DisbursementId,
+		TransactionId:  transaction.Id,
+		ReferenceId:    transaction.ReferenceId,
+		Channel:        string(notification.Channel),
+	})
 	disbursement, err := p.disbursement.Get(ctx, transaction.DisbursementId)
 	if err != nil {
 		return fmt.Errorf("failed to get disbursement: %w", err)
 	}
+	ctx = logging.With(ctx, logging.Fields{LoanId: disbursement.LoanId})
+	log.Ctx(ctx).Info().
+		Str("status", string(notification.Status)).
+		Msg("payment notification received")
 	if notification.Status == models.TransactionStatusSuccess {
-		return p.HandleSuccess(ctx, disbursement.Id, transaction.Id, notification.Channel)
-	}
-	return p.HandleFailure(
-		ctx,
-		disbursement,
-		transaction,
-		notification.Channel,
-		errors.New(notification.Message),
-	)
+		err = p.HandleSuccess(ctx, disbursement, transaction.Id, notification.Channel, notification.ProcessedAt)
+	} else {
+		err = p.HandleFailure(
+			ctx,
+			disbursement,
+			transaction,
+			notification.Channel,
+			errors.New(notification.Message),
+		)
+	}
+	if errors.Is(err, models.DISBURSEMENT_STATUS_CHANGED) {
+		// Another notification got there first, or an operator closed the
+		// disbursement; the gateway has nothing to resend.
+		log.Ctx(ctx).Warn().Err(err).Msg("payment notification not applied")
+		return nil
+	}
+	return err
 }
 
+// HandleFailure marks the transaction failed and moves the disbursement to
+// the status the failure earns it. The disbursement only moves from the
+// status it was read in, so a failure that lands after a success, a cancel
+// or another failure neither releases the loan nor dead-letters it again.
+// The webhook event for the new status is queued in the same transaction.
 func (p PaymentServiceImpl) HandleFailure(
 	ctx context.Context,
 	disbursement *schema.Disbursement,
@@ -186,8 +322,13 @@ func (p PaymentServiceImpl) HandleFailure(
 		err = payment.Error
 	}
 	status, retryCount := p.evaluateFailure(disbursement.RetryCount, err)
-	return p.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		dbErr := p.transaction.Update(ctx, transaction.Id, map[string]any{
+	log.Ctx(ctx).Warn().Err(err).
+		Str("status", string(status)).
+		Int("retry_count", retryCount).
+		Msg("transfer failed")
+	claimed := false
+	dbErr := p.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		dbErr := p.transaction.WithTx(tx).Update(ctx, transaction.Id, map[string]any{
 			"status":     models.TransactionStatusFailed,
 			"message":    err.Error(),
 			"updated_at": time.Now(),
@@ -195,43 +336,209 @@ func (p PaymentServiceImpl) HandleFailure(
 		if dbErr != nil {
 			return dbErr
 		}
-		return p.disbursement.Update(ctx, disbursement.Id, map[string]any{
-			"status":      status,
-			"channel":     channel,
-			"retry_count": retryCount,
-			"last_error":  err.Error(),
-			"updated_at":  time.Now(),
-		})
+		claimed, dbErr = p.disbursement.WithTx(tx).UpdateIfStatus(ctx, disbursement.Id, disbursement.Status,
+			map[string]any{
+				"status":      status,
+				"channel":     channel,
+				"retry_count": retryCount,
+				"last_error":  err.Error(),
+				"updated_at":  time.Now(),
+			},
+		)
+		if dbErr != nil || !claimed {
+			return dbErr
+		}
+		if status == models.DisbursementStatusFailed {
+			if dbErr = releaseLoan(ctx, p.loan.WithTx(tx), disbursement.LoanId); dbErr != nil {
+				return dbErr
+			}
+			dbErr = recordDeadLetter(ctx, p.deadLetter.WithTx(tx), disbursement, channel,
+				models.ClassifyFailure(err.Error()), err.Error(),
+			)
+			if dbErr != nil {
+				return dbErr
+			}
+		}
+		if event, ok := models.WebhookEventFor(status); ok {
+			return queueEvent(ctx, tx, p.webhook, event, disbursement.Id)
+		}
+		return nil
 	})
+	if dbErr != nil {
+		return dbErr
+	}
+	if !claimed {
+		return fmt.Errorf("%w: disbursement is no longer %s", models.DISBURSEMENT_STATUS_CHANGED, disbursement.Status)
+	}
+	metrics.RecordFailure(status, channel, models.ClassifyFailure(err.Error()), retryCount)
+	publishStatus(p.bus, disbursement, status, channel, retryCount, err.Error())
+	return nil
 }
 
+// HandleSuccess marks the transaction and disbursement successful, credits
+// the loan and queues the success webhook event in one database
+// transaction. The disbursement only moves to success from the status it
+// was read in, so a repeated or concurrent success notification does none
+// of that again. The repayment schedule is
+// generated once that has committed, from settledAt, when the money reached
+// the borrower, or from now when that is unknown. A failed disbursement has
+// released its loan, so the loan is reserved again before it is credited
+// and its dead-letter entry is closed. A schedule that fails to
+// generate does not undo the settlement: the disbursement is left
+// schedule_pending and the retry worker generates it later.
 func (p PaymentServiceImpl) HandleSuccess(
 	ctx context.Context,
-	disbursementId, transactionId string,
+	disbursement *schema.Disbursement,
+	transactionId string,
 	channel models.PaymentChannel,
+	settledAt time.Time,
 ) error {
-	return p.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		dbErr := p.transaction.Update(ctx, transactionId, map[string]any{
+	if settledAt.IsZero() {
+		settledAt = time.Now()
+	}
+	settled := false
+	var loan *schema.Loan
+	err := p.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		dbErr := p.transaction.WithTx(tx).Update(ctx, transactionId, map[string]any{
 			"status":     models.TransactionStatusSuccess,
 			"updated_at": time.Now(),
 		})
+		if dbErr != nil || disbursement.Status == models.DisbursementStatusSuccess {
+			return dbErr
+		}
+		settled, dbErr = p.disbursement.WithTx(tx).UpdateIfStatus(ctx, disbursement.Id, disbursement.Status,
+			map[string]any{
+				"status":           models.DisbursementStatusSuccess,
+				"channel":          channel,
+				"settled_at":       settledAt,
+				"schedule_pending": true,
+				"updated_at":       time.Now(),
+			},
+		)
+		if dbErr != nil || !settled {
+			return dbErr
+		}
+		loans := p.loan.WithTx(tx)
+		if disbursement.Status == models.DisbursementStatusFailed {
+			if _, dbErr = reserveLoan(ctx, loans, disbursement.LoanId); dbErr != nil {
+				return dbErr
+			}
+			dbErr = resolveDeadLetter(ctx, p.deadLetter.WithTx(tx), disbursement.Id,
+				models.DeadLetterStatusClosed, "", "settled after failing",
+			)
+			if dbErr != nil {
+				return dbErr
+			}
+		}
+		loan, dbErr = settleLoan(ctx, loans, disbursement)
 		if dbErr != nil {
 			return dbErr
 		}
-		return p.disbursement.Update(ctx, disbursementId, map[string]any{
-			"status":     models.DisbursementStatusSuccess,
-			"channel":    channel,
-			"updated_at": time.Now(),
+		return queueEvent(ctx, tx, p.webhook, models.WebhookEventSuccess, disbursement.Id)
+	})
+	if err != nil {
+		return err
+	}
+	if !settled {
+		if disbursement.Status == models.DisbursementStatusSuccess {
+			return nil
+		}
+		return fmt.Errorf("%w: disbursement is no longer %s", models.DISBURSEMENT_STATUS_CHANGED, disbursement.Status)
+	}
+	log.Ctx(ctx).Info().Msg("disbursement succeeded")
+	metrics.RecordSuccess(channel, disbursement.CreatedAt, disbursement.RetryCount)
+	publishStatus(p.bus, disbursement, models.DisbursementStatusSuccess, channel, disbursement.RetryCount, "")
+	if err := p.generateSchedule(ctx, loan, disbursement, settledAt); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("repayment schedule not stored, it is retried later")
+	}
+	return nil
+}
+
+func (p PaymentServiceImpl) GeneratePendingSchedules(ctx context.Context, limit int) (int, error) {
+	disbursements, err := p.disbursement.ListSchedulePending(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+	generated := 0
+	for _, disbursement := range disbursements {
+		ctx := logging.With(ctx, logging.Fields{
+			LoanId:         disbursement.LoanId,
+			DisbursementId: disbursement.Id,
+		})
+		settledAt := disbursement.UpdatedAt
+		if disbursement.SettledAt != nil {
+			settledAt = *disbursement.SettledAt
+		}
+		loan, err := p.loan.Get(ctx, disbursement.LoanId)
+		if err == nil {
+			err = p.generateSchedule(ctx, loan, &disbursement, settledAt)
+		}
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("repayment schedule not stored, it is retried later")
+			// Moved to the back, so one that keeps failing does not hold
+			// up the rest.
+			if err := p.disbursement.Update(ctx, disbursement.Id, map[string]any{
+				"updated_at": time.Now(),
+			}); err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("failed to reschedule schedule generation")
+			}
+			continue
+		}
+		generated++
+	}
+	return generated, nil
+}
+
+// generateSchedule stores the repayment schedule of a settled disbursement
+// and clears its schedule_pending flag together, so a retry never stores it
+// twice.
+func (p PaymentServiceImpl) generateSchedule(
+	ctx context.Context,
+	loan *schema.Loan,
+	disbursement *schema.Disbursement,
+	settledAt time.Time,
+) error {
+	return p.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := p.schedule.WithTx(tx).Generate(ctx, loan, disbursement, settledAt); err != nil {
+			return err
+		}
+		return p.disbursement.WithTx(tx).Update(ctx, disbursement.Id, map[string]any{
+			"schedule_pending": false,
 		})
 	})
 }
 
+// settleLoan adds a successful disbursement to the loan's disbursed amount
+// and marks the loan disbursed once the sanctioned amount is fully paid out.
+func settleLoan(
+	ctx context.Context,
+	loans daos.LoanRepository,
+	disbursement *schema.Disbursement,
+) (*schema.Loan, error) {
+	loan, err := loans.Get(ctx, disbursement.LoanId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loan: %w", err)
+	}
+
+	disbursed := loan.DisbursedAmount + disbursement.Amount
+	next := models.LoanStatusPartiallyDisbursed
+	if disbursed >= loan.Amount {
+		next = models.LoanStatusDisbursed
+	}
+	err = transitionLoan(ctx, loans, loan, next, map[string]any{
+		"disbursed_amount": disbursed,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return loan, nil
+}
+
 func (p PaymentServiceImpl) evaluateFailure(
 	retryCount int,
 	err error,
 ) (models.DisbursementStatus, int) {
-	log.Info().Msgf("evaluating failure: %v", err)
-	if retryCount >= MaxRetries {
+	if retryCount >= p.settings.Get().Retry.MaxRetries {
 		return models.DisbursementStatusFailed, retryCount
 	}
 
@@ -243,7 +550,6 @@ func (p PaymentServiceImpl) evaluateFailure(
 		}
 	}
 
-	log.Info().Msgf("error is not transient, returning failed")
 	return models.DisbursementStatusFailed, newRetryCount
 }
 
@@ -281,46 +587,136 @@ func (p PaymentServiceImpl) shouldProcess(
 	}
 }
 
+// transitionToProcessing claims the disbursement for this attempt. It only
+// moves it from the status it was read in, so when the payment worker and
+// the retry worker both hold the same initiated disbursement only one of
+// them sends it; it reports whether this caller won. It also records when a
+// NEFT transfer should settle, which is the next half-hourly batch on a bank
+// working day. Instant channels clear it, since a retry may have moved the
+// disbursement off NEFT. The processing webhook event is queued with the
+// claim.
 func (p PaymentServiceImpl) transitionToProcessing(
 	ctx context.Context,
 	disbursement *schema.Disbursement,
 	channel models.PaymentChannel,
-) error {
-	return p.disbursement.Update(ctx, disbursement.Id, map[string]any{
-		"status":     models.DisbursementStatusProcessing,
-		"channel":    channel,
-		"updated_at": time.Now(),
+) (bool, error) {
+	now := time.Now()
+	var expectedSettlementAt *time.Time
+	if channel == models.PaymentChannelNEFT {
+		settlement := p.calendar.NextNEFTSettlement(now)
+		expectedSettlementAt = &settlement
+	}
+	claimed := false
+	err := p.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		claimed, err = p.disbursement.WithTx(tx).UpdateIfStatus(ctx, disbursement.Id, disbursement.Status, map[string]any{
+			"status":                 models.DisbursementStatusProcessing,
+			"channel":                channel,
+			"expected_settlement_at": expectedSettlementAt,
+			"updated_at":             now,
+		})
+		if err != nil || !claimed {
+			return err
+		}
+		return queueEvent(ctx, tx, p.webhook, models.WebhookEventProcessing, disbursement.Id)
 	})
+	if err != nil || !claimed {
+		return false, err
+	}
+	disbursement.Status = models.DisbursementStatusProcessing
+	publishStatus(p.bus, disbursement, models.DisbursementStatusProcessing, channel, disbursement.RetryCount, "")
+	return true, nil
 }
 
+// publishStatus counts a saved status change and puts it on the event bus
+// for live streams.
+func publishStatus(
+	bus *events.Bus,
+	disbursement *schema.Disbursement,
+	status models.DisbursementStatus,
+	channel models.PaymentChannel,
+	retryCount int,
+	lastError string,
+) {
+	metrics.RecordStatus(status, channel)
+	bus.Publish(events.Event{
+		Type:           events.TypeStatus,
+		DisbursementId: disbursement.Id,
+		LoanId:         disbursement.LoanId,
+		ClientId:       disbursement.ClientId,
+		Status:         status,
+		Channel:        channel,
+		Amount:         disbursement.Amount,
+		RetryCount:     retryCount,
+		Error:          lastError,
+	})
+}
+
+// selectChannel picks the channel for the disbursement's amount and retry
+// count, then routes it to one the beneficiary's branch is on. A channel
+// forced by an operator is used as is.
 func (p PaymentServiceImpl) selectChannel(
-	loan *schema.Loan,
 	disbursement *schema.Disbursement,
+	beneficiary *schema.Beneficiary,
 ) models.PaymentChannel {
-	if disbursement.RetryCount != 0 {
-		return p.switchChannel(loan, disbursement)
+	if disbursement.ForcedChannel != nil {
+		return *disbursement.ForcedChannel
 	}
-	if loan.Amount <= 100000 {
-		return models.PaymentChannelUPI
-	} else if loan.Amount <= 500000 {
-		return models.PaymentChannelIMPS
+	return routeChannel(p.directory, beneficiary, p.preferredChannel(disbursement))
+}
+
+func (p PaymentServiceImpl) preferredChannel(
+	disbursement *schema.Disbursement,
+) models.PaymentChannel {
+	if disbursement.RetryCount != 0 {
+		return p.switchChannel(disbursement)
 	}
-	return models.PaymentChannelNEFT
+	return channelForAmount(p.settings.Get().Channels, disbursement.Amount)
 }
 
 func (p PaymentServiceImpl) switchChannel(
-	loan *schema.Loan,
 	disbursement *schema.Disbursement,
 ) models.PaymentChannel {
-	if disbursement.RetryCount == 2 && loan.Amount <= 100000 {
+	limits := p.settings.Get().Channels
+	if disbursement.RetryCount == 2 && disbursement.Amount <= limits.UPILimit {
 		return models.PaymentChannelIMPS
 	}
-	if loan.Amount <= 500000 {
+	if disbursement.Amount <= limits.IMPSLimit {
 		return models.PaymentChannelIMPS
 	}
 	return models.PaymentChannelNEFT
 }
 
+// channelOrder lists the channels by rising transfer limit, so moving a
+// transfer along it never takes it over a channel's limit.
+var channelOrder = []models.PaymentChannel{
+	models.PaymentChannelUPI,
+	models.PaymentChannelIMPS,
+	models.PaymentChannelNEFT,
+}
+
+// routeChannel moves a transfer off a channel the beneficiary's branch is not
+// on, to the next one in channelOrder that it is. When there is none, or the
+// branch is not in the IFSC directory, the channel is kept and the gateway
+// refuses the transfer.
+func routeChannel(
+	directory *ifsc.Directory,
+	beneficiary *schema.Beneficiary,
+	channel models.PaymentChannel,
+) models.PaymentChannel {
+	branch, ok := directory.Resolve(beneficiary.IFSC)
+	start := slices.Index(channelOrder, channel)
+	if !ok || start < 0 {
+		return channel
+	}
+	for _, candidate := range channelOrder[start:] {
+		if branch.Supports(string(candidate)) {
+			return candidate
+		}
+	}
+	return channel
+}
+
 func (p PaymentServiceImpl) isChannelActive(
 	ctx context.Context,
 	channel models.PaymentChannel,
@@ -355,7 +751,7 @@ func (p PaymentServiceImpl) transfer(
 ) (models.PaymentResponse, error) {
 	request := models.PaymentRequest{
 		ReferenceID: referenceId,
-		Amount:      loan.Amount,
+		Amount:      disbursement.Amount,
 		Channel:     channel,
 		Beneficiary: models.Beneficiary{
 			Name:    beneficiary.Name,
@@ -370,5 +766,8 @@ func (p PaymentServiceImpl) transfer(
 		},
 	}
 
-	return p.gatewayProvider.Transfer(ctx, request)
+	start := time.Now()
+	response, err := p.gatewayProvider.Transfer(ctx, request)
+	metrics.ObserveTransfer(channel, time.Since(start), err)
+	return response, err
 }
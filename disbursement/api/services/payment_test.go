@@ -3,13 +3,17 @@ package services
 import (
 	"context"
 	"errors"
+	"loan-disbursement-service/config"
 	"loan-disbursement-service/db"
 	"loan-disbursement-service/db/schema"
+	"loan-disbursement-service/events"
 	"loan-disbursement-service/models"
 	db_test "loan-disbursement-service/test/db"
 	provider_test "loan-disbursement-service/test/providers"
 	utils_test "loan-disbursement-service/test/utils"
 	"reflect"
+	"shared/calendar"
+	"shared/ifsc"
 	"testing"
 	"time"
 	"unsafe"
@@ -38,6 +42,17 @@ func setupMockDB(t *testing.T) *db.Database {
 	return testDB
 }
 
+func newTestDirectory(t *testing.T) *ifsc.Directory {
+	directory, err := ifsc.New([]ifsc.Branch{
+		{IFSC: "HDFC0001234", Bank: "HDFC Bank", Branch: "Andheri East", NEFT: true, IMPS: true, UPI: true},
+		{IFSC: "SBIN0001234", Bank: "State Bank of India", Branch: "Bandra West", NEFT: true, IMPS: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return directory
+}
+
 func TestPaymentService_Process(t *testing.T) {
 	ctx := context.Background()
 
@@ -47,9 +62,13 @@ func TestPaymentService_Process(t *testing.T) {
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockLoan := new(db_test.MockLoanRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
 		mockRetryPolicy := new(MockRetryPolicy)
+		mockSchedule := new(MockScheduleService)
 		mockGatewayProvider := new(provider_test.MockGatewayProvider)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
+		bus := events.NewBus(events.DefaultHistorySize)
+		subscription, _, _, _ := bus.Subscribe(events.Filter{}, 0)
 
 		service := NewPaymentService(
 			mockDB,
@@ -57,15 +76,23 @@ func TestPaymentService_Process(t *testing.T) {
 			mockTransaction,
 			mockLoan,
 			mockBeneficiary,
+			mockDeadLetter,
+			newMockWebhookService(),
 			mockRetryPolicy,
+			mockSchedule,
 			mockGatewayProvider,
 			mockIdGenerator,
+			calendar.Default(),
+			nil,
+			bus,
 			"https://example.com/webhook",
+			nil,
 		)
 
 		disbursement := &schema.Disbursement{
 			Id:         "DISB-123",
 			LoanId:     "LOAN-123",
+			Amount:     50000.0,
 			Status:     models.DisbursementStatusInitiated,
 			RetryCount: 0,
 			UpdatedAt:  time.Now(),
@@ -83,27 +110,28 @@ func TestPaymentService_Process(t *testing.T) {
 			Account: "1234567890",
 			IFSC:    "IFSC0001234",
 			Bank:    "Test Bank",
+			Status:  models.BeneficiaryStatusVerified,
 		}
 
 		transactionId := "TXN-123"
 		referenceId := "REF-123"
 
-		mockLoan.On("Get", ctx, "LOAN-123").Return(loan, nil).Once()
-		mockBeneficiary.On("GetById", ctx, "BEN-123").Return(beneficiary, nil).Once()
-		mockGatewayProvider.On("IsActive", ctx, models.PaymentChannelUPI).Return(true, nil).Once()
-		mockDisbursement.On("Update", ctx, disbursement.Id, mock.MatchedBy(func(fields map[string]any) bool {
+		mockLoan.On("Get", mock.Anything, "LOAN-123").Return(loan, nil).Once()
+		mockBeneficiary.On("GetById", mock.Anything, "BEN-123").Return(beneficiary, nil).Once()
+		mockGatewayProvider.On("IsActive", mock.Anything, models.PaymentChannelUPI).Return(true, nil).Once()
+		mockDisbursement.On("UpdateIfStatus", mock.Anything, disbursement.Id, disbursement.Status, mock.MatchedBy(func(fields map[string]any) bool {
 			return fields["status"] == models.DisbursementStatusProcessing &&
 				fields["channel"] == models.PaymentChannelUPI
 		})).
-			Return(nil).
+			Return(true, nil).
 			Once()
 		mockIdGenerator.On("GenerateTransactionId").Return(transactionId).Once()
 		mockIdGenerator.On("GenerateReferenceId").Return(referenceId).Once()
-		mockTransaction.On("Create", ctx, mock.MatchedBy(func(txn schema.Transaction) bool {
+		mockTransaction.On("Create", mock.Anything, mock.MatchedBy(func(txn schema.Transaction) bool {
 			return txn.Id == transactionId &&
 				txn.ReferenceId == referenceId &&
 				txn.Channel == models.PaymentChannelUPI &&
-				txn.Amount == loan.Amount &&
+				txn.Amount == disbursement.Amount &&
 				txn.Status == models.TransactionStatusInitiated
 		})).Return(&schema.Transaction{Id: transactionId}, nil).Once()
 
@@ -111,8 +139,8 @@ func TestPaymentService_Process(t *testing.T) {
 			Status: models.TransactionStatusSuccess,
 		}
 
-		mockGatewayProvider.On("Transfer", ctx, mock.Anything).Return(paymentResponse, nil).Once()
-		mockTransaction.On("Update", ctx, transactionId, mock.MatchedBy(func(fields map[string]any) bool {
+		mockGatewayProvider.On("Transfer", mock.Anything, mock.Anything).Return(paymentResponse, nil).Once()
+		mockTransaction.On("Update", mock.Anything, transactionId, mock.MatchedBy(func(fields map[string]any) bool {
 			return fields["status"] == models.TransactionStatusSuccess
 		})).
 			Return(nil).
@@ -127,6 +155,85 @@ func TestPaymentService_Process(t *testing.T) {
 		mockDisbursement.AssertExpectations(t)
 		mockTransaction.AssertExpectations(t)
 		mockIdGenerator.AssertExpectations(t)
+
+		processing := <-subscription.Events()
+		assert.Equal(t, events.TypeStatus, processing.Type)
+		assert.Equal(t, models.DisbursementStatusProcessing, processing.Status)
+		attempt := <-subscription.Events()
+		assert.Equal(t, events.TypeTransaction, attempt.Type)
+		assert.Equal(t, transactionId, attempt.TransactionId)
+		assert.Equal(t, referenceId, attempt.ReferenceId)
+		assert.Equal(t, models.PaymentChannelUPI, attempt.Channel)
+	})
+
+	t.Run("routes away from a channel the branch is not on", func(t *testing.T) {
+		mockDB := setupMockDB(t)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
+		mockGatewayProvider := new(provider_test.MockGatewayProvider)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewPaymentService(
+			mockDB,
+			mockDisbursement,
+			mockTransaction,
+			mockLoan,
+			mockBeneficiary,
+			mockDeadLetter,
+			newMockWebhookService(),
+			new(MockRetryPolicy),
+			new(MockScheduleService),
+			mockGatewayProvider,
+			mockIdGenerator,
+			calendar.Default(),
+			newTestDirectory(t),
+			events.NewBus(events.DefaultHistorySize),
+			"https://example.com/webhook",
+			nil,
+		)
+
+		disbursement := &schema.Disbursement{
+			Id:        "DISB-123",
+			LoanId:    "LOAN-123",
+			Amount:    50000.0,
+			Status:    models.DisbursementStatusInitiated,
+			UpdatedAt: time.Now(),
+		}
+
+		mockLoan.On("Get", mock.Anything, "LOAN-123").
+			Return(&schema.Loan{Id: "LOAN-123", Amount: 50000.0, BeneficiaryId: stringPtr("BEN-123")}, nil).Once()
+		mockBeneficiary.On("GetById", mock.Anything, "BEN-123").
+			Return(&schema.Beneficiary{
+				Id:      "BEN-123",
+				Name:    "John Doe",
+				Account: "1234567890",
+				IFSC:    "SBIN0001234",
+				Status:  models.BeneficiaryStatusVerified,
+			}, nil).
+			Once()
+		mockGatewayProvider.On("IsActive", mock.Anything, models.PaymentChannelIMPS).Return(true, nil).Once()
+		mockDisbursement.On("UpdateIfStatus", mock.Anything, disbursement.Id, disbursement.Status, mock.MatchedBy(func(fields map[string]any) bool {
+			return fields["channel"] == models.PaymentChannelIMPS
+		})).Return(true, nil).Once()
+		mockIdGenerator.On("GenerateTransactionId").Return("TXN-123").Once()
+		mockIdGenerator.On("GenerateReferenceId").Return("REF-123").Once()
+		mockTransaction.On("Create", mock.Anything, mock.MatchedBy(func(txn schema.Transaction) bool {
+			return txn.Channel == models.PaymentChannelIMPS
+		})).Return(&schema.Transaction{Id: "TXN-123"}, nil).Once()
+		mockGatewayProvider.On("Transfer", mock.Anything, mock.MatchedBy(func(request models.PaymentRequest) bool {
+			return request.Channel == models.PaymentChannelIMPS
+		})).Return(models.PaymentResponse{Status: models.TransactionStatusSuccess}, nil).Once()
+		mockTransaction.On("Update", mock.Anything, "TXN-123", mock.Anything).Return(nil).Once()
+
+		err := service.Process(ctx, disbursement)
+
+		assert.NoError(t, err)
+		mockGatewayProvider.AssertExpectations(t)
+		mockDisbursement.AssertExpectations(t)
+		mockTransaction.AssertExpectations(t)
 	})
 
 	t.Run("returns nil when disbursement status is processing", func(t *testing.T) {
@@ -135,7 +242,9 @@ func TestPaymentService_Process(t *testing.T) {
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockLoan := new(db_test.MockLoanRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
 		mockRetryPolicy := new(MockRetryPolicy)
+		mockSchedule := new(MockScheduleService)
 		mockGatewayProvider := new(provider_test.MockGatewayProvider)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
@@ -145,10 +254,17 @@ func TestPaymentService_Process(t *testing.T) {
 			mockTransaction,
 			mockLoan,
 			mockBeneficiary,
+			mockDeadLetter,
+			newMockWebhookService(),
 			mockRetryPolicy,
+			mockSchedule,
 			mockGatewayProvider,
 			mockIdGenerator,
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
 			"https://example.com/webhook",
+			nil,
 		)
 
 		disbursement := &schema.Disbursement{
@@ -169,7 +285,9 @@ func TestPaymentService_Process(t *testing.T) {
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockLoan := new(db_test.MockLoanRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
 		mockRetryPolicy := new(MockRetryPolicy)
+		mockSchedule := new(MockScheduleService)
 		mockGatewayProvider := new(provider_test.MockGatewayProvider)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
@@ -179,10 +297,17 @@ func TestPaymentService_Process(t *testing.T) {
 			mockTransaction,
 			mockLoan,
 			mockBeneficiary,
+			mockDeadLetter,
+			newMockWebhookService(),
 			mockRetryPolicy,
+			mockSchedule,
 			mockGatewayProvider,
 			mockIdGenerator,
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
 			"https://example.com/webhook",
+			nil,
 		)
 
 		disbursement := &schema.Disbursement{
@@ -202,7 +327,9 @@ func TestPaymentService_Process(t *testing.T) {
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockLoan := new(db_test.MockLoanRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
 		mockRetryPolicy := new(MockRetryPolicy)
+		mockSchedule := new(MockScheduleService)
 		mockGatewayProvider := new(provider_test.MockGatewayProvider)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
@@ -212,15 +339,23 @@ func TestPaymentService_Process(t *testing.T) {
 			mockTransaction,
 			mockLoan,
 			mockBeneficiary,
+			mockDeadLetter,
+			newMockWebhookService(),
 			mockRetryPolicy,
+			mockSchedule,
 			mockGatewayProvider,
 			mockIdGenerator,
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
 			"https://example.com/webhook",
+			nil,
 		)
 
 		disbursement := &schema.Disbursement{
 			Id:         "DISB-123",
 			LoanId:     "LOAN-123",
+			Amount:     50000.0,
 			Status:     models.DisbursementStatusSuspended,
 			RetryCount: 1,
 			UpdatedAt:  time.Now().Add(-2 * time.Hour),
@@ -238,6 +373,7 @@ func TestPaymentService_Process(t *testing.T) {
 			Account: "1234567890",
 			IFSC:    "IFSC0001234",
 			Bank:    "Test Bank",
+			Status:  models.BeneficiaryStatusVerified,
 		}
 
 		transactionId := "TXN-123"
@@ -246,18 +382,19 @@ func TestPaymentService_Process(t *testing.T) {
 		mockRetryPolicy.On("IsRetryEligible", disbursement.UpdatedAt, disbursement.RetryCount).
 			Return(true).
 			Once()
-		mockLoan.On("Get", ctx, "LOAN-123").Return(loan, nil).Once()
-		mockBeneficiary.On("GetById", ctx, "BEN-123").Return(beneficiary, nil).Once()
-		mockGatewayProvider.On("IsActive", ctx, models.PaymentChannelNEFT).Return(true, nil).Once()
-		mockDisbursement.On("Update", ctx, disbursement.Id, mock.MatchedBy(func(fields map[string]any) bool {
+		mockLoan.On("Get", mock.Anything, "LOAN-123").Return(loan, nil).Once()
+		mockBeneficiary.On("GetById", mock.Anything, "BEN-123").Return(beneficiary, nil).Once()
+		mockGatewayProvider.On("IsActive", mock.Anything, models.PaymentChannelIMPS).Return(true, nil).Once()
+		mockDisbursement.On("UpdateIfStatus", mock.Anything, disbursement.Id, disbursement.Status, mock.MatchedBy(func(fields map[string]any) bool {
 			return fields["status"] == models.DisbursementStatusProcessing &&
-				fields["channel"] == models.PaymentChannelNEFT
+				fields["channel"] == models.PaymentChannelIMPS &&
+				fields["expected_settlement_at"] == (*time.Time)(nil)
 		})).
-			Return(nil).
+			Return(true, nil).
 			Once()
 		mockIdGenerator.On("GenerateTransactionId").Return(transactionId).Once()
 		mockIdGenerator.On("GenerateReferenceId").Return(referenceId).Once()
-		mockTransaction.On("Create", ctx, mock.Anything).
+		mockTransaction.On("Create", mock.Anything, mock.Anything).
 			Return(&schema.Transaction{Id: transactionId}, nil).
 			Once()
 
@@ -265,8 +402,8 @@ func TestPaymentService_Process(t *testing.T) {
 			Status: models.TransactionStatusSuccess,
 		}
 
-		mockGatewayProvider.On("Transfer", ctx, mock.Anything).Return(paymentResponse, nil).Once()
-		mockTransaction.On("Update", ctx, transactionId, mock.Anything).Return(nil).Once()
+		mockGatewayProvider.On("Transfer", mock.Anything, mock.Anything).Return(paymentResponse, nil).Once()
+		mockTransaction.On("Update", mock.Anything, transactionId, mock.Anything).Return(nil).Once()
 
 		err := service.Process(ctx, disbursement)
 
@@ -280,7 +417,9 @@ func TestPaymentService_Process(t *testing.T) {
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockLoan := new(db_test.MockLoanRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
 		mockRetryPolicy := new(MockRetryPolicy)
+		mockSchedule := new(MockScheduleService)
 		mockGatewayProvider := new(provider_test.MockGatewayProvider)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
@@ -290,10 +429,17 @@ func TestPaymentService_Process(t *testing.T) {
 			mockTransaction,
 			mockLoan,
 			mockBeneficiary,
+			mockDeadLetter,
+			newMockWebhookService(),
 			mockRetryPolicy,
+			mockSchedule,
 			mockGatewayProvider,
 			mockIdGenerator,
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
 			"https://example.com/webhook",
+			nil,
 		)
 
 		disbursement := &schema.Disbursement{
@@ -319,7 +465,9 @@ func TestPaymentService_Process(t *testing.T) {
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockLoan := new(db_test.MockLoanRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
 		mockRetryPolicy := new(MockRetryPolicy)
+		mockSchedule := new(MockScheduleService)
 		mockGatewayProvider := new(provider_test.MockGatewayProvider)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
@@ -329,10 +477,17 @@ func TestPaymentService_Process(t *testing.T) {
 			mockTransaction,
 			mockLoan,
 			mockBeneficiary,
+			mockDeadLetter,
+			newMockWebhookService(),
 			mockRetryPolicy,
+			mockSchedule,
 			mockGatewayProvider,
 			mockIdGenerator,
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
 			"https://example.com/webhook",
+			nil,
 		)
 
 		disbursement := &schema.Disbursement{
@@ -342,7 +497,7 @@ func TestPaymentService_Process(t *testing.T) {
 			RetryCount: 0,
 		}
 
-		mockLoan.On("Get", ctx, "LOAN-123").Return(nil, errors.New("loan not found")).Once()
+		mockLoan.On("Get", mock.Anything, "LOAN-123").Return(nil, errors.New("loan not found")).Once()
 
 		err := service.Process(ctx, disbursement)
 
@@ -356,7 +511,9 @@ func TestPaymentService_Process(t *testing.T) {
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockLoan := new(db_test.MockLoanRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
 		mockRetryPolicy := new(MockRetryPolicy)
+		mockSchedule := new(MockScheduleService)
 		mockGatewayProvider := new(provider_test.MockGatewayProvider)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
@@ -366,15 +523,23 @@ func TestPaymentService_Process(t *testing.T) {
 			mockTransaction,
 			mockLoan,
 			mockBeneficiary,
+			mockDeadLetter,
+			newMockWebhookService(),
 			mockRetryPolicy,
+			mockSchedule,
 			mockGatewayProvider,
 			mockIdGenerator,
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
 			"https://example.com/webhook",
+			nil,
 		)
 
 		disbursement := &schema.Disbursement{
 			Id:         "DISB-123",
 			LoanId:     "LOAN-123",
+			Amount:     50000.0,
 			Status:     models.DisbursementStatusInitiated,
 			RetryCount: 0,
 		}
@@ -385,8 +550,8 @@ func TestPaymentService_Process(t *testing.T) {
 			BeneficiaryId: stringPtr("BEN-123"),
 		}
 
-		mockLoan.On("Get", ctx, "LOAN-123").Return(loan, nil).Once()
-		mockBeneficiary.On("GetById", ctx, "BEN-123").
+		mockLoan.On("Get", mock.Anything, "LOAN-123").Return(loan, nil).Once()
+		mockBeneficiary.On("GetById", mock.Anything, "BEN-123").
 			Return(nil, errors.New("beneficiary not found")).
 			Once()
 
@@ -402,7 +567,9 @@ func TestPaymentService_Process(t *testing.T) {
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockLoan := new(db_test.MockLoanRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
 		mockRetryPolicy := new(MockRetryPolicy)
+		mockSchedule := new(MockScheduleService)
 		mockGatewayProvider := new(provider_test.MockGatewayProvider)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
@@ -412,15 +579,23 @@ func TestPaymentService_Process(t *testing.T) {
 			mockTransaction,
 			mockLoan,
 			mockBeneficiary,
+			mockDeadLetter,
+			newMockWebhookService(),
 			mockRetryPolicy,
+			mockSchedule,
 			mockGatewayProvider,
 			mockIdGenerator,
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
 			"https://example.com/webhook",
+			nil,
 		)
 
 		disbursement := &schema.Disbursement{
 			Id:         "DISB-123",
 			LoanId:     "LOAN-123",
+			Amount:     50000.0,
 			Status:     models.DisbursementStatusInitiated,
 			RetryCount: 0,
 			UpdatedAt:  time.Now(),
@@ -438,23 +613,24 @@ func TestPaymentService_Process(t *testing.T) {
 			Account: "1234567890",
 			IFSC:    "IFSC0001234",
 			Bank:    "Test Bank",
+			Status:  models.BeneficiaryStatusVerified,
 		}
 
 		transactionId := "TXN-123"
 		referenceId := "REF-123"
 
-		mockLoan.On("Get", ctx, "LOAN-123").Return(loan, nil).Once()
-		mockBeneficiary.On("GetById", ctx, "BEN-123").Return(beneficiary, nil).Once()
-		mockDisbursement.On("Update", ctx, disbursement.Id, mock.MatchedBy(func(fields map[string]any) bool {
+		mockLoan.On("Get", mock.Anything, "LOAN-123").Return(loan, nil).Once()
+		mockBeneficiary.On("GetById", mock.Anything, "BEN-123").Return(beneficiary, nil).Once()
+		mockDisbursement.On("UpdateIfStatus", mock.Anything, disbursement.Id, disbursement.Status, mock.MatchedBy(func(fields map[string]any) bool {
 			return fields["status"] == models.DisbursementStatusProcessing &&
 				fields["channel"] == models.PaymentChannelUPI
 		})).
-			Return(nil).
+			Return(true, nil).
 			Once()
-		mockGatewayProvider.On("IsActive", ctx, models.PaymentChannelUPI).Return(false, nil).Once()
+		mockGatewayProvider.On("IsActive", mock.Anything, models.PaymentChannelUPI).Return(false, nil).Once()
 		mockIdGenerator.On("GenerateTransactionId").Return(transactionId).Once()
 		mockIdGenerator.On("GenerateReferenceId").Return(referenceId).Once()
-		mockTransaction.On("Create", ctx, mock.MatchedBy(func(txn schema.Transaction) bool {
+		mockTransaction.On("Create", mock.Anything, mock.MatchedBy(func(txn schema.Transaction) bool {
 			return txn.Channel == models.PaymentChannelIMPS
 		})).Return(&schema.Transaction{Id: transactionId}, nil).Once()
 
@@ -462,8 +638,8 @@ func TestPaymentService_Process(t *testing.T) {
 			Status: models.TransactionStatusSuccess,
 		}
 
-		mockGatewayProvider.On("Transfer", ctx, mock.Anything).Return(paymentResponse, nil).Once()
-		mockTransaction.On("Update", ctx, transactionId, mock.Anything).Return(nil).Once()
+		mockGatewayProvider.On("Transfer", mock.Anything, mock.Anything).Return(paymentResponse, nil).Once()
+		mockTransaction.On("Update", mock.Anything, transactionId, mock.Anything).Return(nil).Once()
 
 		err := service.Process(ctx, disbursement)
 
@@ -477,7 +653,9 @@ func TestPaymentService_Process(t *testing.T) {
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockLoan := new(db_test.MockLoanRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
 		mockRetryPolicy := new(MockRetryPolicy)
+		mockSchedule := new(MockScheduleService)
 		mockGatewayProvider := new(provider_test.MockGatewayProvider)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
@@ -487,15 +665,23 @@ func TestPaymentService_Process(t *testing.T) {
 			mockTransaction,
 			mockLoan,
 			mockBeneficiary,
+			mockDeadLetter,
+			newMockWebhookService(),
 			mockRetryPolicy,
+			mockSchedule,
 			mockGatewayProvider,
 			mockIdGenerator,
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
 			"https://example.com/webhook",
+			nil,
 		)
 
 		disbursement := &schema.Disbursement{
 			Id:         "DISB-123",
 			LoanId:     "LOAN-123",
+			Amount:     50000.0,
 			Status:     models.DisbursementStatusInitiated,
 			RetryCount: 0,
 			UpdatedAt:  time.Now(),
@@ -513,6 +699,7 @@ func TestPaymentService_Process(t *testing.T) {
 			Account: "1234567890",
 			IFSC:    "IFSC0001234",
 			Bank:    "Test Bank",
+			Status:  models.BeneficiaryStatusVerified,
 		}
 
 		transactionId := "TXN-123"
@@ -523,21 +710,21 @@ func TestPaymentService_Process(t *testing.T) {
 			Channel:     models.PaymentChannelUPI,
 		}
 
-		mockLoan.On("Get", ctx, "LOAN-123").Return(loan, nil).Once()
-		mockBeneficiary.On("GetById", ctx, "BEN-123").Return(beneficiary, nil).Once()
-		mockGatewayProvider.On("IsActive", ctx, models.PaymentChannelUPI).Return(true, nil).Once()
-		mockDisbursement.On("Update", ctx, disbursement.Id, mock.Anything).Return(nil).Once()
+		mockLoan.On("Get", mock.Anything, "LOAN-123").Return(loan, nil).Once()
+		mockBeneficiary.On("GetById", mock.Anything, "BEN-123").Return(beneficiary, nil).Once()
+		mockGatewayProvider.On("IsActive", mock.Anything, models.PaymentChannelUPI).Return(true, nil).Once()
+		mockDisbursement.On("UpdateIfStatus", mock.Anything, disbursement.Id, disbursement.Status, mock.Anything).Return(true, nil).Once()
 		mockIdGenerator.On("GenerateTransactionId").Return(transactionId).Once()
 		mockIdGenerator.On("GenerateReferenceId").Return(referenceId).Once()
-		mockTransaction.On("Create", ctx, mock.Anything).Return(transaction, nil).Once()
+		mockTransaction.On("Create", mock.Anything, mock.Anything).Return(transaction, nil).Once()
 
 		transferError := models.NETWORK_ERROR
-		mockGatewayProvider.On("Transfer", ctx, mock.Anything).
+		mockGatewayProvider.On("Transfer", mock.Anything, mock.Anything).
 			Return(models.PaymentResponse{}, transferError).
 			Once()
 
-		mockTransaction.On("Update", ctx, transactionId, mock.Anything).Return(nil).Once()
-		mockDisbursement.On("Update", ctx, disbursement.Id, mock.Anything).Return(nil).Once()
+		mockTransaction.On("Update", mock.Anything, transactionId, mock.Anything).Return(nil).Once()
+		mockDisbursement.On("UpdateIfStatus", mock.Anything, disbursement.Id, models.DisbursementStatusProcessing, mock.Anything).Return(true, nil).Once()
 
 		err := service.Process(ctx, disbursement)
 
@@ -552,7 +739,9 @@ func TestPaymentService_Process(t *testing.T) {
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockLoan := new(db_test.MockLoanRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
 		mockRetryPolicy := new(MockRetryPolicy)
+		mockSchedule := new(MockScheduleService)
 		mockGatewayProvider := new(provider_test.MockGatewayProvider)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
@@ -562,15 +751,23 @@ func TestPaymentService_Process(t *testing.T) {
 			mockTransaction,
 			mockLoan,
 			mockBeneficiary,
+			mockDeadLetter,
+			newMockWebhookService(),
 			mockRetryPolicy,
+			mockSchedule,
 			mockGatewayProvider,
 			mockIdGenerator,
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
 			"https://example.com/webhook",
+			nil,
 		)
 
 		disbursement := &schema.Disbursement{
 			Id:         "DISB-123",
 			LoanId:     "LOAN-123",
+			Amount:     200000.0,
 			Status:     models.DisbursementStatusInitiated,
 			RetryCount: 0,
 			UpdatedAt:  time.Now(),
@@ -588,22 +785,23 @@ func TestPaymentService_Process(t *testing.T) {
 			Account: "1234567890",
 			IFSC:    "IFSC0001234",
 			Bank:    "Test Bank",
+			Status:  models.BeneficiaryStatusVerified,
 		}
 
 		transactionId := "TXN-123"
 		referenceId := "REF-123"
 
-		mockLoan.On("Get", ctx, "LOAN-123").Return(loan, nil).Once()
-		mockBeneficiary.On("GetById", ctx, "BEN-123").Return(beneficiary, nil).Once()
-		mockGatewayProvider.On("IsActive", ctx, models.PaymentChannelIMPS).Return(true, nil).Once()
-		mockDisbursement.On("Update", ctx, disbursement.Id, mock.MatchedBy(func(fields map[string]any) bool {
+		mockLoan.On("Get", mock.Anything, "LOAN-123").Return(loan, nil).Once()
+		mockBeneficiary.On("GetById", mock.Anything, "BEN-123").Return(beneficiary, nil).Once()
+		mockGatewayProvider.On("IsActive", mock.Anything, models.PaymentChannelIMPS).Return(true, nil).Once()
+		mockDisbursement.On("UpdateIfStatus", mock.Anything, disbursement.Id, disbursement.Status, mock.MatchedBy(func(fields map[string]any) bool {
 			return fields["channel"] == models.PaymentChannelIMPS
 		})).
-			Return(nil).
+			Return(true, nil).
 			Once()
 		mockIdGenerator.On("GenerateTransactionId").Return(transactionId).Once()
 		mockIdGenerator.On("GenerateReferenceId").Return(referenceId).Once()
-		mockTransaction.On("Create", ctx, mock.MatchedBy(func(txn schema.Transaction) bool {
+		mockTransaction.On("Create", mock.Anything, mock.MatchedBy(func(txn schema.Transaction) bool {
 			return txn.Channel == models.PaymentChannelIMPS
 		})).Return(&schema.Transaction{Id: transactionId}, nil).Once()
 
@@ -611,8 +809,8 @@ func TestPaymentService_Process(t *testing.T) {
 			Status: models.TransactionStatusSuccess,
 		}
 
-		mockGatewayProvider.On("Transfer", ctx, mock.Anything).Return(paymentResponse, nil).Once()
-		mockTransaction.On("Update", ctx, transactionId, mock.Anything).Return(nil).Once()
+		mockGatewayProvider.On("Transfer", mock.Anything, mock.Anything).Return(paymentResponse, nil).Once()
+		mockTransaction.On("Update", mock.Anything, transactionId, mock.Anything).Return(nil).Once()
 
 		err := service.Process(ctx, disbursement)
 
@@ -625,7 +823,9 @@ func TestPaymentService_Process(t *testing.T) {
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockLoan := new(db_test.MockLoanRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
 		mockRetryPolicy := new(MockRetryPolicy)
+		mockSchedule := new(MockScheduleService)
 		mockGatewayProvider := new(provider_test.MockGatewayProvider)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
@@ -635,15 +835,23 @@ func TestPaymentService_Process(t *testing.T) {
 			mockTransaction,
 			mockLoan,
 			mockBeneficiary,
+			mockDeadLetter,
+			newMockWebhookService(),
 			mockRetryPolicy,
+			mockSchedule,
 			mockGatewayProvider,
 			mockIdGenerator,
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
 			"https://example.com/webhook",
+			nil,
 		)
 
 		disbursement := &schema.Disbursement{
 			Id:         "DISB-123",
 			LoanId:     "LOAN-123",
+			Amount:     600000.0,
 			Status:     models.DisbursementStatusInitiated,
 			RetryCount: 0,
 			UpdatedAt:  time.Now(),
@@ -661,22 +869,25 @@ func TestPaymentService_Process(t *testing.T) {
 			Account: "1234567890",
 			IFSC:    "IFSC0001234",
 			Bank:    "Test Bank",
+			Status:  models.BeneficiaryStatusVerified,
 		}
 
 		transactionId := "TXN-123"
 		referenceId := "REF-123"
 
-		mockLoan.On("Get", ctx, "LOAN-123").Return(loan, nil).Once()
-		mockBeneficiary.On("GetById", ctx, "BEN-123").Return(beneficiary, nil).Once()
-		mockGatewayProvider.On("IsActive", ctx, models.PaymentChannelNEFT).Return(true, nil).Once()
-		mockDisbursement.On("Update", ctx, disbursement.Id, mock.MatchedBy(func(fields map[string]any) bool {
-			return fields["channel"] == models.PaymentChannelNEFT
+		mockLoan.On("Get", mock.Anything, "LOAN-123").Return(loan, nil).Once()
+		mockBeneficiary.On("GetById", mock.Anything, "BEN-123").Return(beneficiary, nil).Once()
+		mockGatewayProvider.On("IsActive", mock.Anything, models.PaymentChannelNEFT).Return(true, nil).Once()
+		mockDisbursement.On("UpdateIfStatus", mock.Anything, disbursement.Id, disbursement.Status, mock.MatchedBy(func(fields map[string]any) bool {
+			settlement, ok := fields["expected_settlement_at"].(*time.Time)
+			return fields["channel"] == models.PaymentChannelNEFT &&
+				ok && settlement.After(time.Now())
 		})).
-			Return(nil).
+			Return(true, nil).
 			Once()
 		mockIdGenerator.On("GenerateTransactionId").Return(transactionId).Once()
 		mockIdGenerator.On("GenerateReferenceId").Return(referenceId).Once()
-		mockTransaction.On("Create", ctx, mock.MatchedBy(func(txn schema.Transaction) bool {
+		mockTransaction.On("Create", mock.Anything, mock.MatchedBy(func(txn schema.Transaction) bool {
 			return txn.Channel == models.PaymentChannelNEFT
 		})).Return(&schema.Transaction{Id: transactionId}, nil).Once()
 
@@ -684,13 +895,214 @@ func TestPaymentService_Process(t *testing.T) {
 			Status: models.TransactionStatusSuccess,
 		}
 
-		mockGatewayProvider.On("Transfer", ctx, mock.Anything).Return(paymentResponse, nil).Once()
-		mockTransaction.On("Update", ctx, transactionId, mock.Anything).Return(nil).Once()
+		mockGatewayProvider.On("Transfer", mock.Anything, mock.Anything).Return(paymentResponse, nil).Once()
+		mockTransaction.On("Update", mock.Anything, transactionId, mock.Anything).Return(nil).Once()
+
+		err := service.Process(ctx, disbursement)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("uses forced channel over amount based selection", func(t *testing.T) {
+		mockDB := setupMockDB(t)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
+		mockGatewayProvider := new(provider_test.MockGatewayProvider)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewPaymentService(
+			mockDB,
+			mockDisbursement,
+			mockTransaction,
+			mockLoan,
+			mockBeneficiary,
+			mockDeadLetter,
+			newMockWebhookService(),
+			new(MockRetryPolicy),
+			new(MockScheduleService),
+			mockGatewayProvider,
+			mockIdGenerator,
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			"https://example.com/webhook",
+			nil,
+		)
+
+		forced := models.PaymentChannelIMPS
+		disbursement := &schema.Disbursement{
+			Id:            "DISB-123",
+			LoanId:        "LOAN-123",
+			Amount:        600000.0,
+			Status:        models.DisbursementStatusInitiated,
+			ForcedChannel: &forced,
+		}
+
+		mockLoan.On("Get", mock.Anything, "LOAN-123").
+			Return(&schema.Loan{Id: "LOAN-123", BeneficiaryId: stringPtr("BEN-123")}, nil).Once()
+		mockBeneficiary.On("GetById", mock.Anything, "BEN-123").Return(&schema.Beneficiary{Id: "BEN-123", Status: models.BeneficiaryStatusVerified}, nil).Once()
+		mockGatewayProvider.On("IsActive", mock.Anything, models.PaymentChannelIMPS).Return(true, nil).Once()
+		mockDisbursement.On("UpdateIfStatus", mock.Anything, disbursement.Id, disbursement.Status, mock.MatchedBy(func(fields map[string]any) bool {
+			return fields["channel"] == models.PaymentChannelIMPS &&
+				fields["expected_settlement_at"] == (*time.Time)(nil)
+		})).
+			Return(true, nil).
+			Once()
+		mockIdGenerator.On("GenerateTransactionId").Return("TXN-123").Once()
+		mockIdGenerator.On("GenerateReferenceId").Return("REF-123").Once()
+		mockTransaction.On("Create", mock.Anything, mock.MatchedBy(func(txn schema.Transaction) bool {
+			return txn.Channel == models.PaymentChannelIMPS
+		})).Return(&schema.Transaction{Id: "TXN-123"}, nil).Once()
+		mockGatewayProvider.On("Transfer", mock.Anything, mock.Anything).
+			Return(models.PaymentResponse{Status: models.TransactionStatusSuccess}, nil).Once()
+		mockTransaction.On("Update", mock.Anything, "TXN-123", mock.Anything).Return(nil).Once()
+
+		err := service.Process(ctx, disbursement)
+
+		assert.NoError(t, err)
+		mockDisbursement.AssertExpectations(t)
+	})
+
+	t.Run("skips disbursement another worker claimed first", func(t *testing.T) {
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockGatewayProvider := new(provider_test.MockGatewayProvider)
+
+		service := NewPaymentService(
+			setupMockDB(t),
+			mockDisbursement,
+			mockTransaction,
+			mockLoan,
+			mockBeneficiary,
+			new(db_test.MockDeadLetterRepository),
+			newMockWebhookService(),
+			new(MockRetryPolicy),
+			new(MockScheduleService),
+			mockGatewayProvider,
+			new(utils_test.MockIdGenerator),
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			"https://example.com/webhook",
+			nil,
+		)
+
+		disbursement := &schema.Disbursement{
+			Id:     "DISB-123",
+			LoanId: "LOAN-123",
+			Amount: 50000.0,
+			Status: models.DisbursementStatusInitiated,
+		}
+
+		mockLoan.On("Get", mock.Anything, "LOAN-123").
+			Return(&schema.Loan{Id: "LOAN-123", BeneficiaryId: stringPtr("BEN-123")}, nil).Once()
+		mockBeneficiary.On("GetById", mock.Anything, "BEN-123").
+			Return(&schema.Beneficiary{Id: "BEN-123", Status: models.BeneficiaryStatusVerified}, nil).Once()
+		mockDisbursement.On("UpdateIfStatus", mock.Anything, disbursement.Id, models.DisbursementStatusInitiated,
+			mock.Anything).Return(false, nil).Once()
 
 		err := service.Process(ctx, disbursement)
 
 		assert.NoError(t, err)
+		mockGatewayProvider.AssertNotCalled(t, "Transfer", mock.Anything, mock.Anything)
+		mockTransaction.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
 	})
+
+	t.Run("fails without sending when the beneficiary is no longer payable", func(t *testing.T) {
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
+		mockGatewayProvider := new(provider_test.MockGatewayProvider)
+
+		service := NewPaymentService(
+			setupMockDB(t),
+			mockDisbursement,
+			mockTransaction,
+			mockLoan,
+			mockBeneficiary,
+			mockDeadLetter,
+			newMockWebhookService(),
+			new(MockRetryPolicy),
+			new(MockScheduleService),
+			mockGatewayProvider,
+			new(utils_test.MockIdGenerator),
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			"https://example.com/webhook",
+			nil,
+		)
+
+		disbursement := &schema.Disbursement{
+			Id:      "DISB-123",
+			LoanId:  "LOAN-123",
+			Amount:  50000.0,
+			Channel: models.PaymentChannelUPI,
+			Status:  models.DisbursementStatusInitiated,
+		}
+		loan := &schema.Loan{
+			Id:            "LOAN-123",
+			Status:        models.LoanStatusDisbursementPending,
+			BeneficiaryId: stringPtr("BEN-123"),
+		}
+
+		mockLoan.On("Get", mock.Anything, "LOAN-123").Return(loan, nil).Twice()
+		mockBeneficiary.On("GetById", mock.Anything, "BEN-123").
+			Return(&schema.Beneficiary{Id: "BEN-123", Status: models.BeneficiaryStatusUnverified}, nil).Once()
+		mockDisbursement.On("UpdateIfStatus", mock.Anything, disbursement.Id, models.DisbursementStatusInitiated,
+			mock.MatchedBy(func(fields map[string]any) bool {
+				return fields["status"] == models.DisbursementStatusFailed &&
+					fields["last_error"] == models.BENEFICIARY_NOT_VERIFIED.Error()
+			})).Return(true, nil).Once()
+		mockLoan.On("UpdateIfStatus", mock.Anything, "LOAN-123", models.LoanStatusDisbursementPending,
+			map[string]any{"status": models.LoanStatusSanctioned}).Return(true, nil).Once()
+		mockDeadLetter.On("Record", mock.Anything, mock.MatchedBy(func(entry schema.DeadLetter) bool {
+			return entry.DisbursementId == "DISB-123" && entry.Category == models.FailureCategoryBeneficiary
+		})).Return(nil).Once()
+
+		err := service.Process(ctx, disbursement)
+
+		assert.NoError(t, err)
+		mockDisbursement.AssertExpectations(t)
+		mockLoan.AssertExpectations(t)
+		mockDeadLetter.AssertExpectations(t)
+		mockGatewayProvider.AssertNotCalled(t, "Transfer", mock.Anything, mock.Anything)
+		mockTransaction.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestPaymentService_SelectChannel(t *testing.T) {
+	settings := config.Default()
+	settings.Channels = config.Channels{UPILimit: 50000, IMPSLimit: 200000}
+	service := PaymentServiceImpl{settings: config.NewStore("", settings)}
+	beneficiary := &schema.Beneficiary{IFSC: "HDFC0001234"}
+
+	tests := []struct {
+		name       string
+		amount     float64
+		retryCount int
+		want       models.PaymentChannel
+	}{
+		{"UPI up to its configured limit", 50000, 0, models.PaymentChannelUPI},
+		{"IMPS above the UPI limit", 50001, 0, models.PaymentChannelIMPS},
+		{"NEFT above the IMPS limit", 200001, 0, models.PaymentChannelNEFT},
+		{"IMPS on the second retry", 40000, 2, models.PaymentChannelIMPS},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			disbursement := &schema.Disbursement{Amount: tt.amount, RetryCount: tt.retryCount}
+
+			assert.Equal(t, tt.want, service.selectChannel(disbursement, beneficiary))
+		})
+	}
 }
 
 func TestPaymentService_HandleNotification(t *testing.T) {
@@ -702,7 +1114,9 @@ func TestPaymentService_HandleNotification(t *testing.T) {
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockLoan := new(db_test.MockLoanRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
 		mockRetryPolicy := new(MockRetryPolicy)
+		mockSchedule := new(MockScheduleService)
 		mockGatewayProvider := new(provider_test.MockGatewayProvider)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
@@ -712,16 +1126,24 @@ func TestPaymentService_HandleNotification(t *testing.T) {
 			mockTransaction,
 			mockLoan,
 			mockBeneficiary,
+			mockDeadLetter,
+			newMockWebhookService(),
 			mockRetryPolicy,
+			mockSchedule,
 			mockGatewayProvider,
 			mockIdGenerator,
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
 			"https://example.com/webhook",
+			nil,
 		)
 
 		notification := models.PaymentNotificationRequest{
 			ReferenceID: "REF-123",
 			Status:      models.TransactionStatusSuccess,
 			Channel:     models.PaymentChannelUPI,
+			ProcessedAt: time.Date(2026, time.March, 10, 9, 0, 0, 0, time.UTC),
 		}
 
 		transaction := &schema.Transaction{
@@ -731,20 +1153,42 @@ func TestPaymentService_HandleNotification(t *testing.T) {
 		}
 
 		disbursement := &schema.Disbursement{
-			Id: "DISB-123",
+			Id:     "DISB-123",
+			LoanId: "LOAN-123",
+			Amount: 50000.0,
+			Status: models.DisbursementStatusProcessing,
 		}
 
-		mockTransaction.On("GetByReferenceID", ctx, "REF-123").Return(transaction, nil).Once()
-		mockDisbursement.On("Get", ctx, "DISB-123").Return(disbursement, nil).Once()
-		mockTransaction.On("Update", ctx, transaction.Id, mock.MatchedBy(func(fields map[string]any) bool {
+		mockTransaction.On("GetByReferenceID", mock.Anything, "REF-123").Return(transaction, nil).Once()
+		mockDisbursement.On("Get", mock.Anything, "DISB-123").Return(disbursement, nil).Once()
+		mockTransaction.On("Update", mock.Anything, transaction.Id, mock.MatchedBy(func(fields map[string]any) bool {
 			return fields["status"] == models.TransactionStatusSuccess
 		})).
 			Return(nil).
 			Once()
-		mockDisbursement.On("Update", ctx, disbursement.Id, mock.MatchedBy(func(fields map[string]any) bool {
-			return fields["status"] == models.DisbursementStatusSuccess &&
-				fields["channel"] == notification.Channel
-		})).
+		mockDisbursement.On("UpdateIfStatus", mock.Anything, disbursement.Id, models.DisbursementStatusProcessing,
+			mock.MatchedBy(func(fields map[string]any) bool {
+				return fields["status"] == models.DisbursementStatusSuccess &&
+					fields["channel"] == notification.Channel
+			}),
+		).
+			Return(true, nil).
+			Once()
+		mockLoan.On("Get", mock.Anything, "LOAN-123").
+			Return(&schema.Loan{
+				Id:     "LOAN-123",
+				Amount: 50000.0,
+				Status: models.LoanStatusDisbursementPending,
+			}, nil).
+			Once()
+		mockLoan.On("UpdateIfStatus", mock.Anything, "LOAN-123", models.LoanStatusDisbursementPending, mock.Anything).
+			Return(true, nil).
+			Once()
+
+		mockSchedule.On("Generate", mock.Anything, mock.Anything, disbursement, notification.ProcessedAt).
+			Return(nil).
+			Once()
+		mockDisbursement.On("Update", mock.Anything, "DISB-123", map[string]any{"schedule_pending": false}).
 			Return(nil).
 			Once()
 
@@ -761,7 +1205,9 @@ func TestPaymentService_HandleNotification(t *testing.T) {
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockLoan := new(db_test.MockLoanRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
 		mockRetryPolicy := new(MockRetryPolicy)
+		mockSchedule := new(MockScheduleService)
 		mockGatewayProvider := new(provider_test.MockGatewayProvider)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
@@ -771,10 +1217,17 @@ func TestPaymentService_HandleNotification(t *testing.T) {
 			mockTransaction,
 			mockLoan,
 			mockBeneficiary,
+			mockDeadLetter,
+			newMockWebhookService(),
 			mockRetryPolicy,
+			mockSchedule,
 			mockGatewayProvider,
 			mockIdGenerator,
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
 			"https://example.com/webhook",
+			nil,
 		)
 
 		notification := models.PaymentNotificationRequest{
@@ -792,19 +1245,34 @@ func TestPaymentService_HandleNotification(t *testing.T) {
 
 		disbursement := &schema.Disbursement{
 			Id:         "DISB-123",
+			LoanId:     "LOAN-123",
 			RetryCount: 0,
 		}
 
-		mockTransaction.On("GetByReferenceID", ctx, "REF-123").Return(transaction, nil).Once()
-		mockDisbursement.On("Get", ctx, "DISB-123").Return(disbursement, nil).Once()
-		mockTransaction.On("Update", ctx, transaction.Id, mock.Anything).Return(nil).Once()
-		mockDisbursement.On("Update", ctx, disbursement.Id, mock.Anything).Return(nil).Once()
+		mockTransaction.On("GetByReferenceID", mock.Anything, "REF-123").Return(transaction, nil).Once()
+		mockDisbursement.On("Get", mock.Anything, "DISB-123").Return(disbursement, nil).Once()
+		mockTransaction.On("Update", mock.Anything, transaction.Id, mock.Anything).Return(nil).Once()
+		mockDisbursement.On("UpdateIfStatus", mock.Anything, disbursement.Id, disbursement.Status, mock.Anything).Return(true, nil).Once()
+		mockLoan.On("Get", mock.Anything, "LOAN-123").
+			Return(&schema.Loan{Id: "LOAN-123", Status: models.LoanStatusDisbursementPending}, nil).
+			Once()
+		mockLoan.On("UpdateIfStatus", mock.Anything, "LOAN-123", models.LoanStatusDisbursementPending, map[string]any{"status": models.LoanStatusSanctioned}).
+			Return(true, nil).
+			Once()
+		mockDeadLetter.On("Record", mock.Anything, mock.MatchedBy(func(entry schema.DeadLetter) bool {
+			return entry.DisbursementId == "DISB-123" &&
+				entry.LoanId == "LOAN-123" &&
+				entry.Channel == models.PaymentChannelUPI &&
+				entry.Category == models.FailureCategoryUnknown &&
+				entry.LastError == "Payment failed"
+		})).Return(nil).Once()
 
 		err := service.HandleNotification(ctx, notification)
 
 		assert.NoError(t, err)
 		mockTransaction.AssertExpectations(t)
 		mockDisbursement.AssertExpectations(t)
+		mockDeadLetter.AssertExpectations(t)
 	})
 
 	t.Run("returns error when transaction not found", func(t *testing.T) {
@@ -813,7 +1281,9 @@ func TestPaymentService_HandleNotification(t *testing.T) {
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockLoan := new(db_test.MockLoanRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
 		mockRetryPolicy := new(MockRetryPolicy)
+		mockSchedule := new(MockScheduleService)
 		mockGatewayProvider := new(provider_test.MockGatewayProvider)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
@@ -823,10 +1293,17 @@ func TestPaymentService_HandleNotification(t *testing.T) {
 			mockTransaction,
 			mockLoan,
 			mockBeneficiary,
+			mockDeadLetter,
+			newMockWebhookService(),
 			mockRetryPolicy,
+			mockSchedule,
 			mockGatewayProvider,
 			mockIdGenerator,
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
 			"https://example.com/webhook",
+			nil,
 		)
 
 		notification := models.PaymentNotificationRequest{
@@ -834,7 +1311,7 @@ func TestPaymentService_HandleNotification(t *testing.T) {
 			Status:      models.TransactionStatusSuccess,
 		}
 
-		mockTransaction.On("GetByReferenceID", ctx, "REF-123").
+		mockTransaction.On("GetByReferenceID", mock.Anything, "REF-123").
 			Return(nil, errors.New("not found")).
 			Once()
 
@@ -850,7 +1327,9 @@ func TestPaymentService_HandleNotification(t *testing.T) {
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockLoan := new(db_test.MockLoanRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
 		mockRetryPolicy := new(MockRetryPolicy)
+		mockSchedule := new(MockScheduleService)
 		mockGatewayProvider := new(provider_test.MockGatewayProvider)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
@@ -860,10 +1339,17 @@ func TestPaymentService_HandleNotification(t *testing.T) {
 			mockTransaction,
 			mockLoan,
 			mockBeneficiary,
+			mockDeadLetter,
+			newMockWebhookService(),
 			mockRetryPolicy,
+			mockSchedule,
 			mockGatewayProvider,
 			mockIdGenerator,
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
 			"https://example.com/webhook",
+			nil,
 		)
 
 		notification := models.PaymentNotificationRequest{
@@ -877,8 +1363,8 @@ func TestPaymentService_HandleNotification(t *testing.T) {
 			ReferenceId:    "REF-123",
 		}
 
-		mockTransaction.On("GetByReferenceID", ctx, "REF-123").Return(transaction, nil).Once()
-		mockDisbursement.On("Get", ctx, "DISB-123").Return(nil, errors.New("not found")).Once()
+		mockTransaction.On("GetByReferenceID", mock.Anything, "REF-123").Return(transaction, nil).Once()
+		mockDisbursement.On("Get", mock.Anything, "DISB-123").Return(nil, errors.New("not found")).Once()
 
 		err := service.HandleNotification(ctx, notification)
 
@@ -898,7 +1384,9 @@ func TestPaymentService_HandleFailure(t *testing.T) {
 			mockTransaction := new(db_test.MockTransactionRepository)
 			mockLoan := new(db_test.MockLoanRepository)
 			mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+			mockDeadLetter := new(db_test.MockDeadLetterRepository)
 			mockRetryPolicy := new(MockRetryPolicy)
+			mockSchedule := new(MockScheduleService)
 			mockGatewayProvider := new(provider_test.MockGatewayProvider)
 			mockIdGenerator := new(utils_test.MockIdGenerator)
 
@@ -908,10 +1396,17 @@ func TestPaymentService_HandleFailure(t *testing.T) {
 				mockTransaction,
 				mockLoan,
 				mockBeneficiary,
+				mockDeadLetter,
+				newMockWebhookService(),
 				mockRetryPolicy,
+				mockSchedule,
 				mockGatewayProvider,
 				mockIdGenerator,
+				calendar.Default(),
+				nil,
+				events.NewBus(events.DefaultHistorySize),
 				"https://example.com/webhook",
+				nil,
 			)
 
 			disbursement := &schema.Disbursement{
@@ -958,7 +1453,9 @@ func TestPaymentService_HandleFailure(t *testing.T) {
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockLoan := new(db_test.MockLoanRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
 		mockRetryPolicy := new(MockRetryPolicy)
+		mockSchedule := new(MockScheduleService)
 		mockGatewayProvider := new(provider_test.MockGatewayProvider)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
@@ -968,10 +1465,17 @@ func TestPaymentService_HandleFailure(t *testing.T) {
 			mockTransaction,
 			mockLoan,
 			mockBeneficiary,
+			mockDeadLetter,
+			newMockWebhookService(),
 			mockRetryPolicy,
+			mockSchedule,
 			mockGatewayProvider,
 			mockIdGenerator,
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
 			"https://example.com/webhook",
+			nil,
 		)
 
 		disbursement := &schema.Disbursement{
@@ -1009,7 +1513,9 @@ func TestPaymentService_HandleFailure(t *testing.T) {
 			mockTransaction := new(db_test.MockTransactionRepository)
 			mockLoan := new(db_test.MockLoanRepository)
 			mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+			mockDeadLetter := new(db_test.MockDeadLetterRepository)
 			mockRetryPolicy := new(MockRetryPolicy)
+			mockSchedule := new(MockScheduleService)
 			mockGatewayProvider := new(provider_test.MockGatewayProvider)
 			mockIdGenerator := new(utils_test.MockIdGenerator)
 
@@ -1019,10 +1525,17 @@ func TestPaymentService_HandleFailure(t *testing.T) {
 				mockTransaction,
 				mockLoan,
 				mockBeneficiary,
+				mockDeadLetter,
+				newMockWebhookService(),
 				mockRetryPolicy,
+				mockSchedule,
 				mockGatewayProvider,
 				mockIdGenerator,
+				calendar.Default(),
+				nil,
+				events.NewBus(events.DefaultHistorySize),
 				"https://example.com/webhook",
+				nil,
 			)
 
 			disbursement := &schema.Disbursement{
@@ -1045,11 +1558,11 @@ func TestPaymentService_HandleFailure(t *testing.T) {
 				Return(paymentResponse, nil).
 				Once()
 			mockTransaction.On("Update", ctx, transaction.Id, mock.Anything).Return(nil).Once()
-			mockDisbursement.On("Update", ctx, disbursement.Id, mock.MatchedBy(func(fields map[string]any) bool {
+			mockDisbursement.On("UpdateIfStatus", ctx, disbursement.Id, disbursement.Status, mock.MatchedBy(func(fields map[string]any) bool {
 				return fields["status"] == models.DisbursementStatusSuspended &&
 					fields["retry_count"] == 1
 			})).
-				Return(nil).
+				Return(true, nil).
 				Once()
 
 			err := service.HandleFailure(
@@ -1073,9 +1586,14 @@ func TestPaymentService_HandleFailure(t *testing.T) {
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockLoan := new(db_test.MockLoanRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
 		mockRetryPolicy := new(MockRetryPolicy)
+		mockSchedule := new(MockScheduleService)
 		mockGatewayProvider := new(provider_test.MockGatewayProvider)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
+		mockWebhook := new(MockWebhookService)
+		bus := events.NewBus(events.DefaultHistorySize)
+		subscription, _, _, _ := bus.Subscribe(events.Filter{DisbursementId: "DISB-123"}, 0)
 
 		service := NewPaymentService(
 			mockDB,
@@ -1083,10 +1601,17 @@ func TestPaymentService_HandleFailure(t *testing.T) {
 			mockTransaction,
 			mockLoan,
 			mockBeneficiary,
+			mockDeadLetter,
+			mockWebhook,
 			mockRetryPolicy,
+			mockSchedule,
 			mockGatewayProvider,
 			mockIdGenerator,
+			calendar.Default(),
+			nil,
+			bus,
 			"https://example.com/webhook",
+			nil,
 		)
 
 		disbursement := &schema.Disbursement{
@@ -1101,12 +1626,13 @@ func TestPaymentService_HandleFailure(t *testing.T) {
 		}
 
 		mockTransaction.On("Update", ctx, transaction.Id, mock.Anything).Return(nil).Once()
-		mockDisbursement.On("Update", ctx, disbursement.Id, mock.MatchedBy(func(fields map[string]any) bool {
+		mockDisbursement.On("UpdateIfStatus", ctx, disbursement.Id, disbursement.Status, mock.MatchedBy(func(fields map[string]any) bool {
 			return fields["status"] == models.DisbursementStatusSuspended &&
 				fields["retry_count"] == 1
 		})).
-			Return(nil).
+			Return(true, nil).
 			Once()
+		mockWebhook.On("Publish", ctx, models.WebhookEventSuspended, "DISB-123").Return(nil).Once()
 
 		err := service.HandleFailure(
 			ctx,
@@ -1119,6 +1645,13 @@ func TestPaymentService_HandleFailure(t *testing.T) {
 		assert.NoError(t, err)
 		mockTransaction.AssertExpectations(t)
 		mockDisbursement.AssertExpectations(t)
+		mockWebhook.AssertExpectations(t)
+
+		event := <-subscription.Events()
+		assert.Equal(t, events.TypeStatus, event.Type)
+		assert.Equal(t, models.DisbursementStatusSuspended, event.Status)
+		assert.Equal(t, 1, event.RetryCount)
+		assert.Equal(t, models.NETWORK_ERROR.Error(), event.Error)
 	})
 
 	t.Run("handles permanent failure and marks as failed", func(t *testing.T) {
@@ -1127,7 +1660,9 @@ func TestPaymentService_HandleFailure(t *testing.T) {
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockLoan := new(db_test.MockLoanRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
 		mockRetryPolicy := new(MockRetryPolicy)
+		mockSchedule := new(MockScheduleService)
 		mockGatewayProvider := new(provider_test.MockGatewayProvider)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
@@ -1137,14 +1672,22 @@ func TestPaymentService_HandleFailure(t *testing.T) {
 			mockTransaction,
 			mockLoan,
 			mockBeneficiary,
+			mockDeadLetter,
+			newMockWebhookService(),
 			mockRetryPolicy,
+			mockSchedule,
 			mockGatewayProvider,
 			mockIdGenerator,
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
 			"https://example.com/webhook",
+			nil,
 		)
 
 		disbursement := &schema.Disbursement{
 			Id:         "DISB-123",
+			LoanId:     "LOAN-123",
 			RetryCount: 0,
 		}
 
@@ -1157,13 +1700,25 @@ func TestPaymentService_HandleFailure(t *testing.T) {
 		permanentError := errors.New("invalid IFSC code")
 
 		mockTransaction.On("Update", ctx, transaction.Id, mock.Anything).Return(nil).Once()
-		mockDisbursement.On("Update", ctx, disbursement.Id, mock.MatchedBy(func(fields map[string]any) bool {
+		mockDisbursement.On("UpdateIfStatus", ctx, disbursement.Id, disbursement.Status, mock.MatchedBy(func(fields map[string]any) bool {
 			return fields["status"] == models.DisbursementStatusFailed &&
 				fields["retry_count"] == 1
 		})).
-			Return(nil).
+			Return(true, nil).
 			Once()
 
+		mockLoan.On("Get", ctx, "LOAN-123").
+			Return(&schema.Loan{Id: "LOAN-123", Status: models.LoanStatusDisbursementPending}, nil).
+			Once()
+		mockLoan.On("UpdateIfStatus", ctx, "LOAN-123", models.LoanStatusDisbursementPending, map[string]any{"status": models.LoanStatusSanctioned}).
+			Return(true, nil).
+			Once()
+		mockDeadLetter.On("Record", ctx, mock.MatchedBy(func(entry schema.DeadLetter) bool {
+			return entry.DisbursementId == "DISB-123" &&
+				entry.Category == models.FailureCategoryInvalidIFSC &&
+				entry.Status == models.DeadLetterStatusOpen
+		})).Return(nil).Once()
+
 		err := service.HandleFailure(
 			ctx,
 			disbursement,
@@ -1175,6 +1730,8 @@ func TestPaymentService_HandleFailure(t *testing.T) {
 		assert.NoError(t, err)
 		mockTransaction.AssertExpectations(t)
 		mockDisbursement.AssertExpectations(t)
+		mockLoan.AssertExpectations(t)
+		mockDeadLetter.AssertExpectations(t)
 	})
 
 	t.Run("marks as failed when retry count exceeds max retries", func(t *testing.T) {
@@ -1183,7 +1740,9 @@ func TestPaymentService_HandleFailure(t *testing.T) {
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockLoan := new(db_test.MockLoanRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
 		mockRetryPolicy := new(MockRetryPolicy)
+		mockSchedule := new(MockScheduleService)
 		mockGatewayProvider := new(provider_test.MockGatewayProvider)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
@@ -1193,15 +1752,23 @@ func TestPaymentService_HandleFailure(t *testing.T) {
 			mockTransaction,
 			mockLoan,
 			mockBeneficiary,
+			mockDeadLetter,
+			newMockWebhookService(),
 			mockRetryPolicy,
+			mockSchedule,
 			mockGatewayProvider,
 			mockIdGenerator,
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
 			"https://example.com/webhook",
+			nil,
 		)
 
 		disbursement := &schema.Disbursement{
 			Id:         "DISB-123",
-			RetryCount: MaxRetries,
+			LoanId:     "LOAN-123",
+			RetryCount: defaultRetry.MaxRetries,
 		}
 
 		transaction := &schema.Transaction{
@@ -1211,12 +1778,23 @@ func TestPaymentService_HandleFailure(t *testing.T) {
 		}
 
 		mockTransaction.On("Update", ctx, transaction.Id, mock.Anything).Return(nil).Once()
-		mockDisbursement.On("Update", ctx, disbursement.Id, mock.MatchedBy(func(fields map[string]any) bool {
+		mockDisbursement.On("UpdateIfStatus", ctx, disbursement.Id, disbursement.Status, mock.MatchedBy(func(fields map[string]any) bool {
 			return fields["status"] == models.DisbursementStatusFailed &&
-				fields["retry_count"] == MaxRetries
+				fields["retry_count"] == defaultRetry.MaxRetries
 		})).
-			Return(nil).
+			Return(true, nil).
+			Once()
+
+		mockLoan.On("Get", ctx, "LOAN-123").
+			Return(&schema.Loan{Id: "LOAN-123", Status: models.LoanStatusDisbursementPending, DisbursedAmount: 20000.0}, nil).
+			Once()
+		mockLoan.On("UpdateIfStatus", ctx, "LOAN-123", models.LoanStatusDisbursementPending, map[string]any{"status": models.LoanStatusPartiallyDisbursed}).
+			Return(true, nil).
 			Once()
+		mockDeadLetter.On("Record", ctx, mock.MatchedBy(func(entry schema.DeadLetter) bool {
+			return entry.DisbursementId == "DISB-123" &&
+				entry.Category == models.FailureCategoryNetwork
+		})).Return(nil).Once()
 
 		err := service.HandleFailure(
 			ctx,
@@ -1229,6 +1807,73 @@ func TestPaymentService_HandleFailure(t *testing.T) {
 		assert.NoError(t, err)
 		mockTransaction.AssertExpectations(t)
 		mockDisbursement.AssertExpectations(t)
+		mockLoan.AssertExpectations(t)
+		mockDeadLetter.AssertExpectations(t)
+	})
+
+	t.Run("leaves the loan alone when the disbursement has moved on", func(t *testing.T) {
+		mockDB := setupMockDB(t)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
+		mockRetryPolicy := new(MockRetryPolicy)
+		mockSchedule := new(MockScheduleService)
+		mockGatewayProvider := new(provider_test.MockGatewayProvider)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+		webhook := newMockWebhookService()
+
+		service := NewPaymentService(
+			mockDB,
+			mockDisbursement,
+			mockTransaction,
+			mockLoan,
+			mockBeneficiary,
+			mockDeadLetter,
+			webhook,
+			mockRetryPolicy,
+			mockSchedule,
+			mockGatewayProvider,
+			mockIdGenerator,
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			"https://example.com/webhook",
+			nil,
+		)
+
+		disbursement := &schema.Disbursement{
+			Id:     "DISB-123",
+			LoanId: "LOAN-123",
+			Status: models.DisbursementStatusProcessing,
+		}
+
+		transaction := &schema.Transaction{
+			Id:          "TXN-123",
+			ReferenceId: "REF-123",
+			Channel:     models.PaymentChannelUPI,
+		}
+
+		mockTransaction.On("Update", ctx, transaction.Id, mock.Anything).Return(nil).Once()
+		mockDisbursement.On("UpdateIfStatus", ctx, disbursement.Id, models.DisbursementStatusProcessing, mock.Anything).
+			Return(false, nil).
+			Once()
+
+		err := service.HandleFailure(
+			ctx,
+			disbursement,
+			transaction,
+			models.PaymentChannelUPI,
+			errors.New("invalid IFSC code"),
+		)
+
+		assert.ErrorIs(t, err, models.DISBURSEMENT_STATUS_CHANGED)
+		mockTransaction.AssertExpectations(t)
+		mockDisbursement.AssertExpectations(t)
+		mockLoan.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+		mockDeadLetter.AssertNotCalled(t, "Record", mock.Anything, mock.Anything)
+		webhook.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything, mock.Anything)
 	})
 }
 
@@ -1241,9 +1886,12 @@ func TestPaymentService_HandleSuccess(t *testing.T) {
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockLoan := new(db_test.MockLoanRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
 		mockRetryPolicy := new(MockRetryPolicy)
+		mockSchedule := new(MockScheduleService)
 		mockGatewayProvider := new(provider_test.MockGatewayProvider)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
+		mockWebhook := new(MockWebhookService)
 
 		service := NewPaymentService(
 			mockDB,
@@ -1251,33 +1899,429 @@ func TestPaymentService_HandleSuccess(t *testing.T) {
 			mockTransaction,
 			mockLoan,
 			mockBeneficiary,
+			mockDeadLetter,
+			mockWebhook,
 			mockRetryPolicy,
+			mockSchedule,
 			mockGatewayProvider,
 			mockIdGenerator,
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
 			"https://example.com/webhook",
+			nil,
 		)
 
-		disbursementId := "DISB-123"
+		disbursement := &schema.Disbursement{
+			Id:     "DISB-123",
+			LoanId: "LOAN-123",
+			Amount: 50000.0,
+			Status: models.DisbursementStatusProcessing,
+		}
 		transactionId := "TXN-123"
 		channel := models.PaymentChannelUPI
+		settledAt := time.Date(2026, time.March, 10, 9, 0, 0, 0, time.UTC)
 
 		mockTransaction.On("Update", ctx, transactionId, mock.MatchedBy(func(fields map[string]any) bool {
 			return fields["status"] == models.TransactionStatusSuccess
 		})).
 			Return(nil).
 			Once()
-		mockDisbursement.On("Update", ctx, disbursementId, mock.MatchedBy(func(fields map[string]any) bool {
-			return fields["status"] == models.DisbursementStatusSuccess &&
-				fields["channel"] == channel
-		})).
+		mockDisbursement.On("UpdateIfStatus", ctx, disbursement.Id, models.DisbursementStatusProcessing,
+			mock.MatchedBy(func(fields map[string]any) bool {
+				return fields["status"] == models.DisbursementStatusSuccess &&
+					fields["channel"] == channel &&
+					fields["settled_at"] == settledAt &&
+					fields["schedule_pending"] == true
+			}),
+		).
+			Return(true, nil).
+			Once()
+		mockLoan.On("Get", ctx, "LOAN-123").
+			Return(&schema.Loan{
+				Id:     "LOAN-123",
+				Amount: 50000.0,
+				Status: models.LoanStatusDisbursementPending,
+			}, nil).
+			Once()
+		mockLoan.On("UpdateIfStatus", ctx, "LOAN-123", models.LoanStatusDisbursementPending, map[string]any{
+			"status":           models.LoanStatusDisbursed,
+			"disbursed_amount": 50000.0,
+		}).
+			Return(true, nil).
+			Once()
+
+		mockSchedule.On("Generate", ctx, mock.Anything, disbursement, settledAt).
+			Return(nil).
+			Once()
+		mockDisbursement.On("Update", mock.Anything, "DISB-123", map[string]any{"schedule_pending": false}).
 			Return(nil).
 			Once()
+		mockWebhook.On("Publish", ctx, models.WebhookEventSuccess, "DISB-123").Return(nil).Once()
 
-		err := service.HandleSuccess(ctx, disbursementId, transactionId, channel)
+		err := service.HandleSuccess(ctx, disbursement, transactionId, channel, settledAt)
 
 		assert.NoError(t, err)
 		mockTransaction.AssertExpectations(t)
 		mockDisbursement.AssertExpectations(t)
+		mockLoan.AssertExpectations(t)
+		mockSchedule.AssertExpectations(t)
+		mockWebhook.AssertExpectations(t)
+	})
+
+	t.Run("rolls the settlement back when the webhook event cannot be queued", func(t *testing.T) {
+		mockDB := setupMockDB(t)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockSchedule := new(MockScheduleService)
+		mockWebhook := new(MockWebhookService)
+
+		service := NewPaymentService(
+			mockDB,
+			mockDisbursement,
+			mockTransaction,
+			mockLoan,
+			new(db_test.MockBeneficiaryRepository),
+			new(db_test.MockDeadLetterRepository),
+			mockWebhook,
+			new(MockRetryPolicy),
+			mockSchedule,
+			new(provider_test.MockGatewayProvider),
+			new(utils_test.MockIdGenerator),
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			"https://example.com/webhook",
+			nil,
+		)
+
+		disbursement := &schema.Disbursement{
+			Id:     "DISB-123",
+			LoanId: "LOAN-123",
+			Amount: 50000.0,
+			Status: models.DisbursementStatusProcessing,
+		}
+		mockTransaction.On("Update", ctx, "TXN-123", mock.Anything).Return(nil).Once()
+		mockDisbursement.On("UpdateIfStatus", ctx, "DISB-123", models.DisbursementStatusProcessing, mock.Anything).
+			Return(true, nil).Once()
+		mockLoan.On("Get", ctx, "LOAN-123").Return(&schema.Loan{
+			Id:     "LOAN-123",
+			Amount: 50000.0,
+			Status: models.LoanStatusDisbursementPending,
+		}, nil).Once()
+		mockLoan.On("UpdateIfStatus", ctx, "LOAN-123", models.LoanStatusDisbursementPending, mock.Anything).
+			Return(true, nil).Once()
+		mockWebhook.On("Publish", ctx, models.WebhookEventSuccess, "DISB-123").
+			Return(errors.New("database error")).Once()
+
+		err := service.HandleSuccess(ctx, disbursement, "TXN-123", models.PaymentChannelUPI, time.Now())
+
+		assert.ErrorContains(t, err, "failed to queue webhook event")
+		mockSchedule.AssertNotCalled(t, "Generate")
+		mockWebhook.AssertExpectations(t)
+	})
+
+	t.Run("reserves the loan and closes the dead letter of a failed disbursement", func(t *testing.T) {
+		mockDB := setupMockDB(t)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
+		mockRetryPolicy := new(MockRetryPolicy)
+		mockSchedule := new(MockScheduleService)
+		mockGatewayProvider := new(provider_test.MockGatewayProvider)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+		mockWebhook := new(MockWebhookService)
+
+		service := NewPaymentService(
+			mockDB,
+			mockDisbursement,
+			mockTransaction,
+			mockLoan,
+			mockBeneficiary,
+			mockDeadLetter,
+			mockWebhook,
+			mockRetryPolicy,
+			mockSchedule,
+			mockGatewayProvider,
+			mockIdGenerator,
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			"https://example.com/webhook",
+			nil,
+		)
+
+		disbursement := &schema.Disbursement{
+			Id:     "DISB-123",
+			LoanId: "LOAN-123",
+			Amount: 50000.0,
+			Status: models.DisbursementStatusFailed,
+		}
+		settledAt := time.Date(2026, time.March, 10, 9, 0, 0, 0, time.UTC)
+
+		mockTransaction.On("Update", ctx, "TXN-123", mock.Anything).Return(nil).Once()
+		mockDisbursement.On("UpdateIfStatus", ctx, "DISB-123", models.DisbursementStatusFailed, mock.Anything).
+			Return(true, nil).
+			Once()
+		mockLoan.On("Get", ctx, "LOAN-123").
+			Return(&schema.Loan{Id: "LOAN-123", Amount: 50000.0, Status: models.LoanStatusSanctioned}, nil).
+			Once()
+		mockLoan.On("UpdateIfStatus", ctx, "LOAN-123", models.LoanStatusSanctioned, map[string]any{
+			"status": models.LoanStatusDisbursementPending,
+		}).
+			Return(true, nil).
+			Once()
+		mockLoan.On("Get", ctx, "LOAN-123").
+			Return(&schema.Loan{Id: "LOAN-123", Amount: 50000.0, Status: models.LoanStatusDisbursementPending}, nil).
+			Once()
+		mockLoan.On("UpdateIfStatus", ctx, "LOAN-123", models.LoanStatusDisbursementPending, map[string]any{
+			"status":           models.LoanStatusDisbursed,
+			"disbursed_amount": 50000.0,
+		}).
+			Return(true, nil).
+			Once()
+		mockDeadLetter.On("UpdateIfStatus", ctx, "DISB-123", models.DeadLetterStatusOpen,
+			mock.MatchedBy(func(fields map[string]any) bool {
+				return fields["status"] == models.DeadLetterStatusClosed
+			}),
+		).Return(true, nil).Once()
+		mockSchedule.On("Generate", ctx, mock.Anything, disbursement, settledAt).Return(nil).Once()
+		mockDisbursement.On("Update", mock.Anything, "DISB-123", map[string]any{"schedule_pending": false}).
+			Return(nil).
+			Once()
+		mockWebhook.On("Publish", ctx, models.WebhookEventSuccess, "DISB-123").Return(nil).Once()
+
+		err := service.HandleSuccess(ctx, disbursement, "TXN-123", models.PaymentChannelIMPS, settledAt)
+
+		assert.NoError(t, err)
+		mockLoan.AssertExpectations(t)
+		mockDeadLetter.AssertExpectations(t)
+	})
+
+	t.Run("marks loan partially disbursed after first tranche", func(t *testing.T) {
+		mockDB := setupMockDB(t)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
+		mockRetryPolicy := new(MockRetryPolicy)
+		mockSchedule := new(MockScheduleService)
+		mockGatewayProvider := new(provider_test.MockGatewayProvider)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewPaymentService(
+			mockDB,
+			mockDisbursement,
+			mockTransaction,
+			mockLoan,
+			mockBeneficiary,
+			mockDeadLetter,
+			newMockWebhookService(),
+			mockRetryPolicy,
+			mockSchedule,
+			mockGatewayProvider,
+			mockIdGenerator,
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			"https://example.com/webhook",
+			nil,
+		)
+
+		disbursement := &schema.Disbursement{
+			Id:     "DISB-123",
+			LoanId: "LOAN-123",
+			Amount: 20000.0,
+			Status: models.DisbursementStatusProcessing,
+		}
+
+		mockTransaction.On("Update", ctx, "TXN-123", mock.Anything).Return(nil).Once()
+		mockDisbursement.On("UpdateIfStatus", ctx, disbursement.Id, models.DisbursementStatusProcessing, mock.Anything).
+			Return(true, nil).
+			Once()
+		mockLoan.On("Get", ctx, "LOAN-123").
+			Return(&schema.Loan{
+				Id:     "LOAN-123",
+				Amount: 50000.0,
+				Status: models.LoanStatusDisbursementPending,
+			}, nil).
+			Once()
+		mockLoan.On("UpdateIfStatus", ctx, "LOAN-123", models.LoanStatusDisbursementPending, map[string]any{
+			"status":           models.LoanStatusPartiallyDisbursed,
+			"disbursed_amount": 20000.0,
+		}).
+			Return(true, nil).
+			Once()
+
+		mockSchedule.On("Generate", ctx, mock.Anything, disbursement, mock.AnythingOfType("time.Time")).
+			Return(nil).
+			Once()
+		mockDisbursement.On("Update", mock.Anything, "DISB-123", map[string]any{"schedule_pending": false}).
+			Return(nil).
+			Once()
+
+		err := service.HandleSuccess(ctx, disbursement, "TXN-123", models.PaymentChannelUPI, time.Time{})
+
+		assert.NoError(t, err)
+		mockLoan.AssertExpectations(t)
+		mockSchedule.AssertExpectations(t)
+	})
+
+	t.Run("keeps the settlement when the schedule fails", func(t *testing.T) {
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockSchedule := new(MockScheduleService)
+		mockWebhook := new(MockWebhookService)
+
+		service := NewPaymentService(
+			setupMockDB(t),
+			mockDisbursement,
+			mockTransaction,
+			mockLoan,
+			new(db_test.MockBeneficiaryRepository),
+			new(db_test.MockDeadLetterRepository),
+			mockWebhook,
+			new(MockRetryPolicy),
+			mockSchedule,
+			new(provider_test.MockGatewayProvider),
+			new(utils_test.MockIdGenerator),
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			"https://example.com/webhook",
+			nil,
+		)
+
+		disbursement := &schema.Disbursement{
+			Id:     "DISB-123",
+			LoanId: "LOAN-123",
+			Amount: 50000.0,
+			Status: models.DisbursementStatusProcessing,
+		}
+
+		mockTransaction.On("Update", ctx, "TXN-123", mock.Anything).Return(nil).Once()
+		mockDisbursement.On("UpdateIfStatus", ctx, disbursement.Id, models.DisbursementStatusProcessing, mock.Anything).
+			Return(true, nil).
+			Once()
+		mockLoan.On("Get", ctx, "LOAN-123").
+			Return(&schema.Loan{Id: "LOAN-123", Amount: 50000.0, Status: models.LoanStatusDisbursementPending}, nil).
+			Once()
+		mockLoan.On("UpdateIfStatus", ctx, "LOAN-123", models.LoanStatusDisbursementPending, mock.Anything).Return(true, nil).Once()
+		mockWebhook.On("Publish", ctx, models.WebhookEventSuccess, "DISB-123").Return(nil).Once()
+		mockSchedule.On("Generate", ctx, mock.Anything, disbursement, mock.AnythingOfType("time.Time")).
+			Return(errors.New("failed to save schedule")).
+			Once()
+
+		err := service.HandleSuccess(ctx, disbursement, "TXN-123", models.PaymentChannelUPI, time.Time{})
+
+		assert.NoError(t, err)
+		mockLoan.AssertExpectations(t)
+		mockWebhook.AssertExpectations(t)
+		mockDisbursement.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("does not credit loan twice for repeated success", func(t *testing.T) {
+		mockDB := setupMockDB(t)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
+		mockRetryPolicy := new(MockRetryPolicy)
+		mockSchedule := new(MockScheduleService)
+		mockGatewayProvider := new(provider_test.MockGatewayProvider)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+		mockWebhook := new(MockWebhookService)
+
+		service := NewPaymentService(
+			mockDB,
+			mockDisbursement,
+			mockTransaction,
+			mockLoan,
+			mockBeneficiary,
+			mockDeadLetter,
+			mockWebhook,
+			mockRetryPolicy,
+			mockSchedule,
+			mockGatewayProvider,
+			mockIdGenerator,
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			"https://example.com/webhook",
+			nil,
+		)
+
+		disbursement := &schema.Disbursement{
+			Id:     "DISB-123",
+			LoanId: "LOAN-123",
+			Amount: 50000.0,
+			Status: models.DisbursementStatusSuccess,
+		}
+
+		mockTransaction.On("Update", ctx, "TXN-123", mock.Anything).Return(nil).Once()
+
+		err := service.HandleSuccess(ctx, disbursement, "TXN-123", models.PaymentChannelUPI, time.Time{})
+
+		assert.NoError(t, err)
+		mockDisbursement.AssertNotCalled(t, "UpdateIfStatus")
+		mockLoan.AssertNotCalled(t, "Get")
+		mockLoan.AssertNotCalled(t, "Update")
+		mockSchedule.AssertNotCalled(t, "Generate")
+		mockWebhook.AssertNotCalled(t, "Publish")
+	})
+
+	t.Run("does not settle when the disbursement moved on concurrently", func(t *testing.T) {
+		mockDB := setupMockDB(t)
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockSchedule := new(MockScheduleService)
+		mockWebhook := new(MockWebhookService)
+
+		service := NewPaymentService(
+			mockDB,
+			mockDisbursement,
+			mockTransaction,
+			mockLoan,
+			new(db_test.MockBeneficiaryRepository),
+			new(db_test.MockDeadLetterRepository),
+			mockWebhook,
+			new(MockRetryPolicy),
+			mockSchedule,
+			new(provider_test.MockGatewayProvider),
+			new(utils_test.MockIdGenerator),
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			"https://example.com/webhook",
+			nil,
+		)
+
+		disbursement := &schema.Disbursement{
+			Id:     "DISB-123",
+			LoanId: "LOAN-123",
+			Amount: 50000.0,
+			Status: models.DisbursementStatusProcessing,
+		}
+
+		mockTransaction.On("Update", ctx, "TXN-123", mock.Anything).Return(nil).Once()
+		mockDisbursement.On("UpdateIfStatus", ctx, disbursement.Id, models.DisbursementStatusProcessing, mock.Anything).
+			Return(false, nil).
+			Once()
+
+		err := service.HandleSuccess(ctx, disbursement, "TXN-123", models.PaymentChannelUPI, time.Time{})
+
+		assert.ErrorIs(t, err, models.DISBURSEMENT_STATUS_CHANGED)
+		mockLoan.AssertNotCalled(t, "Get")
+		mockLoan.AssertNotCalled(t, "Update")
+		mockSchedule.AssertNotCalled(t, "Generate")
+		mockWebhook.AssertNotCalled(t, "Publish")
 	})
 
 	t.Run("returns error when transaction update fails", func(t *testing.T) {
@@ -1286,7 +2330,9 @@ func TestPaymentService_HandleSuccess(t *testing.T) {
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockLoan := new(db_test.MockLoanRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
 		mockRetryPolicy := new(MockRetryPolicy)
+		mockSchedule := new(MockScheduleService)
 		mockGatewayProvider := new(provider_test.MockGatewayProvider)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
@@ -1296,13 +2342,25 @@ func TestPaymentService_HandleSuccess(t *testing.T) {
 			mockTransaction,
 			mockLoan,
 			mockBeneficiary,
+			mockDeadLetter,
+			newMockWebhookService(),
 			mockRetryPolicy,
+			mockSchedule,
 			mockGatewayProvider,
 			mockIdGenerator,
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
 			"https://example.com/webhook",
+			nil,
 		)
 
-		disbursementId := "DISB-123"
+		disbursement := &schema.Disbursement{
+			Id:     "DISB-123",
+			LoanId: "LOAN-123",
+			Amount: 50000.0,
+			Status: models.DisbursementStatusProcessing,
+		}
 		transactionId := "TXN-123"
 		channel := models.PaymentChannelUPI
 
@@ -1310,10 +2368,10 @@ func TestPaymentService_HandleSuccess(t *testing.T) {
 			Return(errors.New("update failed")).
 			Once()
 
-		err := service.HandleSuccess(ctx, disbursementId, transactionId, channel)
+		err := service.HandleSuccess(ctx, disbursement, transactionId, channel, time.Time{})
 
 		assert.Error(t, err)
-		mockDisbursement.AssertNotCalled(t, "Update")
+		mockDisbursement.AssertNotCalled(t, "UpdateIfStatus")
 	})
 
 	t.Run("returns error when disbursement update fails", func(t *testing.T) {
@@ -1322,7 +2380,9 @@ func TestPaymentService_HandleSuccess(t *testing.T) {
 		mockTransaction := new(db_test.MockTransactionRepository)
 		mockLoan := new(db_test.MockLoanRepository)
 		mockBeneficiary := new(db_test.MockBeneficiaryRepository)
+		mockDeadLetter := new(db_test.MockDeadLetterRepository)
 		mockRetryPolicy := new(MockRetryPolicy)
+		mockSchedule := new(MockScheduleService)
 		mockGatewayProvider := new(provider_test.MockGatewayProvider)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
 
@@ -1332,25 +2392,118 @@ func TestPaymentService_HandleSuccess(t *testing.T) {
 			mockTransaction,
 			mockLoan,
 			mockBeneficiary,
+			mockDeadLetter,
+			newMockWebhookService(),
 			mockRetryPolicy,
+			mockSchedule,
 			mockGatewayProvider,
 			mockIdGenerator,
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
 			"https://example.com/webhook",
+			nil,
 		)
 
-		disbursementId := "DISB-123"
+		disbursement := &schema.Disbursement{
+			Id:     "DISB-123",
+			LoanId: "LOAN-123",
+			Amount: 50000.0,
+			Status: models.DisbursementStatusProcessing,
+		}
 		transactionId := "TXN-123"
 		channel := models.PaymentChannelUPI
 
 		mockTransaction.On("Update", ctx, transactionId, mock.Anything).Return(nil).Once()
-		mockDisbursement.On("Update", ctx, disbursementId, mock.Anything).
-			Return(errors.New("update failed")).
+		mockDisbursement.On("UpdateIfStatus", ctx, disbursement.Id, models.DisbursementStatusProcessing, mock.Anything).
+			Return(false, errors.New("update failed")).
 			Once()
 
-		err := service.HandleSuccess(ctx, disbursementId, transactionId, channel)
+		err := service.HandleSuccess(ctx, disbursement, transactionId, channel, time.Time{})
 
 		assert.Error(t, err)
 		mockTransaction.AssertExpectations(t)
 		mockDisbursement.AssertExpectations(t)
 	})
 }
+
+func TestPaymentService_GeneratePendingSchedules(t *testing.T) {
+	ctx := context.Background()
+	settledAt := time.Date(2026, time.March, 10, 9, 0, 0, 0, time.UTC)
+
+	newService := func(
+		t *testing.T,
+		mockDisbursement *db_test.MockDisbursementRepository,
+		mockLoan *db_test.MockLoanRepository,
+		mockSchedule *MockScheduleService,
+	) PaymentService {
+		return NewPaymentService(
+			setupMockDB(t),
+			mockDisbursement,
+			new(db_test.MockTransactionRepository),
+			mockLoan,
+			new(db_test.MockBeneficiaryRepository),
+			new(db_test.MockDeadLetterRepository),
+			newMockWebhookService(),
+			new(MockRetryPolicy),
+			mockSchedule,
+			new(provider_test.MockGatewayProvider),
+			new(utils_test.MockIdGenerator),
+			calendar.Default(),
+			nil,
+			events.NewBus(events.DefaultHistorySize),
+			"https://example.com/webhook",
+			nil,
+		)
+	}
+
+	t.Run("generates from the settlement time and clears the flag", func(t *testing.T) {
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockSchedule := new(MockScheduleService)
+		service := newService(t, mockDisbursement, mockLoan, mockSchedule)
+		loan := &schema.Loan{Id: "LOAN-123"}
+
+		mockDisbursement.On("ListSchedulePending", ctx, 10).Return([]schema.Disbursement{
+			{Id: "DISB-123", LoanId: "LOAN-123", SettledAt: &settledAt, SchedulePending: true},
+		}, nil).Once()
+		mockLoan.On("Get", mock.Anything, "LOAN-123").Return(loan, nil).Once()
+		mockSchedule.On("Generate", mock.Anything, loan, mock.Anything, settledAt).Return(nil).Once()
+		mockDisbursement.On("Update", mock.Anything, "DISB-123", map[string]any{"schedule_pending": false}).
+			Return(nil).
+			Once()
+
+		generated, err := service.GeneratePendingSchedules(ctx, 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, generated)
+		mockSchedule.AssertExpectations(t)
+		mockDisbursement.AssertExpectations(t)
+	})
+
+	t.Run("leaves a failing schedule pending and moves it back", func(t *testing.T) {
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockLoan := new(db_test.MockLoanRepository)
+		mockSchedule := new(MockScheduleService)
+		service := newService(t, mockDisbursement, mockLoan, mockSchedule)
+
+		mockDisbursement.On("ListSchedulePending", ctx, 10).Return([]schema.Disbursement{
+			{Id: "DISB-123", LoanId: "LOAN-123", SettledAt: &settledAt, SchedulePending: true},
+		}, nil).Once()
+		mockLoan.On("Get", mock.Anything, "LOAN-123").Return(&schema.Loan{Id: "LOAN-123"}, nil).Once()
+		mockSchedule.On("Generate", mock.Anything, mock.Anything, mock.Anything, settledAt).
+			Return(errors.New("failed to save schedule")).
+			Once()
+		mockDisbursement.On("Update", mock.Anything, "DISB-123", mock.MatchedBy(func(fields map[string]any) bool {
+			_, cleared := fields["schedule_pending"]
+			_, touched := fields["updated_at"]
+			return !cleared && touched
+		})).Return(nil).Once()
+
+		generated, err := service.GeneratePendingSchedules(ctx, 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, generated)
+		mockDisbursement.AssertExpectations(t)
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"loan-disbursement-service/db/schema"
 	"loan-disbursement-service/models"
 	"loan-disbursement-service/utils"
+	"shared/apperrors"
 	"time"
 )
 
@@ -35,7 +36,7 @@ func (s *ReconciliationServiceImpl) Reconcile(
 ) (*models.ReconciliationResponse, error) {
 	date, err := time.Parse(time.DateOnly, req.StatementDate)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse statement date: %w", err)
+		return nil, apperrors.Invalid(fmt.Errorf("failed to parse statement date: %w", err))
 	}
 	ourTransactions, err := s.transaction.ListByDate(
 		ctx,
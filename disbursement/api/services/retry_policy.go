@@ -1,15 +1,13 @@
 package services
 
 import (
+	"loan-disbursement-service/config"
 	"math"
 	"math/rand"
 	"time"
 )
 
 const (
-	MaxRetries      = 5
-	InitialDelay    = 30 * time.Second
-	MaxDelay        = 30 * time.Minute
 	JitterPercent   = 0.2
 	ChannelSwitchAt = 2
 )
@@ -20,21 +18,26 @@ type RetryPolicy interface {
 	IsRetryEligible(lastAttemptTime time.Time, retryCount int) bool
 }
 
-type RetryPolicyImpl struct{}
+// RetryPolicyImpl reads its delays from settings on every call, so a
+// reloaded configuration applies to the next backoff.
+type RetryPolicyImpl struct {
+	settings *config.Store
+}
 
-func NewRetryPolicy() RetryPolicy {
-	return &RetryPolicyImpl{}
+func NewRetryPolicy(settings *config.Store) RetryPolicy {
+	return &RetryPolicyImpl{settings: settings}
 }
 
 func (rp *RetryPolicyImpl) CalculateBackoff(retryCount int) time.Duration {
+	retry := rp.settings.Get().Retry
 	if retryCount <= 0 {
-		return InitialDelay
+		return retry.InitialDelay
 	}
 
-	delay := float64(InitialDelay) * math.Pow(2, float64(retryCount))
+	delay := float64(retry.InitialDelay) * math.Pow(2, float64(retryCount))
 
-	if delay > float64(MaxDelay) {
-		delay = float64(MaxDelay)
+	if delay > float64(retry.MaxDelay) {
+		delay = float64(retry.MaxDelay)
 	}
 
 	jitter := delay * JitterPercent * (rand.Float64()*2 - 1)
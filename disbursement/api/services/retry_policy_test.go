@@ -1,6 +1,7 @@
 package services
 
 import (
+	"loan-disbursement-service/config"
 	"testing"
 	"time"
 
@@ -10,6 +11,8 @@ import (
 
 var _ RetryPolicy = (*MockRetryPolicy)(nil)
 
+var defaultRetry = config.Default().Retry
+
 type MockRetryPolicy struct {
 	mock.Mock
 }
@@ -30,18 +33,18 @@ func (m *MockRetryPolicy) IsRetryEligible(lastAttemptTime time.Time, retryCount
 }
 
 func TestRetryPolicy_CalculateBackoff(t *testing.T) {
-	policy := NewRetryPolicy()
+	policy := NewRetryPolicy(nil)
 
 	t.Run("returns initial delay for zero retry count", func(t *testing.T) {
 		backoff := policy.CalculateBackoff(0)
 
-		assert.Equal(t, InitialDelay, backoff)
+		assert.Equal(t, defaultRetry.InitialDelay, backoff)
 	})
 
 	t.Run("returns initial delay for negative retry count", func(t *testing.T) {
 		backoff := policy.CalculateBackoff(-1)
 
-		assert.Equal(t, InitialDelay, backoff)
+		assert.Equal(t, defaultRetry.InitialDelay, backoff)
 	})
 
 	t.Run("returns exponential backoff for retry count 1", func(t *testing.T) {
@@ -92,12 +95,12 @@ func TestRetryPolicy_CalculateBackoff(t *testing.T) {
 	t.Run("caps at max delay for high retry counts", func(t *testing.T) {
 		backoff := policy.CalculateBackoff(10)
 
-		expectedMin := time.Duration(float64(MaxDelay) * 0.8)
-		expectedMax := time.Duration(float64(MaxDelay) * 1.2)
+		expectedMin := time.Duration(float64(defaultRetry.MaxDelay) * 0.8)
+		expectedMax := time.Duration(float64(defaultRetry.MaxDelay) * 1.2)
 
 		assert.GreaterOrEqual(t, backoff, expectedMin)
 		assert.LessOrEqual(t, backoff, expectedMax)
-		assert.LessOrEqual(t, backoff, MaxDelay*2)
+		assert.LessOrEqual(t, backoff, defaultRetry.MaxDelay*2)
 	})
 
 	t.Run("returns consistent backoff pattern across multiple calls", func(t *testing.T) {
@@ -117,7 +120,7 @@ func TestRetryPolicy_CalculateBackoff(t *testing.T) {
 	})
 
 	t.Run("handles max retries constant", func(t *testing.T) {
-		backoff := policy.CalculateBackoff(MaxRetries)
+		backoff := policy.CalculateBackoff(defaultRetry.MaxRetries)
 
 		expectedBase := 30 * time.Second * 32
 		expectedMin := time.Duration(float64(expectedBase) * 0.8)
@@ -126,10 +129,20 @@ func TestRetryPolicy_CalculateBackoff(t *testing.T) {
 		assert.GreaterOrEqual(t, backoff, expectedMin)
 		assert.LessOrEqual(t, backoff, expectedMax)
 	})
+
+	t.Run("uses the configured delays", func(t *testing.T) {
+		settings := config.Default()
+		settings.Retry.InitialDelay = 5 * time.Second
+		settings.Retry.MaxDelay = 10 * time.Second
+		policy := NewRetryPolicy(config.NewStore("", settings))
+
+		assert.Equal(t, 5*time.Second, policy.CalculateBackoff(0))
+		assert.LessOrEqual(t, policy.CalculateBackoff(4), 12*time.Second)
+	})
 }
 
 func TestRetryPolicy_NextRetryTime(t *testing.T) {
-	policy := NewRetryPolicy()
+	policy := NewRetryPolicy(nil)
 
 	t.Run("returns future time for zero retry count", func(t *testing.T) {
 		before := time.Now()
@@ -199,7 +212,7 @@ func TestRetryPolicy_NextRetryTime(t *testing.T) {
 }
 
 func TestRetryPolicy_IsRetryEligible(t *testing.T) {
-	policy := NewRetryPolicy()
+	policy := NewRetryPolicy(nil)
 
 	t.Run("returns false when last attempt was just now", func(t *testing.T) {
 		lastAttemptTime := time.Now()
@@ -256,7 +269,7 @@ func TestRetryPolicy_IsRetryEligible(t *testing.T) {
 	})
 
 	t.Run("returns true for high retry count when enough time has passed", func(t *testing.T) {
-		lastAttemptTime := time.Now().Add(-35 * time.Minute)
+		lastAttemptTime := time.Now().Add(-37 * time.Minute)
 		retryCount := 10
 
 		result := policy.IsRetryEligible(lastAttemptTime, retryCount)
@@ -265,7 +278,7 @@ func TestRetryPolicy_IsRetryEligible(t *testing.T) {
 	})
 
 	t.Run("returns false for high retry count when not enough time has passed", func(t *testing.T) {
-		lastAttemptTime := time.Now().Add(-25 * time.Minute)
+		lastAttemptTime := time.Now().Add(-23 * time.Minute)
 		retryCount := 10
 
 		result := policy.IsRetryEligible(lastAttemptTime, retryCount)
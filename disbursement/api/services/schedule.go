@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"loan-disbursement-service/amortization"
+	"loan-disbursement-service/db/daos"
+	"loan-disbursement-service/db/schema"
+	"loan-disbursement-service/models"
+	"math"
+	"shared/logging"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+type ScheduleService interface {
+	Generate(
+		ctx context.Context,
+		loan *schema.Loan,
+		disbursement *schema.Disbursement,
+		disbursedAt time.Time,
+	) error
+	Get(ctx context.Context, loanId string) (*models.RepaymentSchedule, error)
+	// WithTx returns the service with its reads and writes running in tx.
+	WithTx(tx *gorm.DB) ScheduleService
+}
+
+type ScheduleServiceImpl struct {
+	loan        daos.LoanRepository
+	installment daos.InstallmentRepository
+}
+
+func NewScheduleService(
+	loan daos.LoanRepository,
+	installment daos.InstallmentRepository,
+) ScheduleService {
+	return &ScheduleServiceImpl{loan: loan, installment: installment}
+}
+
+func (s *ScheduleServiceImpl) WithTx(tx *gorm.DB) ScheduleService {
+	return &ScheduleServiceImpl{loan: s.loan.WithTx(tx), installment: s.installment.WithTx(tx)}
+}
+
+// Generate builds and stores the repayment schedule for a successful
+// disbursement, with the first installment one period after disbursedAt.
+// Loans without repayment terms get no schedule.
+func (s *ScheduleServiceImpl) Generate(
+	ctx context.Context,
+	loan *schema.Loan,
+	disbursement *schema.Disbursement,
+	disbursedAt time.Time,
+) error {
+	if loan.TenureMonths == 0 {
+		ctx = logging.With(ctx, logging.Fields{LoanId: loan.Id})
+		log.Ctx(ctx).Info().
+			Msg("loan has no repayment terms, skipping schedule generation")
+		return nil
+	}
+
+	installments, err := amortization.Generate(amortization.Terms{
+		Principal:    disbursement.Amount,
+		AnnualRate:   loan.InterestRate,
+		TenureMonths: loan.TenureMonths,
+		PeriodMonths: loan.RepaymentFrequency.PeriodMonths(),
+		Method:       amortization.Method(loan.InterestMethod),
+	}, disbursedAt)
+	if err != nil {
+		return fmt.Errorf("failed to generate schedule for loan %s: %w", loan.Id, err)
+	}
+
+	rows := make([]schema.Installment, len(installments))
+	for i, installment := range installments {
+		rows[i] = schema.Installment{
+			DisbursementId: disbursement.Id,
+			Number:         installment.Number,
+			LoanId:         loan.Id,
+			DueDate:        installment.DueDate,
+			OpeningBalance: installment.OpeningBalance,
+			Principal:      installment.Principal,
+			Interest:       installment.Interest,
+			Amount:         installment.Amount,
+			ClosingBalance: installment.ClosingBalance,
+		}
+	}
+	if err := s.installment.CreateBatch(ctx, rows); err != nil {
+		return fmt.Errorf("failed to save schedule: %w", err)
+	}
+
+	ctx = logging.With(ctx, logging.Fields{
+		LoanId:         loan.Id,
+		DisbursementId: disbursement.Id,
+	})
+	log.Ctx(ctx).Info().
+		Int("installments", len(rows)).
+		Msg("repayment schedule generated")
+	return nil
+}
+
+func (s *ScheduleServiceImpl) Get(
+	ctx context.Context,
+	loanId string,
+) (*models.RepaymentSchedule, error) {
+	loan, err := s.loan.Get(ctx, loanId)
+	if err != nil {
+		return nil, err
+	}
+
+	installments, err := s.installment.ListByLoan(ctx, loanId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installments: %w", err)
+	}
+
+	schedule := &models.RepaymentSchedule{
+		LoanId:             loan.Id,
+		InterestRate:       loan.InterestRate,
+		TenureMonths:       loan.TenureMonths,
+		RepaymentFrequency: loan.RepaymentFrequency,
+		InterestMethod:     loan.InterestMethod,
+		Installments:       make([]models.Installment, len(installments)),
+	}
+	for i, installment := range installments {
+		schedule.TotalPrincipal += installment.Principal
+		schedule.TotalInterest += installment.Interest
+		schedule.Installments[i] = models.Installment{
+			DisbursementId: installment.DisbursementId,
+			Number:         installment.Number,
+			DueDate:        installment.DueDate,
+			OpeningBalance: installment.OpeningBalance,
+			Principal:      installment.Principal,
+			Interest:       installment.Interest,
+			Amount:         installment.Amount,
+			ClosingBalance: installment.ClosingBalance,
+		}
+	}
+	schedule.TotalPrincipal = math.Round(schedule.TotalPrincipal*100) / 100
+	schedule.TotalInterest = math.Round(schedule.TotalInterest*100) / 100
+	return schedule, nil
+}
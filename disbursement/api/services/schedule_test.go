@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"loan-disbursement-service/db/schema"
+	"loan-disbursement-service/models"
+	db_test "loan-disbursement-service/test/db"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+var _ ScheduleService = (*MockScheduleService)(nil)
+
+type MockScheduleService struct {
+	mock.Mock
+}
+
+func (m *MockScheduleService) Generate(
+	ctx context.Context,
+	loan *schema.Loan,
+	disbursement *schema.Disbursement,
+	disbursedAt time.Time,
+) error {
+	args := m.Called(ctx, loan, disbursement, disbursedAt)
+	return args.Error(0)
+}
+
+func (m *MockScheduleService) Get(
+	ctx context.Context,
+	loanId string,
+) (*models.RepaymentSchedule, error) {
+	args := m.Called(ctx, loanId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RepaymentSchedule), args.Error(1)
+}
+
+func (m *MockScheduleService) WithTx(tx *gorm.DB) ScheduleService {
+	return m
+}
+
+func TestScheduleService_Generate(t *testing.T) {
+	ctx := context.Background()
+	disbursedAt := time.Date(2024, time.March, 10, 9, 0, 0, 0, time.UTC)
+
+	loan := &schema.Loan{
+		Id:                 "LOAN-123",
+		Amount:             120000.0,
+		InterestRate:       12,
+		TenureMonths:       12,
+		RepaymentFrequency: models.RepaymentFrequencyMonthly,
+		InterestMethod:     models.InterestMethodReducingBalance,
+	}
+
+	t.Run("stores installments for the disbursed amount", func(t *testing.T) {
+		mockLoan := new(db_test.MockLoanRepository)
+		mockInstallment := new(db_test.MockInstallmentRepository)
+		service := NewScheduleService(mockLoan, mockInstallment)
+
+		disbursement := &schema.Disbursement{Id: "DISB-123", LoanId: loan.Id, Amount: 60000.0}
+
+		mockInstallment.On("CreateBatch", ctx, mock.MatchedBy(func(rows []schema.Installment) bool {
+			total := 0.0
+			for _, row := range rows {
+				total += row.Principal
+			}
+			return len(rows) == 12 &&
+				rows[0].DisbursementId == disbursement.Id &&
+				rows[0].LoanId == loan.Id &&
+				rows[0].Number == 1 &&
+				rows[0].DueDate.Equal(time.Date(2024, time.April, 10, 9, 0, 0, 0, time.UTC)) &&
+				rows[0].Interest == 600 &&
+				total > 59999.99 && total < 60000.01
+		})).Return(nil).Once()
+
+		err := service.Generate(ctx, loan, disbursement, disbursedAt)
+
+		assert.NoError(t, err)
+		mockInstallment.AssertExpectations(t)
+	})
+
+	t.Run("skips loans without repayment terms", func(t *testing.T) {
+		mockLoan := new(db_test.MockLoanRepository)
+		mockInstallment := new(db_test.MockInstallmentRepository)
+		service := NewScheduleService(mockLoan, mockInstallment)
+
+		err := service.Generate(
+			ctx,
+			&schema.Loan{Id: "LOAN-456", Amount: 50000.0},
+			&schema.Disbursement{Id: "DISB-456", Amount: 50000.0},
+			disbursedAt,
+		)
+
+		assert.NoError(t, err)
+		mockInstallment.AssertNotCalled(t, "CreateBatch")
+	})
+
+	t.Run("returns error when installments cannot be saved", func(t *testing.T) {
+		mockLoan := new(db_test.MockLoanRepository)
+		mockInstallment := new(db_test.MockInstallmentRepository)
+		service := NewScheduleService(mockLoan, mockInstallment)
+
+		mockInstallment.On("CreateBatch", ctx, mock.Anything).
+			Return(errors.New("database error")).Once()
+
+		err := service.Generate(
+			ctx,
+			loan,
+			&schema.Disbursement{Id: "DISB-123", Amount: 60000.0},
+			disbursedAt,
+		)
+
+		assert.ErrorContains(t, err, "failed to save schedule")
+	})
+}
+
+func TestScheduleService_Get(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns installments with totals", func(t *testing.T) {
+		mockLoan := new(db_test.MockLoanRepository)
+		mockInstallment := new(db_test.MockInstallmentRepository)
+		service := NewScheduleService(mockLoan, mockInstallment)
+
+		mockLoan.On("Get", ctx, "LOAN-123").Return(&schema.Loan{
+			Id:                 "LOAN-123",
+			InterestRate:       12,
+			TenureMonths:       2,
+			RepaymentFrequency: models.RepaymentFrequencyMonthly,
+			InterestMethod:     models.InterestMethodFlat,
+		}, nil).Once()
+		mockInstallment.On("ListByLoan", ctx, "LOAN-123").Return([]schema.Installment{
+			{DisbursementId: "DISB-123", Number: 1, Principal: 500, Interest: 10, Amount: 510},
+			{DisbursementId: "DISB-123", Number: 2, Principal: 500, Interest: 10, Amount: 510},
+		}, nil).Once()
+
+		schedule, err := service.Get(ctx, "LOAN-123")
+
+		assert.NoError(t, err)
+		assert.Len(t, schedule.Installments, 2)
+		assert.Equal(t, 1000.0, schedule.TotalPrincipal)
+		assert.Equal(t, 20.0, schedule.TotalInterest)
+		assert.Equal(t, models.InterestMethodFlat, schedule.InterestMethod)
+	})
+
+	t.Run("returns error when loan not found", func(t *testing.T) {
+		mockLoan := new(db_test.MockLoanRepository)
+		mockInstallment := new(db_test.MockInstallmentRepository)
+		service := NewScheduleService(mockLoan, mockInstallment)
+
+		mockLoan.On("Get", ctx, "LOAN-404").Return(nil, gorm.ErrRecordNotFound).Once()
+
+		schedule, err := service.Get(ctx, "LOAN-404")
+
+		assert.Nil(t, schedule)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		mockInstallment.AssertNotCalled(t, "ListByLoan")
+	})
+}
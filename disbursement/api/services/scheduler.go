@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"loan-disbursement-service/db/daos"
+	"loan-disbursement-service/db/schema"
+	"loan-disbursement-service/events"
+	"loan-disbursement-service/models"
+	"shared/calendar"
+	"shared/logging"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type SchedulerService interface {
+	Release(ctx context.Context, disbursement *schema.Disbursement, now time.Time) (bool, error)
+}
+
+type SchedulerServiceImpl struct {
+	disbursement daos.DisbursementRepository
+	calendar     *calendar.Calendar
+	bus          *events.Bus
+	paymentChan  chan string
+}
+
+func NewSchedulerService(
+	disbursement daos.DisbursementRepository,
+	calendar *calendar.Calendar,
+	bus *events.Bus,
+	paymentChan chan string,
+) SchedulerService {
+	return &SchedulerServiceImpl{
+		disbursement: disbursement,
+		calendar:     calendar,
+		bus:          bus,
+		paymentChan:  paymentChan,
+	}
+}
+
+// Release moves a due scheduled disbursement to initiated and reports whether
+// it did. NEFT disbursements are held while the NEFT window is closed so they
+// go out in the next window rather than failing at the bank, including over
+// bank holidays; UPI needs no check because the payment service falls back
+// to IMPS, which is always on.
+// Released NEFT disbursements are left for the NEFT worker, the rest are
+// queued for the payment worker straight away.
+func (s *SchedulerServiceImpl) Release(
+	ctx context.Context,
+	disbursement *schema.Disbursement,
+	now time.Time,
+) (bool, error) {
+	ctx = logging.With(ctx, logging.Fields{
+		LoanId:         disbursement.LoanId,
+		DisbursementId: disbursement.Id,
+		Channel:        string(disbursement.Channel),
+	})
+	if disbursement.Channel == models.PaymentChannelNEFT && !s.calendar.IsNEFTOpen(now) {
+		log.Ctx(ctx).Info().
+			Time("next_window_at", s.calendar.NextNEFTOpen(now)).
+			Msg("NEFT window closed, holding scheduled disbursement")
+		return false, nil
+	}
+
+	released, err := s.disbursement.UpdateIfStatus(
+		ctx,
+		disbursement.Id,
+		models.DisbursementStatusScheduled,
+		map[string]any{
+			"status":     models.DisbursementStatusInitiated,
+			"updated_at": now,
+		},
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to release disbursement: %w", err)
+	}
+	if !released {
+		// Cancelled between listing and release.
+		return false, nil
+	}
+
+	log.Ctx(ctx).Info().Msg("scheduled disbursement released")
+	publishStatus(s.bus, disbursement, models.DisbursementStatusInitiated, disbursement.Channel, disbursement.RetryCount, "")
+	if disbursement.Channel != models.PaymentChannelNEFT {
+		queuePayment(ctx, s.paymentChan, disbursement.Id)
+	}
+	return true, nil
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"loan-disbursement-service/db/schema"
+	"loan-disbursement-service/events"
+	"loan-disbursement-service/models"
+	db_test "loan-disbursement-service/test/db"
+	"shared/calendar"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSchedulerService_Release(t *testing.T) {
+	ctx := context.Background()
+	bankCalendar := calendar.Default()
+	open := time.Date(2024, time.March, 11, 10, 0, 0, 0, calendar.IST)  // Monday
+	closed := time.Date(2024, time.March, 9, 10, 0, 0, 0, calendar.IST) // second Saturday
+	matchRelease := mock.MatchedBy(func(fields map[string]any) bool {
+		return fields["status"] == models.DisbursementStatusInitiated
+	})
+
+	t.Run("releases UPI disbursement to the payment worker", func(t *testing.T) {
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		paymentChan := make(chan string, 1)
+		bus := events.NewBus(events.DefaultHistorySize)
+		subscription, _, _, _ := bus.Subscribe(events.Filter{}, 0)
+		service := NewSchedulerService(mockDisbursement, bankCalendar, bus, paymentChan)
+
+		disbursement := &schema.Disbursement{Id: "DISB-123", Channel: models.PaymentChannelUPI}
+
+		mockDisbursement.On("UpdateIfStatus", mock.Anything, "DISB-123", models.DisbursementStatusScheduled, matchRelease).
+			Return(true, nil).Once()
+
+		released, err := service.Release(ctx, disbursement, open)
+
+		assert.NoError(t, err)
+		assert.True(t, released)
+		assert.Equal(t, "DISB-123", <-paymentChan)
+		event := <-subscription.Events()
+		assert.Equal(t, "DISB-123", event.DisbursementId)
+		assert.Equal(t, models.DisbursementStatusInitiated, event.Status)
+	})
+
+	t.Run("releases NEFT disbursement to the NEFT worker when window is open", func(t *testing.T) {
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		paymentChan := make(chan string, 1)
+		service := NewSchedulerService(mockDisbursement, bankCalendar, events.NewBus(events.DefaultHistorySize), paymentChan)
+
+		disbursement := &schema.Disbursement{Id: "DISB-123", Channel: models.PaymentChannelNEFT}
+
+		mockDisbursement.On("UpdateIfStatus", mock.Anything, "DISB-123", models.DisbursementStatusScheduled, matchRelease).
+			Return(true, nil).Once()
+
+		released, err := service.Release(ctx, disbursement, open)
+
+		assert.NoError(t, err)
+		assert.True(t, released)
+		assert.Empty(t, paymentChan)
+	})
+
+	t.Run("holds NEFT disbursement on a bank holiday Saturday", func(t *testing.T) {
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		service := NewSchedulerService(mockDisbursement, bankCalendar, events.NewBus(events.DefaultHistorySize), make(chan string, 1))
+
+		released, err := service.Release(ctx, &schema.Disbursement{
+			Id:      "DISB-123",
+			Channel: models.PaymentChannelNEFT,
+		}, closed)
+
+		assert.NoError(t, err)
+		assert.False(t, released)
+		mockDisbursement.AssertNotCalled(t, "UpdateIfStatus")
+	})
+
+	t.Run("skips disbursement cancelled before release", func(t *testing.T) {
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		paymentChan := make(chan string, 1)
+		service := NewSchedulerService(mockDisbursement, bankCalendar, events.NewBus(events.DefaultHistorySize), paymentChan)
+
+		mockDisbursement.On("UpdateIfStatus", mock.Anything, "DISB-123", models.DisbursementStatusScheduled, matchRelease).
+			Return(false, nil).Once()
+
+		released, err := service.Release(ctx, &schema.Disbursement{
+			Id:      "DISB-123",
+			Channel: models.PaymentChannelIMPS,
+		}, open)
+
+		assert.NoError(t, err)
+		assert.False(t, released)
+		assert.Empty(t, paymentChan)
+	})
+
+	t.Run("returns error when release update fails", func(t *testing.T) {
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		service := NewSchedulerService(mockDisbursement, bankCalendar, events.NewBus(events.DefaultHistorySize), make(chan string, 1))
+
+		mockDisbursement.On("UpdateIfStatus", mock.Anything, "DISB-123", models.DisbursementStatusScheduled, matchRelease).
+			Return(false, errors.New("database error")).Once()
+
+		released, err := service.Release(ctx, &schema.Disbursement{
+			Id:      "DISB-123",
+			Channel: models.PaymentChannelIMPS,
+		}, open)
+
+		assert.ErrorContains(t, err, "failed to release disbursement")
+		assert.False(t, released)
+	})
+}
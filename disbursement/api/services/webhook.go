@@ -0,0 +1,532 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"loan-disbursement-service/db/daos"
+	"loan-disbursement-service/db/schema"
+	httpclient "loan-disbursement-service/http"
+	"loan-disbursement-service/models"
+	"loan-disbursement-service/providers"
+	"loan-disbursement-service/utils"
+	"net/netip"
+	"net/url"
+	"shared/logging"
+	"shared/tracing"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultWebhookDeliveryPageSize = 50
+	// MaxWebhookAttempts is how many times a delivery is tried before it is
+	// marked failed. With the backoff below that spans about eight and a half
+	// hours.
+	MaxWebhookAttempts = 10
+	webhookBaseBackoff = 30 * time.Second
+	webhookMaxBackoff  = 6 * time.Hour
+)
+
+// WebhookService lets loan origination systems subscribe to disbursement
+// status changes instead of polling. Events are written to a delivery log
+// as they happen and posted by the delivery worker, so a subscriber that is
+// down gets them once it is back. Subscriptions belong to the client that
+// created them: clientId is the authenticated caller, a client only sees and
+// manages its own subscriptions, and only hears about its own disbursements.
+type WebhookService interface {
+	Subscribe(
+		ctx context.Context,
+		clientId string,
+		req models.WebhookSubscriptionRequest,
+	) (*models.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context, clientId string) ([]models.WebhookSubscription, error)
+	Unsubscribe(ctx context.Context, clientId, subscriptionId string) error
+	ListDeliveries(
+		ctx context.Context,
+		clientId, subscriptionId string,
+		filter models.WebhookDeliveryFilter,
+	) ([]models.WebhookDelivery, error)
+	Redeliver(ctx context.Context, clientId, deliveryId string) (*models.WebhookDelivery, error)
+	Publish(ctx context.Context, event models.WebhookEvent, disbursementId string) error
+	DeliverPending(ctx context.Context, limit int) (int, error)
+	// WithTx returns the service reading the disbursement and writing the
+	// delivery log in tx, for an event to be queued in the transaction that
+	// saves the status change.
+	WithTx(tx *gorm.DB) WebhookService
+}
+
+type WebhookServiceImpl struct {
+	idGenerator  utils.IdGenerator
+	subscription daos.WebhookSubscriptionRepository
+	delivery     daos.WebhookDeliveryRepository
+	disbursement daos.DisbursementRepository
+	provider     providers.WebhookProvider
+}
+
+func NewWebhookService(
+	idGenerator utils.IdGenerator,
+	subscription daos.WebhookSubscriptionRepository,
+	delivery daos.WebhookDeliveryRepository,
+	disbursement daos.DisbursementRepository,
+	provider providers.WebhookProvider,
+) WebhookService {
+	return &WebhookServiceImpl{
+		idGenerator:  idGenerator,
+		subscription: subscription,
+		delivery:     delivery,
+		disbursement: disbursement,
+		provider:     provider,
+	}
+}
+
+func (s *WebhookServiceImpl) WithTx(tx *gorm.DB) WebhookService {
+	return &WebhookServiceImpl{
+		idGenerator:  s.idGenerator,
+		subscription: s.subscription.WithTx(tx),
+		delivery:     s.delivery.WithTx(tx),
+		disbursement: s.disbursement.WithTx(tx),
+		provider:     s.provider,
+	}
+}
+
+// Subscribe refuses URLs naming the host itself or an internal address.
+// Host names are resolved when a delivery is made, and the delivery is
+// refused then if the name points at an internal address.
+func (s *WebhookServiceImpl) Subscribe(
+	ctx context.Context,
+	clientId string,
+	req models.WebhookSubscriptionRequest,
+) (*models.WebhookSubscription, error) {
+	if clientId == "" {
+		return nil, models.WEBHOOK_CLIENT_ID_REQUIRED
+	}
+	target, err := url.Parse(strings.TrimSpace(req.URL))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, models.INVALID_WEBHOOK_URL
+	}
+	if !isPublicHost(target.Hostname()) {
+		return nil, models.WEBHOOK_URL_NOT_PUBLIC
+	}
+	if len(req.Events) == 0 {
+		return nil, models.WEBHOOK_EVENTS_REQUIRED
+	}
+	events := make([]string, 0, len(req.Events))
+	for _, event := range req.Events {
+		if !event.IsValid() {
+			return nil, fmt.Errorf("%w: %s", models.INVALID_WEBHOOK_EVENT, event)
+		}
+		if !slices.Contains(events, string(event)) {
+			events = append(events, string(event))
+		}
+	}
+
+	now := time.Now()
+	subscription := schema.WebhookSubscription{
+		Id:        s.idGenerator.GenerateWebhookId(),
+		ClientId:  clientId,
+		URL:       target.String(),
+		Secret:    s.idGenerator.GenerateWebhookSecret(),
+		Events:    strings.Join(events, ","),
+		Active:    true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.subscription.Create(ctx, subscription); err != nil {
+		return nil, fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+
+	log.Ctx(ctx).Info().
+		Str("subscription_id", subscription.Id).
+		Str("client_id", clientId).
+		Msg("webhook subscription created")
+	response := webhookSubscriptionResponse(subscription)
+	response.Secret = subscription.Secret
+	return &response, nil
+}
+
+func (s *WebhookServiceImpl) ListSubscriptions(
+	ctx context.Context,
+	clientId string,
+) ([]models.WebhookSubscription, error) {
+	if clientId == "" {
+		return nil, models.WEBHOOK_CLIENT_ID_REQUIRED
+	}
+	subscriptions, err := s.subscription.ListByClient(ctx, clientId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	response := make([]models.WebhookSubscription, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		response = append(response, webhookSubscriptionResponse(subscription))
+	}
+	return response, nil
+}
+
+// Unsubscribe deactivates the subscription. Its delivery log is kept, and
+// deliveries still pending are marked failed by the worker.
+func (s *WebhookServiceImpl) Unsubscribe(ctx context.Context, clientId, subscriptionId string) error {
+	if _, err := s.load(ctx, clientId, subscriptionId); err != nil {
+		return err
+	}
+	err := s.subscription.Update(ctx, subscriptionId, map[string]any{
+		"active":     false,
+		"updated_at": time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+	return nil
+}
+
+func (s *WebhookServiceImpl) ListDeliveries(
+	ctx context.Context,
+	clientId, subscriptionId string,
+	filter models.WebhookDeliveryFilter,
+) ([]models.WebhookDelivery, error) {
+	if filter.Status != "" && !filter.Status.IsValid() {
+		return nil, models.INVALID_WEBHOOK_DELIVERY_STATUS
+	}
+	if filter.Limit == 0 {
+		filter.Limit = DefaultWebhookDeliveryPageSize
+	}
+	if _, err := s.load(ctx, clientId, subscriptionId); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.delivery.ListBySubscription(ctx, subscriptionId, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	response := make([]models.WebhookDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		response = append(response, webhookDeliveryResponse(delivery))
+	}
+	return response, nil
+}
+
+// Redeliver queues a delivered or failed delivery to be sent again with a
+// fresh attempt budget. The subscriber gets the original body, so it can
+// use the event id to discard an event it already handled.
+func (s *WebhookServiceImpl) Redeliver(
+	ctx context.Context,
+	clientId, deliveryId string,
+) (*models.WebhookDelivery, error) {
+	delivery, err := s.delivery.Get(ctx, deliveryId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.WEBHOOK_DELIVERY_NOT_FOUND
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	subscription, err := s.load(ctx, clientId, delivery.SubscriptionId)
+	if errors.Is(err, models.WEBHOOK_SUBSCRIPTION_NOT_FOUND) {
+		return nil, models.WEBHOOK_DELIVERY_NOT_FOUND
+	}
+	if err != nil {
+		return nil, err
+	}
+	if delivery.Status == models.WebhookDeliveryStatusPending {
+		return nil, models.WEBHOOK_DELIVERY_PENDING
+	}
+	if !subscription.Active {
+		return nil, models.WEBHOOK_SUBSCRIPTION_NOT_FOUND
+	}
+
+	now := time.Now()
+	fields := map[string]any{
+		"status":          models.WebhookDeliveryStatusPending,
+		"attempts":        0,
+		"next_attempt_at": now,
+		"updated_at":      now,
+	}
+	queued, err := s.delivery.UpdateIfStatus(ctx, deliveryId, delivery.Status, fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	if !queued {
+		return nil, models.WEBHOOK_DELIVERY_PENDING
+	}
+
+	delivery.Status = models.WebhookDeliveryStatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = &now
+	delivery.UpdatedAt = now
+	response := webhookDeliveryResponse(*delivery)
+	return &response, nil
+}
+
+// Publish queues a delivery of the event for every active subscription to
+// it held by the client the disbursement was made for. The payload is built
+// from the disbursement as it is now, so it is called after the status
+// change is written, in the same transaction through WithTx.
+func (s *WebhookServiceImpl) Publish(
+	ctx context.Context,
+	event models.WebhookEvent,
+	disbursementId string,
+) error {
+	disbursement, err := s.disbursement.Get(ctx, disbursementId)
+	if err != nil {
+		return fmt.Errorf("failed to get disbursement: %w", err)
+	}
+	if disbursement.ClientId == "" {
+		return nil
+	}
+	subscriptions, err := s.subscription.ListActiveByClient(ctx, disbursement.ClientId)
+	if err != nil {
+		return fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	subscriptions = slices.DeleteFunc(subscriptions, func(subscription schema.WebhookSubscription) bool {
+		return !slices.Contains(strings.Split(subscription.Events, ","), string(event))
+	})
+	if len(subscriptions) == 0 {
+		return nil
+	}
+	status, ok := event.Status()
+	if !ok {
+		status = disbursement.Status
+	}
+
+	now := time.Now()
+	eventId := s.idGenerator.GenerateEventId()
+	payload, err := json.Marshal(models.WebhookPayload{
+		EventId:    eventId,
+		Event:      event,
+		OccurredAt: now,
+		Data: models.WebhookDisbursementData{
+			DisbursementId: disbursement.Id,
+			LoanId:         disbursement.LoanId,
+			Amount:         disbursement.Amount,
+			Channel:        disbursement.Channel,
+			Status:         status,
+			RetryCount:     disbursement.RetryCount,
+			LastError:      disbursement.LastError,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	deliveries := make([]schema.WebhookDelivery, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		deliveries = append(deliveries, schema.WebhookDelivery{
+			Id:             s.idGenerator.GenerateWebhookDeliveryId(),
+			EventId:        eventId,
+			SubscriptionId: subscription.Id,
+			DisbursementId: disbursement.Id,
+			Event:          event,
+			Payload:        string(payload),
+			Status:         models.WebhookDeliveryStatusPending,
+			NextAttemptAt:  &now,
+			TraceParent:    tracing.TraceParent(ctx),
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		})
+	}
+	if err := s.delivery.Create(ctx, deliveries); err != nil {
+		return fmt.Errorf("failed to create webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+// DeliverPending sends deliveries that are due and reschedules the ones the
+// subscriber did not accept. It returns how many were delivered.
+func (s *WebhookServiceImpl) DeliverPending(ctx context.Context, limit int) (int, error) {
+	deliveries, err := s.delivery.ListDue(ctx, time.Now(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	subscriptions := map[string]*schema.WebhookSubscription{}
+	delivered := 0
+	for _, delivery := range deliveries {
+		subscription, ok := subscriptions[delivery.SubscriptionId]
+		if !ok {
+			subscription, err = s.subscription.Get(ctx, delivery.SubscriptionId)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Ctx(ctx).Error().Err(err).
+					Str("delivery_id", delivery.Id).
+					Msg("failed to get webhook subscription")
+				continue
+			}
+			subscriptions[delivery.SubscriptionId] = subscription
+		}
+
+		fields := s.attempt(ctx, delivery, subscription)
+		if err := s.delivery.Update(ctx, delivery.Id, fields); err != nil {
+			log.Ctx(ctx).Error().Err(err).
+				Str("delivery_id", delivery.Id).
+				Msg("failed to update webhook delivery")
+			continue
+		}
+		if fields["status"] == models.WebhookDeliveryStatusDelivered {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+// attempt posts one delivery and returns the fields recording the outcome.
+func (s *WebhookServiceImpl) attempt(
+	ctx context.Context,
+	delivery schema.WebhookDelivery,
+	subscription *schema.WebhookSubscription,
+) map[string]any {
+	now := time.Now()
+	if subscription == nil || !subscription.Active {
+		return map[string]any{
+			"status":          models.WebhookDeliveryStatusFailed,
+			"next_attempt_at": nil,
+			"last_error":      "subscription is no longer active",
+			"updated_at":      now,
+		}
+	}
+
+	ctx, span := tracing.Resume(ctx, delivery.TraceParent, "webhook.deliver",
+		tracing.WebhookDeliveryIdKey.String(delivery.Id),
+		tracing.DisbursementIdKey.String(delivery.DisbursementId),
+	)
+	defer span.End()
+	ctx = logging.With(ctx, logging.Fields{DisbursementId: delivery.DisbursementId})
+
+	statusCode, err := s.provider.Deliver(ctx, models.WebhookRequest{
+		URL:        subscription.URL,
+		Secret:     subscription.Secret,
+		DeliveryId: delivery.Id,
+		Event:      delivery.Event,
+		Payload:    []byte(delivery.Payload),
+	})
+	attempts := delivery.Attempts + 1
+	fields := map[string]any{
+		"attempts":   attempts,
+		"updated_at": now,
+	}
+	if statusCode != 0 {
+		fields["last_status_code"] = statusCode
+	}
+	if err == nil {
+		fields["status"] = models.WebhookDeliveryStatusDelivered
+		fields["next_attempt_at"] = nil
+		fields["last_error"] = nil
+		fields["delivered_at"] = now
+		return fields
+	}
+
+	tracing.Record(span, err)
+	log.Ctx(ctx).Warn().Err(err).
+		Str("delivery_id", delivery.Id).
+		Str("subscription_id", subscription.Id).
+		Int("attempts", attempts).
+		Msg("webhook delivery failed")
+	fields["last_error"] = err.Error()
+	if attempts >= MaxWebhookAttempts {
+		fields["status"] = models.WebhookDeliveryStatusFailed
+		fields["next_attempt_at"] = nil
+		return fields
+	}
+	fields["next_attempt_at"] = now.Add(webhookBackoff(attempts))
+	return fields
+}
+
+// load returns the client's subscription. Another client's subscription is
+// reported as not found, so its existence is not given away.
+func (s *WebhookServiceImpl) load(
+	ctx context.Context,
+	clientId, subscriptionId string,
+) (*schema.WebhookSubscription, error) {
+	if clientId == "" {
+		return nil, models.WEBHOOK_CLIENT_ID_REQUIRED
+	}
+	subscription, err := s.subscription.Get(ctx, subscriptionId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.WEBHOOK_SUBSCRIPTION_NOT_FOUND
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+	if subscription.ClientId != clientId {
+		return nil, models.WEBHOOK_SUBSCRIPTION_NOT_FOUND
+	}
+	return subscription, nil
+}
+
+// isPublicHost rejects localhost and IP literals outside the public
+// internet. Other host names pass; the delivery client checks the address
+// they resolve to.
+func isPublicHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return httpclient.IsPublicAddr(addr)
+	}
+	return true
+}
+
+// webhookBackoff doubles the wait after each failed attempt, starting at
+// webhookBaseBackoff.
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookBaseBackoff << (attempts - 1)
+	if backoff > webhookMaxBackoff || backoff <= 0 {
+		return webhookMaxBackoff
+	}
+	return backoff
+}
+
+// queueEvent writes the event to the delivery log in tx, the transaction
+// that saved the status change. Subscribers hear of exactly the changes that
+// commit, and the delivery worker sends them from the log.
+func queueEvent(
+	ctx context.Context,
+	tx *gorm.DB,
+	webhook WebhookService,
+	event models.WebhookEvent,
+	disbursementId string,
+) error {
+	if err := webhook.WithTx(tx).Publish(ctx, event, disbursementId); err != nil {
+		return fmt.Errorf("failed to queue webhook event %s: %w", event, err)
+	}
+	return nil
+}
+
+func webhookSubscriptionResponse(subscription schema.WebhookSubscription) models.WebhookSubscription {
+	events := []models.WebhookEvent{}
+	for _, event := range strings.Split(subscription.Events, ",") {
+		if event != "" {
+			events = append(events, models.WebhookEvent(event))
+		}
+	}
+	return models.WebhookSubscription{
+		Id:        subscription.Id,
+		ClientId:  subscription.ClientId,
+		URL:       subscription.URL,
+		Events:    events,
+		Active:    subscription.Active,
+		CreatedAt: subscription.CreatedAt,
+		UpdatedAt: subscription.UpdatedAt,
+	}
+}
+
+func webhookDeliveryResponse(delivery schema.WebhookDelivery) models.WebhookDelivery {
+	return models.WebhookDelivery{
+		Id:             delivery.Id,
+		EventId:        delivery.EventId,
+		SubscriptionId: delivery.SubscriptionId,
+		DisbursementId: delivery.DisbursementId,
+		Event:          delivery.Event,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+		UpdatedAt:      delivery.UpdatedAt,
+	}
+}
@@ -0,0 +1,578 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"loan-disbursement-service/db/schema"
+	"loan-disbursement-service/models"
+	db_test "loan-disbursement-service/test/db"
+	provider_test "loan-disbursement-service/test/providers"
+	utils_test "loan-disbursement-service/test/utils"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockWebhookService struct {
+	mock.Mock
+}
+
+// newMockWebhookService accepts any event, for services whose tests are
+// not about webhooks.
+func newMockWebhookService() *MockWebhookService {
+	m := new(MockWebhookService)
+	m.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	return m
+}
+
+func (m *MockWebhookService) Subscribe(
+	ctx context.Context,
+	clientId string,
+	req models.WebhookSubscriptionRequest,
+) (*models.WebhookSubscription, error) {
+	args := m.Called(ctx, clientId, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookService) ListSubscriptions(
+	ctx context.Context,
+	clientId string,
+) ([]models.WebhookSubscription, error) {
+	args := m.Called(ctx, clientId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookService) Unsubscribe(ctx context.Context, clientId, subscriptionId string) error {
+	args := m.Called(ctx, clientId, subscriptionId)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) ListDeliveries(
+	ctx context.Context,
+	clientId, subscriptionId string,
+	filter models.WebhookDeliveryFilter,
+) ([]models.WebhookDelivery, error) {
+	args := m.Called(ctx, clientId, subscriptionId, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookService) Redeliver(
+	ctx context.Context,
+	clientId, deliveryId string,
+) (*models.WebhookDelivery, error) {
+	args := m.Called(ctx, clientId, deliveryId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookService) Publish(
+	ctx context.Context,
+	event models.WebhookEvent,
+	disbursementId string,
+) error {
+	args := m.Called(ctx, event, disbursementId)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) WithTx(tx *gorm.DB) WebhookService {
+	return m
+}
+
+func (m *MockWebhookService) DeliverPending(ctx context.Context, limit int) (int, error) {
+	args := m.Called(ctx, limit)
+	return args.Int(0), args.Error(1)
+}
+
+type webhookMocks struct {
+	idGenerator  *utils_test.MockIdGenerator
+	subscription *db_test.MockWebhookSubscriptionRepository
+	delivery     *db_test.MockWebhookDeliveryRepository
+	disbursement *db_test.MockDisbursementRepository
+	provider     *provider_test.MockWebhookProvider
+}
+
+func newWebhookService() (WebhookService, webhookMocks) {
+	mocks := webhookMocks{
+		idGenerator:  new(utils_test.MockIdGenerator),
+		subscription: new(db_test.MockWebhookSubscriptionRepository),
+		delivery:     new(db_test.MockWebhookDeliveryRepository),
+		disbursement: new(db_test.MockDisbursementRepository),
+		provider:     new(provider_test.MockWebhookProvider),
+	}
+	service := NewWebhookService(
+		mocks.idGenerator,
+		mocks.subscription,
+		mocks.delivery,
+		mocks.disbursement,
+		mocks.provider,
+	)
+	return service, mocks
+}
+
+func TestWebhookService_Subscribe(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("creates subscription and returns its secret once", func(t *testing.T) {
+		service, mocks := newWebhookService()
+
+		mocks.idGenerator.On("GenerateWebhookId").Return("WHK-123").Once()
+		mocks.idGenerator.On("GenerateWebhookSecret").Return("whsec_abc").Once()
+		mocks.subscription.On("Create", ctx, mock.MatchedBy(func(subscription schema.WebhookSubscription) bool {
+			return subscription.Id == "WHK-123" &&
+				subscription.ClientId == "los" &&
+				subscription.URL == "https://los.example.com/hooks" &&
+				subscription.Secret == "whsec_abc" &&
+				subscription.Events == "disbursement.success,disbursement.failed" &&
+				subscription.Active
+		})).Return(nil).Once()
+
+		response, err := service.Subscribe(ctx, "los", models.WebhookSubscriptionRequest{
+			URL: "https://los.example.com/hooks",
+			Events: []models.WebhookEvent{
+				models.WebhookEventSuccess,
+				models.WebhookEventFailed,
+				models.WebhookEventSuccess,
+			},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "WHK-123", response.Id)
+		assert.Equal(t, "whsec_abc", response.Secret)
+		assert.Equal(t, []models.WebhookEvent{models.WebhookEventSuccess, models.WebhookEventFailed}, response.Events)
+		mocks.subscription.AssertExpectations(t)
+	})
+
+	t.Run("validates request", func(t *testing.T) {
+		cases := []struct {
+			name string
+			req  models.WebhookSubscriptionRequest
+			err  error
+		}{
+			{
+				name: "missing client id",
+				req:  models.WebhookSubscriptionRequest{URL: "https://los.example.com", Events: []models.WebhookEvent{models.WebhookEventSuccess}},
+				err:  models.WEBHOOK_CLIENT_ID_REQUIRED,
+			},
+			{
+				name: "loopback url",
+				req:  models.WebhookSubscriptionRequest{URL: "http://127.0.0.1:7070/hooks", Events: []models.WebhookEvent{models.WebhookEventSuccess}},
+				err:  models.WEBHOOK_URL_NOT_PUBLIC,
+			},
+			{
+				name: "localhost url",
+				req:  models.WebhookSubscriptionRequest{URL: "http://localhost/hooks", Events: []models.WebhookEvent{models.WebhookEventSuccess}},
+				err:  models.WEBHOOK_URL_NOT_PUBLIC,
+			},
+			{
+				name: "private url",
+				req:  models.WebhookSubscriptionRequest{URL: "https://10.0.0.5/hooks", Events: []models.WebhookEvent{models.WebhookEventSuccess}},
+				err:  models.WEBHOOK_URL_NOT_PUBLIC,
+			},
+			{
+				name: "link-local url",
+				req:  models.WebhookSubscriptionRequest{URL: "http://[fe80::1]/hooks", Events: []models.WebhookEvent{models.WebhookEventSuccess}},
+				err:  models.WEBHOOK_URL_NOT_PUBLIC,
+			},
+			{
+				name: "metadata url",
+				req:  models.WebhookSubscriptionRequest{URL: "http://169.254.169.254/latest", Events: []models.WebhookEvent{models.WebhookEventSuccess}},
+				err:  models.WEBHOOK_URL_NOT_PUBLIC,
+			},
+			{
+				name: "relative url",
+				req:  models.WebhookSubscriptionRequest{URL: "/hooks", Events: []models.WebhookEvent{models.WebhookEventSuccess}},
+				err:  models.INVALID_WEBHOOK_URL,
+			},
+			{
+				name: "unsupported scheme",
+				req:  models.WebhookSubscriptionRequest{URL: "ftp://los.example.com", Events: []models.WebhookEvent{models.WebhookEventSuccess}},
+				err:  models.INVALID_WEBHOOK_URL,
+			},
+			{
+				name: "no events",
+				req:  models.WebhookSubscriptionRequest{URL: "https://los.example.com"},
+				err:  models.WEBHOOK_EVENTS_REQUIRED,
+			},
+			{
+				name: "unknown event",
+				req:  models.WebhookSubscriptionRequest{URL: "https://los.example.com", Events: []models.WebhookEvent{"disbursement.lost"}},
+				err:  models.INVALID_WEBHOOK_EVENT,
+			},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				service, mocks := newWebhookService()
+
+				clientId := "los"
+				if errors.Is(tc.err, models.WEBHOOK_CLIENT_ID_REQUIRED) {
+					clientId = ""
+				}
+
+				_, err := service.Subscribe(ctx, clientId, tc.req)
+
+				assert.ErrorIs(t, err, tc.err)
+				mocks.subscription.AssertNotCalled(t, "Create")
+			})
+		}
+	})
+}
+
+func TestWebhookService_Publish(t *testing.T) {
+	ctx := context.Background()
+	lastError := "Insufficient balance"
+
+	t.Run("queues one delivery per subscribed endpoint with the same event", func(t *testing.T) {
+		service, mocks := newWebhookService()
+
+		mocks.subscription.On("ListActiveByClient", ctx, "los").Return([]schema.WebhookSubscription{
+			{Id: "WHK-1", Events: "disbursement.failed,disbursement.success", Active: true},
+			{Id: "WHK-2", Events: "disbursement.created", Active: true},
+			{Id: "WHK-3", Events: "disbursement.failed", Active: true},
+		}, nil).Once()
+		mocks.disbursement.On("Get", ctx, "DISB-123").Return(&schema.Disbursement{
+			Id:         "DISB-123",
+			LoanId:     "LOAN-123",
+			ClientId:   "los",
+			Amount:     50000,
+			Channel:    models.PaymentChannelIMPS,
+			Status:     models.DisbursementStatusFailed,
+			RetryCount: 3,
+			LastError:  &lastError,
+		}, nil).Once()
+		mocks.idGenerator.On("GenerateEventId").Return("EVT-123").Once()
+		mocks.idGenerator.On("GenerateWebhookDeliveryId").Return("WHD-1").Once()
+		mocks.idGenerator.On("GenerateWebhookDeliveryId").Return("WHD-3").Once()
+
+		var deliveries []schema.WebhookDelivery
+		mocks.delivery.On("Create", ctx, mock.Anything).
+			Run(func(args mock.Arguments) {
+				deliveries = args.Get(1).([]schema.WebhookDelivery)
+			}).
+			Return(nil).Once()
+
+		err := service.Publish(ctx, models.WebhookEventFailed, "DISB-123")
+
+		assert.NoError(t, err)
+		assert.Len(t, deliveries, 2)
+		assert.Equal(t, "WHK-1", deliveries[0].SubscriptionId)
+		assert.Equal(t, "WHK-3", deliveries[1].SubscriptionId)
+		assert.Equal(t, deliveries[0].Payload, deliveries[1].Payload)
+		assert.Equal(t, models.WebhookDeliveryStatusPending, deliveries[0].Status)
+		assert.NotNil(t, deliveries[0].NextAttemptAt)
+
+		var payload models.WebhookPayload
+		assert.NoError(t, json.Unmarshal([]byte(deliveries[0].Payload), &payload))
+		assert.Equal(t, "EVT-123", payload.EventId)
+		assert.Equal(t, models.WebhookEventFailed, payload.Event)
+		assert.Equal(t, models.DisbursementStatusFailed, payload.Data.Status)
+		assert.Equal(t, "LOAN-123", payload.Data.LoanId)
+		assert.Equal(t, &lastError, payload.Data.LastError)
+	})
+
+	t.Run("reports the event's status even if the disbursement has moved on", func(t *testing.T) {
+		service, mocks := newWebhookService()
+
+		mocks.disbursement.On("Get", ctx, "DISB-123").Return(&schema.Disbursement{
+			Id:       "DISB-123",
+			ClientId: "los",
+			Status:   models.DisbursementStatusSuccess,
+		}, nil).Once()
+		mocks.subscription.On("ListActiveByClient", ctx, "los").Return([]schema.WebhookSubscription{
+			{Id: "WHK-1", Events: "disbursement.processing", Active: true},
+		}, nil).Once()
+		mocks.idGenerator.On("GenerateEventId").Return("EVT-123").Once()
+		mocks.idGenerator.On("GenerateWebhookDeliveryId").Return("WHD-1").Once()
+		mocks.delivery.On("Create", ctx, mock.MatchedBy(func(deliveries []schema.WebhookDelivery) bool {
+			var payload models.WebhookPayload
+			json.Unmarshal([]byte(deliveries[0].Payload), &payload)
+			return payload.Data.Status == models.DisbursementStatusProcessing
+		})).Return(nil).Once()
+
+		err := service.Publish(ctx, models.WebhookEventProcessing, "DISB-123")
+
+		assert.NoError(t, err)
+		mocks.delivery.AssertExpectations(t)
+	})
+
+	t.Run("does nothing without subscribers", func(t *testing.T) {
+		service, mocks := newWebhookService()
+
+		mocks.disbursement.On("Get", ctx, "DISB-123").
+			Return(&schema.Disbursement{Id: "DISB-123", ClientId: "los"}, nil).Once()
+		mocks.subscription.On("ListActiveByClient", ctx, "los").Return([]schema.WebhookSubscription{
+			{Id: "WHK-1", Events: "disbursement.success", Active: true},
+		}, nil).Once()
+
+		err := service.Publish(ctx, models.WebhookEventCancelled, "DISB-123")
+
+		assert.NoError(t, err)
+		mocks.delivery.AssertNotCalled(t, "Create")
+	})
+
+	t.Run("does nothing for a disbursement made for no client", func(t *testing.T) {
+		service, mocks := newWebhookService()
+
+		mocks.disbursement.On("Get", ctx, "DISB-123").
+			Return(&schema.Disbursement{Id: "DISB-123"}, nil).Once()
+
+		err := service.Publish(ctx, models.WebhookEventSuccess, "DISB-123")
+
+		assert.NoError(t, err)
+		mocks.subscription.AssertNotCalled(t, "ListActiveByClient")
+		mocks.delivery.AssertNotCalled(t, "Create")
+	})
+}
+
+func TestWebhookService_DeliverPending(t *testing.T) {
+	ctx := context.Background()
+	subscription := &schema.WebhookSubscription{
+		Id:     "WHK-1",
+		URL:    "https://los.example.com/hooks",
+		Secret: "whsec_abc",
+		Active: true,
+	}
+	pending := func(attempts int) schema.WebhookDelivery {
+		return schema.WebhookDelivery{
+			Id:             "WHD-1",
+			SubscriptionId: "WHK-1",
+			Event:          models.WebhookEventSuccess,
+			Payload:        `{"event_id":"EVT-1"}`,
+			Status:         models.WebhookDeliveryStatusPending,
+			Attempts:       attempts,
+		}
+	}
+	request := models.WebhookRequest{
+		URL:        "https://los.example.com/hooks",
+		Secret:     "whsec_abc",
+		DeliveryId: "WHD-1",
+		Event:      models.WebhookEventSuccess,
+		Payload:    []byte(`{"event_id":"EVT-1"}`),
+	}
+
+	t.Run("marks accepted delivery delivered", func(t *testing.T) {
+		service, mocks := newWebhookService()
+
+		mocks.delivery.On("ListDue", ctx, mock.Anything, 10).
+			Return([]schema.WebhookDelivery{pending(0)}, nil).Once()
+		mocks.subscription.On("Get", ctx, "WHK-1").Return(subscription, nil).Once()
+		mocks.provider.On("Deliver", mock.Anything, request).Return(http.StatusOK, nil).Once()
+		mocks.delivery.On("Update", ctx, "WHD-1", mock.MatchedBy(func(fields map[string]any) bool {
+			return fields["status"] == models.WebhookDeliveryStatusDelivered &&
+				fields["attempts"] == 1 &&
+				fields["last_status_code"] == http.StatusOK &&
+				fields["delivered_at"] != nil
+		})).Return(nil).Once()
+
+		delivered, err := service.DeliverPending(ctx, 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, delivered)
+		mocks.delivery.AssertExpectations(t)
+	})
+
+	t.Run("reschedules rejected delivery with backoff", func(t *testing.T) {
+		service, mocks := newWebhookService()
+
+		mocks.delivery.On("ListDue", ctx, mock.Anything, 10).
+			Return([]schema.WebhookDelivery{pending(2)}, nil).Once()
+		mocks.subscription.On("Get", ctx, "WHK-1").Return(subscription, nil).Once()
+		mocks.provider.On("Deliver", mock.Anything, request).
+			Return(http.StatusServiceUnavailable, errors.New("subscriber rejected webhook: status=503")).Once()
+		mocks.delivery.On("Update", ctx, "WHD-1", mock.MatchedBy(func(fields map[string]any) bool {
+			next, ok := fields["next_attempt_at"].(time.Time)
+			return ok &&
+				fields["status"] == nil &&
+				fields["attempts"] == 3 &&
+				fields["last_status_code"] == http.StatusServiceUnavailable &&
+				next.Sub(time.Now()) > time.Minute
+		})).Return(nil).Once()
+
+		delivered, err := service.DeliverPending(ctx, 10)
+
+		assert.NoError(t, err)
+		assert.Zero(t, delivered)
+		mocks.delivery.AssertExpectations(t)
+	})
+
+	t.Run("fails delivery after last attempt", func(t *testing.T) {
+		service, mocks := newWebhookService()
+
+		mocks.delivery.On("ListDue", ctx, mock.Anything, 10).
+			Return([]schema.WebhookDelivery{pending(MaxWebhookAttempts - 1)}, nil).Once()
+		mocks.subscription.On("Get", ctx, "WHK-1").Return(subscription, nil).Once()
+		mocks.provider.On("Deliver", mock.Anything, request).Return(0, models.NETWORK_ERROR).Once()
+		mocks.delivery.On("Update", ctx, "WHD-1", mock.MatchedBy(func(fields map[string]any) bool {
+			_, hasStatusCode := fields["last_status_code"]
+			return fields["status"] == models.WebhookDeliveryStatusFailed &&
+				fields["attempts"] == MaxWebhookAttempts &&
+				fields["next_attempt_at"] == nil &&
+				!hasStatusCode
+		})).Return(nil).Once()
+
+		_, err := service.DeliverPending(ctx, 10)
+
+		assert.NoError(t, err)
+		mocks.delivery.AssertExpectations(t)
+	})
+
+	t.Run("fails deliveries of removed subscription without sending", func(t *testing.T) {
+		service, mocks := newWebhookService()
+
+		mocks.delivery.On("ListDue", ctx, mock.Anything, 10).
+			Return([]schema.WebhookDelivery{pending(0)}, nil).Once()
+		mocks.subscription.On("Get", ctx, "WHK-1").
+			Return(&schema.WebhookSubscription{Id: "WHK-1", Active: false}, nil).Once()
+		mocks.delivery.On("Update", ctx, "WHD-1", mock.MatchedBy(func(fields map[string]any) bool {
+			return fields["status"] == models.WebhookDeliveryStatusFailed
+		})).Return(nil).Once()
+
+		_, err := service.DeliverPending(ctx, 10)
+
+		assert.NoError(t, err)
+		mocks.provider.AssertNotCalled(t, "Deliver")
+		mocks.delivery.AssertExpectations(t)
+	})
+}
+
+func TestWebhookService_Redeliver(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("queues failed delivery with a fresh attempt budget", func(t *testing.T) {
+		service, mocks := newWebhookService()
+
+		mocks.delivery.On("Get", ctx, "WHD-1").Return(&schema.WebhookDelivery{
+			Id:             "WHD-1",
+			SubscriptionId: "WHK-1",
+			Status:         models.WebhookDeliveryStatusFailed,
+			Attempts:       MaxWebhookAttempts,
+		}, nil).Once()
+		mocks.subscription.On("Get", ctx, "WHK-1").
+			Return(&schema.WebhookSubscription{Id: "WHK-1", ClientId: "los", Active: true}, nil).Once()
+		mocks.delivery.On("UpdateIfStatus", ctx, "WHD-1", models.WebhookDeliveryStatusFailed,
+			mock.MatchedBy(func(fields map[string]any) bool {
+				return fields["status"] == models.WebhookDeliveryStatusPending && fields["attempts"] == 0
+			}),
+		).Return(true, nil).Once()
+
+		response, err := service.Redeliver(ctx, "los", "WHD-1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.WebhookDeliveryStatusPending, response.Status)
+		assert.Zero(t, response.Attempts)
+	})
+
+	t.Run("rejects delivery that is still pending", func(t *testing.T) {
+		service, mocks := newWebhookService()
+
+		mocks.delivery.On("Get", ctx, "WHD-1").Return(&schema.WebhookDelivery{
+			Id:             "WHD-1",
+			SubscriptionId: "WHK-1",
+			Status:         models.WebhookDeliveryStatusPending,
+		}, nil).Once()
+		mocks.subscription.On("Get", ctx, "WHK-1").
+			Return(&schema.WebhookSubscription{Id: "WHK-1", ClientId: "los", Active: true}, nil).Once()
+
+		_, err := service.Redeliver(ctx, "los", "WHD-1")
+
+		assert.ErrorIs(t, err, models.WEBHOOK_DELIVERY_PENDING)
+	})
+
+	t.Run("rejects delivery of removed subscription", func(t *testing.T) {
+		service, mocks := newWebhookService()
+
+		mocks.delivery.On("Get", ctx, "WHD-1").Return(&schema.WebhookDelivery{
+			Id:             "WHD-1",
+			SubscriptionId: "WHK-1",
+			Status:         models.WebhookDeliveryStatusDelivered,
+		}, nil).Once()
+		mocks.subscription.On("Get", ctx, "WHK-1").
+			Return(&schema.WebhookSubscription{Id: "WHK-1", ClientId: "los", Active: false}, nil).Once()
+
+		_, err := service.Redeliver(ctx, "los", "WHD-1")
+
+		assert.ErrorIs(t, err, models.WEBHOOK_SUBSCRIPTION_NOT_FOUND)
+		mocks.delivery.AssertNotCalled(t, "UpdateIfStatus")
+	})
+
+	t.Run("does not redeliver another client's delivery", func(t *testing.T) {
+		service, mocks := newWebhookService()
+
+		mocks.delivery.On("Get", ctx, "WHD-1").Return(&schema.WebhookDelivery{
+			Id:             "WHD-1",
+			SubscriptionId: "WHK-1",
+			Status:         models.WebhookDeliveryStatusFailed,
+		}, nil).Once()
+		mocks.subscription.On("Get", ctx, "WHK-1").
+			Return(&schema.WebhookSubscription{Id: "WHK-1", ClientId: "other", Active: true}, nil).Once()
+
+		_, err := service.Redeliver(ctx, "los", "WHD-1")
+
+		assert.ErrorIs(t, err, models.WEBHOOK_DELIVERY_NOT_FOUND)
+		mocks.delivery.AssertNotCalled(t, "UpdateIfStatus")
+	})
+
+	t.Run("returns not found for unknown delivery", func(t *testing.T) {
+		service, mocks := newWebhookService()
+
+		mocks.delivery.On("Get", ctx, "WHD-404").Return(nil, gorm.ErrRecordNotFound).Once()
+
+		_, err := service.Redeliver(ctx, "los", "WHD-404")
+
+		assert.ErrorIs(t, err, models.WEBHOOK_DELIVERY_NOT_FOUND)
+	})
+}
+
+func TestWebhookService_Unsubscribe(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("deactivates the client's subscription", func(t *testing.T) {
+		service, mocks := newWebhookService()
+
+		mocks.subscription.On("Get", ctx, "WHK-1").
+			Return(&schema.WebhookSubscription{Id: "WHK-1", ClientId: "los", Active: true}, nil).Once()
+		mocks.subscription.On("Update", ctx, "WHK-1", mock.MatchedBy(func(fields map[string]any) bool {
+			return fields["active"] == false
+		})).Return(nil).Once()
+
+		err := service.Unsubscribe(ctx, "los", "WHK-1")
+
+		assert.NoError(t, err)
+		mocks.subscription.AssertExpectations(t)
+	})
+
+	t.Run("returns not found for another client's subscription", func(t *testing.T) {
+		service, mocks := newWebhookService()
+
+		mocks.subscription.On("Get", ctx, "WHK-1").
+			Return(&schema.WebhookSubscription{Id: "WHK-1", ClientId: "other", Active: true}, nil).Once()
+
+		err := service.Unsubscribe(ctx, "los", "WHK-1")
+
+		assert.ErrorIs(t, err, models.WEBHOOK_SUBSCRIPTION_NOT_FOUND)
+		mocks.subscription.AssertNotCalled(t, "Update")
+	})
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, webhookBackoff(1))
+	assert.Equal(t, time.Minute, webhookBackoff(2))
+	assert.Equal(t, 4*time.Minute, webhookBackoff(4))
+	assert.Equal(t, webhookMaxBackoff, webhookBackoff(20))
+	assert.Equal(t, webhookMaxBackoff, webhookBackoff(80))
+}
@@ -0,0 +1,62 @@
+// Package auth decides what API callers may do. Callers are identified by
+// the shared authn package; each of their roles grants a fixed set of
+// permissions that routes are mapped to.
+package auth
+
+import (
+	"errors"
+	"shared/authn"
+	"shared/pii"
+	"slices"
+)
+
+var ErrPermissionDenied = errors.New("permission denied")
+
+type Permission string
+
+const (
+	PermissionLoanWrite      Permission = "loan:write"
+	PermissionDisburse       Permission = "disburse"
+	PermissionRetry          Permission = "disburse:retry"
+	PermissionAdmin          Permission = "admin"
+	PermissionReconciliation Permission = "reconciliation"
+	// PermissionNotify lets the payment gateway report transfer outcomes.
+	PermissionNotify Permission = "payment:notify"
+	// PermissionViewPII shows account numbers and beneficiary names unmasked.
+	PermissionViewPII Permission = pii.ViewPermission
+)
+
+// Roles callers can hold. The operator roles match models.OperatorRole.
+const (
+	RoleOpsAdmin        = "ops_admin"
+	RoleOpsSupport      = "ops_support"
+	RoleLoanOrigination = "loan_origination"
+	RoleFinance         = "finance"
+	RolePaymentGateway  = "payment_gateway"
+)
+
+var rolePermissions = map[string][]Permission{
+	RoleOpsAdmin: {
+		PermissionLoanWrite,
+		PermissionDisburse,
+		PermissionRetry,
+		PermissionAdmin,
+		PermissionReconciliation,
+		PermissionViewPII,
+	},
+	RoleOpsSupport:      {PermissionRetry, PermissionAdmin},
+	RoleLoanOrigination: {PermissionLoanWrite, PermissionDisburse, PermissionRetry},
+	RoleFinance:         {PermissionReconciliation},
+	RolePaymentGateway:  {PermissionNotify},
+}
+
+// Can reports whether any of the principal's roles grants permission.
+// Roles this service does not know grant nothing.
+func Can(principal authn.Principal, permission Permission) bool {
+	for _, role := range principal.Roles {
+		if slices.Contains(rolePermissions[role], permission) {
+			return true
+		}
+	}
+	return false
+}
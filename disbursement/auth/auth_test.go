@@ -0,0 +1,20 @@
+package auth
+
+import (
+	"shared/authn"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCan(t *testing.T) {
+	origination := authn.Principal{Subject: "los", Roles: []string{RoleLoanOrigination}}
+	finance := authn.Principal{Subject: "recon", Roles: []string{RoleFinance, "unknown"}}
+
+	assert.True(t, Can(origination, PermissionDisburse))
+	assert.True(t, Can(origination, PermissionLoanWrite))
+	assert.False(t, Can(origination, PermissionAdmin))
+	assert.True(t, Can(finance, PermissionReconciliation))
+	assert.False(t, Can(finance, PermissionViewPII))
+	assert.False(t, Can(authn.Principal{}, PermissionDisburse))
+}
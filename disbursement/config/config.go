@@ -0,0 +1,323 @@
+// Package config holds the service's tunable settings: the port it listens
+// on, HTTP client timeouts, how often the workers poll and how much they take
+// at a time, the retry backoff, the amounts that decide a payment channel,
+// the name match thresholds, the queue sizes and the API rate limits.
+// Settings are read from a YAML file, and each can be overridden by an
+// environment variable named after its path, so worker.retry.interval is
+// overridden by WORKER_RETRY_INTERVAL. Anything left out keeps its default.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"loan-disbursement-service/ratelimit"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrInvalidConfig = errors.New("invalid config")
+
+type Config struct {
+	Server     Server           `yaml:"server"`
+	HTTP       HTTP             `yaml:"http"`
+	Worker     Worker           `yaml:"worker"`
+	Retry      Retry            `yaml:"retry"`
+	Channels   Channels         `yaml:"channels"`
+	NameMatch  NameMatch        `yaml:"name_match"`
+	Queues     Queues           `yaml:"queues"`
+	RateLimits ratelimit.Config `yaml:"rate_limits"`
+}
+
+type Server struct {
+	Port string `yaml:"port"`
+}
+
+// HTTP is for the clients calling the payment gateway, loan origination and
+// webhook subscribers.
+type HTTP struct {
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+type Worker struct {
+	Retry      Job `yaml:"retry"`
+	NEFT       Job `yaml:"neft"`
+	Scheduled  Job `yaml:"scheduled"`
+	DeadLetter Job `yaml:"dead_letter"`
+	Webhook    Job `yaml:"webhook"`
+	Batch      Job `yaml:"batch"`
+	PennyDrop  Job `yaml:"penny_drop"`
+}
+
+// Job is a polling worker: every Interval it takes up to BatchSize records
+// at a time until none are left.
+type Job struct {
+	Interval  time.Duration `yaml:"interval"`
+	BatchSize int           `yaml:"batch_size"`
+}
+
+// Retry is the backoff between attempts at a failed payment, doubling from
+// InitialDelay up to MaxDelay, and how many retries it gets before it fails.
+type Retry struct {
+	MaxRetries   int           `yaml:"max_retries"`
+	InitialDelay time.Duration `yaml:"initial_delay"`
+	MaxDelay     time.Duration `yaml:"max_delay"`
+}
+
+// Channels are the largest amounts sent over UPI and IMPS. Larger amounts
+// go over NEFT.
+type Channels struct {
+	UPILimit  float64 `yaml:"upi_limit"`
+	IMPSLimit float64 `yaml:"imps_limit"`
+}
+
+// NameMatch are the scores, from 0 to 100, below which a disbursement is
+// refused and below which it proceeds but is flagged for review.
+type NameMatch struct {
+	BlockThreshold float64 `yaml:"block_threshold"`
+	FlagThreshold  float64 `yaml:"flag_threshold"`
+}
+
+// Queues are how many disbursements and batches wait in memory for the
+// payment and batch workers.
+type Queues struct {
+	Payment int `yaml:"payment"`
+	Batch   int `yaml:"batch"`
+}
+
+func Default() Config {
+	return Config{
+		Server: Server{Port: "7070"},
+		HTTP:   HTTP{Timeout: 30 * time.Second},
+		Worker: Worker{
+			Retry:      Job{Interval: 15 * time.Second, BatchSize: 10},
+			NEFT:       Job{Interval: 60 * time.Second, BatchSize: 10},
+			Scheduled:  Job{Interval: 30 * time.Second, BatchSize: 50},
+			DeadLetter: Job{Interval: 60 * time.Second, BatchSize: 50},
+			Webhook:    Job{Interval: 10 * time.Second, BatchSize: 100},
+			Batch:      Job{Interval: 60 * time.Second, BatchSize: 10},
+			PennyDrop:  Job{Interval: 30 * time.Second, BatchSize: 10},
+		},
+		Retry: Retry{
+			MaxRetries:   5,
+			InitialDelay: 30 * time.Second,
+			MaxDelay:     30 * time.Minute,
+		},
+		Channels:   Channels{UPILimit: 100000, IMPSLimit: 500000},
+		NameMatch:  NameMatch{BlockThreshold: 60, FlagThreshold: 85},
+		Queues:     Queues{Payment: 100, Batch: 10},
+		RateLimits: ratelimit.DefaultConfig(),
+	}
+}
+
+// Load reads the YAML file at path over the defaults, applies environment
+// overrides and validates the result. An empty path gives the defaults with
+// the overrides applied.
+func Load(path string) (Config, error) {
+	config := Default()
+	if path != "" {
+		file, err := os.ReadFile(path)
+		if err != nil {
+			return Config{}, err
+		}
+		decoder := yaml.NewDecoder(bytes.NewReader(file))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&config); err != nil && err != io.EOF {
+			return Config{}, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+		}
+	}
+	if err := applyEnv(reflect.ValueOf(&config).Elem(), nil); err != nil {
+		return Config{}, err
+	}
+	if err := config.Validate(); err != nil {
+		return Config{}, err
+	}
+	return config, nil
+}
+
+func (c Config) Validate() error {
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("%w: server.port %q is not a port", ErrInvalidConfig, c.Server.Port)
+	}
+	if c.HTTP.Timeout <= 0 {
+		return fmt.Errorf("%w: http.timeout must be positive", ErrInvalidConfig)
+	}
+	for name, job := range map[string]Job{
+		"retry":       c.Worker.Retry,
+		"neft":        c.Worker.NEFT,
+		"scheduled":   c.Worker.Scheduled,
+		"dead_letter": c.Worker.DeadLetter,
+		"webhook":     c.Worker.Webhook,
+		"batch":       c.Worker.Batch,
+		"penny_drop":  c.Worker.PennyDrop,
+	} {
+		if job.Interval <= 0 || job.BatchSize <= 0 {
+			return fmt.Errorf(
+				"%w: worker.%s needs a positive interval and batch_size, got %s and %d",
+				ErrInvalidConfig, name, job.Interval, job.BatchSize,
+			)
+		}
+	}
+	if c.Retry.MaxRetries < 0 {
+		return fmt.Errorf("%w: retry.max_retries must not be negative", ErrInvalidConfig)
+	}
+	if c.Retry.InitialDelay <= 0 || c.Retry.MaxDelay < c.Retry.InitialDelay {
+		return fmt.Errorf(
+			"%w: retry delays %s and %s, want 0 < initial_delay <= max_delay",
+			ErrInvalidConfig, c.Retry.InitialDelay, c.Retry.MaxDelay,
+		)
+	}
+	if c.Channels.UPILimit <= 0 || c.Channels.IMPSLimit < c.Channels.UPILimit {
+		return fmt.Errorf(
+			"%w: channel limits %.2f and %.2f, want 0 < upi_limit <= imps_limit",
+			ErrInvalidConfig, c.Channels.UPILimit, c.Channels.IMPSLimit,
+		)
+	}
+	if c.NameMatch.BlockThreshold < 0 || c.NameMatch.FlagThreshold > 100 ||
+		c.NameMatch.BlockThreshold > c.NameMatch.FlagThreshold {
+		return fmt.Errorf(
+			"%w: name match thresholds %.2f and %.2f, want 0 <= block_threshold <= flag_threshold <= 100",
+			ErrInvalidConfig, c.NameMatch.BlockThreshold, c.NameMatch.FlagThreshold,
+		)
+	}
+	if c.Queues.Payment <= 0 || c.Queues.Batch <= 0 {
+		return fmt.Errorf(
+			"%w: queue sizes must be positive, got payment %d and batch %d",
+			ErrInvalidConfig, c.Queues.Payment, c.Queues.Batch,
+		)
+	}
+	if err := c.RateLimits.Validate(); err != nil {
+		return fmt.Errorf("%w: rate_limits: %v", ErrInvalidConfig, err)
+	}
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// applyEnv sets each field of value from the environment variable named
+// after its path of YAML keys, upper-cased and joined by underscores.
+func applyEnv(value reflect.Value, path []string) error {
+	for i := range value.NumField() {
+		field := value.Field(i)
+		key, _, _ := strings.Cut(value.Type().Field(i).Tag.Get("yaml"), ",")
+		fieldPath := append(path[:len(path):len(path)], key)
+		if field.Kind() == reflect.Struct {
+			if err := applyEnv(field, fieldPath); err != nil {
+				return err
+			}
+			continue
+		}
+		name := strings.ToUpper(strings.Join(fieldPath, "_"))
+		env, ok := os.LookupEnv(name)
+		if !ok || env == "" {
+			continue
+		}
+		if err := setField(field, env); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidConfig, name, err)
+		}
+	}
+	return nil
+}
+
+func setField(field reflect.Value, env string) error {
+	switch {
+	case field.Type() == durationType:
+		duration, err := time.ParseDuration(env)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+	case field.Kind() == reflect.String:
+		field.SetString(env)
+	case field.Kind() == reflect.Int:
+		parsed, err := strconv.Atoi(env)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(parsed))
+	case field.Kind() == reflect.Float64:
+		parsed, err := strconv.ParseFloat(env, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(parsed)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+// Store holds the configuration the service is running with, for the parts
+// that read it as they go. It is safe for concurrent use. A nil Store gives
+// the defaults, so components can be built without one in tests.
+type Store struct {
+	path    string
+	current atomic.Pointer[Config]
+	mu      sync.Mutex
+	changed chan struct{}
+}
+
+// NewStore starts a Store at config, which Reload refreshes from path.
+func NewStore(path string, config Config) *Store {
+	store := &Store{path: path, changed: make(chan struct{})}
+	store.current.Store(&config)
+	return store
+}
+
+func (s *Store) Get() Config {
+	if s == nil {
+		return Default()
+	}
+	return *s.current.Load()
+}
+
+// Changed is closed by the next successful Reload, for workers to pick up
+// new intervals at once. Call it again after each reload. A nil Store never
+// changes.
+func (s *Store) Changed() <-chan struct{} {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.changed
+}
+
+// Reload loads the configuration again and swaps it in. The server, HTTP and
+// queue settings are only read at startup, so they keep their running
+// values; restart lists those that changed and need a restart to take
+// effect. A configuration that fails to load or validate is not applied.
+func (s *Store) Reload() (restart []string, err error) {
+	next, err := Load(s.path)
+	if err != nil {
+		return nil, err
+	}
+	current := s.Get()
+	if next.Server.Port != current.Server.Port {
+		restart = append(restart, "server.port")
+	}
+	if next.HTTP.Timeout != current.HTTP.Timeout {
+		restart = append(restart, "http.timeout")
+	}
+	if next.Queues != current.Queues {
+		restart = append(restart, "queues")
+	}
+	next.Server = current.Server
+	next.HTTP = current.HTTP
+	next.Queues = current.Queues
+	s.current.Store(&next)
+
+	s.mu.Lock()
+	close(s.changed)
+	s.changed = make(chan struct{})
+	s.mu.Unlock()
+	return restart, nil
+}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"errors"
+	"loan-disbursement-service/ratelimit"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("gives the defaults without a path", func(t *testing.T) {
+		config, err := Load("")
+
+		assert.NoError(t, err)
+		assert.Equal(t, Default(), config)
+	})
+
+	t.Run("reads the file over the defaults", func(t *testing.T) {
+		path := writeFile(t, `
+server:
+  port: "9090"
+worker:
+  retry:
+    interval: 5s
+retry:
+  max_retries: 3
+channels:
+  upi_limit: 50000
+rate_limits:
+  routes:
+    POST /api/v1/batch: {rate: 2, burst: 4}
+`)
+
+		config, err := Load(path)
+
+		assert.NoError(t, err)
+		want := Default()
+		want.Server.Port = "9090"
+		want.Worker.Retry.Interval = 5 * time.Second
+		want.Retry.MaxRetries = 3
+		want.Channels.UPILimit = 50000
+		want.RateLimits.Routes["POST /api/v1/batch"] = ratelimit.Limit{Rate: 2, Burst: 4}
+		assert.Equal(t, want, config)
+	})
+
+	t.Run("reads an empty file as the defaults", func(t *testing.T) {
+		config, err := Load(writeFile(t, ""))
+
+		assert.NoError(t, err)
+		assert.Equal(t, Default(), config)
+	})
+
+	t.Run("overrides from the environment", func(t *testing.T) {
+		t.Setenv("SERVER_PORT", "8081")
+		t.Setenv("HTTP_TIMEOUT", "5s")
+		t.Setenv("WORKER_DEAD_LETTER_BATCH_SIZE", "20")
+		t.Setenv("CHANNELS_IMPS_LIMIT", "200000")
+		t.Setenv("NAME_MATCH_BLOCK_THRESHOLD", "70")
+
+		config, err := Load(writeFile(t, "server:\n  port: \"9090\"\n"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "8081", config.Server.Port)
+		assert.Equal(t, 5*time.Second, config.HTTP.Timeout)
+		assert.Equal(t, 20, config.Worker.DeadLetter.BatchSize)
+		assert.Equal(t, 200000.0, config.Channels.IMPSLimit)
+		assert.Equal(t, 70.0, config.NameMatch.BlockThreshold)
+	})
+
+	t.Run("rejects an unparsable override", func(t *testing.T) {
+		t.Setenv("WORKER_NEFT_INTERVAL", "soon")
+
+		_, err := Load("")
+
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+	})
+
+	for name, file := range map[string]string{
+		"unknown key":      "worker:\n  retry:\n    intervl: 5s\n",
+		"malformed yaml":   "server: [",
+		"bad duration":     "http:\n  timeout: soon\n",
+		"bad port":         "server:\n  port: \"70700\"\n",
+		"zero batch":       "worker:\n  webhook:\n    batch_size: 0\n",
+		"negative retries": "retry:\n  max_retries: -1\n",
+		"delays reversed":  "retry:\n  initial_delay: 1h\n  max_delay: 1m\n",
+		"limits reversed":  "channels:\n  upi_limit: 600000\n",
+		"flag over 100":    "name_match:\n  flag_threshold: 101\n",
+		"empty queue":      "queues:\n  payment: 0\n",
+		"burstless limit":  "rate_limits:\n  default: {rate: 1, burst: 0}\n",
+	} {
+		t.Run("rejects "+name, func(t *testing.T) {
+			_, err := Load(writeFile(t, file))
+
+			assert.ErrorIs(t, err, ErrInvalidConfig)
+		})
+	}
+
+	t.Run("fails on a missing file", func(t *testing.T) {
+		_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+
+		assert.True(t, errors.Is(err, os.ErrNotExist))
+	})
+}
+
+func TestStore_Reload(t *testing.T) {
+	t.Run("applies new settings and keeps the startup ones", func(t *testing.T) {
+		path := writeFile(t, "")
+		store := NewStore(path, Default())
+		if err := os.WriteFile(path, []byte(`
+server:
+  port: "9090"
+worker:
+  neft:
+    interval: 2m
+`), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		restart, err := store.Reload()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"server.port"}, restart)
+		assert.Equal(t, "7070", store.Get().Server.Port)
+		assert.Equal(t, 2*time.Minute, store.Get().Worker.NEFT.Interval)
+	})
+
+	t.Run("keeps the running settings when the file is invalid", func(t *testing.T) {
+		path := writeFile(t, "")
+		store := NewStore(path, Default())
+		if err := os.WriteFile(path, []byte("retry:\n  max_retries: -1\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := store.Reload()
+
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+		assert.Equal(t, Default(), store.Get())
+	})
+
+	t.Run("gives the defaults without a store", func(t *testing.T) {
+		assert.Equal(t, Default(), (*Store)(nil).Get())
+	})
+}
@@ -0,0 +1,38 @@
+package daos
+
+import (
+	"context"
+	"loan-disbursement-service/db/schema"
+
+	"gorm.io/gorm"
+)
+
+type AuditRepository interface {
+	Create(ctx context.Context, entry schema.AuditLog) error
+	ListByEntity(ctx context.Context, entityType string, entityId string) ([]schema.AuditLog, error)
+}
+
+type AuditDAO struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &AuditDAO{db: db}
+}
+
+func (a AuditDAO) Create(ctx context.Context, entry schema.AuditLog) error {
+	return a.db.WithContext(ctx).Create(&entry).Error
+}
+
+func (a AuditDAO) ListByEntity(
+	ctx context.Context,
+	entityType string,
+	entityId string,
+) ([]schema.AuditLog, error) {
+	var entries []schema.AuditLog
+	err := a.db.WithContext(ctx).
+		Where("entity_type = ? AND entity_id = ?", entityType, entityId).
+		Order("created_at ASC").
+		Find(&entries).Error
+	return entries, err
+}
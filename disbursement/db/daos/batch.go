@@ -0,0 +1,115 @@
+package daos
+
+import (
+	"context"
+	"loan-disbursement-service/db/schema"
+	"loan-disbursement-service/models"
+	"shared/encryption"
+
+	"gorm.io/gorm"
+)
+
+type BatchRepository interface {
+	Create(ctx context.Context, batch schema.Batch, rows []schema.BatchRow) (*schema.Batch, error)
+	Get(ctx context.Context, id string) (*schema.Batch, error)
+	Update(ctx context.Context, id string, fields map[string]any) error
+	ListByStatus(ctx context.Context, status []models.BatchStatus) ([]schema.Batch, error)
+	ListRows(ctx context.Context, batchId string) ([]schema.BatchRow, error)
+	UpdateRow(ctx context.Context, batchId string, rowNumber int, fields map[string]any) error
+}
+
+// BatchDAO stores the account numbers of rows encrypted with cipher and
+// returns them decrypted.
+type BatchDAO struct {
+	db     *gorm.DB
+	cipher *encryption.Cipher
+}
+
+func NewBatchRepository(db *gorm.DB, cipher *encryption.Cipher) BatchRepository {
+	return &BatchDAO{db: db, cipher: cipher}
+}
+
+// Create stores the batch and all of its rows together so the worker never
+// sees a batch with only some of its rows.
+func (b BatchDAO) Create(
+	ctx context.Context,
+	batch schema.Batch,
+	rows []schema.BatchRow,
+) (*schema.Batch, error) {
+	sealed := make([]schema.BatchRow, len(rows))
+	for i, row := range rows {
+		account, err := b.cipher.Encrypt(ctx, row.AccountNumber)
+		if err != nil {
+			return nil, err
+		}
+		row.AccountNumber = account
+		sealed[i] = row
+	}
+	err := b.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&batch).Error; err != nil {
+			return err
+		}
+		return tx.CreateInBatches(&sealed, 500).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (b BatchDAO) Get(ctx context.Context, id string) (*schema.Batch, error) {
+	var batch schema.Batch
+	if err := b.db.WithContext(ctx).Where("id = ?", id).First(&batch).Error; err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (b BatchDAO) Update(ctx context.Context, id string, fields map[string]any) error {
+	return b.db.WithContext(ctx).Model(&schema.Batch{}).
+		Where("id = ?", id).
+		Updates(fields).Error
+}
+
+func (b BatchDAO) ListByStatus(
+	ctx context.Context,
+	status []models.BatchStatus,
+) ([]schema.Batch, error) {
+	var batches []schema.Batch
+	if err := b.db.WithContext(ctx).Model(&schema.Batch{}).
+		Where("status IN ?", status).
+		Order("created_at ASC").
+		Find(&batches).Error; err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
+
+func (b BatchDAO) ListRows(ctx context.Context, batchId string) ([]schema.BatchRow, error) {
+	var rows []schema.BatchRow
+	if err := b.db.WithContext(ctx).Model(&schema.BatchRow{}).
+		Where("batch_id = ?", batchId).
+		Order("row_number ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		account, err := b.cipher.Decrypt(ctx, rows[i].AccountNumber)
+		if err != nil {
+			return nil, err
+		}
+		rows[i].AccountNumber = account
+	}
+	return rows, nil
+}
+
+func (b BatchDAO) UpdateRow(
+	ctx context.Context,
+	batchId string,
+	rowNumber int,
+	fields map[string]any,
+) error {
+	return b.db.WithContext(ctx).Model(&schema.BatchRow{}).
+		Where("batch_id = ? AND row_number = ?", batchId, rowNumber).
+		Updates(fields).Error
+}
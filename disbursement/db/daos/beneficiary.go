@@ -4,12 +4,15 @@ import (
 	"context"
 	"errors"
 	"loan-disbursement-service/db/schema"
+	"loan-disbursement-service/models"
+	"maps"
+	"shared/encryption"
 
 	"gorm.io/gorm"
 )
 
 type BeneficiaryRepository interface {
-	Create(ctx context.Context, id, account, ifsc, bank string) (*schema.Beneficiary, error)
+	Create(ctx context.Context, id, name, account, ifsc, bank string) (*schema.Beneficiary, error)
 	CreateOrGet(
 		ctx context.Context,
 		id, name, account, ifsc, bank string,
@@ -17,29 +20,51 @@ type BeneficiaryRepository interface {
 	Get(ctx context.Context, account, ifsc, bank string) (*schema.Beneficiary, error)
 	GetById(ctx context.Context, id string) (*schema.Beneficiary, error)
 	Update(ctx context.Context, id string, fields map[string]any) error
+	List(ctx context.Context) ([]schema.Beneficiary, error)
+	ListPendingPennyDrop(ctx context.Context, limit int) ([]schema.Beneficiary, error)
+	Delete(ctx context.Context, id string) error
+	WithTx(tx *gorm.DB) BeneficiaryRepository
 }
+
+// BeneficiaryDAO stores account numbers encrypted with cipher and returns
+// them decrypted.
 type BeneficiaryDAO struct {
-	db *gorm.DB
+	db     *gorm.DB
+	cipher *encryption.Cipher
+}
+
+func NewBeneficiaryRepository(db *gorm.DB, cipher *encryption.Cipher) BeneficiaryRepository {
+	return &BeneficiaryDAO{db: db, cipher: cipher}
 }
 
-func NewBeneficiaryRepository(db *gorm.DB) BeneficiaryRepository {
-	return &BeneficiaryDAO{db: db}
+// WithTx returns the repository with its queries running in tx.
+func (b BeneficiaryDAO) WithTx(tx *gorm.DB) BeneficiaryRepository {
+	return &BeneficiaryDAO{db: tx, cipher: b.cipher}
 }
 
 func (b BeneficiaryDAO) Create(
 	ctx context.Context,
-	id, account, ifsc, bank string,
+	id, name, account, ifsc, bank string,
 ) (*schema.Beneficiary, error) {
+	sealed, err := b.cipher.Encrypt(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	index := b.cipher.BlindIndex(account)
 	beneficiary := &schema.Beneficiary{
-		Id:      id,
-		Account: account,
-		IFSC:    ifsc,
-		Bank:    bank,
+		Id:           id,
+		Name:         name,
+		Account:      sealed,
+		AccountIndex: &index,
+		IFSC:         ifsc,
+		Bank:         bank,
+		Status:       models.BeneficiaryStatusUnverified,
 	}
 
 	if err := b.db.WithContext(ctx).Model(&schema.Beneficiary{}).Create(beneficiary).Error; err != nil {
 		return nil, err
 	}
+	beneficiary.Account = account
 	return beneficiary, nil
 }
 
@@ -55,7 +80,7 @@ func (b BeneficiaryDAO) CreateOrGet(
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, err
 	}
-	beneficiary, err = b.Create(ctx, id, account, ifsc, bank)
+	beneficiary, err = b.Create(ctx, id, name, account, ifsc, bank)
 	if err != nil {
 		return nil, err
 	}
@@ -68,11 +93,11 @@ func (b BeneficiaryDAO) Get(
 ) (*schema.Beneficiary, error) {
 	var beneficiary schema.Beneficiary
 	if err := b.db.WithContext(ctx).Model(&schema.Beneficiary{}).
-		Where("account = ? AND ifsc = ? AND bank = ?", account, ifsc, bank).
+		Where("account_index = ? AND ifsc = ? AND bank = ?", b.cipher.BlindIndex(account), ifsc, bank).
 		First(&beneficiary).Error; err != nil {
 		return nil, err
 	}
-	return &beneficiary, nil
+	return b.decrypt(ctx, &beneficiary)
 }
 
 func (b BeneficiaryDAO) GetById(
@@ -85,11 +110,77 @@ func (b BeneficiaryDAO) GetById(
 		First(&beneficiary).Error; err != nil {
 		return nil, err
 	}
-	return &beneficiary, nil
+	return b.decrypt(ctx, &beneficiary)
 }
 
 func (b BeneficiaryDAO) Update(ctx context.Context, id string, fields map[string]any) error {
+	if account, ok := fields["account"].(string); ok {
+		sealed, err := b.cipher.Encrypt(ctx, account)
+		if err != nil {
+			return err
+		}
+		fields = maps.Clone(fields)
+		fields["account"] = sealed
+		fields["account_index"] = b.cipher.BlindIndex(account)
+	}
 	return b.db.WithContext(ctx).Model(&schema.Beneficiary{}).
 		Where("id = ?", id).
 		Updates(fields).Error
 }
+
+func (b BeneficiaryDAO) List(ctx context.Context) ([]schema.Beneficiary, error) {
+	var beneficiaries []schema.Beneficiary
+	if err := b.db.WithContext(ctx).Model(&schema.Beneficiary{}).
+		Order("created_at DESC").
+		Find(&beneficiaries).Error; err != nil {
+		return nil, err
+	}
+	for i := range beneficiaries {
+		if _, err := b.decrypt(ctx, &beneficiaries[i]); err != nil {
+			return nil, err
+		}
+	}
+	return beneficiaries, nil
+}
+
+// ListPendingPennyDrop returns unverified beneficiaries whose account has not
+// been penny dropped yet, least recently updated first.
+func (b BeneficiaryDAO) ListPendingPennyDrop(ctx context.Context, limit int) ([]schema.Beneficiary, error) {
+	var beneficiaries []schema.Beneficiary
+	if err := b.db.WithContext(ctx).Model(&schema.Beneficiary{}).
+		Where("status = ? AND penny_dropped_at IS NULL", models.BeneficiaryStatusUnverified).
+		Order("updated_at ASC").
+		Limit(limit).
+		Find(&beneficiaries).Error; err != nil {
+		return nil, err
+	}
+	for i := range beneficiaries {
+		if _, err := b.decrypt(ctx, &beneficiaries[i]); err != nil {
+			return nil, err
+		}
+	}
+	return beneficiaries, nil
+}
+
+func (b BeneficiaryDAO) Delete(ctx context.Context, id string) error {
+	result := b.db.WithContext(ctx).Where("id = ?", id).Delete(&schema.Beneficiary{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (b BeneficiaryDAO) decrypt(
+	ctx context.Context,
+	beneficiary *schema.Beneficiary,
+) (*schema.Beneficiary, error) {
+	account, err := b.cipher.Decrypt(ctx, beneficiary.Account)
+	if err != nil {
+		return nil, err
+	}
+	beneficiary.Account = account
+	return beneficiary, nil
+}
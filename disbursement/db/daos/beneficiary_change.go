@@ -0,0 +1,65 @@
+package daos
+
+import (
+	"context"
+	"loan-disbursement-service/db/schema"
+	"shared/encryption"
+
+	"gorm.io/gorm"
+)
+
+type BeneficiaryChangeRepository interface {
+	Create(ctx context.Context, change schema.BeneficiaryChange) error
+	ListByLoan(ctx context.Context, loanId string) ([]schema.BeneficiaryChange, error)
+	WithTx(tx *gorm.DB) BeneficiaryChangeRepository
+}
+
+// BeneficiaryChangeDAO stores the previous account number encrypted with
+// cipher and returns it decrypted.
+type BeneficiaryChangeDAO struct {
+	db     *gorm.DB
+	cipher *encryption.Cipher
+}
+
+func NewBeneficiaryChangeRepository(
+	db *gorm.DB,
+	cipher *encryption.Cipher,
+) BeneficiaryChangeRepository {
+	return &BeneficiaryChangeDAO{db: db, cipher: cipher}
+}
+
+// WithTx returns the repository with its queries running in tx.
+func (b BeneficiaryChangeDAO) WithTx(tx *gorm.DB) BeneficiaryChangeRepository {
+	return &BeneficiaryChangeDAO{db: tx, cipher: b.cipher}
+}
+
+func (b BeneficiaryChangeDAO) Create(ctx context.Context, change schema.BeneficiaryChange) error {
+	sealed, err := b.cipher.Encrypt(ctx, change.PreviousAccount)
+	if err != nil {
+		return err
+	}
+	change.PreviousAccount = sealed
+	return b.db.WithContext(ctx).Create(&change).Error
+}
+
+func (b BeneficiaryChangeDAO) ListByLoan(
+	ctx context.Context,
+	loanId string,
+) ([]schema.BeneficiaryChange, error) {
+	var changes []schema.BeneficiaryChange
+	err := b.db.WithContext(ctx).
+		Where("loan_id = ?", loanId).
+		Order("created_at ASC").
+		Find(&changes).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range changes {
+		account, err := b.cipher.Decrypt(ctx, changes[i].PreviousAccount)
+		if err != nil {
+			return nil, err
+		}
+		changes[i].PreviousAccount = account
+	}
+	return changes, nil
+}
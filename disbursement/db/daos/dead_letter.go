@@ -0,0 +1,148 @@
+package daos
+
+import (
+	"context"
+	"loan-disbursement-service/db/schema"
+	"loan-disbursement-service/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DeadLetterRepository interface {
+	Record(ctx context.Context, entry schema.DeadLetter) error
+	Get(ctx context.Context, disbursementId string) (*schema.DeadLetter, error)
+	List(ctx context.Context, filter models.DeadLetterFilter) ([]schema.DeadLetter, error)
+	ListUnnotified(ctx context.Context, limit int) ([]schema.DeadLetter, error)
+	Update(ctx context.Context, disbursementId string, fields map[string]any) error
+	UpdateIfStatus(
+		ctx context.Context,
+		disbursementId string,
+		status models.DeadLetterStatus,
+		fields map[string]any,
+	) (bool, error)
+	WithTx(tx *gorm.DB) DeadLetterRepository
+}
+
+type DeadLetterDAO struct {
+	db *gorm.DB
+}
+
+func NewDeadLetterRepository(db *gorm.DB) DeadLetterRepository {
+	return &DeadLetterDAO{db: db}
+}
+
+// WithTx returns the repository with its queries running in tx.
+func (d DeadLetterDAO) WithTx(tx *gorm.DB) DeadLetterRepository {
+	return &DeadLetterDAO{db: tx}
+}
+
+// Record opens the entry for a failed disbursement, or reopens it when the
+// disbursement failed again after being requeued. A reopened entry is
+// notified again.
+func (d DeadLetterDAO) Record(ctx context.Context, entry schema.DeadLetter) error {
+	return d.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "disbursement_id"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"channel":         entry.Channel,
+			"category":        entry.Category,
+			"last_error":      entry.LastError,
+			"status":          models.DeadLetterStatusOpen,
+			"failure_count":   gorm.Expr("dead_letters.failure_count + 1"),
+			"notified_at":     nil,
+			"resolved_by":     nil,
+			"resolution_note": nil,
+			"resolved_at":     nil,
+			"updated_at":      entry.UpdatedAt,
+		}),
+	}).Create(&entry).Error
+}
+
+func (d DeadLetterDAO) Get(ctx context.Context, disbursementId string) (*schema.DeadLetter, error) {
+	var entry schema.DeadLetter
+	err := d.db.WithContext(ctx).
+		Where("disbursement_id = ?", disbursementId).
+		First(&entry).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// List returns entries newest first. Open entries whose disbursement has
+// since been retried outside the dead-letter queue are left out.
+func (d DeadLetterDAO) List(
+	ctx context.Context,
+	filter models.DeadLetterFilter,
+) ([]schema.DeadLetter, error) {
+	var entries []schema.DeadLetter
+	query := d.scope(ctx, filter.Status)
+	if filter.Category != "" {
+		query = query.Where("dead_letters.category = ?", filter.Category)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if err := query.Order("dead_letters.updated_at DESC").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// ListUnnotified returns open entries the loan origination system has not
+// acknowledged yet, oldest first.
+func (d DeadLetterDAO) ListUnnotified(ctx context.Context, limit int) ([]schema.DeadLetter, error) {
+	var entries []schema.DeadLetter
+	if err := d.scope(ctx, models.DeadLetterStatusOpen).
+		Where("dead_letters.notified_at IS NULL").
+		Order("dead_letters.updated_at ASC").
+		Limit(limit).
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (d DeadLetterDAO) Update(
+	ctx context.Context,
+	disbursementId string,
+	fields map[string]any,
+) error {
+	return d.db.WithContext(ctx).Model(&schema.DeadLetter{}).
+		Where("disbursement_id = ?", disbursementId).
+		Updates(fields).Error
+}
+
+// UpdateIfStatus applies fields only while the entry is still in status, so
+// two operators resolving the same entry cannot both succeed.
+func (d DeadLetterDAO) UpdateIfStatus(
+	ctx context.Context,
+	disbursementId string,
+	status models.DeadLetterStatus,
+	fields map[string]any,
+) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&schema.DeadLetter{}).
+		Where("disbursement_id = ? AND status = ?", disbursementId, status).
+		Updates(fields)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (d DeadLetterDAO) scope(ctx context.Context, status models.DeadLetterStatus) *gorm.DB {
+	query := d.db.WithContext(ctx).Model(&schema.DeadLetter{}).Select("dead_letters.*")
+	if status == "" {
+		return query
+	}
+	query = query.Where("dead_letters.status = ?", status)
+	if status == models.DeadLetterStatusOpen {
+		query = query.
+			Joins("JOIN disbursements ON disbursements.id = dead_letters.disbursement_id").
+			Where("disbursements.status = ?", models.DisbursementStatusFailed)
+	}
+	return query
+}
@@ -4,18 +4,13 @@ import (
 	"context"
 	"loan-disbursement-service/db/schema"
 	"loan-disbursement-service/models"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type DisbursementRepository interface {
-	Create(
-		ctx context.Context,
-		id, loanId string,
-		channel models.PaymentChannel,
-		status models.DisbursementStatus,
-		amount float64,
-	) (*schema.Disbursement, error)
+	Create(ctx context.Context, disbursement schema.Disbursement) (*schema.Disbursement, error)
 	Update(ctx context.Context, id string, fields map[string]any) error
 	Get(ctx context.Context, id string) (*schema.Disbursement, error)
 	GetByLoanId(ctx context.Context, loanId string) (*schema.Disbursement, error)
@@ -29,6 +24,17 @@ type DisbursementRepository interface {
 		ctx context.Context,
 		loanId string,
 	) ([]schema.Disbursement, error)
+	ListByIds(ctx context.Context, ids []string) ([]schema.Disbursement, error)
+	ListDue(ctx context.Context, dueBy time.Time, offset, limit int) ([]schema.Disbursement, error)
+	ListSchedulePending(ctx context.Context, limit int) ([]schema.Disbursement, error)
+	UpdateIfStatus(
+		ctx context.Context,
+		id string,
+		status models.DisbursementStatus,
+		fields map[string]any,
+	) (bool, error)
+	CountByStatus(ctx context.Context) (map[models.DisbursementStatus]int64, error)
+	WithTx(tx *gorm.DB) DisbursementRepository
 }
 type DisbursementDAO struct {
 	db *gorm.DB
@@ -38,27 +44,19 @@ func NewDisbursementRepository(db *gorm.DB) DisbursementRepository {
 	return &DisbursementDAO{db: db}
 }
 
+// WithTx returns the repository with its queries running in tx.
+func (d DisbursementDAO) WithTx(tx *gorm.DB) DisbursementRepository {
+	return &DisbursementDAO{db: tx}
+}
+
 func (d DisbursementDAO) Create(
 	ctx context.Context,
-	id, loanId string,
-	channel models.PaymentChannel,
-	status models.DisbursementStatus,
-	amount float64,
+	disbursement schema.Disbursement,
 ) (*schema.Disbursement, error) {
-	disbursement := &schema.Disbursement{
-		Id:         id,
-		LoanId:     loanId,
-		Channel:    channel,
-		Amount:     amount,
-		Status:     status,
-		RetryCount: 0,
-		LastError:  nil,
-	}
-
-	if err := d.db.WithContext(ctx).Create(disbursement).Error; err != nil {
+	if err := d.db.WithContext(ctx).Create(&disbursement).Error; err != nil {
 		return nil, err
 	}
-	return disbursement, nil
+	return &disbursement, nil
 }
 
 func (d DisbursementDAO) Update(ctx context.Context, id string, fields map[string]any) error {
@@ -80,7 +78,10 @@ func (d DisbursementDAO) GetByLoanId(
 	loanId string,
 ) (*schema.Disbursement, error) {
 	var disbursement schema.Disbursement
-	if err := d.db.WithContext(ctx).Where("loan_id = ?", loanId).First(&disbursement).Error; err != nil {
+	if err := d.db.WithContext(ctx).
+		Where("loan_id = ?", loanId).
+		Order("created_at DESC").
+		First(&disbursement).Error; err != nil {
 		return nil, err
 	}
 	return &disbursement, nil
@@ -123,3 +124,90 @@ func (d DisbursementDAO) ListByLoan(
 	}
 	return disbursements, nil
 }
+
+func (d DisbursementDAO) ListByIds(
+	ctx context.Context,
+	ids []string,
+) ([]schema.Disbursement, error) {
+	var disbursements []schema.Disbursement
+	if err := d.db.WithContext(ctx).Where("id IN ?", ids).Find(&disbursements).Error; err != nil {
+		return nil, err
+	}
+	return disbursements, nil
+}
+
+// ListDue returns scheduled disbursements whose scheduled_at is at or before
+// dueBy, earliest first.
+func (d DisbursementDAO) ListDue(
+	ctx context.Context,
+	dueBy time.Time,
+	offset, limit int,
+) ([]schema.Disbursement, error) {
+	var disbursements []schema.Disbursement
+	if err := d.db.WithContext(ctx).Model(&schema.Disbursement{}).
+		Where("status = ? AND scheduled_at <= ?", models.DisbursementStatusScheduled, dueBy).
+		Order("scheduled_at ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&disbursements).Error; err != nil {
+		return nil, err
+	}
+	return disbursements, nil
+}
+
+// ListSchedulePending returns successful disbursements whose repayment
+// schedule is still to be stored, least recently attempted first.
+func (d DisbursementDAO) ListSchedulePending(
+	ctx context.Context,
+	limit int,
+) ([]schema.Disbursement, error) {
+	var disbursements []schema.Disbursement
+	if err := d.db.WithContext(ctx).Model(&schema.Disbursement{}).
+		Where("status = ? AND schedule_pending", models.DisbursementStatusSuccess).
+		Order("updated_at ASC").
+		Limit(limit).
+		Find(&disbursements).Error; err != nil {
+		return nil, err
+	}
+	return disbursements, nil
+}
+
+// UpdateIfStatus applies fields only while the disbursement is still in
+// status, and reports whether it did. Callers racing on the same disbursement
+// use it so only one of them wins.
+func (d DisbursementDAO) UpdateIfStatus(
+	ctx context.Context,
+	id string,
+	status models.DisbursementStatus,
+	fields map[string]any,
+) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&schema.Disbursement{}).
+		Where("id = ? AND status = ?", id, status).
+		Updates(fields)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// CountByStatus returns how many disbursements are in each status. Statuses
+// with no disbursements are left out.
+func (d DisbursementDAO) CountByStatus(
+	ctx context.Context,
+) (map[models.DisbursementStatus]int64, error) {
+	var rows []struct {
+		Status models.DisbursementStatus
+		Count  int64
+	}
+	if err := d.db.WithContext(ctx).Model(&schema.Disbursement{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[models.DisbursementStatus]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
@@ -0,0 +1,48 @@
+package daos
+
+import (
+	"context"
+	"loan-disbursement-service/db/schema"
+
+	"gorm.io/gorm"
+)
+
+type InstallmentRepository interface {
+	CreateBatch(ctx context.Context, installments []schema.Installment) error
+	ListByLoan(ctx context.Context, loanId string) ([]schema.Installment, error)
+	WithTx(tx *gorm.DB) InstallmentRepository
+}
+
+type InstallmentDAO struct {
+	db *gorm.DB
+}
+
+func NewInstallmentRepository(db *gorm.DB) InstallmentRepository {
+	return &InstallmentDAO{db: db}
+}
+
+// WithTx returns the repository with its queries running in tx.
+func (i InstallmentDAO) WithTx(tx *gorm.DB) InstallmentRepository {
+	return &InstallmentDAO{db: tx}
+}
+
+func (i InstallmentDAO) CreateBatch(
+	ctx context.Context,
+	installments []schema.Installment,
+) error {
+	return i.db.WithContext(ctx).Model(&schema.Installment{}).Create(&installments).Error
+}
+
+func (i InstallmentDAO) ListByLoan(
+	ctx context.Context,
+	loanId string,
+) ([]schema.Installment, error) {
+	var installments []schema.Installment
+	if err := i.db.WithContext(ctx).Model(&schema.Installment{}).
+		Where("loan_id = ?", loanId).
+		Order("due_date ASC, disbursement_id ASC, number ASC").
+		Find(&installments).Error; err != nil {
+		return nil, err
+	}
+	return installments, nil
+}
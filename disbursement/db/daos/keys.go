@@ -0,0 +1,125 @@
+package daos
+
+import (
+	"context"
+	"loan-disbursement-service/db/schema"
+	"shared/encryption"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// sealedColumn is a column holding encrypted account numbers, with the
+// primary key of its table and, for beneficiaries, the blind index kept
+// alongside it.
+type sealedColumn struct {
+	model  any
+	keys   []string
+	column string
+	index  string
+}
+
+var sealedColumns = []sealedColumn{
+	{model: &schema.Beneficiary{}, keys: []string{"id"}, column: "account", index: "account_index"},
+	{model: &schema.BeneficiaryChange{}, keys: []string{"id"}, column: "previous_account"},
+	{model: &schema.BatchRow{}, keys: []string{"batch_id", "row_number"}, column: "account_number"},
+}
+
+const rotationBatchSize = 500
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// RotateKeys rewraps every stored account number whose data key is wrapped
+// with a key other than the current one, and encrypts those stored before
+// encryption was enabled, filling in their blind index. It returns how many
+// values changed. A key can be dropped from the provider once this has run
+// after it stopped being current.
+func RotateKeys(ctx context.Context, db *gorm.DB, cipher *encryption.Cipher) (int, error) {
+	return rewrapAll(ctx, db, cipher, cipher.CurrentPrefix())
+}
+
+// SealPlaintext encrypts the account numbers stored before encryption was
+// enabled and fills in missing blind indexes, leaving values already sealed
+// under any key alone. It returns how many values changed.
+func SealPlaintext(ctx context.Context, db *gorm.DB, cipher *encryption.Cipher) (int, error) {
+	return rewrapAll(ctx, db, cipher, encryption.Prefix)
+}
+
+// rewrapAll rewraps every stored account number that does not start with
+// done, or whose blind index is missing.
+func rewrapAll(ctx context.Context, db *gorm.DB, cipher *encryption.Cipher, done string) (int, error) {
+	rotated := 0
+	for _, sealed := range sealedColumns {
+		count, err := sealed.rotate(ctx, db, cipher, done)
+		rotated += count
+		if err != nil {
+			return rotated, err
+		}
+	}
+	return rotated, nil
+}
+
+func (s sealedColumn) rotate(
+	ctx context.Context,
+	db *gorm.DB,
+	cipher *encryption.Cipher,
+	done string,
+) (int, error) {
+	pattern := likeEscaper.Replace(done) + "%"
+	pending := s.column + " NOT LIKE ?"
+	if s.index != "" {
+		pending = "(" + pending + " OR " + s.index + " IS NULL)"
+	}
+	rotated := 0
+	for {
+		var rows []map[string]any
+		if err := db.WithContext(ctx).Model(s.model).
+			Select(append(s.keys, s.column)).
+			Where(s.column+" <> '' AND "+pending, pattern).
+			Limit(rotationBatchSize).
+			Find(&rows).Error; err != nil {
+			return rotated, err
+		}
+		if len(rows) == 0 {
+			return rotated, nil
+		}
+
+		for _, row := range rows {
+			value, _ := row[s.column].(string)
+			fields, err := s.rewrap(ctx, cipher, value)
+			if err != nil {
+				return rotated, err
+			}
+			query := db.WithContext(ctx).Model(s.model)
+			for _, key := range s.keys {
+				query = query.Where(key+" = ?", row[key])
+			}
+			// UpdateColumns leaves updated_at alone; rotation is not a change
+			// to the record.
+			if err := query.UpdateColumns(fields).Error; err != nil {
+				return rotated, err
+			}
+			rotated++
+		}
+	}
+}
+
+func (s sealedColumn) rewrap(
+	ctx context.Context,
+	cipher *encryption.Cipher,
+	value string,
+) (map[string]any, error) {
+	sealed, err := cipher.Rewrap(ctx, value)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]any{s.column: sealed}
+	if s.index != "" {
+		plaintext, err := cipher.Decrypt(ctx, sealed)
+		if err != nil {
+			return nil, err
+		}
+		fields[s.index] = cipher.BlindIndex(plaintext)
+	}
+	return fields, nil
+}
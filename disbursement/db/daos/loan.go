@@ -3,15 +3,24 @@ package daos
 import (
 	"context"
 	"loan-disbursement-service/db/schema"
+	"loan-disbursement-service/models"
 
 	"gorm.io/gorm"
 )
 
 type LoanRepository interface {
-	Create(ctx context.Context, loanId string, amount float64) (*schema.Loan, error)
+	Create(ctx context.Context, loan schema.Loan) (*schema.Loan, error)
 	Update(ctx context.Context, loanId string, data map[string]any) (*schema.Loan, error)
+	UpdateIfStatus(
+		ctx context.Context,
+		loanId string,
+		status models.LoanStatus,
+		data map[string]any,
+	) (bool, error)
 	List(ctx context.Context) ([]schema.Loan, error)
 	Get(ctx context.Context, loanId string) (*schema.Loan, error)
+	ListByBeneficiary(ctx context.Context, beneficiaryId string) ([]schema.Loan, error)
+	WithTx(tx *gorm.DB) LoanRepository
 }
 
 func NewLoanRepository(db *gorm.DB) LoanRepository {
@@ -24,16 +33,16 @@ type LoanDAO struct {
 	db *gorm.DB
 }
 
-func (l LoanDAO) Create(ctx context.Context, loanId string, amount float64) (*schema.Loan, error) {
-	loan := &schema.Loan{
-		Id:     loanId,
-		Amount: amount,
-	}
+// WithTx returns the repository with its queries running in tx.
+func (l LoanDAO) WithTx(tx *gorm.DB) LoanRepository {
+	return &LoanDAO{db: tx}
+}
 
-	if err := l.db.WithContext(ctx).Model(&schema.Loan{}).Create(loan).Error; err != nil {
+func (l LoanDAO) Create(ctx context.Context, loan schema.Loan) (*schema.Loan, error) {
+	if err := l.db.WithContext(ctx).Model(&schema.Loan{}).Create(&loan).Error; err != nil {
 		return nil, err
 	}
-	return loan, nil
+	return &loan, nil
 }
 
 func (l LoanDAO) Update(
@@ -56,6 +65,24 @@ func (l LoanDAO) Update(
 	return &loan, nil
 }
 
+// UpdateIfStatus applies data only while the loan is still in status, and
+// reports whether it did. Callers racing on the same loan use it so only one
+// of them wins.
+func (l LoanDAO) UpdateIfStatus(
+	ctx context.Context,
+	loanId string,
+	status models.LoanStatus,
+	data map[string]any,
+) (bool, error) {
+	result := l.db.WithContext(ctx).Model(&schema.Loan{}).
+		Where("id = ? AND status = ?", loanId, status).
+		Updates(data)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
 func (l LoanDAO) List(ctx context.Context) ([]schema.Loan, error) {
 	var loans []schema.Loan
 	if err := l.db.WithContext(ctx).Model(&schema.Loan{}).Find(&loans).Error; err != nil {
@@ -73,3 +100,16 @@ func (l LoanDAO) Get(ctx context.Context, loanId string) (*schema.Loan, error) {
 	}
 	return &loan, nil
 }
+
+func (l LoanDAO) ListByBeneficiary(
+	ctx context.Context,
+	beneficiaryId string,
+) ([]schema.Loan, error) {
+	var loans []schema.Loan
+	if err := l.db.WithContext(ctx).Model(&schema.Loan{}).
+		Where("beneficiary_id = ?", beneficiaryId).
+		Find(&loans).Error; err != nil {
+		return nil, err
+	}
+	return loans, nil
+}
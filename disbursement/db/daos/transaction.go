@@ -23,6 +23,7 @@ type TransactionRepository interface {
 		date time.Time,
 		status []models.TransactionStatus,
 	) ([]schema.Transaction, error)
+	WithTx(tx *gorm.DB) TransactionRepository
 }
 
 type TransactionDAO struct {
@@ -33,6 +34,11 @@ func NewTransactionRepository(db *gorm.DB) TransactionRepository {
 	return &TransactionDAO{db: db}
 }
 
+// WithTx returns the repository with its queries running in tx.
+func (t TransactionDAO) WithTx(tx *gorm.DB) TransactionRepository {
+	return &TransactionDAO{db: tx}
+}
+
 func (t TransactionDAO) Create(
 	ctx context.Context,
 	transaction schema.Transaction,
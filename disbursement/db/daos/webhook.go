@@ -0,0 +1,186 @@
+package daos
+
+import (
+	"context"
+	"loan-disbursement-service/db/schema"
+	"loan-disbursement-service/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type WebhookSubscriptionRepository interface {
+	Create(ctx context.Context, subscription schema.WebhookSubscription) error
+	Get(ctx context.Context, id string) (*schema.WebhookSubscription, error)
+	ListByClient(ctx context.Context, clientId string) ([]schema.WebhookSubscription, error)
+	ListActiveByClient(ctx context.Context, clientId string) ([]schema.WebhookSubscription, error)
+	Update(ctx context.Context, id string, fields map[string]any) error
+	WithTx(tx *gorm.DB) WebhookSubscriptionRepository
+}
+
+type WebhookSubscriptionDAO struct {
+	db *gorm.DB
+}
+
+func NewWebhookSubscriptionRepository(db *gorm.DB) WebhookSubscriptionRepository {
+	return &WebhookSubscriptionDAO{db: db}
+}
+
+// WithTx returns the repository with its queries running in tx.
+func (w WebhookSubscriptionDAO) WithTx(tx *gorm.DB) WebhookSubscriptionRepository {
+	return &WebhookSubscriptionDAO{db: tx}
+}
+
+func (w WebhookSubscriptionDAO) Create(
+	ctx context.Context,
+	subscription schema.WebhookSubscription,
+) error {
+	return w.db.WithContext(ctx).Create(&subscription).Error
+}
+
+func (w WebhookSubscriptionDAO) Get(
+	ctx context.Context,
+	id string,
+) (*schema.WebhookSubscription, error) {
+	var subscription schema.WebhookSubscription
+	if err := w.db.WithContext(ctx).Where("id = ?", id).First(&subscription).Error; err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (w WebhookSubscriptionDAO) ListByClient(
+	ctx context.Context,
+	clientId string,
+) ([]schema.WebhookSubscription, error) {
+	var subscriptions []schema.WebhookSubscription
+	err := w.db.WithContext(ctx).
+		Where("client_id = ?", clientId).
+		Order("created_at ASC").
+		Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (w WebhookSubscriptionDAO) ListActiveByClient(
+	ctx context.Context,
+	clientId string,
+) ([]schema.WebhookSubscription, error) {
+	var subscriptions []schema.WebhookSubscription
+	err := w.db.WithContext(ctx).
+		Where("client_id = ? AND active = ?", clientId, true).
+		Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (w WebhookSubscriptionDAO) Update(ctx context.Context, id string, fields map[string]any) error {
+	return w.db.WithContext(ctx).Model(&schema.WebhookSubscription{}).
+		Where("id = ?", id).
+		Updates(fields).Error
+}
+
+type WebhookDeliveryRepository interface {
+	Create(ctx context.Context, deliveries []schema.WebhookDelivery) error
+	Get(ctx context.Context, id string) (*schema.WebhookDelivery, error)
+	ListBySubscription(
+		ctx context.Context,
+		subscriptionId string,
+		filter models.WebhookDeliveryFilter,
+	) ([]schema.WebhookDelivery, error)
+	ListDue(ctx context.Context, now time.Time, limit int) ([]schema.WebhookDelivery, error)
+	Update(ctx context.Context, id string, fields map[string]any) error
+	UpdateIfStatus(
+		ctx context.Context,
+		id string,
+		status models.WebhookDeliveryStatus,
+		fields map[string]any,
+	) (bool, error)
+	WithTx(tx *gorm.DB) WebhookDeliveryRepository
+}
+
+type WebhookDeliveryDAO struct {
+	db *gorm.DB
+}
+
+func NewWebhookDeliveryRepository(db *gorm.DB) WebhookDeliveryRepository {
+	return &WebhookDeliveryDAO{db: db}
+}
+
+// WithTx returns the repository with its queries running in tx.
+func (w WebhookDeliveryDAO) WithTx(tx *gorm.DB) WebhookDeliveryRepository {
+	return &WebhookDeliveryDAO{db: tx}
+}
+
+func (w WebhookDeliveryDAO) Create(ctx context.Context, deliveries []schema.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return w.db.WithContext(ctx).Create(&deliveries).Error
+}
+
+func (w WebhookDeliveryDAO) Get(ctx context.Context, id string) (*schema.WebhookDelivery, error) {
+	var delivery schema.WebhookDelivery
+	if err := w.db.WithContext(ctx).Where("id = ?", id).First(&delivery).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// ListBySubscription returns the subscription's delivery log newest first.
+func (w WebhookDeliveryDAO) ListBySubscription(
+	ctx context.Context,
+	subscriptionId string,
+	filter models.WebhookDeliveryFilter,
+) ([]schema.WebhookDelivery, error) {
+	var deliveries []schema.WebhookDelivery
+	query := w.db.WithContext(ctx).Where("subscription_id = ?", subscriptionId)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	err := query.Order("created_at DESC").Find(&deliveries).Error
+	return deliveries, err
+}
+
+// ListDue returns pending deliveries whose next attempt is due, oldest
+// first so events reach a subscriber roughly in the order they happened.
+func (w WebhookDeliveryDAO) ListDue(
+	ctx context.Context,
+	now time.Time,
+	limit int,
+) ([]schema.WebhookDelivery, error) {
+	var deliveries []schema.WebhookDelivery
+	err := w.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryStatusPending, now).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+func (w WebhookDeliveryDAO) Update(ctx context.Context, id string, fields map[string]any) error {
+	return w.db.WithContext(ctx).Model(&schema.WebhookDelivery{}).
+		Where("id = ?", id).
+		Updates(fields).Error
+}
+
+// UpdateIfStatus applies fields only while the delivery is still in status,
+// so a manual redelivery cannot race the delivery worker.
+func (w WebhookDeliveryDAO) UpdateIfStatus(
+	ctx context.Context,
+	id string,
+	status models.WebhookDeliveryStatus,
+	fields map[string]any,
+) (bool, error) {
+	result := w.db.WithContext(ctx).Model(&schema.WebhookDelivery{}).
+		Where("id = ? AND status = ?", id, status).
+		Updates(fields)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
@@ -1,47 +1,98 @@
 package db
 
 import (
+	"context"
 	"loan-disbursement-service/db/daos"
 	"loan-disbursement-service/db/schema"
+	"shared/encryption"
+	"shared/tracing"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
 type Database struct {
-	db           *gorm.DB
-	loan         daos.LoanRepository
-	beneficiary  daos.BeneficiaryRepository
-	disbursement daos.DisbursementRepository
-	transaction  daos.TransactionRepository
+	db                *gorm.DB
+	cipher            *encryption.Cipher
+	loan              daos.LoanRepository
+	beneficiary       daos.BeneficiaryRepository
+	disbursement      daos.DisbursementRepository
+	transaction       daos.TransactionRepository
+	installment       daos.InstallmentRepository
+	batch             daos.BatchRepository
+	audit             daos.AuditRepository
+	deadLetter        daos.DeadLetterRepository
+	beneficiaryChange daos.BeneficiaryChangeRepository
+	webhook           daos.WebhookSubscriptionRepository
+	webhookDelivery   daos.WebhookDeliveryRepository
 }
 
-func New(dsn string) (*Database, error) {
+func New(dsn string, cipher *encryption.Cipher) (*Database, error) {
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
 	if err != nil {
 		return nil, err
 	}
+	if err := db.Use(tracing.GormPlugin()); err != nil {
+		return nil, err
+	}
 
 	if err := db.AutoMigrate(
 		&schema.Beneficiary{},
 		&schema.Loan{},
 		&schema.Disbursement{},
 		&schema.Transaction{},
+		&schema.Installment{},
+		&schema.Batch{},
+		&schema.BatchRow{},
+		&schema.AuditLog{},
+		&schema.DeadLetter{},
+		&schema.BeneficiaryChange{},
+		&schema.WebhookSubscription{},
+		&schema.WebhookDelivery{},
 	); err != nil {
 		return nil, err
 	}
+	// Beneficiaries used to be unique on the plaintext account, which
+	// idx_beneficiary_account now covers through its blind index.
+	if db.Migrator().HasIndex(&schema.Beneficiary{}, "idx_beneficiary_unique") {
+		if err := db.Migrator().DropIndex(&schema.Beneficiary{}, "idx_beneficiary_unique"); err != nil {
+			return nil, err
+		}
+	}
+	// Account numbers stored before encryption have no blind index, so they
+	// are not found by account and do not count towards uniqueness. Seal
+	// them before serving, after which nothing stored is left in plaintext.
+	if _, err := daos.SealPlaintext(context.Background(), db, cipher); err != nil {
+		return nil, err
+	}
+	cipher = cipher.Strict()
 
 	return &Database{
-		db:           db,
-		loan:         daos.NewLoanRepository(db),
-		beneficiary:  daos.NewBeneficiaryRepository(db),
-		disbursement: daos.NewDisbursementRepository(db),
-		transaction:  daos.NewTransactionRepository(db),
+		db:                db,
+		cipher:            cipher,
+		loan:              daos.NewLoanRepository(db),
+		beneficiary:       daos.NewBeneficiaryRepository(db, cipher),
+		disbursement:      daos.NewDisbursementRepository(db),
+		transaction:       daos.NewTransactionRepository(db),
+		installment:       daos.NewInstallmentRepository(db),
+		batch:             daos.NewBatchRepository(db, cipher),
+		audit:             daos.NewAuditRepository(db),
+		deadLetter:        daos.NewDeadLetterRepository(db),
+		beneficiaryChange: daos.NewBeneficiaryChangeRepository(db, cipher),
+		webhook:           daos.NewWebhookSubscriptionRepository(db),
+		webhookDelivery:   daos.NewWebhookDeliveryRepository(db),
 	}, nil
 }
 func (d *Database) GetDB() *gorm.DB {
 	return d.db
 }
+
+// RotateKeys rewraps the stored account numbers with the current key and
+// encrypts any still in plaintext, returning how many changed.
+func (d *Database) RotateKeys(ctx context.Context) (int, error) {
+	return daos.RotateKeys(ctx, d.db, d.cipher)
+}
+
 func (d *Database) GetLoanRepository() daos.LoanRepository {
 	return d.loan
 }
@@ -57,3 +108,31 @@ func (d *Database) GetDisbursementRepository() daos.DisbursementRepository {
 func (d *Database) GetTransactionRepository() daos.TransactionRepository {
 	return d.transaction
 }
+
+func (d *Database) GetInstallmentRepository() daos.InstallmentRepository {
+	return d.installment
+}
+
+func (d *Database) GetBatchRepository() daos.BatchRepository {
+	return d.batch
+}
+
+func (d *Database) GetAuditRepository() daos.AuditRepository {
+	return d.audit
+}
+
+func (d *Database) GetDeadLetterRepository() daos.DeadLetterRepository {
+	return d.deadLetter
+}
+
+func (d *Database) GetBeneficiaryChangeRepository() daos.BeneficiaryChangeRepository {
+	return d.beneficiaryChange
+}
+
+func (d *Database) GetWebhookSubscriptionRepository() daos.WebhookSubscriptionRepository {
+	return d.webhook
+}
+
+func (d *Database) GetWebhookDeliveryRepository() daos.WebhookDeliveryRepository {
+	return d.webhookDelivery
+}
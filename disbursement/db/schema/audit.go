@@ -0,0 +1,22 @@
+package schema
+
+import (
+	"loan-disbursement-service/models"
+	"time"
+)
+
+// AuditLog records a manual operation on an entity. Details holds the
+// action's parameters as JSON.
+type AuditLog struct {
+	Id           string `gorm:"primaryKey"`
+	EntityType   string `gorm:"index:idx_audit_entity"`
+	EntityId     string `gorm:"index:idx_audit_entity"`
+	Action       models.AdminAction
+	OperatorId   string `gorm:"index"`
+	OperatorRole models.OperatorRole
+	Reason       string
+	FromStatus   models.DisbursementStatus
+	ToStatus     models.DisbursementStatus
+	Details      *string
+	CreatedAt    time.Time
+}
@@ -0,0 +1,39 @@
+package schema
+
+import (
+	"loan-disbursement-service/models"
+	"time"
+)
+
+type Batch struct {
+	Id           string `gorm:"primaryKey"`
+	Source       string
+	ClientId     string
+	Status       models.BatchStatus `gorm:"index;default:accepted"`
+	TotalRows    int
+	AcceptedRows int
+	RejectedRows int
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	CompletedAt  *time.Time
+	TraceParent  string
+}
+
+// BatchRow keeps the instruction as uploaded so the results file can echo it
+// back next to the outcome, including rows that never became disbursements.
+type BatchRow struct {
+	BatchId         string `gorm:"primaryKey"`
+	RowNumber       int    `gorm:"primaryKey"`
+	LoanId          string
+	Amount          float64
+	AccountNumber   string
+	IFSCCode        string
+	BeneficiaryName string
+	BeneficiaryBank string
+	ScheduledAt     *time.Time
+	Status          models.BatchRowStatus `gorm:"index"`
+	Error           *string
+	DisbursementId  *string `gorm:"index"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
@@ -1,13 +1,30 @@
 package schema
 
-import "time"
+import (
+	"loan-disbursement-service/models"
+	"time"
+)
 
+// Beneficiary is stored with Account encrypted. AccountIndex is the blind
+// index of the account, which lookups and the unique index use in its place;
+// rows written before encryption get theirs when the database is opened.
 type Beneficiary struct {
-	Id        string `gorm:"primaryKey"`
-	Name      string `gorm:"uniqueIndex:idx_beneficiary_unique"`
-	Account   string `gorm:"uniqueIndex:idx_beneficiary_unique"`
-	IFSC      string `gorm:"uniqueIndex:idx_beneficiary_unique"`
-	Bank      string `gorm:"uniqueIndex:idx_beneficiary_unique"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	Id             string `gorm:"primaryKey"`
+	Name           string `gorm:"uniqueIndex:idx_beneficiary_account"`
+	Account        string
+	AccountIndex   *string                  `gorm:"uniqueIndex:idx_beneficiary_account"`
+	IFSC           string                   `gorm:"uniqueIndex:idx_beneficiary_account"`
+	Bank           string                   `gorm:"uniqueIndex:idx_beneficiary_account"`
+	Status         models.BeneficiaryStatus `gorm:"index;default:unverified"`
+	StatusReason   *string
+	VerifiedAt     *time.Time
+	OverrideReason *string
+	OverriddenBy   *string
+	OverriddenAt   *time.Time
+	RegisteredName *string
+	NameMatchScore *float64
+	PennyDropRef   *string
+	PennyDroppedAt *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
 }
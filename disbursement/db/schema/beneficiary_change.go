@@ -0,0 +1,21 @@
+package schema
+
+import "time"
+
+// BeneficiaryChange records a loan's beneficiary being replaced. The previous
+// payee details are copied so the history survives later edits to, or
+// deletion of, the old beneficiary.
+type BeneficiaryChange struct {
+	Id                    string `gorm:"primaryKey"`
+	LoanId                string `gorm:"index"`
+	DisbursementId        string
+	PreviousBeneficiaryId *string
+	PreviousName          string
+	PreviousAccount       string
+	PreviousIFSC          string
+	PreviousBank          string
+	BeneficiaryId         string
+	OperatorId            string
+	Reason                string
+	CreatedAt             time.Time
+}
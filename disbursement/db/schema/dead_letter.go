@@ -0,0 +1,26 @@
+package schema
+
+import (
+	"loan-disbursement-service/models"
+	"time"
+)
+
+// DeadLetter tracks a permanently failed disbursement until operations
+// requeue or close it. A disbursement that fails again after a requeue
+// reopens its entry.
+type DeadLetter struct {
+	DisbursementId string `gorm:"primaryKey"`
+	LoanId         string `gorm:"index"`
+	Amount         float64
+	Channel        models.PaymentChannel
+	Category       models.FailureCategory `gorm:"index"`
+	LastError      string
+	Status         models.DeadLetterStatus `gorm:"index"`
+	FailureCount   int
+	NotifiedAt     *time.Time
+	ResolvedBy     *string
+	ResolutionNote *string
+	ResolvedAt     *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
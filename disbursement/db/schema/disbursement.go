@@ -5,15 +5,28 @@ import (
 	"time"
 )
 
+// Disbursement is a payout of a loan. SchedulePending is set when it
+// succeeds and cleared once its repayment schedule is stored, which happens
+// after the settlement commits, from SettledAt.
 type Disbursement struct {
-	Id         string `gorm:"primaryKey"`
-	LoanId     string `gorm:"index"`
-	Loan       Loan   `gorm:"foreignKey:LoanId;references:Id"`
-	RetryCount int    `gorm:"default:0"`
-	Channel    models.PaymentChannel
-	Amount     float64
-	Status     models.DisbursementStatus
-	LastError  *string
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
+	Id                   string `gorm:"primaryKey"`
+	LoanId               string `gorm:"index"`
+	ClientId             string `gorm:"index"`
+	Loan                 Loan   `gorm:"foreignKey:LoanId;references:Id"`
+	RetryCount           int    `gorm:"default:0"`
+	Channel              models.PaymentChannel
+	ForcedChannel        *models.PaymentChannel
+	Amount               float64
+	Status               models.DisbursementStatus
+	LastError            *string
+	NameMatchDecision    models.NameMatchDecision
+	BorrowerNameScore    *float64
+	RegisteredNameScore  *float64
+	ScheduledAt          *time.Time `gorm:"index"`
+	ExpectedSettlementAt *time.Time
+	SettledAt            *time.Time
+	SchedulePending      bool `gorm:"index;default:false"`
+	TraceParent          string
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
 }
@@ -0,0 +1,19 @@
+package schema
+
+import "time"
+
+// Installment is one row of a repayment schedule. Each successful
+// disbursement gets its own schedule, so a loan disbursed in tranches has one
+// set of installments per tranche.
+type Installment struct {
+	DisbursementId string `gorm:"primaryKey"`
+	Number         int    `gorm:"primaryKey"`
+	LoanId         string `gorm:"index"`
+	DueDate        time.Time
+	OpeningBalance float64
+	Principal      float64
+	Interest       float64
+	Amount         float64
+	ClosingBalance float64
+	CreatedAt      time.Time
+}
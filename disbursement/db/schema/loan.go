@@ -1,12 +1,25 @@
 package schema
 
-import "time"
+import (
+	"loan-disbursement-service/models"
+	"time"
+)
 
 type Loan struct {
-	Id            string `gorm:"primaryKey"`
-	Amount        float64
-	BeneficiaryId *string      `gorm:"index"`
-	Beneficiary   *Beneficiary `gorm:"foreignKey:BeneficiaryId;references:Id"`
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
+	Id                 string `gorm:"primaryKey"`
+	Amount             float64
+	DisbursedAmount    float64
+	BorrowerId         string `gorm:"index"`
+	BorrowerName       string
+	ProductType        models.ProductType
+	SanctionDate       time.Time
+	InterestRate       float64
+	TenureMonths       int
+	RepaymentFrequency models.RepaymentFrequency
+	InterestMethod     models.InterestMethod
+	Status             models.LoanStatus `gorm:"index;default:sanctioned"`
+	BeneficiaryId      *string           `gorm:"index"`
+	Beneficiary        *Beneficiary      `gorm:"foreignKey:BeneficiaryId;references:Id"`
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
 }
@@ -14,6 +14,7 @@ type Transaction struct {
 	Channel        models.PaymentChannel
 	Status         models.TransactionStatus
 	Message        *string
+	UTR            *string `gorm:"index"`
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 }
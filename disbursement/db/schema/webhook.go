@@ -0,0 +1,39 @@
+package schema
+
+import (
+	"loan-disbursement-service/models"
+	"time"
+)
+
+// WebhookSubscription registers a client's URL for disbursement events.
+// Events holds the subscribed event names comma separated.
+type WebhookSubscription struct {
+	Id        string `gorm:"primaryKey"`
+	ClientId  string `gorm:"index"`
+	URL       string
+	Secret    string
+	Events    string
+	Active    bool `gorm:"index"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// WebhookDelivery is one event queued for one subscription, kept as the
+// delivery log once it is sent. Payload is the exact JSON body posted.
+type WebhookDelivery struct {
+	Id             string `gorm:"primaryKey"`
+	EventId        string `gorm:"index"`
+	SubscriptionId string `gorm:"index"`
+	DisbursementId string `gorm:"index"`
+	Event          models.WebhookEvent
+	Payload        string
+	Status         models.WebhookDeliveryStatus `gorm:"index"`
+	Attempts       int
+	NextAttemptAt  *time.Time `gorm:"index"`
+	LastStatusCode *int
+	LastError      *string
+	DeliveredAt    *time.Time
+	TraceParent    string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
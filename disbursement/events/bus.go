@@ -0,0 +1,211 @@
+// Package events is an in-process bus of disbursement status changes and
+// payment attempts, for streaming to dashboards. Events are numbered in
+// publish order and the most recent ones are kept so a subscriber that
+// reconnects can resume where it left off. Nothing is persisted: after a
+// restart numbering starts again and earlier events are gone.
+package events
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"loan-disbursement-service/models"
+)
+
+type Type string
+
+const (
+	// TypeStatus is a disbursement moving to a new status.
+	TypeStatus Type = "status"
+	// TypeTransaction is a new payment attempt sent to the gateway.
+	TypeTransaction Type = "transaction"
+)
+
+const (
+	DefaultHistorySize = 1000
+	// subscriberBuffer is how many events a subscriber may fall behind
+	// before it is dropped.
+	subscriberBuffer = 64
+)
+
+var ErrBusClosed = errors.New("event bus is closed")
+
+func (t Type) IsValid() bool {
+	return t == TypeStatus || t == TypeTransaction
+}
+
+type Event struct {
+	Id             uint64                    `json:"id"`
+	Type           Type                      `json:"type"`
+	DisbursementId string                    `json:"disbursement_id"`
+	LoanId         string                    `json:"loan_id"`
+	ClientId       string                    `json:"-"`
+	Status         models.DisbursementStatus `json:"status"`
+	Channel        models.PaymentChannel     `json:"channel,omitempty"`
+	Amount         float64                   `json:"amount"`
+	RetryCount     int                       `json:"retry_count"`
+	TransactionId  string                    `json:"transaction_id,omitempty"`
+	ReferenceId    string                    `json:"reference_id,omitempty"`
+	Error          string                    `json:"error,omitempty" pii:"text"`
+	OccurredAt     time.Time                 `json:"occurred_at"`
+}
+
+// Filter selects events for a subscriber. Empty fields match everything.
+// ClientId scopes a partner to the disbursements it created.
+type Filter struct {
+	DisbursementId string
+	LoanId         string
+	ClientId       string
+	Types          []Type
+	Statuses       []models.DisbursementStatus
+	Channels       []models.PaymentChannel
+}
+
+func (f Filter) Matches(event Event) bool {
+	if f.DisbursementId != "" && f.DisbursementId != event.DisbursementId {
+		return false
+	}
+	if f.LoanId != "" && f.LoanId != event.LoanId {
+		return false
+	}
+	if f.ClientId != "" && f.ClientId != event.ClientId {
+		return false
+	}
+	if len(f.Types) > 0 && !slices.Contains(f.Types, event.Type) {
+		return false
+	}
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, event.Status) {
+		return false
+	}
+	if len(f.Channels) > 0 && !slices.Contains(f.Channels, event.Channel) {
+		return false
+	}
+	return true
+}
+
+type Bus struct {
+	mu          sync.Mutex
+	lastId      uint64
+	history     []Event
+	historySize int
+	subscribers map[*Subscription]struct{}
+	closed      bool
+}
+
+func NewBus(historySize int) *Bus {
+	if historySize <= 0 {
+		historySize = DefaultHistorySize
+	}
+	return &Bus{
+		historySize: historySize,
+		subscribers: map[*Subscription]struct{}{},
+	}
+}
+
+// Publish numbers the event, records it and hands it to every matching
+// subscriber. It never blocks: a subscriber whose buffer is full is dropped
+// and has to resubscribe from the last event it saw.
+func (b *Bus) Publish(event Event) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return event
+	}
+
+	b.lastId++
+	event.Id = b.lastId
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	if len(b.history) == b.historySize {
+		b.history = slices.Delete(b.history, 0, 1)
+	}
+	b.history = append(b.history, event)
+
+	for subscription := range b.subscribers {
+		if !subscription.filter.Matches(event) {
+			continue
+		}
+		select {
+		case subscription.events <- event:
+		default:
+			b.drop(subscription)
+		}
+	}
+	return event
+}
+
+// Subscribe registers a subscriber and returns the recorded events after
+// lastId that match filter, so nothing published in between is missed.
+// Pass 0 for lastId to start from new events only. complete is false when
+// events after lastId have already been discarded, or lastId is from before
+// a restart, and the subscriber should reload the state it shows.
+func (b *Bus) Subscribe(
+	filter Filter,
+	lastId uint64,
+) (subscription *Subscription, replay []Event, complete bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, nil, false, ErrBusClosed
+	}
+
+	complete = true
+	if lastId > 0 {
+		complete = lastId <= b.lastId &&
+			(len(b.history) == 0 || lastId+1 >= b.history[0].Id)
+		for _, event := range b.history {
+			if event.Id > lastId && filter.Matches(event) {
+				replay = append(replay, event)
+			}
+		}
+	}
+
+	subscription = &Subscription{
+		bus:    b,
+		filter: filter,
+		events: make(chan Event, subscriberBuffer),
+	}
+	b.subscribers[subscription] = struct{}{}
+	return subscription, replay, complete, nil
+}
+
+// Close ends every subscription and stops accepting new ones, so open
+// streams finish when the server shuts down.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for subscription := range b.subscribers {
+		b.drop(subscription)
+	}
+}
+
+// drop must be called with mu held.
+func (b *Bus) drop(subscription *Subscription) {
+	if _, ok := b.subscribers[subscription]; !ok {
+		return
+	}
+	delete(b.subscribers, subscription)
+	close(subscription.events)
+}
+
+type Subscription struct {
+	bus    *Bus
+	filter Filter
+	events chan Event
+}
+
+// Events delivers matching events. It is closed when the subscriber falls
+// too far behind, the bus closes or the subscription is cancelled.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+func (s *Subscription) Cancel() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.drop(s)
+}
@@ -0,0 +1,159 @@
+package events
+
+import (
+	"testing"
+
+	"loan-disbursement-service/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func statusEvent(disbursementId string, status models.DisbursementStatus) Event {
+	return Event{
+		Type:           TypeStatus,
+		DisbursementId: disbursementId,
+		LoanId:         "LOAN-" + disbursementId,
+		Status:         status,
+		Channel:        models.PaymentChannelIMPS,
+	}
+}
+
+func TestBus_Publish(t *testing.T) {
+	t.Run("numbers events and delivers matching ones", func(t *testing.T) {
+		bus := NewBus(10)
+		subscription, replay, complete, err := bus.Subscribe(Filter{DisbursementId: "DIS-1"}, 0)
+		assert.NoError(t, err)
+		assert.Empty(t, replay)
+		assert.True(t, complete)
+
+		first := bus.Publish(statusEvent("DIS-1", models.DisbursementStatusProcessing))
+		bus.Publish(statusEvent("DIS-2", models.DisbursementStatusProcessing))
+		third := bus.Publish(statusEvent("DIS-1", models.DisbursementStatusSuccess))
+
+		assert.Equal(t, uint64(1), first.Id)
+		assert.False(t, first.OccurredAt.IsZero())
+		assert.Equal(t, first, <-subscription.Events())
+		assert.Equal(t, third, <-subscription.Events())
+		assert.Empty(t, subscription.Events())
+	})
+
+	t.Run("drops subscriber that falls behind", func(t *testing.T) {
+		bus := NewBus(10)
+		subscription, _, _, _ := bus.Subscribe(Filter{}, 0)
+
+		for range subscriberBuffer + 1 {
+			bus.Publish(statusEvent("DIS-1", models.DisbursementStatusProcessing))
+		}
+
+		received := 0
+		for range subscription.Events() {
+			received++
+		}
+		assert.Equal(t, subscriberBuffer, received)
+	})
+}
+
+func TestBus_Subscribe(t *testing.T) {
+	t.Run("replays recorded events after last id", func(t *testing.T) {
+		bus := NewBus(10)
+		bus.Publish(statusEvent("DIS-1", models.DisbursementStatusProcessing))
+		bus.Publish(statusEvent("DIS-2", models.DisbursementStatusProcessing))
+		bus.Publish(statusEvent("DIS-1", models.DisbursementStatusFailed))
+
+		_, replay, complete, err := bus.Subscribe(Filter{DisbursementId: "DIS-1"}, 1)
+
+		assert.NoError(t, err)
+		assert.True(t, complete)
+		assert.Len(t, replay, 1)
+		assert.Equal(t, uint64(3), replay[0].Id)
+	})
+
+	t.Run("reports incomplete replay when events were discarded", func(t *testing.T) {
+		bus := NewBus(2)
+		for range 4 {
+			bus.Publish(statusEvent("DIS-1", models.DisbursementStatusProcessing))
+		}
+
+		_, replay, complete, _ := bus.Subscribe(Filter{}, 1)
+
+		assert.False(t, complete)
+		assert.Len(t, replay, 2)
+		assert.Equal(t, uint64(3), replay[0].Id)
+	})
+
+	t.Run("reports complete replay at the edge of history", func(t *testing.T) {
+		bus := NewBus(2)
+		for range 4 {
+			bus.Publish(statusEvent("DIS-1", models.DisbursementStatusProcessing))
+		}
+
+		_, replay, complete, _ := bus.Subscribe(Filter{}, 2)
+
+		assert.True(t, complete)
+		assert.Len(t, replay, 2)
+	})
+
+	t.Run("reports incomplete replay for id from before a restart", func(t *testing.T) {
+		bus := NewBus(10)
+		bus.Publish(statusEvent("DIS-1", models.DisbursementStatusProcessing))
+
+		_, replay, complete, _ := bus.Subscribe(Filter{}, 50)
+
+		assert.False(t, complete)
+		assert.Empty(t, replay)
+	})
+
+	t.Run("refuses subscribers once closed", func(t *testing.T) {
+		bus := NewBus(10)
+		subscription, _, _, _ := bus.Subscribe(Filter{}, 0)
+
+		bus.Close()
+
+		_, open := <-subscription.Events()
+		assert.False(t, open)
+		_, _, _, err := bus.Subscribe(Filter{}, 0)
+		assert.ErrorIs(t, err, ErrBusClosed)
+	})
+
+	t.Run("cancel is safe after the subscriber was dropped", func(t *testing.T) {
+		bus := NewBus(10)
+		subscription, _, _, _ := bus.Subscribe(Filter{}, 0)
+		bus.Close()
+
+		assert.NotPanics(t, subscription.Cancel)
+	})
+}
+
+func TestFilter_Matches(t *testing.T) {
+	event := Event{
+		Type:           TypeTransaction,
+		DisbursementId: "DIS-1",
+		LoanId:         "LOAN-1",
+		ClientId:       "partner-a",
+		Status:         models.DisbursementStatusProcessing,
+		Channel:        models.PaymentChannelUPI,
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"empty filter", Filter{}, true},
+		{"loan", Filter{LoanId: "LOAN-1"}, true},
+		{"other loan", Filter{LoanId: "LOAN-2"}, false},
+		{"client", Filter{ClientId: "partner-a"}, true},
+		{"other client", Filter{ClientId: "partner-b"}, false},
+		{"type", Filter{Types: []Type{TypeStatus, TypeTransaction}}, true},
+		{"other type", Filter{Types: []Type{TypeStatus}}, false},
+		{"status", Filter{Statuses: []models.DisbursementStatus{models.DisbursementStatusProcessing}}, true},
+		{"other status", Filter{Statuses: []models.DisbursementStatus{models.DisbursementStatusFailed}}, false},
+		{"channel", Filter{Channels: []models.PaymentChannel{models.PaymentChannelUPI}}, true},
+		{"other channel", Filter{Channels: []models.PaymentChannel{models.PaymentChannelNEFT}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Matches(event))
+		})
+	}
+}
@@ -6,7 +6,12 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"shared/tracing"
 	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type NetHTTPClient struct {
@@ -87,14 +92,36 @@ func (c *NetHTTPClient) do(
 		}
 	}
 
+	ctx, span := tracing.Tracer().Start(ctx, "HTTP "+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(method),
+			semconv.URLFull(url),
+		),
+	)
+	defer span.End()
+
 	req, err := http.NewRequestWithContext(ctx, method, url, reader)
 	if err != nil {
-		return nil, err
+		return nil, tracing.Record(span, err)
 	}
 
 	for k, v := range headers {
 		req.Header.Set(k, v)
 	}
+	// The callee continues this trace and logs under the same request id.
+	tracing.Inject(ctx, req.Header)
+	if requestId, ok := tracing.RequestId(ctx); ok && req.Header.Get("X-Request-ID") == "" {
+		req.Header.Set("X-Request-ID", requestId)
+	}
 
-	return c.client.Do(req)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, tracing.Record(span, err)
+	}
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	return resp, nil
 }
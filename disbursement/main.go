@@ -6,62 +6,181 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"loan-disbursement-service/api"
 	"loan-disbursement-service/api/services"
+	"loan-disbursement-service/config"
 	"loan-disbursement-service/db"
+	"loan-disbursement-service/events"
 	httpclient "loan-disbursement-service/http"
+	"loan-disbursement-service/metrics"
 	"loan-disbursement-service/providers"
+	"loan-disbursement-service/ratelimit"
 	"loan-disbursement-service/utils"
 	"loan-disbursement-service/worker"
+	"shared/authn"
+	"shared/calendar"
+	"shared/encryption"
+	"shared/ifsc"
+	"shared/pii"
+	"shared/tracing"
 
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
 func main() {
+	log.Logger = log.Output(pii.Writer(os.Stderr))
+	zerolog.InterfaceMarshalFunc = pii.Marshal
+
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		ServiceName: "loan-disbursement-service",
+		Exporter:    os.Getenv("OTEL_TRACES_EXPORTER"),
+		File:        os.Getenv("OTEL_TRACES_FILE"),
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to set up tracing")
+	}
+
 	dsn := os.Getenv("DATABASE_URL")
 	if dsn == "" {
 		log.Fatal().Msg("DATABASE_URL env var is required")
 	}
 
-	database, err := db.New(dsn)
+	keyringFile := os.Getenv("ENCRYPTION_KEYRING_FILE")
+	if keyringFile == "" {
+		log.Fatal().Msg("ENCRYPTION_KEYRING_FILE env var is required")
+	}
+	keyring, err := encryption.LoadKeyring(keyringFile)
 	if err != nil {
-		log.Fatal().Err(err).Msg("failed to connect database")
+		log.Fatal().Err(err).Msg("failed to load encryption keyring")
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	cipher, err := encryption.New(ctx, keyring)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to set up encryption")
+	}
+
+	database, err := db.New(dsn, cipher)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to connect database")
+	}
+
+	// rotate-keys rewraps stored account numbers with the current key and
+	// exits, rather than starting the service.
+	if len(os.Args) > 1 && os.Args[1] == "rotate-keys" {
+		rotated, err := database.RotateKeys(ctx)
+		if err != nil {
+			log.Fatal().Err(err).Int("rotated", rotated).Msg("key rotation failed")
+		}
+		log.Info().Int("rotated", rotated).Msg("key rotation complete")
+		return
+	}
+
+	configFile := os.Getenv("CONFIG_FILE")
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load config")
+	}
+	settings := config.NewStore(configFile, cfg)
+
+	authenticators := loadAuthenticators()
+	limiter, err := ratelimit.New(cfg.RateLimits)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to set up rate limits")
+	}
+
 	idGenerator := utils.NewIdGenerator()
 
 	paymentProvider, err := providers.NewPaymentProvider(
 		os.Getenv("PAYMENT_PROVIDER_URL"),
-		httpclient.NewHTTPClient(),
+		httpclient.WithHeaders(httpclient.NewHTTPClient(cfg.HTTP.Timeout), map[string]string{
+			authn.APIKeyHeader: os.Getenv("PAYMENT_PROVIDER_API_KEY"),
+		}),
 	)
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to create payment provider")
 	}
 	notificationURL := os.Getenv("NOTIFICATION_URL")
-	paymentChan := make(chan string, 100) // Buffered to prevent blocking
+	// Dead letters are only pushed to loan origination when it has a webhook
+	// configured; they are still listed in the admin API either way.
+	var originationProvider providers.OriginationProvider
+	if url := os.Getenv("ORIGINATION_NOTIFICATION_URL"); url != "" {
+		originationProvider = providers.NewOriginationProvider(url, httpclient.NewHTTPClient(cfg.HTTP.Timeout))
+	}
+	bankCalendar, err := calendar.Load(os.Getenv("CALENDAR_FILE"))
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load bank calendar")
+	}
+	if now := time.Now(); os.Getenv("CALENDAR_FILE") != "" && !bankCalendar.Covers(now) {
+		log.Warn().Int("year", now.In(bankCalendar.Location()).Year()).
+			Msg("bank calendar lists no holidays for this year, they are treated as working days")
+	}
+	directory, err := ifsc.Load(os.Getenv("IFSC_DIRECTORY_FILE"))
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load IFSC directory")
+	}
+	if directory == nil {
+		log.Warn().Msg("IFSC_DIRECTORY_FILE is not set, beneficiary branches are not checked")
+	} else {
+		log.Info().Int("branches", directory.Len()).Msg("loaded IFSC directory")
+	}
+	paymentChan := make(chan string, cfg.Queues.Payment) // Buffered to prevent blocking
+	batchChan := make(chan string, cfg.Queues.Batch)
+	if err := metrics.RegisterQueue("payment", paymentChan); err != nil {
+		log.Fatal().Err(err).Msg("failed to register payment queue metric")
+	}
+	if err := metrics.RegisterQueue("batch", batchChan); err != nil {
+		log.Fatal().Err(err).Msg("failed to register batch queue metric")
+	}
+	if err := metrics.RegisterStatusCounts(database.GetDisbursementRepository()); err != nil {
+		log.Fatal().Err(err).Msg("failed to register disbursement status metric")
+	}
 
 	serviceFactory := services.New(
 		database,
 		idGenerator,
 		paymentProvider,
+		originationProvider,
+		providers.NewWebhookProvider(httpclient.NewPublicHTTPClient(cfg.HTTP.Timeout)),
 		notificationURL,
 		paymentChan,
+		batchChan,
+		bankCalendar,
+		directory,
+		events.NewBus(events.DefaultHistorySize),
+		settings,
 	)
 
 	worker := worker.NewWorker(
 		database.GetDisbursementRepository(),
 		serviceFactory.GetPaymentService(),
+		serviceFactory.GetBeneficiaryService(),
+		serviceFactory.GetBatchService(),
+		serviceFactory.GetSchedulerService(),
+		serviceFactory.GetDeadLetterService(),
+		serviceFactory.GetWebhookService(),
+		bankCalendar,
+		settings,
 		paymentChan,
+		batchChan,
 	)
 	go worker.StartPaymentDisbursement(ctx)
 	go worker.StartRetryDisbursement(ctx)
 	go worker.StartNEFTDisbursement(ctx)
+	go worker.StartBatchDisbursement(ctx)
+	go worker.StartScheduledDisbursement(ctx)
+	go worker.StartWebhookDelivery(ctx)
+	go worker.StartPennyDropWorker(ctx)
+	if originationProvider != nil {
+		go worker.StartDeadLetterNotifier(ctx)
+	}
 
-	server := api.New("7070", serviceFactory)
+	server := api.New(cfg.Server.Port, serviceFactory, limiter, authenticators...)
 
 	go func() {
 		if err := server.Serve(); err != nil && err != http.ErrServerClosed {
@@ -69,6 +188,14 @@ func main() {
 		}
 	}()
 
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			reloadConfig(settings, limiter)
+		}
+	}()
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
@@ -76,6 +203,56 @@ func main() {
 	log.Info().Msg("Received interrupt signal, shutting down...")
 	worker.Stop(ctx)
 	server.Close(ctx)
+	if err := shutdownTracing(ctx); err != nil {
+		log.Error().Err(err).Msg("failed to flush traces")
+	}
 
 	log.Info().Msg("Disbursement service stopped")
 }
+
+// loadAuthenticators reads the API keys and the JWKS callers authenticate
+// with. At least one of them must be configured.
+func loadAuthenticators() []authn.Authenticator {
+	var authenticators []authn.Authenticator
+	if path := os.Getenv("AUTH_JWKS_FILE"); path != "" {
+		verifier, err := authn.LoadJWKS(path, authn.JWTConfig{
+			Issuer:   os.Getenv("AUTH_JWT_ISSUER"),
+			Audience: os.Getenv("AUTH_JWT_AUDIENCE"),
+		})
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to load jwks")
+		}
+		authenticators = append(authenticators, verifier)
+	}
+	if path := os.Getenv("AUTH_API_KEYS_FILE"); path != "" {
+		keys, err := authn.LoadAPIKeys(path)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to load api keys")
+		}
+		authenticators = append(authenticators, keys)
+	}
+	if len(authenticators) == 0 {
+		log.Fatal().Msg("AUTH_API_KEYS_FILE or AUTH_JWKS_FILE env var is required")
+	}
+	return authenticators
+}
+
+// reloadConfig reads the config file again on SIGHUP. Worker intervals apply
+// at once, batch sizes, the retry backoff and the channel limits from the
+// next poll or payment, name match thresholds from the next disbursement, and
+// rate limits from the next request; the port, HTTP timeout and queue sizes
+// only after a restart.
+func reloadConfig(settings *config.Store, limiter *ratelimit.Limiter) {
+	restart, err := settings.Reload()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to reload config, keeping the running one")
+		return
+	}
+	if len(restart) > 0 {
+		log.Warn().Strs("settings", restart).Msg("config changes need a restart to take effect")
+	}
+	if err := limiter.Update(settings.Get().RateLimits, time.Now()); err != nil {
+		log.Error().Err(err).Msg("failed to apply reloaded rate limits, keeping the running ones")
+	}
+	log.Info().Msg("config reloaded")
+}
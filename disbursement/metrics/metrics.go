@@ -0,0 +1,209 @@
+// Package metrics holds the Prometheus collectors for the disbursement
+// pipeline and serves them for scraping. Collectors are registered on the
+// package's own Registry rather than the global default so only what is
+// declared here, plus the Go runtime and process collectors, is exposed.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"loan-disbursement-service/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	namespace = "disbursement"
+
+	outcomeOK    = "ok"
+	outcomeError = "error"
+
+	// countTimeout bounds the per status query run on each scrape.
+	countTimeout = 5 * time.Second
+)
+
+// statuses are reported by the per status gauge even when no disbursement
+// is in them, so a status emptying out reads as zero rather than vanishing.
+var statuses = []models.DisbursementStatus{
+	models.DisbursementStatusScheduled,
+	models.DisbursementStatusInitiated,
+	models.DisbursementStatusProcessing,
+	models.DisbursementStatusSuspended,
+	models.DisbursementStatusSuccess,
+	models.DisbursementStatusFailed,
+	models.DisbursementStatusCancelled,
+}
+
+var Registry = prometheus.NewRegistry()
+
+var (
+	statusTransitions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "status_transitions_total",
+		Help:      "Disbursements moved to a status by the payment pipeline.",
+	}, []string{"status", "channel"})
+
+	failures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "failures_total",
+		Help:      "Failed payment attempts by resulting status, channel and failure reason.",
+	}, []string{"status", "channel", "reason"})
+
+	transferDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "gateway_transfer_duration_seconds",
+		Help:      "Latency of transfer calls to the payment gateway.",
+		Buckets:   prometheus.ExponentialBuckets(0.05, 2, 10),
+	}, []string{"channel", "outcome"})
+
+	timeToSuccess = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "time_to_success_seconds",
+		Help:      "Time from a disbursement being created to its payment succeeding.",
+		// 1s to about 9h, wide enough for NEFT batches that settle later
+		// in the day.
+		Buckets: prometheus.ExponentialBuckets(1, 2, 16),
+	}, []string{"channel"})
+
+	rejectedRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rejected_requests_total",
+		Help:      "API requests turned away by the rate limiter or a full payment queue.",
+	}, []string{"route", "reason"})
+
+	retryAttempts = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "retry_attempts",
+		Help:      "Retries a disbursement took before it succeeded or finally failed.",
+		// One bucket per attempt up to the retry limit.
+		Buckets: prometheus.LinearBuckets(0, 1, 6),
+	}, []string{"status"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		statusTransitions,
+		failures,
+		transferDuration,
+		timeToSuccess,
+		retryAttempts,
+		rejectedRequests,
+	)
+}
+
+// Handler serves everything in Registry. A failing collector, such as the
+// per status count when the database is down, is reported in the response
+// without hiding the other metrics.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+}
+
+func RecordStatus(status models.DisbursementStatus, channel models.PaymentChannel) {
+	statusTransitions.WithLabelValues(string(status), string(channel)).Inc()
+}
+
+// RecordFailure counts a failed payment attempt. status is where the failure
+// left the disbursement: suspended for a retry or failed for good.
+func RecordFailure(
+	status models.DisbursementStatus,
+	channel models.PaymentChannel,
+	reason models.FailureCategory,
+	retryCount int,
+) {
+	failures.WithLabelValues(string(status), string(channel), string(reason)).Inc()
+	if status == models.DisbursementStatusFailed {
+		retryAttempts.WithLabelValues(string(status)).Observe(float64(retryCount))
+	}
+}
+
+// RecordSuccess observes how long a disbursement took to pay out, measured
+// from createdAt, and how many retries it needed.
+func RecordSuccess(channel models.PaymentChannel, createdAt time.Time, retryCount int) {
+	if !createdAt.IsZero() {
+		timeToSuccess.WithLabelValues(string(channel)).Observe(time.Since(createdAt).Seconds())
+	}
+	retryAttempts.WithLabelValues(string(models.DisbursementStatusSuccess)).
+		Observe(float64(retryCount))
+}
+
+func ObserveTransfer(channel models.PaymentChannel, elapsed time.Duration, err error) {
+	outcome := outcomeOK
+	if err != nil {
+		outcome = outcomeError
+	}
+	transferDuration.WithLabelValues(string(channel), outcome).Observe(elapsed.Seconds())
+}
+
+// RecordRejected counts a request to route refused for reason, such as
+// rate_limited or queue_full.
+func RecordRejected(route, reason string) {
+	rejectedRequests.WithLabelValues(route, reason).Inc()
+}
+
+// RegisterQueue exposes the number of ids waiting in queue, read on every
+// scrape.
+func RegisterQueue(name string, queue chan string) error {
+	return Registry.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "queue_depth",
+		Help:        "Ids waiting in a worker queue.",
+		ConstLabels: prometheus.Labels{"queue": name},
+	}, func() float64 {
+		return float64(len(queue))
+	}))
+}
+
+type StatusCounter interface {
+	CountByStatus(ctx context.Context) (map[models.DisbursementStatus]int64, error)
+}
+
+// RegisterStatusCounts exposes how many disbursements are in each status,
+// counted by counter on every scrape.
+func RegisterStatusCounts(counter StatusCounter) error {
+	return Registry.Register(newStatusCollector(counter))
+}
+
+type statusCollector struct {
+	counter StatusCounter
+	desc    *prometheus.Desc
+}
+
+func newStatusCollector(counter StatusCounter) *statusCollector {
+	return &statusCollector{
+		counter: counter,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "disbursements"),
+			"Disbursements currently in each status.",
+			[]string{"status"},
+			nil,
+		),
+	}
+}
+
+func (c *statusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *statusCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), countTimeout)
+	defer cancel()
+
+	counts, err := c.counter.CountByStatus(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+	for _, status := range statuses {
+		ch <- prometheus.MustNewConstMetric(
+			c.desc, prometheus.GaugeValue, float64(counts[status]), string(status),
+		)
+	}
+}
@@ -0,0 +1,142 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"loan-disbursement-service/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubCounter struct {
+	counts map[models.DisbursementStatus]int64
+	err    error
+}
+
+func (s stubCounter) CountByStatus(
+	ctx context.Context,
+) (map[models.DisbursementStatus]int64, error) {
+	return s.counts, s.err
+}
+
+func TestRecordFailure(t *testing.T) {
+	t.Run("counts by status channel and reason", func(t *testing.T) {
+		counter := failures.WithLabelValues("suspended", "UPI", "network")
+		before := testutil.ToFloat64(counter)
+
+		RecordFailure(
+			models.DisbursementStatusSuspended,
+			models.PaymentChannelUPI,
+			models.FailureCategoryNetwork,
+			1,
+		)
+
+		assert.Equal(t, before+1, testutil.ToFloat64(counter))
+	})
+
+	t.Run("observes retries only once failed for good", func(t *testing.T) {
+		before := testutil.CollectAndCount(retryAttempts)
+		RecordFailure(
+			models.DisbursementStatusSuspended,
+			models.PaymentChannelIMPS,
+			models.FailureCategoryBankUnavailable,
+			2,
+		)
+		assert.Equal(t, before, testutil.CollectAndCount(retryAttempts))
+
+		RecordFailure(
+			models.DisbursementStatusFailed,
+			models.PaymentChannelIMPS,
+			models.FailureCategoryInvalidIFSC,
+			3,
+		)
+		assert.Contains(t, scrape(t), `disbursement_retry_attempts_count{status="failed"}`)
+	})
+}
+
+func TestRecordSuccess(t *testing.T) {
+	RecordSuccess(models.PaymentChannelNEFT, time.Now().Add(-time.Minute), 2)
+
+	body := scrape(t)
+	assert.Contains(t, body, `disbursement_time_to_success_seconds_count{channel="NEFT"} 1`)
+	assert.Contains(t, body, `disbursement_retry_attempts_bucket{status="success",le="1"} 0`)
+	assert.Contains(t, body, `disbursement_retry_attempts_bucket{status="success",le="2"} 1`)
+}
+
+func TestObserveTransfer(t *testing.T) {
+	ObserveTransfer(models.PaymentChannelIMPS, 120*time.Millisecond, nil)
+	ObserveTransfer(models.PaymentChannelIMPS, 2*time.Second, errors.New("network error"))
+
+	body := scrape(t)
+	assert.Contains(t, body,
+		`disbursement_gateway_transfer_duration_seconds_count{channel="IMPS",outcome="ok"} 1`)
+	assert.Contains(t, body,
+		`disbursement_gateway_transfer_duration_seconds_count{channel="IMPS",outcome="error"} 1`)
+}
+
+func TestRecordRejected(t *testing.T) {
+	RecordRejected("POST /api/v1/disburse", "rate_limited")
+
+	assert.Contains(t, scrape(t),
+		`disbursement_rejected_requests_total{reason="rate_limited",route="POST /api/v1/disburse"} 1`)
+}
+
+func TestRegisterQueue(t *testing.T) {
+	queue := make(chan string, 5)
+	queue <- "DIS-1"
+	queue <- "DIS-2"
+
+	assert.NoError(t, RegisterQueue("test", queue))
+	assert.Contains(t, scrape(t), `disbursement_queue_depth{queue="test"} 2`)
+
+	assert.Error(t, RegisterQueue("test", queue))
+}
+
+func TestStatusCollector(t *testing.T) {
+	t.Run("reports every status", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(newStatusCollector(stubCounter{
+			counts: map[models.DisbursementStatus]int64{
+				models.DisbursementStatusSuccess:   7,
+				models.DisbursementStatusSuspended: 2,
+			},
+		}))
+
+		expected := `
+# HELP disbursement_disbursements Disbursements currently in each status.
+# TYPE disbursement_disbursements gauge
+disbursement_disbursements{status="cancelled"} 0
+disbursement_disbursements{status="failed"} 0
+disbursement_disbursements{status="initiated"} 0
+disbursement_disbursements{status="processing"} 0
+disbursement_disbursements{status="scheduled"} 0
+disbursement_disbursements{status="success"} 7
+disbursement_disbursements{status="suspended"} 2
+`
+		assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected)))
+	})
+
+	t.Run("reports a count failure", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(newStatusCollector(stubCounter{err: errors.New("db down")}))
+
+		_, err := registry.Gather()
+		assert.ErrorContains(t, err, "db down")
+	})
+}
+
+func scrape(t *testing.T) string {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	return recorder.Body.String()
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+
+	"shared/apperrors"
+)
+
+type OperatorRole string
+type AdminAction string
+
+const (
+	// OperatorRoleAdmin may force-resolve disbursements and reassign channels.
+	OperatorRoleAdmin OperatorRole = "ops_admin"
+	// OperatorRoleSupport may only requeue and reset retries.
+	OperatorRoleSupport OperatorRole = "ops_support"
+)
+
+const (
+	AdminActionMarkSuccess  AdminAction = "mark_success"
+	AdminActionMarkFailed   AdminAction = "mark_failed"
+	AdminActionRequeue      AdminAction = "requeue"
+	AdminActionForceChannel AdminAction = "force_channel"
+	AdminActionResetRetries AdminAction = "reset_retries"
+	// AdminActionCloseDeadLetter closes a dead letter with no further action.
+	AdminActionCloseDeadLetter AdminAction = "close_dead_letter"
+	// AdminActionCorrectBeneficiary relinks a failed disbursement's loan to
+	// corrected payee details and requeues the disbursement.
+	AdminActionCorrectBeneficiary AdminAction = "correct_beneficiary"
+)
+
+const AuditEntityDisbursement = "disbursement"
+
+var (
+	REASON_REQUIRED       = apperrors.New(apperrors.CodeInvalidRequest, "reason is required")
+	INVALID_UTR           = apperrors.New(apperrors.CodeInvalidRequest, "UTR must be 12 to 22 letters or digits")
+	ACTION_NOT_ALLOWED    = apperrors.New(apperrors.CodeConflict, "action not allowed in the disbursement's current status")
+	OPERATOR_REQUIRED     = apperrors.New(apperrors.CodeUnauthenticated, "operator id and role are required")
+	OPERATOR_NOT_ALLOWED  = apperrors.New(apperrors.CodePermissionDenied, "operator role is not allowed to perform this action")
+	INVALID_SETTLED_AT    = apperrors.New(apperrors.CodeInvalidRequest, "settled_at cannot be in the future")
+	CHANNEL_OVER_LIMIT    = apperrors.New(apperrors.CodeInvalidRequest, "amount is over the channel's limit")
+	CHANNEL_NOT_AT_BRANCH = apperrors.New(apperrors.CodeInvalidRequest, "beneficiary branch does not take payments over the channel")
+)
+
+type Operator struct {
+	Id   string
+	Role OperatorRole
+}
+
+type MarkSuccessRequest struct {
+	UTR    string `json:"utr"`
+	Reason string `json:"reason"`
+	// SettledAt is when the bank credited the beneficiary; it defaults to
+	// now.
+	SettledAt *time.Time `json:"settled_at"`
+}
+
+type ForceChannelRequest struct {
+	Channel PaymentChannel `json:"channel"`
+	Reason  string         `json:"reason"`
+}
+
+// AdminActionRequest is the body of actions that take nothing but a reason.
+type AdminActionRequest struct {
+	Reason string `json:"reason"`
+}
+
+type AuditEntry struct {
+	AuditId      string             `json:"audit_id"`
+	Action       AdminAction        `json:"action"`
+	OperatorId   string             `json:"operator_id"`
+	OperatorRole OperatorRole       `json:"operator_role"`
+	Reason       string             `json:"reason"`
+	FromStatus   DisbursementStatus `json:"from_status"`
+	ToStatus     DisbursementStatus `json:"to_status"`
+	Details      map[string]any     `json:"details,omitempty"`
+	CreatedAt    time.Time          `json:"created_at"`
+}
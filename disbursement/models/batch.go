@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"shared/apperrors"
+)
+
+type BatchStatus string
+type BatchRowStatus string
+
+const (
+	BatchStatusAccepted   BatchStatus = "accepted"
+	BatchStatusProcessing BatchStatus = "processing"
+	BatchStatusCompleted  BatchStatus = "completed"
+)
+
+const (
+	// BatchRowStatusRejected rows failed validation on upload and are never
+	// submitted.
+	BatchRowStatusRejected BatchRowStatus = "rejected"
+	// BatchRowStatusPending rows passed validation and wait for the batch
+	// worker.
+	BatchRowStatusPending BatchRowStatus = "pending"
+	// BatchRowStatusSubmitted rows have a disbursement; its own status tracks
+	// the payment from there.
+	BatchRowStatusSubmitted BatchRowStatus = "submitted"
+	// BatchRowStatusFailed rows were refused when the disbursement was
+	// created, for example because the beneficiary is not verified.
+	BatchRowStatusFailed BatchRowStatus = "failed"
+)
+
+var (
+	EMPTY_BATCH        = apperrors.New(apperrors.CodeInvalidRequest, "batch has no rows")
+	BATCH_TOO_LARGE    = apperrors.New(apperrors.CodeInvalidRequest, "batch has too many rows")
+	INVALID_BATCH_FILE = apperrors.New(apperrors.CodeInvalidRequest, "invalid batch file")
+	BATCH_NOT_FOUND    = apperrors.New(apperrors.CodeNotFound, "batch not found")
+)
+
+type BatchRow struct {
+	RowNumber          int                `json:"row_number"`
+	LoanId             string             `json:"loan_id"`
+	Amount             float64            `json:"amount"`
+	Status             BatchRowStatus     `json:"status"`
+	Error              *string            `json:"error" pii:"text"`
+	DisbursementId     *string            `json:"disbursement_id"`
+	DisbursementStatus DisbursementStatus `json:"disbursement_status,omitempty"`
+}
+
+type BatchResponse struct {
+	BatchId            string                     `json:"batch_id"`
+	Status             BatchStatus                `json:"status"`
+	Source             string                     `json:"source"`
+	TotalRows          int                        `json:"total_rows"`
+	AcceptedRows       int                        `json:"accepted_rows"`
+	RejectedRows       int                        `json:"rejected_rows"`
+	RowStatus          map[BatchRowStatus]int     `json:"row_status,omitempty"`
+	DisbursementStatus map[DisbursementStatus]int `json:"disbursement_status,omitempty"`
+	TotalAmount        float64                    `json:"total_amount"`
+	Rows               []BatchRow                 `json:"rows,omitempty"`
+	CreatedAt          time.Time                  `json:"created_at"`
+	UpdatedAt          time.Time                  `json:"updated_at"`
+}
@@ -1,8 +1,100 @@
 package models
 
+import (
+	"time"
+
+	"shared/apperrors"
+)
+
+type BeneficiaryStatus string
+
+const (
+	BeneficiaryStatusUnverified BeneficiaryStatus = "unverified"
+	BeneficiaryStatusVerified   BeneficiaryStatus = "verified"
+	BeneficiaryStatusRejected   BeneficiaryStatus = "rejected"
+)
+
+var (
+	BENEFICIARY_NOT_VERIFIED     = apperrors.New(apperrors.CodeUnprocessable, "beneficiary is not verified")
+	BENEFICIARY_REJECTED         = apperrors.New(apperrors.CodeUnprocessable, "beneficiary is rejected")
+	BENEFICIARY_IN_USE           = apperrors.New(apperrors.CodeConflict, "beneficiary is linked to a loan")
+	BENEFICIARY_LOCKED           = apperrors.New(apperrors.CodeConflict, "beneficiary is being paid; use the admin beneficiary correction")
+	INVALID_BENEFICIARY_STATUS   = apperrors.New(apperrors.CodeInvalidRequest, "invalid beneficiary status")
+	BENEFICIARY_DETAILS_REQUIRED = apperrors.New(apperrors.CodeInvalidRequest, "name, account_number, ifsc_code and bank are required")
+	BENEFICIARY_UNCHANGED        = apperrors.New(apperrors.CodeUnprocessable, "corrected details match the current beneficiary")
+	BENEFICIARY_UNVERIFIABLE     = apperrors.New(apperrors.CodeUnavailable, "beneficiary account could not be verified, try again")
+	REJECTION_REASON_REQUIRED    = apperrors.New(apperrors.CodeInvalidRequest, "rejection reason is required")
+	OVERRIDE_APPROVAL_REQUIRED   = apperrors.New(apperrors.CodeInvalidRequest, "override reason is required")
+	CORRECTION_OVERRIDE_REQUIRED = apperrors.New(apperrors.CodeUnprocessable, "corrected beneficiary is not verified, set override and override_reason to pay it")
+	UNKNOWN_IFSC                 = apperrors.New(apperrors.CodeUnprocessable, "IFSC code is not in the bank directory")
+	IFSC_NOT_FOUND               = apperrors.New(apperrors.CodeNotFound, "IFSC code not found")
+)
+
 type Beneficiary struct {
-	Name    string `json:"name"`
-	Account string `json:"account"`
+	Name    string `json:"name" pii:"name"`
+	Account string `json:"account" pii:"account"`
 	IFSC    string `json:"ifsc"`
 	Bank    string `json:"bank"`
 }
+
+type BeneficiaryRequest struct {
+	Name          string `json:"name" pii:"name" validate:"notblank,max=200"`
+	AccountNumber string `json:"account_number" pii:"account" validate:"required,account_number"`
+	IFSCCode      string `json:"ifsc_code" validate:"required,ifsc"`
+	Bank          string `json:"bank" validate:"max=100"`
+}
+
+type BeneficiaryVerificationRequest struct {
+	Status BeneficiaryStatus `json:"status"`
+	Reason string            `json:"reason"`
+}
+
+type BeneficiaryOverrideRequest struct {
+	Reason string `json:"reason"`
+}
+
+type BeneficiaryResponse struct {
+	Id             string            `json:"id"`
+	Name           string            `json:"name" pii:"name"`
+	AccountNumber  string            `json:"account_number" pii:"account"`
+	IFSCCode       string            `json:"ifsc_code"`
+	Bank           string            `json:"bank"`
+	Status         BeneficiaryStatus `json:"status"`
+	StatusReason   *string           `json:"status_reason" pii:"text"`
+	VerifiedAt     *time.Time        `json:"verified_at"`
+	OverrideReason *string           `json:"override_reason"`
+	OverriddenBy   *string           `json:"overridden_by"`
+	OverriddenAt   *time.Time        `json:"overridden_at"`
+	RegisteredName *string           `json:"registered_name" pii:"name"`
+	NameMatchScore *float64          `json:"name_match_score"`
+	PennyDroppedAt *time.Time        `json:"penny_dropped_at"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// BeneficiaryCorrectionRequest replaces the payee of a failed disbursement's
+// loan. Override with OverrideReason pays a corrected payee that is not yet
+// verified; without it such a correction is refused.
+type BeneficiaryCorrectionRequest struct {
+	Name           string `json:"name" pii:"name"`
+	AccountNumber  string `json:"account_number" pii:"account"`
+	IFSCCode       string `json:"ifsc_code"`
+	Bank           string `json:"bank"`
+	Reason         string `json:"reason"`
+	Override       bool   `json:"override"`
+	OverrideReason string `json:"override_reason"`
+}
+
+// BeneficiaryChange is one correction of a loan's beneficiary. Previous holds
+// the details as they were when replaced.
+type BeneficiaryChange struct {
+	ChangeId              string      `json:"change_id"`
+	LoanId                string      `json:"loan_id"`
+	DisbursementId        string      `json:"disbursement_id"`
+	PreviousBeneficiaryId *string     `json:"previous_beneficiary_id"`
+	Previous              Beneficiary `json:"previous"`
+	BeneficiaryId         string      `json:"beneficiary_id"`
+	OperatorId            string      `json:"operator_id"`
+	Reason                string      `json:"reason"`
+	CreatedAt             time.Time   `json:"created_at"`
+}
@@ -0,0 +1,151 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"shared/apperrors"
+)
+
+type FailureCategory string
+type DeadLetterStatus string
+
+const (
+	FailureCategoryInvalidIFSC         FailureCategory = "invalid_ifsc"
+	FailureCategoryInactiveAccount     FailureCategory = "inactive_account"
+	FailureCategoryLimitExceeded       FailureCategory = "limit_exceeded"
+	FailureCategoryInsufficientBalance FailureCategory = "insufficient_balance"
+	FailureCategoryBankUnavailable     FailureCategory = "bank_unavailable"
+	FailureCategoryNetwork             FailureCategory = "network"
+	// FailureCategoryBeneficiary is a beneficiary that was no longer payable
+	// when the transfer was about to be sent.
+	FailureCategoryBeneficiary FailureCategory = "beneficiary_not_payable"
+	// FailureCategoryManual is a disbursement an operator marked failed.
+	FailureCategoryManual  FailureCategory = "manual"
+	FailureCategoryUnknown FailureCategory = "unknown"
+)
+
+const (
+	DeadLetterStatusOpen     DeadLetterStatus = "open"
+	DeadLetterStatusRequeued DeadLetterStatus = "requeued"
+	DeadLetterStatusClosed   DeadLetterStatus = "closed"
+)
+
+// MaxDeadLetterBulkSize caps how many disbursements one bulk request touches.
+const MaxDeadLetterBulkSize = 500
+
+const DeadLetterEvent = "disbursement.dead_lettered"
+
+var (
+	DEAD_LETTER_NOT_FOUND      = apperrors.New(apperrors.CodeNotFound, "dead letter not found")
+	DEAD_LETTER_NOT_OPEN       = apperrors.New(apperrors.CodeConflict, "dead letter is not open")
+	DISBURSEMENT_IDS_REQUIRED  = apperrors.New(apperrors.CodeInvalidRequest, "disbursement_ids is required")
+	TOO_MANY_DISBURSEMENT_IDS  = apperrors.New(apperrors.CodeInvalidRequest, "too many disbursement_ids in one request")
+	INVALID_FAILURE_CATEGORY   = apperrors.New(apperrors.CodeInvalidRequest, "invalid failure category")
+	INVALID_DEAD_LETTER_STATUS = apperrors.New(apperrors.CodeInvalidRequest, "invalid dead letter status")
+)
+
+// failureCategories maps gateway errors to categories. Errors arrive as the
+// gateway's message text, so they are matched on content rather than with
+// errors.Is.
+var failureCategories = []struct {
+	err      error
+	category FailureCategory
+}{
+	{INVALID_IFSC, FailureCategoryInvalidIFSC},
+	{INACTIVE_ACCOUNT, FailureCategoryInactiveAccount},
+	{LIMIT_EXCEEDED, FailureCategoryLimitExceeded},
+	{INSUFFICIENT_BALANCE, FailureCategoryInsufficientBalance},
+	{BENEFICIARY_BANK_DOWN, FailureCategoryBankUnavailable},
+	{SERVICE_UNAVAILABLE, FailureCategoryBankUnavailable},
+	{NETWORK_ERROR, FailureCategoryNetwork},
+	{BENEFICIARY_NOT_VERIFIED, FailureCategoryBeneficiary},
+	{BENEFICIARY_REJECTED, FailureCategoryBeneficiary},
+}
+
+// ClassifyFailure derives the failure category from a disbursement's last
+// error.
+func ClassifyFailure(lastError string) FailureCategory {
+	message := strings.ToLower(lastError)
+	for _, known := range failureCategories {
+		if strings.Contains(message, strings.ToLower(known.err.Error())) {
+			return known.category
+		}
+	}
+	return FailureCategoryUnknown
+}
+
+func (c FailureCategory) IsValid() bool {
+	if c == FailureCategoryManual || c == FailureCategoryUnknown {
+		return true
+	}
+	for _, known := range failureCategories {
+		if known.category == c {
+			return true
+		}
+	}
+	return false
+}
+
+func (s DeadLetterStatus) IsValid() bool {
+	switch s {
+	case DeadLetterStatusOpen, DeadLetterStatusRequeued, DeadLetterStatusClosed:
+		return true
+	}
+	return false
+}
+
+type DeadLetter struct {
+	DisbursementId string           `json:"disbursement_id"`
+	LoanId         string           `json:"loan_id"`
+	Amount         float64          `json:"amount"`
+	Channel        PaymentChannel   `json:"channel"`
+	Category       FailureCategory  `json:"category"`
+	LastError      string           `json:"last_error" pii:"text"`
+	Status         DeadLetterStatus `json:"status"`
+	FailureCount   int              `json:"failure_count"`
+	NotifiedAt     *time.Time       `json:"notified_at,omitempty"`
+	ResolvedBy     *string          `json:"resolved_by,omitempty"`
+	ResolutionNote *string          `json:"resolution_note,omitempty"`
+	ResolvedAt     *time.Time       `json:"resolved_at,omitempty"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+}
+
+type DeadLetterFilter struct {
+	Status   DeadLetterStatus
+	Category FailureCategory
+	Offset   int
+	Limit    int
+}
+
+type DeadLetterBulkRequest struct {
+	DisbursementIds []string `json:"disbursement_ids"`
+	Reason          string   `json:"reason"`
+}
+
+type DeadLetterBulkResult struct {
+	DisbursementId string `json:"disbursement_id"`
+	Success        bool   `json:"success"`
+	Error          string `json:"error,omitempty"`
+}
+
+type DeadLetterBulkResponse struct {
+	Succeeded int                    `json:"succeeded"`
+	Failed    int                    `json:"failed"`
+	Results   []DeadLetterBulkResult `json:"results"`
+}
+
+// DeadLetterNotification is posted to the loan origination system when a
+// disbursement fails permanently.
+type DeadLetterNotification struct {
+	Event          string          `json:"event"`
+	DisbursementId string          `json:"disbursement_id"`
+	LoanId         string          `json:"loan_id"`
+	Amount         float64         `json:"amount"`
+	Channel        PaymentChannel  `json:"channel"`
+	Category       FailureCategory `json:"category"`
+	LastError      string          `json:"last_error"`
+	FailureCount   int             `json:"failure_count"`
+	FailedAt       time.Time       `json:"failed_at"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	tests := []struct {
+		lastError string
+		expected  FailureCategory
+	}{
+		{"Invalid IFSC code", FailureCategoryInvalidIFSC},
+		{"invalid IFSC code", FailureCategoryInvalidIFSC},
+		{"Inactive Beneficiary Account", FailureCategoryInactiveAccount},
+		{"gateway error: Limit Exceeded for channel", FailureCategoryLimitExceeded},
+		{"Insufficient Balance", FailureCategoryInsufficientBalance},
+		{"Beneficiary Bank is Down", FailureCategoryBankUnavailable},
+		{"Service Unavailable", FailureCategoryBankUnavailable},
+		{"network error", FailureCategoryNetwork},
+		{"gateway error: status=502", FailureCategoryUnknown},
+		{"", FailureCategoryUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lastError, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ClassifyFailure(tt.lastError))
+		})
+	}
+}
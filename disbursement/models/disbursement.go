@@ -1,9 +1,14 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"shared/apperrors"
+)
 
 type DisbursementStatus string
 type TransactionStatus string
+type NameMatchDecision string
 
 const (
 	TransactionStatusInitiated TransactionStatus = "initiated"
@@ -18,34 +23,83 @@ const (
 	DisbursementStatusSuccess    DisbursementStatus = "success"
 	DisbursementStatusFailed     DisbursementStatus = "failed"
 	DisbursementStatusSuspended  DisbursementStatus = "suspended"
+	// DisbursementStatusScheduled disbursements wait for their scheduled_at
+	// before the scheduler releases them as initiated.
+	DisbursementStatusScheduled DisbursementStatus = "scheduled"
+	DisbursementStatusCancelled DisbursementStatus = "cancelled"
 )
 
+func (s DisbursementStatus) IsValid() bool {
+	switch s {
+	case DisbursementStatusInitiated,
+		DisbursementStatusProcessing,
+		DisbursementStatusSuccess,
+		DisbursementStatusFailed,
+		DisbursementStatusSuspended,
+		DisbursementStatusScheduled,
+		DisbursementStatusCancelled:
+		return true
+	}
+	return false
+}
+
+const (
+	NameMatchDecisionAllow NameMatchDecision = "allow"
+	NameMatchDecisionFlag  NameMatchDecision = "flag"
+	NameMatchDecisionBlock NameMatchDecision = "block"
+)
+
+var (
+	NAME_MISMATCH                = apperrors.New(apperrors.CodeUnprocessable, "beneficiary name does not match borrower")
+	DISBURSEMENT_NOT_CANCELLABLE = apperrors.New(apperrors.CodeConflict, "only scheduled disbursements can be cancelled")
+	PAYMENT_QUEUE_FULL           = apperrors.New(apperrors.CodeUnavailable, "payment queue is full, try again later")
+	INVALID_LOAN_ID              = apperrors.New(apperrors.CodeUnprocessable, "invalid loan id")
+	AMOUNT_EXCEEDS_UNDISBURSED   = apperrors.New(apperrors.CodeUnprocessable, "disbursement amount exceeds undisbursed loan amount")
+	DISBURSEMENT_NOT_RETRYABLE   = apperrors.New(apperrors.CodeConflict, "disbursement cannot be retried")
+	DISBURSEMENT_STATUS_CHANGED  = apperrors.New(apperrors.CodeConflict, "disbursement status changed, reload and try again")
+	DISBURSEMENT_NOT_FOUND       = apperrors.New(apperrors.CodeNotFound, "disbursement not found")
+)
+
+// DisburseRequest takes the payee's account details only for loans without
+// a beneficiary, and then all of account_number, ifsc_code and
+// beneficiary_name.
 type DisburseRequest struct {
-	LoanId          string  `json:"loan_id"`
-	Amount          float64 `json:"amount"`
-	AccountNumber   string  `json:"account_number"`
-	IFSCCode        string  `json:"ifsc_code"`
-	BeneficiaryName string  `json:"beneficiary_name"`
-	BeneficiaryBank string  `json:"beneficiary_bank"`
+	LoanId          string  `json:"loan_id" validate:"required,max=64"`
+	Amount          float64 `json:"amount" validate:"gt=0,lte=100000000"`
+	AccountNumber   string  `json:"account_number" pii:"account" validate:"required_with=IFSCCode BeneficiaryName,omitempty,account_number"`
+	IFSCCode        string  `json:"ifsc_code" validate:"required_with=AccountNumber BeneficiaryName,omitempty,ifsc"`
+	BeneficiaryName string  `json:"beneficiary_name" pii:"name" validate:"required_with=AccountNumber IFSCCode,omitempty,notblank,max=200"`
+	BeneficiaryBank string  `json:"beneficiary_bank" validate:"max=100"`
+	// ScheduledAt holds the disbursement until that time. Omitted or past
+	// values disburse immediately.
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+	// ClientId is the caller the disbursement is made for. It is set from
+	// the authenticated principal, never from the body, and scopes the
+	// webhook events the disbursement publishes.
+	ClientId string `json:"-"`
 }
 
 type TransactionResponse struct {
 	TransactionId string            `json:"transaction_id"`
 	Status        TransactionStatus `json:"status"`
 	Channel       PaymentChannel    `json:"channel"`
-	Message       *string           `json:"message"`
+	Message       *string           `json:"message" pii:"text"`
+	UTR           *string           `json:"utr,omitempty"`
 	CreatedAt     time.Time         `json:"created_at"`
 	UpdatedAt     time.Time         `json:"updated_at"`
 }
 
 type Disbursement struct {
-	DisbursementId string                `json:"disbursement_id"`
-	Status         DisbursementStatus    `json:"status"`
-	LoanId         string                `json:"loan_id"`
-	Amount         float64               `json:"amount"`
-	Transaction    []TransactionResponse `json:"transaction"`
-	CreatedAt      time.Time             `json:"created_at"`
-	UpdatedAt      time.Time             `json:"updated_at"`
+	DisbursementId       string                `json:"disbursement_id"`
+	Status               DisbursementStatus    `json:"status"`
+	LoanId               string                `json:"loan_id"`
+	Amount               float64               `json:"amount"`
+	NameMatch            NameMatchResult       `json:"name_match"`
+	Transaction          []TransactionResponse `json:"transaction"`
+	ScheduledAt          *time.Time            `json:"scheduled_at,omitempty"`
+	ExpectedSettlementAt *time.Time            `json:"expected_settlement_at,omitempty"`
+	CreatedAt            time.Time             `json:"created_at"`
+	UpdatedAt            time.Time             `json:"updated_at"`
 }
 
 type DisbursementResponse struct {
@@ -53,3 +107,9 @@ type DisbursementResponse struct {
 	Status         DisbursementStatus `json:"status"`
 	Message        string             `json:"message"`
 }
+
+type NameMatchResult struct {
+	BorrowerScore   *float64          `json:"borrower_score"`
+	RegisteredScore *float64          `json:"registered_score"`
+	Decision        NameMatchDecision `json:"decision"`
+}
@@ -1,14 +1,146 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"shared/apperrors"
+)
+
+type LoanStatus string
+type ProductType string
+type RepaymentFrequency string
+type InterestMethod string
+
+const (
+	LoanStatusSanctioned          LoanStatus = "sanctioned"
+	LoanStatusDisbursementPending LoanStatus = "disbursement_pending"
+	LoanStatusPartiallyDisbursed  LoanStatus = "partially_disbursed"
+	LoanStatusDisbursed           LoanStatus = "disbursed"
+	LoanStatusCancelled           LoanStatus = "cancelled"
+	LoanStatusClosed              LoanStatus = "closed"
+)
+
+const (
+	ProductTypePersonal  ProductType = "personal"
+	ProductTypeBusiness  ProductType = "business"
+	ProductTypeHome      ProductType = "home"
+	ProductTypeVehicle   ProductType = "vehicle"
+	ProductTypeEducation ProductType = "education"
+	ProductTypeGold      ProductType = "gold"
+)
+
+const (
+	RepaymentFrequencyMonthly   RepaymentFrequency = "monthly"
+	RepaymentFrequencyQuarterly RepaymentFrequency = "quarterly"
+)
+
+const (
+	InterestMethodReducingBalance InterestMethod = "reducing_balance"
+	InterestMethodFlat            InterestMethod = "flat"
+)
+
+var (
+	LOAN_AMOUNT_LOCKED             = apperrors.New(apperrors.CodeConflict, "loan amount and terms cannot change once a disbursement exists")
+	INVALID_LOAN_TERMS             = apperrors.New(apperrors.CodeInvalidRequest, "invalid loan terms")
+	LOAN_NOT_DISBURSABLE           = apperrors.New(apperrors.CodeUnprocessable, "loan is not open for disbursement")
+	INVALID_LOAN_STATUS_TRANSITION = apperrors.New(apperrors.CodeConflict, "invalid loan status transition")
+	LOAN_STATUS_CHANGED            = apperrors.New(apperrors.CodeConflict, "loan status changed, reload and try again")
+	LOAN_BENEFICIARY_LOCKED        = apperrors.New(apperrors.CodeConflict, "loan beneficiary cannot change here; use the admin beneficiary correction")
+	INVALID_PRODUCT_TYPE           = apperrors.New(apperrors.CodeInvalidRequest, "invalid product type")
+)
+
+// loanTransitions lists the statuses a loan may move to from each status.
+// Disbursement outcomes drive the loan between sanctioned, pending and
+// (partially) disbursed; cancelled and closed are terminal.
+var loanTransitions = map[LoanStatus][]LoanStatus{
+	LoanStatusSanctioned: {LoanStatusDisbursementPending, LoanStatusCancelled},
+	LoanStatusDisbursementPending: {
+		LoanStatusSanctioned,
+		LoanStatusPartiallyDisbursed,
+		LoanStatusDisbursed,
+	},
+	LoanStatusPartiallyDisbursed: {LoanStatusDisbursementPending, LoanStatusClosed},
+	LoanStatusDisbursed:          {LoanStatusClosed},
+}
+
+func (s LoanStatus) CanTransitionTo(next LoanStatus) bool {
+	for _, allowed := range loanTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDisbursable reports whether a new disbursement may be created for a loan
+// in this status.
+func (s LoanStatus) IsDisbursable() bool {
+	return s == LoanStatusSanctioned || s == LoanStatusPartiallyDisbursed
+}
+
+func (p ProductType) IsValid() bool {
+	switch p {
+	case ProductTypePersonal,
+		ProductTypeBusiness,
+		ProductTypeHome,
+		ProductTypeVehicle,
+		ProductTypeEducation,
+		ProductTypeGold:
+		return true
+	}
+	return false
+}
+
+// PeriodMonths returns the number of months between installments, or 0 for
+// an unknown frequency.
+func (f RepaymentFrequency) PeriodMonths() int {
+	switch f {
+	case RepaymentFrequencyMonthly:
+		return 1
+	case RepaymentFrequencyQuarterly:
+		return 3
+	}
+	return 0
+}
+
+func (m InterestMethod) IsValid() bool {
+	return m == InterestMethodReducingBalance || m == InterestMethodFlat
+}
 
 type Loan struct {
-	Id        string    `json:"id"`
-	Amount    float64   `json:"amount"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	Id                 string             `json:"id"`
+	Amount             float64            `json:"amount"`
+	DisbursedAmount    float64            `json:"disbursed_amount"`
+	BorrowerId         string             `json:"borrower_id"`
+	BorrowerName       string             `json:"borrower_name"`
+	ProductType        ProductType        `json:"product_type"`
+	SanctionDate       time.Time          `json:"sanction_date"`
+	InterestRate       float64            `json:"interest_rate"`
+	TenureMonths       int                `json:"tenure_months"`
+	RepaymentFrequency RepaymentFrequency `json:"repayment_frequency"`
+	InterestMethod     InterestMethod     `json:"interest_method"`
+	Status             LoanStatus         `json:"status"`
+	BeneficiaryId      *string            `json:"beneficiary_id"`
+	CreatedAt          time.Time          `json:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at"`
 }
 
 type LoanRequest struct {
-	Amount float64 `json:"amount"`
+	Amount             float64            `json:"amount" validate:"gt=0,lte=100000000"`
+	BorrowerId         string             `json:"borrower_id" validate:"required,max=64"`
+	BorrowerName       string             `json:"borrower_name" validate:"notblank,max=200"`
+	ProductType        ProductType        `json:"product_type" validate:"omitempty,oneof=personal business home vehicle education gold"`
+	SanctionDate       *time.Time         `json:"sanction_date"`
+	InterestRate       *float64           `json:"interest_rate" validate:"omitempty,gte=0,lte=100"`
+	TenureMonths       int                `json:"tenure_months" validate:"gte=0,lte=480"`
+	RepaymentFrequency RepaymentFrequency `json:"repayment_frequency" validate:"omitempty,oneof=monthly quarterly"`
+	InterestMethod     InterestMethod     `json:"interest_method" validate:"omitempty,oneof=reducing_balance flat"`
+}
+
+type LoanStatusRequest struct {
+	Status LoanStatus `json:"status"`
+}
+
+type LinkBeneficiaryRequest struct {
+	BeneficiaryId string `json:"beneficiary_id"`
 }
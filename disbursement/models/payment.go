@@ -3,6 +3,8 @@ package models
 import (
 	"errors"
 	"time"
+
+	"shared/apperrors"
 )
 
 type PaymentChannel string
@@ -13,13 +15,17 @@ const (
 	PaymentChannelIMPS PaymentChannel = "IMPS"
 )
 
+func (c PaymentChannel) IsValid() bool {
+	return c == PaymentChannelUPI || c == PaymentChannelNEFT || c == PaymentChannelIMPS
+}
+
 var (
 	INVALID_TRANSACTION_ID         = errors.New("invalid transaction ID")
 	TRANSACTION_NOT_FOUND          = errors.New("transaction not found")
 	TRANSACTION_ID_REQUIRED        = errors.New("transactionId is required")
 	NETWORK_ERROR                  = errors.New("network error")
 	UNKNOWN_ERROR                  = errors.New("unknown error")
-	INVALID_PAYMENT_CHANNEL        = errors.New("Invalid Payment Channel")
+	INVALID_PAYMENT_CHANNEL        = apperrors.New(apperrors.CodeInvalidRequest, "Invalid Payment Channel")
 	SERVICE_UNAVAILABLE            = errors.New("Service Unavailable")
 	BENEFICIARY_BANK_DOWN          = errors.New("Beneficiary Bank is Down")
 	LIMIT_EXCEEDED                 = errors.New("Limit Exceeded")
@@ -27,6 +33,7 @@ var (
 	INVALID_IFSC                   = errors.New("Invalid IFSC code")
 	INACTIVE_ACCOUNT               = errors.New("Inactive Beneficiary Account")
 	INSUFFICIENT_BALANCE           = errors.New("Insufficient Balance")
+	CHANNEL_NOT_SUPPORTED          = errors.New("channel not supported by beneficiary branch")
 )
 
 var TRANSIANT_FAILURES = []error{
@@ -37,6 +44,8 @@ var TRANSIANT_FAILURES = []error{
 	LIMIT_EXCEEDED,
 	BENEFICIARY_BANK_DOWN,
 	INSUFFICIENT_BALANCE,
+	// A retry moves the transfer to another channel.
+	CHANNEL_NOT_SUPPORTED,
 }
 
 var PERMANENT_FAILURES = []error{
@@ -84,10 +93,25 @@ type PaymentNotificationRequest struct {
 	ProcessedAt   time.Time         `json:"processed_at"`
 }
 
+type AccountVerificationRequest struct {
+	ReferenceID string      `json:"reference_id"`
+	Beneficiary Beneficiary `json:"beneficiary"`
+}
+
+type AccountVerificationResponse struct {
+	TransactionID  string            `json:"transaction_id"`
+	ReferenceID    string            `json:"reference_id"`
+	Account        string            `json:"account" pii:"account"`
+	IFSC           string            `json:"ifsc"`
+	RegisteredName string            `json:"registered_name" pii:"name"`
+	Status         TransactionStatus `json:"status"`
+	VerifiedAt     time.Time         `json:"verified_at"`
+}
+
 type PaymentError struct {
 	Error string `json:"error"`
 }
 
 type PaymentChannelResponse struct {
-	Active bool `json:"active"`
+	Available bool `json:"available"`
 }
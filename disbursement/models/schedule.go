@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+type Installment struct {
+	DisbursementId string    `json:"disbursement_id"`
+	Number         int       `json:"number"`
+	DueDate        time.Time `json:"due_date"`
+	OpeningBalance float64   `json:"opening_balance"`
+	Principal      float64   `json:"principal"`
+	Interest       float64   `json:"interest"`
+	Amount         float64   `json:"amount"`
+	ClosingBalance float64   `json:"closing_balance"`
+}
+
+type RepaymentSchedule struct {
+	LoanId             string             `json:"loan_id"`
+	InterestRate       float64            `json:"interest_rate"`
+	TenureMonths       int                `json:"tenure_months"`
+	RepaymentFrequency RepaymentFrequency `json:"repayment_frequency"`
+	InterestMethod     InterestMethod     `json:"interest_method"`
+	TotalPrincipal     float64            `json:"total_principal"`
+	TotalInterest      float64            `json:"total_interest"`
+	Installments       []Installment      `json:"installments"`
+}
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"time"
+
+	"shared/apperrors"
+)
+
+type WebhookEvent string
+type WebhookDeliveryStatus string
+
+const (
+	WebhookEventCreated    WebhookEvent = "disbursement.created"
+	WebhookEventProcessing WebhookEvent = "disbursement.processing"
+	WebhookEventSuccess    WebhookEvent = "disbursement.success"
+	WebhookEventSuspended  WebhookEvent = "disbursement.suspended"
+	WebhookEventFailed     WebhookEvent = "disbursement.failed"
+	WebhookEventCancelled  WebhookEvent = "disbursement.cancelled"
+)
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusDelivered WebhookDeliveryStatus = "delivered"
+	// WebhookDeliveryStatusFailed is a delivery that used up its attempts. It
+	// is only sent again on manual redelivery.
+	WebhookDeliveryStatusFailed WebhookDeliveryStatus = "failed"
+)
+
+var (
+	WEBHOOK_CLIENT_ID_REQUIRED      = apperrors.New(apperrors.CodeUnauthenticated, "webhooks need an authenticated client")
+	INVALID_WEBHOOK_URL             = apperrors.New(apperrors.CodeInvalidRequest, "url must be an absolute http or https URL")
+	WEBHOOK_URL_NOT_PUBLIC          = apperrors.New(apperrors.CodeInvalidRequest, "url must point to a public internet address")
+	WEBHOOK_EVENTS_REQUIRED         = apperrors.New(apperrors.CodeInvalidRequest, "events is required")
+	INVALID_WEBHOOK_EVENT           = apperrors.New(apperrors.CodeInvalidRequest, "invalid webhook event")
+	INVALID_WEBHOOK_DELIVERY_STATUS = apperrors.New(apperrors.CodeInvalidRequest, "invalid webhook delivery status")
+	WEBHOOK_SUBSCRIPTION_NOT_FOUND  = apperrors.New(apperrors.CodeNotFound, "webhook subscription not found")
+	WEBHOOK_DELIVERY_NOT_FOUND      = apperrors.New(apperrors.CodeNotFound, "webhook delivery not found")
+	WEBHOOK_DELIVERY_PENDING        = apperrors.New(apperrors.CodeConflict, "webhook delivery is already pending")
+)
+
+// webhookEventStatuses is the disbursement status each status change event
+// reports. Created reports whichever status the disbursement was created in.
+var webhookEventStatuses = map[WebhookEvent]DisbursementStatus{
+	WebhookEventProcessing: DisbursementStatusProcessing,
+	WebhookEventSuccess:    DisbursementStatusSuccess,
+	WebhookEventSuspended:  DisbursementStatusSuspended,
+	WebhookEventFailed:     DisbursementStatusFailed,
+	WebhookEventCancelled:  DisbursementStatusCancelled,
+}
+
+func (e WebhookEvent) IsValid() bool {
+	_, ok := webhookEventStatuses[e]
+	return ok || e == WebhookEventCreated
+}
+
+// Status returns the disbursement status the event reports, and false for
+// events that are not tied to one status.
+func (e WebhookEvent) Status() (DisbursementStatus, bool) {
+	status, ok := webhookEventStatuses[e]
+	return status, ok
+}
+
+// WebhookEventFor returns the event announcing a change to status, and false
+// when subscribers are not told about that status.
+func WebhookEventFor(status DisbursementStatus) (WebhookEvent, bool) {
+	for event, eventStatus := range webhookEventStatuses {
+		if eventStatus == status {
+			return event, true
+		}
+	}
+	return "", false
+}
+
+func (s WebhookDeliveryStatus) IsValid() bool {
+	switch s {
+	case WebhookDeliveryStatusPending, WebhookDeliveryStatusDelivered, WebhookDeliveryStatusFailed:
+		return true
+	}
+	return false
+}
+
+// WebhookSubscriptionRequest subscribes the calling client. The client is
+// the authenticated principal, so a caller cannot subscribe on behalf of
+// another.
+type WebhookSubscriptionRequest struct {
+	URL    string         `json:"url"`
+	Events []WebhookEvent `json:"events"`
+}
+
+// WebhookSubscription carries the signing secret only in the response to
+// the request that created it.
+type WebhookSubscription struct {
+	Id        string         `json:"id"`
+	ClientId  string         `json:"client_id"`
+	URL       string         `json:"url"`
+	Events    []WebhookEvent `json:"events"`
+	Secret    string         `json:"secret,omitempty"`
+	Active    bool           `json:"active"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+type WebhookDeliveryFilter struct {
+	Status WebhookDeliveryStatus
+	Offset int
+	Limit  int
+}
+
+type WebhookDelivery struct {
+	Id             string                `json:"id"`
+	EventId        string                `json:"event_id"`
+	SubscriptionId string                `json:"subscription_id"`
+	DisbursementId string                `json:"disbursement_id"`
+	Event          WebhookEvent          `json:"event"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	NextAttemptAt  *time.Time            `json:"next_attempt_at,omitempty"`
+	LastStatusCode *int                  `json:"last_status_code,omitempty"`
+	LastError      *string               `json:"last_error,omitempty" pii:"text"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+}
+
+// WebhookPayload is the body posted to subscribers. Every subscription
+// notified of the same status change gets the same event id, and a
+// redelivery sends the original body again.
+type WebhookPayload struct {
+	EventId    string                  `json:"event_id"`
+	Event      WebhookEvent            `json:"event"`
+	OccurredAt time.Time               `json:"occurred_at"`
+	Data       WebhookDisbursementData `json:"data"`
+}
+
+type WebhookDisbursementData struct {
+	DisbursementId string             `json:"disbursement_id"`
+	LoanId         string             `json:"loan_id"`
+	Amount         float64            `json:"amount"`
+	Channel        PaymentChannel     `json:"channel"`
+	Status         DisbursementStatus `json:"status"`
+	RetryCount     int                `json:"retry_count"`
+	LastError      *string            `json:"last_error,omitempty"`
+}
+
+// WebhookRequest is one attempt at posting a delivery to its subscriber.
+type WebhookRequest struct {
+	URL        string
+	Secret     string
+	DeliveryId string
+	Event      WebhookEvent
+	Payload    []byte
+}
@@ -0,0 +1,228 @@
+// Package namematch scores how likely two spellings of a person's name refer
+// to the same individual. It is tuned for Indian names, where honorifics,
+// initials, transliteration variants and token order differ freely between
+// loan applications and bank records.
+package namematch
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const (
+	// initialSimilarity is the credit given when a single letter initial
+	// matches the first letter of a full token in the other name.
+	initialSimilarity = 0.85
+
+	// coverageWeight controls how much the score is driven by the shorter
+	// name being fully explained by the longer one. The remainder penalises
+	// extra tokens, so "RAVI KUMAR" vs "RAVI KUMAR SHARMA" scores high but
+	// below an exact match.
+	coverageWeight = 0.75
+)
+
+var honorifics = map[string]struct{}{
+	"MR": {}, "MRS": {}, "MS": {}, "MISS": {}, "MASTER": {}, "DR": {}, "PROF": {},
+	"SHRI": {}, "SRI": {}, "SHREE": {}, "SMT": {}, "SMTI": {}, "KUM": {}, "KUMARI": {},
+	"SHRIMATI": {}, "LATE": {}, "MX": {}, "SIR": {}, "MADAM": {},
+}
+
+// variants maps common abbreviations and spellings to a single canonical form
+// before phonetic folding is applied.
+var variants = map[string]string{
+	"MOHD":     "MOHAMMAD",
+	"MD":       "MOHAMMAD",
+	"MOHAMMED": "MOHAMMAD",
+	"MOHAMED":  "MOHAMMAD",
+	"MOHAMAD":  "MOHAMMAD",
+	"MUHAMMAD": "MOHAMMAD",
+	"MUHAMMED": "MOHAMMAD",
+	"KR":       "KUMAR",
+	"KMR":      "KUMAR",
+	"PD":       "PRASAD",
+	"PRASHAD":  "PRASAD",
+	"LAKSHMI":  "LAXMI",
+	"LAKSMI":   "LAXMI",
+	"CH":       "CHANDRA",
+	"SK":       "SHEIKH",
+	"SHAIKH":   "SHEIKH",
+	"SYED":     "SAYYED",
+	"SAIYED":   "SAYYED",
+}
+
+// phoneticFolds collapses spellings that transliterate the same sound.
+var phoneticFolds = strings.NewReplacer(
+	"AA", "A",
+	"EE", "I",
+	"II", "I",
+	"OO", "U",
+	"UU", "U",
+	"KSH", "X",
+	"KS", "X",
+	"TH", "T",
+	"DH", "D",
+	"BH", "B",
+	"KH", "K",
+	"GH", "G",
+	"CH", "C",
+	"JH", "J",
+	"PH", "F",
+	"SH", "S",
+	"W", "V",
+	"Z", "J",
+	"Q", "K",
+	"Y", "I",
+)
+
+// Score returns a similarity between 0 and 100 for two names. Tokens are
+// paired regardless of order, so "KUMAR RAVI" and "Ravi Kumar" score 100.
+func Score(a, b string) float64 {
+	aTokens := tokens(a)
+	bTokens := tokens(b)
+	if len(aTokens) == 0 || len(bTokens) == 0 {
+		return 0
+	}
+
+	matched := bestPairing(aTokens, bTokens)
+	shorter := float64(min(len(aTokens), len(bTokens)))
+	longer := float64(max(len(aTokens), len(bTokens)))
+
+	score := 100 * (coverageWeight*matched/shorter + (1-coverageWeight)*matched/longer)
+	return math.Round(score*100) / 100
+}
+
+type token struct {
+	raw string
+	key string
+}
+
+func (t token) isInitial() bool {
+	return len(t.raw) == 1
+}
+
+func tokens(name string) []token {
+	fields := strings.FieldsFunc(strings.ToUpper(name), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	result := make([]token, 0, len(fields))
+	for _, field := range fields {
+		if _, ok := honorifics[field]; ok {
+			continue
+		}
+		if canonical, ok := variants[field]; ok {
+			field = canonical
+		}
+		result = append(result, token{raw: field, key: phoneticKey(field)})
+	}
+	return result
+}
+
+func phoneticKey(value string) string {
+	folded := phoneticFolds.Replace(value)
+
+	// Collapse doubled letters ("LALLU" -> "LALU") left after folding.
+	runes := make([]rune, 0, len(folded))
+	for _, r := range folded {
+		if len(runes) > 0 && runes[len(runes)-1] == r {
+			continue
+		}
+		runes = append(runes, r)
+	}
+
+	// A trailing schwa is often dropped or added in transliteration
+	// ("RAMA"/"RAM", "KRISHNA"/"KRISHN").
+	if len(runes) > 3 && runes[len(runes)-1] == 'A' {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes)
+}
+
+type pair struct {
+	a, b       int
+	similarity float64
+}
+
+// bestPairing greedily matches tokens by descending similarity and returns
+// the sum of similarities of the matched pairs.
+func bestPairing(aTokens, bTokens []token) float64 {
+	pairs := make([]pair, 0, len(aTokens)*len(bTokens))
+	for i, a := range aTokens {
+		for j, b := range bTokens {
+			if similarity := tokenSimilarity(a, b); similarity > 0 {
+				pairs = append(pairs, pair{a: i, b: j, similarity: similarity})
+			}
+		}
+	}
+	slices.SortStableFunc(pairs, func(x, y pair) int {
+		switch {
+		case x.similarity > y.similarity:
+			return -1
+		case x.similarity < y.similarity:
+			return 1
+		}
+		return 0
+	})
+
+	usedA := make([]bool, len(aTokens))
+	usedB := make([]bool, len(bTokens))
+	total := 0.0
+	for _, p := range pairs {
+		if usedA[p.a] || usedB[p.b] {
+			continue
+		}
+		usedA[p.a] = true
+		usedB[p.b] = true
+		total += p.similarity
+	}
+	return total
+}
+
+func tokenSimilarity(a, b token) float64 {
+	if a.key == b.key {
+		return 1
+	}
+	if a.isInitial() || b.isInitial() {
+		if a.raw[0] == b.raw[0] {
+			return initialSimilarity
+		}
+		return 0
+	}
+	return dice(a.key, b.key)
+}
+
+// dice returns the Sørensen–Dice coefficient over character bigrams.
+func dice(a, b string) float64 {
+	aBigrams := bigrams(a)
+	bBigrams := bigrams(b)
+	if len(aBigrams) == 0 || len(bBigrams) == 0 {
+		return 0
+	}
+
+	counts := make(map[string]int, len(aBigrams))
+	for _, bigram := range aBigrams {
+		counts[bigram]++
+	}
+	common := 0
+	for _, bigram := range bBigrams {
+		if counts[bigram] > 0 {
+			counts[bigram]--
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(aBigrams)+len(bBigrams))
+}
+
+func bigrams(value string) []string {
+	runes := []rune(value)
+	if len(runes) < 2 {
+		return nil
+	}
+	result := make([]string, 0, len(runes)-1)
+	for i := 0; i < len(runes)-1; i++ {
+		result = append(result, string(runes[i:i+2]))
+	}
+	return result
+}
@@ -0,0 +1,41 @@
+package namematch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScore(t *testing.T) {
+	testCases := []struct {
+		name    string
+		a, b    string
+		atLeast float64
+		below   float64
+	}{
+		{name: "identical names", a: "Ravi Kumar", b: "RAVI KUMAR", atLeast: 100, below: 100.01},
+		{name: "reordered tokens", a: "Kumar Ravi", b: "Ravi Kumar", atLeast: 100, below: 100.01},
+		{name: "honorifics ignored", a: "Mr. Ravi Kumar", b: "SHRI RAVI KUMAR", atLeast: 100, below: 100.01},
+		{name: "punctuation ignored", a: "Ravi-Kumar.", b: "RAVI KUMAR", atLeast: 100, below: 100.01},
+		{name: "initials", a: "R. Kumar", b: "Ravi Kumar", atLeast: 90, below: 100},
+		{name: "abbreviated variant", a: "Mohd Irfan", b: "MOHAMMED IRFAN", atLeast: 100, below: 100.01},
+		{name: "transliteration variant", a: "Laxmi Devi", b: "LAKSHMI DEVI", atLeast: 100, below: 100.01},
+		{name: "spelling variant", a: "Sreedhar Rao", b: "SRIDHAR RAO", atLeast: 100, below: 100.01},
+		{name: "missing middle name", a: "Ravi Sharma", b: "RAVI KUMAR SHARMA", atLeast: 85, below: 100},
+		{name: "typo", a: "Rajesh Kumar", b: "RAJSH KUMAR", atLeast: 75, below: 100},
+		{name: "different person", a: "Ravi Kumar", b: "ANITA DESAI", atLeast: 0, below: 30},
+		{name: "same surname only", a: "Ravi Kumar", b: "SURESH KUMAR", atLeast: 40, below: 80},
+		{name: "wrong initial", a: "S. Kumar", b: "Ravi Kumar", atLeast: 40, below: 80},
+		{name: "empty name", a: "", b: "RAVI KUMAR", atLeast: 0, below: 0.01},
+		{name: "only honorifics", a: "Mr.", b: "RAVI KUMAR", atLeast: 0, below: 0.01},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			score := Score(tc.a, tc.b)
+			assert.GreaterOrEqual(t, score, tc.atLeast)
+			assert.Less(t, score, tc.below)
+			assert.Equal(t, score, Score(tc.b, tc.a), "score should be symmetric")
+		})
+	}
+}
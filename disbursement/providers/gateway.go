@@ -38,7 +38,7 @@ func (g GatewayProvider) Transfer(
 		},
 	)
 	if err != nil {
-		log.Error().Err(err).Msg("gateway error")
+		log.Ctx(ctx).Error().Err(err).Msg("gateway error")
 		return models.PaymentResponse{}, models.NETWORK_ERROR
 	}
 	defer resp.Body.Close()
@@ -46,10 +46,10 @@ func (g GatewayProvider) Transfer(
 	if resp.StatusCode != http.StatusOK {
 		var errBody map[string]any
 		if err := json.NewDecoder(resp.Body).Decode(&errBody); err != nil {
-			log.Error().Err(err).Msg("failed to decode error response")
+			log.Ctx(ctx).Error().Err(err).Msg("failed to decode error response")
 			return models.PaymentResponse{}, fmt.Errorf("gateway error: status=%d", resp.StatusCode)
 		}
-		if errorMessage, ok := errBody["error"].(string); ok {
+		if errorMessage, ok := gatewayErrorMessage(errBody); ok {
 			return models.PaymentResponse{}, errors.New(errorMessage)
 		}
 		return models.PaymentResponse{}, fmt.Errorf(
@@ -92,13 +92,13 @@ func (g GatewayProvider) Fetch(
 	if resp.StatusCode != http.StatusOK {
 		var errBody map[string]any
 		if err := json.NewDecoder(resp.Body).Decode(&errBody); err != nil {
-			log.Error().Err(err).Msg("failed to decode error response")
+			log.Ctx(ctx).Error().Err(err).Msg("failed to decode error response")
 			return models.PaymentResponse{}, fmt.Errorf("gateway error: status=%d", resp.StatusCode)
 		}
-		if errorMessage, ok := errBody["error"].(string); ok {
+		if errorMessage, ok := gatewayErrorMessage(errBody); ok {
 			return models.PaymentResponse{}, errors.New(errorMessage)
 		}
-		log.Error().Int("status_code", resp.StatusCode).
+		log.Ctx(ctx).Error().Int("status_code", resp.StatusCode).
 			RawJSON("body", []byte(fmt.Sprintf("%v", errBody))).
 			Msg("gateway error")
 		return models.PaymentResponse{}, models.UNKNOWN_ERROR
@@ -135,5 +135,63 @@ func (g GatewayProvider) IsActive(
 		return false, err
 	}
 
-	return result.Active, nil
+	return result.Available, nil
+}
+
+func (g GatewayProvider) VerifyAccount(
+	ctx context.Context,
+	req models.AccountVerificationRequest,
+) (models.AccountVerificationResponse, error) {
+	resp, err := g.client.POST(
+		ctx,
+		fmt.Sprintf("%s/api/v1/verification", g.baseURL),
+		req,
+		map[string]string{
+			"Content-Type": "application/json",
+		},
+	)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("gateway error")
+		return models.AccountVerificationResponse{}, models.NETWORK_ERROR
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errBody map[string]any
+		if err := json.NewDecoder(resp.Body).Decode(&errBody); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to decode error response")
+			return models.AccountVerificationResponse{}, fmt.Errorf(
+				"gateway error: status=%d",
+				resp.StatusCode,
+			)
+		}
+		if errorMessage, ok := gatewayErrorMessage(errBody); ok {
+			return models.AccountVerificationResponse{}, errors.New(errorMessage)
+		}
+		return models.AccountVerificationResponse{}, fmt.Errorf(
+			"gateway error: status=%d body=%v",
+			resp.StatusCode,
+			errBody,
+		)
+	}
+
+	var result models.AccountVerificationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return models.AccountVerificationResponse{}, err
+	}
+
+	return result, nil
+}
+
+// gatewayErrorMessage reads the message out of a gateway error body, which
+// is an error envelope or, from gateways that predate it, a bare string.
+func gatewayErrorMessage(body map[string]any) (string, bool) {
+	switch e := body["error"].(type) {
+	case string:
+		return e, true
+	case map[string]any:
+		message, ok := e["message"].(string)
+		return message, ok
+	}
+	return "", false
 }
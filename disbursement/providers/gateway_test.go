@@ -534,7 +534,7 @@ func TestGatewayProvider_IsActive(t *testing.T) {
 
 		channel := models.PaymentChannelUPI
 
-		responseBody := `{"active": true}`
+		responseBody := `{"channel": "NEFT", "available": true}`
 		response := http_test.NewJSONResponse(http.StatusOK, responseBody)
 
 		expectedURL := "http://localhost:8080/api/v1/channel/UPI/status"
@@ -557,7 +557,7 @@ func TestGatewayProvider_IsActive(t *testing.T) {
 
 		channel := models.PaymentChannelNEFT
 
-		responseBody := `{"active": false}`
+		responseBody := `{"channel": "NEFT", "available": false}`
 		response := http_test.NewJSONResponse(http.StatusOK, responseBody)
 
 		expectedURL := "http://localhost:8080/api/v1/channel/NEFT/status"
@@ -660,7 +660,7 @@ func TestGatewayProvider_IsActive(t *testing.T) {
 				mockClient := new(http_test.MockHTTPClient)
 				provider := NewGatewayProvider(baseURL, mockClient)
 
-				responseBody := `{"active": true}`
+				responseBody := `{"available": true}`
 				response := http_test.NewJSONResponse(http.StatusOK, responseBody)
 
 				expectedURL := "http://localhost:8080/api/v1/channel/" + string(
@@ -727,3 +727,98 @@ func TestGatewayProvider_IsActive(t *testing.T) {
 		response.Body.Close()
 	})
 }
+
+func TestGatewayProvider_VerifyAccount(t *testing.T) {
+	ctx := context.Background()
+	baseURL := "http://localhost:8080"
+
+	request := models.AccountVerificationRequest{
+		ReferenceID: "REF-123",
+		Beneficiary: models.Beneficiary{
+			Name:    "John Doe",
+			Account: "1234567890",
+			IFSC:    "IFSC0001234",
+			Bank:    "Test Bank",
+		},
+	}
+
+	t.Run("successfully verifies account", func(t *testing.T) {
+		mockClient := new(http_test.MockHTTPClient)
+		provider := NewGatewayProvider(baseURL, mockClient)
+
+		expectedResponse := models.AccountVerificationResponse{
+			TransactionID:  "IMPS-TXN-123456789012",
+			ReferenceID:    request.ReferenceID,
+			Account:        request.Beneficiary.Account,
+			IFSC:           request.Beneficiary.IFSC,
+			RegisteredName: "JOHN DOE",
+			Status:         "success",
+		}
+
+		responseBody, _ := json.Marshal(expectedResponse)
+		response := http_test.NewJSONResponse(http.StatusOK, string(responseBody))
+
+		mockClient.On("POST", ctx, "http://localhost:8080/api/v1/verification", request, mock.MatchedBy(func(headers map[string]string) bool {
+			return headers["Content-Type"] == "application/json"
+		})).
+			Return(response, nil).
+			Once()
+
+		result, err := provider.VerifyAccount(ctx, request)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expectedResponse.TransactionID, result.TransactionID)
+		assert.Equal(t, expectedResponse.RegisteredName, result.RegisteredName)
+
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("returns network error when POST request fails", func(t *testing.T) {
+		mockClient := new(http_test.MockHTTPClient)
+		provider := NewGatewayProvider(baseURL, mockClient)
+
+		mockClient.On("POST", ctx, "http://localhost:8080/api/v1/verification", request, mock.Anything).
+			Return(nil, errors.New("connection refused")).Once()
+
+		result, err := provider.VerifyAccount(ctx, request)
+
+		assert.Equal(t, models.NETWORK_ERROR, err)
+		assert.Equal(t, models.AccountVerificationResponse{}, result)
+	})
+
+	t.Run("returns gateway error message when status code is not OK", func(t *testing.T) {
+		mockClient := new(http_test.MockHTTPClient)
+		provider := NewGatewayProvider(baseURL, mockClient)
+
+		response := http_test.NewJSONResponse(
+			http.StatusBadRequest,
+			`{"error": "Inactive Beneficiary Account"}`,
+		)
+
+		mockClient.On("POST", ctx, "http://localhost:8080/api/v1/verification", request, mock.Anything).
+			Return(response, nil).Once()
+
+		result, err := provider.VerifyAccount(ctx, request)
+
+		assert.Error(t, err)
+		assert.Equal(t, models.INACTIVE_ACCOUNT.Error(), err.Error())
+		assert.Equal(t, models.AccountVerificationResponse{}, result)
+	})
+
+	t.Run("reads the message of a gateway error envelope", func(t *testing.T) {
+		mockClient := new(http_test.MockHTTPClient)
+		provider := NewGatewayProvider(baseURL, mockClient)
+
+		response := http_test.NewJSONResponse(
+			http.StatusUnprocessableEntity,
+			`{"error": {"code": "unprocessable", "message": "Inactive Beneficiary Account", "request_id": "req-1"}}`,
+		)
+
+		mockClient.On("POST", ctx, "http://localhost:8080/api/v1/verification", request, mock.Anything).
+			Return(response, nil).Once()
+
+		_, err := provider.VerifyAccount(ctx, request)
+
+		assert.Equal(t, models.INACTIVE_ACCOUNT.Error(), err.Error())
+	})
+}
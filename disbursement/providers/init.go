@@ -17,6 +17,10 @@ type PaymentProvider interface {
 		ctx context.Context,
 		channel models.PaymentChannel,
 	) (bool, error)
+	VerifyAccount(
+		ctx context.Context,
+		req models.AccountVerificationRequest,
+	) (models.AccountVerificationResponse, error)
 }
 
 func NewPaymentProvider(baseURL string, client httpclient.HTTPClient) (PaymentProvider, error) {
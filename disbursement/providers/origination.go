@@ -0,0 +1,53 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	httpclient "loan-disbursement-service/http"
+	"loan-disbursement-service/models"
+)
+
+// OriginationProvider tells the loan origination system about disbursements
+// it needs to act on.
+type OriginationProvider interface {
+	NotifyDeadLetter(ctx context.Context, notification models.DeadLetterNotification) error
+}
+
+type OriginationClient struct {
+	notificationURL string
+	client          httpclient.HTTPClient
+}
+
+func NewOriginationProvider(notificationURL string, client httpclient.HTTPClient) *OriginationClient {
+	return &OriginationClient{
+		notificationURL: notificationURL,
+		client:          client,
+	}
+}
+
+// NotifyDeadLetter posts the notification to the origination system's
+// webhook. Any 2xx response counts as delivered.
+func (o OriginationClient) NotifyDeadLetter(
+	ctx context.Context,
+	notification models.DeadLetterNotification,
+) error {
+	resp, err := o.client.POST(
+		ctx,
+		o.notificationURL,
+		notification,
+		map[string]string{
+			"Content-Type": "application/json",
+		},
+	)
+	if err != nil {
+		return models.NETWORK_ERROR
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("origination system rejected notification: status=%d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"loan-disbursement-service/models"
+	http_test "loan-disbursement-service/test/http"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOriginationClient_NotifyDeadLetter(t *testing.T) {
+	ctx := context.Background()
+	url := "http://los.local/webhooks/disbursement"
+	notification := models.DeadLetterNotification{
+		Event:          models.DeadLetterEvent,
+		DisbursementId: "DISB-001",
+		LoanId:         "LOAN-001",
+		Category:       models.FailureCategoryInvalidIFSC,
+	}
+
+	t.Run("delivers notification on accepted response", func(t *testing.T) {
+		mockClient := new(http_test.MockHTTPClient)
+		provider := NewOriginationProvider(url, mockClient)
+
+		mockClient.On("POST", ctx, url, notification, mock.Anything).
+			Return(http_test.NewJSONResponse(http.StatusAccepted, `{}`), nil).
+			Once()
+
+		err := provider.NotifyDeadLetter(ctx, notification)
+
+		assert.NoError(t, err)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("returns error when origination system rejects notification", func(t *testing.T) {
+		mockClient := new(http_test.MockHTTPClient)
+		provider := NewOriginationProvider(url, mockClient)
+
+		mockClient.On("POST", ctx, url, notification, mock.Anything).
+			Return(http_test.NewJSONResponse(http.StatusInternalServerError, `{}`), nil).
+			Once()
+
+		err := provider.NotifyDeadLetter(ctx, notification)
+
+		assert.ErrorContains(t, err, "status=500")
+	})
+
+	t.Run("returns network error when request fails", func(t *testing.T) {
+		mockClient := new(http_test.MockHTTPClient)
+		provider := NewOriginationProvider(url, mockClient)
+
+		mockClient.On("POST", ctx, url, notification, mock.Anything).
+			Return(nil, errors.New("connection refused")).
+			Once()
+
+		err := provider.NotifyDeadLetter(ctx, notification)
+
+		assert.Equal(t, models.NETWORK_ERROR, err)
+	})
+}
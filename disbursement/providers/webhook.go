@@ -0,0 +1,72 @@
+package providers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	httpclient "loan-disbursement-service/http"
+	"loan-disbursement-service/models"
+)
+
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookIdHeader        = "X-Webhook-Id"
+	WebhookEventHeader     = "X-Webhook-Event"
+)
+
+// WebhookProvider posts disbursement events to subscriber URLs.
+type WebhookProvider interface {
+	Deliver(ctx context.Context, req models.WebhookRequest) (int, error)
+}
+
+type WebhookClient struct {
+	client httpclient.HTTPClient
+}
+
+func NewWebhookProvider(client httpclient.HTTPClient) *WebhookClient {
+	return &WebhookClient{client: client}
+}
+
+// Deliver posts the payload signed with the subscription's secret and
+// returns the subscriber's status code, which is 0 when no response came
+// back. Any 2xx response counts as delivered.
+func (w WebhookClient) Deliver(ctx context.Context, req models.WebhookRequest) (int, error) {
+	resp, err := w.client.POST(
+		ctx,
+		req.URL,
+		req.Payload,
+		map[string]string{
+			"Content-Type":         "application/json",
+			WebhookIdHeader:        req.DeliveryId,
+			WebhookEventHeader:     string(req.Event),
+			WebhookSignatureHeader: SignWebhook(req.Secret, time.Now().Unix(), req.Payload),
+		},
+	)
+	if err != nil {
+		return 0, models.NETWORK_ERROR
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return resp.StatusCode, fmt.Errorf("subscriber rejected webhook: status=%d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// SignWebhook builds the signature header value. Subscribers recompute the
+// HMAC-SHA256 of "<t>.<body>" with their secret and compare it to v1, and
+// reject timestamps too far in the past to stop replays.
+func SignWebhook(secret string, timestamp int64, payload []byte) string {
+	t := strconv.FormatInt(timestamp, 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return fmt.Sprintf("t=%s,v1=%s", t, hex.EncodeToString(mac.Sum(nil)))
+}
@@ -0,0 +1,92 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"loan-disbursement-service/models"
+	http_test "loan-disbursement-service/test/http"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWebhookClient_Deliver(t *testing.T) {
+	ctx := context.Background()
+	req := models.WebhookRequest{
+		URL:        "http://los.local/webhooks/disbursement",
+		Secret:     "whsec_test",
+		DeliveryId: "WHD-001",
+		Event:      models.WebhookEventSuccess,
+		Payload:    []byte(`{"event":"disbursement.success"}`),
+	}
+
+	t.Run("posts signed payload and reports status code", func(t *testing.T) {
+		mockClient := new(http_test.MockHTTPClient)
+		provider := NewWebhookProvider(mockClient)
+
+		var headers map[string]string
+		mockClient.On("POST", ctx, req.URL, req.Payload, mock.Anything).
+			Run(func(args mock.Arguments) {
+				headers = args.Get(3).(map[string]string)
+			}).
+			Return(http_test.NewJSONResponse(http.StatusNoContent, ``), nil).
+			Once()
+
+		status, err := provider.Deliver(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, status)
+		assert.Equal(t, "WHD-001", headers[WebhookIdHeader])
+		assert.Equal(t, "disbursement.success", headers[WebhookEventHeader])
+
+		timestamp, err := strconv.ParseInt(
+			strings.TrimPrefix(strings.Split(headers[WebhookSignatureHeader], ",")[0], "t="), 10, 64,
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, SignWebhook(req.Secret, timestamp, req.Payload), headers[WebhookSignatureHeader])
+	})
+
+	t.Run("returns error and status code when subscriber rejects webhook", func(t *testing.T) {
+		mockClient := new(http_test.MockHTTPClient)
+		provider := NewWebhookProvider(mockClient)
+
+		mockClient.On("POST", ctx, req.URL, req.Payload, mock.Anything).
+			Return(http_test.NewJSONResponse(http.StatusBadGateway, `{}`), nil).
+			Once()
+
+		status, err := provider.Deliver(ctx, req)
+
+		assert.Equal(t, http.StatusBadGateway, status)
+		assert.ErrorContains(t, err, "status=502")
+	})
+
+	t.Run("returns network error when request fails", func(t *testing.T) {
+		mockClient := new(http_test.MockHTTPClient)
+		provider := NewWebhookProvider(mockClient)
+
+		mockClient.On("POST", ctx, req.URL, req.Payload, mock.Anything).
+			Return(nil, errors.New("connection refused")).
+			Once()
+
+		status, err := provider.Deliver(ctx, req)
+
+		assert.Zero(t, status)
+		assert.Equal(t, models.NETWORK_ERROR, err)
+	})
+}
+
+func TestSignWebhook(t *testing.T) {
+	payload := []byte(`{"event":"disbursement.success"}`)
+
+	signature := SignWebhook("whsec_test", 1700000000, payload)
+
+	assert.True(t, strings.HasPrefix(signature, "t=1700000000,v1="))
+	assert.Len(t, strings.TrimPrefix(signature, "t=1700000000,v1="), 64)
+	assert.Equal(t, signature, SignWebhook("whsec_test", 1700000000, payload))
+	assert.NotEqual(t, signature, SignWebhook("whsec_other", 1700000000, payload))
+	assert.NotEqual(t, signature, SignWebhook("whsec_test", 1700000001, payload))
+}
@@ -0,0 +1,161 @@
+// Package ratelimit keeps any one API client from flooding a route. Each
+// client gets a token bucket per route, refilled at the route's rate up to
+// its burst, and a request is admitted only while its bucket holds a token.
+// Limits are part of the service configuration so they can be tuned per
+// route and per client without a release, and changed on reload.
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+var (
+	ErrInvalidConfig = errors.New("invalid rate limit config")
+	ErrRateLimited   = errors.New("rate limit exceeded")
+)
+
+// Limit is a token bucket: Rate requests a second on average, with bursts
+// of up to Burst. A zero Rate means the route is not limited.
+type Limit struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
+
+// Config holds the limits by route, named by method and path template as
+// in "POST /api/v1/disburse". A client's own limit for a route replaces the
+// route's, and routes without a limit get Default.
+type Config struct {
+	Default Limit                       `yaml:"default"`
+	Routes  map[string]Limit            `yaml:"routes"`
+	Clients map[string]map[string]Limit `yaml:"clients"`
+}
+
+// DefaultConfig gives the limits used when none are configured. Creating
+// disbursements is held to a lower rate than the rest, as each one is queued
+// for payment. Every call returns new maps, so they can be decoded over.
+func DefaultConfig() Config {
+	return Config{
+		Default: Limit{Rate: 20, Burst: 40},
+		Routes: map[string]Limit{
+			"POST /api/v1/disburse":            {Rate: 5, Burst: 10},
+			"POST /api/v1/disburse/{id}/retry": {Rate: 5, Burst: 10},
+			"POST /api/v1/batch":               {Rate: 1, Burst: 2},
+		},
+	}
+}
+
+func (c Config) Validate() error {
+	limits := []Limit{c.Default}
+	for _, limit := range c.Routes {
+		limits = append(limits, limit)
+	}
+	for _, routes := range c.Clients {
+		for _, limit := range routes {
+			limits = append(limits, limit)
+		}
+	}
+	for _, limit := range limits {
+		if limit.Rate < 0 || limit.Burst < 0 || (limit.Rate > 0 && limit.Burst == 0) {
+			return fmt.Errorf("%w: rate %v with burst %d", ErrInvalidConfig, limit.Rate, limit.Burst)
+		}
+	}
+	return nil
+}
+
+type Limiter struct {
+	config  Config
+	mu      sync.Mutex
+	buckets map[bucketKey]*rate.Limiter
+}
+
+type bucketKey struct {
+	client string
+	route  string
+}
+
+func New(config Config) (*Limiter, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &Limiter{config: config, buckets: make(map[bucketKey]*rate.Limiter)}, nil
+}
+
+// Update applies new limits from now on. Buckets in use take the new rate
+// and burst but keep their tokens, so a reload neither refills nor drains
+// them; buckets whose route is no longer limited, or newly limited, start
+// over on their next request.
+func (l *Limiter) Update(config Config, now time.Time) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.config = config
+	for key, bucket := range l.buckets {
+		limit := l.limit(key.client, key.route)
+		if bucket == nil || limit.Rate == 0 {
+			delete(l.buckets, key)
+			continue
+		}
+		bucket.SetLimitAt(now, rate.Limit(limit.Rate))
+		bucket.SetBurstAt(now, limit.Burst)
+	}
+	return nil
+}
+
+// Allow takes a token from client's bucket for route. When the bucket is
+// empty it returns false and how long until a token is available.
+func (l *Limiter) Allow(client, route string, now time.Time) (bool, time.Duration) {
+	bucket := l.bucket(client, route)
+	if bucket == nil {
+		return true, 0
+	}
+	reservation := bucket.ReserveN(now, 1)
+	delay := reservation.DelayFrom(now)
+	if delay == 0 {
+		return true, 0
+	}
+	// The token is handed back so a client retrying early does not push
+	// its next slot further out.
+	reservation.CancelAt(now)
+	return false, delay
+}
+
+// RetryAfter rounds delay up to whole seconds, as the Retry-After header
+// takes, and never below one.
+func RetryAfter(delay time.Duration) int {
+	return max(1, int(math.Ceil(delay.Seconds())))
+}
+
+func (l *Limiter) bucket(client, route string) *rate.Limiter {
+	key := bucketKey{client: client, route: route}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if bucket, ok := l.buckets[key]; ok {
+		return bucket
+	}
+	limit := l.limit(client, route)
+	if limit.Rate == 0 {
+		l.buckets[key] = nil
+		return nil
+	}
+	bucket := rate.NewLimiter(rate.Limit(limit.Rate), limit.Burst)
+	l.buckets[key] = bucket
+	return bucket
+}
+
+func (l *Limiter) limit(client, route string) Limit {
+	if limit, ok := l.config.Clients[client][route]; ok {
+		return limit
+	}
+	if limit, ok := l.config.Routes[route]; ok {
+		return limit
+	}
+	return l.config.Default
+}
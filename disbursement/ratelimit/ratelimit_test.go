@@ -0,0 +1,154 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const disburse = "POST /api/v1/disburse"
+
+func TestLimiter_Allow(t *testing.T) {
+	now := time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC)
+	newLimiter := func(t *testing.T) *Limiter {
+		limiter, err := New(Config{
+			Default: Limit{Rate: 10, Burst: 10},
+			Routes:  map[string]Limit{disburse: {Rate: 1, Burst: 2}},
+			Clients: map[string]map[string]Limit{
+				"batch-uploader": {disburse: {Rate: 100, Burst: 100}},
+				"internal":       {disburse: {}},
+			},
+		})
+		assert.NoError(t, err)
+		return limiter
+	}
+
+	t.Run("admits a burst and then refuses until a token is refilled", func(t *testing.T) {
+		limiter := newLimiter(t)
+
+		ok1, _ := limiter.Allow("los", disburse, now)
+		ok2, _ := limiter.Allow("los", disburse, now)
+		ok3, delay := limiter.Allow("los", disburse, now)
+
+		assert.True(t, ok1)
+		assert.True(t, ok2)
+		assert.False(t, ok3)
+		assert.Equal(t, time.Second, delay)
+
+		ok4, _ := limiter.Allow("los", disburse, now.Add(time.Second))
+		assert.True(t, ok4)
+	})
+
+	t.Run("does not charge refused requests", func(t *testing.T) {
+		limiter := newLimiter(t)
+		limiter.Allow("los", disburse, now)
+		limiter.Allow("los", disburse, now)
+		for range 5 {
+			limiter.Allow("los", disburse, now.Add(500*time.Millisecond))
+		}
+
+		ok, _ := limiter.Allow("los", disburse, now.Add(time.Second))
+
+		assert.True(t, ok)
+	})
+
+	t.Run("keeps a bucket per client", func(t *testing.T) {
+		limiter := newLimiter(t)
+		limiter.Allow("los", disburse, now)
+		limiter.Allow("los", disburse, now)
+
+		ok, _ := limiter.Allow("collections", disburse, now)
+
+		assert.True(t, ok)
+	})
+
+	t.Run("keeps a bucket per route", func(t *testing.T) {
+		limiter := newLimiter(t)
+		limiter.Allow("los", disburse, now)
+		limiter.Allow("los", disburse, now)
+
+		ok, _ := limiter.Allow("los", "GET /api/v1/disburse/{id}", now)
+
+		assert.True(t, ok)
+	})
+
+	t.Run("applies a client's own limit", func(t *testing.T) {
+		limiter := newLimiter(t)
+		admitted := 0
+		for range 50 {
+			if ok, _ := limiter.Allow("batch-uploader", disburse, now); ok {
+				admitted++
+			}
+		}
+
+		assert.Equal(t, 50, admitted)
+	})
+
+	t.Run("does not limit a zero rate", func(t *testing.T) {
+		limiter := newLimiter(t)
+		for range 50 {
+			ok, _ := limiter.Allow("internal", disburse, now)
+			assert.True(t, ok)
+		}
+	})
+}
+
+func TestNew(t *testing.T) {
+	for name, config := range map[string]Config{
+		"rejects a negative rate":       {Default: Limit{Rate: -1, Burst: 1}},
+		"rejects a rate without burst":  {Routes: map[string]Limit{disburse: {Rate: 1}}},
+		"rejects a bad client override": {Clients: map[string]map[string]Limit{"los": {disburse: {Rate: 1, Burst: -1}}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := New(config)
+
+			assert.ErrorIs(t, err, ErrInvalidConfig)
+		})
+	}
+}
+
+func TestLimiter_Update(t *testing.T) {
+	now := time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC)
+
+	t.Run("applies the new limit to buckets in use", func(t *testing.T) {
+		limiter, _ := New(Config{Default: Limit{Rate: 1, Burst: 1}})
+		allowed, _ := limiter.Allow("los", disburse, now)
+		assert.True(t, allowed)
+
+		err := limiter.Update(Config{Default: Limit{Rate: 10, Burst: 1}}, now)
+
+		assert.NoError(t, err)
+		allowed, _ = limiter.Allow("los", disburse, now)
+		assert.False(t, allowed, "the bucket keeps its tokens")
+		allowed, _ = limiter.Allow("los", disburse, now.Add(100*time.Millisecond))
+		assert.True(t, allowed, "and refills at the new rate")
+	})
+
+	t.Run("limits a route that was not limited", func(t *testing.T) {
+		limiter, _ := New(Config{})
+		limiter.Allow("los", disburse, now)
+
+		assert.NoError(t, limiter.Update(Config{Default: Limit{Rate: 1, Burst: 1}}, now))
+
+		allowed, _ := limiter.Allow("los", disburse, now)
+		assert.True(t, allowed)
+		allowed, _ = limiter.Allow("los", disburse, now)
+		assert.False(t, allowed)
+	})
+
+	t.Run("keeps the running limits when the new ones are invalid", func(t *testing.T) {
+		limiter, _ := New(Config{Default: Limit{Rate: 1, Burst: 1}})
+
+		err := limiter.Update(Config{Default: Limit{Rate: -1}}, now)
+
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+		assert.Equal(t, Limit{Rate: 1, Burst: 1}, limiter.config.Default)
+	})
+}
+
+func TestRetryAfter(t *testing.T) {
+	assert.Equal(t, 1, RetryAfter(0))
+	assert.Equal(t, 1, RetryAfter(200*time.Millisecond))
+	assert.Equal(t, 3, RetryAfter(2100*time.Millisecond))
+}
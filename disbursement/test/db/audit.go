@@ -0,0 +1,30 @@
+package db_test
+
+import (
+	"context"
+	"loan-disbursement-service/db/schema"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock AuditRepository
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) Create(ctx context.Context, entry schema.AuditLog) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) ListByEntity(
+	ctx context.Context,
+	entityType string,
+	entityId string,
+) ([]schema.AuditLog, error) {
+	args := m.Called(ctx, entityType, entityId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]schema.AuditLog), args.Error(1)
+}
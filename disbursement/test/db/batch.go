@@ -0,0 +1,71 @@
+package db_test
+
+import (
+	"context"
+	"loan-disbursement-service/db/schema"
+	"loan-disbursement-service/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock BatchRepository
+type MockBatchRepository struct {
+	mock.Mock
+}
+
+func (m *MockBatchRepository) Create(
+	ctx context.Context,
+	batch schema.Batch,
+	rows []schema.BatchRow,
+) (*schema.Batch, error) {
+	args := m.Called(ctx, batch, rows)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*schema.Batch), args.Error(1)
+}
+
+func (m *MockBatchRepository) Get(ctx context.Context, id string) (*schema.Batch, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*schema.Batch), args.Error(1)
+}
+
+func (m *MockBatchRepository) Update(ctx context.Context, id string, fields map[string]any) error {
+	args := m.Called(ctx, id, fields)
+	return args.Error(0)
+}
+
+func (m *MockBatchRepository) ListByStatus(
+	ctx context.Context,
+	status []models.BatchStatus,
+) ([]schema.Batch, error) {
+	args := m.Called(ctx, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]schema.Batch), args.Error(1)
+}
+
+func (m *MockBatchRepository) ListRows(
+	ctx context.Context,
+	batchId string,
+) ([]schema.BatchRow, error) {
+	args := m.Called(ctx, batchId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]schema.BatchRow), args.Error(1)
+}
+
+func (m *MockBatchRepository) UpdateRow(
+	ctx context.Context,
+	batchId string,
+	rowNumber int,
+	fields map[string]any,
+) error {
+	args := m.Called(ctx, batchId, rowNumber, fields)
+	return args.Error(0)
+}
@@ -2,9 +2,11 @@ package db_test
 
 import (
 	"context"
+	"loan-disbursement-service/db/daos"
 	"loan-disbursement-service/db/schema"
 
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 // Mock BeneficiaryRepository
@@ -14,9 +16,9 @@ type MockBeneficiaryRepository struct {
 
 func (m *MockBeneficiaryRepository) Create(
 	ctx context.Context,
-	id, account, ifsc, bank string,
+	id, name, account, ifsc, bank string,
 ) (*schema.Beneficiary, error) {
-	args := m.Called(ctx, id, account, ifsc, bank)
+	args := m.Called(ctx, id, name, account, ifsc, bank)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -64,3 +66,31 @@ func (m *MockBeneficiaryRepository) Update(
 	args := m.Called(ctx, id, fields)
 	return args.Error(0)
 }
+
+func (m *MockBeneficiaryRepository) List(ctx context.Context) ([]schema.Beneficiary, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]schema.Beneficiary), args.Error(1)
+}
+
+func (m *MockBeneficiaryRepository) ListPendingPennyDrop(
+	ctx context.Context,
+	limit int,
+) ([]schema.Beneficiary, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]schema.Beneficiary), args.Error(1)
+}
+
+func (m *MockBeneficiaryRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockBeneficiaryRepository) WithTx(tx *gorm.DB) daos.BeneficiaryRepository {
+	return m
+}
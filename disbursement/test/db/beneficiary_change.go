@@ -0,0 +1,38 @@
+package db_test
+
+import (
+	"context"
+	"loan-disbursement-service/db/daos"
+	"loan-disbursement-service/db/schema"
+
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// Mock BeneficiaryChangeRepository
+type MockBeneficiaryChangeRepository struct {
+	mock.Mock
+}
+
+func (m *MockBeneficiaryChangeRepository) Create(
+	ctx context.Context,
+	change schema.BeneficiaryChange,
+) error {
+	args := m.Called(ctx, change)
+	return args.Error(0)
+}
+
+func (m *MockBeneficiaryChangeRepository) ListByLoan(
+	ctx context.Context,
+	loanId string,
+) ([]schema.BeneficiaryChange, error) {
+	args := m.Called(ctx, loanId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]schema.BeneficiaryChange), args.Error(1)
+}
+
+func (m *MockBeneficiaryChangeRepository) WithTx(tx *gorm.DB) daos.BeneficiaryChangeRepository {
+	return m
+}
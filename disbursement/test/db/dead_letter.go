@@ -0,0 +1,77 @@
+package db_test
+
+import (
+	"context"
+	"loan-disbursement-service/db/daos"
+	"loan-disbursement-service/db/schema"
+	"loan-disbursement-service/models"
+
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// Mock DeadLetterRepository
+type MockDeadLetterRepository struct {
+	mock.Mock
+}
+
+func (m *MockDeadLetterRepository) Record(ctx context.Context, entry schema.DeadLetter) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockDeadLetterRepository) Get(
+	ctx context.Context,
+	disbursementId string,
+) (*schema.DeadLetter, error) {
+	args := m.Called(ctx, disbursementId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*schema.DeadLetter), args.Error(1)
+}
+
+func (m *MockDeadLetterRepository) List(
+	ctx context.Context,
+	filter models.DeadLetterFilter,
+) ([]schema.DeadLetter, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]schema.DeadLetter), args.Error(1)
+}
+
+func (m *MockDeadLetterRepository) ListUnnotified(
+	ctx context.Context,
+	limit int,
+) ([]schema.DeadLetter, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]schema.DeadLetter), args.Error(1)
+}
+
+func (m *MockDeadLetterRepository) Update(
+	ctx context.Context,
+	disbursementId string,
+	fields map[string]any,
+) error {
+	args := m.Called(ctx, disbursementId, fields)
+	return args.Error(0)
+}
+
+func (m *MockDeadLetterRepository) UpdateIfStatus(
+	ctx context.Context,
+	disbursementId string,
+	status models.DeadLetterStatus,
+	fields map[string]any,
+) (bool, error) {
+	args := m.Called(ctx, disbursementId, status, fields)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDeadLetterRepository) WithTx(tx *gorm.DB) daos.DeadLetterRepository {
+	return m
+}
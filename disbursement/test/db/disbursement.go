@@ -2,10 +2,13 @@ package db_test
 
 import (
 	"context"
+	"loan-disbursement-service/db/daos"
 	"loan-disbursement-service/db/schema"
 	"loan-disbursement-service/models"
+	"time"
 
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 // Mock DisbursementRepository
@@ -15,12 +18,9 @@ type MockDisbursementRepository struct {
 
 func (m *MockDisbursementRepository) Create(
 	ctx context.Context,
-	id, loanId string,
-	channel models.PaymentChannel,
-	status models.DisbursementStatus,
-	amount float64,
+	disbursement schema.Disbursement,
 ) (*schema.Disbursement, error) {
-	args := m.Called(ctx, id, loanId, channel, status, amount)
+	args := m.Called(ctx, disbursement)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -52,6 +52,9 @@ func (m *MockDisbursementRepository) GetByLoanId(
 	loanId string,
 ) (*schema.Disbursement, error) {
 	args := m.Called(ctx, loanId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
 	return args.Get(0).(*schema.Disbursement), args.Error(1)
 }
 
@@ -75,3 +78,61 @@ func (m *MockDisbursementRepository) ListByLoan(
 	args := m.Called(ctx, loanId)
 	return args.Get(0).([]schema.Disbursement), args.Error(1)
 }
+
+func (m *MockDisbursementRepository) ListByIds(
+	ctx context.Context,
+	ids []string,
+) ([]schema.Disbursement, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]schema.Disbursement), args.Error(1)
+}
+
+func (m *MockDisbursementRepository) ListDue(
+	ctx context.Context,
+	dueBy time.Time,
+	offset, limit int,
+) ([]schema.Disbursement, error) {
+	args := m.Called(ctx, dueBy, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]schema.Disbursement), args.Error(1)
+}
+
+func (m *MockDisbursementRepository) ListSchedulePending(
+	ctx context.Context,
+	limit int,
+) ([]schema.Disbursement, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]schema.Disbursement), args.Error(1)
+}
+
+func (m *MockDisbursementRepository) UpdateIfStatus(
+	ctx context.Context,
+	id string,
+	status models.DisbursementStatus,
+	fields map[string]any,
+) (bool, error) {
+	args := m.Called(ctx, id, status, fields)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDisbursementRepository) CountByStatus(
+	ctx context.Context,
+) (map[models.DisbursementStatus]int64, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[models.DisbursementStatus]int64), args.Error(1)
+}
+
+func (m *MockDisbursementRepository) WithTx(tx *gorm.DB) daos.DisbursementRepository {
+	return m
+}
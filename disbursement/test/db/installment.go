@@ -0,0 +1,38 @@
+package db_test
+
+import (
+	"context"
+	"loan-disbursement-service/db/daos"
+	"loan-disbursement-service/db/schema"
+
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// Mock InstallmentRepository
+type MockInstallmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockInstallmentRepository) CreateBatch(
+	ctx context.Context,
+	installments []schema.Installment,
+) error {
+	args := m.Called(ctx, installments)
+	return args.Error(0)
+}
+
+func (m *MockInstallmentRepository) ListByLoan(
+	ctx context.Context,
+	loanId string,
+) ([]schema.Installment, error) {
+	args := m.Called(ctx, loanId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]schema.Installment), args.Error(1)
+}
+
+func (m *MockInstallmentRepository) WithTx(tx *gorm.DB) daos.InstallmentRepository {
+	return m
+}
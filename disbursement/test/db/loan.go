@@ -2,9 +2,12 @@ package db_test
 
 import (
 	"context"
+	"loan-disbursement-service/db/daos"
 	"loan-disbursement-service/db/schema"
+	"loan-disbursement-service/models"
 
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 // Mock LoanRepository
@@ -14,10 +17,9 @@ type MockLoanRepository struct {
 
 func (m *MockLoanRepository) Create(
 	ctx context.Context,
-	id string,
-	amount float64,
+	loan schema.Loan,
 ) (*schema.Loan, error) {
-	args := m.Called(ctx, id, amount)
+	args := m.Called(ctx, loan)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -36,6 +38,16 @@ func (m *MockLoanRepository) Update(
 	return args.Get(0).(*schema.Loan), args.Error(1)
 }
 
+func (m *MockLoanRepository) UpdateIfStatus(
+	ctx context.Context,
+	id string,
+	status models.LoanStatus,
+	fields map[string]any,
+) (bool, error) {
+	args := m.Called(ctx, id, status, fields)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockLoanRepository) Get(ctx context.Context, id string) (*schema.Loan, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -48,3 +60,15 @@ func (m *MockLoanRepository) List(ctx context.Context) ([]schema.Loan, error) {
 	args := m.Called(ctx)
 	return args.Get(0).([]schema.Loan), args.Error(1)
 }
+
+func (m *MockLoanRepository) ListByBeneficiary(
+	ctx context.Context,
+	beneficiaryId string,
+) ([]schema.Loan, error) {
+	args := m.Called(ctx, beneficiaryId)
+	return args.Get(0).([]schema.Loan), args.Error(1)
+}
+
+func (m *MockLoanRepository) WithTx(tx *gorm.DB) daos.LoanRepository {
+	return m
+}
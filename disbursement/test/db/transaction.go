@@ -2,11 +2,13 @@ package db_test
 
 import (
 	"context"
+	"loan-disbursement-service/db/daos"
 	"loan-disbursement-service/db/schema"
 	"loan-disbursement-service/models"
 	"time"
 
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 // Mock TransactionRepository
@@ -72,3 +74,7 @@ func (m *MockTransactionRepository) ListByDate(
 	args := m.Called(ctx, date, status)
 	return args.Get(0).([]schema.Transaction), args.Error(1)
 }
+
+func (m *MockTransactionRepository) WithTx(tx *gorm.DB) daos.TransactionRepository {
+	return m
+}
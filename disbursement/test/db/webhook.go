@@ -0,0 +1,142 @@
+package db_test
+
+import (
+	"context"
+	"loan-disbursement-service/db/daos"
+	"loan-disbursement-service/db/schema"
+	"loan-disbursement-service/models"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// Mock WebhookSubscriptionRepository
+type MockWebhookSubscriptionRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookSubscriptionRepository) Create(
+	ctx context.Context,
+	subscription schema.WebhookSubscription,
+) error {
+	args := m.Called(ctx, subscription)
+	return args.Error(0)
+}
+
+func (m *MockWebhookSubscriptionRepository) Get(
+	ctx context.Context,
+	id string,
+) (*schema.WebhookSubscription, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*schema.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookSubscriptionRepository) ListByClient(
+	ctx context.Context,
+	clientId string,
+) ([]schema.WebhookSubscription, error) {
+	args := m.Called(ctx, clientId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]schema.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookSubscriptionRepository) ListActiveByClient(
+	ctx context.Context,
+	clientId string,
+) ([]schema.WebhookSubscription, error) {
+	args := m.Called(ctx, clientId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]schema.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookSubscriptionRepository) Update(
+	ctx context.Context,
+	id string,
+	fields map[string]any,
+) error {
+	args := m.Called(ctx, id, fields)
+	return args.Error(0)
+}
+
+// Mock WebhookDeliveryRepository
+type MockWebhookDeliveryRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookDeliveryRepository) Create(
+	ctx context.Context,
+	deliveries []schema.WebhookDelivery,
+) error {
+	args := m.Called(ctx, deliveries)
+	return args.Error(0)
+}
+
+func (m *MockWebhookDeliveryRepository) Get(
+	ctx context.Context,
+	id string,
+) (*schema.WebhookDelivery, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*schema.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookDeliveryRepository) ListBySubscription(
+	ctx context.Context,
+	subscriptionId string,
+	filter models.WebhookDeliveryFilter,
+) ([]schema.WebhookDelivery, error) {
+	args := m.Called(ctx, subscriptionId, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]schema.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookDeliveryRepository) ListDue(
+	ctx context.Context,
+	now time.Time,
+	limit int,
+) ([]schema.WebhookDelivery, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]schema.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookDeliveryRepository) Update(
+	ctx context.Context,
+	id string,
+	fields map[string]any,
+) error {
+	args := m.Called(ctx, id, fields)
+	return args.Error(0)
+}
+
+func (m *MockWebhookDeliveryRepository) UpdateIfStatus(
+	ctx context.Context,
+	id string,
+	status models.WebhookDeliveryStatus,
+	fields map[string]any,
+) (bool, error) {
+	args := m.Called(ctx, id, status, fields)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWebhookSubscriptionRepository) WithTx(tx *gorm.DB) daos.WebhookSubscriptionRepository {
+	return m
+}
+
+func (m *MockWebhookDeliveryRepository) WithTx(tx *gorm.DB) daos.WebhookDeliveryRepository {
+	return m
+}
@@ -35,3 +35,11 @@ func (m *MockGatewayProvider) IsActive(
 	args := m.Called(ctx, channel)
 	return args.Bool(0), args.Error(1)
 }
+
+func (m *MockGatewayProvider) VerifyAccount(
+	ctx context.Context,
+	req models.AccountVerificationRequest,
+) (models.AccountVerificationResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(models.AccountVerificationResponse), args.Error(1)
+}
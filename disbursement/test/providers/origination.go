@@ -0,0 +1,20 @@
+package provider_test
+
+import (
+	"context"
+	"loan-disbursement-service/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOriginationProvider struct {
+	mock.Mock
+}
+
+func (m *MockOriginationProvider) NotifyDeadLetter(
+	ctx context.Context,
+	notification models.DeadLetterNotification,
+) error {
+	args := m.Called(ctx, notification)
+	return args.Error(0)
+}
@@ -0,0 +1,17 @@
+package provider_test
+
+import (
+	"context"
+	"loan-disbursement-service/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWebhookProvider struct {
+	mock.Mock
+}
+
+func (m *MockWebhookProvider) Deliver(ctx context.Context, req models.WebhookRequest) (int, error) {
+	args := m.Called(ctx, req)
+	return args.Int(0), args.Error(1)
+}
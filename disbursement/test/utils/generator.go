@@ -36,3 +36,38 @@ func (m *MockIdGenerator) GenerateReconciliationId() string {
 	args := m.Called()
 	return args.String(0)
 }
+
+func (m *MockIdGenerator) GenerateBatchId() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockIdGenerator) GenerateAuditId() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockIdGenerator) GenerateBeneficiaryChangeId() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockIdGenerator) GenerateWebhookId() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockIdGenerator) GenerateWebhookDeliveryId() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockIdGenerator) GenerateEventId() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockIdGenerator) GenerateWebhookSecret() string {
+	args := m.Called()
+	return args.String(0)
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 
 	"github.com/google/uuid"
@@ -13,6 +15,13 @@ type IdGenerator interface {
 	GenerateBeneficiaryId() string
 	GenerateDisbursementId() string
 	GenerateReconciliationId() string
+	GenerateBatchId() string
+	GenerateAuditId() string
+	GenerateBeneficiaryChangeId() string
+	GenerateWebhookId() string
+	GenerateWebhookDeliveryId() string
+	GenerateEventId() string
+	GenerateWebhookSecret() string
 }
 
 type IdGeneratorImpl struct{}
@@ -44,3 +53,35 @@ func (g *IdGeneratorImpl) GenerateReferenceId() string {
 func (g *IdGeneratorImpl) GenerateReconciliationId() string {
 	return fmt.Sprintf("RECON-%s", uuid.New().String()[:12])
 }
+
+func (g *IdGeneratorImpl) GenerateBatchId() string {
+	return fmt.Sprintf("BATCH-%s", uuid.New().String()[:12])
+}
+
+func (g *IdGeneratorImpl) GenerateAuditId() string {
+	return fmt.Sprintf("AUD-%s", uuid.New().String()[:12])
+}
+
+func (g *IdGeneratorImpl) GenerateBeneficiaryChangeId() string {
+	return fmt.Sprintf("BCH-%s", uuid.New().String()[:12])
+}
+
+func (g *IdGeneratorImpl) GenerateWebhookId() string {
+	return fmt.Sprintf("WHK-%s", uuid.New().String()[:12])
+}
+
+func (g *IdGeneratorImpl) GenerateWebhookDeliveryId() string {
+	return fmt.Sprintf("WHD-%s", uuid.New().String()[:12])
+}
+
+func (g *IdGeneratorImpl) GenerateEventId() string {
+	return fmt.Sprintf("EVT-%s", uuid.New().String()[:12])
+}
+
+// GenerateWebhookSecret returns a random key for signing a subscription's
+// payloads.
+func (g *IdGeneratorImpl) GenerateWebhookSecret() string {
+	key := make([]byte, 32)
+	rand.Read(key)
+	return fmt.Sprintf("whsec_%s", hex.EncodeToString(key))
+}
@@ -0,0 +1,35 @@
+package worker
+
+import (
+	"context"
+	"shared/logging"
+
+	"github.com/rs/zerolog/log"
+)
+
+func (w *Worker) ProcessBatch(ctx context.Context, batchId string) {
+	if err := w.batchService.Process(ctx, batchId); err != nil {
+		log.Ctx(logging.With(ctx, logging.Fields{BatchId: batchId})).Error().Err(err).
+			Msg("failed to process batch")
+	}
+}
+
+// ResumeBatches processes batches left unfinished by a previous run, or not
+// queued because the batch channel was full, since their ids only lived on
+// the in-memory channel. It takes up to worker.batch.batch_size at a time,
+// oldest first.
+func (w *Worker) ResumeBatches(ctx context.Context) {
+	batchIds, err := w.batchService.ListUnfinished(ctx)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to list unfinished batches")
+		return
+	}
+	if size := w.jobs().Batch.BatchSize; len(batchIds) > size {
+		batchIds = batchIds[:size]
+	}
+	for _, batchId := range batchIds {
+		log.Ctx(logging.With(ctx, logging.Fields{BatchId: batchId})).Info().
+			Msg("Resuming disbursement batch")
+		w.ProcessBatch(ctx, batchId)
+	}
+}
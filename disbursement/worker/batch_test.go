@@ -0,0 +1,109 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"loan-disbursement-service/config"
+	"loan-disbursement-service/models"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBatchService struct {
+	mock.Mock
+}
+
+func (m *MockBatchService) Create(
+	ctx context.Context,
+	clientId, source string,
+	requests []models.DisburseRequest,
+) (*models.BatchResponse, error) {
+	args := m.Called(ctx, clientId, source, requests)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BatchResponse), args.Error(1)
+}
+
+func (m *MockBatchService) CreateFromCSV(
+	ctx context.Context,
+	clientId, source string,
+	file io.Reader,
+) (*models.BatchResponse, error) {
+	args := m.Called(ctx, clientId, source, file)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BatchResponse), args.Error(1)
+}
+
+func (m *MockBatchService) Process(ctx context.Context, batchId string) error {
+	args := m.Called(ctx, batchId)
+	return args.Error(0)
+}
+
+func (m *MockBatchService) ListUnfinished(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockBatchService) Get(ctx context.Context, clientId, batchId string) (*models.BatchResponse, error) {
+	args := m.Called(ctx, clientId, batchId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BatchResponse), args.Error(1)
+}
+
+func (m *MockBatchService) WriteResults(ctx context.Context, clientId, batchId string, w io.Writer) error {
+	args := m.Called(ctx, clientId, batchId, w)
+	return args.Error(0)
+}
+
+func TestWorker_ResumeBatches(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("processes every unfinished batch", func(t *testing.T) {
+		mockBatchService := new(MockBatchService)
+		worker := Worker{batchService: mockBatchService}
+
+		mockBatchService.On("ListUnfinished", ctx).Return([]string{"BATCH-1", "BATCH-2"}, nil).Once()
+		mockBatchService.On("Process", ctx, "BATCH-1").Return(errors.New("database error")).Once()
+		mockBatchService.On("Process", ctx, "BATCH-2").Return(nil).Once()
+
+		worker.ResumeBatches(ctx)
+
+		mockBatchService.AssertExpectations(t)
+	})
+
+	t.Run("takes at most a batch size of batches", func(t *testing.T) {
+		mockBatchService := new(MockBatchService)
+		settings := config.Default()
+		settings.Worker.Batch.BatchSize = 1
+		worker := Worker{batchService: mockBatchService, settings: config.NewStore("", settings)}
+
+		mockBatchService.On("ListUnfinished", ctx).Return([]string{"BATCH-1", "BATCH-2"}, nil).Once()
+		mockBatchService.On("Process", ctx, "BATCH-1").Return(nil).Once()
+
+		worker.ResumeBatches(ctx)
+
+		mockBatchService.AssertExpectations(t)
+		mockBatchService.AssertNotCalled(t, "Process", ctx, "BATCH-2")
+	})
+
+	t.Run("returns early when batches cannot be listed", func(t *testing.T) {
+		mockBatchService := new(MockBatchService)
+		worker := Worker{batchService: mockBatchService}
+
+		mockBatchService.On("ListUnfinished", ctx).Return(nil, errors.New("database error")).Once()
+
+		worker.ResumeBatches(ctx)
+
+		mockBatchService.AssertNotCalled(t, "Process")
+	})
+}
@@ -0,0 +1,21 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ProcessPendingPennyDrops penny drops the beneficiaries registered with a
+// disbursement request, which does not wait for the gateway, one batch per
+// tick.
+func (w *Worker) ProcessPendingPennyDrops(ctx context.Context) {
+	dropped, err := w.beneficiary.PennyDropPending(ctx, w.jobs().PennyDrop.BatchSize)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to list beneficiaries pending penny drop")
+		return
+	}
+	if dropped > 0 {
+		log.Ctx(ctx).Info().Int("penny_dropped", dropped).Msg("Pending penny drops recorded")
+	}
+}
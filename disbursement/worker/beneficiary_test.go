@@ -0,0 +1,107 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"loan-disbursement-service/models"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBeneficiaryService struct {
+	mock.Mock
+}
+
+func (m *MockBeneficiaryService) Create(
+	ctx context.Context,
+	req models.BeneficiaryRequest,
+) (*models.BeneficiaryResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*models.BeneficiaryResponse), args.Error(1)
+}
+
+func (m *MockBeneficiaryService) Register(
+	ctx context.Context,
+	req models.BeneficiaryRequest,
+) (*models.BeneficiaryResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*models.BeneficiaryResponse), args.Error(1)
+}
+
+func (m *MockBeneficiaryService) PennyDropPending(ctx context.Context, limit int) (int, error) {
+	args := m.Called(ctx, limit)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockBeneficiaryService) Update(
+	ctx context.Context,
+	beneficiaryId string,
+	req models.BeneficiaryRequest,
+) (*models.BeneficiaryResponse, error) {
+	args := m.Called(ctx, beneficiaryId, req)
+	return args.Get(0).(*models.BeneficiaryResponse), args.Error(1)
+}
+
+func (m *MockBeneficiaryService) List(ctx context.Context) ([]models.BeneficiaryResponse, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.BeneficiaryResponse), args.Error(1)
+}
+
+func (m *MockBeneficiaryService) Get(
+	ctx context.Context,
+	beneficiaryId string,
+) (*models.BeneficiaryResponse, error) {
+	args := m.Called(ctx, beneficiaryId)
+	return args.Get(0).(*models.BeneficiaryResponse), args.Error(1)
+}
+
+func (m *MockBeneficiaryService) Delete(ctx context.Context, beneficiaryId string) error {
+	args := m.Called(ctx, beneficiaryId)
+	return args.Error(0)
+}
+
+func (m *MockBeneficiaryService) Verify(
+	ctx context.Context,
+	beneficiaryId string,
+	req models.BeneficiaryVerificationRequest,
+) (*models.BeneficiaryResponse, error) {
+	args := m.Called(ctx, beneficiaryId, req)
+	return args.Get(0).(*models.BeneficiaryResponse), args.Error(1)
+}
+
+func (m *MockBeneficiaryService) Override(
+	ctx context.Context,
+	operator models.Operator,
+	beneficiaryId string,
+	req models.BeneficiaryOverrideRequest,
+) (*models.BeneficiaryResponse, error) {
+	args := m.Called(ctx, operator, beneficiaryId, req)
+	return args.Get(0).(*models.BeneficiaryResponse), args.Error(1)
+}
+
+func TestWorker_ProcessPendingPennyDrops(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("penny drops one batch", func(t *testing.T) {
+		mockBeneficiary := new(MockBeneficiaryService)
+		worker := Worker{beneficiary: mockBeneficiary, settings: batchSize(10)}
+
+		mockBeneficiary.On("PennyDropPending", ctx, 10).Return(3, nil).Once()
+
+		worker.ProcessPendingPennyDrops(ctx)
+
+		mockBeneficiary.AssertExpectations(t)
+	})
+
+	t.Run("logs and returns when listing fails", func(t *testing.T) {
+		mockBeneficiary := new(MockBeneficiaryService)
+		worker := Worker{beneficiary: mockBeneficiary, settings: batchSize(10)}
+
+		mockBeneficiary.On("PennyDropPending", ctx, 10).Return(0, errors.New("db down")).Once()
+
+		worker.ProcessPendingPennyDrops(ctx)
+
+		mockBeneficiary.AssertExpectations(t)
+	})
+}
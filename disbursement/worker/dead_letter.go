@@ -0,0 +1,20 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ProcessDeadLetterNotifications delivers one batch per tick, so an
+// origination system that is down is not hammered with the whole backlog.
+func (w *Worker) ProcessDeadLetterNotifications(ctx context.Context) {
+	notified, err := w.deadLetter.NotifyPending(ctx, w.jobs().DeadLetter.BatchSize)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to notify dead letters")
+		return
+	}
+	if notified > 0 {
+		log.Ctx(ctx).Info().Int("notified", notified).Msg("Dead letter notifier")
+	}
+}
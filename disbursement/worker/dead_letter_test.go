@@ -0,0 +1,81 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"loan-disbursement-service/models"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDeadLetterService struct {
+	mock.Mock
+}
+
+func (m *MockDeadLetterService) List(
+	ctx context.Context,
+	filter models.DeadLetterFilter,
+) ([]models.DeadLetter, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]models.DeadLetter), args.Error(1)
+}
+
+func (m *MockDeadLetterService) Export(
+	ctx context.Context,
+	filter models.DeadLetterFilter,
+	w io.Writer,
+) error {
+	args := m.Called(ctx, filter, w)
+	return args.Error(0)
+}
+
+func (m *MockDeadLetterService) Requeue(
+	ctx context.Context,
+	operator models.Operator,
+	req models.DeadLetterBulkRequest,
+) (*models.DeadLetterBulkResponse, error) {
+	args := m.Called(ctx, operator, req)
+	return args.Get(0).(*models.DeadLetterBulkResponse), args.Error(1)
+}
+
+func (m *MockDeadLetterService) Close(
+	ctx context.Context,
+	operator models.Operator,
+	req models.DeadLetterBulkRequest,
+) (*models.DeadLetterBulkResponse, error) {
+	args := m.Called(ctx, operator, req)
+	return args.Get(0).(*models.DeadLetterBulkResponse), args.Error(1)
+}
+
+func (m *MockDeadLetterService) NotifyPending(ctx context.Context, limit int) (int, error) {
+	args := m.Called(ctx, limit)
+	return args.Int(0), args.Error(1)
+}
+
+func TestWorker_ProcessDeadLetterNotifications(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("notifies one batch", func(t *testing.T) {
+		mockDeadLetter := new(MockDeadLetterService)
+		worker := Worker{deadLetter: mockDeadLetter, settings: batchSize(20)}
+
+		mockDeadLetter.On("NotifyPending", ctx, 20).Return(3, nil).Once()
+
+		worker.ProcessDeadLetterNotifications(ctx)
+
+		mockDeadLetter.AssertExpectations(t)
+	})
+
+	t.Run("logs and returns when listing fails", func(t *testing.T) {
+		mockDeadLetter := new(MockDeadLetterService)
+		worker := Worker{deadLetter: mockDeadLetter, settings: batchSize(20)}
+
+		mockDeadLetter.On("NotifyPending", ctx, 20).Return(0, errors.New("db down")).Once()
+
+		worker.ProcessDeadLetterNotifications(ctx)
+
+		mockDeadLetter.AssertExpectations(t)
+	})
+}
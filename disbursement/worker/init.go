@@ -3,7 +3,10 @@ package worker
 import (
 	"context"
 	"loan-disbursement-service/api/services"
+	"loan-disbursement-service/config"
 	"loan-disbursement-service/db/daos"
+	"shared/calendar"
+	"shared/logging"
 	"sync"
 	"time"
 
@@ -11,90 +14,254 @@ import (
 )
 
 type Worker struct {
-	disbursement      daos.DisbursementRepository
-	paymentService    services.PaymentService
-	neftBatchSize     int
-	retryBatchSize    int
-	neftPollInterval  time.Duration
-	retryPollInterval time.Duration
-	paymentChan       chan string
-	stopChan          chan struct{}
-	stopOnce          sync.Once
+	disbursement   daos.DisbursementRepository
+	paymentService services.PaymentService
+	beneficiary    services.BeneficiaryService
+	batchService   services.BatchService
+	scheduler      services.SchedulerService
+	deadLetter     services.DeadLetterService
+	webhook        services.WebhookService
+	calendar       *calendar.Calendar
+	settings       *config.Store
+	paymentChan    chan string
+	batchChan      chan string
+	stopChan       chan struct{}
+	stopOnce       sync.Once
 }
 
 func NewWorker(
 	disbursement daos.DisbursementRepository,
 	paymentService services.PaymentService,
+	beneficiary services.BeneficiaryService,
+	batchService services.BatchService,
+	scheduler services.SchedulerService,
+	deadLetter services.DeadLetterService,
+	webhook services.WebhookService,
+	calendar *calendar.Calendar,
+	settings *config.Store,
 	paymentChan chan string,
+	batchChan chan string,
 ) *Worker {
 	return &Worker{
-		neftPollInterval:  60 * time.Second,
-		retryPollInterval: 15 * time.Second,
-		paymentChan:       paymentChan,
-		stopChan:          make(chan struct{}),
-		disbursement:      disbursement,
-		paymentService:    paymentService,
-		retryBatchSize:    10,
-		neftBatchSize:     10,
-		stopOnce:          sync.Once{},
+		paymentChan:    paymentChan,
+		batchChan:      batchChan,
+		stopChan:       make(chan struct{}),
+		disbursement:   disbursement,
+		paymentService: paymentService,
+		beneficiary:    beneficiary,
+		batchService:   batchService,
+		scheduler:      scheduler,
+		deadLetter:     deadLetter,
+		webhook:        webhook,
+		calendar:       calendar,
+		settings:       settings,
+		stopOnce:       sync.Once{},
 	}
 }
 
+// jobs is read again on every tick, so batch sizes from a reloaded
+// configuration apply from the next one. Tickers are reset as soon as the
+// configuration changes, so a new interval does not wait out the old one;
+// each worker takes the change signal before reading the interval it resets
+// to, so a reload in between is not missed.
+func (w *Worker) jobs() config.Worker {
+	return w.settings.Get().Worker
+}
+
 func (w *Worker) StartPaymentDisbursement(ctx context.Context) {
-	log.Info().Msg("Starting payment disbursement worker")
+	log.Ctx(ctx).Info().Msg("Starting payment disbursement worker")
 	for {
 		select {
 		case <-ctx.Done():
 			log.Ctx(ctx).Info().Msg("Worker stopped by context")
-			close(w.stopChan)
 			return
 		case <-w.stopChan:
 			log.Ctx(ctx).Info().Msg("Worker stopped by signal")
 			return
 		case disbursementId := <-w.paymentChan:
-			log.Ctx(ctx).Info().Msgf("Processing payment for disbursement: %s", disbursementId)
+			log.Ctx(logging.With(ctx, logging.Fields{DisbursementId: disbursementId})).Info().
+				Msg("Processing payment for disbursement")
 			w.ProcessPaymentBatch(ctx, disbursementId)
 		}
 	}
 }
 
+func (w *Worker) StartBatchDisbursement(ctx context.Context) {
+	log.Ctx(ctx).Info().Msg("Starting batch disbursement worker")
+	w.ResumeBatches(ctx)
+	changed := w.settings.Changed()
+	ticker := time.NewTicker(w.jobs().Batch.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Ctx(ctx).Info().Msg("Worker stopped by context")
+			return
+		case <-w.stopChan:
+			log.Ctx(ctx).Info().Msg("Worker stopped by signal")
+			return
+		case batchId := <-w.batchChan:
+			log.Ctx(logging.With(ctx, logging.Fields{BatchId: batchId})).Info().
+				Msg("Processing disbursement batch")
+			w.ProcessBatch(ctx, batchId)
+		case <-changed:
+			changed = w.settings.Changed()
+			ticker.Reset(w.jobs().Batch.Interval)
+		case <-ticker.C:
+			w.ResumeBatches(ctx)
+		}
+	}
+}
+
 func (w *Worker) StartRetryDisbursement(ctx context.Context) {
-	log.Info().Msg("Starting retry disbursement worker")
-	ticker := time.NewTicker(w.retryPollInterval)
+	log.Ctx(ctx).Info().Msg("Starting retry disbursement worker")
+	changed := w.settings.Changed()
+	ticker := time.NewTicker(w.jobs().Retry.Interval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			log.Ctx(ctx).Info().Msg("Worker stopped by context")
-			close(w.stopChan)
 			return
 		case <-w.stopChan:
 			log.Ctx(ctx).Info().Msg("Worker stopped by signal")
 			return
+		case <-changed:
+			changed = w.settings.Changed()
+			ticker.Reset(w.jobs().Retry.Interval)
 		case <-ticker.C:
 			log.Ctx(ctx).Info().Msg("Processing retry disbursement")
 			w.ProcessRetryBatch(ctx)
+			w.ProcessPendingSchedules(ctx)
+		}
+	}
+}
+
+func (w *Worker) StartScheduledDisbursement(ctx context.Context) {
+	log.Ctx(ctx).Info().Msg("Starting scheduled disbursement worker")
+	changed := w.settings.Changed()
+	ticker := time.NewTicker(w.jobs().Scheduled.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Ctx(ctx).Info().Msg("Worker stopped by context")
+			return
+		case <-w.stopChan:
+			log.Ctx(ctx).Info().Msg("Worker stopped by signal")
+			return
+		case <-changed:
+			changed = w.settings.Changed()
+			ticker.Reset(w.jobs().Scheduled.Interval)
+		case <-ticker.C:
+			log.Ctx(ctx).Info().Msg("Releasing scheduled disbursements")
+			w.ProcessScheduledBatch(ctx, time.Now())
+		}
+	}
+}
+
+// StartDeadLetterNotifier delivers dead letters to the loan origination
+// system. Undelivered ones are picked up again on the next tick.
+func (w *Worker) StartDeadLetterNotifier(ctx context.Context) {
+	log.Ctx(ctx).Info().Msg("Starting dead letter notifier")
+	changed := w.settings.Changed()
+	ticker := time.NewTicker(w.jobs().DeadLetter.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Ctx(ctx).Info().Msg("Worker stopped by context")
+			return
+		case <-w.stopChan:
+			log.Ctx(ctx).Info().Msg("Worker stopped by signal")
+			return
+		case <-changed:
+			changed = w.settings.Changed()
+			ticker.Reset(w.jobs().DeadLetter.Interval)
+		case <-ticker.C:
+			w.ProcessDeadLetterNotifications(ctx)
+		}
+	}
+}
+
+// StartWebhookDelivery posts queued webhook deliveries. Deliveries the
+// subscriber did not accept are rescheduled with backoff by the webhook
+// service and picked up on a later tick.
+func (w *Worker) StartWebhookDelivery(ctx context.Context) {
+	log.Ctx(ctx).Info().Msg("Starting webhook delivery worker")
+	changed := w.settings.Changed()
+	ticker := time.NewTicker(w.jobs().Webhook.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Ctx(ctx).Info().Msg("Worker stopped by context")
+			return
+		case <-w.stopChan:
+			log.Ctx(ctx).Info().Msg("Worker stopped by signal")
+			return
+		case <-changed:
+			changed = w.settings.Changed()
+			ticker.Reset(w.jobs().Webhook.Interval)
+		case <-ticker.C:
+			w.ProcessWebhookDeliveries(ctx)
+		}
+	}
+}
+
+// StartPennyDropWorker penny drops beneficiaries registered without one.
+// Each drop waits on the gateway for settlement, so it runs apart from the
+// retry worker to keep slow drops from holding up retries.
+func (w *Worker) StartPennyDropWorker(ctx context.Context) {
+	log.Ctx(ctx).Info().Msg("Starting penny drop worker")
+	changed := w.settings.Changed()
+	ticker := time.NewTicker(w.jobs().PennyDrop.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Ctx(ctx).Info().Msg("Worker stopped by context")
+			return
+		case <-w.stopChan:
+			log.Ctx(ctx).Info().Msg("Worker stopped by signal")
+			return
+		case <-changed:
+			changed = w.settings.Changed()
+			ticker.Reset(w.jobs().PennyDrop.Interval)
+		case <-ticker.C:
+			w.ProcessPendingPennyDrops(ctx)
 		}
 	}
 }
 
 func (w *Worker) StartNEFTDisbursement(ctx context.Context) {
-	log.Info().Msg("Starting neft disbursement worker")
+	log.Ctx(ctx).Info().Msg("Starting neft disbursement worker")
 
-	ticker := time.NewTicker(w.neftPollInterval)
+	changed := w.settings.Changed()
+	ticker := time.NewTicker(w.jobs().NEFT.Interval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			log.Ctx(ctx).Info().Msg("Worker stopped by context")
-			close(w.stopChan)
 			return
 		case <-w.stopChan:
 			log.Ctx(ctx).Info().Msg("Worker stopped by signal")
 			return
+		case <-changed:
+			changed = w.settings.Changed()
+			ticker.Reset(w.jobs().NEFT.Interval)
 		case <-ticker.C:
+			if !w.isNEFTWindowOpen(ctx, time.Now()) {
+				continue
+			}
 			log.Ctx(ctx).Info().Msg("Processing neft disbursement")
 			w.ProcessNEFTBatch(ctx)
 		}
@@ -105,5 +272,5 @@ func (w *Worker) Stop(ctx context.Context) {
 	w.stopOnce.Do(func() {
 		close(w.stopChan)
 	})
-	log.Info().Msg("Stopping disbursement worker")
+	log.Ctx(ctx).Info().Msg("Stopping disbursement worker")
 }
@@ -0,0 +1,37 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWorker_StopsOnContext(t *testing.T) {
+	t.Run("stops every loop and still allows Stop", func(t *testing.T) {
+		worker := Worker{paymentChan: make(chan string), stopChan: make(chan struct{})}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		var running sync.WaitGroup
+		for range 3 {
+			running.Add(1)
+			go func() {
+				defer running.Done()
+				worker.StartPaymentDisbursement(ctx)
+			}()
+		}
+		cancel()
+
+		stopped := make(chan struct{})
+		go func() {
+			running.Wait()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+			t.Fatal("workers did not stop when the context was cancelled")
+		}
+		worker.Stop(context.Background())
+	})
+}
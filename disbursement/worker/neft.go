@@ -3,11 +3,27 @@ package worker
 import (
 	"context"
 	"loan-disbursement-service/models"
+	"shared/logging"
+	"time"
 
 	"github.com/rs/zerolog/log"
 )
 
+// isNEFTWindowOpen keeps NEFT disbursements queued outside the settlement
+// window, on bank holidays and on second and fourth Saturdays, so they are
+// submitted when the bank will batch them rather than failing at the gateway.
+func (w *Worker) isNEFTWindowOpen(ctx context.Context, now time.Time) bool {
+	if w.calendar.IsNEFTOpen(now) {
+		return true
+	}
+	log.Ctx(ctx).Info().
+		Time("next_window_at", w.calendar.NextNEFTOpen(now)).
+		Msg("NEFT window closed, holding NEFT disbursements")
+	return false
+}
+
 func (w *Worker) ProcessNEFTBatch(ctx context.Context) {
+	batchSize := w.jobs().NEFT.BatchSize
 	status := []models.DisbursementStatus{
 		models.DisbursementStatusInitiated,
 		models.DisbursementStatusSuspended,
@@ -20,28 +36,31 @@ func (w *Worker) ProcessNEFTBatch(ctx context.Context) {
 		disbursements, err := w.disbursement.List(
 			ctx,
 			offset,
-			w.neftBatchSize,
+			batchSize,
 			status,
 			channels,
 		)
-		log.Info().Msgf("NEFT disbursements worker: %v", len(disbursements))
 		if err != nil {
-			log.Error().Err(err).Msg("failed to list disbursements")
+			log.Ctx(ctx).Error().Err(err).Msg("failed to list disbursements")
 			return
 		}
+		log.Ctx(ctx).Info().Int("count", len(disbursements)).Msg("NEFT disbursements worker")
 
 		for _, disbursement := range disbursements {
 			err := w.paymentService.Process(ctx, &disbursement)
 			if err != nil {
-				log.Error().Err(err).Msg("failed to process disbursement")
+				log.Ctx(logging.With(ctx, logging.Fields{
+					LoanId:         disbursement.LoanId,
+					DisbursementId: disbursement.Id,
+				})).Error().Err(err).Msg("failed to process disbursement")
 			}
 		}
 
-		if len(disbursements) < w.neftBatchSize {
-			log.Info().Msg("no more disbursements to process")
+		if len(disbursements) < batchSize {
+			log.Ctx(ctx).Info().Msg("no more disbursements to process")
 			break
 		}
 
-		offset += w.neftBatchSize
+		offset += batchSize
 	}
 }
@@ -6,7 +6,11 @@ import (
 	"loan-disbursement-service/db/schema"
 	"loan-disbursement-service/models"
 	db_test "loan-disbursement-service/test/db"
+	"shared/calendar"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestWorker_ProcessNEFTBatch(t *testing.T) {
@@ -19,7 +23,7 @@ func TestWorker_ProcessNEFTBatch(t *testing.T) {
 		worker := Worker{
 			disbursement:   mockDisbursement,
 			paymentService: mockPaymentService,
-			neftBatchSize:  10,
+			settings:       batchSize(10),
 		}
 
 		disbursements := []schema.Disbursement{
@@ -51,14 +55,14 @@ func TestWorker_ProcessNEFTBatch(t *testing.T) {
 		mockPaymentService.AssertExpectations(t)
 	})
 
-	t.Run("stops when batch size is less than neftBatchSize", func(t *testing.T) {
+	t.Run("stops when a page is smaller than the batch size", func(t *testing.T) {
 		mockDisbursement := new(db_test.MockDisbursementRepository)
 		mockPaymentService := new(MockPaymentService)
 
 		worker := Worker{
 			disbursement:   mockDisbursement,
 			paymentService: mockPaymentService,
-			neftBatchSize:  10,
+			settings:       batchSize(10),
 		}
 
 		disbursements := []schema.Disbursement{
@@ -92,7 +96,7 @@ func TestWorker_ProcessNEFTBatch(t *testing.T) {
 		worker := Worker{
 			disbursement:   mockDisbursement,
 			paymentService: mockPaymentService,
-			neftBatchSize:  2,
+			settings:       batchSize(2),
 		}
 
 		firstBatch := []schema.Disbursement{
@@ -147,7 +151,7 @@ func TestWorker_ProcessNEFTBatch(t *testing.T) {
 		worker := Worker{
 			disbursement:   mockDisbursement,
 			paymentService: mockPaymentService,
-			neftBatchSize:  10,
+			settings:       batchSize(10),
 		}
 
 		mockDisbursement.On("List", ctx, 0, 10, []models.DisbursementStatus{
@@ -170,7 +174,7 @@ func TestWorker_ProcessNEFTBatch(t *testing.T) {
 		worker := Worker{
 			disbursement:   mockDisbursement,
 			paymentService: mockPaymentService,
-			neftBatchSize:  10,
+			settings:       batchSize(10),
 		}
 
 		disbursements := []schema.Disbursement{
@@ -218,7 +222,7 @@ func TestWorker_ProcessNEFTBatch(t *testing.T) {
 		worker := Worker{
 			disbursement:   mockDisbursement,
 			paymentService: mockPaymentService,
-			neftBatchSize:  10,
+			settings:       batchSize(10),
 		}
 
 		emptyList := []schema.Disbursement{}
@@ -243,7 +247,7 @@ func TestWorker_ProcessNEFTBatch(t *testing.T) {
 		worker := Worker{
 			disbursement:   mockDisbursement,
 			paymentService: mockPaymentService,
-			neftBatchSize:  10,
+			settings:       batchSize(10),
 		}
 
 		disbursements := []schema.Disbursement{
@@ -277,7 +281,7 @@ func TestWorker_ProcessNEFTBatch(t *testing.T) {
 		worker := Worker{
 			disbursement:   mockDisbursement,
 			paymentService: mockPaymentService,
-			neftBatchSize:  2,
+			settings:       batchSize(2),
 		}
 
 		exactBatch := []schema.Disbursement{
@@ -325,7 +329,7 @@ func TestWorker_ProcessNEFTBatch(t *testing.T) {
 		worker := Worker{
 			disbursement:   mockDisbursement,
 			paymentService: mockPaymentService,
-			neftBatchSize:  5,
+			settings:       batchSize(5),
 		}
 
 		largeBatch := make([]schema.Disbursement, 5)
@@ -371,7 +375,7 @@ func TestWorker_ProcessNEFTBatch(t *testing.T) {
 		worker := Worker{
 			disbursement:   mockDisbursement,
 			paymentService: mockPaymentService,
-			neftBatchSize:  10,
+			settings:       batchSize(10),
 		}
 
 		disbursements := []schema.Disbursement{
@@ -403,3 +407,12 @@ func TestWorker_ProcessNEFTBatch(t *testing.T) {
 		mockPaymentService.AssertExpectations(t)
 	})
 }
+
+func TestWorker_isNEFTWindowOpen(t *testing.T) {
+	ctx := context.Background()
+	worker := Worker{calendar: calendar.Default()}
+
+	assert.True(t, worker.isNEFTWindowOpen(ctx, time.Date(2024, time.March, 11, 10, 0, 0, 0, calendar.IST)))
+	assert.False(t, worker.isNEFTWindowOpen(ctx, time.Date(2024, time.March, 11, 20, 0, 0, 0, calendar.IST)))
+	assert.False(t, worker.isNEFTWindowOpen(ctx, time.Date(2024, time.March, 9, 10, 0, 0, 0, calendar.IST)))
+}
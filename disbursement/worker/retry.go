@@ -3,13 +3,24 @@ package worker
 import (
 	"context"
 	"loan-disbursement-service/models"
+	"shared/logging"
+	"time"
 
 	"github.com/rs/zerolog/log"
 )
 
+// ProcessRetryBatch retries suspended disbursements whose backoff is over.
+// It also sends initiated ones the payment worker has not picked up within
+// an interval, which is how a disbursement left off a full payment queue is
+// sent. Processing claims the disbursement, so one still waiting on the
+// queue is not sent twice.
 func (w *Worker) ProcessRetryBatch(ctx context.Context) {
+	jobs := w.jobs()
+	batchSize := jobs.Retry.BatchSize
+	unqueuedBefore := time.Now().Add(-jobs.Retry.Interval)
 	status := []models.DisbursementStatus{
 		models.DisbursementStatusSuspended,
+		models.DisbursementStatusInitiated,
 	}
 	offset := 0
 	channels := []models.PaymentChannel{
@@ -20,28 +31,48 @@ func (w *Worker) ProcessRetryBatch(ctx context.Context) {
 		disbursements, err := w.disbursement.List(
 			ctx,
 			offset,
-			w.retryBatchSize,
+			batchSize,
 			status,
 			channels,
 		)
-		log.Info().Msgf("Retry disbursements worker: %v", len(disbursements))
 		if err != nil {
-			log.Error().Err(err).Msg("failed to list disbursements")
+			log.Ctx(ctx).Error().Err(err).Msg("failed to list disbursements")
 			return
 		}
+		log.Ctx(ctx).Info().Int("count", len(disbursements)).Msg("Retry disbursements worker")
 
 		for _, disbursement := range disbursements {
+			if disbursement.Status == models.DisbursementStatusInitiated &&
+				disbursement.UpdatedAt.After(unqueuedBefore) {
+				continue
+			}
 			err := w.paymentService.Process(ctx, &disbursement)
 			if err != nil {
-				log.Error().Err(err).Msg("failed to process disbursement")
+				log.Ctx(logging.With(ctx, logging.Fields{
+					LoanId:         disbursement.LoanId,
+					DisbursementId: disbursement.Id,
+				})).Error().Err(err).Msg("failed to process disbursement")
 			}
 		}
 
-		if len(disbursements) < w.retryBatchSize {
-			log.Info().Msg("no more disbursements to process")
+		if len(disbursements) < batchSize {
+			log.Ctx(ctx).Info().Msg("no more disbursements to process")
 			break
 		}
 
-		offset += w.retryBatchSize
+		offset += batchSize
+	}
+}
+
+// ProcessPendingSchedules generates the repayment schedules that could not be
+// stored when their disbursement settled, one batch per tick.
+func (w *Worker) ProcessPendingSchedules(ctx context.Context) {
+	generated, err := w.paymentService.GeneratePendingSchedules(ctx, w.jobs().Retry.BatchSize)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to list pending repayment schedules")
+		return
+	}
+	if generated > 0 {
+		log.Ctx(ctx).Info().Int("generated", generated).Msg("Pending repayment schedules generated")
 	}
 }
@@ -3,14 +3,28 @@ package worker
 import (
 	"context"
 	"errors"
+	"loan-disbursement-service/config"
 	"loan-disbursement-service/db/schema"
 	"loan-disbursement-service/models"
 	db_test "loan-disbursement-service/test/db"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
 
+// batchSize gives settings where every poller takes size records at a time.
+func batchSize(size int) *config.Store {
+	settings := config.Default()
+	settings.Worker.Retry.BatchSize = size
+	settings.Worker.NEFT.BatchSize = size
+	settings.Worker.Scheduled.BatchSize = size
+	settings.Worker.DeadLetter.BatchSize = size
+	settings.Worker.Webhook.BatchSize = size
+	settings.Worker.PennyDrop.BatchSize = size
+	return config.NewStore("", settings)
+}
+
 // MockPaymentService for testing
 type MockPaymentService struct {
 	mock.Mock
@@ -42,13 +56,20 @@ func (m *MockPaymentService) HandleFailure(
 
 func (m *MockPaymentService) HandleSuccess(
 	ctx context.Context,
-	disbursementId, transactionId string,
+	disbursement *schema.Disbursement,
+	transactionId string,
 	channel models.PaymentChannel,
+	settledAt time.Time,
 ) error {
-	args := m.Called(ctx, disbursementId, transactionId, channel)
+	args := m.Called(ctx, disbursement, transactionId, channel, settledAt)
 	return args.Error(0)
 }
 
+func (m *MockPaymentService) GeneratePendingSchedules(ctx context.Context, limit int) (int, error) {
+	args := m.Called(ctx, limit)
+	return args.Int(0), args.Error(1)
+}
+
 func TestWorker_ProcessRetryBatch(t *testing.T) {
 	ctx := context.Background()
 
@@ -59,7 +80,7 @@ func TestWorker_ProcessRetryBatch(t *testing.T) {
 		worker := Worker{
 			disbursement:   mockDisbursement,
 			paymentService: mockPaymentService,
-			retryBatchSize: 10,
+			settings:       batchSize(10),
 		}
 
 		disbursements := []schema.Disbursement{
@@ -75,6 +96,7 @@ func TestWorker_ProcessRetryBatch(t *testing.T) {
 
 		mockDisbursement.On("List", ctx, 0, 10, []models.DisbursementStatus{
 			models.DisbursementStatusSuspended,
+			models.DisbursementStatusInitiated,
 		}, []models.PaymentChannel{
 			models.PaymentChannelUPI,
 			models.PaymentChannelIMPS,
@@ -89,14 +111,14 @@ func TestWorker_ProcessRetryBatch(t *testing.T) {
 		mockPaymentService.AssertExpectations(t)
 	})
 
-	t.Run("stops when batch size is less than retryBatchSize", func(t *testing.T) {
+	t.Run("stops when a page is smaller than the batch size", func(t *testing.T) {
 		mockDisbursement := new(db_test.MockDisbursementRepository)
 		mockPaymentService := new(MockPaymentService)
 
 		worker := Worker{
 			disbursement:   mockDisbursement,
 			paymentService: mockPaymentService,
-			retryBatchSize: 10,
+			settings:       batchSize(10),
 		}
 
 		disbursements := []schema.Disbursement{
@@ -108,6 +130,7 @@ func TestWorker_ProcessRetryBatch(t *testing.T) {
 
 		mockDisbursement.On("List", ctx, 0, 10, []models.DisbursementStatus{
 			models.DisbursementStatusSuspended,
+			models.DisbursementStatusInitiated,
 		}, []models.PaymentChannel{
 			models.PaymentChannelUPI,
 			models.PaymentChannelIMPS,
@@ -119,7 +142,7 @@ func TestWorker_ProcessRetryBatch(t *testing.T) {
 
 		mockDisbursement.AssertExpectations(t)
 		mockPaymentService.AssertExpectations(t)
-		// Should not call List again since batch size < retryBatchSize
+		// Should not call List again since the page was smaller than the batch size
 		mockDisbursement.AssertNumberOfCalls(t, "List", 1)
 	})
 
@@ -130,7 +153,7 @@ func TestWorker_ProcessRetryBatch(t *testing.T) {
 		worker := Worker{
 			disbursement:   mockDisbursement,
 			paymentService: mockPaymentService,
-			retryBatchSize: 2,
+			settings:       batchSize(2),
 		}
 
 		firstBatch := []schema.Disbursement{
@@ -153,6 +176,7 @@ func TestWorker_ProcessRetryBatch(t *testing.T) {
 
 		mockDisbursement.On("List", ctx, 0, 2, []models.DisbursementStatus{
 			models.DisbursementStatusSuspended,
+			models.DisbursementStatusInitiated,
 		}, []models.PaymentChannel{
 			models.PaymentChannelUPI,
 			models.PaymentChannelIMPS,
@@ -160,6 +184,7 @@ func TestWorker_ProcessRetryBatch(t *testing.T) {
 
 		mockDisbursement.On("List", ctx, 2, 2, []models.DisbursementStatus{
 			models.DisbursementStatusSuspended,
+			models.DisbursementStatusInitiated,
 		}, []models.PaymentChannel{
 			models.PaymentChannelUPI,
 			models.PaymentChannelIMPS,
@@ -182,11 +207,12 @@ func TestWorker_ProcessRetryBatch(t *testing.T) {
 		worker := Worker{
 			disbursement:   mockDisbursement,
 			paymentService: mockPaymentService,
-			retryBatchSize: 10,
+			settings:       batchSize(10),
 		}
 
 		mockDisbursement.On("List", ctx, 0, 10, []models.DisbursementStatus{
 			models.DisbursementStatusSuspended,
+			models.DisbursementStatusInitiated,
 		}, []models.PaymentChannel{
 			models.PaymentChannelUPI,
 			models.PaymentChannelIMPS,
@@ -205,7 +231,7 @@ func TestWorker_ProcessRetryBatch(t *testing.T) {
 		worker := Worker{
 			disbursement:   mockDisbursement,
 			paymentService: mockPaymentService,
-			retryBatchSize: 10,
+			settings:       batchSize(10),
 		}
 
 		disbursements := []schema.Disbursement{
@@ -225,6 +251,7 @@ func TestWorker_ProcessRetryBatch(t *testing.T) {
 
 		mockDisbursement.On("List", ctx, 0, 10, []models.DisbursementStatus{
 			models.DisbursementStatusSuspended,
+			models.DisbursementStatusInitiated,
 		}, []models.PaymentChannel{
 			models.PaymentChannelUPI,
 			models.PaymentChannelIMPS,
@@ -251,13 +278,14 @@ func TestWorker_ProcessRetryBatch(t *testing.T) {
 		worker := Worker{
 			disbursement:   mockDisbursement,
 			paymentService: mockPaymentService,
-			retryBatchSize: 10,
+			settings:       batchSize(10),
 		}
 
 		emptyList := []schema.Disbursement{}
 
 		mockDisbursement.On("List", ctx, 0, 10, []models.DisbursementStatus{
 			models.DisbursementStatusSuspended,
+			models.DisbursementStatusInitiated,
 		}, []models.PaymentChannel{
 			models.PaymentChannelUPI,
 			models.PaymentChannelIMPS,
@@ -276,7 +304,7 @@ func TestWorker_ProcessRetryBatch(t *testing.T) {
 		worker := Worker{
 			disbursement:   mockDisbursement,
 			paymentService: mockPaymentService,
-			retryBatchSize: 10,
+			settings:       batchSize(10),
 		}
 
 		disbursements := []schema.Disbursement{
@@ -287,7 +315,7 @@ func TestWorker_ProcessRetryBatch(t *testing.T) {
 		}
 
 		mockDisbursement.On("List", ctx, 0, 10,
-			[]models.DisbursementStatus{models.DisbursementStatusSuspended},
+			[]models.DisbursementStatus{models.DisbursementStatusSuspended, models.DisbursementStatusInitiated},
 			[]models.PaymentChannel{models.PaymentChannelUPI, models.PaymentChannelIMPS},
 		).Return(disbursements, nil).Once()
 
@@ -299,6 +327,42 @@ func TestWorker_ProcessRetryBatch(t *testing.T) {
 		mockPaymentService.AssertExpectations(t)
 	})
 
+	t.Run("sends initiated disbursements the payment worker has not picked up", func(t *testing.T) {
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockPaymentService := new(MockPaymentService)
+
+		worker := Worker{
+			disbursement:   mockDisbursement,
+			paymentService: mockPaymentService,
+			settings:       batchSize(10),
+		}
+
+		disbursements := []schema.Disbursement{
+			{
+				Id:        "DISB-1",
+				Status:    models.DisbursementStatusInitiated,
+				UpdatedAt: time.Now().Add(-time.Hour),
+			},
+			{
+				Id:        "DISB-2",
+				Status:    models.DisbursementStatusInitiated,
+				UpdatedAt: time.Now(),
+			},
+		}
+
+		mockDisbursement.On("List", ctx, 0, 10,
+			[]models.DisbursementStatus{models.DisbursementStatusSuspended, models.DisbursementStatusInitiated},
+			[]models.PaymentChannel{models.PaymentChannelUPI, models.PaymentChannelIMPS},
+		).Return(disbursements, nil).Once()
+
+		mockPaymentService.On("Process", ctx, &disbursements[0]).Return(nil).Once()
+
+		worker.ProcessRetryBatch(ctx)
+
+		mockPaymentService.AssertExpectations(t)
+		mockPaymentService.AssertNumberOfCalls(t, "Process", 1)
+	})
+
 	t.Run("handles exact batch size boundary", func(t *testing.T) {
 		mockDisbursement := new(db_test.MockDisbursementRepository)
 		mockPaymentService := new(MockPaymentService)
@@ -306,7 +370,7 @@ func TestWorker_ProcessRetryBatch(t *testing.T) {
 		worker := Worker{
 			disbursement:   mockDisbursement,
 			paymentService: mockPaymentService,
-			retryBatchSize: 2,
+			settings:       batchSize(2),
 		}
 
 		exactBatch := []schema.Disbursement{
@@ -324,6 +388,7 @@ func TestWorker_ProcessRetryBatch(t *testing.T) {
 
 		mockDisbursement.On("List", ctx, 0, 2, []models.DisbursementStatus{
 			models.DisbursementStatusSuspended,
+			models.DisbursementStatusInitiated,
 		}, []models.PaymentChannel{
 			models.PaymentChannelUPI,
 			models.PaymentChannelIMPS,
@@ -331,6 +396,7 @@ func TestWorker_ProcessRetryBatch(t *testing.T) {
 
 		mockDisbursement.On("List", ctx, 2, 2, []models.DisbursementStatus{
 			models.DisbursementStatusSuspended,
+			models.DisbursementStatusInitiated,
 		}, []models.PaymentChannel{
 			models.PaymentChannelUPI,
 			models.PaymentChannelIMPS,
@@ -352,7 +418,7 @@ func TestWorker_ProcessRetryBatch(t *testing.T) {
 		worker := Worker{
 			disbursement:   mockDisbursement,
 			paymentService: mockPaymentService,
-			retryBatchSize: 5,
+			settings:       batchSize(5),
 		}
 
 		largeBatch := make([]schema.Disbursement, 5)
@@ -367,6 +433,7 @@ func TestWorker_ProcessRetryBatch(t *testing.T) {
 
 		mockDisbursement.On("List", ctx, 0, 5, []models.DisbursementStatus{
 			models.DisbursementStatusSuspended,
+			models.DisbursementStatusInitiated,
 		}, []models.PaymentChannel{
 			models.PaymentChannelUPI,
 			models.PaymentChannelIMPS,
@@ -374,6 +441,7 @@ func TestWorker_ProcessRetryBatch(t *testing.T) {
 
 		mockDisbursement.On("List", ctx, 5, 5, []models.DisbursementStatus{
 			models.DisbursementStatusSuspended,
+			models.DisbursementStatusInitiated,
 		}, []models.PaymentChannel{
 			models.PaymentChannelUPI,
 			models.PaymentChannelIMPS,
@@ -390,3 +458,29 @@ func TestWorker_ProcessRetryBatch(t *testing.T) {
 		mockPaymentService.AssertNumberOfCalls(t, "Process", 5)
 	})
 }
+
+func TestWorker_ProcessPendingSchedules(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("generates one batch of pending schedules", func(t *testing.T) {
+		mockPaymentService := new(MockPaymentService)
+		worker := Worker{paymentService: mockPaymentService, settings: batchSize(10)}
+
+		mockPaymentService.On("GeneratePendingSchedules", ctx, 10).Return(2, nil).Once()
+
+		worker.ProcessPendingSchedules(ctx)
+
+		mockPaymentService.AssertExpectations(t)
+	})
+
+	t.Run("logs and returns when listing fails", func(t *testing.T) {
+		mockPaymentService := new(MockPaymentService)
+		worker := Worker{paymentService: mockPaymentService, settings: batchSize(10)}
+
+		mockPaymentService.On("GeneratePendingSchedules", ctx, 10).Return(0, errors.New("db down")).Once()
+
+		worker.ProcessPendingSchedules(ctx)
+
+		mockPaymentService.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,41 @@
+package worker
+
+import (
+	"context"
+	"shared/logging"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+func (w *Worker) ProcessScheduledBatch(ctx context.Context, now time.Time) {
+	batchSize := w.jobs().Scheduled.BatchSize
+	// Released disbursements drop out of the due list, so only the ones held
+	// back move the offset forward.
+	offset := 0
+	for {
+		disbursements, err := w.disbursement.ListDue(ctx, now, offset, batchSize)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to list scheduled disbursements")
+			return
+		}
+		log.Ctx(ctx).Info().Int("count", len(disbursements)).Msg("Scheduled disbursements worker")
+
+		for _, disbursement := range disbursements {
+			released, err := w.scheduler.Release(ctx, &disbursement, now)
+			if err != nil {
+				log.Ctx(logging.With(ctx, logging.Fields{
+					LoanId:         disbursement.LoanId,
+					DisbursementId: disbursement.Id,
+				})).Error().Err(err).Msg("failed to release disbursement")
+			}
+			if !released {
+				offset++
+			}
+		}
+
+		if len(disbursements) < batchSize {
+			break
+		}
+	}
+}
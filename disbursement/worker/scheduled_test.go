@@ -0,0 +1,77 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"loan-disbursement-service/db/schema"
+	db_test "loan-disbursement-service/test/db"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSchedulerService struct {
+	mock.Mock
+}
+
+func (m *MockSchedulerService) Release(
+	ctx context.Context,
+	disbursement *schema.Disbursement,
+	now time.Time,
+) (bool, error) {
+	args := m.Called(ctx, disbursement, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestWorker_ProcessScheduledBatch(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, time.March, 10, 9, 0, 0, 0, time.UTC)
+
+	t.Run("skips past held disbursements on the next page", func(t *testing.T) {
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockScheduler := new(MockSchedulerService)
+
+		worker := Worker{
+			disbursement: mockDisbursement,
+			scheduler:    mockScheduler,
+			settings:     batchSize(2),
+		}
+
+		mockDisbursement.On("ListDue", ctx, now, 0, 2).Return([]schema.Disbursement{
+			{Id: "DISB-1"},
+			{Id: "DISB-2"},
+		}, nil).Once()
+		mockDisbursement.On("ListDue", ctx, now, 1, 2).Return([]schema.Disbursement{
+			{Id: "DISB-3"},
+		}, nil).Once()
+		mockScheduler.On("Release", ctx, mock.MatchedBy(func(d *schema.Disbursement) bool {
+			return d.Id == "DISB-1"
+		}), now).Return(false, nil).Once()
+		mockScheduler.On("Release", ctx, mock.MatchedBy(func(d *schema.Disbursement) bool {
+			return d.Id == "DISB-2" || d.Id == "DISB-3"
+		}), now).Return(true, nil).Twice()
+
+		worker.ProcessScheduledBatch(ctx, now)
+
+		mockDisbursement.AssertExpectations(t)
+		mockScheduler.AssertExpectations(t)
+	})
+
+	t.Run("returns early when listing fails", func(t *testing.T) {
+		mockDisbursement := new(db_test.MockDisbursementRepository)
+		mockScheduler := new(MockSchedulerService)
+
+		worker := Worker{
+			disbursement: mockDisbursement,
+			scheduler:    mockScheduler,
+			settings:     batchSize(2),
+		}
+
+		mockDisbursement.On("ListDue", ctx, now, 0, 2).Return(nil, errors.New("database error")).Once()
+
+		worker.ProcessScheduledBatch(ctx, now)
+
+		mockScheduler.AssertNotCalled(t, "Release")
+	})
+}
@@ -0,0 +1,19 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ProcessWebhookDeliveries sends one batch of due deliveries per tick.
+func (w *Worker) ProcessWebhookDeliveries(ctx context.Context) {
+	delivered, err := w.webhook.DeliverPending(ctx, w.jobs().Webhook.BatchSize)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to deliver webhooks")
+		return
+	}
+	if delivered > 0 {
+		log.Ctx(ctx).Info().Int("delivered", delivered).Msg("Webhook delivery")
+	}
+}
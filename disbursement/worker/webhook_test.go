@@ -0,0 +1,144 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"loan-disbursement-service/api/services"
+	"loan-disbursement-service/config"
+	"loan-disbursement-service/models"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockWebhookService) Subscribe(
+	ctx context.Context,
+	clientId string,
+	req models.WebhookSubscriptionRequest,
+) (*models.WebhookSubscription, error) {
+	args := m.Called(ctx, clientId, req)
+	return args.Get(0).(*models.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookService) ListSubscriptions(
+	ctx context.Context,
+	clientId string,
+) ([]models.WebhookSubscription, error) {
+	args := m.Called(ctx, clientId)
+	return args.Get(0).([]models.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookService) Unsubscribe(ctx context.Context, clientId, subscriptionId string) error {
+	args := m.Called(ctx, clientId, subscriptionId)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) ListDeliveries(
+	ctx context.Context,
+	clientId, subscriptionId string,
+	filter models.WebhookDeliveryFilter,
+) ([]models.WebhookDelivery, error) {
+	args := m.Called(ctx, clientId, subscriptionId, filter)
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookService) Redeliver(
+	ctx context.Context,
+	clientId, deliveryId string,
+) (*models.WebhookDelivery, error) {
+	args := m.Called(ctx, clientId, deliveryId)
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookService) Publish(
+	ctx context.Context,
+	event models.WebhookEvent,
+	disbursementId string,
+) error {
+	args := m.Called(ctx, event, disbursementId)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) WithTx(tx *gorm.DB) services.WebhookService {
+	return m
+}
+
+func (m *MockWebhookService) DeliverPending(ctx context.Context, limit int) (int, error) {
+	args := m.Called(ctx, limit)
+	return args.Int(0), args.Error(1)
+}
+
+func TestWorker_ProcessWebhookDeliveries(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("delivers one batch", func(t *testing.T) {
+		mockWebhook := new(MockWebhookService)
+		worker := Worker{webhook: mockWebhook, settings: batchSize(100)}
+
+		mockWebhook.On("DeliverPending", ctx, 100).Return(4, nil).Once()
+
+		worker.ProcessWebhookDeliveries(ctx)
+
+		mockWebhook.AssertExpectations(t)
+	})
+
+	t.Run("logs and returns when listing fails", func(t *testing.T) {
+		mockWebhook := new(MockWebhookService)
+		worker := Worker{webhook: mockWebhook, settings: batchSize(100)}
+
+		mockWebhook.On("DeliverPending", ctx, 100).Return(0, errors.New("db down")).Once()
+
+		worker.ProcessWebhookDeliveries(ctx)
+
+		mockWebhook.AssertExpectations(t)
+	})
+}
+
+func TestWorker_StartWebhookDelivery(t *testing.T) {
+	t.Run("applies a reloaded interval without waiting out the old one", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte("worker:\n  webhook:\n    interval: 1h\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		settings, err := config.Load(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		store := config.NewStore(path, settings)
+		mockWebhook := new(MockWebhookService)
+		worker := Worker{webhook: mockWebhook, settings: store, stopChan: make(chan struct{})}
+		delivered := make(chan struct{}, 1)
+		mockWebhook.On("DeliverPending", mock.Anything, 100).Return(0, nil).
+			Run(func(mock.Arguments) {
+				select {
+				case delivered <- struct{}{}:
+				default:
+				}
+			})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go worker.StartWebhookDelivery(ctx)
+
+		if err := os.WriteFile(path, []byte("worker:\n  webhook:\n    interval: 10ms\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.Reload(); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case <-delivered:
+		case <-time.After(time.Second):
+			t.Fatal("deliveries did not run at the reloaded interval")
+		}
+	})
+}
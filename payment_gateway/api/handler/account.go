@@ -6,6 +6,8 @@ import (
 
 	"payment-gateway/api/service"
 	"payment-gateway/models"
+	"shared/apperrors"
+	"shared/validation"
 
 	"github.com/gorilla/mux"
 )
@@ -24,13 +26,17 @@ func NewAccountHandler(service service.AccountService) *AccountHandler {
 func (h AccountHandler) CreateAccount(w http.ResponseWriter, r *http.Request) {
 	var req models.CreateAccountRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		h.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+	if err := validation.Struct(req); err != nil {
+		h.Error(w, r, err)
 		return
 	}
 
 	account, err := h.service.CreateAccount(r.Context(), req)
 	if err != nil {
-		h.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		h.Error(w, r, err)
 		return
 	}
 
@@ -40,7 +46,7 @@ func (h AccountHandler) CreateAccount(w http.ResponseWriter, r *http.Request) {
 func (h AccountHandler) ListAccounts(w http.ResponseWriter, r *http.Request) {
 	accounts, err := h.service.ListAccounts(r.Context())
 	if err != nil {
-		h.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		h.Error(w, r, err)
 		return
 	}
 	h.JSONResponse(w, accounts)
@@ -50,13 +56,17 @@ func (h AccountHandler) UpdateAccount(w http.ResponseWriter, r *http.Request) {
 	id := mux.Vars(r)["id"]
 	var req models.UpdateAccountRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		h.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+	if err := validation.Struct(req); err != nil {
+		h.Error(w, r, err)
 		return
 	}
 
 	account, err := h.service.UpdateAccount(r.Context(), id, req)
 	if err != nil {
-		h.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		h.Error(w, r, err)
 		return
 	}
 	h.JSONResponse(w, account)
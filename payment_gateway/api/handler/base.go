@@ -3,6 +3,8 @@ package handler
 import (
 	"encoding/json"
 	"net/http"
+
+	"shared/apperrors"
 )
 
 type BaseHandler struct{}
@@ -13,8 +15,8 @@ func (b *BaseHandler) JSONResponse(w http.ResponseWriter, v any) {
 	json.NewEncoder(w).Encode(v)
 }
 
-func (b *BaseHandler) ErrorResponse(w http.ResponseWriter, status int, error string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(map[string]string{"error": error})
+// Error sends err in the error envelope, with the status of its code.
+// Errors without a code are sent as internal.
+func (b *BaseHandler) Error(w http.ResponseWriter, r *http.Request, err error) {
+	apperrors.Write(w, r, err)
 }
@@ -5,6 +5,9 @@ import (
 	"net/http"
 	"payment-gateway/api/service"
 	"payment-gateway/models"
+	"shared/apperrors"
+	"shared/pii"
+	"shared/validation"
 
 	"github.com/gorilla/mux"
 )
@@ -21,15 +24,19 @@ func NewPaymentHandler(service service.PaymentService) *PaymentHandler {
 func (h PaymentHandler) ProcessPayment(w http.ResponseWriter, r *http.Request) {
 	request := models.PaymentRequest{}
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		h.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		h.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+	if err := validation.Struct(request); err != nil {
+		h.Error(w, r, err)
 		return
 	}
 	transaction, err := h.service.Process(r.Context(), request)
 	if err != nil {
-		h.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		h.Error(w, r, err)
 		return
 	}
-	h.JSONResponse(w, transaction)
+	h.JSONResponse(w, pii.Visible(r.Context(), transaction))
 }
 
 func (h PaymentHandler) GetTransaction(w http.ResponseWriter, r *http.Request) {
@@ -37,8 +44,8 @@ func (h PaymentHandler) GetTransaction(w http.ResponseWriter, r *http.Request) {
 	channel := models.PaymentChannel(mux.Vars(r)["channel"])
 	transaction, err := h.service.GetTransaction(r.Context(), channel, transactionID)
 	if err != nil {
-		h.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		h.Error(w, r, err)
 		return
 	}
-	h.JSONResponse(w, transaction)
+	h.JSONResponse(w, pii.Visible(r.Context(), transaction))
 }
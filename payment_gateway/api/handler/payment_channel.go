@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"payment-gateway/api/service"
 	"payment-gateway/models"
+	"shared/apperrors"
+	"shared/validation"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -29,12 +31,16 @@ func NewPaymentChannelHandler(
 func (h PaymentChannelHandler) CreatePaymentChannel(w http.ResponseWriter, r *http.Request) {
 	var req models.CreatePaymentChannelRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		h.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+	if err := validation.Struct(req); err != nil {
+		h.Error(w, r, err)
 		return
 	}
 	paymentChannel, err := h.service.CreatePaymentChannel(r.Context(), req)
 	if err != nil {
-		h.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		h.Error(w, r, err)
 		return
 	}
 	h.JSONResponse(w, paymentChannel)
@@ -43,7 +49,7 @@ func (h PaymentChannelHandler) CreatePaymentChannel(w http.ResponseWriter, r *ht
 func (h PaymentChannelHandler) ListPaymentChannels(w http.ResponseWriter, r *http.Request) {
 	paymentChannels, err := h.service.ListPaymentChannels(r.Context())
 	if err != nil {
-		h.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		h.Error(w, r, err)
 		return
 	}
 	h.JSONResponse(w, paymentChannels)
@@ -53,12 +59,16 @@ func (h PaymentChannelHandler) UpdatePaymentChannel(w http.ResponseWriter, r *ht
 	channel := models.PaymentChannel(mux.Vars(r)["channel"])
 	var req models.UpdatePaymentChannelRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		h.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+	if err := validation.Struct(req); err != nil {
+		h.Error(w, r, err)
 		return
 	}
 	paymentChannel, err := h.service.UpdatePaymentChannel(r.Context(), channel, req)
 	if err != nil {
-		h.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		h.Error(w, r, err)
 		return
 	}
 	h.JSONResponse(w, paymentChannel)
@@ -66,7 +76,5 @@ func (h PaymentChannelHandler) UpdatePaymentChannel(w http.ResponseWriter, r *ht
 
 func (h PaymentChannelHandler) CheckAvailability(w http.ResponseWriter, r *http.Request) {
 	channel := models.PaymentChannel(mux.Vars(r)["channel"])
-	time := time.Now()
-	available := h.schedule.IsAvailable(channel, time)
-	h.JSONResponse(w, map[string]bool{"available": available})
+	h.JSONResponse(w, h.schedule.Availability(channel, time.Now()))
 }
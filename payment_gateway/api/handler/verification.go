@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"payment-gateway/api/service"
+	"payment-gateway/models"
+	"shared/apperrors"
+	"shared/pii"
+	"shared/validation"
+)
+
+type VerificationHandler struct {
+	BaseHandler
+	service service.VerificationService
+}
+
+func NewVerificationHandler(service service.VerificationService) *VerificationHandler {
+	return &VerificationHandler{service: service}
+}
+
+func (h VerificationHandler) VerifyAccount(w http.ResponseWriter, r *http.Request) {
+	request := models.AccountVerificationRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.Error(w, r, apperrors.Invalid(err))
+		return
+	}
+	if err := validation.Struct(request); err != nil {
+		h.Error(w, r, err)
+		return
+	}
+	verification, err := h.service.VerifyAccount(r.Context(), request)
+	if err != nil {
+		h.Error(w, r, err)
+		return
+	}
+	h.JSONResponse(w, pii.Visible(r.Context(), verification))
+}
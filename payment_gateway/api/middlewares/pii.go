@@ -0,0 +1,20 @@
+package middlewares
+
+import (
+	"net/http"
+	"payment-gateway/auth"
+	"shared/authn"
+	"shared/pii"
+)
+
+// PIIAccessMiddleware shows account numbers and beneficiary names in full to
+// callers whose roles grant pii:view; everyone else gets them masked. It
+// must run after Authenticate.
+func PIIAccessMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if principal, _ := authn.PrincipalFromContext(r.Context()); auth.Can(principal, auth.PermissionViewPII) {
+			r = r.WithContext(pii.WithAccess(r.Context()))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
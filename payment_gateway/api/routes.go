@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"payment-gateway/api/handler"
 	"payment-gateway/api/middlewares"
+	"payment-gateway/auth"
+	"payment-gateway/metrics"
 
 	"github.com/gorilla/mux"
 )
@@ -13,14 +15,28 @@ func (g *GatewayServer) routes() http.Handler {
 
 	router.Use(middlewares.CorrelationIDMiddleware)
 
+	router.Handle("/metrics", metrics.Handler()).Methods(http.MethodGet)
+
 	subRoute := router.PathPrefix("/api/v1").Subrouter()
+	subRoute.Use(
+		middlewares.TracingMiddleware,
+		middlewares.Authenticate(g.authenticators...),
+		middlewares.PIIAccessMiddleware,
+	)
+
+	// Reads are open to any authenticated caller. Accounts, whose update is
+	// the balance top-up, and channels are managed by admins only.
+	admin := middlewares.RequirePermission(auth.PermissionAdmin)
+	disburse := middlewares.RequirePermission(auth.PermissionDisburse)
 
 	accountHandler := handler.NewAccountHandler(g.serviceFactory.GetAccountService())
 	accountSubRoute := subRoute.PathPrefix("/account").Subrouter()
 
-	accountSubRoute.HandleFunc("", accountHandler.CreateAccount).Methods(http.MethodPost)
+	accountSubRoute.Handle("", admin(http.HandlerFunc(accountHandler.CreateAccount))).
+		Methods(http.MethodPost)
 	accountSubRoute.HandleFunc("", accountHandler.ListAccounts).Methods(http.MethodGet)
-	accountSubRoute.HandleFunc("/{id}", accountHandler.UpdateAccount).Methods(http.MethodPut)
+	accountSubRoute.Handle("/{id}", admin(http.HandlerFunc(accountHandler.UpdateAccount))).
+		Methods(http.MethodPut)
 
 	paymentChannelHandler := handler.NewPaymentChannelHandler(
 		g.serviceFactory.GetPaymentChannelService(),
@@ -28,11 +44,11 @@ func (g *GatewayServer) routes() http.Handler {
 	)
 	paymentChannelSubRoute := subRoute.PathPrefix("/channel").Subrouter()
 
-	paymentChannelSubRoute.HandleFunc("", paymentChannelHandler.CreatePaymentChannel).
+	paymentChannelSubRoute.Handle("", admin(http.HandlerFunc(paymentChannelHandler.CreatePaymentChannel))).
 		Methods(http.MethodPost)
 	paymentChannelSubRoute.HandleFunc("", paymentChannelHandler.ListPaymentChannels).
 		Methods(http.MethodGet)
-	paymentChannelSubRoute.HandleFunc("/{channel}", paymentChannelHandler.UpdatePaymentChannel).
+	paymentChannelSubRoute.Handle("/{channel}", admin(http.HandlerFunc(paymentChannelHandler.UpdatePaymentChannel))).
 		Methods(http.MethodPut)
 	paymentChannelSubRoute.HandleFunc("/{channel}/status", paymentChannelHandler.CheckAvailability).
 		Methods(http.MethodGet)
@@ -40,9 +56,14 @@ func (g *GatewayServer) routes() http.Handler {
 	paymentHandler := handler.NewPaymentHandler(g.serviceFactory.GetPaymentService())
 	paymentSubRoute := subRoute.PathPrefix("/payment").Subrouter()
 
-	paymentSubRoute.HandleFunc("", paymentHandler.ProcessPayment).Methods(http.MethodPost)
+	paymentSubRoute.Handle("", disburse(http.HandlerFunc(paymentHandler.ProcessPayment))).
+		Methods(http.MethodPost)
 	paymentSubRoute.HandleFunc("/{channel}/txn/{id}", paymentHandler.GetTransaction).
 		Methods(http.MethodGet)
 
+	verificationHandler := handler.NewVerificationHandler(g.serviceFactory.GetVerificationService())
+	subRoute.Handle("/verification", disburse(http.HandlerFunc(verificationHandler.VerifyAccount))).
+		Methods(http.MethodPost)
+
 	return router
 }
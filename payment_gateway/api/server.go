@@ -3,6 +3,7 @@ package api
 import (
 	"net/http"
 	"payment-gateway/api/service"
+	"shared/authn"
 
 	"github.com/rs/zerolog/log"
 )
@@ -11,13 +12,20 @@ type GatewayServer struct {
 	port           string
 	server         *http.Server
 	serviceFactory service.ServiceFactory
+	authenticators []authn.Authenticator
 }
 
-func NewGatewayServer(port string, serviceFactory service.ServiceFactory) *GatewayServer {
+// NewGatewayServer serves the API to callers one of authenticators admits.
+func NewGatewayServer(
+	port string,
+	serviceFactory service.ServiceFactory,
+	authenticators ...authn.Authenticator,
+) *GatewayServer {
 	return &GatewayServer{
 		port:           port,
 		server:         nil,
 		serviceFactory: serviceFactory,
+		authenticators: authenticators,
 	}
 }
 
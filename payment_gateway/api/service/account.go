@@ -5,8 +5,11 @@ import (
 	"errors"
 	"payment-gateway/db/daos"
 	"payment-gateway/db/schema"
+	"payment-gateway/failures"
 	"payment-gateway/models"
 	"payment-gateway/utils"
+
+	"gorm.io/gorm"
 )
 
 type AccountService interface {
@@ -40,7 +43,7 @@ func (s *AccountServiceImpl) CreateAccount(
 		return nil, err
 	}
 	if len(accounts) > 0 {
-		return nil, errors.New("account already exists")
+		return nil, failures.ACCOUNT_ALREADY_EXISTS
 	}
 	newAccount, err := s.accountRepo.Create(
 		ctx,
@@ -75,11 +78,14 @@ func (s *AccountServiceImpl) UpdateAccount(
 	account models.UpdateAccountRequest,
 ) (*models.Account, error) {
 	existingAccount, err := s.accountRepo.Get(ctx, accountId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, failures.ACCOUNT_NOT_FOUND
+	}
 	if err != nil {
 		return nil, err
 	}
 	if existingAccount == nil {
-		return nil, errors.New("account not found")
+		return nil, failures.ACCOUNT_NOT_FOUND
 	}
 
 	threshold := existingAccount.Threshold
@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"payment-gateway/db/schema"
+	"payment-gateway/failures"
 	"payment-gateway/models"
 	db_test "payment-gateway/test/db"
 	utils_test "payment-gateway/test/utils"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 )
 
 func TestAccountService_CreateAccount(t *testing.T) {
@@ -376,6 +378,21 @@ func TestAccountService_UpdateAccount(t *testing.T) {
 		mockRepo.AssertNotCalled(t, "Update")
 	})
 
+	t.Run("reports a missing record as account not found", func(t *testing.T) {
+		mockRepo := new(db_test.MockAccountRepository)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+
+		service := NewAccountService(mockRepo, mockIdGenerator)
+
+		mockRepo.On("Get", ctx, "ACC-NONEXISTENT").Return(nil, gorm.ErrRecordNotFound).Once()
+
+		result, err := service.UpdateAccount(ctx, "ACC-NONEXISTENT", models.UpdateAccountRequest{})
+
+		assert.ErrorIs(t, err, failures.ACCOUNT_NOT_FOUND)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "Update")
+	})
+
 	t.Run("returns error when repository get fails", func(t *testing.T) {
 		mockRepo := new(db_test.MockAccountRepository)
 		mockIdGenerator := new(utils_test.MockIdGenerator)
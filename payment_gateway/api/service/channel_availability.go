@@ -2,21 +2,32 @@ package service
 
 import (
 	"math/rand/v2"
+	"payment-gateway/metrics"
 	"payment-gateway/models"
+	"shared/calendar"
 	"time"
 )
 
 type AvailabilitySchedule interface {
 	IsAvailable(channel models.PaymentChannel, time time.Time) bool
+	Availability(channel models.PaymentChannel, time time.Time) models.ChannelAvailabilityResponse
 }
 
-type AvailabilityScheduleImpl struct{}
+type AvailabilityScheduleImpl struct {
+	calendar *calendar.Calendar
+}
 
-func NewAvailabilitySchedule() AvailabilitySchedule {
-	return &AvailabilityScheduleImpl{}
+func NewAvailabilitySchedule(calendar *calendar.Calendar) AvailabilitySchedule {
+	return &AvailabilityScheduleImpl{calendar: calendar}
 }
 
 func (s AvailabilityScheduleImpl) IsAvailable(channel models.PaymentChannel, time time.Time) bool {
+	available := s.isAvailable(channel, time)
+	metrics.RecordAvailability(channel, available)
+	return available
+}
+
+func (s AvailabilityScheduleImpl) isAvailable(channel models.PaymentChannel, time time.Time) bool {
 	switch channel {
 	case models.PaymentChannelUPI:
 		return s.isUPIAvailable()
@@ -28,15 +39,31 @@ func (s AvailabilityScheduleImpl) IsAvailable(channel models.PaymentChannel, tim
 	return false
 }
 
+// Availability adds the NEFT window and settlement batch to IsAvailable so
+// callers can tell when a NEFT payment would actually land.
+func (s AvailabilityScheduleImpl) Availability(
+	channel models.PaymentChannel,
+	at time.Time,
+) models.ChannelAvailabilityResponse {
+	response := models.ChannelAvailabilityResponse{
+		Channel:   channel,
+		Available: s.IsAvailable(channel, at),
+	}
+	if channel == models.PaymentChannelNEFT {
+		nextWindow := s.calendar.NextNEFTOpen(at)
+		settlement := s.calendar.NextNEFTSettlement(at)
+		response.NextWindowAt = &nextWindow
+		response.ExpectedSettlementAt = &settlement
+	}
+	return response
+}
+
 func (s AvailabilityScheduleImpl) isUPIAvailable() bool {
 	return rand.Float64() > 0.09
 }
 
 func (s AvailabilityScheduleImpl) isNEFTAvailable(at time.Time) bool {
-	if at.Weekday() == time.Saturday || at.Weekday() == time.Sunday {
-		return false
-	}
-	return at.Hour() >= 8 && at.Hour() < 19
+	return s.calendar.IsNEFTOpen(at)
 }
 
 func (s AvailabilityScheduleImpl) isIMPSAvailable(at time.Time) bool {
@@ -2,14 +2,28 @@ package service
 
 import (
 	"payment-gateway/models"
+	"shared/calendar"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
+// newTestCalendar keeps the schedule in UTC so the fixtures below read as
+// wall-clock times.
+func newTestCalendar(t *testing.T) *calendar.Calendar {
+	cal, err := calendar.New(calendar.Config{
+		Timezone: "UTC",
+		Holidays: []calendar.Holiday{{Date: "2024-01-26", Name: "Republic Day"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cal
+}
+
 func TestAvailabilitySchedule_IsAvailable(t *testing.T) {
-	service := NewAvailabilitySchedule()
+	service := NewAvailabilitySchedule(newTestCalendar(t))
 	testTime := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC) // Monday, Jan 15, 12:00 PM
 
 	t.Run("returns false for unknown channel", func(t *testing.T) {
@@ -37,7 +51,7 @@ func TestAvailabilitySchedule_IsAvailable(t *testing.T) {
 }
 
 func TestAvailabilitySchedule_isUPIAvailable(t *testing.T) {
-	service := NewAvailabilitySchedule().(*AvailabilityScheduleImpl)
+	service := NewAvailabilitySchedule(newTestCalendar(t)).(*AvailabilityScheduleImpl)
 
 	t.Run("returns boolean value based on random probability", func(t *testing.T) {
 		results := make(map[bool]int)
@@ -59,14 +73,26 @@ func TestAvailabilitySchedule_isUPIAvailable(t *testing.T) {
 }
 
 func TestAvailabilitySchedule_isNEFTAvailable(t *testing.T) {
-	service := NewAvailabilitySchedule().(*AvailabilityScheduleImpl)
+	service := NewAvailabilitySchedule(newTestCalendar(t)).(*AvailabilityScheduleImpl)
 
-	t.Run("returns false on Saturday", func(t *testing.T) {
+	t.Run("returns false on second Saturday", func(t *testing.T) {
 		saturday := time.Date(2024, 1, 13, 12, 0, 0, 0, time.UTC) // Saturday, Jan 13, 12:00 PM
 		result := service.isNEFTAvailable(saturday)
 		assert.False(t, result)
 	})
 
+	t.Run("returns true on third Saturday during business hours", func(t *testing.T) {
+		saturday := time.Date(2024, 1, 20, 12, 0, 0, 0, time.UTC) // Saturday, Jan 20, 12:00 PM
+		result := service.isNEFTAvailable(saturday)
+		assert.True(t, result)
+	})
+
+	t.Run("returns false on bank holiday", func(t *testing.T) {
+		holiday := time.Date(2024, 1, 26, 12, 0, 0, 0, time.UTC) // Friday, Jan 26, 12:00 PM
+		result := service.isNEFTAvailable(holiday)
+		assert.False(t, result)
+	})
+
 	t.Run("returns false on Sunday", func(t *testing.T) {
 		sunday := time.Date(2024, 1, 14, 12, 0, 0, 0, time.UTC) // Sunday, Jan 14, 12:00 PM
 		result := service.isNEFTAvailable(sunday)
@@ -139,8 +165,43 @@ func TestAvailabilitySchedule_isNEFTAvailable(t *testing.T) {
 	})
 }
 
+func TestAvailabilitySchedule_Availability(t *testing.T) {
+	service := NewAvailabilitySchedule(newTestCalendar(t))
+
+	t.Run("includes next window and settlement for NEFT", func(t *testing.T) {
+		fridayEvening := time.Date(2024, 1, 12, 20, 0, 0, 0, time.UTC) // Friday, Jan 12, 8:00 PM
+
+		result := service.Availability(models.PaymentChannelNEFT, fridayEvening)
+
+		assert.False(t, result.Available)
+		// Saturday the 13th is the second Saturday, so the next window is Monday.
+		assert.Equal(t, time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC), *result.NextWindowAt)
+		assert.Equal(t, time.Date(2024, 1, 15, 8, 30, 0, 0, time.UTC), *result.ExpectedSettlementAt)
+	})
+
+	t.Run("settles in the next half-hourly batch while open", func(t *testing.T) {
+		mondayMorning := time.Date(2024, 1, 15, 10, 10, 0, 0, time.UTC) // Monday, 10:10 AM
+
+		result := service.Availability(models.PaymentChannelNEFT, mondayMorning)
+
+		assert.True(t, result.Available)
+		assert.Equal(t, mondayMorning, *result.NextWindowAt)
+		assert.Equal(t, time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC), *result.ExpectedSettlementAt)
+	})
+
+	t.Run("omits settlement for instant channels", func(t *testing.T) {
+		monday := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+		result := service.Availability(models.PaymentChannelIMPS, monday)
+
+		assert.True(t, result.Available)
+		assert.Nil(t, result.NextWindowAt)
+		assert.Nil(t, result.ExpectedSettlementAt)
+	})
+}
+
 func TestAvailabilitySchedule_isIMPSAvailable(t *testing.T) {
-	service := NewAvailabilitySchedule().(*AvailabilityScheduleImpl)
+	service := NewAvailabilitySchedule(newTestCalendar(t)).(*AvailabilityScheduleImpl)
 
 	t.Run("returns true on Monday", func(t *testing.T) {
 		monday := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC) // Monday, Jan 15, 12:00 PM
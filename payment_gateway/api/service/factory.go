@@ -4,6 +4,8 @@ import (
 	"payment-gateway/db"
 	"payment-gateway/models"
 	"payment-gateway/utils"
+	"shared/calendar"
+	"shared/ifsc"
 )
 
 type ServiceFactory interface {
@@ -11,6 +13,7 @@ type ServiceFactory interface {
 	GetPaymentChannelService() PaymentChannelService
 	GetAvailabilitySchedule() AvailabilitySchedule
 	GetPaymentService() PaymentService
+	GetVerificationService() VerificationService
 }
 
 type ServiceFactoryImpl struct {
@@ -18,25 +21,38 @@ type ServiceFactoryImpl struct {
 	paymentChannelService PaymentChannelService
 	availabilitySchedule  AvailabilitySchedule
 	paymentService        PaymentService
+	verificationService   VerificationService
 }
 
 func NewServiceFactory(
 	db *db.Database,
 	processor chan models.ProcessorMessage,
 	idGenerator utils.IdGenerator,
+	calendar *calendar.Calendar,
+	directory *ifsc.Directory,
 ) ServiceFactory {
+	schedule := NewAvailabilitySchedule(calendar)
 	return &ServiceFactoryImpl{
 		accountService: NewAccountService(db.GetAccountRepository(), idGenerator),
 		paymentChannelService: NewPaymentChannelService(
 			db.GetPaymentChannelRepository(),
 			idGenerator,
 		),
-		availabilitySchedule: NewAvailabilitySchedule(),
+		availabilitySchedule: schedule,
 		paymentService: NewPaymentService(
 			processor,
 			db.GetPaymentChannelRepository(),
 			db.GetTransactionRepository(),
 			idGenerator,
+			directory,
+		),
+		verificationService: NewVerificationService(
+			processor,
+			db.GetPaymentChannelRepository(),
+			db.GetTransactionRepository(),
+			idGenerator,
+			directory,
+			schedule,
 		),
 	}
 }
@@ -56,3 +72,7 @@ func (f *ServiceFactoryImpl) GetAvailabilitySchedule() AvailabilitySchedule {
 func (f *ServiceFactoryImpl) GetPaymentService() PaymentService {
 	return f.paymentService
 }
+
+func (f *ServiceFactoryImpl) GetVerificationService() VerificationService {
+	return f.verificationService
+}
@@ -10,6 +10,8 @@ import (
 	"payment-gateway/models"
 	"payment-gateway/payment"
 	"payment-gateway/utils"
+	"shared/ifsc"
+	"shared/logging"
 	"slices"
 
 	"github.com/rs/zerolog/log"
@@ -31,6 +33,7 @@ type PaymentServiceImpl struct {
 	transaction     daos.TransactionRepository
 	idGenerator     utils.IdGenerator
 	processor       chan models.ProcessorMessage
+	directory       *ifsc.Directory
 }
 
 func NewPaymentService(
@@ -38,12 +41,14 @@ func NewPaymentService(
 	paymentChannel daos.PaymentChannelRepository,
 	transactionRepository daos.TransactionRepository,
 	idGenerator utils.IdGenerator,
+	directory *ifsc.Directory,
 ) PaymentService {
 	return &PaymentServiceImpl{
 		processor:      processor,
 		paymentChannel: paymentChannel,
 		transaction:    transactionRepository,
 		idGenerator:    idGenerator,
+		directory:      directory,
 	}
 }
 
@@ -51,20 +56,27 @@ func (s *PaymentServiceImpl) Process(
 	ctx context.Context,
 	request models.PaymentRequest,
 ) (*models.Transaction, error) {
+	fields := logging.Metadata(request.Metadata)
+	fields.ReferenceId = request.ReferenceID
+	fields.Channel = string(request.Channel)
+	ctx = logging.With(ctx, fields)
+
 	existingTransaction, err := s.transaction.GetByReferenceID(ctx, request.ReferenceID)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		log.Error().Msgf("failed to get transaction: %s", err)
+		log.Ctx(ctx).Error().Err(err).Msg("failed to get transaction")
 		return nil, fmt.Errorf("failed to get transaction: %s", err)
 	}
 
 	if existingTransaction != nil {
-		log.Warn().Msgf("transaction already exists: %s", request.ReferenceID)
+		log.Ctx(ctx).Warn().
+			Str("existing_transaction_id", existingTransaction.ID).
+			Msg("transaction already exists")
 		return nil, failures.REFERENCE_ID_ALREADY_PROCESSED
 	}
 
-	err = s.validateBeneficiary(request.Beneficiary)
+	branch, err := validateBeneficiary(s.directory, &request.Beneficiary)
 	if err != nil {
-		log.Error().Msgf("beneficiary validation failed: %s", err)
+		log.Ctx(ctx).Error().Err(err).Msg("beneficiary validation failed")
 		return nil, err
 	}
 
@@ -73,6 +85,11 @@ func (s *PaymentServiceImpl) Process(
 		return nil, err
 	}
 
+	if !branch.Supports(string(request.Channel)) {
+		log.Ctx(ctx).Warn().Str("ifsc", branch.IFSC).Msg("branch does not take payments on channel")
+		return nil, failures.CHANNEL_NOT_SUPPORTED
+	}
+
 	paymentProvider, err := s.newPaymentProvider(paymentChannel)
 	if err != nil {
 		return nil, err
@@ -80,6 +97,10 @@ func (s *PaymentServiceImpl) Process(
 
 	err = paymentProvider.ValidateLimit(request.Amount)
 	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).
+			Float64("amount", request.Amount).
+			Float64("limit", paymentChannel.Limit).
+			Msg("payment rejected")
 		return nil, err
 	}
 
@@ -91,6 +112,7 @@ func (p *PaymentServiceImpl) GetTransaction(
 	channel models.PaymentChannel,
 	transactionID string,
 ) (*models.Transaction, error) {
+	ctx = logging.With(ctx, logging.Fields{TransactionId: transactionID, Channel: string(channel)})
 	paymentChannel, err := p.getPaymentChannel(ctx, channel)
 	if err != nil {
 		return nil, err
@@ -104,16 +126,25 @@ func (p *PaymentServiceImpl) GetTransaction(
 	return paymentProvider.GetTransaction(ctx, transactionID)
 }
 
-func (p *PaymentServiceImpl) validateBeneficiary(beneficiary models.Beneficiary) error {
-	if slices.Contains(failures.INVALID_IFSC_CODES, beneficiary.IFSC) {
-		return failures.INVALID_IFSC
+// validateBeneficiary finds the beneficiary's branch in the IFSC directory
+// and names the bank after it, whatever bank the caller gave.
+func validateBeneficiary(
+	directory *ifsc.Directory,
+	beneficiary *models.Beneficiary,
+) (ifsc.Branch, error) {
+	branch, ok := directory.Resolve(beneficiary.IFSC)
+	if !ok {
+		return ifsc.Branch{}, failures.INVALID_IFSC
 	}
 
 	if slices.Contains(failures.INVALID_ACCOUNT_NUMBERS, beneficiary.Account) {
-		return failures.INACTIVE_ACCOUNT
+		return ifsc.Branch{}, failures.INACTIVE_ACCOUNT
 	}
 
-	return nil
+	if branch.Bank != "" {
+		beneficiary.Bank = branch.Bank
+	}
+	return branch, nil
 }
 
 func (p *PaymentServiceImpl) getPaymentChannel(
@@ -123,10 +154,10 @@ func (p *PaymentServiceImpl) getPaymentChannel(
 	paymentChannel, err := p.paymentChannel.Get(ctx, channel)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Error().Msgf("invalid payment channel: %s", channel)
+			log.Ctx(ctx).Error().Msg("invalid payment channel")
 			return nil, failures.INVALID_PAYMENT_CHANNEL
 		}
-		log.Error().Msgf("failed to get payment channel: %s", err)
+		log.Ctx(ctx).Error().Err(err).Msg("failed to get payment channel")
 		return nil, fmt.Errorf("failed to get payment channel: %s", err)
 	}
 	return paymentChannel, nil
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"payment-gateway/db/daos"
 	"payment-gateway/db/schema"
+	"payment-gateway/failures"
 	"payment-gateway/models"
 	"payment-gateway/utils"
 
@@ -48,7 +49,7 @@ func (s *PaymentChannelServiceImpl) CreatePaymentChannel(
 		return nil, errors.New("failed to get payment channel")
 	}
 	if existingPaymentChannel != nil {
-		return nil, errors.New("payment channel already exists")
+		return nil, failures.PAYMENT_CHANNEL_ALREADY_EXISTS
 	}
 	id := s.idGenerator.GeneratePaymentChannelId()
 	newPaymentChannel, err := s.paymentChannelRepo.Create(
@@ -71,11 +72,14 @@ func (s *PaymentChannelServiceImpl) UpdatePaymentChannel(
 	paymentChannel models.UpdatePaymentChannelRequest,
 ) (*models.PaymentChannelResponse, error) {
 	existingPaymentChannel, err := s.paymentChannelRepo.Get(ctx, channel)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, failures.PAYMENT_CHANNEL_NOT_FOUND
+	}
 	if err != nil {
 		return nil, err
 	}
 	if existingPaymentChannel == nil {
-		return nil, errors.New("payment channel not found")
+		return nil, failures.PAYMENT_CHANNEL_NOT_FOUND
 	}
 	updatedPaymentChannel, err := s.paymentChannelRepo.Update(
 		ctx,
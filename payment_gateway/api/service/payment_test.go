@@ -8,6 +8,7 @@ import (
 	"payment-gateway/models"
 	db_test "payment-gateway/test/db"
 	utils_test "payment-gateway/test/utils"
+	"shared/ifsc"
 	"testing"
 	"time"
 
@@ -16,6 +17,17 @@ import (
 	"gorm.io/gorm"
 )
 
+func newTestDirectory(t *testing.T) *ifsc.Directory {
+	directory, err := ifsc.New([]ifsc.Branch{
+		{IFSC: "HDFC0001234", Bank: "HDFC Bank", Branch: "Andheri East", NEFT: true, IMPS: true, UPI: true},
+		{IFSC: "SBIN0001234", Bank: "State Bank of India", Branch: "Bandra West", NEFT: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return directory
+}
+
 func TestPaymentService_Process(t *testing.T) {
 	ctx := context.Background()
 
@@ -30,6 +42,7 @@ func TestPaymentService_Process(t *testing.T) {
 			mockPaymentChannel,
 			mockTransaction,
 			mockIdGenerator,
+			nil,
 		)
 
 		request := models.PaymentRequest{
@@ -64,11 +77,11 @@ func TestPaymentService_Process(t *testing.T) {
 			Status:      models.TransactionStatusInitiated,
 		}
 
-		mockTransaction.On("GetByReferenceID", ctx, request.ReferenceID).
+		mockTransaction.On("GetByReferenceID", mock.Anything, request.ReferenceID).
 			Return(nil, nil).Once()
-		mockPaymentChannel.On("Get", ctx, request.Channel).
+		mockPaymentChannel.On("Get", mock.Anything, request.Channel).
 			Return(paymentChannel, nil).Once()
-		mockTransaction.On("Create", ctx, mock.AnythingOfType("schema.Transaction")).
+		mockTransaction.On("Create", mock.Anything, mock.AnythingOfType("schema.Transaction")).
 			Return(schema.Transaction{
 				ID:              expectedTransaction.ID,
 				ReferenceID:     expectedTransaction.ReferenceID,
@@ -107,6 +120,7 @@ func TestPaymentService_Process(t *testing.T) {
 			mockPaymentChannel,
 			mockTransaction,
 			mockIdGenerator,
+			nil,
 		)
 
 		request := models.PaymentRequest{
@@ -126,7 +140,7 @@ func TestPaymentService_Process(t *testing.T) {
 			ReferenceID: request.ReferenceID,
 		}
 
-		mockTransaction.On("GetByReferenceID", ctx, request.ReferenceID).
+		mockTransaction.On("GetByReferenceID", mock.Anything, request.ReferenceID).
 			Return(existingTransaction, nil).Once()
 
 		result, err := service.Process(ctx, request)
@@ -150,6 +164,7 @@ func TestPaymentService_Process(t *testing.T) {
 			mockPaymentChannel,
 			mockTransaction,
 			mockIdGenerator,
+			nil,
 		)
 
 		request := models.PaymentRequest{
@@ -166,7 +181,7 @@ func TestPaymentService_Process(t *testing.T) {
 
 		repoError := errors.New("database error")
 
-		mockTransaction.On("GetByReferenceID", ctx, request.ReferenceID).
+		mockTransaction.On("GetByReferenceID", mock.Anything, request.ReferenceID).
 			Return(nil, repoError).Once()
 
 		result, err := service.Process(ctx, request)
@@ -190,6 +205,7 @@ func TestPaymentService_Process(t *testing.T) {
 			mockPaymentChannel,
 			mockTransaction,
 			mockIdGenerator,
+			newTestDirectory(t),
 		)
 
 		request := models.PaymentRequest{
@@ -199,12 +215,12 @@ func TestPaymentService_Process(t *testing.T) {
 			Beneficiary: models.Beneficiary{
 				Name:    "John Doe",
 				Account: "1234567890",
-				IFSC:    "INVALID_IFSC_CODE_1",
+				IFSC:    "HDFC0009999",
 				Bank:    "Test Bank",
 			},
 		}
 
-		mockTransaction.On("GetByReferenceID", ctx, request.ReferenceID).
+		mockTransaction.On("GetByReferenceID", mock.Anything, request.ReferenceID).
 			Return(nil, nil).Once()
 
 		result, err := service.Process(ctx, request)
@@ -217,6 +233,89 @@ func TestPaymentService_Process(t *testing.T) {
 		mockPaymentChannel.AssertNotCalled(t, "Get")
 	})
 
+	t.Run("returns error for a channel the branch does not support", func(t *testing.T) {
+		mockPaymentChannel := new(db_test.MockPaymentChannelRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+		processor := make(chan models.ProcessorMessage, 1)
+
+		service := NewPaymentService(
+			processor,
+			mockPaymentChannel,
+			mockTransaction,
+			mockIdGenerator,
+			newTestDirectory(t),
+		)
+
+		request := models.PaymentRequest{
+			ReferenceID: "REF-123",
+			Amount:      5000.0,
+			Channel:     models.PaymentChannelUPI,
+			Beneficiary: models.Beneficiary{
+				Name:    "John Doe",
+				Account: "1234567890",
+				IFSC:    "SBIN0001234",
+			},
+		}
+
+		mockTransaction.On("GetByReferenceID", mock.Anything, request.ReferenceID).
+			Return(nil, nil).Once()
+		mockPaymentChannel.On("Get", mock.Anything, request.Channel).
+			Return(&schema.PaymentChannel{Id: "CH-001", Name: models.PaymentChannelUPI, Limit: 100000.0}, nil).Once()
+
+		result, err := service.Process(ctx, request)
+
+		assert.Nil(t, result)
+		assert.Equal(t, failures.CHANNEL_NOT_SUPPORTED, err)
+		mockTransaction.AssertNotCalled(t, "Create")
+	})
+
+	t.Run("names the bank after the branch", func(t *testing.T) {
+		mockPaymentChannel := new(db_test.MockPaymentChannelRepository)
+		mockTransaction := new(db_test.MockTransactionRepository)
+		mockIdGenerator := new(utils_test.MockIdGenerator)
+		processor := make(chan models.ProcessorMessage, 1)
+
+		service := NewPaymentService(
+			processor,
+			mockPaymentChannel,
+			mockTransaction,
+			mockIdGenerator,
+			newTestDirectory(t),
+		)
+
+		request := models.PaymentRequest{
+			ReferenceID: "REF-123",
+			Amount:      5000.0,
+			Channel:     models.PaymentChannelUPI,
+			Beneficiary: models.Beneficiary{
+				Name:    "John Doe",
+				Account: "1234567890",
+				IFSC:    "HDFC0001234",
+				Bank:    "Hdfc",
+			},
+		}
+
+		mockTransaction.On("GetByReferenceID", mock.Anything, request.ReferenceID).
+			Return(nil, nil).Once()
+		mockPaymentChannel.On("Get", mock.Anything, request.Channel).
+			Return(&schema.PaymentChannel{Id: "CH-001", Name: models.PaymentChannelUPI, Limit: 100000.0}, nil).Once()
+		mockTransaction.On("Create", mock.Anything, mock.MatchedBy(func(txn schema.Transaction) bool {
+			return txn.BankName == "HDFC Bank"
+		})).Return(schema.Transaction{
+			ID:          "UPI-TXN-123456789012",
+			ReferenceID: request.ReferenceID,
+			BankName:    "HDFC Bank",
+			Status:      models.TransactionStatusInitiated,
+		}, nil).Once()
+
+		result, err := service.Process(ctx, request)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "HDFC Bank", result.Beneficiary.Bank)
+		mockTransaction.AssertExpectations(t)
+	})
+
 	t.Run("returns error for inactive account", func(t *testing.T) {
 		mockPaymentChannel := new(db_test.MockPaymentChannelRepository)
 		mockTransaction := new(db_test.MockTransactionRepository)
@@ -228,6 +327,7 @@ func TestPaymentService_Process(t *testing.T) {
 			mockPaymentChannel,
 			mockTransaction,
 			mockIdGenerator,
+			nil,
 		)
 
 		request := models.PaymentRequest{
@@ -242,7 +342,7 @@ func TestPaymentService_Process(t *testing.T) {
 			},
 		}
 
-		mockTransaction.On("GetByReferenceID", ctx, request.ReferenceID).
+		mockTransaction.On("GetByReferenceID", mock.Anything, request.ReferenceID).
 			Return(nil, nil).Once()
 
 		result, err := service.Process(ctx, request)
@@ -266,6 +366,7 @@ func TestPaymentService_Process(t *testing.T) {
 			mockPaymentChannel,
 			mockTransaction,
 			mockIdGenerator,
+			nil,
 		)
 
 		request := models.PaymentRequest{
@@ -280,9 +381,9 @@ func TestPaymentService_Process(t *testing.T) {
 			},
 		}
 
-		mockTransaction.On("GetByReferenceID", ctx, request.ReferenceID).
+		mockTransaction.On("GetByReferenceID", mock.Anything, request.ReferenceID).
 			Return(nil, nil).Once()
-		mockPaymentChannel.On("Get", ctx, request.Channel).
+		mockPaymentChannel.On("Get", mock.Anything, request.Channel).
 			Return(nil, gorm.ErrRecordNotFound).Once()
 
 		result, err := service.Process(ctx, request)
@@ -306,6 +407,7 @@ func TestPaymentService_Process(t *testing.T) {
 			mockPaymentChannel,
 			mockTransaction,
 			mockIdGenerator,
+			nil,
 		)
 
 		request := models.PaymentRequest{
@@ -322,9 +424,9 @@ func TestPaymentService_Process(t *testing.T) {
 
 		repoError := errors.New("database connection error")
 
-		mockTransaction.On("GetByReferenceID", ctx, request.ReferenceID).
+		mockTransaction.On("GetByReferenceID", mock.Anything, request.ReferenceID).
 			Return(nil, nil).Once()
-		mockPaymentChannel.On("Get", ctx, request.Channel).
+		mockPaymentChannel.On("Get", mock.Anything, request.Channel).
 			Return(nil, repoError).Once()
 
 		result, err := service.Process(ctx, request)
@@ -348,6 +450,7 @@ func TestPaymentService_Process(t *testing.T) {
 			mockPaymentChannel,
 			mockTransaction,
 			mockIdGenerator,
+			nil,
 		)
 
 		request := models.PaymentRequest{
@@ -370,9 +473,9 @@ func TestPaymentService_Process(t *testing.T) {
 			Fee:         5.0,
 		}
 
-		mockTransaction.On("GetByReferenceID", ctx, request.ReferenceID).
+		mockTransaction.On("GetByReferenceID", mock.Anything, request.ReferenceID).
 			Return(nil, nil).Once()
-		mockPaymentChannel.On("Get", ctx, request.Channel).
+		mockPaymentChannel.On("Get", mock.Anything, request.Channel).
 			Return(paymentChannel, nil).Once()
 
 		result, err := service.Process(ctx, request)
@@ -407,6 +510,7 @@ func TestPaymentService_Process(t *testing.T) {
 					mockPaymentChannel,
 					mockTransaction,
 					mockIdGenerator,
+					nil,
 				)
 
 				request := models.PaymentRequest{
@@ -429,11 +533,11 @@ func TestPaymentService_Process(t *testing.T) {
 					Fee:         5.0,
 				}
 
-				mockTransaction.On("GetByReferenceID", ctx, request.ReferenceID).
+				mockTransaction.On("GetByReferenceID", mock.Anything, request.ReferenceID).
 					Return(nil, nil).Once()
-				mockPaymentChannel.On("Get", ctx, request.Channel).
+				mockPaymentChannel.On("Get", mock.Anything, request.Channel).
 					Return(paymentChannel, nil).Once()
-				mockTransaction.On("Create", ctx, mock.AnythingOfType("schema.Transaction")).
+				mockTransaction.On("Create", mock.Anything, mock.AnythingOfType("schema.Transaction")).
 					Return(schema.Transaction{
 						ID:              "TXN-123",
 						ReferenceID:     request.ReferenceID,
@@ -476,6 +580,7 @@ func TestPaymentService_GetTransaction(t *testing.T) {
 			mockPaymentChannel,
 			mockTransaction,
 			mockIdGenerator,
+			nil,
 		)
 
 		channel := models.PaymentChannelUPI
@@ -498,9 +603,9 @@ func TestPaymentService_GetTransaction(t *testing.T) {
 			Status:      models.TransactionStatusSuccess,
 		}
 
-		mockPaymentChannel.On("Get", ctx, channel).
+		mockPaymentChannel.On("Get", mock.Anything, channel).
 			Return(paymentChannel, nil).Once()
-		mockTransaction.On("Get", ctx, transactionID).
+		mockTransaction.On("Get", mock.Anything, transactionID).
 			Return(schema.Transaction{
 				ID:              transactionID,
 				ReferenceID:     expectedTransaction.ReferenceID,
@@ -538,12 +643,13 @@ func TestPaymentService_GetTransaction(t *testing.T) {
 			mockPaymentChannel,
 			mockTransaction,
 			mockIdGenerator,
+			nil,
 		)
 
 		channel := models.PaymentChannelUPI
 		transactionID := "UPI-TXN-123456789012"
 
-		mockPaymentChannel.On("Get", ctx, channel).
+		mockPaymentChannel.On("Get", mock.Anything, channel).
 			Return(nil, gorm.ErrRecordNotFound).Once()
 
 		result, err := service.GetTransaction(ctx, channel, transactionID)
@@ -567,6 +673,7 @@ func TestPaymentService_GetTransaction(t *testing.T) {
 			mockPaymentChannel,
 			mockTransaction,
 			mockIdGenerator,
+			nil,
 		)
 
 		channel := models.PaymentChannelUPI
@@ -574,7 +681,7 @@ func TestPaymentService_GetTransaction(t *testing.T) {
 
 		repoError := errors.New("database connection error")
 
-		mockPaymentChannel.On("Get", ctx, channel).
+		mockPaymentChannel.On("Get", mock.Anything, channel).
 			Return(nil, repoError).Once()
 
 		result, err := service.GetTransaction(ctx, channel, transactionID)
@@ -598,6 +705,7 @@ func TestPaymentService_GetTransaction(t *testing.T) {
 			mockPaymentChannel,
 			mockTransaction,
 			mockIdGenerator,
+			nil,
 		)
 
 		channel := models.PaymentChannelUPI
@@ -611,9 +719,9 @@ func TestPaymentService_GetTransaction(t *testing.T) {
 			Fee:         5.0,
 		}
 
-		mockPaymentChannel.On("Get", ctx, channel).
+		mockPaymentChannel.On("Get", mock.Anything, channel).
 			Return(paymentChannel, nil).Once()
-		mockTransaction.On("Get", ctx, transactionID).
+		mockTransaction.On("Get", mock.Anything, transactionID).
 			Return(schema.Transaction{}, gorm.ErrRecordNotFound).Once()
 
 		result, err := service.GetTransaction(ctx, channel, transactionID)
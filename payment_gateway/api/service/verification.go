@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"payment-gateway/db/daos"
+	"payment-gateway/db/schema"
+	"payment-gateway/failures"
+	"payment-gateway/models"
+	"payment-gateway/payment"
+	"payment-gateway/utils"
+	"shared/ifsc"
+	"shared/logging"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+type VerificationService interface {
+	VerifyAccount(
+		ctx context.Context,
+		request models.AccountVerificationRequest,
+	) (*models.AccountVerificationResponse, error)
+}
+
+const (
+	// pennyDropPoll is how often VerifyAccount checks whether the transfer
+	// has settled.
+	pennyDropPoll = 100 * time.Millisecond
+	// pennyDropTimeout bounds the wait for the processor to settle it.
+	pennyDropTimeout = 10 * time.Second
+)
+
+type VerificationServiceImpl struct {
+	processor      chan models.ProcessorMessage
+	paymentChannel daos.PaymentChannelRepository
+	transaction    daos.TransactionRepository
+	idGenerator    utils.IdGenerator
+	directory      *ifsc.Directory
+	schedule       AvailabilitySchedule
+}
+
+func NewVerificationService(
+	processor chan models.ProcessorMessage,
+	paymentChannel daos.PaymentChannelRepository,
+	transaction daos.TransactionRepository,
+	idGenerator utils.IdGenerator,
+	directory *ifsc.Directory,
+	schedule AvailabilitySchedule,
+) VerificationService {
+	return &VerificationServiceImpl{
+		processor:      processor,
+		paymentChannel: paymentChannel,
+		transaction:    transaction,
+		idGenerator:    idGenerator,
+		directory:      directory,
+		schedule:       schedule,
+	}
+}
+
+// VerifyAccount performs a penny drop: a ₹1 IMPS transfer to the beneficiary
+// that returns the name the bank has on record. The transfer goes through
+// the IMPS provider like any payment, so the processor debits it and its fee
+// from the account, and the call waits for it to settle.
+func (s *VerificationServiceImpl) VerifyAccount(
+	ctx context.Context,
+	request models.AccountVerificationRequest,
+) (*models.AccountVerificationResponse, error) {
+	ctx = logging.With(ctx, logging.Fields{
+		ReferenceId: request.ReferenceID,
+		Channel:     string(models.PaymentChannelIMPS),
+	})
+	existingTransaction, err := s.transaction.GetByReferenceID(ctx, request.ReferenceID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to get transaction")
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	if existingTransaction != nil {
+		log.Ctx(ctx).Warn().
+			Str("existing_transaction_id", existingTransaction.ID).
+			Msg("transaction already exists")
+		return nil, failures.REFERENCE_ID_ALREADY_PROCESSED
+	}
+
+	branch, err := validateBeneficiary(s.directory, &request.Beneficiary)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("beneficiary validation failed")
+		return nil, err
+	}
+	if !branch.Supports(string(models.PaymentChannelIMPS)) {
+		log.Ctx(ctx).Warn().Str("ifsc", branch.IFSC).Msg("branch does not take payments on channel")
+		return nil, failures.CHANNEL_NOT_SUPPORTED
+	}
+	if !s.schedule.IsAvailable(models.PaymentChannelIMPS, time.Now()) {
+		log.Ctx(ctx).Warn().Msg("IMPS is not available, refusing penny drop")
+		return nil, failures.CHANNEL_UNAVAILABLE
+	}
+
+	paymentChannel, err := s.paymentChannel.Get(ctx, models.PaymentChannelIMPS)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Ctx(ctx).Error().Msg("invalid payment channel")
+			return nil, failures.INVALID_PAYMENT_CHANNEL
+		}
+		log.Ctx(ctx).Error().Err(err).Msg("failed to get payment channel")
+		return nil, fmt.Errorf("failed to get payment channel: %w", err)
+	}
+
+	provider, err := payment.NewPaymentProvider(paymentChannel, s.processor, s.transaction, s.idGenerator)
+	if err != nil {
+		return nil, err
+	}
+	if err := provider.ValidateLimit(models.PennyDropAmount); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Float64("limit", paymentChannel.Limit).Msg("penny drop rejected")
+		return nil, err
+	}
+
+	registeredName := registeredAccountHolder(request.Beneficiary)
+	transaction, err := provider.Transfer(ctx, models.PaymentRequest{
+		ReferenceID: request.ReferenceID,
+		Amount:      models.PennyDropAmount,
+		Channel:     models.PaymentChannelIMPS,
+		Beneficiary: request.Beneficiary,
+		Metadata: map[string]any{
+			"purpose":         models.PennyDropPurpose,
+			"registered_name": registeredName,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	ctx = logging.With(ctx, logging.Fields{TransactionId: transaction.ID})
+
+	settled, err := s.settle(ctx, transaction.ID)
+	if err != nil {
+		return nil, err
+	}
+	if settled.Status != models.TransactionStatusSuccess {
+		reason := settlementFailure(settled.Message)
+		log.Ctx(ctx).Warn().Err(reason).Msg("penny drop failed")
+		return nil, reason
+	}
+	log.Ctx(ctx).Info().Msg("penny drop settled")
+
+	verifiedAt := settled.UpdatedAt
+	if settled.ProcessedAt != nil {
+		verifiedAt = *settled.ProcessedAt
+	}
+	return &models.AccountVerificationResponse{
+		TransactionID:  settled.ID,
+		ReferenceID:    settled.ReferenceID,
+		Account:        settled.AccountNumber,
+		IFSC:           settled.IFSCCode,
+		RegisteredName: registeredName,
+		Status:         settled.Status,
+		VerifiedAt:     verifiedAt,
+	}, nil
+}
+
+// settle waits for the processor to move the transaction to success or
+// failed.
+func (s *VerificationServiceImpl) settle(ctx context.Context, transactionId string) (*schema.Transaction, error) {
+	ctx, cancel := context.WithTimeout(ctx, pennyDropTimeout)
+	defer cancel()
+	ticker := time.NewTicker(pennyDropPoll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Ctx(ctx).Warn().Msg("penny drop did not settle in time")
+			return nil, failures.VERIFICATION_TIMEOUT
+		case <-ticker.C:
+		}
+		transaction, err := s.transaction.Get(ctx, transactionId)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to get transaction")
+			return nil, fmt.Errorf("failed to get transaction: %w", err)
+		}
+		if transaction.Status == models.TransactionStatusSuccess ||
+			transaction.Status == models.TransactionStatusFailed {
+			return &transaction, nil
+		}
+	}
+}
+
+// settlementFailure returns the failure the processor recorded on a failed
+// transaction, so the caller gets the same error as for any other payment.
+func settlementFailure(message *string) error {
+	if message == nil {
+		return failures.UNKNOWN_ERROR
+	}
+	for _, failure := range failures.TRANSACTION_FAILURES {
+		if failure.Error() == *message {
+			return failure
+		}
+	}
+	if *message == failures.INSUFFICIENT_BALANCE.Error() {
+		return failures.INSUFFICIENT_BALANCE
+	}
+	return errors.New(*message)
+}
+
+// registeredAccountHolder returns the holder name the simulated bank has on
+// record for the account, or no name when it has none. The name supplied by
+// the remitter is never echoed back, as it would always match itself.
+func registeredAccountHolder(beneficiary models.Beneficiary) string {
+	return failures.REGISTERED_ACCOUNT_HOLDERS[beneficiary.Account]
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"payment-gateway/db/schema"
+	"payment-gateway/failures"
+	"payment-gateway/models"
+	db_test "payment-gateway/test/db"
+	utils_test "payment-gateway/test/utils"
+	"shared/ifsc"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// fixedSchedule reports every channel as available or not, whatever the time.
+type fixedSchedule bool
+
+func (s fixedSchedule) IsAvailable(models.PaymentChannel, time.Time) bool {
+	return bool(s)
+}
+
+func (s fixedSchedule) Availability(channel models.PaymentChannel, _ time.Time) models.ChannelAvailabilityResponse {
+	return models.ChannelAvailabilityResponse{Channel: channel, Available: bool(s)}
+}
+
+type verificationMocks struct {
+	processor      chan models.ProcessorMessage
+	paymentChannel *db_test.MockPaymentChannelRepository
+	transaction    *db_test.MockTransactionRepository
+}
+
+func newVerificationService(directory *ifsc.Directory, available bool) (VerificationService, verificationMocks) {
+	mocks := verificationMocks{
+		processor:      make(chan models.ProcessorMessage, 1),
+		paymentChannel: new(db_test.MockPaymentChannelRepository),
+		transaction:    new(db_test.MockTransactionRepository),
+	}
+	service := NewVerificationService(
+		mocks.processor,
+		mocks.paymentChannel,
+		mocks.transaction,
+		new(utils_test.MockIdGenerator),
+		directory,
+		fixedSchedule(available),
+	)
+	return service, mocks
+}
+
+func TestVerificationService_VerifyAccount(t *testing.T) {
+	ctx := context.Background()
+
+	impsChannel := &schema.PaymentChannel{
+		Id:          "CH-002",
+		Name:        models.PaymentChannelIMPS,
+		Limit:       500000.0,
+		SuccessRate: 0.95,
+		Fee:         2.0,
+	}
+
+	t.Run("sends the penny drop through IMPS", func(t *testing.T) {
+		service, mocks := newVerificationService(nil, true)
+
+		request := models.AccountVerificationRequest{
+			ReferenceID: "REF-123",
+			Beneficiary: models.Beneficiary{
+				Name:    "  John   Doe ",
+				Account: "1234567890",
+				IFSC:    "IFSC0001234",
+				Bank:    "Test Bank",
+			},
+		}
+		processedAt := time.Now()
+
+		mocks.transaction.On("GetByReferenceID", mock.Anything, request.ReferenceID).
+			Return(nil, gorm.ErrRecordNotFound).Once()
+		mocks.paymentChannel.On("Get", mock.Anything, models.PaymentChannelIMPS).
+			Return(impsChannel, nil).Once()
+		mocks.transaction.On("Create", mock.Anything, mock.MatchedBy(func(txn schema.Transaction) bool {
+			return txn.Amount == models.PennyDropAmount &&
+				txn.Channel == models.PaymentChannelIMPS &&
+				txn.Fee == impsChannel.Fee &&
+				txn.Status == models.TransactionStatusInitiated &&
+				txn.Metadata["purpose"] == models.PennyDropPurpose
+		})).Return(schema.Transaction{ID: "IMPS-TXN-123456789012", Status: models.TransactionStatusInitiated}, nil).Once()
+		mocks.transaction.On("Get", mock.Anything, "IMPS-TXN-123456789012").
+			Return(schema.Transaction{ID: "IMPS-TXN-123456789012", Status: models.TransactionStatusProcessing}, nil).Once()
+		mocks.transaction.On("Get", mock.Anything, "IMPS-TXN-123456789012").Return(schema.Transaction{
+			ID:            "IMPS-TXN-123456789012",
+			ReferenceID:   request.ReferenceID,
+			AccountNumber: request.Beneficiary.Account,
+			IFSCCode:      request.Beneficiary.IFSC,
+			Status:        models.TransactionStatusSuccess,
+			ProcessedAt:   &processedAt,
+		}, nil).Once()
+
+		result, err := service.VerifyAccount(ctx, request)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "IMPS-TXN-123456789012", result.TransactionID)
+		assert.Equal(t, models.TransactionStatusSuccess, result.Status)
+		assert.Equal(t, processedAt, result.VerifiedAt)
+		assert.Len(t, mocks.processor, 1)
+
+		mocks.transaction.AssertExpectations(t)
+		mocks.paymentChannel.AssertExpectations(t)
+	})
+
+	t.Run("returns bank record name when it differs", func(t *testing.T) {
+		service, mocks := newVerificationService(nil, true)
+
+		request := models.AccountVerificationRequest{
+			ReferenceID: "REF-456",
+			Beneficiary: models.Beneficiary{
+				Name:    "John Doe",
+				Account: "3333333333333333",
+				IFSC:    "IFSC0001234",
+			},
+		}
+
+		mocks.transaction.On("GetByReferenceID", mock.Anything, request.ReferenceID).
+			Return(nil, gorm.ErrRecordNotFound).Once()
+		mocks.paymentChannel.On("Get", mock.Anything, models.PaymentChannelIMPS).
+			Return(impsChannel, nil).Once()
+		mocks.transaction.On("Create", mock.Anything, mock.AnythingOfType("schema.Transaction")).
+			Return(schema.Transaction{ID: "IMPS-TXN-456"}, nil).Once()
+		mocks.transaction.On("Get", mock.Anything, "IMPS-TXN-456").
+			Return(schema.Transaction{ID: "IMPS-TXN-456", Status: models.TransactionStatusSuccess}, nil).Once()
+
+		result, err := service.VerifyAccount(ctx, request)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "RAMESH KUMAR", result.RegisteredName)
+	})
+
+	t.Run("returns no name when the bank has none on record", func(t *testing.T) {
+		service, mocks := newVerificationService(nil, true)
+
+		request := models.AccountVerificationRequest{
+			ReferenceID: "REF-789",
+			Beneficiary: models.Beneficiary{
+				Name:    "John Doe",
+				Account: "1234567890",
+				IFSC:    "IFSC0001234",
+			},
+		}
+
+		mocks.transaction.On("GetByReferenceID", mock.Anything, request.ReferenceID).
+			Return(nil, gorm.ErrRecordNotFound).Once()
+		mocks.paymentChannel.On("Get", mock.Anything, models.PaymentChannelIMPS).
+			Return(impsChannel, nil).Once()
+		mocks.transaction.On("Create", mock.Anything, mock.AnythingOfType("schema.Transaction")).
+			Return(schema.Transaction{ID: "IMPS-TXN-789"}, nil).Once()
+		mocks.transaction.On("Get", mock.Anything, "IMPS-TXN-789").
+			Return(schema.Transaction{ID: "IMPS-TXN-789", Status: models.TransactionStatusSuccess}, nil).Once()
+
+		result, err := service.VerifyAccount(ctx, request)
+
+		assert.NoError(t, err)
+		assert.Empty(t, result.RegisteredName)
+	})
+
+	t.Run("returns the processor's failure when the penny drop fails", func(t *testing.T) {
+		service, mocks := newVerificationService(nil, true)
+		message := failures.INSUFFICIENT_BALANCE.Error()
+
+		mocks.transaction.On("GetByReferenceID", mock.Anything, "REF-123").
+			Return(nil, gorm.ErrRecordNotFound).Once()
+		mocks.paymentChannel.On("Get", mock.Anything, models.PaymentChannelIMPS).
+			Return(impsChannel, nil).Once()
+		mocks.transaction.On("Create", mock.Anything, mock.AnythingOfType("schema.Transaction")).
+			Return(schema.Transaction{ID: "IMPS-TXN-123"}, nil).Once()
+		mocks.transaction.On("Get", mock.Anything, "IMPS-TXN-123").
+			Return(schema.Transaction{ID: "IMPS-TXN-123", Status: models.TransactionStatusFailed, Message: &message}, nil).Once()
+
+		result, err := service.VerifyAccount(ctx, models.AccountVerificationRequest{
+			ReferenceID: "REF-123",
+			Beneficiary: models.Beneficiary{Name: "John Doe", Account: "1234567890", IFSC: "IFSC0001234"},
+		})
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, failures.INSUFFICIENT_BALANCE)
+	})
+
+	t.Run("returns error when IMPS is not available", func(t *testing.T) {
+		service, mocks := newVerificationService(nil, false)
+
+		mocks.transaction.On("GetByReferenceID", mock.Anything, "REF-123").
+			Return(nil, gorm.ErrRecordNotFound).Once()
+
+		result, err := service.VerifyAccount(ctx, models.AccountVerificationRequest{
+			ReferenceID: "REF-123",
+			Beneficiary: models.Beneficiary{Name: "John Doe", Account: "1234567890", IFSC: "IFSC0001234"},
+		})
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, failures.CHANNEL_UNAVAILABLE)
+		mocks.transaction.AssertNotCalled(t, "Create")
+	})
+
+	t.Run("returns error for inactive account", func(t *testing.T) {
+		service, mocks := newVerificationService(nil, true)
+
+		request := models.AccountVerificationRequest{
+			ReferenceID: "REF-789",
+			Beneficiary: models.Beneficiary{
+				Name:    "John Doe",
+				Account: "0000000000000000",
+				IFSC:    "IFSC0001234",
+			},
+		}
+
+		mocks.transaction.On("GetByReferenceID", mock.Anything, request.ReferenceID).
+			Return(nil, gorm.ErrRecordNotFound).Once()
+
+		result, err := service.VerifyAccount(ctx, request)
+
+		assert.Nil(t, result)
+		assert.Equal(t, failures.INACTIVE_ACCOUNT, err)
+		mocks.paymentChannel.AssertNotCalled(t, "Get")
+		mocks.transaction.AssertNotCalled(t, "Create")
+	})
+
+	t.Run("returns error when the branch is not on IMPS", func(t *testing.T) {
+		service, mocks := newVerificationService(newTestDirectory(t), true)
+
+		request := models.AccountVerificationRequest{
+			ReferenceID: "REF-789",
+			Beneficiary: models.Beneficiary{
+				Name:    "John Doe",
+				Account: "1234567890",
+				IFSC:    "SBIN0001234",
+			},
+		}
+
+		mocks.transaction.On("GetByReferenceID", mock.Anything, request.ReferenceID).
+			Return(nil, gorm.ErrRecordNotFound).Once()
+
+		result, err := service.VerifyAccount(ctx, request)
+
+		assert.Nil(t, result)
+		assert.Equal(t, failures.CHANNEL_NOT_SUPPORTED, err)
+		mocks.paymentChannel.AssertNotCalled(t, "Get")
+		mocks.transaction.AssertNotCalled(t, "Create")
+	})
+
+	t.Run("returns error when reference ID already processed", func(t *testing.T) {
+		service, mocks := newVerificationService(nil, true)
+
+		mocks.transaction.On("GetByReferenceID", mock.Anything, "REF-123").
+			Return(&schema.Transaction{ID: "IMPS-TXN-EXISTING"}, nil).Once()
+
+		result, err := service.VerifyAccount(ctx, models.AccountVerificationRequest{
+			ReferenceID: "REF-123",
+		})
+
+		assert.Nil(t, result)
+		assert.Equal(t, failures.REFERENCE_ID_ALREADY_PROCESSED, err)
+	})
+}
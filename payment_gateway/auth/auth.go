@@ -0,0 +1,49 @@
+// Package auth decides what API callers may do. Callers are identified by
+// the shared authn package; each of their roles grants a fixed set of
+// permissions that routes are mapped to.
+package auth
+
+import (
+	"errors"
+	"shared/authn"
+	"shared/pii"
+	"slices"
+)
+
+var ErrPermissionDenied = errors.New("permission denied")
+
+type Permission string
+
+const (
+	// PermissionDisburse sends payments and verifies accounts.
+	PermissionDisburse Permission = "disburse"
+	// PermissionAdmin manages accounts, including top-ups, and channels.
+	PermissionAdmin Permission = "admin"
+	// PermissionViewPII shows account numbers and beneficiary names unmasked.
+	PermissionViewPII Permission = pii.ViewPermission
+)
+
+// Roles callers can hold.
+const (
+	RoleOpsAdmin = "ops_admin"
+	// RoleDisbursementService is held by the disbursement service, which
+	// matches registered names from penny drops against the borrower and so
+	// sees them unmasked.
+	RoleDisbursementService = "disbursement_service"
+)
+
+var rolePermissions = map[string][]Permission{
+	RoleOpsAdmin:            {PermissionDisburse, PermissionAdmin, PermissionViewPII},
+	RoleDisbursementService: {PermissionDisburse, PermissionViewPII},
+}
+
+// Can reports whether any of the principal's roles grants permission.
+// Roles this service does not know grant nothing.
+func Can(principal authn.Principal, permission Permission) bool {
+	for _, role := range principal.Roles {
+		if slices.Contains(rolePermissions[role], permission) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,19 @@
+package auth
+
+import (
+	"shared/authn"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCan(t *testing.T) {
+	disbursement := authn.Principal{Subject: "disbursement", Roles: []string{RoleDisbursementService}}
+	admin := authn.Principal{Subject: "ops@example.com", Roles: []string{"unknown", RoleOpsAdmin}}
+
+	assert.True(t, Can(disbursement, PermissionDisburse))
+	assert.True(t, Can(disbursement, PermissionViewPII))
+	assert.False(t, Can(disbursement, PermissionAdmin))
+	assert.True(t, Can(admin, PermissionAdmin))
+	assert.False(t, Can(authn.Principal{}, PermissionDisburse))
+}
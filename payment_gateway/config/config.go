@@ -0,0 +1,133 @@
+// Package config holds the gateway's settings: the port it listens on and
+// the timeout of its HTTP client. Settings are read from a YAML file, and
+// each can be overridden by an environment variable named after its path, so
+// http.timeout is overridden by HTTP_TIMEOUT. Anything left out keeps its
+// default. Both settings are read at startup and change with a restart.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrInvalidConfig = errors.New("invalid config")
+
+type Config struct {
+	Server Server `yaml:"server"`
+	HTTP   HTTP   `yaml:"http"`
+}
+
+type Server struct {
+	Port string `yaml:"port"`
+}
+
+// HTTP is for the client sending transaction notifications.
+type HTTP struct {
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+func Default() Config {
+	return Config{
+		Server: Server{Port: "8080"},
+		HTTP:   HTTP{Timeout: 30 * time.Second},
+	}
+}
+
+// Load reads the YAML file at path over the defaults, applies environment
+// overrides and validates the result. An empty path gives the defaults with
+// the overrides applied.
+func Load(path string) (Config, error) {
+	config := Default()
+	if path != "" {
+		file, err := os.ReadFile(path)
+		if err != nil {
+			return Config{}, err
+		}
+		decoder := yaml.NewDecoder(bytes.NewReader(file))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&config); err != nil && err != io.EOF {
+			return Config{}, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+		}
+	}
+	if err := applyEnv(reflect.ValueOf(&config).Elem(), nil); err != nil {
+		return Config{}, err
+	}
+	if err := config.Validate(); err != nil {
+		return Config{}, err
+	}
+	return config, nil
+}
+
+func (c Config) Validate() error {
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("%w: server.port %q is not a port", ErrInvalidConfig, c.Server.Port)
+	}
+	if c.HTTP.Timeout <= 0 {
+		return fmt.Errorf("%w: http.timeout must be positive", ErrInvalidConfig)
+	}
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// applyEnv sets each field of value from the environment variable named
+// after its path of YAML keys, upper-cased and joined by underscores.
+func applyEnv(value reflect.Value, path []string) error {
+	for i := range value.NumField() {
+		field := value.Field(i)
+		key, _, _ := strings.Cut(value.Type().Field(i).Tag.Get("yaml"), ",")
+		fieldPath := append(path[:len(path):len(path)], key)
+		if field.Kind() == reflect.Struct {
+			if err := applyEnv(field, fieldPath); err != nil {
+				return err
+			}
+			continue
+		}
+		name := strings.ToUpper(strings.Join(fieldPath, "_"))
+		env, ok := os.LookupEnv(name)
+		if !ok || env == "" {
+			continue
+		}
+		if err := setField(field, env); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidConfig, name, err)
+		}
+	}
+	return nil
+}
+
+func setField(field reflect.Value, env string) error {
+	switch {
+	case field.Type() == durationType:
+		duration, err := time.ParseDuration(env)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+	case field.Kind() == reflect.String:
+		field.SetString(env)
+	case field.Kind() == reflect.Int:
+		parsed, err := strconv.Atoi(env)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(parsed))
+	case field.Kind() == reflect.Float64:
+		parsed, err := strconv.ParseFloat(env, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(parsed)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("gives the defaults without a path", func(t *testing.T) {
+		config, err := Load("")
+
+		assert.NoError(t, err)
+		assert.Equal(t, Default(), config)
+	})
+
+	t.Run("reads the file over the defaults", func(t *testing.T) {
+		config, err := Load(writeFile(t, "http:\n  timeout: 10s\n"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, Config{Server: Server{Port: "8080"}, HTTP: HTTP{Timeout: 10 * time.Second}}, config)
+	})
+
+	t.Run("overrides from the environment", func(t *testing.T) {
+		t.Setenv("SERVER_PORT", "8081")
+		t.Setenv("HTTP_TIMEOUT", "5s")
+
+		config, err := Load(writeFile(t, "server:\n  port: \"9090\"\n"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, Config{Server: Server{Port: "8081"}, HTTP: HTTP{Timeout: 5 * time.Second}}, config)
+	})
+
+	t.Run("rejects an unparsable override", func(t *testing.T) {
+		t.Setenv("HTTP_TIMEOUT", "soon")
+
+		_, err := Load("")
+
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+	})
+
+	for name, file := range map[string]string{
+		"unknown key":    "http:\n  timout: 10s\n",
+		"malformed yaml": "server: [",
+		"bad port":       "server:\n  port: gateway\n",
+		"zero timeout":   "http:\n  timeout: 0s\n",
+	} {
+		t.Run("rejects "+name, func(t *testing.T) {
+			_, err := Load(writeFile(t, file))
+
+			assert.ErrorIs(t, err, ErrInvalidConfig)
+		})
+	}
+
+	t.Run("fails on a missing file", func(t *testing.T) {
+		_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+
+		assert.True(t, errors.Is(err, os.ErrNotExist))
+	})
+}
@@ -0,0 +1,79 @@
+package daos
+
+import (
+	"context"
+	"payment-gateway/db/schema"
+	"shared/encryption"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// sealedColumn is a column holding encrypted account numbers, with the
+// primary key of its table.
+type sealedColumn struct {
+	model  any
+	keys   []string
+	column string
+}
+
+var sealedColumns = []sealedColumn{
+	{model: &schema.Transaction{}, keys: []string{"id"}, column: "account_number"},
+}
+
+const rotationBatchSize = 500
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// RotateKeys rewraps every stored account number whose data key is wrapped
+// with a key other than the current one, and encrypts those stored before
+// encryption was enabled. It returns how many values changed. A key can be
+// dropped from the provider once this has run after it stopped being
+// current.
+func RotateKeys(ctx context.Context, db *gorm.DB, cipher *encryption.Cipher) (int, error) {
+	rotated := 0
+	for _, sealed := range sealedColumns {
+		count, err := sealed.rotate(ctx, db, cipher)
+		rotated += count
+		if err != nil {
+			return rotated, err
+		}
+	}
+	return rotated, nil
+}
+
+func (s sealedColumn) rotate(ctx context.Context, db *gorm.DB, cipher *encryption.Cipher) (int, error) {
+	current := likeEscaper.Replace(cipher.CurrentPrefix()) + "%"
+	rotated := 0
+	for {
+		var rows []map[string]any
+		if err := db.WithContext(ctx).Model(s.model).
+			Select(append(s.keys, s.column)).
+			Where(s.column+" <> '' AND "+s.column+" NOT LIKE ?", current).
+			Limit(rotationBatchSize).
+			Find(&rows).Error; err != nil {
+			return rotated, err
+		}
+		if len(rows) == 0 {
+			return rotated, nil
+		}
+
+		for _, row := range rows {
+			value, _ := row[s.column].(string)
+			sealed, err := cipher.Rewrap(ctx, value)
+			if err != nil {
+				return rotated, err
+			}
+			query := db.WithContext(ctx).Model(s.model)
+			for _, key := range s.keys {
+				query = query.Where(key+" = ?", row[key])
+			}
+			// UpdateColumn leaves updated_at alone; rotation is not a change
+			// to the record.
+			if err := query.UpdateColumn(s.column, sealed).Error; err != nil {
+				return rotated, err
+			}
+			rotated++
+		}
+	}
+}
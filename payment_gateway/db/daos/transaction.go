@@ -4,6 +4,7 @@ import (
 	"context"
 	"payment-gateway/db/schema"
 	"payment-gateway/models"
+	"shared/encryption"
 
 	"gorm.io/gorm"
 )
@@ -20,21 +21,31 @@ type TransactionRepository interface {
 	) ([]schema.Transaction, error)
 }
 
+// TransactionDAO stores beneficiary account numbers encrypted with cipher
+// and returns them decrypted.
 type TransactionDAO struct {
-	db *gorm.DB
+	db     *gorm.DB
+	cipher *encryption.Cipher
 }
 
-func NewTransactionRepository(db *gorm.DB) TransactionRepository {
-	return &TransactionDAO{db: db}
+func NewTransactionRepository(db *gorm.DB, cipher *encryption.Cipher) TransactionRepository {
+	return &TransactionDAO{db: db, cipher: cipher}
 }
 
 func (t TransactionDAO) Create(
 	ctx context.Context,
 	transaction schema.Transaction,
 ) (schema.Transaction, error) {
+	account := transaction.AccountNumber
+	sealed, err := t.cipher.Encrypt(ctx, account)
+	if err != nil {
+		return schema.Transaction{}, err
+	}
+	transaction.AccountNumber = sealed
 	if err := t.db.WithContext(ctx).Model(&schema.Transaction{}).Create(&transaction).Error; err != nil {
 		return schema.Transaction{}, err
 	}
+	transaction.AccountNumber = account
 	return transaction, nil
 }
 
@@ -58,6 +69,9 @@ func (t TransactionDAO) Get(ctx context.Context, id string) (schema.Transaction,
 		First(&transaction).Error; err != nil {
 		return schema.Transaction{}, err
 	}
+	if err := t.decrypt(ctx, &transaction); err != nil {
+		return schema.Transaction{}, err
+	}
 	return transaction, nil
 }
 
@@ -71,6 +85,9 @@ func (t TransactionDAO) GetByReferenceID(
 		First(&transaction).Error; err != nil {
 		return nil, err
 	}
+	if err := t.decrypt(ctx, &transaction); err != nil {
+		return nil, err
+	}
 	return &transaction, nil
 }
 
@@ -93,5 +110,19 @@ func (t TransactionDAO) List(
 		Find(&transactions).Error; err != nil {
 		return nil, err
 	}
+	for i := range transactions {
+		if err := t.decrypt(ctx, &transactions[i]); err != nil {
+			return nil, err
+		}
+	}
 	return transactions, nil
 }
+
+func (t TransactionDAO) decrypt(ctx context.Context, transaction *schema.Transaction) error {
+	account, err := t.cipher.Decrypt(ctx, transaction.AccountNumber)
+	if err != nil {
+		return err
+	}
+	transaction.AccountNumber = account
+	return nil
+}
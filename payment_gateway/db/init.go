@@ -1,8 +1,11 @@
 package db
 
 import (
+	"context"
 	"payment-gateway/db/daos"
 	"payment-gateway/db/schema"
+	"shared/encryption"
+	"shared/tracing"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -10,16 +13,20 @@ import (
 
 type Database struct {
 	db             *gorm.DB
+	cipher         *encryption.Cipher
 	account        daos.AccountRepository
 	paymentChannel daos.PaymentChannelRepository
 	transaction    daos.TransactionRepository
 }
 
-func New(dsn string) (*Database, error) {
+func New(dsn string, cipher *encryption.Cipher) (*Database, error) {
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
 	if err != nil {
 		return nil, err
 	}
+	if err := db.Use(tracing.GormPlugin()); err != nil {
+		return nil, err
+	}
 
 	if err := db.AutoMigrate(
 		&schema.Account{},
@@ -31,9 +38,10 @@ func New(dsn string) (*Database, error) {
 
 	return &Database{
 		db:             db,
+		cipher:         cipher,
 		account:        daos.NewAccountRepository(db),
 		paymentChannel: daos.NewPaymentChannelRepository(db),
-		transaction:    daos.NewTransactionRepository(db),
+		transaction:    daos.NewTransactionRepository(db, cipher),
 	}, nil
 }
 
@@ -41,6 +49,12 @@ func (d Database) GetDB() *gorm.DB {
 	return d.db
 }
 
+// RotateKeys rewraps the stored account numbers with the current key and
+// encrypts any still in plaintext, returning how many changed.
+func (d Database) RotateKeys(ctx context.Context) (int, error) {
+	return daos.RotateKeys(ctx, d.db, d.cipher)
+}
+
 func (d Database) GetAccountRepository() daos.AccountRepository {
 	return d.account
 }
@@ -45,6 +45,7 @@ func (j *JSONB) Scan(value interface{}) error {
 	return nil
 }
 
+// Transaction is stored with AccountNumber encrypted.
 type Transaction struct {
 	ID              string `gorm:"primaryKey"`
 	ReferenceID     string `gorm:"index"`
@@ -61,5 +62,6 @@ type Transaction struct {
 	CreatedAt       time.Time
 	ProcessedAt     *time.Time
 	NotifiedAt      *time.Time
+	TraceParent     string
 	UpdatedAt       time.Time
 }
@@ -1,18 +1,29 @@
 package failures
 
-import "errors"
+import (
+	"errors"
+
+	"shared/apperrors"
+)
 
 var (
-	INVALID_IFSC                   = errors.New("Invalid IFSC code")
-	BENEFICIARY_BANK_DOWN          = errors.New("Beneficiary Bank is Down")
-	INACTIVE_ACCOUNT               = errors.New("Inactive Beneficiary Account")
-	SERVICE_UNAVAILABLE            = errors.New("Service Unavailable")
-	LIMIT_EXCEEDED                 = errors.New("Limit Exceeded")
-	REFERENCE_ID_ALREADY_PROCESSED = errors.New("Reference ID already processed")
-	TRANSACTION_NOT_FOUND          = errors.New("Transaction not found")
-	INVALID_PAYMENT_CHANNEL        = errors.New("Invalid Payment Channel")
+	INVALID_IFSC                   = apperrors.New(apperrors.CodeUnprocessable, "Invalid IFSC code")
+	BENEFICIARY_BANK_DOWN          = apperrors.New(apperrors.CodeUnavailable, "Beneficiary Bank is Down")
+	INACTIVE_ACCOUNT               = apperrors.New(apperrors.CodeUnprocessable, "Inactive Beneficiary Account")
+	SERVICE_UNAVAILABLE            = apperrors.New(apperrors.CodeUnavailable, "Service Unavailable")
+	LIMIT_EXCEEDED                 = apperrors.New(apperrors.CodeUnprocessable, "Limit Exceeded")
+	REFERENCE_ID_ALREADY_PROCESSED = apperrors.New(apperrors.CodeConflict, "Reference ID already processed")
+	TRANSACTION_NOT_FOUND          = apperrors.New(apperrors.CodeNotFound, "Transaction not found")
+	INVALID_PAYMENT_CHANNEL        = apperrors.New(apperrors.CodeUnprocessable, "Invalid Payment Channel")
 	UNKNOWN_ERROR                  = errors.New("Unknown Error")
-	INSUFFICIENT_BALANCE           = errors.New("Insufficient Balance")
+	INSUFFICIENT_BALANCE           = apperrors.New(apperrors.CodeUnprocessable, "Insufficient Balance")
+	ACCOUNT_ALREADY_EXISTS         = apperrors.New(apperrors.CodeConflict, "account already exists")
+	ACCOUNT_NOT_FOUND              = apperrors.New(apperrors.CodeNotFound, "account not found")
+	PAYMENT_CHANNEL_ALREADY_EXISTS = apperrors.New(apperrors.CodeConflict, "payment channel already exists")
+	PAYMENT_CHANNEL_NOT_FOUND      = apperrors.New(apperrors.CodeNotFound, "payment channel not found")
+	CHANNEL_NOT_SUPPORTED          = apperrors.New(apperrors.CodeUnprocessable, "channel not supported by beneficiary branch")
+	CHANNEL_UNAVAILABLE            = apperrors.New(apperrors.CodeUnavailable, "channel is not available")
+	VERIFICATION_TIMEOUT           = apperrors.New(apperrors.CodeUnavailable, "account verification did not settle in time")
 )
 
 var TRANSACTION_FAILURES = []error{
@@ -21,14 +32,15 @@ var TRANSACTION_FAILURES = []error{
 	LIMIT_EXCEEDED,
 }
 
-var INVALID_IFSC_CODES = []string{
-	"INVALID_IFSC_CODE_1",
-	"INVALID_IFSC_CODE_2",
-	"INVALID_IFSC_CODE_3",
-}
-
 var INVALID_ACCOUNT_NUMBERS = []string{
 	"0000000000000000",
 	"1111111111111111",
 	"2222222222222222",
 }
+
+// REGISTERED_ACCOUNT_HOLDERS simulates the holder names on bank records.
+// Penny drops to accounts not listed here return no name.
+var REGISTERED_ACCOUNT_HOLDERS = map[string]string{
+	"3333333333333333": "RAMESH KUMAR",
+	"4444444444444444": "ACME TRADERS PRIVATE LIMITED",
+}
@@ -0,0 +1,62 @@
+package http
+
+import (
+	"context"
+	"maps"
+	"net/http"
+)
+
+type headerClient struct {
+	client  HTTPClient
+	headers map[string]string
+}
+
+// WithHeaders returns a client that adds headers to every request made
+// through client, such as the credentials of the service being called.
+// Headers a caller sets itself are left as they are.
+func WithHeaders(client HTTPClient, headers map[string]string) HTTPClient {
+	return &headerClient{client: client, headers: headers}
+}
+
+func (c *headerClient) GET(
+	ctx context.Context,
+	url string,
+	headers map[string]string,
+) (*http.Response, error) {
+	return c.client.GET(ctx, url, c.merge(headers))
+}
+
+func (c *headerClient) POST(
+	ctx context.Context,
+	url string,
+	body any,
+	headers map[string]string,
+) (*http.Response, error) {
+	return c.client.POST(ctx, url, body, c.merge(headers))
+}
+
+func (c *headerClient) PUT(
+	ctx context.Context,
+	url string,
+	body any,
+	headers map[string]string,
+) (*http.Response, error) {
+	return c.client.PUT(ctx, url, body, c.merge(headers))
+}
+
+func (c *headerClient) DELETE(
+	ctx context.Context,
+	url string,
+	headers map[string]string,
+) (*http.Response, error) {
+	return c.client.DELETE(ctx, url, c.merge(headers))
+}
+
+func (c *headerClient) merge(headers map[string]string) map[string]string {
+	merged := maps.Clone(c.headers)
+	if merged == nil {
+		merged = make(map[string]string, len(headers))
+	}
+	maps.Copy(merged, headers)
+	return merged
+}
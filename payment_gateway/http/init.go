@@ -23,6 +23,8 @@ type HTTPClient interface {
 	DELETE(ctx context.Context, url string, headers map[string]string) (*http.Response, error)
 }
 
-func NewHTTPClient() HTTPClient {
-	return NewNetHTTPClient(30 * time.Second)
+// NewHTTPClient gives up on a request after timeout, or after 30 seconds
+// when timeout is not positive.
+func NewHTTPClient(timeout time.Duration) HTTPClient {
+	return NewNetHTTPClient(timeout)
 }
@@ -3,34 +3,116 @@ package main
 import (
 	"context"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"payment-gateway/api"
 	"payment-gateway/api/service"
+	"payment-gateway/config"
 	"payment-gateway/db"
 	httpclient "payment-gateway/http"
+	"payment-gateway/metrics"
 	"payment-gateway/models"
 	"payment-gateway/utils"
 	"payment-gateway/worker"
+	"shared/authn"
+	"shared/calendar"
+	"shared/encryption"
+	"shared/ifsc"
+	"shared/pii"
+	"shared/tracing"
 	"syscall"
+	"time"
 
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
 func main() {
-	db, err := db.New(os.Getenv("DATABASE_URL"))
+	log.Logger = log.Output(pii.Writer(os.Stderr))
+	zerolog.InterfaceMarshalFunc = pii.Marshal
+
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		ServiceName: "payment-gateway",
+		Exporter:    os.Getenv("OTEL_TRACES_EXPORTER"),
+		File:        os.Getenv("OTEL_TRACES_FILE"),
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to set up tracing")
+	}
+
+	keyringFile := os.Getenv("ENCRYPTION_KEYRING_FILE")
+	if keyringFile == "" {
+		log.Fatal().Msg("ENCRYPTION_KEYRING_FILE env var is required")
+	}
+	keyring, err := encryption.LoadKeyring(keyringFile)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load encryption keyring")
+	}
+	cipher, err := encryption.New(context.Background(), keyring)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to set up encryption")
+	}
+
+	db, err := db.New(os.Getenv("DATABASE_URL"), cipher)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to connect to database")
 	}
 
+	// rotate-keys rewraps stored account numbers with the current key and
+	// exits, rather than starting the gateway.
+	if len(os.Args) > 1 && os.Args[1] == "rotate-keys" {
+		rotated, err := db.RotateKeys(context.Background())
+		if err != nil {
+			log.Fatal().Err(err).Int("rotated", rotated).Msg("Key rotation failed")
+		}
+		log.Info().Int("rotated", rotated).Msg("Key rotation complete")
+		return
+	}
+
+	cfg, err := config.Load(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load config")
+	}
+
+	authenticators := loadAuthenticators()
+
 	idGenerator := utils.NewIdGenerator()
 
+	bankCalendar, err := calendar.Load(os.Getenv("CALENDAR_FILE"))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load bank calendar")
+	}
+	if now := time.Now(); os.Getenv("CALENDAR_FILE") != "" && !bankCalendar.Covers(now) {
+		log.Warn().Int("year", now.In(bankCalendar.Location()).Year()).
+			Msg("Bank calendar lists no holidays for this year, they are treated as working days")
+	}
+	directory, err := ifsc.Load(os.Getenv("IFSC_DIRECTORY_FILE"))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load IFSC directory")
+	}
+	if directory == nil {
+		log.Warn().Msg("IFSC_DIRECTORY_FILE is not set, beneficiary branches are not checked")
+	} else {
+		log.Info().Int("branches", directory.Len()).Msg("Loaded IFSC directory")
+	}
+
 	processor := make(chan models.ProcessorMessage)
 
-	serviceFactory := service.NewServiceFactory(db, processor, idGenerator)
-	server := api.NewGatewayServer("8080", serviceFactory)
+	if err := metrics.RegisterAccountBalances(db.GetAccountRepository()); err != nil {
+		log.Fatal().Err(err).Msg("Failed to register account balance metric")
+	}
+
+	serviceFactory := service.NewServiceFactory(db, processor, idGenerator, bankCalendar, directory)
+	server := api.NewGatewayServer(cfg.Server.Port, serviceFactory, authenticators...)
 
-	worker := worker.NewWorker(db, processor, httpclient.NewHTTPClient())
+	notificationBase, err := url.Parse(os.Getenv("NOTIFICATION_BASE_URL"))
+	if err != nil || notificationBase.Scheme == "" || notificationBase.Host == "" {
+		log.Fatal().Msg("NOTIFICATION_BASE_URL env var must be an absolute URL")
+	}
+	worker := worker.NewWorker(db, processor, httpclient.WithHeaders(httpclient.NewHTTPClient(cfg.HTTP.Timeout), map[string]string{
+		authn.APIKeyHeader: os.Getenv("NOTIFICATION_API_KEY"),
+	}), notificationBase)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -52,6 +134,36 @@ func main() {
 	log.Info().Msg("Received interrupt signal, shutting down...")
 	worker.Stop(ctx)
 	server.Close()
+	if err := shutdownTracing(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to flush traces")
+	}
 
 	log.Info().Msg("Payment gateway stopped")
 }
+
+// loadAuthenticators reads the API keys and the JWKS callers authenticate
+// with. At least one of them must be configured.
+func loadAuthenticators() []authn.Authenticator {
+	var authenticators []authn.Authenticator
+	if path := os.Getenv("AUTH_JWKS_FILE"); path != "" {
+		verifier, err := authn.LoadJWKS(path, authn.JWTConfig{
+			Issuer:   os.Getenv("AUTH_JWT_ISSUER"),
+			Audience: os.Getenv("AUTH_JWT_AUDIENCE"),
+		})
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to load jwks")
+		}
+		authenticators = append(authenticators, verifier)
+	}
+	if path := os.Getenv("AUTH_API_KEYS_FILE"); path != "" {
+		keys, err := authn.LoadAPIKeys(path)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to load api keys")
+		}
+		authenticators = append(authenticators, keys)
+	}
+	if len(authenticators) == 0 {
+		log.Fatal().Msg("AUTH_API_KEYS_FILE or AUTH_JWKS_FILE env var is required")
+	}
+	return authenticators
+}
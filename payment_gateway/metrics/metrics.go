@@ -0,0 +1,198 @@
+// Package metrics holds the Prometheus collectors for the gateway's
+// processor and notifier workers and serves them for scraping. Collectors
+// are registered on the package's own Registry, together with the Go runtime
+// and process collectors.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"payment-gateway/db/schema"
+	"payment-gateway/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	namespace = "gateway"
+
+	outcomeSuccess = "success"
+	outcomeFailure = "failure"
+
+	// balanceTimeout bounds the account query run on each scrape.
+	balanceTimeout = 5 * time.Second
+)
+
+var Registry = prometheus.NewRegistry()
+
+var (
+	transactions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transactions_total",
+		Help:      "Transactions reaching a status, by channel.",
+	}, []string{"channel", "status"})
+
+	processorQueue = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "processor_queue_depth",
+		Help:      "Transactions handed to the processor worker and not yet picked up.",
+	})
+
+	processingDelay = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "processing_delay_seconds",
+		Help:      "Simulated settlement delay applied by the processor worker.",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 8),
+	}, []string{"channel"})
+
+	notifications = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notifications_total",
+		Help:      "Status notifications sent to callers, by destination host and outcome.",
+	}, []string{"destination", "outcome"})
+
+	notificationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "notification_duration_seconds",
+		Help:      "Latency of status notifications, by destination host.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 10),
+	}, []string{"destination"})
+
+	availabilityChecks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "channel_availability_checks_total",
+		Help:      "Channel availability results from the availability schedule.",
+	}, []string{"channel", "available"})
+
+	channelAvailable = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "channel_available",
+		Help:      "Latest availability result for each channel, 1 when available.",
+	}, []string{"channel"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		transactions,
+		processorQueue,
+		processingDelay,
+		notifications,
+		notificationDuration,
+		availabilityChecks,
+		channelAvailable,
+	)
+}
+
+// Handler serves everything in Registry. A failing collector, such as the
+// account balance when the database is down, is reported in the response
+// without hiding the other metrics.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+}
+
+func RecordTransaction(channel models.PaymentChannel, status models.TransactionStatus) {
+	transactions.WithLabelValues(string(channel), string(status)).Inc()
+}
+
+// ProcessorQueued is called before handing a transaction to the processor
+// worker and ProcessorReceived once the worker takes it. The processor
+// channel is unbuffered, so the difference is the number of senders waiting
+// on it.
+func ProcessorQueued() {
+	processorQueue.Inc()
+}
+
+func ProcessorReceived() {
+	processorQueue.Dec()
+}
+
+func ObserveProcessingDelay(channel models.PaymentChannel, delay time.Duration) {
+	processingDelay.WithLabelValues(string(channel)).Observe(delay.Seconds())
+}
+
+// ObserveNotification records a notification to notificationURL. Only the
+// host is used as the destination label so per transaction paths or query
+// strings do not each become a series.
+func ObserveNotification(notificationURL string, elapsed time.Duration, ok bool) {
+	host := destination(notificationURL)
+	outcome := outcomeSuccess
+	if !ok {
+		outcome = outcomeFailure
+	}
+	notifications.WithLabelValues(host, outcome).Inc()
+	notificationDuration.WithLabelValues(host).Observe(elapsed.Seconds())
+}
+
+// destination returns the host of rawURL, or "invalid" when it has none.
+func destination(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return "invalid"
+	}
+	return parsed.Host
+}
+
+func RecordAvailability(channel models.PaymentChannel, available bool) {
+	value, label := 0.0, "false"
+	if available {
+		value, label = 1, "true"
+	}
+	availabilityChecks.WithLabelValues(string(channel), label).Inc()
+	channelAvailable.WithLabelValues(string(channel)).Set(value)
+}
+
+type AccountLister interface {
+	List(ctx context.Context) ([]schema.Account, error)
+}
+
+// RegisterAccountBalances exposes each account's balance, read through
+// accounts on every scrape.
+func RegisterAccountBalances(accounts AccountLister) error {
+	return Registry.Register(newBalanceCollector(accounts))
+}
+
+type balanceCollector struct {
+	accounts AccountLister
+	desc     *prometheus.Desc
+}
+
+func newBalanceCollector(accounts AccountLister) *balanceCollector {
+	return &balanceCollector{
+		accounts: accounts,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "account_balance"),
+			"Current balance of each gateway account.",
+			[]string{"account"},
+			nil,
+		),
+	}
+}
+
+func (c *balanceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *balanceCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), balanceTimeout)
+	defer cancel()
+
+	accounts, err := c.accounts.List(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+	for _, account := range accounts {
+		ch <- prometheus.MustNewConstMetric(
+			c.desc, prometheus.GaugeValue, account.Balance, account.Id,
+		)
+	}
+}
@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"payment-gateway/db/schema"
+	"payment-gateway/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubAccounts struct {
+	accounts []schema.Account
+	err      error
+}
+
+func (s stubAccounts) List(ctx context.Context) ([]schema.Account, error) {
+	return s.accounts, s.err
+}
+
+func TestRecordTransaction(t *testing.T) {
+	counter := transactions.WithLabelValues("IMPS", "failed")
+	before := testutil.ToFloat64(counter)
+
+	RecordTransaction(models.PaymentChannelIMPS, models.TransactionStatusFailed)
+
+	assert.Equal(t, before+1, testutil.ToFloat64(counter))
+}
+
+func TestProcessorQueue(t *testing.T) {
+	before := testutil.ToFloat64(processorQueue)
+
+	ProcessorQueued()
+	ProcessorQueued()
+	assert.Equal(t, before+2, testutil.ToFloat64(processorQueue))
+
+	ProcessorReceived()
+	assert.Equal(t, before+1, testutil.ToFloat64(processorQueue))
+}
+
+func TestObserveProcessingDelay(t *testing.T) {
+	ObserveProcessingDelay(models.PaymentChannelNEFT, 300*time.Millisecond)
+
+	body := scrape(t)
+	assert.Contains(t, body, `gateway_processing_delay_seconds_bucket{channel="NEFT",le="0.2"} 0`)
+	assert.Contains(t, body, `gateway_processing_delay_seconds_bucket{channel="NEFT",le="0.4"} 1`)
+}
+
+func TestObserveNotification(t *testing.T) {
+	ObserveNotification("http://disbursement:7070/api/v1/payment/notify", 40*time.Millisecond, true)
+	ObserveNotification("http://disbursement:7070/api/v1/payment/notify?id=1", time.Second, false)
+	ObserveNotification("not a url", time.Millisecond, false)
+
+	body := scrape(t)
+	assert.Contains(t, body,
+		`gateway_notifications_total{destination="disbursement:7070",outcome="success"} 1`)
+	assert.Contains(t, body,
+		`gateway_notifications_total{destination="disbursement:7070",outcome="failure"} 1`)
+	assert.Contains(t, body,
+		`gateway_notifications_total{destination="invalid",outcome="failure"} 1`)
+	assert.Contains(t, body,
+		`gateway_notification_duration_seconds_count{destination="disbursement:7070"} 2`)
+}
+
+func TestRecordAvailability(t *testing.T) {
+	RecordAvailability(models.PaymentChannelUPI, true)
+	assert.Equal(t, 1.0, testutil.ToFloat64(channelAvailable.WithLabelValues("UPI")))
+
+	RecordAvailability(models.PaymentChannelUPI, false)
+	assert.Equal(t, 0.0, testutil.ToFloat64(channelAvailable.WithLabelValues("UPI")))
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(availabilityChecks.WithLabelValues("UPI", "true")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(availabilityChecks.WithLabelValues("UPI", "false")))
+}
+
+func TestBalanceCollector(t *testing.T) {
+	t.Run("reports each account", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(newBalanceCollector(stubAccounts{
+			accounts: []schema.Account{
+				{Id: "ACC-1", Balance: 2500000},
+				{Id: "ACC-2", Balance: 0},
+			},
+		}))
+
+		expected := `
+# HELP gateway_account_balance Current balance of each gateway account.
+# TYPE gateway_account_balance gauge
+gateway_account_balance{account="ACC-1"} 2.5e+06
+gateway_account_balance{account="ACC-2"} 0
+`
+		assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected)))
+	})
+
+	t.Run("reports a list failure", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(newBalanceCollector(stubAccounts{err: errors.New("db down")}))
+
+		_, err := registry.Gather()
+		assert.ErrorContains(t, err, "db down")
+	})
+}
+
+func scrape(t *testing.T) string {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	return recorder.Body.String()
+}
@@ -8,12 +8,12 @@ type Account struct {
 }
 
 type CreateAccountRequest struct {
-	Name      string  `json:"name"      binding:"required"`
-	Balance   float64 `json:"balance"   binding:"required"`
-	Threshold float64 `json:"threshold" binding:"required"`
+	Name      string  `json:"name"      validate:"notblank,max=200"`
+	Balance   float64 `json:"balance"   validate:"gte=0"`
+	Threshold float64 `json:"threshold" validate:"gte=0"`
 }
 
 type UpdateAccountRequest struct {
-	Balance   float64  `json:"balance"   binding:"required"`
-	Threshold *float64 `json:"threshold"`
+	Balance   float64  `json:"balance"   validate:"gte=0"`
+	Threshold *float64 `json:"threshold" validate:"omitempty,gte=0"`
 }
@@ -1,8 +1,8 @@
 package models
 
 type Beneficiary struct {
-	Name    string `json:"name"`
-	Account string `json:"account"`
-	IFSC    string `json:"ifsc"`
-	Bank    string `json:"bank"`
+	Name    string `json:"name" pii:"name" validate:"notblank,max=200"`
+	Account string `json:"account" pii:"account" validate:"required,account_number"`
+	IFSC    string `json:"ifsc" validate:"required,ifsc"`
+	Bank    string `json:"bank" validate:"max=100"`
 }
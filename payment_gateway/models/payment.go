@@ -1,9 +1,9 @@
 package models
 
 type PaymentRequest struct {
-	ReferenceID string         `json:"reference_id"`
-	Amount      float64        `json:"amount"`
-	Channel     PaymentChannel `json:"channel"`
+	ReferenceID string         `json:"reference_id" validate:"required,max=64"`
+	Amount      float64        `json:"amount" validate:"gt=0,lte=100000000"`
+	Channel     PaymentChannel `json:"channel" validate:"required"`
 	Beneficiary Beneficiary    `json:"beneficiary"`
 	Metadata    map[string]any `json:"metadata"`
 }
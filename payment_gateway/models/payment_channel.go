@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type PaymentChannel string
 
 const (
@@ -9,16 +11,16 @@ const (
 )
 
 type CreatePaymentChannelRequest struct {
-	Channel     PaymentChannel `json:"channel"      binding:"required"`
-	Limit       float64        `json:"limit"        binding:"required"`
-	SuccessRate float64        `json:"success_rate" binding:"required"`
-	Fee         float64        `json:"fee"          binding:"required"`
+	Channel     PaymentChannel `json:"channel"      validate:"required,oneof=UPI NEFT IMPS"`
+	Limit       float64        `json:"limit"        validate:"gt=0"`
+	SuccessRate float64        `json:"success_rate" validate:"gte=0,lte=1"`
+	Fee         float64        `json:"fee"          validate:"gte=0"`
 }
 
 type UpdatePaymentChannelRequest struct {
-	Limit       *float64 `json:"limit"        binding:"required"`
-	SuccessRate *float64 `json:"success_rate" binding:"required"`
-	Fee         *float64 `json:"fee"          binding:"required"`
+	Limit       *float64 `json:"limit"        validate:"omitempty,gt=0"`
+	SuccessRate *float64 `json:"success_rate" validate:"omitempty,gte=0,lte=1"`
+	Fee         *float64 `json:"fee"          validate:"omitempty,gte=0"`
 }
 
 type PaymentChannelResponse struct {
@@ -28,3 +30,12 @@ type PaymentChannelResponse struct {
 	SuccessRate float64        `json:"success_rate"`
 	Fee         float64        `json:"fee"`
 }
+
+// ChannelAvailabilityResponse carries the next NEFT window and the batch a
+// payment made now would settle in; both are omitted for instant channels.
+type ChannelAvailabilityResponse struct {
+	Channel              PaymentChannel `json:"channel"`
+	Available            bool           `json:"available"`
+	NextWindowAt         *time.Time     `json:"next_window_at,omitempty"`
+	ExpectedSettlementAt *time.Time     `json:"expected_settlement_at,omitempty"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// PennyDropAmount is the amount sent to the beneficiary to validate the account.
+const PennyDropAmount = 1.0
+
+// PennyDropPurpose is the purpose recorded in the metadata of penny drop
+// transactions.
+const PennyDropPurpose = "penny_drop"
+
+type AccountVerificationRequest struct {
+	ReferenceID string      `json:"reference_id" validate:"required,max=64"`
+	Beneficiary Beneficiary `json:"beneficiary"`
+}
+
+type AccountVerificationResponse struct {
+	TransactionID  string            `json:"transaction_id"`
+	ReferenceID    string            `json:"reference_id"`
+	Account        string            `json:"account" pii:"account"`
+	IFSC           string            `json:"ifsc"`
+	RegisteredName string            `json:"registered_name,omitempty" pii:"name"`
+	Status         TransactionStatus `json:"status"`
+	VerifiedAt     time.Time         `json:"verified_at"`
+}
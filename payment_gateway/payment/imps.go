@@ -8,8 +8,11 @@ import (
 	"payment-gateway/db/daos"
 	"payment-gateway/db/schema"
 	"payment-gateway/failures"
+	"payment-gateway/metrics"
 	"payment-gateway/models"
 	"payment-gateway/utils"
+	"shared/logging"
+	"shared/tracing"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -43,7 +46,6 @@ func NewIMPSProvider(
 
 func (i *IMPSProvider) ValidateLimit(amount float64) error {
 	if amount > i.limit {
-		log.Warn().Msgf("amount exceeds limit: %f > %f", amount, i.limit)
 		return failures.LIMIT_EXCEEDED
 	}
 	return nil
@@ -56,10 +58,10 @@ func (i *IMPSProvider) GetTransaction(
 	transaction, err := i.transaction.Get(ctx, transactionID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Warn().Msgf("transaction not found: %s: %s", transactionID, err)
+			log.Ctx(ctx).Warn().Err(err).Msg("transaction not found")
 			return nil, failures.TRANSACTION_NOT_FOUND
 		}
-		log.Error().Msgf("failed to get transaction: %s: %s", transactionID, err)
+		log.Ctx(ctx).Error().Err(err).Msg("failed to get transaction")
 		return nil, errors.New("failed to get transaction")
 	}
 	return toModelTransaction(transaction), nil
@@ -70,6 +72,7 @@ func (i *IMPSProvider) Transfer(
 	request models.PaymentRequest,
 ) (*models.Transaction, error) {
 	transactionId := i.idGenerator.GenerateIMPSTransactionId()
+	ctx = logging.With(ctx, logging.Fields{TransactionId: transactionId})
 	newTransaction := schema.Transaction{
 		ID:              transactionId,
 		ReferenceID:     request.ReferenceID,
@@ -81,16 +84,20 @@ func (i *IMPSProvider) Transfer(
 		IFSCCode:        request.Beneficiary.IFSC,
 		BankName:        request.Beneficiary.Bank,
 		Metadata:        request.Metadata,
+		TraceParent:     tracing.TraceParent(ctx),
 		Status:          models.TransactionStatusInitiated,
 		Message:         nil,
 	}
 	transaction, err := i.transaction.Create(ctx, newTransaction)
 	if err != nil {
-		log.Error().Msgf("failed to create transaction: %s: %s", transactionId, err)
+		log.Ctx(ctx).Error().Err(err).Msg("failed to create transaction")
 		return nil, fmt.Errorf("failed to create transaction: %s", err)
 	}
 
+	log.Ctx(ctx).Info().Float64("amount", request.Amount).Msg("transaction initiated")
 	delay := time.Duration(rand.Intn(1500)) * time.Millisecond
+	metrics.RecordTransaction(models.PaymentChannelIMPS, models.TransactionStatusInitiated)
+	metrics.ProcessorQueued()
 	i.processor <- models.ProcessorMessage{
 		TransactionID: transactionId,
 		SuccessRate:   i.successRate,
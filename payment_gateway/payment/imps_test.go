@@ -232,7 +232,7 @@ func TestIMPSProvider_Transfer(t *testing.T) {
 			UpdatedAt:       time.Now(),
 		}
 
-		mockTransaction.On("Create", ctx, mock.AnythingOfType("schema.Transaction")).
+		mockTransaction.On("Create", mock.Anything, mock.AnythingOfType("schema.Transaction")).
 			Return(expectedSchemaTransaction, nil).Once()
 
 		result, err := provider.Transfer(ctx, request)
@@ -299,7 +299,7 @@ func TestIMPSProvider_Transfer(t *testing.T) {
 			UpdatedAt:       time.Now(),
 		}
 
-		mockTransaction.On("Create", ctx, mock.AnythingOfType("schema.Transaction")).
+		mockTransaction.On("Create", mock.Anything, mock.AnythingOfType("schema.Transaction")).
 			Return(expectedSchemaTransaction, nil).Once()
 
 		result, err := provider.Transfer(ctx, request)
@@ -340,7 +340,7 @@ func TestIMPSProvider_Transfer(t *testing.T) {
 
 		repoError := errors.New("database connection error")
 
-		mockTransaction.On("Create", ctx, mock.AnythingOfType("schema.Transaction")).
+		mockTransaction.On("Create", mock.Anything, mock.AnythingOfType("schema.Transaction")).
 			Return(schema.Transaction{}, repoError).Once()
 
 		result, err := provider.Transfer(ctx, request)
@@ -398,7 +398,7 @@ func TestIMPSProvider_Transfer(t *testing.T) {
 			UpdatedAt:       time.Now(),
 		}
 
-		mockTransaction.On("Create", ctx, mock.MatchedBy(func(tx schema.Transaction) bool {
+		mockTransaction.On("Create", mock.Anything, mock.MatchedBy(func(tx schema.Transaction) bool {
 			return tx.Fee == fee &&
 				tx.ReferenceID == request.ReferenceID &&
 				tx.Amount == request.Amount &&
@@ -459,7 +459,7 @@ func TestIMPSProvider_Transfer(t *testing.T) {
 			UpdatedAt:       time.Now(),
 		}
 
-		mockTransaction.On("Create", ctx, mock.MatchedBy(func(tx schema.Transaction) bool {
+		mockTransaction.On("Create", mock.Anything, mock.MatchedBy(func(tx schema.Transaction) bool {
 			return tx.Amount == 0.0
 		})).Return(expectedSchemaTransaction, nil).Once()
 
@@ -8,8 +8,11 @@ import (
 	"payment-gateway/db/daos"
 	"payment-gateway/db/schema"
 	"payment-gateway/failures"
+	"payment-gateway/metrics"
 	"payment-gateway/models"
 	"payment-gateway/utils"
+	"shared/logging"
+	"shared/tracing"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -52,10 +55,10 @@ func (n *NEFTProvider) GetTransaction(
 	transaction, err := n.transaction.Get(ctx, transactionID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Warn().Msgf("transaction not found: %s: %s", transactionID, err)
+			log.Ctx(ctx).Warn().Err(err).Msg("transaction not found")
 			return nil, failures.TRANSACTION_NOT_FOUND
 		}
-		log.Error().Msgf("failed to get transaction: %s: %s", transactionID, err)
+		log.Ctx(ctx).Error().Err(err).Msg("failed to get transaction")
 		return nil, errors.New("failed to get transaction")
 	}
 	return toModelTransaction(transaction), nil
@@ -66,6 +69,7 @@ func (n *NEFTProvider) Transfer(
 	request models.PaymentRequest,
 ) (*models.Transaction, error) {
 	transactionId := n.idGenerator.GenerateNEFTTransactionId()
+	ctx = logging.With(ctx, logging.Fields{TransactionId: transactionId})
 	newTransaction := schema.Transaction{
 		ID:              transactionId,
 		ReferenceID:     request.ReferenceID,
@@ -77,16 +81,20 @@ func (n *NEFTProvider) Transfer(
 		IFSCCode:        request.Beneficiary.IFSC,
 		BankName:        request.Beneficiary.Bank,
 		Metadata:        request.Metadata,
+		TraceParent:     tracing.TraceParent(ctx),
 		Status:          models.TransactionStatusInitiated,
 		Message:         nil,
 	}
 	transaction, err := n.transaction.Create(ctx, newTransaction)
 	if err != nil {
-		log.Error().Msgf("failed to create transaction: %s: %s", transactionId, err)
+		log.Ctx(ctx).Error().Err(err).Msg("failed to create transaction")
 		return nil, fmt.Errorf("failed to create transaction: %s", err)
 	}
 
+	log.Ctx(ctx).Info().Float64("amount", request.Amount).Msg("transaction initiated")
 	delay := time.Duration(rand.Intn(1500)) * time.Millisecond
+	metrics.RecordTransaction(models.PaymentChannelNEFT, models.TransactionStatusInitiated)
+	metrics.ProcessorQueued()
 	n.processor <- models.ProcessorMessage{
 		TransactionID: transactionId,
 		SuccessRate:   n.successRate,
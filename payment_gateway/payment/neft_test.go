@@ -194,7 +194,7 @@ func TestNEFTProvider_Transfer(t *testing.T) {
 			UpdatedAt:       time.Now(),
 		}
 
-		mockTransaction.On("Create", ctx, mock.AnythingOfType("schema.Transaction")).
+		mockTransaction.On("Create", mock.Anything, mock.AnythingOfType("schema.Transaction")).
 			Return(expectedSchemaTransaction, nil).Once()
 
 		result, err := provider.Transfer(ctx, request)
@@ -254,7 +254,7 @@ func TestNEFTProvider_Transfer(t *testing.T) {
 			UpdatedAt:       time.Now(),
 		}
 
-		mockTransaction.On("Create", ctx, mock.AnythingOfType("schema.Transaction")).
+		mockTransaction.On("Create", mock.Anything, mock.AnythingOfType("schema.Transaction")).
 			Return(expectedSchemaTransaction, nil).Once()
 
 		result, err := provider.Transfer(ctx, request)
@@ -288,7 +288,7 @@ func TestNEFTProvider_Transfer(t *testing.T) {
 
 		repoError := errors.New("database connection error")
 
-		mockTransaction.On("Create", ctx, mock.AnythingOfType("schema.Transaction")).
+		mockTransaction.On("Create", mock.Anything, mock.AnythingOfType("schema.Transaction")).
 			Return(schema.Transaction{}, repoError).Once()
 
 		result, err := provider.Transfer(ctx, request)
@@ -339,7 +339,7 @@ func TestNEFTProvider_Transfer(t *testing.T) {
 			UpdatedAt:       time.Now(),
 		}
 
-		mockTransaction.On("Create", ctx, mock.MatchedBy(func(tx schema.Transaction) bool {
+		mockTransaction.On("Create", mock.Anything, mock.MatchedBy(func(tx schema.Transaction) bool {
 			return tx.Fee == fee &&
 				tx.ReferenceID == request.ReferenceID &&
 				tx.Amount == request.Amount &&
@@ -392,7 +392,7 @@ func TestNEFTProvider_Transfer(t *testing.T) {
 			UpdatedAt:       time.Now(),
 		}
 
-		mockTransaction.On("Create", ctx, mock.MatchedBy(func(tx schema.Transaction) bool {
+		mockTransaction.On("Create", mock.Anything, mock.MatchedBy(func(tx schema.Transaction) bool {
 			return tx.Amount == 0.0
 		})).Return(expectedSchemaTransaction, nil).Once()
 
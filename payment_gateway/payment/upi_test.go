@@ -213,7 +213,7 @@ func TestUPIProvider_Transfer(t *testing.T) {
 
 		// Note: The implementation uses its own idGenerator, not the one passed in
 		// So we can't mock the transaction ID generation
-		mockTransaction.On("Create", ctx, mock.AnythingOfType("schema.Transaction")).
+		mockTransaction.On("Create", mock.Anything, mock.AnythingOfType("schema.Transaction")).
 			Return(expectedSchemaTransaction, nil).Once()
 
 		result, err := provider.Transfer(ctx, request)
@@ -273,7 +273,7 @@ func TestUPIProvider_Transfer(t *testing.T) {
 			UpdatedAt:       time.Now(),
 		}
 
-		mockTransaction.On("Create", ctx, mock.AnythingOfType("schema.Transaction")).
+		mockTransaction.On("Create", mock.Anything, mock.AnythingOfType("schema.Transaction")).
 			Return(expectedSchemaTransaction, nil).Once()
 
 		result, err := provider.Transfer(ctx, request)
@@ -307,7 +307,7 @@ func TestUPIProvider_Transfer(t *testing.T) {
 
 		repoError := errors.New("database connection error")
 
-		mockTransaction.On("Create", ctx, mock.AnythingOfType("schema.Transaction")).
+		mockTransaction.On("Create", mock.Anything, mock.AnythingOfType("schema.Transaction")).
 			Return(schema.Transaction{}, repoError).Once()
 
 		result, err := provider.Transfer(ctx, request)
@@ -358,7 +358,7 @@ func TestUPIProvider_Transfer(t *testing.T) {
 			UpdatedAt:       time.Now(),
 		}
 
-		mockTransaction.On("Create", ctx, mock.MatchedBy(func(tx schema.Transaction) bool {
+		mockTransaction.On("Create", mock.Anything, mock.MatchedBy(func(tx schema.Transaction) bool {
 			return tx.Fee == fee &&
 				tx.ReferenceID == request.ReferenceID &&
 				tx.Amount == request.Amount &&
@@ -412,7 +412,7 @@ func TestUPIProvider_Transfer(t *testing.T) {
 			UpdatedAt:       time.Now(),
 		}
 
-		mockTransaction.On("Create", ctx, mock.MatchedBy(func(tx schema.Transaction) bool {
+		mockTransaction.On("Create", mock.Anything, mock.MatchedBy(func(tx schema.Transaction) bool {
 			return tx.Amount == 0.0
 		})).Return(expectedSchemaTransaction, nil).Once()
 
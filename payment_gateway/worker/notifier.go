@@ -2,6 +2,13 @@ package worker
 
 import (
 	"context"
+	"fmt"
+	"net/url"
+	"payment-gateway/metrics"
+	"payment-gateway/models"
+	"shared/logging"
+	"shared/tracing"
+	"strings"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -10,14 +17,33 @@ import (
 func (w *Worker) Notify(ctx context.Context, transactionID string) {
 	transaction, err := w.transaction.Get(ctx, transactionID)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to get transaction")
+		log.Ctx(logging.With(ctx, logging.Fields{TransactionId: transactionID})).
+			Error().Err(err).Msg("failed to get transaction")
+		return
+	}
+
+	ctx, span := tracing.Resume(ctx, transaction.TraceParent, "notifier.notify",
+		tracing.TransactionIdKey.String(transaction.ID),
+		tracing.ReferenceIdKey.String(transaction.ReferenceID),
+	)
+	defer span.End()
+	ctx = withTransaction(ctx, transaction)
+
+	// A penny drop is answered on the verification call that made it.
+	if transaction.Metadata["purpose"] == models.PennyDropPurpose {
 		return
 	}
 
 	notificationURL, ok := transaction.Metadata["notification_url"].(string)
 	if !ok {
-		log.Error().
-			Msgf("notification URL not found in metadata for transaction: %s", transactionID)
+		log.Ctx(ctx).Error().Msg("notification URL not found in metadata")
+		return
+	}
+	// The URL comes from the caller, and the client presents the notify
+	// credential, so it is only sent to the configured service.
+	if !w.allowsNotification(notificationURL) {
+		log.Ctx(ctx).Error().Str("url", notificationURL).
+			Msg("notification URL is outside the notification base URL")
 		return
 	}
 
@@ -34,6 +60,7 @@ func (w *Worker) Notify(ctx context.Context, transactionID string) {
 		"processed_at":   transaction.ProcessedAt,
 	}
 
+	start := time.Now()
 	resp, err := w.httpClient.POST(
 		ctx,
 		notificationURL,
@@ -42,18 +69,26 @@ func (w *Worker) Notify(ctx context.Context, transactionID string) {
 			"Content-Type": "application/json",
 		},
 	)
+	metrics.ObserveNotification(
+		notificationURL,
+		time.Since(start),
+		err == nil && resp.StatusCode >= 200 && resp.StatusCode < 300,
+	)
 	if err != nil {
-		log.Error().
+		tracing.Record(span, err)
+		log.Ctx(ctx).Error().
 			Err(err).
-			Msgf("failed to send notification for transaction: %s to URL: %s", transactionID, notificationURL)
+			Str("url", notificationURL).
+			Msg("failed to send notification")
 		return
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		log.Error().
+		tracing.Record(span, fmt.Errorf("notification returned status %d", resp.StatusCode))
+		log.Ctx(ctx).Error().
 			Int("status_code", resp.StatusCode).
 			Str("url", notificationURL).
-			Msgf("notification request failed for transaction: %s", transactionID)
+			Msg("notification request failed")
 		return
 	}
 
@@ -62,12 +97,31 @@ func (w *Worker) Notify(ctx context.Context, transactionID string) {
 		"updated_at":  time.Now(),
 	})
 	if err != nil {
-		log.Error().Err(err).Msgf("failed to update notified_at for transaction: %s", transactionID)
+		log.Ctx(ctx).Error().Err(err).Msg("failed to update notified_at")
 		return
 	}
 
-	log.Info().
+	log.Ctx(ctx).Info().
 		Str("url", notificationURL).
 		Int("status_code", resp.StatusCode).
-		Msgf("Notification sent successfully for transaction: %s", transactionID)
+		Str("status", string(transaction.Status)).
+		Msg("Notification sent successfully")
+}
+
+// allowsNotification reports whether target has the scheme and host of the
+// notification base URL and lies under its path.
+func (w *Worker) allowsNotification(target string) bool {
+	base := w.notificationBase
+	if base == nil {
+		return false
+	}
+	parsed, err := url.Parse(target)
+	if err != nil || parsed.User != nil {
+		return false
+	}
+	if parsed.Scheme != base.Scheme || !strings.EqualFold(parsed.Host, base.Host) {
+		return false
+	}
+	prefix := strings.TrimSuffix(base.Path, "/")
+	return parsed.Path == prefix || strings.HasPrefix(parsed.Path, prefix+"/")
 }
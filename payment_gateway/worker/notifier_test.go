@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"net/url"
 	"payment-gateway/db/schema"
 	"payment-gateway/models"
 	db_test "payment-gateway/test/db"
@@ -15,6 +16,8 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
+var notificationBase, _ = url.Parse("https://example.com/")
+
 func TestWorker_Notify(t *testing.T) {
 	ctx := context.Background()
 	transactionID := "TXN-123456789012"
@@ -24,8 +27,9 @@ func TestWorker_Notify(t *testing.T) {
 		mockHTTPClient := new(http_test.MockHTTPClient)
 
 		worker := &Worker{
-			transaction: mockTransactionRepo,
-			httpClient:  mockHTTPClient,
+			transaction:      mockTransactionRepo,
+			httpClient:       mockHTTPClient,
+			notificationBase: notificationBase,
 		}
 
 		notificationURL := "https://example.com/webhook"
@@ -59,9 +63,9 @@ func TestWorker_Notify(t *testing.T) {
 		updatedTransaction.NotifiedAt = &notifiedAt
 
 		mockTransactionRepo.On("Get", ctx, transactionID).Return(transaction, nil).Once()
-		mockHTTPClient.On("POST", ctx, notificationURL, mock.Anything, mock.Anything).
+		mockHTTPClient.On("POST", mock.Anything, notificationURL, mock.Anything, mock.Anything).
 			Return(mockResponse, nil).Once()
-		mockTransactionRepo.On("Update", ctx, transactionID, mock.MatchedBy(func(fields map[string]any) bool {
+		mockTransactionRepo.On("Update", mock.Anything, transactionID, mock.MatchedBy(func(fields map[string]any) bool {
 			_, hasNotifiedAt := fields["notified_at"]
 			_, hasUpdatedAt := fields["updated_at"]
 			return hasNotifiedAt && hasUpdatedAt
@@ -80,8 +84,9 @@ func TestWorker_Notify(t *testing.T) {
 		mockHTTPClient := new(http_test.MockHTTPClient)
 
 		worker := &Worker{
-			transaction: mockTransactionRepo,
-			httpClient:  mockHTTPClient,
+			transaction:      mockTransactionRepo,
+			httpClient:       mockHTTPClient,
+			notificationBase: notificationBase,
 		}
 
 		repoError := errors.New("transaction not found")
@@ -101,8 +106,9 @@ func TestWorker_Notify(t *testing.T) {
 		mockHTTPClient := new(http_test.MockHTTPClient)
 
 		worker := &Worker{
-			transaction: mockTransactionRepo,
-			httpClient:  mockHTTPClient,
+			transaction:      mockTransactionRepo,
+			httpClient:       mockHTTPClient,
+			notificationBase: notificationBase,
 		}
 
 		transaction := schema.Transaction{
@@ -126,8 +132,9 @@ func TestWorker_Notify(t *testing.T) {
 		mockHTTPClient := new(http_test.MockHTTPClient)
 
 		worker := &Worker{
-			transaction: mockTransactionRepo,
-			httpClient:  mockHTTPClient,
+			transaction:      mockTransactionRepo,
+			httpClient:       mockHTTPClient,
+			notificationBase: notificationBase,
 		}
 
 		transaction := schema.Transaction{
@@ -153,8 +160,9 @@ func TestWorker_Notify(t *testing.T) {
 		mockHTTPClient := new(http_test.MockHTTPClient)
 
 		worker := &Worker{
-			transaction: mockTransactionRepo,
-			httpClient:  mockHTTPClient,
+			transaction:      mockTransactionRepo,
+			httpClient:       mockHTTPClient,
+			notificationBase: notificationBase,
 		}
 
 		notificationURL := "https://example.com/webhook"
@@ -167,7 +175,7 @@ func TestWorker_Notify(t *testing.T) {
 
 		httpError := errors.New("network error")
 		mockTransactionRepo.On("Get", ctx, transactionID).Return(transaction, nil).Once()
-		mockHTTPClient.On("POST", ctx, notificationURL, mock.Anything, mock.Anything).
+		mockHTTPClient.On("POST", mock.Anything, notificationURL, mock.Anything, mock.Anything).
 			Return(nil, httpError).Once()
 
 		worker.Notify(ctx, transactionID)
@@ -182,8 +190,9 @@ func TestWorker_Notify(t *testing.T) {
 		mockHTTPClient := new(http_test.MockHTTPClient)
 
 		worker := &Worker{
-			transaction: mockTransactionRepo,
-			httpClient:  mockHTTPClient,
+			transaction:      mockTransactionRepo,
+			httpClient:       mockHTTPClient,
+			notificationBase: notificationBase,
 		}
 
 		notificationURL := "https://example.com/webhook"
@@ -200,7 +209,7 @@ func TestWorker_Notify(t *testing.T) {
 		}
 
 		mockTransactionRepo.On("Get", ctx, transactionID).Return(transaction, nil).Once()
-		mockHTTPClient.On("POST", ctx, notificationURL, mock.Anything, mock.Anything).
+		mockHTTPClient.On("POST", mock.Anything, notificationURL, mock.Anything, mock.Anything).
 			Return(mockResponse, nil).Once()
 
 		worker.Notify(ctx, transactionID)
@@ -215,8 +224,9 @@ func TestWorker_Notify(t *testing.T) {
 		mockHTTPClient := new(http_test.MockHTTPClient)
 
 		worker := &Worker{
-			transaction: mockTransactionRepo,
-			httpClient:  mockHTTPClient,
+			transaction:      mockTransactionRepo,
+			httpClient:       mockHTTPClient,
+			notificationBase: notificationBase,
 		}
 
 		notificationURL := "https://example.com/webhook"
@@ -233,9 +243,9 @@ func TestWorker_Notify(t *testing.T) {
 
 		updateError := errors.New("database error")
 		mockTransactionRepo.On("Get", ctx, transactionID).Return(transaction, nil).Once()
-		mockHTTPClient.On("POST", ctx, notificationURL, mock.Anything, mock.Anything).
+		mockHTTPClient.On("POST", mock.Anything, notificationURL, mock.Anything, mock.Anything).
 			Return(mockResponse, nil).Once()
-		mockTransactionRepo.On("Update", ctx, transactionID, mock.Anything).
+		mockTransactionRepo.On("Update", mock.Anything, transactionID, mock.Anything).
 			Return(schema.Transaction{}, updateError).Once()
 
 		worker.Notify(ctx, transactionID)
@@ -249,8 +259,9 @@ func TestWorker_Notify(t *testing.T) {
 		mockHTTPClient := new(http_test.MockHTTPClient)
 
 		worker := &Worker{
-			transaction: mockTransactionRepo,
-			httpClient:  mockHTTPClient,
+			transaction:      mockTransactionRepo,
+			httpClient:       mockHTTPClient,
+			notificationBase: notificationBase,
 		}
 
 		notificationURL := "https://example.com/webhook"
@@ -283,7 +294,7 @@ func TestWorker_Notify(t *testing.T) {
 		updatedTransaction.NotifiedAt = &notifiedAt
 
 		mockTransactionRepo.On("Get", ctx, transactionID).Return(transaction, nil).Once()
-		mockHTTPClient.On("POST", ctx, notificationURL, mock.MatchedBy(func(payload map[string]any) bool {
+		mockHTTPClient.On("POST", mock.Anything, notificationURL, mock.MatchedBy(func(payload map[string]any) bool {
 			return assert.Equal(t, transactionID, payload["transaction_id"]) &&
 				assert.Equal(t, "REF-123", payload["reference_id"]) &&
 				assert.Equal(t, float64(1000.0), payload["amount"]) &&
@@ -294,7 +305,7 @@ func TestWorker_Notify(t *testing.T) {
 		}), mock.Anything).
 			Return(mockResponse, nil).
 			Once()
-		mockTransactionRepo.On("Update", ctx, transactionID, mock.Anything).
+		mockTransactionRepo.On("Update", mock.Anything, transactionID, mock.Anything).
 			Return(updatedTransaction, nil).Once()
 
 		worker.Notify(ctx, transactionID)
@@ -303,3 +314,47 @@ func TestWorker_Notify(t *testing.T) {
 		mockHTTPClient.AssertExpectations(t)
 	})
 }
+
+func TestWorker_NotifyOutsideBase(t *testing.T) {
+	ctx := context.Background()
+	transactionID := "TXN-123456789012"
+
+	for name, target := range map[string]string{
+		"another host":       "https://attacker.example/webhook",
+		"another scheme":     "http://example.com/webhook",
+		"credentials in url": "https://user@example.com/webhook",
+	} {
+		t.Run("refuses "+name, func(t *testing.T) {
+			mockTransactionRepo := new(db_test.MockTransactionRepository)
+			mockHTTPClient := new(http_test.MockHTTPClient)
+			worker := &Worker{
+				transaction:      mockTransactionRepo,
+				httpClient:       mockHTTPClient,
+				notificationBase: notificationBase,
+			}
+			mockTransactionRepo.On("Get", ctx, transactionID).Return(schema.Transaction{
+				ID:       transactionID,
+				Metadata: map[string]any{"notification_url": target},
+			}, nil).Once()
+
+			worker.Notify(ctx, transactionID)
+
+			mockHTTPClient.AssertNotCalled(t, "POST")
+			mockTransactionRepo.AssertNotCalled(t, "Update")
+		})
+	}
+
+	t.Run("refuses every URL without a base", func(t *testing.T) {
+		worker := &Worker{}
+
+		assert.False(t, worker.allowsNotification("https://example.com/webhook"))
+	})
+
+	t.Run("stays under the base path", func(t *testing.T) {
+		base, _ := url.Parse("https://example.com/api/v1")
+		worker := &Worker{notificationBase: base}
+
+		assert.True(t, worker.allowsNotification("https://EXAMPLE.com/api/v1/payment/notify"))
+		assert.False(t, worker.allowsNotification("https://example.com/api/v10/payment/notify"))
+	})
+}
@@ -6,54 +6,80 @@ import (
 	"math/rand"
 	"payment-gateway/db/schema"
 	"payment-gateway/failures"
+	"payment-gateway/metrics"
 	"payment-gateway/models"
+	"shared/logging"
+	"shared/tracing"
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 )
 
 func (w *Worker) Process(ctx context.Context, message models.ProcessorMessage) {
 	transaction, err := w.transaction.Get(ctx, message.TransactionID)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to get transaction")
+		log.Ctx(logging.With(ctx, logging.Fields{TransactionId: message.TransactionID})).
+			Error().Err(err).Msg("failed to get transaction")
 		return
 	}
 
 	if transaction.Status != models.TransactionStatusInitiated {
-		log.Info().Msgf("Transaction already processed: %s", message.TransactionID)
+		log.Ctx(withTransaction(ctx, transaction)).Info().
+			Str("status", string(transaction.Status)).
+			Msg("Transaction already processed")
 		return
 	}
 
-	log.Info().Msgf("Processing transaction: %s", message.TransactionID)
+	ctx, span := tracing.Resume(ctx, transaction.TraceParent, "processor.process",
+		tracing.TransactionIdKey.String(transaction.ID),
+		tracing.ReferenceIdKey.String(transaction.ReferenceID),
+	)
+	defer span.End()
+	ctx = withTransaction(ctx, transaction)
+
+	log.Ctx(ctx).Info().Msg("Processing transaction")
 	_, err = w.transaction.Update(ctx, message.TransactionID, map[string]any{
 		"status":     models.TransactionStatusProcessing,
 		"updated_at": time.Now(),
 	})
 	if err != nil {
-		log.Error().Err(err).Msg("failed to update transaction")
+		log.Ctx(ctx).Error().Err(err).Msg("failed to update transaction")
 		return
 	}
 
-	time.Sleep(time.Duration(message.Delay) * time.Millisecond)
+	delay := time.Duration(message.Delay) * time.Millisecond
+	time.Sleep(delay)
+	metrics.ObserveProcessingDelay(transaction.Channel, delay)
 
 	if rand.Float64() > message.SuccessRate {
-		log.Info().Msgf("Transaction failed: %s", message.TransactionID)
 		reason := w.getFailureReason()
-		w.markTransactionAsFailed(ctx, message.TransactionID, reason)
+		log.Ctx(ctx).Info().Str("reason", reason).Msg("Transaction failed")
+		w.markTransactionAsFailed(ctx, transaction.Channel, message.TransactionID, reason)
 		return
 	}
 
 	accounts, err := w.account.List(ctx)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to get account")
-		w.markTransactionAsFailed(ctx, message.TransactionID, failures.UNKNOWN_ERROR.Error())
+		log.Ctx(ctx).Error().Err(err).Msg("failed to get account")
+		w.markTransactionAsFailed(
+			ctx,
+			transaction.Channel,
+			message.TransactionID,
+			failures.UNKNOWN_ERROR.Error(),
+		)
 		return
 	}
 
 	if len(accounts) == 0 {
-		log.Error().Msg("no account found")
-		w.markTransactionAsFailed(ctx, message.TransactionID, failures.UNKNOWN_ERROR.Error())
+		log.Ctx(ctx).Error().Msg("no account found")
+		w.markTransactionAsFailed(
+			ctx,
+			transaction.Channel,
+			message.TransactionID,
+			failures.UNKNOWN_ERROR.Error(),
+		)
 		return
 	}
 
@@ -61,8 +87,13 @@ func (w *Worker) Process(ctx context.Context, message models.ProcessorMessage) {
 	totalAmount := transaction.Amount + transaction.Fee
 
 	if account.Balance < totalAmount {
-		log.Warn().Msgf("Transaction failed due to insufficient balance: %s", message.TransactionID)
-		w.markTransactionAsFailed(ctx, message.TransactionID, failures.INSUFFICIENT_BALANCE.Error())
+		log.Ctx(ctx).Warn().Msg("Transaction failed due to insufficient balance")
+		w.markTransactionAsFailed(
+			ctx,
+			transaction.Channel,
+			message.TransactionID,
+			failures.INSUFFICIENT_BALANCE.Error(),
+		)
 		return
 	}
 
@@ -76,8 +107,8 @@ func (w *Worker) Process(ctx context.Context, message models.ProcessorMessage) {
 		}
 
 		if result.RowsAffected == 0 {
-			log.Warn().
-				Msgf("Account balance update affected 0 rows - balance likely changed by concurrent transaction: %s", message.TransactionID)
+			log.Ctx(ctx).Warn().
+				Msg("Account balance update affected 0 rows - balance likely changed by concurrent transaction")
 			return failures.INSUFFICIENT_BALANCE
 		}
 
@@ -96,37 +127,60 @@ func (w *Worker) Process(ctx context.Context, message models.ProcessorMessage) {
 
 	if err != nil {
 		if errors.Is(err, failures.INSUFFICIENT_BALANCE) {
-			log.Warn().
+			log.Ctx(ctx).Warn().
 				Err(err).
-				Msgf("Transaction failed due to insufficient balance (concurrent update): %s", message.TransactionID)
+				Msg("Transaction failed due to insufficient balance (concurrent update)")
 			w.markTransactionAsFailed(
 				ctx,
+				transaction.Channel,
 				message.TransactionID,
 				failures.INSUFFICIENT_BALANCE.Error(),
 			)
 		} else {
-			log.Error().Err(err).Msg("failed to process transaction in database transaction")
-			w.markTransactionAsFailed(ctx, message.TransactionID, failures.UNKNOWN_ERROR.Error())
+			log.Ctx(ctx).Error().Err(err).Msg("failed to process transaction in database transaction")
+			w.markTransactionAsFailed(
+				ctx,
+				transaction.Channel,
+				message.TransactionID,
+				failures.UNKNOWN_ERROR.Error(),
+			)
 		}
 		return
 	}
 
-	log.Info().Msgf("Transaction processed successfully: %s", message.TransactionID)
+	log.Ctx(ctx).Info().Msg("Transaction processed successfully")
+	metrics.RecordTransaction(transaction.Channel, models.TransactionStatusSuccess)
 	w.notifier <- message.TransactionID
 }
 
-func (w *Worker) markTransactionAsFailed(ctx context.Context, transactionID, message string) {
+func (w *Worker) markTransactionAsFailed(
+	ctx context.Context,
+	channel models.PaymentChannel,
+	transactionID, message string,
+) {
 	_, err := w.transaction.Update(ctx, transactionID, map[string]any{
 		"status":     models.TransactionStatusFailed,
 		"message":    message,
 		"updated_at": time.Now(),
 	})
 	if err != nil {
-		log.Error().Err(err).Msg("failed to update transaction status")
+		log.Ctx(ctx).Error().Err(err).Msg("failed to update transaction status")
 	}
+	tracing.Record(trace.SpanFromContext(ctx), errors.New(message))
+	metrics.RecordTransaction(channel, models.TransactionStatusFailed)
 	w.notifier <- transactionID
 }
 
+// withTransaction adds the transaction's ids, and those of the loan and
+// disbursement it pays out, to the context logger.
+func withTransaction(ctx context.Context, transaction schema.Transaction) context.Context {
+	fields := logging.Metadata(transaction.Metadata)
+	fields.TransactionId = transaction.ID
+	fields.ReferenceId = transaction.ReferenceID
+	fields.Channel = string(transaction.Channel)
+	return logging.With(ctx, fields)
+}
+
 func (w *Worker) getFailureReason() string {
 	failure := failures.TRANSACTION_FAILURES[rand.Intn(len(failures.TRANSACTION_FAILURES))]
 	return failure.Error()
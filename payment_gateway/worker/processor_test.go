@@ -9,6 +9,7 @@ import (
 	db_test "payment-gateway/test/db"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -23,6 +24,7 @@ func TestWorker_Process(t *testing.T) {
 		worker := &Worker{
 			transaction: mockTransactionRepo,
 			account:     mockAccountRepo,
+			notifier:    make(chan string, 1),
 		}
 
 		message := models.ProcessorMessage{
@@ -49,6 +51,7 @@ func TestWorker_Process(t *testing.T) {
 		worker := &Worker{
 			transaction: mockTransactionRepo,
 			account:     mockAccountRepo,
+			notifier:    make(chan string, 1),
 		}
 
 		message := models.ProcessorMessage{
@@ -78,6 +81,7 @@ func TestWorker_Process(t *testing.T) {
 		worker := &Worker{
 			transaction: mockTransactionRepo,
 			account:     mockAccountRepo,
+			notifier:    make(chan string, 1),
 		}
 
 		message := models.ProcessorMessage{
@@ -93,7 +97,7 @@ func TestWorker_Process(t *testing.T) {
 
 		updateError := errors.New("database error")
 		mockTransactionRepo.On("Get", ctx, transactionID).Return(transaction, nil).Once()
-		mockTransactionRepo.On("Update", ctx, transactionID, mock.Anything).
+		mockTransactionRepo.On("Update", mock.Anything, transactionID, mock.Anything).
 			Return(schema.Transaction{}, updateError).Once()
 
 		worker.Process(ctx, message)
@@ -109,6 +113,7 @@ func TestWorker_Process(t *testing.T) {
 		worker := &Worker{
 			transaction: mockTransactionRepo,
 			account:     mockAccountRepo,
+			notifier:    make(chan string, 1),
 		}
 
 		message := models.ProcessorMessage{
@@ -129,12 +134,12 @@ func TestWorker_Process(t *testing.T) {
 		failedTransaction.Status = models.TransactionStatusFailed
 
 		mockTransactionRepo.On("Get", ctx, transactionID).Return(transaction, nil).Once()
-		mockTransactionRepo.On("Update", ctx, transactionID, mock.MatchedBy(func(fields map[string]any) bool {
+		mockTransactionRepo.On("Update", mock.Anything, transactionID, mock.MatchedBy(func(fields map[string]any) bool {
 			return fields["status"] == models.TransactionStatusProcessing
 		})).
 			Return(processingTransaction, nil).
 			Once()
-		mockTransactionRepo.On("Update", ctx, transactionID, mock.MatchedBy(func(fields map[string]any) bool {
+		mockTransactionRepo.On("Update", mock.Anything, transactionID, mock.MatchedBy(func(fields map[string]any) bool {
 			return fields["status"] == models.TransactionStatusFailed
 		})).
 			Return(failedTransaction, nil).
@@ -142,6 +147,8 @@ func TestWorker_Process(t *testing.T) {
 
 		worker.Process(ctx, message)
 
+		assert.Len(t, worker.notifier, 1, "the failure should be queued for notification")
+
 		mockTransactionRepo.AssertExpectations(t)
 		mockAccountRepo.AssertNotCalled(t, "List")
 	})
@@ -153,6 +160,7 @@ func TestWorker_Process(t *testing.T) {
 		worker := &Worker{
 			transaction: mockTransactionRepo,
 			account:     mockAccountRepo,
+			notifier:    make(chan string, 1),
 		}
 
 		message := models.ProcessorMessage{
@@ -174,13 +182,13 @@ func TestWorker_Process(t *testing.T) {
 
 		repoError := errors.New("database error")
 		mockTransactionRepo.On("Get", ctx, transactionID).Return(transaction, nil).Once()
-		mockTransactionRepo.On("Update", ctx, transactionID, mock.MatchedBy(func(fields map[string]any) bool {
+		mockTransactionRepo.On("Update", mock.Anything, transactionID, mock.MatchedBy(func(fields map[string]any) bool {
 			return fields["status"] == models.TransactionStatusProcessing
 		})).
 			Return(processingTransaction, nil).
 			Once()
-		mockAccountRepo.On("List", ctx).Return(nil, repoError).Once()
-		mockTransactionRepo.On("Update", ctx, transactionID, mock.MatchedBy(func(fields map[string]any) bool {
+		mockAccountRepo.On("List", mock.Anything).Return(nil, repoError).Once()
+		mockTransactionRepo.On("Update", mock.Anything, transactionID, mock.MatchedBy(func(fields map[string]any) bool {
 			return fields["status"] == models.TransactionStatusFailed &&
 				fields["message"] == failures.UNKNOWN_ERROR.Error()
 		})).
@@ -189,6 +197,8 @@ func TestWorker_Process(t *testing.T) {
 
 		worker.Process(ctx, message)
 
+		assert.Len(t, worker.notifier, 1, "the failure should be queued for notification")
+
 		mockTransactionRepo.AssertExpectations(t)
 		mockAccountRepo.AssertExpectations(t)
 	})
@@ -200,6 +210,7 @@ func TestWorker_Process(t *testing.T) {
 		worker := &Worker{
 			transaction: mockTransactionRepo,
 			account:     mockAccountRepo,
+			notifier:    make(chan string, 1),
 		}
 
 		message := models.ProcessorMessage{
@@ -220,13 +231,13 @@ func TestWorker_Process(t *testing.T) {
 		failedTransaction.Status = models.TransactionStatusFailed
 
 		mockTransactionRepo.On("Get", ctx, transactionID).Return(transaction, nil).Once()
-		mockTransactionRepo.On("Update", ctx, transactionID, mock.MatchedBy(func(fields map[string]any) bool {
+		mockTransactionRepo.On("Update", mock.Anything, transactionID, mock.MatchedBy(func(fields map[string]any) bool {
 			return fields["status"] == models.TransactionStatusProcessing
 		})).
 			Return(processingTransaction, nil).
 			Once()
-		mockAccountRepo.On("List", ctx).Return([]schema.Account{}, nil).Once()
-		mockTransactionRepo.On("Update", ctx, transactionID, mock.MatchedBy(func(fields map[string]any) bool {
+		mockAccountRepo.On("List", mock.Anything).Return([]schema.Account{}, nil).Once()
+		mockTransactionRepo.On("Update", mock.Anything, transactionID, mock.MatchedBy(func(fields map[string]any) bool {
 			return fields["status"] == models.TransactionStatusFailed &&
 				fields["message"] == failures.UNKNOWN_ERROR.Error()
 		})).
@@ -235,6 +246,8 @@ func TestWorker_Process(t *testing.T) {
 
 		worker.Process(ctx, message)
 
+		assert.Len(t, worker.notifier, 1, "the failure should be queued for notification")
+
 		mockTransactionRepo.AssertExpectations(t)
 		mockAccountRepo.AssertExpectations(t)
 	})
@@ -246,6 +259,7 @@ func TestWorker_Process(t *testing.T) {
 		worker := &Worker{
 			transaction: mockTransactionRepo,
 			account:     mockAccountRepo,
+			notifier:    make(chan string, 1),
 		}
 
 		message := models.ProcessorMessage{
@@ -273,13 +287,13 @@ func TestWorker_Process(t *testing.T) {
 		failedTransaction.Status = models.TransactionStatusFailed
 
 		mockTransactionRepo.On("Get", ctx, transactionID).Return(transaction, nil).Once()
-		mockTransactionRepo.On("Update", ctx, transactionID, mock.MatchedBy(func(fields map[string]any) bool {
+		mockTransactionRepo.On("Update", mock.Anything, transactionID, mock.MatchedBy(func(fields map[string]any) bool {
 			return fields["status"] == models.TransactionStatusProcessing
 		})).
 			Return(processingTransaction, nil).
 			Once()
-		mockAccountRepo.On("List", ctx).Return([]schema.Account{account}, nil).Once()
-		mockTransactionRepo.On("Update", ctx, transactionID, mock.MatchedBy(func(fields map[string]any) bool {
+		mockAccountRepo.On("List", mock.Anything).Return([]schema.Account{account}, nil).Once()
+		mockTransactionRepo.On("Update", mock.Anything, transactionID, mock.MatchedBy(func(fields map[string]any) bool {
 			return fields["status"] == models.TransactionStatusFailed &&
 				fields["message"] == failures.INSUFFICIENT_BALANCE.Error()
 		})).
@@ -288,6 +302,8 @@ func TestWorker_Process(t *testing.T) {
 
 		worker.Process(ctx, message)
 
+		assert.Len(t, worker.notifier, 1, "the failure should be queued for notification")
+
 		mockTransactionRepo.AssertExpectations(t)
 		mockAccountRepo.AssertExpectations(t)
 	})
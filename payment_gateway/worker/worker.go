@@ -2,10 +2,13 @@ package worker
 
 import (
 	"context"
+	"net/url"
 	"payment-gateway/db"
 	"payment-gateway/db/daos"
 	httpclient "payment-gateway/http"
+	"payment-gateway/metrics"
 	"payment-gateway/models"
+	"shared/logging"
 
 	"github.com/rs/zerolog/log"
 )
@@ -18,55 +21,63 @@ type Worker struct {
 	processor   chan models.ProcessorMessage
 	notifier    chan string
 	httpClient  httpclient.HTTPClient
+	// notificationBase is the only place notifications, and the credential
+	// httpClient carries, are sent.
+	notificationBase *url.URL
 }
 
 func NewWorker(
 	database *db.Database,
 	processor chan models.ProcessorMessage,
 	httpClient httpclient.HTTPClient,
+	notificationBase *url.URL,
 ) *Worker {
 	return &Worker{
-		db:          database,
-		account:     database.GetAccountRepository(),
-		transaction: database.GetTransactionRepository(),
-		stopChan:    make(chan struct{}),
-		notifier:    make(chan string),
-		processor:   processor,
-		httpClient:  httpClient,
+		db:               database,
+		account:          database.GetAccountRepository(),
+		transaction:      database.GetTransactionRepository(),
+		stopChan:         make(chan struct{}),
+		notifier:         make(chan string),
+		processor:        processor,
+		httpClient:       httpClient,
+		notificationBase: notificationBase,
 	}
 }
 
 func (w *Worker) StartProcessor(ctx context.Context) {
-	log.Info().Msg("Starting processor worker")
+	log.Ctx(ctx).Info().Msg("Starting processor worker")
 	for {
 		select {
 		case <-ctx.Done():
-			log.Info().Msg("Worker stopped by context")
+			log.Ctx(ctx).Info().Msg("Worker stopped by context")
 			close(w.stopChan)
 			return
 		case <-w.stopChan:
-			log.Info().Msg("Worker stopped by signal")
+			log.Ctx(ctx).Info().Msg("Worker stopped by signal")
 			return
 		case message := <-w.processor:
-			log.Info().Msgf("Received message to process transaction: %s", message.TransactionID)
+			metrics.ProcessorReceived()
+			log.Ctx(logging.With(ctx, logging.Fields{TransactionId: message.TransactionID})).
+				Info().Msg("Received message to process transaction")
 			w.Process(ctx, message)
 		}
 	}
 }
 
 func (w *Worker) StartNotifier(ctx context.Context) {
-	log.Info().Msg("Starting notifier worker")
+	log.Ctx(ctx).Info().Msg("Starting notifier worker")
 	for {
 		select {
 		case <-ctx.Done():
-			log.Info().Msg("Worker stopped by context")
+			log.Ctx(ctx).Info().Msg("Worker stopped by context")
 			close(w.stopChan)
 			return
 		case <-w.stopChan:
-			log.Info().Msg("Worker stopped by signal")
+			log.Ctx(ctx).Info().Msg("Worker stopped by signal")
 			return
 		case transactionID := <-w.notifier:
-			log.Info().Msgf("Received message to notify transaction: %s", transactionID)
+			log.Ctx(logging.With(ctx, logging.Fields{TransactionId: transactionID})).
+				Info().Msg("Received message to notify transaction")
 			w.Notify(ctx, transactionID)
 		}
 	}
@@ -75,5 +86,5 @@ func (w *Worker) StartNotifier(ctx context.Context) {
 
 func (w *Worker) Stop(ctx context.Context) {
 	close(w.stopChan)
-	log.Info().Msg("Stopping worker")
+	log.Ctx(ctx).Info().Msg("Stopping worker")
 }
@@ -0,0 +1,134 @@
+// Package apperrors is the error model of the API. An Error carries a Code,
+// which decides the HTTP status, and a message fit to show the caller.
+// Services return them, usually as sentinels wrapped with context, and every
+// error reaches the caller in the same envelope:
+//
+//	{"error": {"code": "not_found", "message": "loan not found", "request_id": "req-..."}}
+//
+// Errors without an Error in their chain are internal: they are logged, and
+// the caller only learns that something went wrong.
+package apperrors
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"shared/tracing"
+
+	"github.com/rs/zerolog/log"
+)
+
+type Code string
+
+const (
+	CodeInvalidRequest   Code = "invalid_request"
+	CodeUnauthenticated  Code = "unauthenticated"
+	CodePermissionDenied Code = "permission_denied"
+	CodeNotFound         Code = "not_found"
+	CodeConflict         Code = "conflict"
+	CodeUnprocessable    Code = "unprocessable"
+	CodeRateLimited      Code = "rate_limited"
+	CodeInternal         Code = "internal"
+	CodeUnavailable      Code = "unavailable"
+)
+
+var statuses = map[Code]int{
+	CodeInvalidRequest:   http.StatusBadRequest,
+	CodeUnauthenticated:  http.StatusUnauthorized,
+	CodePermissionDenied: http.StatusForbidden,
+	CodeNotFound:         http.StatusNotFound,
+	CodeConflict:         http.StatusConflict,
+	CodeUnprocessable:    http.StatusUnprocessableEntity,
+	CodeRateLimited:      http.StatusTooManyRequests,
+	CodeInternal:         http.StatusInternalServerError,
+	CodeUnavailable:      http.StatusServiceUnavailable,
+}
+
+// internalMessage stands in for the text of internal errors, which can
+// carry queries or upstream responses.
+const internalMessage = "internal server error"
+
+// Status is the HTTP status for code. Unknown codes are internal.
+func (c Code) Status() int {
+	if status, ok := statuses[c]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+type Error struct {
+	Code    Code
+	Message string
+	Details map[string]any
+	err     error
+}
+
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Wrap gives err a code and a message to show in place of its own text.
+// err stays in the chain for errors.Is and for the logs.
+func Wrap(err error, code Code, message string) *Error {
+	return &Error{Code: code, Message: message, err: err}
+}
+
+// Invalid reports a malformed request, such as a body that does not decode,
+// with err's own text.
+func Invalid(err error) *Error {
+	return Wrap(err, CodeInvalidRequest, err.Error())
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// WithDetails returns a copy of e carrying details, which errors.Is still
+// matches to e.
+func (e *Error) WithDetails(details map[string]any) *Error {
+	return &Error{Code: e.Code, Message: e.Message, Details: details, err: e}
+}
+
+// Body is the JSON envelope errors are sent in.
+type Body struct {
+	Error Detail `json:"error"`
+}
+
+type Detail struct {
+	Code      Code           `json:"code"`
+	Message   string         `json:"message"`
+	Details   map[string]any `json:"details,omitempty"`
+	RequestId string         `json:"request_id,omitempty"`
+}
+
+// From returns the code of the Error in err's chain and the message to show
+// for err. The message is err's full text, so context a service wrapped the
+// Error in reaches the caller. Errors without an Error, and those coded
+// internal, get a generic message.
+func From(err error) Detail {
+	var appErr *Error
+	if !errors.As(err, &appErr) || appErr.Code == CodeInternal {
+		return Detail{Code: CodeInternal, Message: internalMessage}
+	}
+	return Detail{Code: appErr.Code, Message: err.Error(), Details: appErr.Details}
+}
+
+// Write sends err in the envelope with the status of its code and the id of
+// the request. Internal errors are logged first, as their text is not sent.
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	detail := From(err)
+	detail.RequestId, _ = tracing.RequestId(r.Context())
+	status := detail.Code.Status()
+	if status >= http.StatusInternalServerError {
+		log.Ctx(r.Context()).Error().Err(err).Int("status", status).Msg("request failed")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(Body{Error: detail})
+}
@@ -0,0 +1,87 @@
+package apperrors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"shared/tracing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errLoanLocked = New(CodeConflict, "loan amount cannot change")
+
+func TestFrom(t *testing.T) {
+	t.Run("keeps the context an error was wrapped in", func(t *testing.T) {
+		detail := From(fmt.Errorf("loan L-1: %w", errLoanLocked))
+
+		assert.Equal(t, Detail{Code: CodeConflict, Message: "loan L-1: loan amount cannot change"}, detail)
+	})
+
+	t.Run("hides the text of errors without a code", func(t *testing.T) {
+		detail := From(errors.New("pq: connection refused"))
+
+		assert.Equal(t, Detail{Code: CodeInternal, Message: "internal server error"}, detail)
+	})
+
+	t.Run("shows the message a wrapped error was given", func(t *testing.T) {
+		err := Wrap(errors.New("record not found"), CodeNotFound, "loan not found")
+
+		assert.Equal(t, "loan not found", From(err).Message)
+	})
+
+	t.Run("carries details", func(t *testing.T) {
+		err := errLoanLocked.WithDetails(map[string]any{"loan_id": "L-1"})
+
+		assert.ErrorIs(t, err, errLoanLocked)
+		assert.Equal(t, map[string]any{"loan_id": "L-1"}, From(err).Details)
+	})
+}
+
+func TestWrite(t *testing.T) {
+	for name, tc := range map[string]struct {
+		err    error
+		status int
+		body   Detail
+	}{
+		"sends the status of the code": {
+			err:    fmt.Errorf("loan L-1: %w", errLoanLocked),
+			status: http.StatusConflict,
+			body:   Detail{Code: CodeConflict, Message: "loan L-1: loan amount cannot change", RequestId: "req-1"},
+		},
+		"sends errors without a code as internal": {
+			err:    errors.New("pq: connection refused"),
+			status: http.StatusInternalServerError,
+			body:   Detail{Code: CodeInternal, Message: "internal server error", RequestId: "req-1"},
+		},
+		"sends malformed requests as invalid": {
+			err:    Invalid(errors.New("unexpected EOF")),
+			status: http.StatusBadRequest,
+			body:   Detail{Code: CodeInvalidRequest, Message: "unexpected EOF", RequestId: "req-1"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r = r.WithContext(tracing.WithRequestId(r.Context(), "req-1"))
+			w := httptest.NewRecorder()
+
+			Write(w, r, tc.err)
+
+			var body Body
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			assert.Equal(t, tc.status, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			assert.Equal(t, tc.body, body.Error)
+		})
+	}
+}
+
+func TestCode_Status(t *testing.T) {
+	assert.Equal(t, http.StatusUnprocessableEntity, CodeUnprocessable.Status())
+	assert.Equal(t, http.StatusServiceUnavailable, CodeUnavailable.Status())
+	assert.Equal(t, http.StatusInternalServerError, Code("unknown").Status())
+}
@@ -0,0 +1,82 @@
+package authn
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// APIKeyHeader carries static API keys.
+const APIKeyHeader = "X-API-Key"
+
+// APIKey is a key as configured: the SHA-256 of the key in hex, never the
+// key itself, and the principal it authenticates as.
+type APIKey struct {
+	Id     string   `json:"id"`
+	SHA256 string   `json:"sha256"`
+	Roles  []string `json:"roles"`
+}
+
+type APIKeysConfig struct {
+	Keys []APIKey `json:"keys"`
+}
+
+// APIKeys authenticates requests by the key in the X-API-Key header. The
+// principal's subject is the id of the key.
+type APIKeys struct {
+	keys []apiKey
+}
+
+type apiKey struct {
+	hash      []byte
+	principal Principal
+}
+
+func NewAPIKeys(config APIKeysConfig) (*APIKeys, error) {
+	keys := make([]apiKey, 0, len(config.Keys))
+	for _, key := range config.Keys {
+		hash, err := hex.DecodeString(key.SHA256)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("api key %q: sha256 must be 64 hex characters", key.Id)
+		}
+		if key.Id == "" {
+			return nil, fmt.Errorf("api key %q: id is required", key.SHA256)
+		}
+		keys = append(keys, apiKey{
+			hash:      hash,
+			principal: Principal{Subject: key.Id, Roles: key.Roles},
+		})
+	}
+	return &APIKeys{keys: keys}, nil
+}
+
+// LoadAPIKeys reads a JSON file of API keys.
+func LoadAPIKeys(path string) (*APIKeys, error) {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config APIKeysConfig
+	if err := json.Unmarshal(file, &config); err != nil {
+		return nil, fmt.Errorf("invalid api keys file: %w", err)
+	}
+	return NewAPIKeys(config)
+}
+
+func (a *APIKeys) Authenticate(r *http.Request) (Principal, error) {
+	presented := r.Header.Get(APIKeyHeader)
+	if presented == "" {
+		return Principal{}, ErrNoCredentials
+	}
+	hash := sha256.Sum256([]byte(presented))
+	for _, key := range a.keys {
+		if subtle.ConstantTimeCompare(hash[:], key.hash) == 1 {
+			return key.principal, nil
+		}
+	}
+	return Principal{}, ErrInvalidCredentials
+}
@@ -0,0 +1,59 @@
+// Package authn identifies API callers for both services. An Authenticator
+// turns a request's credentials, a static API key or a JWT, into a
+// Principal carrying the caller's roles; what those roles may do is up to
+// each service.
+package authn
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+)
+
+var (
+	ErrNoCredentials      = errors.New("authentication required")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Principal is an authenticated caller: a person or a service.
+type Principal struct {
+	Subject string
+	Roles   []string
+}
+
+func (p Principal) HasRole(role string) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+// Authenticator reads one kind of credential. Authenticate returns
+// ErrNoCredentials when the request carries none of that kind, so the next
+// authenticator can try, and ErrInvalidCredentials when they are wrong.
+type Authenticator interface {
+	Authenticate(r *http.Request) (Principal, error)
+}
+
+// Authenticate returns the principal of the first authenticator that finds
+// its kind of credentials on r.
+func Authenticate(r *http.Request, authenticators ...Authenticator) (Principal, error) {
+	for _, authenticator := range authenticators {
+		principal, err := authenticator.Authenticate(r)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return principal, err
+	}
+	return Principal{}, ErrNoCredentials
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal the auth middleware admitted.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
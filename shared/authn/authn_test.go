@@ -0,0 +1,225 @@
+package authn
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+var hmacSecret = []byte(strings.Repeat("s", 32))
+
+func newJWT(t *testing.T, rsaKey *rsa.PrivateKey, config JWTConfig) *JWT {
+	t.Helper()
+	jwks, _ := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "oct", "kid": "hmac-1", "k": base64.RawURLEncoding.EncodeToString(hmacSecret)},
+		{
+			"kty": "RSA",
+			"kid": "rsa-1",
+			"n":   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+		},
+	}})
+	verifier, err := NewJWT(jwks, config)
+	assert.NoError(t, err)
+	return verifier
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	assert.NoError(t, err)
+	return signed
+}
+
+func TestAPIKeys_Authenticate(t *testing.T) {
+	hash := sha256.Sum256([]byte("los-secret"))
+	keys, err := NewAPIKeys(APIKeysConfig{Keys: []APIKey{
+		{Id: "loan-origination", SHA256: hex.EncodeToString(hash[:]), Roles: []string{"loan_origination"}},
+	}})
+	assert.NoError(t, err)
+
+	t.Run("returns the principal of a known key", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set(APIKeyHeader, "los-secret")
+
+		principal, err := keys.Authenticate(r)
+
+		assert.NoError(t, err)
+		assert.Equal(t, Principal{Subject: "loan-origination", Roles: []string{"loan_origination"}}, principal)
+	})
+
+	t.Run("rejects an unknown key", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set(APIKeyHeader, "guess")
+
+		_, err := keys.Authenticate(r)
+
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("reports requests without a key", func(t *testing.T) {
+		_, err := keys.Authenticate(httptest.NewRequest("GET", "/", nil))
+
+		assert.ErrorIs(t, err, ErrNoCredentials)
+	})
+
+	t.Run("refuses keys configured in plaintext", func(t *testing.T) {
+		_, err := NewAPIKeys(APIKeysConfig{Keys: []APIKey{{Id: "los", SHA256: "los-secret"}}})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestJWT_Authenticate(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	verifier := newJWT(t, rsaKey, JWTConfig{Issuer: "https://sso.example.com", Audience: "disbursement"})
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub":   "ops@example.com",
+			"iss":   "https://sso.example.com",
+			"aud":   "disbursement",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"roles": []string{"ops_admin"},
+		}
+	}
+	authenticate := func(token string) (Principal, error) {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		return verifier.Authenticate(r)
+	}
+
+	t.Run("accepts an RS256 token", func(t *testing.T) {
+		principal, err := authenticate(sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, valid()))
+
+		assert.NoError(t, err)
+		assert.Equal(t, Principal{Subject: "ops@example.com", Roles: []string{"ops_admin"}}, principal)
+	})
+
+	t.Run("accepts an HS256 token", func(t *testing.T) {
+		principal, err := authenticate(sign(t, jwt.SigningMethodHS256, "hmac-1", hmacSecret, valid()))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "ops@example.com", principal.Subject)
+	})
+
+	t.Run("rejects an expired token", func(t *testing.T) {
+		claims := valid()
+		claims["exp"] = time.Now().Add(-time.Minute).Unix()
+
+		_, err := authenticate(sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims))
+
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("rejects a token without expiry", func(t *testing.T) {
+		claims := valid()
+		delete(claims, "exp")
+
+		_, err := authenticate(sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims))
+
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("rejects a token for another audience", func(t *testing.T) {
+		claims := valid()
+		claims["aud"] = "payment-gateway"
+
+		_, err := authenticate(sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims))
+
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("rejects a token signed with an unknown key", func(t *testing.T) {
+		other, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+		_, err := authenticate(sign(t, jwt.SigningMethodRS256, "rsa-1", other, valid()))
+
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("rejects an HMAC token naming the RSA key", func(t *testing.T) {
+		publicKey := rsaKey.N.Bytes()
+
+		_, err := authenticate(sign(t, jwt.SigningMethodHS256, "rsa-1", publicKey, valid()))
+
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("rejects a token without kid", func(t *testing.T) {
+		_, err := authenticate(sign(t, jwt.SigningMethodHS256, "", hmacSecret, valid()))
+
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("reports requests without a bearer token", func(t *testing.T) {
+		_, err := verifier.Authenticate(httptest.NewRequest("GET", "/", nil))
+
+		assert.ErrorIs(t, err, ErrNoCredentials)
+	})
+}
+
+func TestNewJWT(t *testing.T) {
+	for name, jwks := range map[string]string{
+		"rejects a key without kid":   `{"keys":[{"kty":"oct","k":"` + base64.RawURLEncoding.EncodeToString(hmacSecret) + `"}]}`,
+		"rejects a short HMAC secret": `{"keys":[{"kty":"oct","kid":"h","k":"c2hvcnQ"}]}`,
+		"rejects other algorithms":    `{"keys":[{"kty":"RSA","kid":"r","alg":"RS512","n":"AQAB","e":"AQAB"}]}`,
+		"rejects other key types":     `{"keys":[{"kty":"EC","kid":"e"}]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewJWT([]byte(jwks), JWTConfig{})
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	hash := sha256.Sum256([]byte("gateway-secret"))
+	keys, _ := NewAPIKeys(APIKeysConfig{Keys: []APIKey{
+		{Id: "payment-gateway", SHA256: hex.EncodeToString(hash[:]), Roles: []string{"payment_gateway"}},
+	}})
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	verifier := newJWT(t, rsaKey, JWTConfig{})
+
+	t.Run("uses whichever credentials the request carries", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set(APIKeyHeader, "gateway-secret")
+
+		principal, err := Authenticate(r, verifier, keys)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "payment-gateway", principal.Subject)
+	})
+
+	t.Run("stops at invalid credentials", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Authorization", "Bearer not-a-token")
+		r.Header.Set(APIKeyHeader, "gateway-secret")
+
+		_, err := Authenticate(r, verifier, keys)
+
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("reports requests without credentials", func(t *testing.T) {
+		_, err := Authenticate(httptest.NewRequest("GET", "/", nil), verifier, keys)
+
+		assert.ErrorIs(t, err, ErrNoCredentials)
+	})
+}
@@ -0,0 +1,160 @@
+package authn
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTConfig restricts the tokens JWT accepts to those issued by Issuer for
+// Audience. Empty values are not checked.
+type JWTConfig struct {
+	Issuer   string
+	Audience string
+}
+
+// JWT authenticates requests by an HS256 or RS256 bearer token signed with
+// a key from a JWKS file. Tokens must name their key with kid and carry an
+// expiry. The principal's subject is the sub claim and its roles the roles
+// claim.
+type JWT struct {
+	keys   map[string]verificationKey
+	parser *jwt.Parser
+}
+
+type verificationKey struct {
+	method jwt.SigningMethod
+	key    any
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	K   string `json:"k"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+type claims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles"`
+}
+
+// NewJWT takes a JSON Web Key Set holding "oct" keys for HS256 and "RSA"
+// public keys for RS256.
+func NewJWT(jwks []byte, config JWTConfig) (*JWT, error) {
+	var set jsonWebKeySet
+	if err := json.Unmarshal(jwks, &set); err != nil {
+		return nil, fmt.Errorf("invalid jwks: %w", err)
+	}
+	keys := make(map[string]verificationKey, len(set.Keys))
+	for _, key := range set.Keys {
+		if key.Kid == "" {
+			return nil, errors.New("invalid jwks: every key needs a kid")
+		}
+		parsed, err := key.verificationKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid jwks: key %q: %w", key.Kid, err)
+		}
+		keys[key.Kid] = parsed
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	}
+	if config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(config.Issuer))
+	}
+	if config.Audience != "" {
+		options = append(options, jwt.WithAudience(config.Audience))
+	}
+	return &JWT{keys: keys, parser: jwt.NewParser(options...)}, nil
+}
+
+// LoadJWKS reads the JSON Web Key Set tokens are verified against.
+func LoadJWKS(path string, config JWTConfig) (*JWT, error) {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewJWT(file, config)
+}
+
+func (j *JWT) Authenticate(r *http.Request) (Principal, error) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return Principal{}, ErrNoCredentials
+	}
+	var parsed claims
+	if _, err := j.parser.ParseWithClaims(token, &parsed, j.key); err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+	if parsed.Subject == "" {
+		return Principal{}, fmt.Errorf("%w: token has no subject", ErrInvalidCredentials)
+	}
+	return Principal{Subject: parsed.Subject, Roles: parsed.Roles}, nil
+}
+
+// key finds the token's key by kid, refusing a key of the wrong type for
+// the token's algorithm so an RSA public key is never used as an HMAC
+// secret.
+func (j *JWT) key(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := j.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+	if token.Method != key.method {
+		return nil, fmt.Errorf("key %q is not for %s", kid, token.Method.Alg())
+	}
+	return key.key, nil
+}
+
+func (k jsonWebKey) verificationKey() (verificationKey, error) {
+	switch k.Kty {
+	case "oct":
+		if k.Alg != "" && k.Alg != jwt.SigningMethodHS256.Alg() {
+			return verificationKey{}, fmt.Errorf("unsupported alg %q", k.Alg)
+		}
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil || len(secret) < 32 {
+			return verificationKey{}, errors.New("k must be at least 32 bytes of base64url")
+		}
+		return verificationKey{method: jwt.SigningMethodHS256, key: secret}, nil
+	case "RSA":
+		if k.Alg != "" && k.Alg != jwt.SigningMethodRS256.Alg() {
+			return verificationKey{}, fmt.Errorf("unsupported alg %q", k.Alg)
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil || len(n) == 0 {
+			return verificationKey{}, errors.New("n must be base64url")
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) == 0 {
+			return verificationKey{}, errors.New("e must be base64url")
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() < 3 {
+			return verificationKey{}, errors.New("e is out of range")
+		}
+		return verificationKey{
+			method: jwt.SigningMethodRS256,
+			key:    &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())},
+		}, nil
+	}
+	return verificationKey{}, fmt.Errorf("unsupported kty %q", k.Kty)
+}
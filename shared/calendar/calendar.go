@@ -0,0 +1,211 @@
+// Package calendar knows when banks settle payments: which days are bank
+// working days, under the RBI rules of Sundays, second and fourth Saturdays
+// and published holidays, and when the half-hourly NEFT batches run on those
+// days. The holidays and the NEFT window come from a JSON file so the list can
+// be updated each year without a release.
+package calendar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+var ErrInvalidConfig = errors.New("invalid calendar config")
+
+// IST is used when the config names no timezone. India has no daylight
+// saving, so a fixed offset is exact and needs no zoneinfo on the host.
+var IST = time.FixedZone("IST", 5*60*60+30*60)
+
+type Holiday struct {
+	Date string `json:"date"` // YYYY-MM-DD
+	Name string `json:"name"`
+}
+
+type NEFTConfig struct {
+	Open                 string `json:"open"`  // HH:MM, default 08:00
+	Close                string `json:"close"` // HH:MM, default 19:00
+	BatchIntervalMinutes int    `json:"batch_interval_minutes"`
+}
+
+type Config struct {
+	Timezone string     `json:"timezone"`
+	Holidays []Holiday  `json:"holidays"`
+	NEFT     NEFTConfig `json:"neft"`
+}
+
+type Calendar struct {
+	location      *time.Location
+	holidays      map[string]string
+	years         map[int]bool
+	open          time.Duration
+	close         time.Duration
+	batchInterval time.Duration
+}
+
+func New(config Config) (*Calendar, error) {
+	location := IST
+	if config.Timezone != "" {
+		loaded, err := time.LoadLocation(config.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("%w: timezone: %v", ErrInvalidConfig, err)
+		}
+		location = loaded
+	}
+
+	open, err := clock(config.NEFT.Open, 8*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	closing, err := clock(config.NEFT.Close, 19*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	interval := 30 * time.Minute
+	if config.NEFT.BatchIntervalMinutes != 0 {
+		interval = time.Duration(config.NEFT.BatchIntervalMinutes) * time.Minute
+	}
+	if open >= closing || interval <= 0 || interval > closing-open {
+		return nil, fmt.Errorf("%w: NEFT window", ErrInvalidConfig)
+	}
+
+	holidays := make(map[string]string, len(config.Holidays))
+	years := map[int]bool{}
+	for _, holiday := range config.Holidays {
+		date, err := time.Parse(dateLayout, holiday.Date)
+		if err != nil {
+			return nil, fmt.Errorf("%w: holiday %q", ErrInvalidConfig, holiday.Date)
+		}
+		holidays[holiday.Date] = holiday.Name
+		years[date.Year()] = true
+	}
+
+	return &Calendar{
+		location:      location,
+		holidays:      holidays,
+		years:         years,
+		open:          open,
+		close:         closing,
+		batchInterval: interval,
+	}, nil
+}
+
+// Load reads a JSON config file. An empty path gives the default calendar.
+func Load(path string) (*Calendar, error) {
+	if path == "" {
+		return Default(), nil
+	}
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config Config
+	if err := json.Unmarshal(file, &config); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	return New(config)
+}
+
+// Default is the calendar without published holidays: IST, NEFT from 08:00
+// to 19:00 in half-hourly batches.
+func Default() *Calendar {
+	calendar, err := New(Config{})
+	if err != nil {
+		panic(err)
+	}
+	return calendar
+}
+
+func (c *Calendar) Location() *time.Location {
+	return c.location
+}
+
+// Holiday returns the name of the published holiday falling on at, if any.
+func (c *Calendar) Holiday(at time.Time) (string, bool) {
+	name, ok := c.holidays[at.In(c.location).Format(dateLayout)]
+	return name, ok
+}
+
+// Covers reports whether the holidays of at's year are listed. A year
+// without any is taken as not yet published, and every weekday in it is
+// treated as a working day.
+func (c *Calendar) Covers(at time.Time) bool {
+	return c.years[at.In(c.location).Year()]
+}
+
+func (c *Calendar) IsWorkingDay(at time.Time) bool {
+	local := at.In(c.location)
+	switch local.Weekday() {
+	case time.Sunday:
+		return false
+	case time.Saturday:
+		// Second and fourth Saturdays of the month.
+		if week := (local.Day()-1)/7 + 1; week == 2 || week == 4 {
+			return false
+		}
+	}
+	_, holiday := c.Holiday(local)
+	return !holiday
+}
+
+func (c *Calendar) IsNEFTOpen(at time.Time) bool {
+	if !c.IsWorkingDay(at) {
+		return false
+	}
+	offset := sinceMidnight(at.In(c.location))
+	return offset >= c.open && offset < c.close
+}
+
+// NextNEFTOpen returns at if the NEFT window is open then, otherwise the
+// moment the next window opens.
+func (c *Calendar) NextNEFTOpen(at time.Time) time.Time {
+	if c.IsNEFTOpen(at) {
+		return at
+	}
+	local := at.In(c.location)
+	day := midnight(local)
+	if sinceMidnight(local) < c.open && c.IsWorkingDay(day) {
+		return day.Add(c.open)
+	}
+	for {
+		day = day.AddDate(0, 0, 1)
+		if c.IsWorkingDay(day) {
+			return day.Add(c.open)
+		}
+	}
+}
+
+// NextNEFTSettlement returns when a payment handed over at at settles: the
+// first batch after at, or the first batch of the next window when at falls
+// outside one.
+func (c *Calendar) NextNEFTSettlement(at time.Time) time.Time {
+	start := c.NextNEFTOpen(at)
+	day := midnight(start)
+	elapsed := sinceMidnight(start) - c.open
+	batch := elapsed/c.batchInterval + 1
+	return day.Add(c.open + batch*c.batchInterval)
+}
+
+func clock(value string, fallback time.Duration) (time.Duration, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: time %q", ErrInvalidConfig, value)
+	}
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
+
+func midnight(at time.Time) time.Time {
+	year, month, day := at.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, at.Location())
+}
+
+func sinceMidnight(at time.Time) time.Duration {
+	return at.Sub(midnight(at))
+}
@@ -0,0 +1,178 @@
+package calendar
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCalendar(t *testing.T) *Calendar {
+	calendar, err := New(Config{
+		Holidays: []Holiday{{Date: "2025-08-15", Name: "Independence Day"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return calendar
+}
+
+func at(value string) time.Time {
+	parsed, err := time.ParseInLocation("2006-01-02 15:04", value, IST)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+func TestCalendar_IsWorkingDay(t *testing.T) {
+	calendar := newTestCalendar(t)
+
+	tests := []struct {
+		name string
+		day  string
+		want bool
+	}{
+		{"weekday", "2025-08-14 10:00", true},
+		{"holiday", "2025-08-15 10:00", false},
+		{"first saturday", "2025-08-02 10:00", true},
+		{"second saturday", "2025-08-09 10:00", false},
+		{"third saturday", "2025-08-16 10:00", true},
+		{"fourth saturday", "2025-08-23 10:00", false},
+		{"fifth saturday", "2025-08-30 10:00", true},
+		{"sunday", "2025-08-17 10:00", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, calendar.IsWorkingDay(at(tt.day)))
+		})
+	}
+
+	t.Run("uses the calendar timezone", func(t *testing.T) {
+		// 20:00 UTC on the 14th is already the 15th in IST.
+		utc := time.Date(2025, 8, 14, 20, 0, 0, 0, time.UTC)
+		assert.False(t, calendar.IsWorkingDay(utc))
+	})
+}
+
+func TestCalendar_IsNEFTOpen(t *testing.T) {
+	calendar := newTestCalendar(t)
+
+	assert.False(t, calendar.IsNEFTOpen(at("2025-08-14 07:59")))
+	assert.True(t, calendar.IsNEFTOpen(at("2025-08-14 08:00")))
+	assert.True(t, calendar.IsNEFTOpen(at("2025-08-14 18:59")))
+	assert.False(t, calendar.IsNEFTOpen(at("2025-08-14 19:00")))
+	assert.False(t, calendar.IsNEFTOpen(at("2025-08-15 12:00")))
+}
+
+func TestCalendar_NextNEFTOpen(t *testing.T) {
+	calendar := newTestCalendar(t)
+
+	tests := []struct {
+		name string
+		from string
+		want string
+	}{
+		{"open now", "2025-08-14 10:15", "2025-08-14 10:15"},
+		{"before opening", "2025-08-14 06:00", "2025-08-14 08:00"},
+		{"after closing rolls over the holiday", "2025-08-14 19:30", "2025-08-16 08:00"},
+		{"fourth saturday and sunday", "2025-08-22 20:00", "2025-08-25 08:00"},
+		{"first saturday is open", "2025-08-01 20:00", "2025-08-02 08:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, at(tt.want).Equal(calendar.NextNEFTOpen(at(tt.from))))
+		})
+	}
+}
+
+func TestCalendar_NextNEFTSettlement(t *testing.T) {
+	calendar := newTestCalendar(t)
+
+	tests := []struct {
+		name string
+		from string
+		want string
+	}{
+		{"mid batch", "2025-08-14 10:10", "2025-08-14 10:30"},
+		{"on a batch boundary waits for the next", "2025-08-14 10:30", "2025-08-14 11:00"},
+		{"at opening", "2025-08-14 08:00", "2025-08-14 08:30"},
+		{"last batch", "2025-08-14 18:45", "2025-08-14 19:00"},
+		{"after closing", "2025-08-14 19:05", "2025-08-16 08:30"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, at(tt.want).Equal(calendar.NextNEFTSettlement(at(tt.from))))
+		})
+	}
+}
+
+func TestCalendar_Covers(t *testing.T) {
+	calendar := newTestCalendar(t)
+
+	assert.True(t, calendar.Covers(at("2025-01-01 00:00")))
+	assert.False(t, calendar.Covers(at("2026-01-01 00:00")))
+	assert.False(t, Default().Covers(at("2025-08-14 10:00")))
+}
+
+func TestNew(t *testing.T) {
+	t.Run("rejects an inverted window", func(t *testing.T) {
+		_, err := New(Config{NEFT: NEFTConfig{Open: "19:00", Close: "08:00"}})
+		assert.True(t, errors.Is(err, ErrInvalidConfig))
+	})
+
+	t.Run("rejects a malformed holiday", func(t *testing.T) {
+		_, err := New(Config{Holidays: []Holiday{{Date: "15-08-2025"}}})
+		assert.True(t, errors.Is(err, ErrInvalidConfig))
+	})
+
+	t.Run("rejects an unknown timezone", func(t *testing.T) {
+		_, err := New(Config{Timezone: "Mars/Olympus"})
+		assert.True(t, errors.Is(err, ErrInvalidConfig))
+	})
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("reads holidays and window from the file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "calendar.json")
+		config := `{
+			"timezone": "UTC",
+			"holidays": [{"date": "2025-10-02", "name": "Gandhi Jayanti"}],
+			"neft": {"open": "09:00", "close": "17:00", "batch_interval_minutes": 60}
+		}`
+		if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		calendar, err := Load(path)
+
+		assert.NoError(t, err)
+		assert.Equal(t, time.UTC, calendar.Location())
+		name, ok := calendar.Holiday(time.Date(2025, 10, 2, 12, 0, 0, 0, time.UTC))
+		assert.True(t, ok)
+		assert.Equal(t, "Gandhi Jayanti", name)
+		assert.False(t, calendar.IsNEFTOpen(time.Date(2025, 10, 1, 8, 30, 0, 0, time.UTC)))
+		assert.Equal(t,
+			time.Date(2025, 10, 1, 11, 0, 0, 0, time.UTC),
+			calendar.NextNEFTSettlement(time.Date(2025, 10, 1, 10, 0, 0, 0, time.UTC)),
+		)
+	})
+
+	t.Run("empty path gives the default calendar", func(t *testing.T) {
+		calendar, err := Load("")
+
+		assert.NoError(t, err)
+		assert.Equal(t, IST, calendar.Location())
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+		assert.Error(t, err)
+	})
+}
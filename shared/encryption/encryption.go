@@ -0,0 +1,221 @@
+// Package encryption seals account numbers before they are stored. Each
+// value is encrypted with its own random data key, and the data key is
+// wrapped by a key encryption key held by a KeyProvider, so rotating the
+// key encryption key only rewraps data keys. Lookups by account number go
+// through a blind index: a keyed hash that is the same for equal numbers
+// but reveals nothing about them.
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var (
+	ErrInvalidCiphertext = errors.New("invalid encrypted value")
+	ErrInvalidKeyId      = errors.New("key id must not be empty or contain ':'")
+	ErrNotSealed         = errors.New("value is not encrypted")
+	ErrUnknownKey        = errors.New("unknown encryption key")
+)
+
+// KeyProvider holds the key encryption keys data keys are wrapped with. A
+// KMS can implement it; Keyring is the local file implementation.
+type KeyProvider interface {
+	// CurrentKeyId names the key new data keys are wrapped with.
+	CurrentKeyId() string
+	WrapKey(ctx context.Context, keyId string, dataKey []byte) ([]byte, error)
+	UnwrapKey(ctx context.Context, keyId string, wrapped []byte) ([]byte, error)
+	// IndexKey is the secret blind indexes are computed with. Stored
+	// indexes are only found again with the same key, so it is not rotated
+	// with the wrapping keys.
+	IndexKey(ctx context.Context) ([]byte, error)
+}
+
+// Prefix marks a sealed value, followed by the key id, the wrapped data key
+// and the encrypted value, separated by ':'.
+const Prefix = "enc:v1:"
+
+const dataKeySize = 32
+
+type Cipher struct {
+	keys     KeyProvider
+	indexKey []byte
+	strict   bool
+}
+
+func New(ctx context.Context, keys KeyProvider) (*Cipher, error) {
+	indexKey, err := keys.IndexKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(indexKey) == 0 {
+		return nil, errors.New("blind index key is empty")
+	}
+	return &Cipher{keys: keys, indexKey: indexKey}, nil
+}
+
+// Encrypt seals plaintext under a new data key wrapped with the provider's
+// current key. Empty values stay empty.
+func (c *Cipher) Encrypt(ctx context.Context, plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return "", err
+	}
+	sealed, err := seal(dataKey, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return c.envelope(ctx, dataKey, sealed)
+}
+
+// Strict returns a copy of the cipher whose Decrypt refuses values that
+// are not sealed, for once every stored value has been encrypted.
+func (c *Cipher) Strict() *Cipher {
+	return &Cipher{keys: c.keys, indexKey: c.indexKey, strict: true}
+}
+
+// Decrypt opens a value sealed by Encrypt. Values stored before encryption
+// was enabled are returned as they are, until key rotation seals them,
+// unless the cipher is strict, which returns ErrNotSealed for them.
+func (c *Cipher) Decrypt(ctx context.Context, value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	if !IsSealed(value) {
+		if c.strict {
+			return "", ErrNotSealed
+		}
+		return value, nil
+	}
+	keyId, wrapped, sealed, err := parse(value)
+	if err != nil {
+		return "", err
+	}
+	dataKey, err := c.keys.UnwrapKey(ctx, keyId, wrapped)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(dataKey, sealed)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// Rewrap returns value with its data key wrapped under the current key,
+// leaving the encrypted value itself as it is. Values stored in plaintext
+// are sealed.
+func (c *Cipher) Rewrap(ctx context.Context, value string) (string, error) {
+	if !IsSealed(value) {
+		return c.Encrypt(ctx, value)
+	}
+	keyId, wrapped, sealed, err := parse(value)
+	if err != nil {
+		return "", err
+	}
+	if keyId == c.keys.CurrentKeyId() {
+		return value, nil
+	}
+	dataKey, err := c.keys.UnwrapKey(ctx, keyId, wrapped)
+	if err != nil {
+		return "", err
+	}
+	return c.envelope(ctx, dataKey, sealed)
+}
+
+// CurrentPrefix is how values sealed under the current key start, for
+// finding those still to be rewrapped.
+func (c *Cipher) CurrentPrefix() string {
+	return Prefix + c.keys.CurrentKeyId() + ":"
+}
+
+// BlindIndex returns the hex HMAC-SHA256 of plaintext, which stands in for
+// the value in equality lookups and unique indexes.
+func (c *Cipher) BlindIndex(plaintext string) string {
+	mac := hmac.New(sha256.New, c.indexKey)
+	mac.Write([]byte(plaintext))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// IsSealed reports whether value was produced by Encrypt.
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+func (c *Cipher) envelope(ctx context.Context, dataKey, sealed []byte) (string, error) {
+	keyId := c.keys.CurrentKeyId()
+	if keyId == "" || strings.Contains(keyId, ":") {
+		return "", ErrInvalidKeyId
+	}
+	wrapped, err := c.keys.WrapKey(ctx, keyId, dataKey)
+	if err != nil {
+		return "", err
+	}
+	return Prefix + keyId + ":" +
+		base64.RawStdEncoding.EncodeToString(wrapped) + ":" +
+		base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+func parse(value string) (keyId string, wrapped, sealed []byte, err error) {
+	parts := strings.Split(strings.TrimPrefix(value, Prefix), ":")
+	if len(parts) != 3 || parts[0] == "" {
+		return "", nil, nil, ErrInvalidCiphertext
+	}
+	if wrapped, err = base64.RawStdEncoding.DecodeString(parts[1]); err != nil {
+		return "", nil, nil, ErrInvalidCiphertext
+	}
+	if sealed, err = base64.RawStdEncoding.DecodeString(parts[2]); err != nil {
+		return "", nil, nil, ErrInvalidCiphertext
+	}
+	return parts[0], wrapped, sealed, nil
+}
+
+// seal encrypts plaintext with AES-256-GCM, prefixing the random nonce.
+func seal(key, plaintext []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(key, sealed []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrInvalidCiphertext
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCiphertext, err)
+	}
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,186 @@
+package encryption
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testKey(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(b), 32)))
+}
+
+func newCipher(t *testing.T, current string, keys map[string]string) *Cipher {
+	t.Helper()
+	keyring, err := NewKeyring(KeyringConfig{CurrentKey: current, Keys: keys, IndexKey: testKey('i')})
+	assert.NoError(t, err)
+	cipher, err := New(context.Background(), keyring)
+	assert.NoError(t, err)
+	return cipher
+}
+
+func TestCipher_Encrypt(t *testing.T) {
+	ctx := context.Background()
+	cipher := newCipher(t, "k1", map[string]string{"k1": testKey('a')})
+
+	t.Run("round trips through Decrypt", func(t *testing.T) {
+		sealed, err := cipher.Encrypt(ctx, "123456789012")
+
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(sealed, "enc:v1:k1:"))
+		assert.NotContains(t, sealed, "123456789012")
+		plaintext, err := cipher.Decrypt(ctx, sealed)
+		assert.NoError(t, err)
+		assert.Equal(t, "123456789012", plaintext)
+	})
+
+	t.Run("uses a new data key for every value", func(t *testing.T) {
+		first, _ := cipher.Encrypt(ctx, "123456789012")
+		second, _ := cipher.Encrypt(ctx, "123456789012")
+
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("leaves empty values empty", func(t *testing.T) {
+		sealed, err := cipher.Encrypt(ctx, "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "", sealed)
+	})
+}
+
+func TestCipher_Decrypt(t *testing.T) {
+	ctx := context.Background()
+	cipher := newCipher(t, "k1", map[string]string{"k1": testKey('a')})
+
+	t.Run("returns plaintext stored before encryption as is", func(t *testing.T) {
+		plaintext, err := cipher.Decrypt(ctx, "123456789012")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "123456789012", plaintext)
+	})
+
+	t.Run("rejects plaintext when strict", func(t *testing.T) {
+		strict := cipher.Strict()
+		sealed, _ := strict.Encrypt(ctx, "123456789012")
+
+		_, err := strict.Decrypt(ctx, "123456789012")
+		assert.ErrorIs(t, err, ErrNotSealed)
+		plaintext, err := strict.Decrypt(ctx, sealed)
+		assert.NoError(t, err)
+		assert.Equal(t, "123456789012", plaintext)
+		empty, err := strict.Decrypt(ctx, "")
+		assert.NoError(t, err)
+		assert.Empty(t, empty)
+	})
+
+	t.Run("rejects a tampered value", func(t *testing.T) {
+		sealed, _ := cipher.Encrypt(ctx, "123456789012")
+		at := len(sealed) - 10
+		replacement := "A"
+		if sealed[at] == 'A' {
+			replacement = "B"
+		}
+		tampered := sealed[:at] + replacement + sealed[at+1:]
+
+		_, err := cipher.Decrypt(ctx, tampered)
+
+		assert.ErrorIs(t, err, ErrInvalidCiphertext)
+	})
+
+	t.Run("rejects a malformed value", func(t *testing.T) {
+		_, err := cipher.Decrypt(ctx, "enc:v1:k1:only-two")
+
+		assert.ErrorIs(t, err, ErrInvalidCiphertext)
+	})
+
+	t.Run("fails for a key no longer in the keyring", func(t *testing.T) {
+		sealed, _ := cipher.Encrypt(ctx, "123456789012")
+		rotated := newCipher(t, "k2", map[string]string{"k2": testKey('b')})
+
+		_, err := rotated.Decrypt(ctx, sealed)
+
+		assert.ErrorIs(t, err, ErrUnknownKey)
+	})
+}
+
+func TestCipher_Rewrap(t *testing.T) {
+	ctx := context.Background()
+	old := newCipher(t, "k1", map[string]string{"k1": testKey('a')})
+	sealed, _ := old.Encrypt(ctx, "123456789012")
+	cipher := newCipher(t, "k2", map[string]string{"k1": testKey('a'), "k2": testKey('b')})
+
+	t.Run("wraps the data key with the current key", func(t *testing.T) {
+		rewrapped, err := cipher.Rewrap(ctx, sealed)
+
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(rewrapped, cipher.CurrentPrefix()))
+		assert.Equal(t, strings.Split(sealed, ":")[4], strings.Split(rewrapped, ":")[4])
+		retired := newCipher(t, "k2", map[string]string{"k2": testKey('b')})
+		plaintext, err := retired.Decrypt(ctx, rewrapped)
+		assert.NoError(t, err)
+		assert.Equal(t, "123456789012", plaintext)
+	})
+
+	t.Run("leaves values under the current key unchanged", func(t *testing.T) {
+		current, _ := cipher.Encrypt(ctx, "123456789012")
+
+		rewrapped, err := cipher.Rewrap(ctx, current)
+
+		assert.NoError(t, err)
+		assert.Equal(t, current, rewrapped)
+	})
+
+	t.Run("seals plaintext", func(t *testing.T) {
+		rewrapped, err := cipher.Rewrap(ctx, "123456789012")
+
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(rewrapped, "enc:v1:k2:"))
+	})
+}
+
+func TestCipher_BlindIndex(t *testing.T) {
+	cipher := newCipher(t, "k1", map[string]string{"k1": testKey('a')})
+	rotated := newCipher(t, "k2", map[string]string{"k2": testKey('b')})
+
+	assert.Equal(t, cipher.BlindIndex("123456789012"), cipher.BlindIndex("123456789012"))
+	assert.NotEqual(t, cipher.BlindIndex("123456789012"), cipher.BlindIndex("123456789013"))
+	assert.Equal(t, cipher.BlindIndex("123456789012"), rotated.BlindIndex("123456789012"))
+	assert.Len(t, cipher.BlindIndex("123456789012"), 64)
+}
+
+func TestLoadKeyring(t *testing.T) {
+	write := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "keyring.json")
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	t.Run("loads a keyring file", func(t *testing.T) {
+		path := write(t, `{"current_key":"k1","keys":{"k1":"`+testKey('a')+`"},"index_key":"`+testKey('i')+`"}`)
+
+		keyring, err := LoadKeyring(path)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "k1", keyring.CurrentKeyId())
+	})
+
+	for name, content := range map[string]string{
+		"rejects a missing current key": `{"current_key":"k2","keys":{"k1":"` + testKey('a') + `"},"index_key":"` + testKey('i') + `"}`,
+		"rejects a short key":           `{"current_key":"k1","keys":{"k1":"c2hvcnQ="},"index_key":"` + testKey('i') + `"}`,
+		"rejects a key id with a colon": `{"current_key":"k:1","keys":{"k:1":"` + testKey('a') + `"},"index_key":"` + testKey('i') + `"}`,
+		"rejects a missing index key":   `{"current_key":"k1","keys":{"k1":"` + testKey('a') + `"}}`,
+		"rejects invalid JSON":          `{`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadKeyring(write(t, content))
+
+			assert.ErrorIs(t, err, ErrInvalidKeyring)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package encryption
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var ErrInvalidKeyring = errors.New("invalid keyring")
+
+// KeyringConfig is the keyring file: base64 encoded 32 byte keys by id, the
+// id new data keys are wrapped with, and the blind index key. Keys that
+// are no longer current stay in the file until every value wrapped with
+// them has been rotated.
+type KeyringConfig struct {
+	CurrentKey string            `json:"current_key"`
+	Keys       map[string]string `json:"keys"`
+	IndexKey   string            `json:"index_key"`
+}
+
+// Keyring is a KeyProvider holding its keys in memory, loaded from a local
+// file. It is meant for development; production should plug in a KMS.
+type Keyring struct {
+	current  string
+	keys     map[string][]byte
+	indexKey []byte
+}
+
+func NewKeyring(config KeyringConfig) (*Keyring, error) {
+	keys := make(map[string][]byte, len(config.Keys))
+	for id, encoded := range config.Keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidKeyring, ErrInvalidKeyId)
+		}
+		key, err := decodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%w: key %q: %v", ErrInvalidKeyring, id, err)
+		}
+		keys[id] = key
+	}
+	if _, ok := keys[config.CurrentKey]; !ok {
+		return nil, fmt.Errorf("%w: current key %q is not in keys", ErrInvalidKeyring, config.CurrentKey)
+	}
+	indexKey, err := decodeKey(config.IndexKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: index key: %v", ErrInvalidKeyring, err)
+	}
+	return &Keyring{current: config.CurrentKey, keys: keys, indexKey: indexKey}, nil
+}
+
+// LoadKeyring reads a JSON keyring file.
+func LoadKeyring(path string) (*Keyring, error) {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config KeyringConfig
+	if err := json.Unmarshal(file, &config); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKeyring, err)
+	}
+	return NewKeyring(config)
+}
+
+func (k *Keyring) CurrentKeyId() string {
+	return k.current
+}
+
+func (k *Keyring) WrapKey(_ context.Context, keyId string, dataKey []byte) ([]byte, error) {
+	key, ok := k.keys[keyId]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, keyId)
+	}
+	return seal(key, dataKey)
+}
+
+func (k *Keyring) UnwrapKey(_ context.Context, keyId string, wrapped []byte) ([]byte, error) {
+	key, ok := k.keys[keyId]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, keyId)
+	}
+	return open(key, wrapped)
+}
+
+func (k *Keyring) IndexKey(context.Context) ([]byte, error) {
+	return k.indexKey, nil
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("want 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
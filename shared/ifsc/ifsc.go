@@ -0,0 +1,268 @@
+// Package ifsc is the directory of bank branches from the RBI IFSC master
+// file: which bank and branch a code belongs to, and which payment systems
+// the branch takes part in. A code missing from the directory does not exist
+// or has been withdrawn, so nothing paid to it can settle. The directory is
+// read from a CSV file so it can be refreshed when RBI publishes a new one.
+package ifsc
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	// DefaultSearchLimit caps a search that asks for no limit.
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+var ErrInvalidFile = errors.New("invalid IFSC file")
+
+// codePattern is the RBI format: a four letter bank code, a zero and a six
+// character branch code.
+var codePattern = regexp.MustCompile(`^[A-Z]{4}0[A-Z0-9]{6}$`)
+
+type Branch struct {
+	IFSC     string `json:"ifsc"`
+	Bank     string `json:"bank"`
+	Branch   string `json:"branch"`
+	Address  string `json:"address,omitempty"`
+	City     string `json:"city,omitempty"`
+	District string `json:"district,omitempty"`
+	State    string `json:"state,omitempty"`
+	MICR     string `json:"micr,omitempty"`
+	NEFT     bool   `json:"neft"`
+	IMPS     bool   `json:"imps"`
+	UPI      bool   `json:"upi"`
+}
+
+// Supports reports whether the branch takes payments over channel, one of
+// NEFT, IMPS and UPI.
+func (b Branch) Supports(channel string) bool {
+	switch channel {
+	case "NEFT":
+		return b.NEFT
+	case "IMPS":
+		return b.IMPS
+	case "UPI":
+		return b.UPI
+	}
+	return false
+}
+
+// Directory is safe for concurrent reads. A nil Directory stands for a
+// deployment without the master file: it knows no branches, but Resolve
+// accepts every code.
+type Directory struct {
+	branches map[string]Branch
+	// codes is sorted, so searches return branches in a stable order.
+	codes []string
+}
+
+func New(branches []Branch) (*Directory, error) {
+	directory := &Directory{
+		branches: make(map[string]Branch, len(branches)),
+		codes:    make([]string, 0, len(branches)),
+	}
+	for _, branch := range branches {
+		branch.IFSC = normalize(branch.IFSC)
+		if !codePattern.MatchString(branch.IFSC) {
+			return nil, fmt.Errorf("%w: code %q", ErrInvalidFile, branch.IFSC)
+		}
+		if _, ok := directory.branches[branch.IFSC]; ok {
+			return nil, fmt.Errorf("%w: duplicate code %s", ErrInvalidFile, branch.IFSC)
+		}
+		directory.branches[branch.IFSC] = branch
+		directory.codes = append(directory.codes, branch.IFSC)
+	}
+	slices.Sort(directory.codes)
+	return directory, nil
+}
+
+// Load reads the master file at path. An empty path gives a nil Directory.
+func Load(path string) (*Directory, error) {
+	if path == "" {
+		return nil, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Parse(file)
+}
+
+// Parse reads the master file as CSV with a header row. IFSC, BANK and
+// BRANCH are required; ADDRESS, CITY, DISTRICT, STATE and MICR are kept when
+// present. NEFT, IMPS and UPI flag which systems a branch is on, as
+// true/false, yes/no, y/n or 1/0. A file without one of those columns is
+// taken to list branches that are all on that system, as RBI's NEFT list is.
+func Parse(r io.Reader) (*Directory, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidFile, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToUpper(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"IFSC", "BANK", "BRANCH"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: missing %s column", ErrInvalidFile, name)
+		}
+	}
+
+	var branches []Branch
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+		}
+		line, _ := reader.FieldPos(0)
+		value := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		flag := func(name string) (bool, error) {
+			if _, ok := columns[name]; !ok {
+				return true, nil
+			}
+			enabled, ok := flags[strings.ToLower(value(name))]
+			if !ok {
+				return false, fmt.Errorf("%w: line %d: %s flag %q", ErrInvalidFile, line, name, value(name))
+			}
+			return enabled, nil
+		}
+
+		branch := Branch{
+			IFSC:     value("IFSC"),
+			Bank:     value("BANK"),
+			Branch:   value("BRANCH"),
+			Address:  value("ADDRESS"),
+			City:     value("CITY"),
+			District: value("DISTRICT"),
+			State:    value("STATE"),
+			MICR:     value("MICR"),
+		}
+		if branch.Bank == "" {
+			return nil, fmt.Errorf("%w: line %d: missing bank", ErrInvalidFile, line)
+		}
+		if branch.NEFT, err = flag("NEFT"); err != nil {
+			return nil, err
+		}
+		if branch.IMPS, err = flag("IMPS"); err != nil {
+			return nil, err
+		}
+		if branch.UPI, err = flag("UPI"); err != nil {
+			return nil, err
+		}
+		branches = append(branches, branch)
+	}
+	return New(branches)
+}
+
+var flags = map[string]bool{
+	"true": true, "yes": true, "y": true, "1": true,
+	"false": false, "no": false, "n": false, "0": false, "": false,
+}
+
+func (d *Directory) Len() int {
+	if d == nil {
+		return 0
+	}
+	return len(d.codes)
+}
+
+// Lookup returns the branch code belongs to, if the directory has it.
+func (d *Directory) Lookup(code string) (Branch, bool) {
+	if d == nil {
+		return Branch{}, false
+	}
+	branch, ok := d.branches[normalize(code)]
+	return branch, ok
+}
+
+// Resolve returns the branch a payment to code goes to, and false when the
+// code is not in the directory. A nil Directory resolves every code to a
+// branch of an unnamed bank that supports every channel.
+func (d *Directory) Resolve(code string) (Branch, bool) {
+	if d == nil {
+		return Branch{IFSC: normalize(code), NEFT: true, IMPS: true, UPI: true}, true
+	}
+	return d.Lookup(code)
+}
+
+// Query matches branches whose code starts with, or whose bank, branch,
+// address or city contains, Text, and whose bank, city and state contain
+// the ones given. Matching ignores case; empty fields match everything.
+type Query struct {
+	Text  string
+	Bank  string
+	City  string
+	State string
+	Limit int
+}
+
+// Search returns up to query.Limit matching branches in code order,
+// DefaultSearchLimit when no limit is given and never more than
+// MaxSearchLimit.
+func (d *Directory) Search(query Query) []Branch {
+	limit := min(query.Limit, MaxSearchLimit)
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	results := []Branch{}
+	if d == nil {
+		return results
+	}
+	text := strings.ToUpper(strings.TrimSpace(query.Text))
+	for _, code := range d.codes {
+		branch := d.branches[code]
+		if text != "" && !strings.HasPrefix(code, text) &&
+			!contains(text, branch.Bank, branch.Branch, branch.Address, branch.City) {
+			continue
+		}
+		if !contains(query.Bank, branch.Bank) ||
+			!contains(query.City, branch.City) ||
+			!contains(query.State, branch.State) {
+			continue
+		}
+		results = append(results, branch)
+		if len(results) == limit {
+			break
+		}
+	}
+	return results
+}
+
+// contains reports whether any of values contains part, ignoring case. An
+// empty part is contained in everything.
+func contains(part string, values ...string) bool {
+	part = strings.ToUpper(strings.TrimSpace(part))
+	if part == "" {
+		return true
+	}
+	for _, value := range values {
+		if strings.Contains(strings.ToUpper(value), part) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalize(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
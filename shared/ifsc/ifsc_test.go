@@ -0,0 +1,170 @@
+package ifsc
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const masterFile = `BANK,IFSC,BRANCH,ADDRESS,CITY,DISTRICT,STATE,MICR,NEFT,IMPS,UPI
+HDFC Bank,HDFC0001234,Andheri East,"Chakala, Andheri East",Mumbai,Mumbai Suburban,Maharashtra,400240015,true,true,true
+State Bank of India,SBIN0000691,New Delhi Main,11 Sansad Marg,New Delhi,New Delhi,Delhi,110002087,yes,yes,no
+State Bank of India,SBIN0001234,Bandra West,Hill Road,Mumbai,Mumbai Suburban,Maharashtra,,Y,N,N
+Karur Vysya Bank,KVBL0001101,Chennai Main,Mount Road,Chennai,Chennai,Tamil Nadu,,1,0,0
+`
+
+func newTestDirectory(t *testing.T) *Directory {
+	directory, err := Parse(strings.NewReader(masterFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return directory
+}
+
+func TestParse(t *testing.T) {
+	directory := newTestDirectory(t)
+
+	assert.Equal(t, 4, directory.Len())
+	branch, ok := directory.Lookup("SBIN0000691")
+	assert.True(t, ok)
+	assert.Equal(t, Branch{
+		IFSC:     "SBIN0000691",
+		Bank:     "State Bank of India",
+		Branch:   "New Delhi Main",
+		Address:  "11 Sansad Marg",
+		City:     "New Delhi",
+		District: "New Delhi",
+		State:    "Delhi",
+		MICR:     "110002087",
+		NEFT:     true,
+		IMPS:     true,
+		UPI:      false,
+	}, branch)
+
+	t.Run("takes branches to be on systems without a column", func(t *testing.T) {
+		directory, err := Parse(strings.NewReader("IFSC,BANK,BRANCH\nHDFC0001234,HDFC Bank,Andheri East\n"))
+
+		assert.NoError(t, err)
+		branch, _ := directory.Lookup("HDFC0001234")
+		assert.True(t, branch.NEFT && branch.IMPS && branch.UPI)
+	})
+
+	for name, file := range map[string]string{
+		"missing column": "IFSC,BRANCH\nHDFC0001234,Andheri East\n",
+		"malformed code": "IFSC,BANK,BRANCH\nHDFC1234,HDFC Bank,Andheri East\n",
+		"duplicate code": "IFSC,BANK,BRANCH\nHDFC0001234,HDFC Bank,A\nHDFC0001234,HDFC Bank,B\n",
+		"missing bank":   "IFSC,BANK,BRANCH\nHDFC0001234,,Andheri East\n",
+		"unknown flag":   "IFSC,BANK,BRANCH,UPI\nHDFC0001234,HDFC Bank,Andheri East,maybe\n",
+		"short record":   "IFSC,BANK,BRANCH\nHDFC0001234,HDFC Bank\n",
+		"empty file":     "",
+	} {
+		t.Run("rejects "+name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(file))
+
+			assert.ErrorIs(t, err, ErrInvalidFile)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("gives no directory without a path", func(t *testing.T) {
+		directory, err := Load("")
+
+		assert.NoError(t, err)
+		assert.Nil(t, directory)
+	})
+
+	t.Run("reads the file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "ifsc.csv")
+		if err := os.WriteFile(path, []byte(masterFile), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		directory, err := Load(path)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 4, directory.Len())
+	})
+
+	t.Run("fails on a missing file", func(t *testing.T) {
+		_, err := Load(filepath.Join(t.TempDir(), "missing.csv"))
+
+		assert.True(t, errors.Is(err, os.ErrNotExist))
+	})
+}
+
+func TestDirectory_Lookup(t *testing.T) {
+	directory := newTestDirectory(t)
+
+	branch, ok := directory.Lookup(" hdfc0001234 ")
+	assert.True(t, ok)
+	assert.Equal(t, "HDFC Bank", branch.Bank)
+
+	_, ok = directory.Lookup("HDFC0009999")
+	assert.False(t, ok)
+
+	_, ok = (*Directory)(nil).Lookup("HDFC0001234")
+	assert.False(t, ok)
+}
+
+func TestDirectory_Resolve(t *testing.T) {
+	t.Run("knows only the directory's codes", func(t *testing.T) {
+		directory := newTestDirectory(t)
+
+		branch, ok := directory.Resolve("SBIN0001234")
+		assert.True(t, ok)
+		assert.True(t, branch.Supports("NEFT"))
+		assert.False(t, branch.Supports("IMPS"))
+		assert.False(t, branch.Supports("UPI"))
+		assert.False(t, branch.Supports("RTGS"))
+
+		_, ok = directory.Resolve("SBIN0009999")
+		assert.False(t, ok)
+	})
+
+	t.Run("accepts every code without a directory", func(t *testing.T) {
+		branch, ok := (*Directory)(nil).Resolve("sbin0009999")
+
+		assert.True(t, ok)
+		assert.Equal(t, Branch{IFSC: "SBIN0009999", NEFT: true, IMPS: true, UPI: true}, branch)
+	})
+}
+
+func TestDirectory_Search(t *testing.T) {
+	directory := newTestDirectory(t)
+	codes := func(branches []Branch) []string {
+		result := []string{}
+		for _, branch := range branches {
+			result = append(result, branch.IFSC)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{"code prefix", Query{Text: "sbin"}, []string{"SBIN0000691", "SBIN0001234"}},
+		{"branch name", Query{Text: "bandra"}, []string{"SBIN0001234"}},
+		{"city", Query{City: "mumbai"}, []string{"HDFC0001234", "SBIN0001234"}},
+		{"bank and city", Query{Bank: "state bank", City: "Mumbai"}, []string{"SBIN0001234"}},
+		{"state", Query{State: "Tamil"}, []string{"KVBL0001101"}},
+		{"limit", Query{Limit: 2}, []string{"HDFC0001234", "KVBL0001101"}},
+		{"no match", Query{Text: "Kolkata"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, codes(directory.Search(tt.query)))
+		})
+	}
+
+	t.Run("finds nothing without a directory", func(t *testing.T) {
+		assert.Empty(t, (*Directory)(nil).Search(Query{Text: "SBIN"}))
+	})
+}
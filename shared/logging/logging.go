@@ -0,0 +1,108 @@
+// Package logging keeps a zerolog logger on the context that carries the ids
+// of whatever the current work is about. CorrelationIDMiddleware starts it
+// with the request id, and services, workers and providers add the loan,
+// disbursement, batch, transaction, reference and channel as they learn them,
+// so every line a payout writes through log.Ctx(ctx) in either service can be
+// found by any of its ids.
+package logging
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Field names of the correlation ids on log lines.
+const (
+	RequestIdField      = "request_id"
+	TraceIdField        = "trace_id"
+	LoanIdField         = "loan_id"
+	DisbursementIdField = "disbursement_id"
+	BatchIdField        = "batch_id"
+	TransactionIdField  = "transaction_id"
+	ReferenceIdField    = "reference_id"
+	ChannelField        = "channel"
+)
+
+func init() {
+	// Without a default, log.Ctx returns a disabled logger for a context
+	// that has none attached, and the line is silently dropped.
+	zerolog.DefaultContextLogger = &log.Logger
+}
+
+// Fields are the correlation ids of the work a context belongs to. Empty
+// fields leave the ids already on the context as they are.
+type Fields struct {
+	RequestId      string
+	LoanId         string
+	DisbursementId string
+	BatchId        string
+	TransactionId  string
+	ReferenceId    string
+	Channel        string
+
+	traceId string
+}
+
+type fieldsKey struct{}
+
+// Metadata returns the ids the disbursement service sends in the payment
+// request metadata, which the gateway stores on the transaction.
+func Metadata(metadata map[string]any) Fields {
+	loanId, _ := metadata["loan_id"].(string)
+	disbursementId, _ := metadata["disbursement_id"].(string)
+	return Fields{LoanId: loanId, DisbursementId: disbursementId}
+}
+
+// With returns ctx with a logger carrying fields on top of the ids ctx
+// already has. An id set again replaces the earlier one rather than being
+// written twice. The trace id of the span on ctx is added as well, so log
+// lines can be matched to their trace.
+func With(ctx context.Context, fields Fields) context.Context {
+	current, _ := ctx.Value(fieldsKey{}).(Fields)
+	merged := current.merge(fields)
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		merged.traceId = spanContext.TraceID().String()
+	}
+	ctx = context.WithValue(ctx, fieldsKey{}, merged)
+	logger := merged.apply(log.Logger.With()).Logger()
+	return logger.WithContext(ctx)
+}
+
+func (f Fields) merge(other Fields) Fields {
+	f.RequestId = pick(other.RequestId, f.RequestId)
+	f.LoanId = pick(other.LoanId, f.LoanId)
+	f.DisbursementId = pick(other.DisbursementId, f.DisbursementId)
+	f.BatchId = pick(other.BatchId, f.BatchId)
+	f.TransactionId = pick(other.TransactionId, f.TransactionId)
+	f.ReferenceId = pick(other.ReferenceId, f.ReferenceId)
+	f.Channel = pick(other.Channel, f.Channel)
+	return f
+}
+
+func (f Fields) apply(logger zerolog.Context) zerolog.Context {
+	for _, field := range []struct{ key, value string }{
+		{RequestIdField, f.RequestId},
+		{TraceIdField, f.traceId},
+		{LoanIdField, f.LoanId},
+		{DisbursementIdField, f.DisbursementId},
+		{BatchIdField, f.BatchId},
+		{TransactionIdField, f.TransactionId},
+		{ReferenceIdField, f.ReferenceId},
+		{ChannelField, f.Channel},
+	} {
+		if field.value != "" {
+			logger = logger.Str(field.key, field.value)
+		}
+	}
+	return logger
+}
+
+func pick(value, fallback string) string {
+	if value != "" {
+		return value
+	}
+	return fallback
+}
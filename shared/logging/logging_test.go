@@ -0,0 +1,107 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buffer bytes.Buffer
+	previous := log.Logger
+	log.Logger = zerolog.New(&buffer)
+	t.Cleanup(func() { log.Logger = previous })
+	return &buffer
+}
+
+func lastLine(t *testing.T, buffer *bytes.Buffer) map[string]any {
+	t.Helper()
+	lines := bytes.Split(bytes.TrimSpace(buffer.Bytes()), []byte("\n"))
+	line := map[string]any{}
+	assert.NoError(t, json.Unmarshal(lines[len(lines)-1], &line))
+	return line
+}
+
+func TestWith(t *testing.T) {
+	t.Run("falls back to the global logger", func(t *testing.T) {
+		buffer := captureLogs(t)
+
+		log.Ctx(context.Background()).Info().Msg("worker started")
+
+		assert.Equal(t, "worker started", lastLine(t, buffer)["message"])
+	})
+
+	t.Run("adds ids as work progresses", func(t *testing.T) {
+		buffer := captureLogs(t)
+
+		ctx := With(context.Background(), Fields{RequestId: "req-1"})
+		ctx = With(ctx, Fields{LoanId: "LOAN-1", DisbursementId: "DISB-1"})
+		ctx = With(ctx, Fields{
+			TransactionId: "TXN-1",
+			ReferenceId:   "REF-1",
+			Channel:       "UPI",
+		})
+		log.Ctx(ctx).Info().Msg("transfer sent")
+
+		assert.Equal(t, map[string]any{
+			"level":           "info",
+			"message":         "transfer sent",
+			"request_id":      "req-1",
+			"loan_id":         "LOAN-1",
+			"disbursement_id": "DISB-1",
+			"transaction_id":  "TXN-1",
+			"reference_id":    "REF-1",
+			"channel":         "UPI",
+		}, lastLine(t, buffer))
+	})
+
+	t.Run("replaces an id set again", func(t *testing.T) {
+		buffer := captureLogs(t)
+
+		ctx := With(context.Background(), Fields{Channel: "UPI"})
+		ctx = With(ctx, Fields{Channel: "IMPS"})
+		log.Ctx(ctx).Info().Msg("channel fallback")
+
+		assert.Equal(t, 1, bytes.Count(buffer.Bytes(), []byte(`"channel"`)))
+		assert.Equal(t, "IMPS", lastLine(t, buffer)["channel"])
+	})
+
+	t.Run("adds the trace id of the span on ctx", func(t *testing.T) {
+		buffer := captureLogs(t)
+		provider := sdktrace.NewTracerProvider()
+		ctx, span := provider.Tracer("test").Start(context.Background(), "payment.process")
+		defer span.End()
+
+		log.Ctx(With(ctx, Fields{})).Info().Msg("processing")
+
+		assert.Equal(t, span.SpanContext().TraceID().String(), lastLine(t, buffer)["trace_id"])
+	})
+
+	t.Run("leaves the parent context unchanged", func(t *testing.T) {
+		buffer := captureLogs(t)
+
+		parent := With(context.Background(), Fields{BatchId: "BATCH-1"})
+		With(parent, Fields{DisbursementId: "DISB-1"})
+		log.Ctx(parent).Info().Msg("batch processed")
+
+		line := lastLine(t, buffer)
+		assert.Equal(t, "BATCH-1", line["batch_id"])
+		assert.NotContains(t, line, "disbursement_id")
+	})
+}
+
+func TestMetadata(t *testing.T) {
+	assert.Equal(t, Fields{LoanId: "LOAN-1", DisbursementId: "DIS-1"}, Metadata(map[string]any{
+		"loan_id":          "LOAN-1",
+		"disbursement_id":  "DIS-1",
+		"notification_url": "http://disbursement:7070/api/v1/payment/notify",
+	}))
+	assert.Equal(t, Fields{}, Metadata(nil))
+}
@@ -0,0 +1,242 @@
+// Package pii masks the beneficiary details both services handle: account
+// numbers keep their last four digits and names keep the first letter of
+// each word. Struct fields holding them are tagged pii:"account" or
+// pii:"name", and free text that may quote an account number, like an
+// error, is tagged pii:"text", so Redact can mask a whole response, Writer
+// masks every log line on its way out, and Visible leaves the details in
+// full only for callers granted ViewPermission.
+package pii
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ViewPermission lets a caller see account numbers and beneficiary names
+// unmasked.
+const ViewPermission = "pii:view"
+
+// Values of the pii struct tag.
+const (
+	KindAccount = "account"
+	KindName    = "name"
+	KindText    = "text"
+)
+
+const visibleDigits = 4
+
+// Account masks all but the last four characters of an account number.
+// Numbers of four characters or fewer are masked entirely.
+func Account(account string) string {
+	runes := []rune(account)
+	keep := 0
+	if len(runes) > visibleDigits {
+		keep = visibleDigits
+	}
+	return strings.Repeat("X", len(runes)-keep) + string(runes[len(runes)-keep:])
+}
+
+// Name keeps the first letter of each word of a name and stars the rest.
+func Name(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		first, size := utf8.DecodeRuneInString(word)
+		words[i] = string(first) + strings.Repeat("*", utf8.RuneCountInString(word[size:]))
+	}
+	return strings.Join(words, " ")
+}
+
+// Text masks every run of 9 to 18 digits in free text, such as an account
+// number quoted in an error, with Account.
+func Text(text string) string {
+	return accountNumber.ReplaceAllStringFunc(text, Account)
+}
+
+func mask(kind, value string) string {
+	switch kind {
+	case KindAccount:
+		return Account(value)
+	case KindName:
+		return Name(value)
+	case KindText:
+		return Text(value)
+	}
+	return value
+}
+
+// Redact returns a copy of v with every string or *string field tagged pii
+// masked, following pointers, slices, maps and nested structs. v itself is
+// left unchanged.
+func Redact[T any](v T) T {
+	value := reflect.ValueOf(&v).Elem()
+	redacted := reflect.New(value.Type()).Elem()
+	redacted.Set(redact(value))
+	masked, _ := redacted.Interface().(T)
+	return masked
+}
+
+func redact(value reflect.Value) reflect.Value {
+	switch value.Kind() {
+	case reflect.Pointer:
+		if value.IsNil() {
+			return value
+		}
+		copied := reflect.New(value.Type().Elem())
+		copied.Elem().Set(redact(value.Elem()))
+		return copied
+	case reflect.Interface:
+		if value.IsNil() {
+			return value
+		}
+		copied := reflect.New(value.Type()).Elem()
+		copied.Set(redact(value.Elem()))
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(value.Type()).Elem()
+		copied.Set(value)
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if kind, ok := field.Tag.Lookup("pii"); ok {
+				copied.Field(i).Set(maskField(kind, value.Field(i)))
+				continue
+			}
+			copied.Field(i).Set(redact(value.Field(i)))
+		}
+		return copied
+	case reflect.Slice:
+		if value.IsNil() {
+			return value
+		}
+		copied := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		for i := 0; i < value.Len(); i++ {
+			copied.Index(i).Set(redact(value.Index(i)))
+		}
+		return copied
+	case reflect.Array:
+		copied := reflect.New(value.Type()).Elem()
+		for i := 0; i < value.Len(); i++ {
+			copied.Index(i).Set(redact(value.Index(i)))
+		}
+		return copied
+	case reflect.Map:
+		if value.IsNil() {
+			return value
+		}
+		copied := reflect.MakeMapWithSize(value.Type(), value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), redact(iter.Value()))
+		}
+		return copied
+	}
+	return value
+}
+
+func maskField(kind string, value reflect.Value) reflect.Value {
+	switch {
+	case value.Kind() == reflect.String:
+		copied := reflect.New(value.Type()).Elem()
+		copied.SetString(mask(kind, value.String()))
+		return copied
+	case value.Kind() == reflect.Pointer && !value.IsNil() && value.Elem().Kind() == reflect.String:
+		copied := reflect.New(value.Type().Elem())
+		copied.Elem().SetString(mask(kind, value.Elem().String()))
+		return copied
+	}
+	return value
+}
+
+// Marshal encodes v as JSON with its pii fields masked. It is installed as
+// zerolog.InterfaceMarshalFunc so structs logged with Interface are masked
+// like API responses.
+func Marshal(v any) ([]byte, error) {
+	return json.Marshal(Redact(v))
+}
+
+var (
+	// taggedField matches a log field whose key names an account or a
+	// beneficiary name, capturing the key and the quoted value.
+	taggedField = regexp.MustCompile(`"(account|account_number|beneficiary_account|beneficiary_name|registered_name)":("(?:[^"\\]|\\.)*")`)
+	// textField matches the free text fields of a log line.
+	textField = regexp.MustCompile(`"(?:message|error)":"(?:[^"\\]|\\.)*"`)
+	// accountNumber matches the digit runs Indian bank account numbers are
+	// made of.
+	accountNumber = regexp.MustCompile(`\b\d{9,18}\b`)
+)
+
+var fieldKinds = map[string]string{
+	"account":             KindAccount,
+	"account_number":      KindAccount,
+	"beneficiary_account": KindAccount,
+	"beneficiary_name":    KindName,
+	"registered_name":     KindName,
+}
+
+// Line masks the account numbers and beneficiary names in a JSON log line:
+// the values of keys that name them, and any run of 9 to 18 digits in the
+// message or error, such as an account number in a formatted struct. Other
+// fields, like ids, amounts and UTRs, are left alone.
+func Line(line []byte) []byte {
+	line = taggedField.ReplaceAllFunc(line, func(match []byte) []byte {
+		parts := taggedField.FindSubmatch(match)
+		var value string
+		if err := json.Unmarshal(parts[2], &value); err != nil {
+			return match
+		}
+		masked, _ := json.Marshal(mask(fieldKinds[string(parts[1])], value))
+		return append([]byte(`"`+string(parts[1])+`":`), masked...)
+	})
+	return textField.ReplaceAllFunc(line, func(field []byte) []byte {
+		return accountNumber.ReplaceAllFunc(field, func(digits []byte) []byte {
+			return []byte(Account(string(digits)))
+		})
+	})
+}
+
+type writer struct {
+	out io.Writer
+}
+
+// Writer returns an io.Writer that masks each log line with Line before
+// passing it to out. The service logger writes through it so that nothing
+// logged anywhere reaches the output unmasked.
+func Writer(out io.Writer) io.Writer {
+	return writer{out: out}
+}
+
+func (w writer) Write(p []byte) (int, error) {
+	if _, err := w.out.Write(Line(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+type accessKey struct{}
+
+// WithAccess returns ctx for a caller granted ViewPermission.
+func WithAccess(ctx context.Context) context.Context {
+	return context.WithValue(ctx, accessKey{}, true)
+}
+
+// CanView reports whether the caller of ctx may see pii unmasked.
+func CanView(ctx context.Context) bool {
+	allowed, _ := ctx.Value(accessKey{}).(bool)
+	return allowed
+}
+
+// Visible returns v as is for callers that can view pii and masked with
+// Redact for everyone else.
+func Visible[T any](ctx context.Context, v T) T {
+	if CanView(ctx) {
+		return v
+	}
+	return Redact(v)
+}
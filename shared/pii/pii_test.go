@@ -0,0 +1,135 @@
+package pii
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+type payee struct {
+	Name           string  `json:"name" pii:"name"`
+	Account        string  `json:"account" pii:"account"`
+	IFSC           string  `json:"ifsc"`
+	RegisteredName *string `json:"registered_name" pii:"name"`
+}
+
+type change struct {
+	Id       string           `json:"id"`
+	Previous payee            `json:"previous"`
+	History  []*payee         `json:"history"`
+	ByLoan   map[string]payee `json:"by_loan"`
+}
+
+func TestAccount(t *testing.T) {
+	t.Run("keeps the last four digits", func(t *testing.T) {
+		assert.Equal(t, "XXXXXXXX9012", Account("123456789012"))
+	})
+
+	t.Run("masks short numbers entirely", func(t *testing.T) {
+		assert.Equal(t, "XXXX", Account("1234"))
+		assert.Equal(t, "", Account(""))
+	})
+}
+
+func TestName(t *testing.T) {
+	assert.Equal(t, "R**** S*****", Name("Rahul Sharma"))
+	assert.Equal(t, "A K****", Name(" A  Kumar "))
+	assert.Equal(t, "Ś****", Name("Śyāma"))
+}
+
+func TestText(t *testing.T) {
+	assert.Equal(t, "account XXXXXXXX9012 is frozen", Text("account 123456789012 is frozen"))
+	assert.Equal(t, "amount 5000 over limit", Text("amount 5000 over limit"))
+}
+
+func TestRedact(t *testing.T) {
+	registered := "Rahul Sharma"
+
+	t.Run("masks tagged fields of nested values", func(t *testing.T) {
+		original := change{
+			Id:       "CHG-1",
+			Previous: payee{Name: "Rahul Sharma", Account: "123456789012", IFSC: "HDFC0001234"},
+			History:  []*payee{{Name: "Rahul", Account: "987654321", RegisteredName: &registered}},
+			ByLoan:   map[string]payee{"LOAN-1": {Name: "Rahul", Account: "555566667777"}},
+		}
+
+		masked := Redact(original)
+
+		assert.Equal(t, "CHG-1", masked.Id)
+		assert.Equal(t, payee{Name: "R**** S*****", Account: "XXXXXXXX9012", IFSC: "HDFC0001234"}, masked.Previous)
+		assert.Equal(t, "XXXXX4321", masked.History[0].Account)
+		assert.Equal(t, "R**** S*****", *masked.History[0].RegisteredName)
+		assert.Equal(t, "XXXXXXXX7777", masked.ByLoan["LOAN-1"].Account)
+	})
+
+	t.Run("leaves the original unchanged", func(t *testing.T) {
+		original := []*payee{{Name: "Rahul", Account: "987654321", RegisteredName: &registered}}
+
+		Redact(original)
+
+		assert.Equal(t, "987654321", original[0].Account)
+		assert.Equal(t, "Rahul Sharma", registered)
+	})
+
+	t.Run("handles nil values", func(t *testing.T) {
+		assert.Nil(t, Redact[*payee](nil))
+		assert.Nil(t, Redact[any](nil))
+		assert.Equal(t, payee{Name: "R****"}, Redact(payee{Name: "Rahul"}))
+	})
+}
+
+func TestLine(t *testing.T) {
+	t.Run("masks fields naming accounts and beneficiaries", func(t *testing.T) {
+		line := `{"level":"info","account_number":"123456789012","beneficiary_name":"Rahul \"RS\" Sharma","message":"beneficiary added"}`
+
+		assert.Equal(t,
+			`{"level":"info","account_number":"XXXXXXXX9012","beneficiary_name":"R**** \"*** S*****","message":"beneficiary added"}`,
+			string(Line([]byte(line))))
+	})
+
+	t.Run("masks account numbers in messages and errors", func(t *testing.T) {
+		line := `{"level":"error","utr":"412345678901","error":"account 123456789012 is frozen","message":"existing: {Account:98765432101}"}`
+
+		assert.Equal(t,
+			`{"level":"error","utr":"412345678901","error":"account XXXXXXXX9012 is frozen","message":"existing: {Account:XXXXXXX2101}"}`,
+			string(Line([]byte(line))))
+	})
+
+	t.Run("leaves other lines as they are", func(t *testing.T) {
+		line := `{"level":"info","loan_id":"LOAN-1","amount":125000000,"message":"disbursement created"}`
+
+		assert.Equal(t, line, string(Line([]byte(line))))
+	})
+}
+
+func TestWriter(t *testing.T) {
+	var buffer bytes.Buffer
+	previous := zerolog.InterfaceMarshalFunc
+	zerolog.InterfaceMarshalFunc = Marshal
+	t.Cleanup(func() { zerolog.InterfaceMarshalFunc = previous })
+	logger := zerolog.New(Writer(&buffer))
+
+	logger.Info().
+		Str("account", "123456789012").
+		Interface("payee", payee{Name: "Rahul Sharma", Account: "987654321"}).
+		Msg("verifying")
+
+	assert.JSONEq(t,
+		`{"level":"info","account":"XXXXXXXX9012","payee":{"name":"R**** S*****","account":"XXXXX4321","ifsc":"","registered_name":null},"message":"verifying"}`,
+		buffer.String())
+}
+
+func TestVisible(t *testing.T) {
+	details := payee{Name: "Rahul Sharma", Account: "123456789012"}
+
+	t.Run("masks for callers without access", func(t *testing.T) {
+		assert.Equal(t, payee{Name: "R**** S*****", Account: "XXXXXXXX9012"}, Visible(context.Background(), details))
+	})
+
+	t.Run("shows details to callers with access", func(t *testing.T) {
+		assert.Equal(t, details, Visible(WithAccess(context.Background()), details))
+	})
+}
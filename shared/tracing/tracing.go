@@ -0,0 +1,191 @@
+// Package tracing sets up OpenTelemetry tracing and carries W3C trace
+// context across the places a payout leaves the current goroutine: HTTP
+// calls between the disbursement service and the payment gateway, their
+// channels and workers, and the pollers that pick work up from the database
+// later. Work handed over through a channel or a poller is identified by id
+// only, so the trace context travels on the row itself (see TraceParent and
+// Resume) and the payout keeps a single trace from the API request to the
+// gateway's notification.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ExporterNone = "none"
+	ExporterOTLP = "otlp"
+	ExporterFile = "file"
+
+	DefaultTraceFile = "traces.jsonl"
+
+	instrumentationName = "shared/tracing"
+	traceParentHeader   = "traceparent"
+)
+
+// Span attributes identifying what a span worked on.
+const (
+	RequestIdKey         = attribute.Key("request.id")
+	LoanIdKey            = attribute.Key("loan.id")
+	DisbursementIdKey    = attribute.Key("disbursement.id")
+	BatchIdKey           = attribute.Key("batch.id")
+	WebhookDeliveryIdKey = attribute.Key("webhook.delivery.id")
+	TransactionIdKey     = attribute.Key("transaction.id")
+	ReferenceIdKey       = attribute.Key("reference.id")
+)
+
+type requestIdKey struct{}
+
+// Config picks where spans go. With ExporterNone spans are still created, so
+// trace ids are generated and forwarded between the services, but nothing is
+// exported. The OTLP exporter reads its endpoint and headers from the
+// standard OTEL_EXPORTER_OTLP_* variables.
+type Config struct {
+	ServiceName string
+	Exporter    string
+	File        string
+}
+
+// Init installs the tracer provider and the W3C trace context propagator
+// globally. The returned function flushes pending spans and must be called
+// on shutdown.
+func Init(ctx context.Context, config Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(config.ServiceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	options := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+	var closer io.Closer
+	switch config.Exporter {
+	case "", ExporterNone:
+	case ExporterOTLP:
+		exporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create otlp exporter: %w", err)
+		}
+		options = append(options, sdktrace.WithBatcher(exporter))
+	case ExporterFile:
+		path := config.File
+		if path == "" {
+			path = DefaultTraceFile
+		}
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open trace file: %w", err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to create file exporter: %w", err)
+		}
+		options = append(options, sdktrace.WithBatcher(exporter))
+		closer = file
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", config.Exporter)
+	}
+
+	provider := sdktrace.NewTracerProvider(options...)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			if closeErr := closer.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		return err
+	}, nil
+}
+
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start begins an internal span as a child of whatever span ctx carries.
+func Start(
+	ctx context.Context,
+	name string,
+	attributes ...attribute.KeyValue,
+) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// Resume begins a span under the trace recorded by TraceParent when the work
+// was handed over. A span already on ctx takes precedence, and an empty or
+// malformed traceParent starts a new trace.
+func Resume(
+	ctx context.Context,
+	traceParent string,
+	name string,
+	attributes ...attribute.KeyValue,
+) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() && traceParent != "" {
+		carrier := propagation.MapCarrier{traceParentHeader: traceParent}
+		ctx = propagation.TraceContext{}.Extract(ctx, carrier)
+	}
+	return Start(ctx, name, attributes...)
+}
+
+// TraceParent returns the W3C traceparent of the span on ctx, for storing
+// with work that is picked up later. It is empty when ctx carries no span.
+func TraceParent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	return carrier.Get(traceParentHeader)
+}
+
+// Record marks span failed when err is not nil and returns err, so it can
+// wrap a return value.
+func Record(span trace.Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+// Inject writes the trace context on ctx into outgoing request headers.
+func Inject(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// Extract reads the trace context of an incoming request.
+func Extract(ctx context.Context, header http.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, requestId)
+}
+
+// RequestId returns the X-Request-ID of the API request ctx belongs to.
+func RequestId(ctx context.Context) (string, bool) {
+	requestId, ok := ctx.Value(requestIdKey{}).(string)
+	return requestId, ok && requestId != ""
+}
@@ -0,0 +1,155 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestResume(t *testing.T) {
+	recorder := recordSpans(t)
+
+	t.Run("continues the stored trace", func(t *testing.T) {
+		ctx, request := Start(context.Background(), "request")
+		traceParent := TraceParent(ctx)
+		request.End()
+		assert.NotEmpty(t, traceParent)
+
+		_, span := Resume(context.Background(), traceParent, "payment.process")
+		span.End()
+
+		spans := recorder.Ended()
+		resumed := spans[len(spans)-1]
+		assert.Equal(t, "payment.process", resumed.Name())
+		assert.Equal(t, request.SpanContext().TraceID(), resumed.SpanContext().TraceID())
+		assert.Equal(t, request.SpanContext().SpanID(), resumed.Parent().SpanID())
+	})
+
+	t.Run("prefers the span on ctx", func(t *testing.T) {
+		stored, storedSpan := Start(context.Background(), "stored")
+		storedSpan.End()
+		ctx, current := Start(context.Background(), "current")
+		defer current.End()
+
+		_, span := Resume(ctx, TraceParent(stored), "payment.process")
+		span.End()
+
+		spans := recorder.Ended()
+		assert.Equal(t, current.SpanContext().SpanID(), spans[len(spans)-1].Parent().SpanID())
+	})
+
+	t.Run("starts a new trace without a parent", func(t *testing.T) {
+		for _, traceParent := range []string{"", "not-a-traceparent"} {
+			_, span := Resume(context.Background(), traceParent, "payment.process")
+			span.End()
+
+			spans := recorder.Ended()
+			resumed := spans[len(spans)-1]
+			assert.True(t, resumed.SpanContext().IsValid())
+			assert.False(t, resumed.Parent().IsValid())
+		}
+	})
+}
+
+func TestTraceParent(t *testing.T) {
+	recordSpans(t)
+
+	assert.Empty(t, TraceParent(context.Background()))
+
+	ctx, span := Start(context.Background(), "request")
+	defer span.End()
+	assert.Regexp(t, `^00-[0-9a-f]{32}-[0-9a-f]{16}-01$`, TraceParent(ctx))
+}
+
+func TestInject(t *testing.T) {
+	recordSpans(t)
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(previous) })
+
+	ctx, span := Start(context.Background(), "request")
+	defer span.End()
+
+	header := http.Header{}
+	Inject(ctx, header)
+	assert.Equal(t, TraceParent(ctx), header.Get("traceparent"))
+
+	extracted := Extract(context.Background(), header)
+	_, child := Start(extracted, "callee")
+	defer child.End()
+	assert.Equal(t, span.SpanContext().TraceID(), child.SpanContext().TraceID())
+}
+
+func TestRecord(t *testing.T) {
+	recorder := recordSpans(t)
+
+	_, span := Start(context.Background(), "ok")
+	assert.NoError(t, Record(span, nil))
+	span.End()
+
+	err := errors.New("gateway error")
+	_, span = Start(context.Background(), "failed")
+	assert.Equal(t, err, Record(span, err))
+	span.End()
+
+	spans := recorder.Ended()
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "gateway error", spans[1].Status().Description)
+}
+
+func TestRequestId(t *testing.T) {
+	_, ok := RequestId(context.Background())
+	assert.False(t, ok)
+
+	requestId, ok := RequestId(WithRequestId(context.Background(), "req-1"))
+	assert.True(t, ok)
+	assert.Equal(t, "req-1", requestId)
+}
+
+func TestInit(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	t.Run("rejects an unknown exporter", func(t *testing.T) {
+		_, err := Init(context.Background(), Config{ServiceName: "test", Exporter: "zipkin"})
+		assert.ErrorContains(t, err, "unknown trace exporter")
+	})
+
+	t.Run("writes spans to a file", func(t *testing.T) {
+		path := t.TempDir() + "/traces.jsonl"
+		shutdown, err := Init(context.Background(), Config{
+			ServiceName: "test",
+			Exporter:    ExporterFile,
+			File:        path,
+		})
+		assert.NoError(t, err)
+
+		_, span := Start(context.Background(), "request")
+		span.End()
+		assert.NoError(t, shutdown(context.Background()))
+
+		body, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Contains(t, string(body), `"Name":"request"`)
+	})
+}
@@ -0,0 +1,127 @@
+// Package validation checks decoded requests against the rules in their
+// `validate` struct tags, such as
+//
+//	Amount   float64 `json:"amount" validate:"gt=0,lte=100000000"`
+//	IFSCCode string  `json:"ifsc_code" validate:"omitempty,ifsc"`
+//
+// Besides the validator's own rules it knows ifsc, account_number and
+// notblank. Failures are reported as invalid requests that name each field
+// by its JSON name, and carry a message per field in their details.
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"shared/apperrors"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var ErrInvalid = apperrors.New(apperrors.CodeInvalidRequest, "invalid request")
+
+var (
+	// ifscPattern is the RBI format: a four letter bank code, a zero and a
+	// six character branch code.
+	ifscPattern          = regexp.MustCompile(`^[A-Z]{4}0[A-Z0-9]{6}$`)
+	accountNumberPattern = regexp.MustCompile(`^[0-9]{9,18}$`)
+)
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			return ""
+		case "":
+			return field.Name
+		}
+		return name
+	})
+	register(v, "ifsc", func(fl validator.FieldLevel) bool {
+		return ifscPattern.MatchString(fl.Field().String())
+	})
+	register(v, "account_number", func(fl validator.FieldLevel) bool {
+		return accountNumberPattern.MatchString(fl.Field().String())
+	})
+	register(v, "notblank", func(fl validator.FieldLevel) bool {
+		return strings.TrimSpace(fl.Field().String()) != ""
+	})
+	return v
+}
+
+func register(v *validator.Validate, tag string, fn validator.Func) {
+	if err := v.RegisterValidation(tag, fn); err != nil {
+		panic(err)
+	}
+}
+
+// Struct checks every field of v.
+func Struct(v any) error {
+	return fieldErrors(validate.Struct(v))
+}
+
+// Present checks only the fields of v that are set, for partial updates
+// where an omitted field is left unchanged.
+func Present(v any) error {
+	value := reflect.Indirect(reflect.ValueOf(v))
+	return fieldErrors(validate.StructFiltered(v, func(namespace []byte) bool {
+		_, path, _ := strings.Cut(string(namespace), ".")
+		name, _, _ := strings.Cut(path, ".")
+		field := value.FieldByName(name)
+		return field.IsValid() && field.IsZero()
+	}))
+}
+
+func fieldErrors(err error) error {
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) {
+		return err
+	}
+	fields := make(map[string]string, len(invalid))
+	messages := make([]string, 0, len(invalid))
+	for _, fieldErr := range invalid {
+		// The namespace starts with the name of the struct validated.
+		_, field, _ := strings.Cut(fieldErr.Namespace(), ".")
+		fields[field] = message(fieldErr)
+		messages = append(messages, field+" "+fields[field])
+	}
+	appErr := apperrors.Wrap(ErrInvalid, apperrors.CodeInvalidRequest, strings.Join(messages, "; "))
+	appErr.Details = map[string]any{"fields": fields}
+	return appErr
+}
+
+func message(err validator.FieldError) string {
+	param := err.Param()
+	unit := ""
+	if err.Kind() == reflect.String {
+		unit = " characters"
+	}
+	switch err.Tag() {
+	case "required", "notblank":
+		return "is required"
+	case "required_with":
+		return "is required with account details"
+	case "gt":
+		if param == "0" {
+			return "must be greater than zero"
+		}
+		return "must be greater than " + param
+	case "gte", "min":
+		return "must be at least " + param + unit
+	case "lte", "max":
+		return "must be at most " + param + unit
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(param), ", ")
+	case "ifsc":
+		return "is not a valid IFSC"
+	case "account_number":
+		return "must be 9 to 18 digits"
+	}
+	return "is invalid"
+}
@@ -0,0 +1,102 @@
+package validation
+
+import (
+	"testing"
+
+	"shared/apperrors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type payee struct {
+	Account string `json:"account_number" validate:"required_with=IFSC,omitempty,account_number"`
+	IFSC    string `json:"ifsc_code" validate:"required_with=Account,omitempty,ifsc"`
+}
+
+type request struct {
+	LoanId string  `json:"loan_id" validate:"required,max=8"`
+	Amount float64 `json:"amount" validate:"gt=0,lte=1000"`
+	Name   string  `json:"name" validate:"notblank"`
+	Kind   string  `json:"kind" validate:"omitempty,oneof=personal gold"`
+	Payee  payee   `json:"payee"`
+}
+
+func valid() request {
+	return request{LoanId: "LOAN-1", Amount: 500, Name: "Asha Rao"}
+}
+
+func fields(t *testing.T, err error) map[string]string {
+	t.Helper()
+	detail := apperrors.From(err)
+	assert.Equal(t, apperrors.CodeInvalidRequest, detail.Code)
+	fields, _ := detail.Details["fields"].(map[string]string)
+	return fields
+}
+
+func TestStruct(t *testing.T) {
+	t.Run("accepts a valid request", func(t *testing.T) {
+		assert.NoError(t, Struct(valid()))
+	})
+
+	t.Run("reports every invalid field by its JSON name", func(t *testing.T) {
+		req := request{LoanId: "LOAN-123456", Amount: -1, Name: "  ", Kind: "car"}
+
+		err := Struct(req)
+
+		assert.ErrorIs(t, err, ErrInvalid)
+		assert.Equal(t, map[string]string{
+			"loan_id": "must be at most 8 characters",
+			"amount":  "must be greater than zero",
+			"name":    "is required",
+			"kind":    "must be one of personal, gold",
+		}, fields(t, err))
+		assert.Equal(t,
+			"loan_id must be at most 8 characters; amount must be greater than zero; "+
+				"name is required; kind must be one of personal, gold",
+			err.Error(),
+		)
+	})
+
+	t.Run("checks IFSC and account number formats", func(t *testing.T) {
+		req := valid()
+		req.Payee = payee{Account: "12AB", IFSC: "hdfc0001234"}
+
+		err := Struct(req)
+
+		assert.Equal(t, map[string]string{
+			"payee.account_number": "must be 9 to 18 digits",
+			"payee.ifsc_code":      "is not a valid IFSC",
+		}, fields(t, err))
+	})
+
+	t.Run("requires the rest of the account details once one is given", func(t *testing.T) {
+		req := valid()
+		req.Payee = payee{Account: "123456789012"}
+
+		err := Struct(req)
+
+		assert.Equal(t, map[string]string{"payee.ifsc_code": "is required with account details"}, fields(t, err))
+	})
+
+	t.Run("accepts valid account details", func(t *testing.T) {
+		req := valid()
+		req.Payee = payee{Account: "123456789012", IFSC: "HDFC0001234"}
+
+		assert.NoError(t, Struct(req))
+	})
+}
+
+func TestPresent(t *testing.T) {
+	t.Run("skips fields that are not set", func(t *testing.T) {
+		assert.NoError(t, Present(request{Amount: 500}))
+	})
+
+	t.Run("checks fields that are set", func(t *testing.T) {
+		err := Present(request{Amount: 5000, Kind: "car"})
+
+		assert.Equal(t, map[string]string{
+			"amount": "must be at most 1000",
+			"kind":   "must be one of personal, gold",
+		}, fields(t, err))
+	})
+}